@@ -0,0 +1,376 @@
+# Code generated by cmd/pbgen from pb/simulation.proto. DO NOT EDIT.
+"""Types of the messages of pb/simulation.proto.
+
+from_json decodes the protobuf JSON mapping streamed by GET /snapshots (see swarm_client.py):
+64-bit integers are converted from strings and the missing fields get their default value.
+"""
+
+from __future__ import annotations
+
+import enum
+from dataclasses import dataclass, field
+from typing import Any, List, Optional
+
+
+class TeamColor(str, enum.Enum):
+    TEAM_UNSPECIFIED = "TEAM_UNSPECIFIED"
+    TEAM_RED = "TEAM_RED"
+    TEAM_BLUE = "TEAM_BLUE"
+    TEAM_NEUTRAL = "TEAM_NEUTRAL"
+
+
+@dataclass
+class Tick:
+    """Sent by the World to tell actors to update their state"""
+
+    #: Simulation time covered by the tick in nanoseconds, 0 for a nominal tick (1/60 s)
+    delta_time: int = 0
+    #: Optional field
+    context: Optional[Perception] = None
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Tick:
+        return cls(
+            delta_time=int(d.get("deltaTime", 0)),
+            context=Perception.from_json(d["context"]) if d.get("context") is not None else None,
+        )
+
+
+@dataclass
+class Vector:
+    x: float = 0.0
+    y: float = 0.0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Vector:
+        return cls(
+            x=float(d.get("x", 0.0)),
+            y=float(d.get("y", 0.0)),
+        )
+
+
+@dataclass
+class GetState:
+    """Sent by the World to ask for current status"""
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> GetState:
+        return cls()
+
+
+@dataclass
+class ActorState:
+    """The response containing the actor's data"""
+
+    id: str = ""
+    #: "RED" or "BLUE"
+    color: TeamColor = TeamColor.TEAM_UNSPECIFIED
+    position: Optional[Vector] = None
+    velocity: Optional[Vector] = None
+    #: Incremented each time a pooled ID is recycled, stale states are ignored
+    generation: int = 0
+    #: Energy a blue lacks, from 0 (fed) to 1 (starving)
+    hunger: float = 0.0
+    #: Stamina a red spent chasing, from 0 (rested) to 1 (exhausted)
+    fatigue: float = 0.0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> ActorState:
+        return cls(
+            id=str(d.get("id", "")),
+            color=TeamColor(d.get("color", "TEAM_UNSPECIFIED")),
+            position=Vector.from_json(d["position"]) if d.get("position") is not None else None,
+            velocity=Vector.from_json(d["velocity"]) if d.get("velocity") is not None else None,
+            generation=int(d.get("generation", 0)),
+            hunger=float(d.get("hunger", 0.0)),
+            fatigue=float(d.get("fatigue", 0.0)),
+        )
+
+
+@dataclass
+class Perception:
+    """Perception is sent by the world to tell an actor what neighbors are visible"""
+
+    targets: List[ActorState] = field(default_factory=list)
+    friends: List[ActorState] = field(default_factory=list)
+    #: Transient force pushing the actor during this tick, e.g. the scare tool of the Game
+    force: Optional[Vector] = None
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Perception:
+        return cls(
+            targets=[ActorState.from_json(v) for v in d.get("targets") or []],
+            friends=[ActorState.from_json(v) for v in d.get("friends") or []],
+            force=Vector.from_json(d["force"]) if d.get("force") is not None else None,
+        )
+
+
+@dataclass
+class Convert:
+    """Convert message is the command to switch teams"""
+
+    target_color: TeamColor = TeamColor.TEAM_UNSPECIFIED
+    #: Name of the behavior used by the new team (empty = keep default)
+    strategy: str = ""
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Convert:
+        return cls(
+            target_color=TeamColor(d.get("targetColor", "TEAM_UNSPECIFIED")),
+            strategy=str(d.get("strategy", "")),
+        )
+
+
+@dataclass
+class Respawn:
+    """Respawn brings a parked (despawned) individual back to life with a new state"""
+
+    state: Optional[ActorState] = None
+    #: Name of the behavior of its team
+    strategy: str = ""
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Respawn:
+        return cls(
+            state=ActorState.from_json(d["state"]) if d.get("state") is not None else None,
+            strategy=str(d.get("strategy", "")),
+        )
+
+
+@dataclass
+class SetStrategy:
+    """SetStrategy switches the named behavior used by every member of a team"""
+
+    team: TeamColor = TeamColor.TEAM_UNSPECIFIED
+    name: str = ""
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> SetStrategy:
+        return cls(
+            team=TeamColor(d.get("team", "TEAM_UNSPECIFIED")),
+            name=str(d.get("name", "")),
+        )
+
+
+@dataclass
+class ReportStatus:
+    """Sent by Individual -> World"""
+
+    state: Optional[ActorState] = None
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> ReportStatus:
+        return cls(
+            state=ActorState.from_json(d["state"]) if d.get("state") is not None else None,
+        )
+
+
+@dataclass
+class WorldSnapshot:
+    """Sent by World -> UI (via channel, or actor if UI is an actor)"""
+
+    actors: List[ActorState] = field(default_factory=list)
+    red_count: int = 0
+    blue_count: int = 0
+    is_game_over: bool = False
+    winner: str = ""
+    #: Simulation step that produced this snapshot
+    tick: int = 0
+    #: Entities outside the viewport (see SetViewport): counted in red_count and blue_count, not listed in actors
+    offscreen_red: int = 0
+    offscreen_blue: int = 0
+    #: Team switches ordered during the tick
+    conversions: int = 0
+    #: Wall time the engine spent on the tick, in nanoseconds (0 unless enabled)
+    tick_duration: int = 0
+    #: Civilians of no team, never listed in the offscreen counts
+    neutral_count: int = 0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> WorldSnapshot:
+        return cls(
+            actors=[ActorState.from_json(v) for v in d.get("actors") or []],
+            red_count=int(d.get("redCount", 0)),
+            blue_count=int(d.get("blueCount", 0)),
+            is_game_over=bool(d.get("isGameOver", False)),
+            winner=str(d.get("winner", "")),
+            tick=int(d.get("tick", 0)),
+            offscreen_red=int(d.get("offscreenRed", 0)),
+            offscreen_blue=int(d.get("offscreenBlue", 0)),
+            conversions=int(d.get("conversions", 0)),
+            tick_duration=int(d.get("tickDuration", 0)),
+            neutral_count=int(d.get("neutralCount", 0)),
+        )
+
+
+@dataclass
+class SetViewport:
+    """SetViewport tells the World the area of the world visible in the UI: the snapshots sent
+    to the UI then list only the entities inside it. An empty area (max <= min) lists them all.
+    """
+
+    min_x: float = 0.0
+    min_y: float = 0.0
+    max_x: float = 0.0
+    max_y: float = 0.0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> SetViewport:
+        return cls(
+            min_x=float(d.get("minX", 0.0)),
+            min_y=float(d.get("minY", 0.0)),
+            max_x=float(d.get("maxX", 0.0)),
+            max_y=float(d.get("maxY", 0.0)),
+        )
+
+
+@dataclass
+class UpdateConfig:
+    """UpdateConfig allows runtime updates to all configuration parameters"""
+
+    #: Interaction Radii
+    detection_radius: float = 0.0
+    defense_radius: float = 0.0
+    contact_radius: float = 0.0
+    visual_range: float = 0.0
+    protected_range: float = 0.0
+    #: Physics / Behavior
+    max_speed: float = 0.0
+    min_speed: float = 0.0
+    aggression: float = 0.0
+    #: Boids Flocking Parameters
+    centering_factor: float = 0.0
+    avoid_factor: float = 0.0
+    matching_factor: float = 0.0
+    turn_factor: float = 0.0
+    #: Population (for reference, changes take effect on restart)
+    num_red_at_start: int = 0
+    num_blue_at_start: int = 0
+    #: Visualization
+    display_detection_circle: bool = False
+    display_defense_circle: bool = False
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> UpdateConfig:
+        return cls(
+            detection_radius=float(d.get("detectionRadius", 0.0)),
+            defense_radius=float(d.get("defenseRadius", 0.0)),
+            contact_radius=float(d.get("contactRadius", 0.0)),
+            visual_range=float(d.get("visualRange", 0.0)),
+            protected_range=float(d.get("protectedRange", 0.0)),
+            max_speed=float(d.get("maxSpeed", 0.0)),
+            min_speed=float(d.get("minSpeed", 0.0)),
+            aggression=float(d.get("aggression", 0.0)),
+            centering_factor=float(d.get("centeringFactor", 0.0)),
+            avoid_factor=float(d.get("avoidFactor", 0.0)),
+            matching_factor=float(d.get("matchingFactor", 0.0)),
+            turn_factor=float(d.get("turnFactor", 0.0)),
+            num_red_at_start=int(d.get("numRedAtStart", 0)),
+            num_blue_at_start=int(d.get("numBlueAtStart", 0)),
+            display_detection_circle=bool(d.get("displayDetectionCircle", False)),
+            display_defense_circle=bool(d.get("displayDefenseCircle", False)),
+        )
+
+
+@dataclass
+class StreamRequest:
+    """StreamRequest configures a snapshot stream"""
+
+    #: Only send one snapshot every N ticks (0 or 1 = every snapshot)
+    every_n_ticks: int = 0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> StreamRequest:
+        return cls(
+            every_n_ticks=int(d.get("everyNTicks", 0)),
+        )
+
+
+@dataclass
+class Unit:
+    """Unit is an entity as the commander of a team sees it"""
+
+    id: str = ""
+    position: Optional[Vector] = None
+    velocity: Optional[Vector] = None
+    #: Teammates within the defense radius
+    friends: int = 0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Unit:
+        return cls(
+            id=str(d.get("id", "")),
+            position=Vector.from_json(d["position"]) if d.get("position") is not None else None,
+            velocity=Vector.from_json(d["velocity"]) if d.get("velocity") is not None else None,
+            friends=int(d.get("friends", 0)),
+        )
+
+
+@dataclass
+class TeamBrief:
+    """TeamBrief is sent by the World to the commander of a team at every tick: the aggregated view of
+    the world it orders its team from
+    """
+
+    team: TeamColor = TeamColor.TEAM_UNSPECIFIED
+    tick: int = 0
+    #: Members of the team, in arrival order
+    units: List[Unit] = field(default_factory=list)
+    #: Members of the other team, in arrival order
+    enemies: List[Unit] = field(default_factory=list)
+    #: Mean position of the units
+    centroid: Optional[Vector] = None
+    #: Mean position of the enemies
+    enemy_centroid: Optional[Vector] = None
+    #: Mean distance of the units to their centroid
+    spread: float = 0.0
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> TeamBrief:
+        return cls(
+            team=TeamColor(d.get("team", "TEAM_UNSPECIFIED")),
+            tick=int(d.get("tick", 0)),
+            units=[Unit.from_json(v) for v in d.get("units") or []],
+            enemies=[Unit.from_json(v) for v in d.get("enemies") or []],
+            centroid=Vector.from_json(d["centroid"]) if d.get("centroid") is not None else None,
+            enemy_centroid=Vector.from_json(d["enemyCentroid"]) if d.get("enemyCentroid") is not None else None,
+            spread=float(d.get("spread", 0.0)),
+        )
+
+
+@dataclass
+class Order:
+    """Order is sent by the commander of a team to its members, which blend it into their steering.
+    The World sends an empty order to a commander to dismiss it.
+    """
+
+    team: TeamColor = TeamColor.TEAM_UNSPECIFIED
+    #: "regroup", "scatter", "focus" or empty for none
+    kind: str = ""
+    point: Optional[Vector] = None
+    #: ID of the enemy to focus
+    target: str = ""
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Order:
+        return cls(
+            team=TeamColor(d.get("team", "TEAM_UNSPECIFIED")),
+            kind=str(d.get("kind", "")),
+            point=Vector.from_json(d["point"]) if d.get("point") is not None else None,
+            target=str(d.get("target", "")),
+        )
+
+
+@dataclass
+class Move:
+    """Move places an individual elsewhere in the same life: only its position and velocity change"""
+
+    position: Optional[Vector] = None
+    velocity: Optional[Vector] = None
+
+    @classmethod
+    def from_json(cls, d: dict[str, Any]) -> Move:
+        return cls(
+            position=Vector.from_json(d["position"]) if d.get("position") is not None else None,
+            velocity=Vector.from_json(d["velocity"]) if d.get("velocity") is not None else None,
+        )
@@ -0,0 +1,54 @@
+"""Reference client of the snapshot stream of the simulation (standard library only).
+
+Start the simulation with the HTTP server, e.g. `go run ./cmd/simulation -http :8080`
+(or `-headless -http :8080`), then:
+
+    python swarm_client.py http://localhost:8080 --every 60
+
+or from a notebook:
+
+    from swarm_client import stream_snapshots
+    for snap in stream_snapshots("http://localhost:8080", every=10):
+        print(snap.tick, snap.red_count, snap.blue_count)
+"""
+
+from __future__ import annotations
+
+import argparse
+import json
+import urllib.request
+from typing import Iterator, Optional
+
+from simulation_types import WorldSnapshot
+
+
+def stream_snapshots(url: str, every: int = 1, timeout: Optional[float] = None) -> Iterator[WorldSnapshot]:
+    """Yields the WorldSnapshots of GET /snapshots until the simulation stops.
+
+    Only one snapshot every `every` is sent, and a slow reader misses snapshots instead of
+    queuing them: compare the ticks to detect the gaps.
+    """
+    request = urllib.request.Request(f"{url.rstrip('/')}/snapshots?every={every}")
+    with urllib.request.urlopen(request, timeout=timeout) as response:
+        for line in response:
+            if line.strip():
+                yield WorldSnapshot.from_json(json.loads(line))
+
+
+def main() -> None:
+    parser = argparse.ArgumentParser(description="Print the populations streamed by the simulation")
+    parser.add_argument("url", nargs="?", default="http://localhost:8080", help="address of the -http server")
+    parser.add_argument("--every", type=int, default=60, help="one snapshot every N")
+    args = parser.parse_args()
+    try:
+        for snap in stream_snapshots(args.url, args.every):
+            print(f"tick {snap.tick:>7}  red {snap.red_count:>5}  blue {snap.blue_count:>5}  listed {len(snap.actors)}")
+            if snap.is_game_over:
+                print(f"game over, {snap.winner} wins")
+                break
+    except KeyboardInterrupt:
+        pass
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,179 @@
+// Code generated by cmd/pbgen from pb/simulation.proto. DO NOT EDIT.
+//
+// Protobuf JSON mapping of the messages, as streamed by GET /snapshots: every field is present,
+// 64-bit integers are strings, enums the names of their values and unset messages null.
+
+export type TeamColor = "TEAM_UNSPECIFIED" | "TEAM_RED" | "TEAM_BLUE" | "TEAM_NEUTRAL";
+
+/** Sent by the World to tell actors to update their state */
+export interface Tick {
+  /** Simulation time covered by the tick in nanoseconds, 0 for a nominal tick (1/60 s) */
+  deltaTime: string;
+  /** Optional field */
+  context: Perception | null;
+}
+
+export interface Vector {
+  x: number;
+  y: number;
+}
+
+/** Sent by the World to ask for current status */
+export type GetState = Record<string, never>;
+
+/** The response containing the actor's data */
+export interface ActorState {
+  id: string;
+  /** "RED" or "BLUE" */
+  color: TeamColor;
+  position: Vector | null;
+  velocity: Vector | null;
+  /** Incremented each time a pooled ID is recycled, stale states are ignored */
+  generation: number;
+  /** Energy a blue lacks, from 0 (fed) to 1 (starving) */
+  hunger: number;
+  /** Stamina a red spent chasing, from 0 (rested) to 1 (exhausted) */
+  fatigue: number;
+}
+
+/** Perception is sent by the world to tell an actor what neighbors are visible */
+export interface Perception {
+  targets: ActorState[];
+  friends: ActorState[];
+  /** Transient force pushing the actor during this tick, e.g. the scare tool of the Game */
+  force: Vector | null;
+}
+
+/** Convert message is the command to switch teams */
+export interface Convert {
+  targetColor: TeamColor;
+  /** Name of the behavior used by the new team (empty = keep default) */
+  strategy: string;
+}
+
+/** Respawn brings a parked (despawned) individual back to life with a new state */
+export interface Respawn {
+  state: ActorState | null;
+  /** Name of the behavior of its team */
+  strategy: string;
+}
+
+/** SetStrategy switches the named behavior used by every member of a team */
+export interface SetStrategy {
+  team: TeamColor;
+  name: string;
+}
+
+/** Sent by Individual -> World */
+export interface ReportStatus {
+  state: ActorState | null;
+}
+
+/** Sent by World -> UI (via channel, or actor if UI is an actor) */
+export interface WorldSnapshot {
+  actors: ActorState[];
+  redCount: number;
+  blueCount: number;
+  isGameOver: boolean;
+  winner: string;
+  /** Simulation step that produced this snapshot */
+  tick: string;
+  /** Entities outside the viewport (see SetViewport): counted in red_count and blue_count, not listed in actors */
+  offscreenRed: number;
+  offscreenBlue: number;
+  /** Team switches ordered during the tick */
+  conversions: number;
+  /** Wall time the engine spent on the tick, in nanoseconds (0 unless enabled) */
+  tickDuration: string;
+  /** Civilians of no team, never listed in the offscreen counts */
+  neutralCount: number;
+}
+
+/**
+ * SetViewport tells the World the area of the world visible in the UI: the snapshots sent
+ * to the UI then list only the entities inside it. An empty area (max <= min) lists them all.
+ */
+export interface SetViewport {
+  minX: number;
+  minY: number;
+  maxX: number;
+  maxY: number;
+}
+
+/** UpdateConfig allows runtime updates to all configuration parameters */
+export interface UpdateConfig {
+  /** Interaction Radii */
+  detectionRadius: number;
+  defenseRadius: number;
+  contactRadius: number;
+  visualRange: number;
+  protectedRange: number;
+  /** Physics / Behavior */
+  maxSpeed: number;
+  minSpeed: number;
+  aggression: number;
+  /** Boids Flocking Parameters */
+  centeringFactor: number;
+  avoidFactor: number;
+  matchingFactor: number;
+  turnFactor: number;
+  /** Population (for reference, changes take effect on restart) */
+  numRedAtStart: number;
+  numBlueAtStart: number;
+  /** Visualization */
+  displayDetectionCircle: boolean;
+  displayDefenseCircle: boolean;
+}
+
+/** StreamRequest configures a snapshot stream */
+export interface StreamRequest {
+  /** Only send one snapshot every N ticks (0 or 1 = every snapshot) */
+  everyNTicks: number;
+}
+
+/** Unit is an entity as the commander of a team sees it */
+export interface Unit {
+  id: string;
+  position: Vector | null;
+  velocity: Vector | null;
+  /** Teammates within the defense radius */
+  friends: number;
+}
+
+/**
+ * TeamBrief is sent by the World to the commander of a team at every tick: the aggregated view of
+ * the world it orders its team from
+ */
+export interface TeamBrief {
+  team: TeamColor;
+  tick: string;
+  /** Members of the team, in arrival order */
+  units: Unit[];
+  /** Members of the other team, in arrival order */
+  enemies: Unit[];
+  /** Mean position of the units */
+  centroid: Vector | null;
+  /** Mean position of the enemies */
+  enemyCentroid: Vector | null;
+  /** Mean distance of the units to their centroid */
+  spread: number;
+}
+
+/**
+ * Order is sent by the commander of a team to its members, which blend it into their steering.
+ * The World sends an empty order to a commander to dismiss it.
+ */
+export interface Order {
+  team: TeamColor;
+  /** "regroup", "scatter", "focus" or empty for none */
+  kind: string;
+  point: Vector | null;
+  /** ID of the enemy to focus */
+  target: string;
+}
+
+/** Move places an individual elsewhere in the same life: only its position and velocity change */
+export interface Move {
+  position: Vector | null;
+  velocity: Vector | null;
+}
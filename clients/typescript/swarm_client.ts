@@ -0,0 +1,55 @@
+// Reference client of the snapshot stream of the simulation, for the browsers and Node 18+ (fetch).
+//
+//   import { streamSnapshots } from "./swarm_client";
+//   for await (const snap of streamSnapshots("http://localhost:8080", { every: 10 })) {
+//     console.log(snap.tick, snap.redCount, snap.blueCount);
+//   }
+//
+// A page served by another origin than the simulation needs a proxy: the server sends no CORS headers.
+
+import type { WorldSnapshot } from "./simulation_types";
+
+export interface StreamOptions {
+  /** Only one snapshot every N (1 by default) */
+  every?: number;
+  /** Stops the stream */
+  signal?: AbortSignal;
+}
+
+/**
+ * Yields the WorldSnapshots of GET /snapshots until the simulation stops or the signal aborts.
+ * A slow reader misses snapshots instead of queuing them: compare the ticks to detect the gaps.
+ */
+export async function* streamSnapshots(url: string, options: StreamOptions = {}): AsyncGenerator<WorldSnapshot> {
+  const res = await fetch(`${url.replace(/\/$/, "")}/snapshots?every=${options.every ?? 1}`, { signal: options.signal });
+  if (!res.ok || res.body === null) {
+    throw new Error(`GET /snapshots failed: ${res.status} ${await res.text()}`);
+  }
+  const reader = res.body.getReader();
+  const decoder = new TextDecoder();
+  let buffered = "";
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) {
+        return;
+      }
+      buffered += decoder.decode(value, { stream: true });
+      let end: number;
+      while ((end = buffered.indexOf("\n")) >= 0) {
+        const line = buffered.slice(0, end);
+        buffered = buffered.slice(end + 1);
+        if (line.trim() !== "") {
+          yield JSON.parse(line) as WorldSnapshot;
+        }
+      }
+    }
+  } finally {
+    reader.releaseLock();
+  }
+}
+
+/** Returns the tick of a snapshot as a number (a JSON string, like every 64-bit integer) */
+export function tickOf(snap: WorldSnapshot): number {
+  return Number(snap.tick);
+}
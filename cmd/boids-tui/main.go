@@ -0,0 +1,138 @@
+// Command boids-tui animates the flock in the terminal with ASCII characters.
+// It runs the rules of package engine without Ebiten, so the flocking can be shown
+// where there is no OpenGL: containers, CI sandboxes, remote shells.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/engine"
+)
+
+// ANSI escape sequences
+const (
+	clearScreen = "\x1b[2J"
+	cursorHome  = "\x1b[H"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	red         = "\x1b[31m"
+	blue        = "\x1b[34m"
+	reset       = "\x1b[0m"
+)
+
+func main() {
+	cols := flag.Int("cols", 100, "width of the animation, in characters")
+	rows := flag.Int("rows", 32, "height of the animation, in lines")
+	numBlue := flag.Int("blue", 150, "number of boids")
+	numRed := flag.Int("red", 0, "number of red hunters chasing the flock")
+	fps := flag.Float64("fps", 20, "frames per second")
+	frames := flag.Int("frames", 0, "stop after this many frames (0 runs until interrupted)")
+	seed := flag.Uint64("seed", 0, "random seed (0 picks one)")
+	color := flag.Bool("color", true, "color the teams with ANSI escape codes")
+	flag.Parse()
+	if *cols <= 0 || *rows <= 0 || *fps <= 0 {
+		log.Fatal("cols, rows and fps must be positive")
+	}
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+
+	// A character is about twice as high as wide: keep the world proportions on screen
+	params := engine.DefaultParams()
+	params.WorldWidth = float64(*cols) * 10
+	params.WorldHeight = float64(*rows) * 20
+	rng := rand.New(rand.NewPCG(*seed, 0))
+	swarm := engine.New(rand.New(rand.NewPCG(*seed, 1)))
+	for i := 0; i < *numBlue+*numRed; i++ {
+		team := pb.TeamColor_TEAM_BLUE
+		if i < *numRed {
+			team = pb.TeamColor_TEAM_RED
+		}
+		angle := rng.Float64() * 2 * math.Pi
+		swarm.Add(team, rng.Float64()*params.WorldWidth, rng.Float64()*params.WorldHeight,
+			math.Cos(angle)*params.MinSpeed, math.Sin(angle)*params.MinSpeed)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	fmt.Fprint(out, clearScreen, hideCursor)
+	defer func() {
+		fmt.Fprint(out, showCursor)
+		out.Flush()
+	}()
+
+	// Ctrl-C must give the cursor back
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *fps))
+	defer ticker.Stop()
+	for frame := 1; *frames == 0 || frame <= *frames; frame++ {
+		if _, err := swarm.Step(&params); err != nil {
+			log.Fatal(err)
+		}
+		reds, blues := swarm.Count()
+		fmt.Fprint(out, cursorHome)
+		fmt.Fprint(out, render(swarm, &params, *cols, *rows, *color))
+		fmt.Fprintf(out, "tick %d | red %d | blue %d | seed %d    \n", frame, reds, blues, *seed)
+		out.Flush()
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			return
+		}
+	}
+}
+
+// render draws the swarm in a box of cols x rows characters: every boid is an arrow
+// pointing where it flies, a cell holding several boids shows the last one
+func render(s *engine.Swarm, p *engine.Params, cols, rows int, color bool) string {
+	cells := make([]string, cols*rows)
+	for i := range cells {
+		cells[i] = " "
+	}
+	for i := 0; i < s.Len(); i++ {
+		c := min(cols-1, max(0, int(s.PosX[i]/p.WorldWidth*float64(cols))))
+		r := min(rows-1, max(0, int(s.PosY[i]/p.WorldHeight*float64(rows))))
+		glyph := arrow(s.VelX[i], s.VelY[i])
+		if s.Color[i] == pb.TeamColor_TEAM_RED {
+			glyph = "X"
+		}
+		if color {
+			clr := blue
+			if s.Color[i] == pb.TeamColor_TEAM_RED {
+				clr = red
+			}
+			glyph = clr + glyph + reset
+		}
+		cells[r*cols+c] = glyph
+	}
+
+	var b strings.Builder
+	border := "+" + strings.Repeat("-", cols) + "+\n"
+	b.WriteString(border)
+	for r := 0; r < rows; r++ {
+		b.WriteByte('|')
+		for c := 0; c < cols; c++ {
+			b.WriteString(cells[r*cols+c])
+		}
+		b.WriteString("|\n")
+	}
+	b.WriteString(border)
+	return b.String()
+}
+
+// arrow returns the character closest to the direction of the velocity (screen Y points down)
+func arrow(vx, vy float64) string {
+	const arrows = ">\\v/<\\^/"
+	octant := int(math.Round(math.Atan2(vy, vx)/(math.Pi/4))+8) % 8
+	return arrows[octant : octant+1]
+}
@@ -0,0 +1,53 @@
+// Command highlights scans a recording made with `simulation -record` and writes
+// the index of its interesting moments (conversion cascades, defenses, near-extinctions).
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+)
+
+func main() {
+	opts := replay.DefaultHighlightOptions()
+	window := flag.Uint64("window", opts.Window, "number of ticks over which population swings are measured")
+	swing := flag.Float64("swing", opts.SwingThreshold, "fraction of the population switching team within the window to be highlighted")
+	extinction := flag.Float64("extinction", opts.ExtinctionThreshold, "fraction of the population under which a team is nearly extinct")
+	out := flag.String("o", "", "output file (default: <recording>.highlights.json)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] recording\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	recording := flag.Arg(0)
+	if *out == "" {
+		*out = replay.HighlightsPath(recording)
+	}
+	opts.Window = *window
+	opts.SwingThreshold = *swing
+	opts.ExtinctionThreshold = *extinction
+
+	frames, err := replay.ReadAll(recording)
+	if errors.Is(err, replay.ErrTruncated) {
+		// A run that crashed still has its intact part to analyze
+		log.Printf("Warning: %v, analyzing the %d snapshots before the cut", err, len(frames))
+	} else if err != nil {
+		log.Fatalf("Failed to read recording: %v", err)
+	}
+	highlights := replay.DetectHighlights(replay.SamplesFromSnapshots(frames), opts)
+	for _, h := range highlights {
+		fmt.Printf("%-20s ticks %6d - %6d  %s\n", h.Kind, h.StartTick, h.EndTick, h.Description)
+	}
+	if err := replay.WriteHighlights(*out, highlights); err != nil {
+		log.Fatalf("Failed to write highlights: %v", err)
+	}
+	fmt.Printf("%d highlights written to %s\n", len(highlights), *out)
+}
@@ -0,0 +1,39 @@
+// Command pbgen generates the client types of the streaming API from pb/simulation.proto:
+// TypeScript interfaces, Python dataclasses and the Markdown reference of the schema (see clientgen).
+// Run it from the root of the repository after editing the .proto file and regenerating the Go code.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/clientgen"
+)
+
+func main() {
+	proto := flag.String("proto", "pb/simulation.proto", "source of the comments of the generated files")
+	out := flag.String("o", "clients", "output directory")
+	flag.Parse()
+
+	src, err := os.ReadFile(*proto)
+	if err != nil {
+		log.Fatalf("Cannot read the proto file: %v", err)
+	}
+	files, err := clientgen.Generate(pb.File_pb_simulation_proto, src)
+	if err != nil {
+		log.Fatalf("Generation failed: %v", err)
+	}
+	for name, data := range files {
+		path := filepath.Join(*out, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatalf("Cannot create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			log.Fatalf("Cannot write %s: %v", path, err)
+		}
+		log.Printf("Generated %s", path)
+	}
+}
@@ -0,0 +1,72 @@
+// Command sensitivity perturbs each parameter of a config ±X% around its baseline, one at a time,
+// runs seeded headless rounds of every variant and ranks the parameters by their effect on the
+// win rate and the time-to-victory.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+func main() {
+	opts := simulation.DefaultSensitivityOptions()
+	configFile := flag.String("config", "config.json", "baseline config")
+	schemaFile := flag.String("schema", "config_schema.json", "JSON schema of the config")
+	delta := flag.Float64("delta", opts.Delta, "relative perturbation of each parameter (0.1 = ±10%)")
+	rounds := flag.Int("rounds", opts.Rounds, "seeded rounds per configuration")
+	seed := flag.Uint64("seed", opts.Seed, "seed of the first round, round i uses seed+i")
+	maxTicks := flag.Uint64("max-ticks", opts.MaxTicks, "ticks after which a round ends without winner")
+	params := flag.String("params", strings.Join(simulation.SensitivityParameters, ","), "comma separated config parameters to perturb")
+	workers := flag.Int("workers", 0, "rounds run in parallel (0 = number of CPUs)")
+	engine := flag.String("engine", simulation.EngineLocal, "engine running the rounds: local (reproducible) or ecs")
+	out := flag.String("o", "", "also write the report as JSON to this file")
+	flag.Parse()
+
+	cfg, err := simulation.LoadConfig(*configFile, *schemaFile)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	// Hundreds of worlds are started: only their problems are worth logging
+	cfg.LogLevel = "error"
+	cfg.Engine = *engine
+	opts.Delta = *delta
+	opts.Rounds = *rounds
+	opts.Seed = *seed
+	opts.MaxTicks = *maxTicks
+	opts.Parameters = strings.Split(*params, ",")
+	opts.Workers = *workers
+	opts.Engine = simulation.SelectEngine(cfg, simulation.NewLocalEngine)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	fmt.Printf("Running %d rounds of %d configurations...\n", opts.Rounds, 2*len(opts.Parameters)+1)
+	report, err := simulation.AnalyzeSensitivity(ctx, cfg, opts)
+	if err != nil {
+		log.Fatalf("Sensitivity analysis failed: %v", err)
+	}
+	if err := report.WriteText(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\nDone in %s\n", time.Since(start).Round(time.Millisecond))
+
+	if *out != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*out, data, 0o644); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		fmt.Printf("Report written to %s\n", *out)
+	}
+}
@@ -1,28 +1,57 @@
+//go:build !js
+
 package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	stdLog "log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
 	"runtime/pprof"
 	"strings"
+	"syscall"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/version"
 	"github.com/tochemey/goakt/v3/actor"
 	"github.com/tochemey/goakt/v3/log"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
-	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
-	memprofile = flag.String("memprofile", "", "write memory profile to file")
+	cpuprofile   = flag.String("cpuprofile", "", "write cpu profile to file")
+	memprofile   = flag.String("memprofile", "", "write memory profile to file")
+	grpcAddr     = flag.String("grpc", "", "listen address of the gRPC snapshot streaming service (e.g. :50051), disabled when empty")
+	httpAddr     = flag.String("http", "", "listen address of the browser live view (e.g. :8080), disabled when empty")
+	watchCfg     = flag.Bool("watch", true, "reload config.json when it changes")
+	recordFile   = flag.String("record", "", "record the run to this file (a highlights index is written next to it on exit)")
+	replayFile   = flag.String("replay", "", "play back a recording made with -record instead of running a simulation")
+	diffFile     = flag.String("diff", "", "with -replay, overlay this second recording of the same seed and plot their divergence")
+	scenarioFile = flag.String("scenario", "", "stage the arrival of entities in named waves described by this JSON file (see scenarios/)")
+	eventsFile   = flag.String("events", "", "write the conversions, spawns, deaths and game over to this file as JSON lines")
+	headless     = flag.Bool("headless", false, "run without window, operated from the lab dashboard served on -http")
+	spectate     = flag.String("spectate", "", "watch the simulation streamed by the -grpc service at this address (e.g. localhost:50051) instead of running one")
+	spectateN    = flag.Int("spectate-every", 1, "with -spectate, receive one snapshot every N ticks (the view interpolates between them)")
+	// one flag per config field (-num-red, -world-width, -max-speed...), overriding config.json
+	overrides = simulation.RegisterConfigFlags(flag.CommandLine)
+)
+
+const (
+	configFile = "config.json"
+	schemaFile = "config_schema.json"
 )
 
 // ZapAdapter adapts zap.SugaredLogger to goakt.Logger interface
@@ -65,11 +94,14 @@ func main() {
 
 	ctx := context.Background()
 	// Load Config
-	cfg, err := simulation.LoadConfig("config.json", "config_schema.json")
+	cfg, err := simulation.LoadConfig(configFile, schemaFile)
 	if err != nil {
 		// Fallback to basic logging if config fails
 		stdLog.Fatalf("Failed to load config: %v", err)
 	}
+	if err := cfg.ApplyOverrides(overrides); err != nil {
+		stdLog.Fatalf("Invalid command line override: %v", err)
+	}
 
 	// 1. Configure Logger
 	var logger *zap.Logger
@@ -106,13 +138,32 @@ func main() {
 	adapter := &ZapAdapter{SugaredLogger: logger.Sugar()}
 
 	ebiten.SetWindowSize(int(cfg.WorldWidth), int(cfg.WorldHeight))
+	// The game follows the size of the window, the replay and the spectator are scaled
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetFullscreen(cfg.Fullscreen)
 	ebiten.SetWindowTitle("Red Virus vs Blue Flock...Convert or Be Converted 🦠🚀") // suggested by Grok 4.1 🤣🔥
 
+	if *replayFile != "" {
+		if err := runReplay(*replayFile, *diffFile, cfg, logger); err != nil {
+			stdLog.Fatalf("Replay failed: %v", err)
+		}
+		return
+	}
+	if *spectate != "" {
+		if err := runSpectator(ctx, *spectate, int32(*spectateN), cfg, logger); err != nil {
+			stdLog.Fatalf("Spectator failed: %v", err)
+		}
+		return
+	}
+
 	// 2. Start Actor System with Custom Logger
 	system, _ := actor.NewActorSystem("SwarmWorld",
 		actor.WithLogger(adapter),
 		actor.WithActorInitMaxRetries(3))
 	_ = system.Start(ctx)
+	if cores := simulation.ResolveSimCores(cfg.SimCores); cores > 0 {
+		logger.Info("Actors limited to a CPU budget", zap.Int("cores", cores), zap.Int("cpus", runtime.NumCPU()))
+	}
 
 	// 5. Initialize Sliders (UI only) - Moved back to GetNewGame or handled there?
 	// Wait, GetNewGame does everything. I just need to pass the system.
@@ -120,8 +171,154 @@ func main() {
 	// Actually, GetNewGame spawns the world too.
 	// So I should just call GetNewGame(ctx, cfg, system)
 
-	game := simulation.GetNewGame(ctx, cfg, system)
-	defer game.System.Stop(ctx)
+	// 3. Optional snapshot fan-out for external observers and recording
+	var worldOpts []simulation.WorldOption
+	var hub *simulation.SnapshotHub
+	if *grpcAddr != "" || *httpAddr != "" || *recordFile != "" || cfg.StatsFile != "" || cfg.PhysicsFile != "" {
+		hub = simulation.NewSnapshotHub()
+		worldOpts = append(worldOpts, simulation.WithSnapshotHub(hub))
+	}
+	if cfg.StatsFile != "" {
+		worldOpts = append(worldOpts, simulation.WithTickTiming())
+	}
+	if *scenarioFile != "" {
+		scenario, err := simulation.LoadScenario(*scenarioFile)
+		if err == nil {
+			err = scenario.Validate(cfg)
+		}
+		if err != nil {
+			stdLog.Fatalf("Failed to load scenario: %v", err)
+		}
+		run := simulation.NewScenarioRun(scenario)
+		run.OnWave = func(stats simulation.WaveStats) {
+			logger.Info("Wave triggered", zap.String("wave", stats.Wave), zap.Uint64("tick", stats.ActivateAt), zap.Int("entities", stats.Spawned))
+		}
+		defer func() {
+			for _, stats := range run.Waves() {
+				logger.Info("Wave summary", zap.String("wave", stats.Wave), zap.Bool("triggered", stats.Triggered),
+					zap.Int("spawned", stats.Spawned), zap.Int("active", stats.Active),
+					zap.Int("converted", stats.Converted), zap.Int("gone", stats.Gone))
+			}
+		}()
+		worldOpts = append(worldOpts, simulation.WithScenario(run))
+		logger.Info("Running scenario", zap.String("name", scenario.Name), zap.Int("groups", len(scenario.Groups)))
+	}
+	if *eventsFile != "" {
+		eventLog, err := simulation.CreateEventLog(*eventsFile)
+		if err != nil {
+			stdLog.Fatalf("Failed to start event log: %v", err)
+		}
+		// Deferred before system.Stop: closed once the world is gone
+		defer func() {
+			if err := eventLog.Close(); err != nil {
+				logger.Error("Cannot write event log", zap.Error(err))
+			}
+		}()
+		worldOpts = append(worldOpts, simulation.WithEventSink(eventLog))
+		logger.Info("Logging events", zap.String("file", *eventsFile))
+	}
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			stdLog.Fatalf("Failed to listen on %s: %v", *grpcAddr, err)
+		}
+		grpcServer := grpc.NewServer()
+		simulation.RegisterObserverServer(grpcServer, hub)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Error("gRPC server stopped", zap.Error(err))
+			}
+		}()
+		defer grpcServer.Stop()
+		logger.Info("gRPC snapshot streaming enabled", zap.String("address", lis.Addr().String()))
+	}
+
+	// Headless: the lab dashboard replaces the live view and the Ebiten client
+	var lab *simulation.Lab
+	if *headless {
+		if *httpAddr == "" {
+			stdLog.Fatal("-headless needs -http to serve the lab dashboard")
+		}
+		lab, err = simulation.NewLab(ctx, cfg, simulation.ActorEngine(system), hub, worldOpts...)
+		if err != nil {
+			stdLog.Fatalf("Failed to start the simulation: %v", err)
+		}
+		lab.SetLabel("version", version.VERSION)
+		lab.SetLabel("revision", version.REVISION)
+		if host, err := os.Hostname(); err == nil {
+			lab.SetLabel("host", host)
+		}
+	}
+
+	if *httpAddr != "" {
+		handler := simulation.NewLiveView(hub, cfg).Handler()
+		if lab != nil {
+			handler = lab.Handler()
+		}
+		httpServer := &http.Server{Addr: *httpAddr, Handler: handler}
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Live view server stopped", zap.Error(err))
+			}
+		}()
+		defer httpServer.Close()
+		if lab != nil {
+			logger.Info("Lab dashboard enabled", zap.String("address", *httpAddr))
+		} else {
+			logger.Info("Browser live view enabled", zap.String("address", *httpAddr))
+		}
+	}
+
+	if *recordFile != "" {
+		stopRecording, err := startRecording(*recordFile, hub, logger)
+		if err != nil {
+			stdLog.Fatalf("Failed to start recording: %v", err)
+		}
+		defer stopRecording()
+	}
+
+	if cfg.StatsFile != "" {
+		stopStats, err := startStats(cfg.StatsFile, hub, logger)
+		if err != nil {
+			stdLog.Fatalf("Failed to start statistics export: %v", err)
+		}
+		defer stopStats()
+	}
+
+	if cfg.PhysicsFile != "" {
+		stopPhysics, err := startPhysics(cfg.PhysicsFile, cfg.MaxSpeed, hub, logger)
+		if err != nil {
+			stdLog.Fatalf("Failed to start physics validation: %v", err)
+		}
+		defer stopPhysics()
+	}
+
+	defer system.Stop(ctx)
+	if lab != nil {
+		runHeadless(ctx, lab, logger)
+		return
+	}
+	game := simulation.GetNewGame(ctx, cfg, simulation.ActorEngine(system), worldOpts...)
+	if *watchCfg {
+		watchCtx, stopWatching := context.WithCancel(ctx)
+		defer stopWatching()
+		go func() {
+			onChange := func(cfg *simulation.Config) {
+				// Command line values keep precedence over the edited file
+				if err := cfg.ApplyOverrides(overrides); err != nil {
+					logger.Warn("Ignoring config change", zap.Error(err))
+					return
+				}
+				game.ReloadConfig(cfg)
+			}
+			err := simulation.WatchConfig(watchCtx, configFile, schemaFile, onChange, func(err error) {
+				logger.Warn("Ignoring config change", zap.Error(err))
+			})
+			if err != nil {
+				logger.Error("Config hot reload disabled", zap.Error(err))
+			}
+		}()
+	}
 	err = ebiten.RunGame(game)
 	if err != nil {
 		stdLog.Fatal(err)
@@ -140,3 +337,212 @@ func main() {
 		}
 	}
 }
+
+// runReplay plays back the recording at 'path' with the highlights found next to it
+// (or detected on the fly when the run was not closed properly), compared with 'diffPath' if set
+func runReplay(path, diffPath string, cfg *simulation.Config, logger *zap.Logger) error {
+	frames, err := loadRecording(path, logger)
+	if err != nil {
+		return err
+	}
+	highlights, err := replay.ReadHighlights(replay.HighlightsPath(path))
+	if err != nil {
+		highlights = replay.DetectHighlights(replay.SamplesFromSnapshots(frames), replay.DefaultHighlightOptions())
+	}
+	logger.Info("Replaying recording", zap.String("file", path), zap.Int("frames", len(frames)), zap.Int("highlights", len(highlights)))
+	ebiten.SetWindowTitle("Replay: " + path)
+	viewer := simulation.NewReplayViewer(frames, highlights, cfg)
+
+	if diffPath != "" {
+		other, err := loadRecording(diffPath, logger)
+		if err != nil {
+			return err
+		}
+		divergences := viewer.CompareWith(other)
+		if tick, ok := replay.FirstDivergence(divergences, 0); ok {
+			logger.Warn("Recordings diverge", zap.Uint64("tick", tick), zap.Int("comparedTicks", len(divergences)))
+		} else {
+			logger.Info("Recordings are identical", zap.Int("comparedTicks", len(divergences)))
+		}
+		ebiten.SetWindowTitle(fmt.Sprintf("Replay: %s vs %s", path, diffPath))
+	}
+	return ebiten.RunGame(viewer)
+}
+
+// runHeadless runs the lab until the process is interrupted, config.json changes are applied live
+func runHeadless(ctx context.Context, lab *simulation.Lab, logger *zap.Logger) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *watchCfg {
+		go func() {
+			onChange := func(cfg *simulation.Config) {
+				err := cfg.ApplyOverrides(overrides)
+				if err == nil {
+					err = lab.ApplyConfig(cfg)
+				}
+				if err != nil {
+					logger.Warn("Ignoring config change", zap.Error(err))
+				}
+			}
+			err := simulation.WatchConfig(ctx, configFile, schemaFile, onChange, func(err error) {
+				logger.Warn("Ignoring config change", zap.Error(err))
+			})
+			if err != nil {
+				logger.Error("Config hot reload disabled", zap.Error(err))
+			}
+		}()
+	}
+	<-ctx.Done()
+	logger.Info("Stopping the headless simulation")
+	if err := lab.Stop(context.Background()); err != nil {
+		logger.Warn("Cannot stop the world", zap.Error(err))
+	}
+}
+
+// runSpectator shows the simulation streamed by the SwarmObserver service at 'addr'
+func runSpectator(ctx context.Context, addr string, everyNTicks int32, cfg *simulation.Config, logger *zap.Logger) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	spectator := simulation.NewSpectator(cfg, simulation.DefaultInterpolationDelay)
+	go func() {
+		if err := spectator.Watch(ctx, pb.NewSwarmObserverClient(conn), everyNTicks); err != nil && ctx.Err() == nil {
+			logger.Warn("Snapshot stream ended", zap.String("addr", addr), zap.Error(err))
+		}
+	}()
+	logger.Info("Spectating", zap.String("addr", addr), zap.Int32("everyNTicks", everyNTicks))
+	ebiten.SetWindowTitle("Spectating: " + addr)
+	return ebiten.RunGame(spectator)
+}
+
+// loadRecording reads a recording, the intact part of one cut by a crash is accepted
+func loadRecording(path string, logger *zap.Logger) ([]*pb.WorldSnapshot, error) {
+	frames, err := replay.ReadAll(path)
+	if errors.Is(err, replay.ErrTruncated) {
+		logger.Warn("Recording cut by a crash, using the snapshots before the cut", zap.String("file", path), zap.Int("frames", len(frames)))
+		return frames, nil
+	}
+	return frames, err
+}
+
+// keepCrashedRecording checks the recording left at 'path' by a previous run:
+// when that run crashed, the torn snapshot is removed and the file is moved to 'path.prev'
+// instead of being overwritten, so the partial data can still be analyzed.
+func keepCrashedRecording(path string, logger *zap.Logger) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	rec, err := replay.Recover(path)
+	if err != nil {
+		return err
+	}
+	if rec.Dropped == 0 {
+		return nil
+	}
+	prev := path + ".prev"
+	if err := os.Rename(path, prev); err != nil {
+		return fmt.Errorf("cannot keep crashed recording: %w", err)
+	}
+	logger.Warn("Previous recording was cut by a crash, kept its intact part",
+		zap.String("file", prev), zap.Int("frames", rec.Frames), zap.Int64("droppedBytes", rec.Dropped))
+	return nil
+}
+
+// startRecording writes every snapshot published on the hub to 'path'.
+// The returned function stops the recording and writes the highlights index.
+func startRecording(path string, hub *simulation.SnapshotHub, logger *zap.Logger) (func(), error) {
+	if err := keepCrashedRecording(path, logger); err != nil {
+		return nil, err
+	}
+	w, err := replay.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, cancel := hub.Subscribe(256)
+	done := make(chan error, 1)
+	go func() {
+		done <- replay.Record(w, snapshots)
+	}()
+	logger.Info("Recording run", zap.String("file", path))
+
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			logger.Error("Recording failed", zap.Error(err))
+		}
+		if err := w.Close(); err != nil {
+			logger.Error("Cannot close recording", zap.Error(err))
+			return
+		}
+		frames, err := replay.ReadAll(path)
+		if err != nil {
+			logger.Error("Cannot read back recording", zap.Error(err))
+			return
+		}
+		highlights := replay.DetectHighlights(replay.SamplesFromSnapshots(frames), replay.DefaultHighlightOptions())
+		if err := replay.WriteHighlights(replay.HighlightsPath(path), highlights); err != nil {
+			logger.Error("Cannot write highlights index", zap.Error(err))
+			return
+		}
+		logger.Info("Recording saved", zap.String("file", path), zap.Int("frames", len(frames)), zap.Int("highlights", len(highlights)))
+	}, nil
+}
+
+// startStats appends the statistics of every snapshot published on the hub to the CSV file 'path'.
+// The returned function stops the export and closes the file.
+func startStats(path string, hub *simulation.SnapshotHub, logger *zap.Logger) (func(), error) {
+	w, err := replay.CreateStats(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, cancel := hub.Subscribe(256)
+	done := make(chan error, 1)
+	go func() {
+		done <- replay.RecordStats(w, snapshots)
+	}()
+	logger.Info("Exporting statistics", zap.String("file", path))
+
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			logger.Error("Statistics export failed", zap.Error(err))
+		}
+		if err := w.Close(); err != nil {
+			logger.Error("Cannot close statistics file", zap.Error(err))
+		}
+	}, nil
+}
+
+// startPhysics validates the physics of the published snapshots, exports them to 'path' and logs the
+// violations. The limits follow the max speed given at start, not its later changes.
+func startPhysics(path string, maxSpeed float64, hub *simulation.SnapshotHub, logger *zap.Logger) (func(), error) {
+	w, err := replay.CreatePhysics(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, cancel := hub.Subscribe(256)
+	validator := replay.NewPhysicsValidator(replay.DefaultPhysicsLimits(maxSpeed))
+	done := make(chan error, 1)
+	go func() {
+		done <- replay.RecordPhysics(w, validator, snapshots, func(v replay.PhysicsViolation) {
+			logger.Warn("Physics violation", zap.Uint64("tick", v.Tick), zap.String("kind", string(v.Kind)),
+				zap.Float64("value", v.Value), zap.Float64("limit", v.Limit))
+		})
+	}()
+	logger.Info("Validating physics", zap.String("file", path))
+
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			logger.Error("Physics export failed", zap.Error(err))
+		}
+		if err := w.Close(); err != nil {
+			logger.Error("Cannot close physics file", zap.Error(err))
+		}
+	}, nil
+}
@@ -0,0 +1,30 @@
+//go:build js
+
+package main
+
+import (
+	"context"
+	"fmt"
+	stdLog "log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/version"
+)
+
+// Browser build: no config file, no profiling, no network services and no actor system
+// (goakt does not build for WASM), the world runs with the local engine.
+func main() {
+	fmt.Printf("🚀 Starting App:'%s', ver:%s, BuildStamp: %s, Repo: %s\n", version.APP, version.VERSION, version.BuildStamp, version.REPOSITORY)
+
+	ctx := context.Background()
+	cfg := simulation.DefaultConfig()
+
+	ebiten.SetWindowSize(int(cfg.WorldWidth), int(cfg.WorldHeight))
+	ebiten.SetWindowTitle("Red Virus vs Blue Flock...Convert or Be Converted 🦠🚀")
+
+	game := simulation.GetNewGame(ctx, cfg, simulation.NewLocalEngine)
+	if err := ebiten.RunGame(game); err != nil {
+		stdLog.Fatal(err)
+	}
+}
@@ -0,0 +1,73 @@
+// Command sweep runs seeded headless rounds for every combination of the parameter values of a
+// sweep spec (see sweeps/aggression.json) and writes a CSV table of their outcomes: winner,
+// duration, conversions and final populations.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+func main() {
+	configFile := flag.String("config", "config.json", "config of the parameters that are not swept")
+	schemaFile := flag.String("schema", "config_schema.json", "JSON schema of the config")
+	specFile := flag.String("spec", "sweeps/aggression.json", "sweep spec: the parameters, their values, the seeds")
+	workers := flag.Int("workers", 0, "rounds run in parallel (0 = number of CPUs)")
+	engine := flag.String("engine", simulation.EngineLocal, "engine running the rounds: local (reproducible) or ecs")
+	out := flag.String("o", "", "CSV file of the results (standard output when empty)")
+	flag.Parse()
+
+	cfg, err := simulation.LoadConfig(*configFile, *schemaFile)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	spec, err := simulation.LoadSweepSpec(*specFile)
+	if err != nil {
+		log.Fatalf("Failed to load sweep spec: %v", err)
+	}
+	// Hundreds of worlds are started: only their problems are worth logging
+	cfg.LogLevel = "error"
+	cfg.Engine = *engine
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create results: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	combos := len(spec.Combinations())
+	fmt.Fprintf(os.Stderr, "Running %d rounds of %d combinations...\n", spec.Seeds, combos)
+	results, err := simulation.RunSweep(ctx, cfg, spec, simulation.SweepOptions{
+		Engine:  simulation.SelectEngine(cfg, simulation.NewLocalEngine),
+		Workers: *workers,
+		OnResult: func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d rounds", done, total)
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		log.Fatalf("Sweep failed: %v", err)
+	}
+	if err := simulation.WriteSweepCSV(w, spec, results); err != nil {
+		log.Fatalf("Failed to write results: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Done in %s\n", time.Since(start).Round(time.Millisecond))
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "Results written to %s\n", *out)
+	}
+}
@@ -0,0 +1,95 @@
+// Command tune evolves the parameters of a config toward an objective with a genetic algorithm:
+// a balanced red win rate or the longest stalemate. Every candidate plays the same seeded headless
+// rounds, the best config found can be written as JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+func main() {
+	opts := simulation.DefaultTunerOptions()
+	configFile := flag.String("config", "config.json", "baseline config")
+	schemaFile := flag.String("schema", "config_schema.json", "JSON schema of the config")
+	objective := flag.String("objective", opts.Objective, "objective: balance (red wins half of the rounds) or stalemate (longest rounds)")
+	params := flag.String("params", strings.Join(simulation.SensitivityParameters, ","), "comma separated config parameters to tune")
+	spread := flag.Float64("spread", opts.Spread, "range of each parameter around the baseline (0.5 = ±50%)")
+	population := flag.Int("population", opts.Population, "candidates per generation")
+	generations := flag.Int("generations", opts.Generations, "number of generations")
+	elite := flag.Int("elite", opts.Elite, "best candidates kept unchanged in the next generation")
+	crossover := flag.Float64("crossover", opts.CrossoverRate, "probability that a child mixes two parents")
+	mutation := flag.Float64("mutation", opts.MutationRate, "probability that a value mutates")
+	mutationScale := flag.Float64("mutation-scale", opts.MutationScale, "size of a mutation, relative to the range of the parameter")
+	rounds := flag.Int("rounds", opts.Rounds, "seeded rounds per candidate")
+	seed := flag.Uint64("seed", opts.Seed, "seed of the first round (round i uses seed+i) and of the tuner")
+	maxTicks := flag.Uint64("max-ticks", opts.MaxTicks, "ticks after which a round ends without winner")
+	workers := flag.Int("workers", 0, "rounds run in parallel (0 = number of CPUs)")
+	engine := flag.String("engine", simulation.EngineLocal, "engine running the rounds: local (reproducible) or ecs")
+	out := flag.String("o", "", "write the best config as JSON to this file")
+	flag.Parse()
+
+	cfg, err := simulation.LoadConfig(*configFile, *schemaFile)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	// Hundreds of worlds are started: only their problems are worth logging
+	logLevel, engineName := cfg.LogLevel, cfg.Engine
+	cfg.LogLevel = "error"
+	cfg.Engine = *engine
+	opts.Objective = *objective
+	opts.Parameters = strings.Split(*params, ",")
+	opts.Spread = *spread
+	opts.Population = *population
+	opts.Generations = *generations
+	opts.Elite = *elite
+	opts.CrossoverRate = *crossover
+	opts.MutationRate = *mutation
+	opts.MutationScale = *mutationScale
+	opts.Rounds = *rounds
+	opts.Seed = *seed
+	opts.MaxTicks = *maxTicks
+	opts.Workers = *workers
+	opts.Engine = simulation.SelectEngine(cfg, simulation.NewLocalEngine)
+	opts.OnGeneration = func(gen simulation.TunerGeneration) {
+		fmt.Printf("Generation %d/%d: best %.3f, mean %.3f\n", gen.Index+1, opts.Generations, gen.Best.Fitness, gen.MeanFitness)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	fmt.Printf("Tuning %d parameters toward %s: %d generations of %d candidates, %d rounds each...\n",
+		len(opts.Parameters), opts.Objective, opts.Generations, opts.Population, opts.Rounds)
+	report, err := simulation.TuneParameters(ctx, cfg, opts)
+	if err != nil {
+		log.Fatalf("Tuning failed: %v", err)
+	}
+	fmt.Println()
+	if err := report.WriteText(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\nDone in %s\n", time.Since(start).Round(time.Millisecond))
+
+	if *out != "" {
+		// The tuned config can replace config.json as is: with the log level and engine of the baseline
+		best := *report.Config
+		best.LogLevel, best.Engine = logLevel, engineName
+		data, err := json.MarshalIndent(&best, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+			log.Fatalf("Failed to write config: %v", err)
+		}
+		fmt.Printf("Best config written to %s\n", *out)
+	}
+}
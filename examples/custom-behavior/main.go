@@ -0,0 +1,75 @@
+// Command custom-behavior registers a new behavior for the blue team and compares it with the
+// default flocking: "coward" blues flee the reds they see instead of flocking with their friends.
+//
+//	go run ./examples/custom-behavior -rounds 5
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+// Coward flees the closest red it sees, and flocks like the classic boids when none is around
+type Coward struct {
+	flock simulation.ClassicBoids
+}
+
+func (c *Coward) Update(me *simulation.Entity, perception *pb.Perception, cfg *simulation.Config) {
+	var closest geometry.Vector2D
+	best := -1.0
+	for _, red := range perception.GetTargets() {
+		pos := simulation.GeomVector2DFromProto(red.GetPosition())
+		if d := me.Pos.DistanceSquaredTo(pos); best < 0 || d < best {
+			closest, best = pos, d
+		}
+	}
+	if best < 0 {
+		c.flock.Update(me, perception, cfg)
+		return
+	}
+	// Away from the red at full speed, the walls still bounce
+	away := me.Pos.Sub(closest)
+	if away.LenSqr() > 0 {
+		me.ApplyForce(away.Normalize().Mul(cfg.Aggression))
+	}
+	me.ClampVelocity(cfg.MinSpeed, cfg.MaxSpeed)
+	me.UpdatePhysics()
+	me.BounceOffWalls(cfg.WorldWidth, cfg.WorldHeight)
+}
+
+func main() {
+	rounds := flag.Int("rounds", 5, "seeded rounds per behavior")
+	maxTicks := flag.Uint64("ticks", 3000, "ticks per round")
+	flag.Parse()
+
+	// Registered behaviors are selectable by name, like the built-in ones (Config.BlueStrategy,
+	// Runner.SetStrategy, the panel of the Game)
+	simulation.RegisterBehavior("coward", pb.TeamColor_TEAM_BLUE, func() simulation.Behavior { return &Coward{} })
+
+	ctx := context.Background()
+	for _, strategy := range []string{simulation.StrategyClassicBoids, "coward"} {
+		var blues int32
+		for round := range *rounds {
+			cfg := simulation.DefaultConfig()
+			cfg.Seed = uint64(round + 1)
+			cfg.BlueStrategy = strategy
+			runner, err := simulation.NewRunner(ctx, cfg, simulation.WithEngine(simulation.NewLocalEngine))
+			if err != nil {
+				log.Fatal(err)
+			}
+			last, err := runner.Run(ctx, *maxTicks, nil)
+			_ = runner.Stop(ctx)
+			if err != nil {
+				log.Fatal(err)
+			}
+			blues += last.GetBlueCount()
+		}
+		fmt.Printf("%-14s %6.1f blues left on average\n", strategy, float64(blues)/float64(*rounds))
+	}
+}
@@ -0,0 +1,87 @@
+// Command metrics extracts time series from a run as CSV: the populations from the snapshots,
+// the conversions from the events of the world, and the mean speed of each team from a tick hook.
+//
+//	go run ./examples/metrics -ticks 1200 -every 60 > metrics.csv
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+func main() {
+	maxTicks := flag.Uint64("ticks", 1200, "ticks to run")
+	every := flag.Uint64("every", 60, "ticks between two rows")
+	seed := flag.Uint64("seed", 1, "seed of the run")
+	flag.Parse()
+
+	// The hook and the sink run in the world goroutine, between two steps of the Runner:
+	// the values they write are read by the onStep callback without locking
+	var speed [2]float64 // Mean speed of the reds and of the blues
+	hook := func(view *simulation.WorldView, _ *simulation.CommandQueue) {
+		var sum [2]float64
+		var n [2]int
+		view.Each(func(e simulation.Entity) bool {
+			team := 1
+			if e.Color == pb.TeamColor_TEAM_RED {
+				team = 0
+			}
+			sum[team] += e.Vel.Len()
+			n[team]++
+			return true
+		})
+		for team := range speed {
+			speed[team] = 0
+			if n[team] > 0 {
+				speed[team] = sum[team] / float64(n[team])
+			}
+		}
+	}
+	var defenses, infections int
+	sink := simulation.EventSinkFunc(func(e simulation.Event) {
+		if e.Kind != simulation.EventConversion || e.Attacker == "" {
+			return
+		}
+		if e.ID == e.Attacker {
+			defenses++
+		} else {
+			infections++
+		}
+	})
+
+	ctx := context.Background()
+	cfg := simulation.DefaultConfig()
+	cfg.Seed = *seed
+	runner, err := simulation.NewRunner(ctx, cfg,
+		simulation.WithEngine(simulation.NewLocalEngine),
+		simulation.WithWorldOptions(simulation.WithTickHook(hook), simulation.WithEventSink(sink)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer runner.Stop(ctx)
+
+	out := csv.NewWriter(os.Stdout)
+	defer out.Flush()
+	_ = out.Write([]string{"tick", "red", "blue", "infections", "defenses", "redSpeed", "blueSpeed"})
+	_, err = runner.Run(ctx, *maxTicks, func(snap *pb.WorldSnapshot) bool {
+		if snap.GetTick()%*every == 0 || snap.GetIsGameOver() {
+			_ = out.Write([]string{
+				strconv.FormatUint(snap.GetTick(), 10),
+				strconv.Itoa(int(snap.GetRedCount())), strconv.Itoa(int(snap.GetBlueCount())),
+				strconv.Itoa(infections), strconv.Itoa(defenses),
+				strconv.FormatFloat(speed[0], 'f', 3, 64), strconv.FormatFloat(speed[1], 'f', 3, 64),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,68 @@
+// Command sweep plays seeded rounds for several values of one parameter and prints the win rate
+// of each value: the smallest parameter sweep written with the Runner API.
+//
+//	go run ./examples/sweep -param aggression -values 0.4,0.8,1.2,1.6 -rounds 5
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+func main() {
+	param := flag.String("param", "aggression", "JSON name of the Config field to sweep")
+	values := flag.String("values", "0.4,0.8,1.2,1.6", "comma-separated values of the parameter")
+	rounds := flag.Int("rounds", 5, "seeded rounds per value, the same seeds for every value")
+	maxTicks := flag.Uint64("ticks", 3000, "ticks after which a round is a draw")
+	flag.Parse()
+
+	ctx := context.Background()
+	fmt.Printf("%-12s %8s %8s %8s %12s\n", *param, "red", "blue", "draw", "mean ticks")
+	for _, value := range strings.Split(*values, ",") {
+		var red, blue, draw int
+		var ticks uint64
+		for round := range *rounds {
+			last, err := play(ctx, *param, value, uint64(round+1), *maxTicks)
+			if err != nil {
+				log.Fatalf("%s=%s: %v", *param, value, err)
+			}
+			ticks += last.GetTick()
+			switch {
+			case !last.GetIsGameOver():
+				draw++
+			case last.GetRedCount() > 0:
+				red++
+			default:
+				blue++
+			}
+		}
+		fmt.Printf("%-12s %8d %8d %8d %12.0f\n", value, red, blue, draw, float64(ticks)/float64(*rounds))
+	}
+}
+
+// play runs one round with the parameter set to 'value' and returns its last snapshot.
+// The local engine makes a seeded round reproducible.
+func play(ctx context.Context, param, value string, seed, maxTicks uint64) (*pb.WorldSnapshot, error) {
+	cfg := simulation.DefaultConfig()
+	cfg.Seed = seed
+	// Parsed and validated like the command-line flags of the simulation
+	overrides := simulation.RegisterConfigFlags(flag.NewFlagSet("sweep", flag.ContinueOnError))
+	if err := overrides.Set(param, value); err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyOverrides(overrides); err != nil {
+		return nil, err
+	}
+	runner, err := simulation.NewRunner(ctx, cfg, simulation.WithEngine(simulation.NewLocalEngine))
+	if err != nil {
+		return nil, err
+	}
+	defer runner.Stop(ctx)
+	return runner.Run(ctx, maxTicks, nil)
+}
@@ -27,6 +27,7 @@ const (
 	TeamColor_TEAM_UNSPECIFIED TeamColor = 0
 	TeamColor_TEAM_RED         TeamColor = 1
 	TeamColor_TEAM_BLUE        TeamColor = 2
+	TeamColor_TEAM_NEUTRAL     TeamColor = 3 // Civilians of no team, captured by the teams touching them
 )
 
 // Enum value maps for TeamColor.
@@ -35,11 +36,13 @@ var (
 		0: "TEAM_UNSPECIFIED",
 		1: "TEAM_RED",
 		2: "TEAM_BLUE",
+		3: "TEAM_NEUTRAL",
 	}
 	TeamColor_value = map[string]int32{
 		"TEAM_UNSPECIFIED": 0,
 		"TEAM_RED":         1,
 		"TEAM_BLUE":        2,
+		"TEAM_NEUTRAL":     3,
 	}
 )
 
@@ -73,7 +76,7 @@ func (TeamColor) EnumDescriptor() ([]byte, []int) {
 // Sent by the World to tell actors to update their state
 type Tick struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	DeltaTime     int64                  `protobuf:"varint,1,opt,name=delta_time,json=deltaTime,proto3" json:"delta_time,omitempty"` // Time elapsed since last frame
+	DeltaTime     int64                  `protobuf:"varint,1,opt,name=delta_time,json=deltaTime,proto3" json:"delta_time,omitempty"` // Simulation time covered by the tick in nanoseconds, 0 for a nominal tick (1/60 s)
 	Context       *Perception            `protobuf:"bytes,2,opt,name=context,proto3" json:"context,omitempty"`                       // Optional field
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -219,6 +222,9 @@ type ActorState struct {
 	Color         TeamColor              `protobuf:"varint,2,opt,name=color,proto3,enum=pb.TeamColor" json:"color,omitempty"` // "RED" or "BLUE"
 	Position      *Vector                `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
 	Velocity      *Vector                `protobuf:"bytes,4,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	Generation    uint32                 `protobuf:"varint,5,opt,name=generation,proto3" json:"generation,omitempty"` // Incremented each time a pooled ID is recycled, stale states are ignored
+	Hunger        float64                `protobuf:"fixed64,6,opt,name=hunger,proto3" json:"hunger,omitempty"`        // Energy a blue lacks, from 0 (fed) to 1 (starving)
+	Fatigue       float64                `protobuf:"fixed64,7,opt,name=fatigue,proto3" json:"fatigue,omitempty"`      // Stamina a red spent chasing, from 0 (rested) to 1 (exhausted)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -281,11 +287,33 @@ func (x *ActorState) GetVelocity() *Vector {
 	return nil
 }
 
+func (x *ActorState) GetGeneration() uint32 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *ActorState) GetHunger() float64 {
+	if x != nil {
+		return x.Hunger
+	}
+	return 0
+}
+
+func (x *ActorState) GetFatigue() float64 {
+	if x != nil {
+		return x.Fatigue
+	}
+	return 0
+}
+
 // Perception is sent by the world to tell an actor what neighbors are visible
 type Perception struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Targets       []*ActorState          `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
 	Friends       []*ActorState          `protobuf:"bytes,2,rep,name=friends,proto3" json:"friends,omitempty"`
+	Force         *Vector                `protobuf:"bytes,3,opt,name=force,proto3" json:"force,omitempty"` // Transient force pushing the actor during this tick, e.g. the scare tool of the Game
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -334,10 +362,18 @@ func (x *Perception) GetFriends() []*ActorState {
 	return nil
 }
 
+func (x *Perception) GetForce() *Vector {
+	if x != nil {
+		return x.Force
+	}
+	return nil
+}
+
 // Convert message is the command to switch teams
 type Convert struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TargetColor   TeamColor              `protobuf:"varint,1,opt,name=target_color,json=targetColor,proto3,enum=pb.TeamColor" json:"target_color,omitempty"`
+	Strategy      string                 `protobuf:"bytes,2,opt,name=strategy,proto3" json:"strategy,omitempty"` // Name of the behavior used by the new team (empty = keep default)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -379,6 +415,119 @@ func (x *Convert) GetTargetColor() TeamColor {
 	return TeamColor_TEAM_UNSPECIFIED
 }
 
+func (x *Convert) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+// Respawn brings a parked (despawned) individual back to life with a new state
+type Respawn struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         *ActorState            `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Strategy      string                 `protobuf:"bytes,2,opt,name=strategy,proto3" json:"strategy,omitempty"` // Name of the behavior of its team
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Respawn) Reset() {
+	*x = Respawn{}
+	mi := &file_pb_simulation_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Respawn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Respawn) ProtoMessage() {}
+
+func (x *Respawn) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Respawn.ProtoReflect.Descriptor instead.
+func (*Respawn) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Respawn) GetState() *ActorState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *Respawn) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+// SetStrategy switches the named behavior used by every member of a team
+type SetStrategy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          TeamColor              `protobuf:"varint,1,opt,name=team,proto3,enum=pb.TeamColor" json:"team,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStrategy) Reset() {
+	*x = SetStrategy{}
+	mi := &file_pb_simulation_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStrategy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStrategy) ProtoMessage() {}
+
+func (x *SetStrategy) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStrategy.ProtoReflect.Descriptor instead.
+func (*SetStrategy) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetStrategy) GetTeam() TeamColor {
+	if x != nil {
+		return x.Team
+	}
+	return TeamColor_TEAM_UNSPECIFIED
+}
+
+func (x *SetStrategy) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
 // Sent by Individual -> World
 type ReportStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -389,7 +538,7 @@ type ReportStatus struct {
 
 func (x *ReportStatus) Reset() {
 	*x = ReportStatus{}
-	mi := &file_pb_simulation_proto_msgTypes[6]
+	mi := &file_pb_simulation_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -401,7 +550,7 @@ func (x *ReportStatus) String() string {
 func (*ReportStatus) ProtoMessage() {}
 
 func (x *ReportStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_pb_simulation_proto_msgTypes[6]
+	mi := &file_pb_simulation_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -414,7 +563,7 @@ func (x *ReportStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportStatus.ProtoReflect.Descriptor instead.
 func (*ReportStatus) Descriptor() ([]byte, []int) {
-	return file_pb_simulation_proto_rawDescGZIP(), []int{6}
+	return file_pb_simulation_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ReportStatus) GetState() *ActorState {
@@ -426,19 +575,26 @@ func (x *ReportStatus) GetState() *ActorState {
 
 // Sent by World -> UI (via channel, or actor if UI is an actor)
 type WorldSnapshot struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Actors        []*ActorState          `protobuf:"bytes,1,rep,name=actors,proto3" json:"actors,omitempty"`
-	RedCount      int32                  `protobuf:"varint,2,opt,name=red_count,json=redCount,proto3" json:"red_count,omitempty"`
-	BlueCount     int32                  `protobuf:"varint,3,opt,name=blue_count,json=blueCount,proto3" json:"blue_count,omitempty"`
-	IsGameOver    bool                   `protobuf:"varint,4,opt,name=is_game_over,json=isGameOver,proto3" json:"is_game_over,omitempty"`
-	Winner        string                 `protobuf:"bytes,5,opt,name=winner,proto3" json:"winner,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Actors     []*ActorState          `protobuf:"bytes,1,rep,name=actors,proto3" json:"actors,omitempty"`
+	RedCount   int32                  `protobuf:"varint,2,opt,name=red_count,json=redCount,proto3" json:"red_count,omitempty"`
+	BlueCount  int32                  `protobuf:"varint,3,opt,name=blue_count,json=blueCount,proto3" json:"blue_count,omitempty"`
+	IsGameOver bool                   `protobuf:"varint,4,opt,name=is_game_over,json=isGameOver,proto3" json:"is_game_over,omitempty"`
+	Winner     string                 `protobuf:"bytes,5,opt,name=winner,proto3" json:"winner,omitempty"`
+	Tick       uint64                 `protobuf:"varint,6,opt,name=tick,proto3" json:"tick,omitempty"` // Simulation step that produced this snapshot
+	// Entities outside the viewport (see SetViewport): counted in red_count and blue_count, not listed in actors
+	OffscreenRed  int32  `protobuf:"varint,7,opt,name=offscreen_red,json=offscreenRed,proto3" json:"offscreen_red,omitempty"`
+	OffscreenBlue int32  `protobuf:"varint,8,opt,name=offscreen_blue,json=offscreenBlue,proto3" json:"offscreen_blue,omitempty"`
+	Conversions   uint32 `protobuf:"varint,9,opt,name=conversions,proto3" json:"conversions,omitempty"`                        // Team switches ordered during the tick
+	TickDuration  int64  `protobuf:"varint,10,opt,name=tick_duration,json=tickDuration,proto3" json:"tick_duration,omitempty"` // Wall time the engine spent on the tick, in nanoseconds (0 unless enabled)
+	NeutralCount  int32  `protobuf:"varint,11,opt,name=neutral_count,json=neutralCount,proto3" json:"neutral_count,omitempty"` // Civilians of no team, never listed in the offscreen counts
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WorldSnapshot) Reset() {
 	*x = WorldSnapshot{}
-	mi := &file_pb_simulation_proto_msgTypes[7]
+	mi := &file_pb_simulation_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -450,7 +606,7 @@ func (x *WorldSnapshot) String() string {
 func (*WorldSnapshot) ProtoMessage() {}
 
 func (x *WorldSnapshot) ProtoReflect() protoreflect.Message {
-	mi := &file_pb_simulation_proto_msgTypes[7]
+	mi := &file_pb_simulation_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -463,7 +619,7 @@ func (x *WorldSnapshot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WorldSnapshot.ProtoReflect.Descriptor instead.
 func (*WorldSnapshot) Descriptor() ([]byte, []int) {
-	return file_pb_simulation_proto_rawDescGZIP(), []int{7}
+	return file_pb_simulation_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *WorldSnapshot) GetActors() []*ActorState {
@@ -501,6 +657,118 @@ func (x *WorldSnapshot) GetWinner() string {
 	return ""
 }
 
+func (x *WorldSnapshot) GetTick() uint64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *WorldSnapshot) GetOffscreenRed() int32 {
+	if x != nil {
+		return x.OffscreenRed
+	}
+	return 0
+}
+
+func (x *WorldSnapshot) GetOffscreenBlue() int32 {
+	if x != nil {
+		return x.OffscreenBlue
+	}
+	return 0
+}
+
+func (x *WorldSnapshot) GetConversions() uint32 {
+	if x != nil {
+		return x.Conversions
+	}
+	return 0
+}
+
+func (x *WorldSnapshot) GetTickDuration() int64 {
+	if x != nil {
+		return x.TickDuration
+	}
+	return 0
+}
+
+func (x *WorldSnapshot) GetNeutralCount() int32 {
+	if x != nil {
+		return x.NeutralCount
+	}
+	return 0
+}
+
+// SetViewport tells the World the area of the world visible in the UI: the snapshots sent
+// to the UI then list only the entities inside it. An empty area (max <= min) lists them all.
+type SetViewport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinX          float64                `protobuf:"fixed64,1,opt,name=min_x,json=minX,proto3" json:"min_x,omitempty"`
+	MinY          float64                `protobuf:"fixed64,2,opt,name=min_y,json=minY,proto3" json:"min_y,omitempty"`
+	MaxX          float64                `protobuf:"fixed64,3,opt,name=max_x,json=maxX,proto3" json:"max_x,omitempty"`
+	MaxY          float64                `protobuf:"fixed64,4,opt,name=max_y,json=maxY,proto3" json:"max_y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetViewport) Reset() {
+	*x = SetViewport{}
+	mi := &file_pb_simulation_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetViewport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetViewport) ProtoMessage() {}
+
+func (x *SetViewport) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetViewport.ProtoReflect.Descriptor instead.
+func (*SetViewport) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetViewport) GetMinX() float64 {
+	if x != nil {
+		return x.MinX
+	}
+	return 0
+}
+
+func (x *SetViewport) GetMinY() float64 {
+	if x != nil {
+		return x.MinY
+	}
+	return 0
+}
+
+func (x *SetViewport) GetMaxX() float64 {
+	if x != nil {
+		return x.MaxX
+	}
+	return 0
+}
+
+func (x *SetViewport) GetMaxY() float64 {
+	if x != nil {
+		return x.MaxY
+	}
+	return 0
+}
+
 // UpdateConfig allows runtime updates to all configuration parameters
 type UpdateConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -531,7 +799,7 @@ type UpdateConfig struct {
 
 func (x *UpdateConfig) Reset() {
 	*x = UpdateConfig{}
-	mi := &file_pb_simulation_proto_msgTypes[8]
+	mi := &file_pb_simulation_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -543,7 +811,7 @@ func (x *UpdateConfig) String() string {
 func (*UpdateConfig) ProtoMessage() {}
 
 func (x *UpdateConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_pb_simulation_proto_msgTypes[8]
+	mi := &file_pb_simulation_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -556,7 +824,7 @@ func (x *UpdateConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateConfig.ProtoReflect.Descriptor instead.
 func (*UpdateConfig) Descriptor() ([]byte, []int) {
-	return file_pb_simulation_proto_rawDescGZIP(), []int{8}
+	return file_pb_simulation_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *UpdateConfig) GetDetectionRadius() float64 {
@@ -671,6 +939,337 @@ func (x *UpdateConfig) GetDisplayDefenseCircle() bool {
 	return false
 }
 
+// StreamRequest configures a snapshot stream
+type StreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EveryNTicks   int32                  `protobuf:"varint,1,opt,name=every_n_ticks,json=everyNTicks,proto3" json:"every_n_ticks,omitempty"` // Only send one snapshot every N ticks (0 or 1 = every snapshot)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	mi := &file_pb_simulation_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StreamRequest) GetEveryNTicks() int32 {
+	if x != nil {
+		return x.EveryNTicks
+	}
+	return 0
+}
+
+// Unit is an entity as the commander of a team sees it
+type Unit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Position      *Vector                `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
+	Velocity      *Vector                `protobuf:"bytes,3,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	Friends       int32                  `protobuf:"varint,4,opt,name=friends,proto3" json:"friends,omitempty"` // Teammates within the defense radius
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Unit) Reset() {
+	*x = Unit{}
+	mi := &file_pb_simulation_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Unit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Unit) ProtoMessage() {}
+
+func (x *Unit) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Unit.ProtoReflect.Descriptor instead.
+func (*Unit) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Unit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Unit) GetPosition() *Vector {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Unit) GetVelocity() *Vector {
+	if x != nil {
+		return x.Velocity
+	}
+	return nil
+}
+
+func (x *Unit) GetFriends() int32 {
+	if x != nil {
+		return x.Friends
+	}
+	return 0
+}
+
+// TeamBrief is sent by the World to the commander of a team at every tick: the aggregated view of
+// the world it orders its team from
+type TeamBrief struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          TeamColor              `protobuf:"varint,1,opt,name=team,proto3,enum=pb.TeamColor" json:"team,omitempty"`
+	Tick          uint64                 `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Units         []*Unit                `protobuf:"bytes,3,rep,name=units,proto3" json:"units,omitempty"`                                      // Members of the team, in arrival order
+	Enemies       []*Unit                `protobuf:"bytes,4,rep,name=enemies,proto3" json:"enemies,omitempty"`                                  // Members of the other team, in arrival order
+	Centroid      *Vector                `protobuf:"bytes,5,opt,name=centroid,proto3" json:"centroid,omitempty"`                                // Mean position of the units
+	EnemyCentroid *Vector                `protobuf:"bytes,6,opt,name=enemy_centroid,json=enemyCentroid,proto3" json:"enemy_centroid,omitempty"` // Mean position of the enemies
+	Spread        float64                `protobuf:"fixed64,7,opt,name=spread,proto3" json:"spread,omitempty"`                                  // Mean distance of the units to their centroid
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeamBrief) Reset() {
+	*x = TeamBrief{}
+	mi := &file_pb_simulation_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeamBrief) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamBrief) ProtoMessage() {}
+
+func (x *TeamBrief) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamBrief.ProtoReflect.Descriptor instead.
+func (*TeamBrief) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TeamBrief) GetTeam() TeamColor {
+	if x != nil {
+		return x.Team
+	}
+	return TeamColor_TEAM_UNSPECIFIED
+}
+
+func (x *TeamBrief) GetTick() uint64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *TeamBrief) GetUnits() []*Unit {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *TeamBrief) GetEnemies() []*Unit {
+	if x != nil {
+		return x.Enemies
+	}
+	return nil
+}
+
+func (x *TeamBrief) GetCentroid() *Vector {
+	if x != nil {
+		return x.Centroid
+	}
+	return nil
+}
+
+func (x *TeamBrief) GetEnemyCentroid() *Vector {
+	if x != nil {
+		return x.EnemyCentroid
+	}
+	return nil
+}
+
+func (x *TeamBrief) GetSpread() float64 {
+	if x != nil {
+		return x.Spread
+	}
+	return 0
+}
+
+// Order is sent by the commander of a team to its members, which blend it into their steering.
+// The World sends an empty order to a commander to dismiss it.
+type Order struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          TeamColor              `protobuf:"varint,1,opt,name=team,proto3,enum=pb.TeamColor" json:"team,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // "regroup", "scatter", "focus" or empty for none
+	Point         *Vector                `protobuf:"bytes,3,opt,name=point,proto3" json:"point,omitempty"`
+	Target        string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"` // ID of the enemy to focus
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_pb_simulation_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Order) GetTeam() TeamColor {
+	if x != nil {
+		return x.Team
+	}
+	return TeamColor_TEAM_UNSPECIFIED
+}
+
+func (x *Order) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Order) GetPoint() *Vector {
+	if x != nil {
+		return x.Point
+	}
+	return nil
+}
+
+func (x *Order) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+// Move places an individual elsewhere in the same life: only its position and velocity change
+type Move struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      *Vector                `protobuf:"bytes,1,opt,name=position,proto3" json:"position,omitempty"`
+	Velocity      *Vector                `protobuf:"bytes,2,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Move) Reset() {
+	*x = Move{}
+	mi := &file_pb_simulation_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Move) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Move) ProtoMessage() {}
+
+func (x *Move) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_simulation_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Move.ProtoReflect.Descriptor instead.
+func (*Move) Descriptor() ([]byte, []int) {
+	return file_pb_simulation_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Move) GetPosition() *Vector {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Move) GetVelocity() *Vector {
+	if x != nil {
+		return x.Velocity
+	}
+	return nil
+}
+
 var File_pb_simulation_proto protoreflect.FileDescriptor
 
 const file_pb_simulation_proto_rawDesc = "" +
@@ -684,7 +1283,7 @@ const file_pb_simulation_proto_rawDesc = "" +
 	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
 	"\x01y\x18\x02 \x01(\x01R\x01y\"\n" +
 	"\n" +
-	"\bGetState\"\x91\x01\n" +
+	"\bGetState\"\xe3\x01\n" +
 	"\n" +
 	"ActorState\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
@@ -692,15 +1291,29 @@ const file_pb_simulation_proto_rawDesc = "" +
 	"\bposition\x18\x03 \x01(\v2\n" +
 	".pb.VectorR\bposition\x12&\n" +
 	"\bvelocity\x18\x04 \x01(\v2\n" +
-	".pb.VectorR\bvelocity\"`\n" +
+	".pb.VectorR\bvelocity\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x05 \x01(\rR\n" +
+	"generation\x12\x16\n" +
+	"\x06hunger\x18\x06 \x01(\x01R\x06hunger\x12\x18\n" +
+	"\afatigue\x18\a \x01(\x01R\afatigue\"\x82\x01\n" +
 	"\n" +
 	"Perception\x12(\n" +
 	"\atargets\x18\x01 \x03(\v2\x0e.pb.ActorStateR\atargets\x12(\n" +
-	"\afriends\x18\x02 \x03(\v2\x0e.pb.ActorStateR\afriends\";\n" +
+	"\afriends\x18\x02 \x03(\v2\x0e.pb.ActorStateR\afriends\x12 \n" +
+	"\x05force\x18\x03 \x01(\v2\n" +
+	".pb.VectorR\x05force\"W\n" +
 	"\aConvert\x120\n" +
-	"\ftarget_color\x18\x01 \x01(\x0e2\r.pb.TeamColorR\vtargetColor\"4\n" +
+	"\ftarget_color\x18\x01 \x01(\x0e2\r.pb.TeamColorR\vtargetColor\x12\x1a\n" +
+	"\bstrategy\x18\x02 \x01(\tR\bstrategy\"K\n" +
+	"\aRespawn\x12$\n" +
+	"\x05state\x18\x01 \x01(\v2\x0e.pb.ActorStateR\x05state\x12\x1a\n" +
+	"\bstrategy\x18\x02 \x01(\tR\bstrategy\"D\n" +
+	"\vSetStrategy\x12!\n" +
+	"\x04team\x18\x01 \x01(\x0e2\r.pb.TeamColorR\x04team\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"4\n" +
 	"\fReportStatus\x12$\n" +
-	"\x05state\x18\x01 \x01(\v2\x0e.pb.ActorStateR\x05state\"\xad\x01\n" +
+	"\x05state\x18\x01 \x01(\v2\x0e.pb.ActorStateR\x05state\"\xf9\x02\n" +
 	"\rWorldSnapshot\x12&\n" +
 	"\x06actors\x18\x01 \x03(\v2\x0e.pb.ActorStateR\x06actors\x12\x1b\n" +
 	"\tred_count\x18\x02 \x01(\x05R\bredCount\x12\x1d\n" +
@@ -708,7 +1321,19 @@ const file_pb_simulation_proto_rawDesc = "" +
 	"blue_count\x18\x03 \x01(\x05R\tblueCount\x12 \n" +
 	"\fis_game_over\x18\x04 \x01(\bR\n" +
 	"isGameOver\x12\x16\n" +
-	"\x06winner\x18\x05 \x01(\tR\x06winner\"\x89\x05\n" +
+	"\x06winner\x18\x05 \x01(\tR\x06winner\x12\x12\n" +
+	"\x04tick\x18\x06 \x01(\x04R\x04tick\x12#\n" +
+	"\roffscreen_red\x18\a \x01(\x05R\foffscreenRed\x12%\n" +
+	"\x0eoffscreen_blue\x18\b \x01(\x05R\roffscreenBlue\x12 \n" +
+	"\vconversions\x18\t \x01(\rR\vconversions\x12#\n" +
+	"\rtick_duration\x18\n" +
+	" \x01(\x03R\ftickDuration\x12#\n" +
+	"\rneutral_count\x18\v \x01(\x05R\fneutralCount\"a\n" +
+	"\vSetViewport\x12\x13\n" +
+	"\x05min_x\x18\x01 \x01(\x01R\x04minX\x12\x13\n" +
+	"\x05min_y\x18\x02 \x01(\x01R\x04minY\x12\x13\n" +
+	"\x05max_x\x18\x03 \x01(\x01R\x04maxX\x12\x13\n" +
+	"\x05max_y\x18\x04 \x01(\x01R\x04maxY\"\x89\x05\n" +
 	"\fUpdateConfig\x12)\n" +
 	"\x10detection_radius\x18\x01 \x01(\x01R\x0fdetectionRadius\x12%\n" +
 	"\x0edefense_radius\x18\x02 \x01(\x01R\rdefenseRadius\x12%\n" +
@@ -729,11 +1354,44 @@ const file_pb_simulation_proto_rawDesc = "" +
 	"\x10num_red_at_start\x18\r \x01(\x05R\rnumRedAtStart\x12)\n" +
 	"\x11num_blue_at_start\x18\x0e \x01(\x05R\x0enumBlueAtStart\x128\n" +
 	"\x18display_detection_circle\x18\x0f \x01(\bR\x16displayDetectionCircle\x124\n" +
-	"\x16display_defense_circle\x18\x10 \x01(\bR\x14displayDefenseCircle*>\n" +
+	"\x16display_defense_circle\x18\x10 \x01(\bR\x14displayDefenseCircle\"3\n" +
+	"\rStreamRequest\x12\"\n" +
+	"\revery_n_ticks\x18\x01 \x01(\x05R\veveryNTicks\"\x80\x01\n" +
+	"\x04Unit\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\bposition\x18\x02 \x01(\v2\n" +
+	".pb.VectorR\bposition\x12&\n" +
+	"\bvelocity\x18\x03 \x01(\v2\n" +
+	".pb.VectorR\bvelocity\x12\x18\n" +
+	"\afriends\x18\x04 \x01(\x05R\afriends\"\xf9\x01\n" +
+	"\tTeamBrief\x12!\n" +
+	"\x04team\x18\x01 \x01(\x0e2\r.pb.TeamColorR\x04team\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\x04R\x04tick\x12\x1e\n" +
+	"\x05units\x18\x03 \x03(\v2\b.pb.UnitR\x05units\x12\"\n" +
+	"\aenemies\x18\x04 \x03(\v2\b.pb.UnitR\aenemies\x12&\n" +
+	"\bcentroid\x18\x05 \x01(\v2\n" +
+	".pb.VectorR\bcentroid\x121\n" +
+	"\x0eenemy_centroid\x18\x06 \x01(\v2\n" +
+	".pb.VectorR\renemyCentroid\x12\x16\n" +
+	"\x06spread\x18\a \x01(\x01R\x06spread\"x\n" +
+	"\x05Order\x12!\n" +
+	"\x04team\x18\x01 \x01(\x0e2\r.pb.TeamColorR\x04team\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12 \n" +
+	"\x05point\x18\x03 \x01(\v2\n" +
+	".pb.VectorR\x05point\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\"V\n" +
+	"\x04Move\x12&\n" +
+	"\bposition\x18\x01 \x01(\v2\n" +
+	".pb.VectorR\bposition\x12&\n" +
+	"\bvelocity\x18\x02 \x01(\v2\n" +
+	".pb.VectorR\bvelocity*P\n" +
 	"\tTeamColor\x12\x14\n" +
 	"\x10TEAM_UNSPECIFIED\x10\x00\x12\f\n" +
 	"\bTEAM_RED\x10\x01\x12\r\n" +
-	"\tTEAM_BLUE\x10\x02B5Z3github.com/lao-tseu-is-alive/go-swarm-simulation/pbb\x06proto3"
+	"\tTEAM_BLUE\x10\x02\x12\x10\n" +
+	"\fTEAM_NEUTRAL\x10\x032J\n" +
+	"\rSwarmObserver\x129\n" +
+	"\x0fStreamSnapshots\x12\x11.pb.StreamRequest\x1a\x11.pb.WorldSnapshot0\x01B5Z3github.com/lao-tseu-is-alive/go-swarm-simulation/pbb\x06proto3"
 
 var (
 	file_pb_simulation_proto_rawDescOnce sync.Once
@@ -748,7 +1406,7 @@ func file_pb_simulation_proto_rawDescGZIP() []byte {
 }
 
 var file_pb_simulation_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pb_simulation_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_pb_simulation_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_pb_simulation_proto_goTypes = []any{
 	(TeamColor)(0),        // 0: pb.TeamColor
 	(*Tick)(nil),          // 1: pb.Tick
@@ -757,25 +1415,49 @@ var file_pb_simulation_proto_goTypes = []any{
 	(*ActorState)(nil),    // 4: pb.ActorState
 	(*Perception)(nil),    // 5: pb.Perception
 	(*Convert)(nil),       // 6: pb.Convert
-	(*ReportStatus)(nil),  // 7: pb.ReportStatus
-	(*WorldSnapshot)(nil), // 8: pb.WorldSnapshot
-	(*UpdateConfig)(nil),  // 9: pb.UpdateConfig
+	(*Respawn)(nil),       // 7: pb.Respawn
+	(*SetStrategy)(nil),   // 8: pb.SetStrategy
+	(*ReportStatus)(nil),  // 9: pb.ReportStatus
+	(*WorldSnapshot)(nil), // 10: pb.WorldSnapshot
+	(*SetViewport)(nil),   // 11: pb.SetViewport
+	(*UpdateConfig)(nil),  // 12: pb.UpdateConfig
+	(*StreamRequest)(nil), // 13: pb.StreamRequest
+	(*Unit)(nil),          // 14: pb.Unit
+	(*TeamBrief)(nil),     // 15: pb.TeamBrief
+	(*Order)(nil),         // 16: pb.Order
+	(*Move)(nil),          // 17: pb.Move
 }
 var file_pb_simulation_proto_depIdxs = []int32{
-	5, // 0: pb.Tick.context:type_name -> pb.Perception
-	0, // 1: pb.ActorState.color:type_name -> pb.TeamColor
-	2, // 2: pb.ActorState.position:type_name -> pb.Vector
-	2, // 3: pb.ActorState.velocity:type_name -> pb.Vector
-	4, // 4: pb.Perception.targets:type_name -> pb.ActorState
-	4, // 5: pb.Perception.friends:type_name -> pb.ActorState
-	0, // 6: pb.Convert.target_color:type_name -> pb.TeamColor
-	4, // 7: pb.ReportStatus.state:type_name -> pb.ActorState
-	4, // 8: pb.WorldSnapshot.actors:type_name -> pb.ActorState
-	9, // [9:9] is the sub-list for method output_type
-	9, // [9:9] is the sub-list for method input_type
-	9, // [9:9] is the sub-list for extension type_name
-	9, // [9:9] is the sub-list for extension extendee
-	0, // [0:9] is the sub-list for field type_name
+	5,  // 0: pb.Tick.context:type_name -> pb.Perception
+	0,  // 1: pb.ActorState.color:type_name -> pb.TeamColor
+	2,  // 2: pb.ActorState.position:type_name -> pb.Vector
+	2,  // 3: pb.ActorState.velocity:type_name -> pb.Vector
+	4,  // 4: pb.Perception.targets:type_name -> pb.ActorState
+	4,  // 5: pb.Perception.friends:type_name -> pb.ActorState
+	2,  // 6: pb.Perception.force:type_name -> pb.Vector
+	0,  // 7: pb.Convert.target_color:type_name -> pb.TeamColor
+	4,  // 8: pb.Respawn.state:type_name -> pb.ActorState
+	0,  // 9: pb.SetStrategy.team:type_name -> pb.TeamColor
+	4,  // 10: pb.ReportStatus.state:type_name -> pb.ActorState
+	4,  // 11: pb.WorldSnapshot.actors:type_name -> pb.ActorState
+	2,  // 12: pb.Unit.position:type_name -> pb.Vector
+	2,  // 13: pb.Unit.velocity:type_name -> pb.Vector
+	0,  // 14: pb.TeamBrief.team:type_name -> pb.TeamColor
+	14, // 15: pb.TeamBrief.units:type_name -> pb.Unit
+	14, // 16: pb.TeamBrief.enemies:type_name -> pb.Unit
+	2,  // 17: pb.TeamBrief.centroid:type_name -> pb.Vector
+	2,  // 18: pb.TeamBrief.enemy_centroid:type_name -> pb.Vector
+	0,  // 19: pb.Order.team:type_name -> pb.TeamColor
+	2,  // 20: pb.Order.point:type_name -> pb.Vector
+	2,  // 21: pb.Move.position:type_name -> pb.Vector
+	2,  // 22: pb.Move.velocity:type_name -> pb.Vector
+	13, // 23: pb.SwarmObserver.StreamSnapshots:input_type -> pb.StreamRequest
+	10, // 24: pb.SwarmObserver.StreamSnapshots:output_type -> pb.WorldSnapshot
+	24, // [24:25] is the sub-list for method output_type
+	23, // [23:24] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_pb_simulation_proto_init() }
@@ -789,9 +1471,9 @@ func file_pb_simulation_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pb_simulation_proto_rawDesc), len(file_pb_simulation_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   9,
+			NumMessages:   17,
 			NumExtensions: 0,
-			NumServices:   0,
+			NumServices:   1,
 		},
 		GoTypes:           file_pb_simulation_proto_goTypes,
 		DependencyIndexes: file_pb_simulation_proto_depIdxs,
@@ -0,0 +1,132 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.32.1
+// source: pb/simulation.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SwarmObserver_StreamSnapshots_FullMethodName = "/pb.SwarmObserver/StreamSnapshots"
+)
+
+// SwarmObserverClient is the client API for SwarmObserver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SwarmObserver lets external processes or machines observe the simulation
+type SwarmObserverClient interface {
+	// StreamSnapshots sends the WorldSnapshots produced by the world,
+	// snapshots are dropped (not queued) when the client is too slow.
+	StreamSnapshots(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WorldSnapshot], error)
+}
+
+type swarmObserverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSwarmObserverClient(cc grpc.ClientConnInterface) SwarmObserverClient {
+	return &swarmObserverClient{cc}
+}
+
+func (c *swarmObserverClient) StreamSnapshots(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WorldSnapshot], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SwarmObserver_ServiceDesc.Streams[0], SwarmObserver_StreamSnapshots_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRequest, WorldSnapshot]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SwarmObserver_StreamSnapshotsClient = grpc.ServerStreamingClient[WorldSnapshot]
+
+// SwarmObserverServer is the server API for SwarmObserver service.
+// All implementations must embed UnimplementedSwarmObserverServer
+// for forward compatibility.
+//
+// SwarmObserver lets external processes or machines observe the simulation
+type SwarmObserverServer interface {
+	// StreamSnapshots sends the WorldSnapshots produced by the world,
+	// snapshots are dropped (not queued) when the client is too slow.
+	StreamSnapshots(*StreamRequest, grpc.ServerStreamingServer[WorldSnapshot]) error
+	mustEmbedUnimplementedSwarmObserverServer()
+}
+
+// UnimplementedSwarmObserverServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSwarmObserverServer struct{}
+
+func (UnimplementedSwarmObserverServer) StreamSnapshots(*StreamRequest, grpc.ServerStreamingServer[WorldSnapshot]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSnapshots not implemented")
+}
+func (UnimplementedSwarmObserverServer) mustEmbedUnimplementedSwarmObserverServer() {}
+func (UnimplementedSwarmObserverServer) testEmbeddedByValue()                       {}
+
+// UnsafeSwarmObserverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwarmObserverServer will
+// result in compilation errors.
+type UnsafeSwarmObserverServer interface {
+	mustEmbedUnimplementedSwarmObserverServer()
+}
+
+func RegisterSwarmObserverServer(s grpc.ServiceRegistrar, srv SwarmObserverServer) {
+	// If the following call pancis, it indicates UnimplementedSwarmObserverServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SwarmObserver_ServiceDesc, srv)
+}
+
+func _SwarmObserver_StreamSnapshots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwarmObserverServer).StreamSnapshots(m, &grpc.GenericServerStream[StreamRequest, WorldSnapshot]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SwarmObserver_StreamSnapshotsServer = grpc.ServerStreamingServer[WorldSnapshot]
+
+// SwarmObserver_ServiceDesc is the grpc.ServiceDesc for SwarmObserver service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SwarmObserver_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.SwarmObserver",
+	HandlerType: (*SwarmObserverServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSnapshots",
+			Handler:       _SwarmObserver_StreamSnapshots_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pb/simulation.proto",
+}
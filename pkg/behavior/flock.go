@@ -0,0 +1,46 @@
+package behavior
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Neighborhood accumulates the friends of a boid for Flock
+type Neighborhood struct {
+	// Separation sums the offsets from the friends too close, pointing away from them
+	Separation geometry.Vector2D
+	// SumPos and SumVel sum the positions and velocities of the Count friends in sight
+	SumPos, SumVel geometry.Vector2D
+	Count          float64
+}
+
+// Separate pushes the boid at 'pos' away from a friend at 'other' (within the protected range)
+func (n *Neighborhood) Separate(pos, other geometry.Vector2D) {
+	n.Separation = n.Separation.Add(pos.Sub(other))
+}
+
+// Add accounts a friend in sight for the alignment and the cohesion
+func (n *Neighborhood) Add(other, otherVel geometry.Vector2D) {
+	n.SumPos = n.SumPos.Add(other)
+	n.SumVel = n.SumVel.Add(otherVel)
+	n.Count++
+}
+
+// FlockWeights are the weights of the boids rules
+type FlockWeights struct {
+	Separation, Alignment, Cohesion float64
+}
+
+// Flock returns Reynolds' boids force: away from the friends too close (separation), toward their
+// mean velocity (alignment) and their center (cohesion)
+func Flock(pos, vel geometry.Vector2D, n *Neighborhood, w FlockWeights) geometry.Vector2D {
+	if n.Count == 0 {
+		return Blend(Weighted{n.Separation, w.Separation})
+	}
+	avgVel, _ := n.SumVel.Div(n.Count)
+	avgPos, _ := n.SumPos.Div(n.Count)
+	return Blend(
+		Weighted{n.Separation, w.Separation},
+		Weighted{avgVel.Sub(vel), w.Alignment},
+		Weighted{Attract(pos, avgPos), w.Cohesion},
+	)
+}
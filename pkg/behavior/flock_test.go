@@ -0,0 +1,31 @@
+package behavior
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestFlock(t *testing.T) {
+	pos, vel := geometry.Vector2D{X: 100, Y: 100}, geometry.Vector2D{X: 1}
+	var n Neighborhood
+	// A friend too close on the right, another one in sight below
+	n.Separate(pos, geometry.Vector2D{X: 104, Y: 100})
+	n.Add(geometry.Vector2D{X: 104, Y: 100}, geometry.Vector2D{X: 1})
+	n.Add(geometry.Vector2D{X: 100, Y: 140}, geometry.Vector2D{X: 1, Y: 2})
+
+	if got := Flock(pos, vel, &n, FlockWeights{Separation: 1}); got != (geometry.Vector2D{X: -4}) {
+		t.Errorf("Separation = %v, want {-4, 0}", got)
+	}
+	if got := Flock(pos, vel, &n, FlockWeights{Alignment: 1}); got != (geometry.Vector2D{Y: 1}) {
+		t.Errorf("Alignment = %v, want {0, 1}", got)
+	}
+	if got := Flock(pos, vel, &n, FlockWeights{Cohesion: 1}); got != (geometry.Vector2D{X: 2, Y: 20}) {
+		t.Errorf("Cohesion = %v, want {2, 20}", got)
+	}
+
+	// Alone: no force
+	if got := Flock(pos, vel, &Neighborhood{}, FlockWeights{1, 1, 1}); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no force without friends, got %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package behavior
+
+import (
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Shapes of a formation
+const (
+	FormationV      = "v"      // Apex ahead, two wings trailing at 45°
+	FormationLine   = "line"   // Abreast, perpendicular to the heading
+	FormationCircle = "circle" // Evenly spaced on a ring
+	FormationGrid   = "grid"   // Rows of a square block
+)
+
+// FormationShapes lists the shapes known by FormationSlots
+var FormationShapes = []string{FormationV, FormationLine, FormationCircle, FormationGrid}
+
+// FormationSlots returns the offsets of the 'n' slots of a formation of 'shape', neighbors
+// 'spacing' apart, in the frame of the formation: X ahead, Y to the right. They are centered on
+// their mean, so that a formation placed on the centroid of its members does not drift.
+// It returns nil for an unknown shape.
+func FormationSlots(shape string, n int, spacing float64) []geometry.Vector2D {
+	if n <= 0 {
+		return nil
+	}
+	slots := make([]geometry.Vector2D, n)
+	switch shape {
+	case FormationV:
+		for i := 1; i < n; i++ {
+			rank, side := float64((i+1)/2), 1.0
+			if i%2 == 0 {
+				side = -1
+			}
+			slots[i] = geometry.Vector2D{X: -rank * spacing, Y: side * rank * spacing}
+		}
+	case FormationLine:
+		for i := range slots {
+			slots[i] = geometry.Vector2D{Y: (float64(i) - float64(n-1)/2) * spacing}
+		}
+	case FormationCircle:
+		if n == 1 {
+			break
+		}
+		// Neighbors 'spacing' apart along the ring
+		radius := spacing / (2 * math.Sin(math.Pi/float64(n)))
+		for i := range slots {
+			angle := 2 * math.Pi * float64(i) / float64(n)
+			slots[i] = geometry.Vector2D{X: radius * math.Cos(angle), Y: radius * math.Sin(angle)}
+		}
+	case FormationGrid:
+		cols := int(math.Ceil(math.Sqrt(float64(n))))
+		for i := range slots {
+			slots[i] = geometry.Vector2D{X: -float64(i/cols) * spacing, Y: float64(i%cols) * spacing}
+		}
+	default:
+		return nil
+	}
+	var mean geometry.Vector2D
+	for _, s := range slots {
+		mean = mean.Add(s)
+	}
+	mean = mean.Mul(1 / float64(n))
+	for i := range slots {
+		slots[i] = slots[i].Sub(mean)
+	}
+	return slots
+}
+
+// FormationPlace returns where 'offset' of a formation lies in the world, the formation being
+// centered on 'anchor' and heading along the unit vector 'heading'
+func FormationPlace(anchor, heading, offset geometry.Vector2D) geometry.Vector2D {
+	right := geometry.Vector2D{X: -heading.Y, Y: heading.X}
+	return anchor.Add(heading.Mul(offset.X)).Add(right.Mul(offset.Y))
+}
@@ -0,0 +1,53 @@
+package behavior
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestFormationSlots(t *testing.T) {
+	for _, shape := range FormationShapes {
+		slots := FormationSlots(shape, 7, 20)
+		if len(slots) != 7 {
+			t.Fatalf("%s: expected 7 slots, got %d", shape, len(slots))
+		}
+		var mean geometry.Vector2D
+		for i, s := range slots {
+			mean = mean.Add(s)
+			for _, other := range slots[:i] {
+				if s.DistanceTo(other) < 19.9 {
+					t.Errorf("%s: slots %v and %v closer than the spacing", shape, s, other)
+				}
+			}
+		}
+		if mean.Len() > 1e-9 {
+			t.Errorf("%s: expected the slots centered, mean %v", shape, mean)
+		}
+	}
+
+	// The apex of the V leads, its wings trail on both sides
+	v := FormationSlots(FormationV, 3, 20)
+	if v[0].X <= v[1].X || v[1].Y*v[2].Y >= 0 {
+		t.Errorf("Unexpected V %v", v)
+	}
+	// The line is abreast
+	for _, s := range FormationSlots(FormationLine, 4, 20) {
+		if s.X != 0 {
+			t.Errorf("Expected the line perpendicular to the heading, got %v", s)
+		}
+	}
+	if FormationSlots("star", 4, 20) != nil || FormationSlots(FormationV, 0, 20) != nil {
+		t.Error("Expected no slot for an unknown shape or no member")
+	}
+}
+
+func TestFormationPlace(t *testing.T) {
+	anchor := geometry.Vector2D{X: 100, Y: 100}
+	// Heading down the screen: ahead is +Y, the right is -X
+	got := FormationPlace(anchor, geometry.Vector2D{Y: 1}, geometry.Vector2D{X: 10, Y: 5})
+	if math.Abs(got.X-95) > 1e-9 || math.Abs(got.Y-110) > 1e-9 {
+		t.Errorf("Expected (95,110), got %v", got)
+	}
+}
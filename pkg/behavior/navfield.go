@@ -0,0 +1,159 @@
+package behavior
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// NavField is a flow field: a direction per cell of a grid covering the world, that the entities
+// sample to know where to go (see Follow). It is built toward a target around the obstacles
+// (TargetField) or drawn by hand with arrows (ParseNavField). A NavField is read-only once built,
+// safe to sample from every entity concurrently.
+type NavField struct {
+	Grid
+	// Dirs holds the unit direction of every cell, row by row, null where there is nowhere to go
+	Dirs []geometry.Vector2D
+}
+
+// NewNavField returns a field without direction covering a world of 'width' by 'height'
+func NewNavField(width, height, cellSize float64) *NavField {
+	g := NewGrid(width, height, cellSize, nil)
+	return &NavField{Grid: *g, Dirs: make([]geometry.Vector2D, g.Cols*g.Rows)}
+}
+
+// Dir returns the direction of a cell, null outside the field
+func (f *NavField) Dir(col, row int) geometry.Vector2D {
+	if !f.In(col, row) {
+		return geometry.Vector2D{}
+	}
+	return f.Dirs[row*f.Cols+col]
+}
+
+// Set sets the direction of a cell, normalized
+func (f *NavField) Set(col, row int, dir geometry.Vector2D) {
+	if f.In(col, row) {
+		f.Dirs[row*f.Cols+col] = dir.Normalize()
+	}
+}
+
+// Sample returns the direction of the cell containing 'pos', null outside the field
+func (f *NavField) Sample(pos geometry.Vector2D) geometry.Vector2D {
+	col, row, ok := f.Cell(pos)
+	if !ok {
+		return geometry.Vector2D{}
+	}
+	return f.Dirs[row*f.Cols+col]
+}
+
+// Follow returns the force turning 'vel' into the velocity along the field at 'pos' at 'maxSpeed',
+// no force where the field has no direction
+func Follow(pos, vel geometry.Vector2D, field *NavField, maxSpeed float64) geometry.Vector2D {
+	dir := field.Sample(pos)
+	if dir.LenSqr() == 0 {
+		return geometry.Vector2D{}
+	}
+	return dir.Mul(maxSpeed).Sub(vel)
+}
+
+// TargetField returns the field leading every cell to 'target' by the shortest path around the
+// obstacles (Dijkstra over the free cells of the Grid, 8 moves, no corner cutting). The blocked
+// cells and those which cannot reach the target have no direction, the cell of the target points to it.
+func TargetField(width, height, cellSize float64, target geometry.Vector2D, obstacles []Obstacle) *NavField {
+	g := NewGrid(width, height, cellSize, obstacles)
+	f := &NavField{Grid: *g, Dirs: make([]geometry.Vector2D, g.Cols*g.Rows)}
+	tc, tr, ok := g.Cell(target)
+	if !ok {
+		return f
+	}
+	cost := make([]float64, len(f.Dirs))
+	for i := range cost {
+		cost[i] = math.Inf(1)
+	}
+	start := tr*g.Cols + tc
+	cost[start] = 0
+	queue := &cellQueue{{index: start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(cellItem)
+		if item.cost > cost[item.index] {
+			continue // Already reached cheaper
+		}
+		col, row := item.index%g.Cols, item.index/g.Cols
+		for _, n := range gridMoves {
+			if !g.legal(col, row, n.dc, n.dr) {
+				continue
+			}
+			c, r := col+n.dc, row+n.dr
+			if next := item.cost + n.cost; next < cost[r*g.Cols+c] {
+				cost[r*g.Cols+c] = next
+				heap.Push(queue, cellItem{index: r*g.Cols + c, cost: next})
+			}
+		}
+	}
+
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			i := row*g.Cols + col
+			if !g.Free(col, row) || math.IsInf(cost[i], 1) {
+				continue
+			}
+			if i == start {
+				f.Set(col, row, target.Sub(g.Center(col, row)))
+				continue
+			}
+			// Toward the cheapest neighbor reached through a legal move
+			best, bestDir := cost[i], geometry.Vector2D{}
+			for _, n := range gridMoves {
+				if !g.legal(col, row, n.dc, n.dr) {
+					continue
+				}
+				c, r := col+n.dc, row+n.dr
+				if cost[r*g.Cols+c] < best {
+					best, bestDir = cost[r*g.Cols+c], geometry.Vector2D{X: float64(n.dc), Y: float64(n.dr)}
+				}
+			}
+			f.Set(col, row, bestDir)
+		}
+	}
+	return f
+}
+
+// navArrows are the directions of the characters of a hand-drawn field: the arrows, and the
+// digits of the numeric keypad for the diagonals (8 is up, 3 is down-right). The other characters
+// (e.g. '.' or ' ') leave the cell without direction.
+var navArrows = map[rune]geometry.Vector2D{
+	'>': {X: 1}, '<': {X: -1}, '^': {Y: -1}, 'v': {Y: 1},
+	'6': {X: 1}, '4': {X: -1}, '8': {Y: -1}, '2': {Y: 1},
+	'9': {X: 1, Y: -1}, '7': {X: -1, Y: -1}, '3': {X: 1, Y: 1}, '1': {X: -1, Y: 1},
+}
+
+// ParseNavField builds a field drawn by hand: one line per row of cells, one character per cell
+// (see navArrows), stretched over a world of 'width' by 'height'. The cells are square, sized after
+// the longest line, the rows below the last line have no direction. E.g. a loop in the top half of
+// a world of 1000 by 800:
+//
+//	>>>>>>>>>v
+//	^........v
+//	^........v
+//	^<<<<<<<<<
+func ParseNavField(src string, width, height float64) (*NavField, error) {
+	lines := strings.Split(strings.TrimRight(src, "\n"), "\n")
+	cols := 0
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+		cols = max(cols, len([]rune(lines[i])))
+	}
+	if cols == 0 {
+		return nil, fmt.Errorf("empty navigation field")
+	}
+	f := NewNavField(width, height, width/float64(cols))
+	for row, line := range lines {
+		for col, r := range []rune(line) {
+			f.Set(col, row, navArrows[r])
+		}
+	}
+	return f, nil
+}
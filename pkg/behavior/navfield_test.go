@@ -0,0 +1,78 @@
+package behavior
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestTargetField(t *testing.T) {
+	// A wall of discs across the middle column, open at the bottom
+	var wall []Obstacle
+	for y := 5.0; y < 80; y += 10 {
+		wall = append(wall, Obstacle{Center: geometry.Vector2D{X: 55, Y: y}, Radius: 6})
+	}
+	target := geometry.Vector2D{X: 95, Y: 5}
+	f := TargetField(100, 100, 10, target, wall)
+	if f.Cols != 10 || f.Rows != 10 {
+		t.Fatalf("Unexpected size %dx%d", f.Cols, f.Rows)
+	}
+	if got := f.Dir(5, 3); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no direction inside the wall, got %v", got)
+	}
+	// Left of the wall the way goes down toward the gap, not straight into the wall
+	if got := f.Dir(4, 0); got.Y <= 0 {
+		t.Errorf("Expected the top left to head down to the gap, got %v", got)
+	}
+	// Right of the wall the way goes straight to the target
+	if got := f.Dir(9, 5); got != (geometry.Vector2D{Y: -1}) {
+		t.Errorf("Expected the right side to head up to the target, got %v", got)
+	}
+	for row := 0; row < f.Rows; row++ {
+		for col := 0; col < f.Cols; col++ {
+			if d := f.Dir(col, row); d.LenSqr() != 0 && math.Abs(d.Len()-1) > 1e-9 {
+				t.Errorf("Expected unit directions, got %v at %d,%d", d, col, row)
+			}
+		}
+	}
+	if got := TargetField(100, 100, 10, geometry.Vector2D{X: -5}, nil).Sample(geometry.Vector2D{X: 50}); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no direction toward a target outside the world, got %v", got)
+	}
+}
+
+func TestParseNavField(t *testing.T) {
+	f, err := ParseNavField(">v\r\n.3\n", 200, 300)
+	if err != nil {
+		t.Fatalf("ParseNavField() error = %v", err)
+	}
+	if f.CellSize != 100 || f.Cols != 2 || f.Rows != 3 {
+		t.Fatalf("Unexpected field %v %dx%d", f.CellSize, f.Cols, f.Rows)
+	}
+	if got := f.Sample(geometry.Vector2D{X: 50, Y: 50}); got != (geometry.Vector2D{X: 1}) {
+		t.Errorf("Expected '>' to head right, got %v", got)
+	}
+	if got := f.Sample(geometry.Vector2D{X: 150, Y: 150}); math.Abs(got.X-math.Sqrt2/2) > 1e-9 || math.Abs(got.Y-math.Sqrt2/2) > 1e-9 {
+		t.Errorf("Expected '3' to head down-right, got %v", got)
+	}
+	if got := f.Sample(geometry.Vector2D{X: 50, Y: 150}); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected '.' to have no direction, got %v", got)
+	}
+	if got := f.Sample(geometry.Vector2D{X: 50, Y: 250}); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no direction below the last line, got %v", got)
+	}
+	if _, err := ParseNavField("\n", 200, 300); err == nil {
+		t.Error("Expected an empty field to be rejected")
+	}
+}
+
+func TestFollow(t *testing.T) {
+	f, _ := ParseNavField(">.", 200, 100)
+	vel := geometry.Vector2D{Y: 1}
+	if got := Follow(geometry.Vector2D{X: 50, Y: 50}, vel, f, 3); got != (geometry.Vector2D{X: 3, Y: -1}) {
+		t.Errorf("Expected the force toward the field velocity, got %v", got)
+	}
+	if got := Follow(geometry.Vector2D{X: 150, Y: 50}, vel, f, 3); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no force without direction, got %v", got)
+	}
+}
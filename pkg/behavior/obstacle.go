@@ -0,0 +1,88 @@
+package behavior
+
+import (
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// whiskerAngle is the angle between the heading and the two side whiskers of AvoidObstacles
+const whiskerAngle = math.Pi / 6
+
+// Obstacle is a solid disc the entities steer around
+type Obstacle struct {
+	Center geometry.Vector2D
+	Radius float64
+}
+
+// Contains reports whether 'pos' is inside the obstacle
+func (o Obstacle) Contains(pos geometry.Vector2D) bool {
+	return pos.DistanceSquaredTo(o.Center) < o.Radius*o.Radius
+}
+
+// Hit returns the distance along the ray from 'from' in the unit direction 'dir' where it enters the
+// obstacle, false when it does not within 'length'. A ray starting inside hits at 0.
+func (o Obstacle) Hit(from, dir geometry.Vector2D, length float64) (float64, bool) {
+	m := from.Sub(o.Center)
+	b := m.Dot(dir)
+	c := m.LenSqr() - o.Radius*o.Radius
+	if c > 0 && b > 0 {
+		return 0, false // Outside and heading away
+	}
+	disc := b*b - c
+	if disc < 0 {
+		return 0, false
+	}
+	t := max(0, -b-math.Sqrt(disc))
+	return t, t <= length
+}
+
+// AvoidObstacles returns the force turning an entity away from the obstacles in its way. It casts
+// three whiskers: one along the velocity reaching 'lookahead' ahead, two at ±30° half as long. The
+// closest hit steers the entity sideways, away from the center of the obstacle, up to the current
+// speed for an imminent hit; an entity inside an obstacle is pushed out. A null velocity or
+// lookahead avoids nothing.
+func AvoidObstacles(pos, vel geometry.Vector2D, obstacles []Obstacle, lookahead float64) geometry.Vector2D {
+	speed := vel.Len()
+	if speed == 0 || lookahead <= 0 || len(obstacles) == 0 {
+		return geometry.Vector2D{}
+	}
+	heading := vel.Mul(1 / speed)
+	whiskers := [3]struct {
+		dir    geometry.Vector2D
+		length float64
+	}{
+		{heading, lookahead},
+		{heading.Rotate(whiskerAngle), lookahead / 2},
+		{heading.Rotate(-whiskerAngle), lookahead / 2},
+	}
+
+	var closest *Obstacle
+	urgency := 0.0
+	for i := range obstacles {
+		o := &obstacles[i]
+		if o.Contains(pos) {
+			out := pos.Sub(o.Center)
+			if out.LenSqr() == 0 {
+				out = heading.Mul(-1)
+			}
+			return out.Normalize().Mul(speed)
+		}
+		for _, w := range whiskers {
+			if t, ok := o.Hit(pos, w.dir, w.length); ok && 1-t/w.length > urgency {
+				closest, urgency = o, 1-t/w.length
+			}
+		}
+	}
+	if closest == nil {
+		return geometry.Vector2D{}
+	}
+	// Sideways, on the side of the heading away from the center: a quarter turn when it is dead ahead
+	toCenter := closest.Center.Sub(pos)
+	lateral := toCenter.Sub(heading.Mul(toCenter.Dot(heading)))
+	away := lateral.Mul(-1).Normalize()
+	if away.LenSqr() == 0 {
+		away = heading.Rotate(math.Pi / 2)
+	}
+	return away.Mul(urgency * speed)
+}
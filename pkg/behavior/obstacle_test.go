@@ -0,0 +1,62 @@
+package behavior
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestObstacleHit(t *testing.T) {
+	o := Obstacle{Center: geometry.Vector2D{X: 100}, Radius: 10}
+	right := geometry.Vector2D{X: 1}
+	if d, ok := o.Hit(geometry.Vector2D{}, right, 200); !ok || math.Abs(d-90) > 1e-9 {
+		t.Errorf("Expected a hit at 90, got %v %v", d, ok)
+	}
+	if _, ok := o.Hit(geometry.Vector2D{}, right, 50); ok {
+		t.Error("Expected no hit beyond the length of the ray")
+	}
+	if _, ok := o.Hit(geometry.Vector2D{}, geometry.Vector2D{Y: 1}, 200); ok {
+		t.Error("Expected no hit for a ray passing by")
+	}
+	if _, ok := o.Hit(geometry.Vector2D{X: 200}, right, 200); ok {
+		t.Error("Expected no hit for a ray heading away")
+	}
+	if d, ok := o.Hit(geometry.Vector2D{X: 95}, right, 1); !ok || d != 0 {
+		t.Errorf("Expected a ray starting inside to hit at 0, got %v %v", d, ok)
+	}
+}
+
+func TestAvoidObstacles(t *testing.T) {
+	obstacles := []Obstacle{{Center: geometry.Vector2D{X: 100, Y: 5}, Radius: 20}}
+	vel := geometry.Vector2D{X: 2}
+
+	// Heading to an obstacle slightly below the path: steers up, harder when closer
+	far := AvoidObstacles(geometry.Vector2D{X: 40}, vel, obstacles, 60)
+	near := AvoidObstacles(geometry.Vector2D{X: 65}, vel, obstacles, 60)
+	if far.Y >= 0 || near.Y >= 0 || math.Abs(far.X) > 1e-9 {
+		t.Errorf("Expected sideways forces away from the obstacle, got %v and %v", far, near)
+	}
+	if near.Len() <= far.Len() || near.Len() > vel.Len()+1e-9 {
+		t.Errorf("Expected a stronger force closer, capped to the speed: %v then %v", far, near)
+	}
+
+	// Out of reach, or not moving: no force
+	if got := AvoidObstacles(geometry.Vector2D{X: 0}, vel, obstacles, 60); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no force out of reach, got %v", got)
+	}
+	if got := AvoidObstacles(geometry.Vector2D{X: 65}, geometry.Vector2D{}, obstacles, 60); got != (geometry.Vector2D{}) {
+		t.Errorf("Expected no force without velocity, got %v", got)
+	}
+
+	// Inside: pushed out at full speed
+	if got := AvoidObstacles(geometry.Vector2D{X: 100, Y: 15}, vel, obstacles, 60); math.Abs(got.X) > 1e-9 || math.Abs(got.Y-2) > 1e-9 {
+		t.Errorf("Expected to be pushed out downward, got %v", got)
+	}
+
+	// Dead ahead: still turns
+	ahead := []Obstacle{{Center: geometry.Vector2D{X: 50}, Radius: 10}}
+	if got := AvoidObstacles(geometry.Vector2D{}, vel, ahead, 60); math.Abs(got.Y) < 1e-9 {
+		t.Errorf("Expected a turn for an obstacle dead ahead, got %v", got)
+	}
+}
@@ -0,0 +1,201 @@
+package behavior
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Grid divides a world into square cells to find ways around its obstacles: a cell is blocked when
+// its center is inside an obstacle. TargetField and FindPath move between its free cells, 8 moves
+// without cutting the corners. A Grid is read-only once built, safe to share between the entities.
+type Grid struct {
+	CellSize   float64
+	Cols, Rows int
+	Obstacles  []Obstacle
+	// Blocked tells the blocked cells row by row, nil when there is no obstacle
+	Blocked []bool
+}
+
+// NewGrid returns the grid of cells of 'cellSize' covering a world of 'width' by 'height'
+func NewGrid(width, height, cellSize float64, obstacles []Obstacle) *Grid {
+	cellSize = max(cellSize, 1)
+	g := &Grid{
+		CellSize:  cellSize,
+		Cols:      max(int(math.Ceil(width/cellSize)), 1),
+		Rows:      max(int(math.Ceil(height/cellSize)), 1),
+		Obstacles: obstacles,
+	}
+	if len(obstacles) == 0 {
+		return g
+	}
+	g.Blocked = make([]bool, g.Cols*g.Rows)
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			center := g.Center(col, row)
+			for _, o := range obstacles {
+				if o.Contains(center) {
+					g.Blocked[row*g.Cols+col] = true
+					break
+				}
+			}
+		}
+	}
+	return g
+}
+
+// In reports whether a cell is inside the grid
+func (g *Grid) In(col, row int) bool {
+	return col >= 0 && col < g.Cols && row >= 0 && row < g.Rows
+}
+
+// Cell returns the column and the row of the cell containing 'pos', false outside the grid
+func (g *Grid) Cell(pos geometry.Vector2D) (int, int, bool) {
+	col, row := int(math.Floor(pos.X/g.CellSize)), int(math.Floor(pos.Y/g.CellSize))
+	return col, row, g.In(col, row)
+}
+
+// Center returns the center of a cell
+func (g *Grid) Center(col, row int) geometry.Vector2D {
+	return geometry.Vector2D{X: (float64(col) + 0.5) * g.CellSize, Y: (float64(row) + 0.5) * g.CellSize}
+}
+
+// Free reports whether a cell is inside the grid and not blocked
+func (g *Grid) Free(col, row int) bool {
+	return g.In(col, row) && (g.Blocked == nil || !g.Blocked[row*g.Cols+col])
+}
+
+// legal reports whether the move by 'dc', 'dr' from a cell leads to a free cell: a diagonal move
+// needs both cells it brushes past to be free
+func (g *Grid) legal(col, row, dc, dr int) bool {
+	return g.Free(col+dc, row+dr) && (dc == 0 || dr == 0 || g.Free(col+dc, row) && g.Free(col, row+dr))
+}
+
+// Clear reports whether the segment from 'from' to 'to' keeps at least 'clearance' away from every
+// obstacle, i.e. an entity can go straight
+func (g *Grid) Clear(from, to geometry.Vector2D, clearance float64) bool {
+	length := to.DistanceTo(from)
+	if length == 0 {
+		return true
+	}
+	dir := to.Sub(from).Mul(1 / length)
+	for _, o := range g.Obstacles {
+		inflated := Obstacle{Center: o.Center, Radius: o.Radius + clearance}
+		if _, ok := inflated.Hit(from, dir, length); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// gridMoves are the 8 moves between cells and their cost
+var gridMoves = [8]struct {
+	dc, dr int
+	cost   float64
+}{
+	{1, 0, 1}, {-1, 0, 1}, {0, 1, 1}, {0, -1, 1},
+	{1, 1, math.Sqrt2}, {1, -1, math.Sqrt2}, {-1, 1, math.Sqrt2}, {-1, -1, math.Sqrt2},
+}
+
+// FindPath returns the waypoints leading from 'from' to 'to' around the obstacles, ending with 'to'
+// (A* over the free cells, octile heuristic), shortened by skipping the waypoints that can be reached
+// straight at 'clearance' from the obstacles. It returns nil when 'to' cannot be reached, and only
+// 'to' when the way is clear. The cells of 'from' and 'to' are usable even when blocked, the
+// entity or its target being on the edge of an obstacle.
+func FindPath(g *Grid, from, to geometry.Vector2D, clearance float64) []geometry.Vector2D {
+	if g.Clear(from, to, clearance) {
+		return []geometry.Vector2D{to}
+	}
+	sc, sr, okFrom := g.Cell(from)
+	tc, tr, okTo := g.Cell(to)
+	if !okFrom || !okTo {
+		return nil
+	}
+	start, goal := sr*g.Cols+sc, tr*g.Cols+tc
+	usable := func(col, row, dc, dr int) bool {
+		if i := (row+dr)*g.Cols + col + dc; i == goal && g.In(col+dc, row+dr) {
+			return true
+		}
+		return g.legal(col, row, dc, dr)
+	}
+	heuristic := func(index int) float64 {
+		dx, dy := math.Abs(float64(index%g.Cols-tc)), math.Abs(float64(index/g.Cols-tr))
+		return max(dx, dy) + (math.Sqrt2-1)*min(dx, dy)
+	}
+
+	cost := make([]float64, g.Cols*g.Rows)
+	parent := make([]int, len(cost))
+	for i := range cost {
+		cost[i], parent[i] = math.Inf(1), -1
+	}
+	cost[start] = 0
+	queue := &cellQueue{{index: start, cost: heuristic(start)}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(cellItem)
+		if item.index == goal {
+			break
+		}
+		if item.cost > cost[item.index]+heuristic(item.index) {
+			continue // Already reached cheaper
+		}
+		col, row := item.index%g.Cols, item.index/g.Cols
+		for _, n := range gridMoves {
+			if !usable(col, row, n.dc, n.dr) {
+				continue
+			}
+			next := (row+n.dr)*g.Cols + col + n.dc
+			if c := cost[item.index] + n.cost; c < cost[next] {
+				cost[next], parent[next] = c, item.index
+				heap.Push(queue, cellItem{index: next, cost: c + heuristic(next)})
+			}
+		}
+	}
+	if math.IsInf(cost[goal], 1) {
+		return nil
+	}
+
+	// The centers of the cells between the start and the goal, then the target itself
+	var cells []geometry.Vector2D
+	for i := parent[goal]; i != start && i >= 0; i = parent[i] {
+		cells = append(cells, g.Center(i%g.Cols, i/g.Cols))
+	}
+	waypoints := make([]geometry.Vector2D, 0, len(cells)+1)
+	for i := len(cells) - 1; i >= 0; i-- {
+		waypoints = append(waypoints, cells[i])
+	}
+	waypoints = append(waypoints, to)
+
+	// Skip the waypoints reachable straight from the previous kept one
+	path := waypoints[:0]
+	at := from
+	for i := 0; i < len(waypoints); {
+		next := i
+		for next+1 < len(waypoints) && g.Clear(at, waypoints[next+1], clearance) {
+			next++
+		}
+		path = append(path, waypoints[next])
+		at, i = waypoints[next], next+1
+	}
+	return path
+}
+
+// cellItem is a cell waiting in the queue of TargetField or FindPath
+type cellItem struct {
+	index int
+	cost  float64
+}
+
+// cellQueue is a min-heap of cellItem by cost
+type cellQueue []cellItem
+
+func (q cellQueue) Len() int           { return len(q) }
+func (q cellQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q cellQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x any)        { *q = append(*q, x.(cellItem)) }
+func (q *cellQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,58 @@
+package behavior
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestFindPath(t *testing.T) {
+	// A wall of discs across the middle column, open at the bottom
+	var wall []Obstacle
+	for y := 5.0; y < 80; y += 10 {
+		wall = append(wall, Obstacle{Center: geometry.Vector2D{X: 55, Y: y}, Radius: 6})
+	}
+	g := NewGrid(100, 100, 10, wall)
+	from, to := geometry.Vector2D{X: 15, Y: 15}, geometry.Vector2D{X: 95, Y: 15}
+
+	path := FindPath(g, from, to, 1)
+	if len(path) < 2 || path[len(path)-1] != to {
+		t.Fatalf("Expected waypoints ending with the target, got %v", path)
+	}
+	at := from
+	for _, p := range path {
+		if !g.Clear(at, p, 0) {
+			t.Errorf("Expected a straight way from %v to %v in %v", at, p, path)
+		}
+		at = p
+	}
+	if below := path[0]; below.Y < 80 {
+		t.Errorf("Expected to head down to the gap first, got %v", path)
+	}
+
+	// In sight: straight to the target
+	if got := FindPath(g, from, geometry.Vector2D{X: 15, Y: 95}, 1); len(got) != 1 {
+		t.Errorf("Expected a direct path, got %v", got)
+	}
+	// Walled in: no path
+	closed := append(wall, Obstacle{Center: geometry.Vector2D{X: 55, Y: 85}, Radius: 6}, Obstacle{Center: geometry.Vector2D{X: 55, Y: 95}, Radius: 6})
+	if got := FindPath(NewGrid(100, 100, 10, closed), from, to, 1); got != nil {
+		t.Errorf("Expected no path through a closed wall, got %v", got)
+	}
+}
+
+func TestGridClear(t *testing.T) {
+	g := NewGrid(100, 100, 10, []Obstacle{{Center: geometry.Vector2D{X: 50, Y: 50}, Radius: 10}})
+	if g.Clear(geometry.Vector2D{X: 0, Y: 50}, geometry.Vector2D{X: 100, Y: 50}, 0) {
+		t.Error("Expected the obstacle to block the way")
+	}
+	if !g.Clear(geometry.Vector2D{X: 0, Y: 65}, geometry.Vector2D{X: 100, Y: 65}, 0) {
+		t.Error("Expected the way past the obstacle to be clear")
+	}
+	if g.Clear(geometry.Vector2D{X: 0, Y: 65}, geometry.Vector2D{X: 100, Y: 65}, 8) {
+		t.Error("Expected the clearance to block the way grazing the obstacle")
+	}
+	if !g.Free(0, 0) || g.Free(5, 5) || g.Free(-1, 0) {
+		t.Error("Expected only the cells outside the obstacle to be free")
+	}
+}
@@ -0,0 +1,99 @@
+package behavior
+
+import "github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+
+// Pheromone is a scalar field over the cells of a Grid: the entities deposit into it where they go,
+// it spreads to the neighbor cells and evaporates, so that it marks the areas they went through
+// recently (stigmergy). Step returns the next field without touching the current one, which stays
+// safe to share between the entities reading it.
+type Pheromone struct {
+	Grid
+	// Level is the amount of each cell, row by row
+	Level []float64
+}
+
+// NewPheromone returns the empty field of a Grid, the blocked cells of which never hold any
+func NewPheromone(g Grid) *Pheromone {
+	return &Pheromone{Grid: g, Level: make([]float64, g.Cols*g.Rows)}
+}
+
+// Deposit adds 'amount' to the cell containing 'pos', nothing outside the grid or on a blocked cell
+func (p *Pheromone) Deposit(pos geometry.Vector2D, amount float64) {
+	if col, row, ok := p.Cell(pos); ok && p.Free(col, row) {
+		p.Level[row*p.Cols+col] += amount
+	}
+}
+
+// At returns the amount of the cell containing 'pos', 0 outside the grid
+func (p *Pheromone) At(pos geometry.Vector2D) float64 {
+	col, row, ok := p.Cell(pos)
+	if !ok {
+		return 0
+	}
+	return p.Level[row*p.Cols+col]
+}
+
+// level returns the amount of a cell, that of the cell (col, row) when the other is outside
+// the grid or blocked: no slope toward the walls
+func (p *Pheromone) level(col, row, otherCol, otherRow int) float64 {
+	if !p.Free(otherCol, otherRow) {
+		return p.Level[row*p.Cols+col]
+	}
+	return p.Level[otherRow*p.Cols+otherCol]
+}
+
+// Gradient returns the slope of the field at 'pos', toward the higher amounts, per cell
+func (p *Pheromone) Gradient(pos geometry.Vector2D) geometry.Vector2D {
+	col, row, ok := p.Cell(pos)
+	if !ok {
+		return geometry.Vector2D{}
+	}
+	return geometry.Vector2D{
+		X: (p.level(col, row, col+1, row) - p.level(col, row, col-1, row)) / 2,
+		Y: (p.level(col, row, col, row+1) - p.level(col, row, col, row-1)) / 2,
+	}
+}
+
+// Max returns the largest amount of the field
+func (p *Pheromone) Max() float64 {
+	var top float64
+	for _, level := range p.Level {
+		top = max(top, level)
+	}
+	return top
+}
+
+// Step returns the field a tick later: every cell shares the fraction 'diffusion' of its amount
+// evenly with its free neighbor cells, then loses the fraction 'evaporation'
+func (p *Pheromone) Step(diffusion, evaporation float64) *Pheromone {
+	next := &Pheromone{Grid: p.Grid, Level: make([]float64, len(p.Level))}
+	for row := 0; row < p.Rows; row++ {
+		for col := 0; col < p.Cols; col++ {
+			level := p.Level[row*p.Cols+col]
+			if level == 0 {
+				continue
+			}
+			var neighbors [4]int
+			n := 0
+			for _, m := range gridMoves[:4] { // The straight moves
+				if p.Free(col+m.dc, row+m.dr) {
+					neighbors[n] = (row+m.dr)*p.Cols + col + m.dc
+					n++
+				}
+			}
+			shared := 0.0
+			if n > 0 {
+				shared = level * diffusion
+				for _, i := range neighbors[:n] {
+					next.Level[i] += shared / float64(n)
+				}
+			}
+			next.Level[row*p.Cols+col] += level - shared
+		}
+	}
+	keep := 1 - evaporation
+	for i := range next.Level {
+		next.Level[i] *= keep
+	}
+	return next
+}
@@ -0,0 +1,56 @@
+package behavior
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestPheromone(t *testing.T) {
+	p := NewPheromone(*NewGrid(50, 50, 10, nil))
+	center := geometry.Vector2D{X: 25, Y: 25}
+	p.Deposit(center, 10)
+	p.Deposit(geometry.Vector2D{X: -5, Y: 25}, 10) // Outside: lost
+	if p.At(center) != 10 || p.Max() != 10 {
+		t.Fatalf("Expected 10 in the center cell, got %v", p.At(center))
+	}
+
+	next := p.Step(0.4, 0.5)
+	if p.At(center) != 10 {
+		t.Error("Expected Step to leave the current field untouched")
+	}
+	// 6 stay in the center, 1 spreads to each of the 4 neighbors, then half evaporates
+	if got := next.At(center); math.Abs(got-3) > 1e-9 {
+		t.Errorf("Expected 3 left in the center, got %v", got)
+	}
+	if got := next.At(geometry.Vector2D{X: 35, Y: 25}); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("Expected 0.5 in the right neighbor, got %v", got)
+	}
+	var total float64
+	for _, level := range next.Level {
+		total += level
+	}
+	if math.Abs(total-5) > 1e-9 {
+		t.Errorf("Expected the diffusion to keep the amount, got %v", total)
+	}
+
+	// The slope points up to the center
+	if g := next.Gradient(geometry.Vector2D{X: 15, Y: 25}); g.X <= 0 || g.Y != 0 {
+		t.Errorf("Expected the gradient to point right to the center, got %v", g)
+	}
+}
+
+func TestPheromone_obstacles(t *testing.T) {
+	wall := []Obstacle{{Center: geometry.Vector2D{X: 35, Y: 25}, Radius: 4}}
+	p := NewPheromone(*NewGrid(50, 50, 10, wall))
+	p.Deposit(geometry.Vector2D{X: 35, Y: 25}, 10)
+	if p.Max() != 0 {
+		t.Error("Expected no deposit on a blocked cell")
+	}
+	p.Deposit(geometry.Vector2D{X: 25, Y: 25}, 9)
+	next := p.Step(0.3, 0)
+	if next.At(geometry.Vector2D{X: 35, Y: 25}) != 0 || math.Abs(next.At(geometry.Vector2D{X: 15, Y: 25})-0.9) > 1e-9 {
+		t.Errorf("Expected the diffusion to skip the blocked cell, got %v", next.Level)
+	}
+}
@@ -0,0 +1,116 @@
+// Package behavior gathers the steering forces moving the entities: seek, flee, arrive, pursuit,
+// evade, wander, the boids rules and the soft walls, blended with weights. The functions are pure,
+// on geometry.Vector2D: the Individuals of package simulation and the struct-of-arrays swarm of
+// package engine (the one of the boids-tui demo) share them, so both move the same way.
+//
+// A force is a change of velocity per nominal tick: the caller scales it by its time step, adds it
+// to the velocity and caps the speed.
+package behavior
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Seek returns the force turning 'vel' into the velocity heading to 'target' at 'maxSpeed'
+func Seek(pos, vel, target geometry.Vector2D, maxSpeed float64) geometry.Vector2D {
+	return target.Sub(pos).Normalize().Mul(maxSpeed).Sub(vel)
+}
+
+// Flee returns the force turning 'vel' into the velocity running away from 'threat' at 'maxSpeed'
+func Flee(pos, vel, threat geometry.Vector2D, maxSpeed float64) geometry.Vector2D {
+	return pos.Sub(threat).Normalize().Mul(maxSpeed).Sub(vel)
+}
+
+// Arrive seeks 'target' like Seek, slowing down within 'slowRadius' to stop on it
+func Arrive(pos, vel, target geometry.Vector2D, maxSpeed, slowRadius float64) geometry.Vector2D {
+	offset := target.Sub(pos)
+	speed := maxSpeed
+	if dist := offset.Len(); dist < slowRadius {
+		speed *= dist / slowRadius
+	}
+	return offset.Normalize().Mul(speed).Sub(vel)
+}
+
+// Pursuit seeks where a moving target will be when reached at 'maxSpeed'
+func Pursuit(pos, vel, targetPos, targetVel geometry.Vector2D, maxSpeed float64) geometry.Vector2D {
+	return Seek(pos, vel, predict(pos, targetPos, targetVel, maxSpeed), maxSpeed)
+}
+
+// Evade flees where a moving threat will be when it reaches 'pos' at 'maxSpeed'
+func Evade(pos, vel, threatPos, threatVel geometry.Vector2D, maxSpeed float64) geometry.Vector2D {
+	return Flee(pos, vel, predict(pos, threatPos, threatVel, maxSpeed), maxSpeed)
+}
+
+// predict returns the position of a moving entity after the ticks needed to cover the distance
+// from 'pos' at 'speed'
+func predict(pos, otherPos, otherVel geometry.Vector2D, speed float64) geometry.Vector2D {
+	if speed <= 0 {
+		return otherPos
+	}
+	return otherPos.Add(otherVel.Mul(pos.DistanceTo(otherPos) / speed))
+}
+
+// Attract returns the pull toward 'target', growing with its distance: the chase of the hunters
+// and the cohesion of the boids
+func Attract(pos, target geometry.Vector2D) geometry.Vector2D {
+	return target.Sub(pos)
+}
+
+// Wander returns a random jitter of up to ±jitter/2 on each axis, 'random' returns numbers in [0, 1)
+// (e.g. rand.Float64, drawn for X then Y)
+func Wander(random func() float64, jitter float64) geometry.Vector2D {
+	x := (random() - 0.5) * jitter
+	y := (random() - 0.5) * jitter
+	return geometry.Vector2D{X: x, Y: y}
+}
+
+// Contain returns the force turning back an entity within 'margin' of the walls of a world of
+// 'width' by 'height': 'turnFactor' toward the inside on each axis
+func Contain(pos geometry.Vector2D, width, height, margin, turnFactor float64) geometry.Vector2D {
+	var force geometry.Vector2D
+	if pos.X < margin {
+		force.X = turnFactor
+	} else if pos.X > width-margin {
+		force.X = -turnFactor
+	}
+	if pos.Y < margin {
+		force.Y = turnFactor
+	} else if pos.Y > height-margin {
+		force.Y = -turnFactor
+	}
+	return force
+}
+
+// Weighted is a force and its weight in a Blend
+type Weighted struct {
+	Force  geometry.Vector2D
+	Weight float64
+}
+
+// Blend returns the weighted sum of the forces
+func Blend(forces ...Weighted) geometry.Vector2D {
+	var sum geometry.Vector2D
+	for _, f := range forces {
+		sum = sum.Add(f.Force.Mul(f.Weight))
+	}
+	return sum
+}
+
+// Truncate caps the length of 'v' to 'maxLen'
+func Truncate(v geometry.Vector2D, maxLen float64) geometry.Vector2D {
+	if l := v.Len(); l > maxLen {
+		return v.Mul(maxLen / l)
+	}
+	return v
+}
+
+// ClampSpeed keeps the length of a velocity between 'minSpeed' and 'maxSpeed', a null velocity stays null
+func ClampSpeed(vel geometry.Vector2D, minSpeed, maxSpeed float64) geometry.Vector2D {
+	speed := vel.Len()
+	if speed > maxSpeed {
+		return vel.Mul(maxSpeed / speed)
+	} else if speed < minSpeed && speed > 0 {
+		return vel.Mul(minSpeed / speed)
+	}
+	return vel
+}
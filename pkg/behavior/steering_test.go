@@ -0,0 +1,98 @@
+package behavior
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func near(a, b geometry.Vector2D) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestSeekFlee(t *testing.T) {
+	pos, vel := geometry.Vector2D{X: 10, Y: 10}, geometry.Vector2D{X: 1}
+	target := geometry.Vector2D{X: 10, Y: 110}
+	// Adding the force to the velocity gives the desired velocity
+	if got := vel.Add(Seek(pos, vel, target, 4)); !near(got, geometry.Vector2D{Y: 4}) {
+		t.Errorf("Seek: expected to head down at 4, got %v", got)
+	}
+	if got := vel.Add(Flee(pos, vel, target, 4)); !near(got, geometry.Vector2D{Y: -4}) {
+		t.Errorf("Flee: expected to head up at 4, got %v", got)
+	}
+}
+
+func TestArrive(t *testing.T) {
+	pos := geometry.Vector2D{}
+	for dist, want := range map[float64]float64{200: 4, 50: 2, 0: 0} {
+		got := Arrive(pos, geometry.Vector2D{}, geometry.Vector2D{X: dist}, 4, 100)
+		if math.Abs(got.Len()-want) > 1e-9 {
+			t.Errorf("Arrive at %.0f: expected a speed of %.1f, got %v", dist, want, got)
+		}
+	}
+}
+
+func TestPursuitEvade(t *testing.T) {
+	pos := geometry.Vector2D{}
+	// A target 40 away moving up at 2: reached in 10 ticks at 4, 20 above its position
+	targetPos, targetVel := geometry.Vector2D{X: 40}, geometry.Vector2D{Y: 2}
+	want := geometry.Vector2D{X: 40, Y: 20}.Normalize().Mul(4)
+	if got := Pursuit(pos, geometry.Vector2D{}, targetPos, targetVel, 4); !near(got, want) {
+		t.Errorf("Pursuit: expected %v, got %v", want, got)
+	}
+	if got := Evade(pos, geometry.Vector2D{}, targetPos, targetVel, 4); !near(got, want.Mul(-1)) {
+		t.Errorf("Evade: expected %v, got %v", want.Mul(-1), got)
+	}
+}
+
+func TestWander(t *testing.T) {
+	draws := []float64{0, 0.999}
+	random := func() float64 {
+		r := draws[0]
+		draws = draws[1:]
+		return r
+	}
+	got := Wander(random, 0.2)
+	if !near(got, geometry.Vector2D{X: -0.1, Y: 0.0998}) {
+		t.Errorf("Expected X from the first draw and Y from the second, got %v", got)
+	}
+}
+
+func TestContain(t *testing.T) {
+	for _, tt := range []struct {
+		pos  geometry.Vector2D
+		want geometry.Vector2D
+	}{
+		{geometry.Vector2D{X: 500, Y: 400}, geometry.Vector2D{}},
+		{geometry.Vector2D{X: 50, Y: 750}, geometry.Vector2D{X: 0.2, Y: -0.2}},
+		{geometry.Vector2D{X: 950, Y: 20}, geometry.Vector2D{X: -0.2, Y: 0.2}},
+	} {
+		if got := Contain(tt.pos, 1000, 800, 100, 0.2); got != tt.want {
+			t.Errorf("Contain(%v) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestBlendTruncateClamp(t *testing.T) {
+	got := Blend(
+		Weighted{geometry.Vector2D{X: 1}, 2},
+		Weighted{geometry.Vector2D{Y: 1}, 0.5},
+		Weighted{geometry.Vector2D{X: 100, Y: 100}, 0},
+	)
+	if got != (geometry.Vector2D{X: 2, Y: 0.5}) {
+		t.Errorf("Blend = %v, want {2, 0.5}", got)
+	}
+	if got := Truncate(geometry.Vector2D{X: 30, Y: 40}, 5); !near(got, geometry.Vector2D{X: 3, Y: 4}) {
+		t.Errorf("Truncate = %v, want {3, 4}", got)
+	}
+	if got := Truncate(geometry.Vector2D{X: 3}, 5); got != (geometry.Vector2D{X: 3}) {
+		t.Errorf("Truncate changed a short vector: %v", got)
+	}
+	if got := ClampSpeed(geometry.Vector2D{X: 1}, 2, 5); got != (geometry.Vector2D{X: 2}) {
+		t.Errorf("ClampSpeed = %v, want the min speed", got)
+	}
+	if got := ClampSpeed(geometry.Vector2D{}, 2, 5); got != (geometry.Vector2D{}) {
+		t.Errorf("ClampSpeed gave a direction to a null velocity: %v", got)
+	}
+}
@@ -0,0 +1,155 @@
+// Package bt is a small behavior tree: sequences, selectors, conditions and actions evaluated against
+// a blackboard of any type, built in code or from a JSON definition. The nodes keep no state between
+// two ticks, so one tree can drive many agents, each ticking it with its own blackboard.
+package bt
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Status is the result of the evaluation of a node
+type Status int
+
+const (
+	Failure Status = iota
+	Success
+	// Running is returned by an action not finished yet, it stops its sequence or selector like a
+	// success stops a selector: the next tick evaluates the tree again from its root
+	Running
+)
+
+func (s Status) String() string {
+	switch s {
+	case Success:
+		return "success"
+	case Running:
+		return "running"
+	}
+	return "failure"
+}
+
+// Node is a node of a tree evaluated against a blackboard of type T
+type Node[T any] interface {
+	Tick(board T) Status
+}
+
+// Sequence runs its children in order until one does not succeed, it succeeds when all of them do
+type Sequence[T any] []Node[T]
+
+func (s Sequence[T]) Tick(board T) Status {
+	for _, child := range s {
+		if status := child.Tick(board); status != Success {
+			return status
+		}
+	}
+	return Success
+}
+
+// Selector runs its children in order until one does not fail, it fails when all of them do
+type Selector[T any] []Node[T]
+
+func (s Selector[T]) Tick(board T) Status {
+	for _, child := range s {
+		if status := child.Tick(board); status != Failure {
+			return status
+		}
+	}
+	return Failure
+}
+
+// Condition succeeds when the function returns true, it fails otherwise
+type Condition[T any] func(board T) bool
+
+func (c Condition[T]) Tick(board T) Status {
+	if c(board) {
+		return Success
+	}
+	return Failure
+}
+
+// Action is a leaf acting on the blackboard
+type Action[T any] func(board T) Status
+
+func (a Action[T]) Tick(board T) Status {
+	return a(board)
+}
+
+// Types of the nodes of a Spec
+const (
+	TypeSequence  = "sequence"
+	TypeSelector  = "selector"
+	TypeCondition = "condition"
+	TypeAction    = "action"
+)
+
+// Spec is the JSON definition of a node: a sequence or a selector has Children, a condition or an
+// action has the Name of a function of the Registry, with an optional numeric argument, e.g.
+//
+//	{"type": "selector", "children": [
+//	    {"type": "sequence", "children": [
+//	        {"type": "condition", "name": "targetWithin", "arg": 80},
+//	        {"type": "action", "name": "flee"}]},
+//	    {"type": "action", "name": "flock"}]}
+type Spec struct {
+	Type     string  `json:"type"`
+	Name     string  `json:"name,omitempty"`
+	Arg      float64 `json:"arg,omitempty"`
+	Children []Spec  `json:"children,omitempty"`
+}
+
+// Registry holds the conditions and the actions a Spec may name, each one built from the argument of
+// its node
+type Registry[T any] struct {
+	Conditions map[string]func(arg float64) Condition[T]
+	Actions    map[string]func(arg float64) Action[T]
+}
+
+// Parse builds the tree defined by the JSON 'data'
+func Parse[T any](data []byte, reg Registry[T]) (Node[T], error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid behavior tree: %w", err)
+	}
+	return Build(spec, reg)
+}
+
+// Build builds the tree defined by 'spec', the errors locate the node, e.g. root.children[1]
+func Build[T any](spec Spec, reg Registry[T]) (Node[T], error) {
+	return build(spec, reg, "root")
+}
+
+func build[T any](spec Spec, reg Registry[T], path string) (Node[T], error) {
+	switch spec.Type {
+	case TypeSequence, TypeSelector:
+		if len(spec.Children) == 0 {
+			return nil, fmt.Errorf("%s: a %s needs children", path, spec.Type)
+		}
+		children := make([]Node[T], len(spec.Children))
+		for i, child := range spec.Children {
+			node, err := build(child, reg, fmt.Sprintf("%s.children[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			children[i] = node
+		}
+		if spec.Type == TypeSequence {
+			return Sequence[T](children), nil
+		}
+		return Selector[T](children), nil
+	case TypeCondition:
+		newCondition, ok := reg.Conditions[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown condition %q", path, spec.Name)
+		}
+		return newCondition(spec.Arg), nil
+	case TypeAction:
+		newAction, ok := reg.Actions[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown action %q", path, spec.Name)
+		}
+		return newAction(spec.Arg), nil
+	}
+	return nil, fmt.Errorf("%s: unknown node type %q, expected %s, %s, %s or %s",
+		path, spec.Type, TypeSequence, TypeSelector, TypeCondition, TypeAction)
+}
@@ -0,0 +1,96 @@
+package bt
+
+import (
+	"strings"
+	"testing"
+)
+
+// board records the actions run during a tick
+type board struct {
+	energy float64
+	ran    []string
+}
+
+func record(name string, status Status) Action[*board] {
+	return func(b *board) Status {
+		b.ran = append(b.ran, name)
+		return status
+	}
+}
+
+func TestNodes(t *testing.T) {
+	low := Condition[*board](func(b *board) bool { return b.energy < 10 })
+	tree := Selector[*board]{
+		Sequence[*board]{low, record("rest", Success)},
+		record("attack", Failure),
+		record("flock", Success),
+	}
+	for _, tt := range []struct {
+		energy float64
+		want   Status
+		ran    string
+	}{
+		{energy: 5, want: Success, ran: "rest"},
+		{energy: 50, want: Success, ran: "attack,flock"},
+	} {
+		b := &board{energy: tt.energy}
+		if got := tree.Tick(b); got != tt.want || strings.Join(b.ran, ",") != tt.ran {
+			t.Errorf("energy %.0f: got %s running %v, want %s running %s", tt.energy, got, b.ran, tt.want, tt.ran)
+		}
+	}
+
+	// Running stops both composites
+	b := &board{}
+	if got := (Sequence[*board]{record("a", Running), record("b", Success)}).Tick(b); got != Running || len(b.ran) != 1 {
+		t.Errorf("Expected a running sequence to stop, got %s running %v", got, b.ran)
+	}
+	b = &board{}
+	if got := (Selector[*board]{record("a", Running), record("b", Success)}).Tick(b); got != Running || len(b.ran) != 1 {
+		t.Errorf("Expected a running selector to stop, got %s running %v", got, b.ran)
+	}
+}
+
+func testRegistry() Registry[*board] {
+	return Registry[*board]{
+		Conditions: map[string]func(arg float64) Condition[*board]{
+			"energyBelow": func(arg float64) Condition[*board] {
+				return func(b *board) bool { return b.energy < arg }
+			},
+		},
+		Actions: map[string]func(arg float64) Action[*board]{
+			"rest":  func(float64) Action[*board] { return record("rest", Success) },
+			"flock": func(float64) Action[*board] { return record("flock", Success) },
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	tree, err := Parse([]byte(`{"type": "selector", "children": [
+		{"type": "sequence", "children": [
+			{"type": "condition", "name": "energyBelow", "arg": 20},
+			{"type": "action", "name": "rest"}]},
+		{"type": "action", "name": "flock"}]}`), testRegistry())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for energy, want := range map[float64]string{10: "rest", 30: "flock"} {
+		b := &board{energy: energy}
+		if tree.Tick(b) != Success || strings.Join(b.ran, ",") != want {
+			t.Errorf("energy %.0f: expected %s, ran %v", energy, want, b.ran)
+		}
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	for src, want := range map[string]string{
+		`{"type": "sequence"`: "invalid behavior tree",
+		`{"type": "parallel", "children": [{"type": "action", "name": "rest"}]}`: `root: unknown node type "parallel"`,
+		`{"type": "selector"}`: "root: a selector needs children",
+		`{"type": "selector", "children": [{"type": "action", "name": "fly"}]}`:       `root.children[0]: unknown action "fly"`,
+		`{"type": "sequence", "children": [{"type": "condition", "name": "hungry"}]}`: `root.children[0]: unknown condition "hungry"`,
+	} {
+		if _, err := Parse([]byte(src), testRegistry()); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%s) error = %v, want %q", src, err, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package capture
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FFmpegPath is the ffmpeg executable used by FFmpegRecorder, looked up in the PATH
+var FFmpegPath = "ffmpeg"
+
+// FFmpegRecorder streams raw RGBA frames to an ffmpeg process through a pipe, which encodes
+// them to the format of the file extension (mp4, webm, mkv...). Unlike GIFRecorder, the frames
+// are not kept in memory. The process starts with the first frame, which sets the video size:
+// the frames of another size are dropped.
+type FFmpegRecorder struct {
+	path      string
+	fps       float64
+	maxFrames int
+
+	frames chan *image.RGBA
+	done   chan struct{}
+
+	mu     sync.Mutex
+	count  int
+	size   image.Point
+	closed bool
+	err    error // first error of the encoding goroutine
+}
+
+// NewFFmpegRecorder starts a recorder writing a video of 'fps' frames per second to 'path'.
+// 'maxFrames' bounds the duration of the recording (0 = unlimited).
+func NewFFmpegRecorder(path string, fps float64, maxFrames int) *FFmpegRecorder {
+	r := &FFmpegRecorder{
+		path:      path,
+		fps:       fps,
+		maxFrames: maxFrames,
+		frames:    make(chan *image.RGBA, 8),
+		done:      make(chan struct{}),
+	}
+	go r.encodeLoop()
+	return r
+}
+
+// Path returns the destination file of the recording
+func (r *FFmpegRecorder) Path() string {
+	return r.path
+}
+
+// Frames returns the number of frames accepted so far
+func (r *FFmpegRecorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Full reports whether the recorder reached maxFrames
+func (r *FFmpegRecorder) Full() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.maxFrames > 0 && r.count >= r.maxFrames
+}
+
+// MemoryBytes returns the memory of the frames waiting for ffmpeg, the others are already in the file
+func (r *FFmpegRecorder) MemoryBytes() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.frames)) * int64(r.size.X*r.size.Y*4)
+}
+
+// AddFrame queues a frame for ffmpeg, the recorder takes ownership of 'img'.
+// It returns false when the frame was dropped because the recorder is full or closed, or the size changed.
+func (r *FFmpegRecorder) AddFrame(img *image.RGBA) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || (r.maxFrames > 0 && r.count >= r.maxFrames) {
+		return false
+	}
+	size := img.Bounds().Size()
+	if r.count == 0 {
+		r.size = size
+	} else if size != r.size {
+		return false
+	}
+	r.count++
+	// Sending under the lock guarantees Close never closes the channel under our feet
+	r.frames <- img
+	return true
+}
+
+// Close waits for ffmpeg to encode the pending frames and finish the file
+func (r *FFmpegRecorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrRecorderClosed
+	}
+	r.closed = true
+	close(r.frames)
+	r.mu.Unlock()
+
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.count == 0 {
+		return fmt.Errorf("capture: no frame recorded for %s", r.path)
+	}
+	return r.err
+}
+
+// ffmpegArgs returns the arguments reading raw RGBA frames of 'size' on stdin
+func (r *FFmpegRecorder) ffmpegArgs(size image.Point) []string {
+	return []string{
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%dx%d", size.X, size.Y),
+		"-r", strconv.FormatFloat(r.fps, 'f', -1, 64),
+		"-i", "-",
+		// The common codecs need even dimensions and a yuv420p input to be played everywhere
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-pix_fmt", "yuv420p",
+		r.path,
+	}
+}
+
+// encodeLoop starts ffmpeg with the first frame and writes the pixels of every frame to its stdin
+func (r *FFmpegRecorder) encodeLoop() {
+	defer close(r.done)
+	var (
+		cmd    *exec.Cmd
+		stdin  io.WriteCloser
+		stderr bytes.Buffer
+		err    error
+	)
+	for img := range r.frames {
+		if err != nil {
+			continue // Drain the channel, the error is reported by Close
+		}
+		if cmd == nil {
+			cmd, stdin, err = r.start(img.Bounds().Size(), &stderr)
+			if err != nil {
+				continue
+			}
+		}
+		err = writeRGBA(stdin, img)
+	}
+	if cmd != nil {
+		_ = stdin.Close()
+		if waitErr := cmd.Wait(); waitErr != nil {
+			err = fmt.Errorf("capture: ffmpeg failed: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+		}
+	}
+	if err != nil {
+		r.mu.Lock()
+		r.err = err
+		r.mu.Unlock()
+	}
+}
+
+func (r *FFmpegRecorder) start(size image.Point, stderr *bytes.Buffer) (*exec.Cmd, io.WriteCloser, error) {
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, nil, fmt.Errorf("capture: cannot create directory: %w", err)
+		}
+	}
+	cmd := exec.Command(FFmpegPath, r.ffmpegArgs(size)...)
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("capture: cannot pipe to ffmpeg: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("capture: cannot start ffmpeg: %w", err)
+	}
+	return cmd, stdin, nil
+}
+
+// writeRGBA writes the pixels of 'img' row by row (a SubImage has a larger stride)
+func writeRGBA(w io.Writer, img *image.RGBA) error {
+	bounds := img.Bounds()
+	rowLen := 4 * bounds.Dx()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := img.PixOffset(bounds.Min.X, y)
+		if _, err := w.Write(img.Pix[offset : offset+rowLen]); err != nil {
+			return fmt.Errorf("capture: cannot write frame to ffmpeg: %w", err)
+		}
+	}
+	return nil
+}
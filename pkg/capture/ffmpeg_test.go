@@ -0,0 +1,76 @@
+package capture
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeFFmpeg replaces ffmpeg with a script copying the raw frames to the output file (its last argument)
+func fakeFFmpeg(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake ffmpeg is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	previous := FFmpegPath
+	FFmpegPath = path
+	t.Cleanup(func() { FFmpegPath = previous })
+}
+
+func TestFFmpegRecorder(t *testing.T) {
+	fakeFFmpeg(t, `for arg; do out=$arg; done; cat > "$out"`)
+	path := filepath.Join(t.TempDir(), "out", "run.mp4")
+	r := NewFFmpegRecorder(path, 20, 3)
+
+	screen := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for i := 0; i < 4; i++ {
+		// A SubImage has the stride of the screen: only its own pixels are sent
+		frame := screen.SubImage(image.Rect(2, 2, 6, 5)).(*image.RGBA)
+		if accepted := r.AddFrame(frame); accepted != (i < 3) {
+			t.Errorf("Frame %d: accepted = %v with maxFrames=3", i, accepted)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected the video file: %v", err)
+	}
+	if want := int64(3 * 4 * 3 * 4); info.Size() != want {
+		t.Errorf("Expected %d bytes of raw frames, got %d", want, info.Size())
+	}
+	if err := r.Close(); err != ErrRecorderClosed {
+		t.Errorf("Expected ErrRecorderClosed, got %v", err)
+	}
+}
+
+func TestFFmpegRecorder_sizeChange(t *testing.T) {
+	fakeFFmpeg(t, `cat > /dev/null`)
+	r := NewFFmpegRecorder(filepath.Join(t.TempDir(), "run.mp4"), 20, 0)
+	if !r.AddFrame(image.NewRGBA(image.Rect(0, 0, 4, 4))) {
+		t.Fatal("Expected the first frame to be accepted")
+	}
+	if r.AddFrame(image.NewRGBA(image.Rect(0, 0, 8, 4))) {
+		t.Error("Expected a frame of another size to be dropped")
+	}
+	if err := r.Close(); err != nil || r.Frames() != 1 {
+		t.Errorf("Close() error = %v, %d frames", err, r.Frames())
+	}
+}
+
+func TestFFmpegRecorder_failure(t *testing.T) {
+	fakeFFmpeg(t, `cat > /dev/null; echo "unknown encoder" >&2; exit 1`)
+	r := NewFFmpegRecorder(filepath.Join(t.TempDir(), "run.mp4"), 20, 0)
+	r.AddFrame(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	if err := r.Close(); err == nil || !strings.Contains(err.Error(), "unknown encoder") {
+		t.Errorf("Expected the ffmpeg error, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package capture records frames of the simulation to animated GIF files and PNG stills.
+// It does not depend on Ebiten: the caller grabs the pixels and hands over image.RGBA frames.
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrRecorderClosed is returned when adding frames to a closed recorder
+var ErrRecorderClosed = errors.New("capture: recorder is closed")
+
+// GIFRecorder quantizes frames in a background goroutine and writes
+// the animated GIF when Close is called.
+type GIFRecorder struct {
+	path      string
+	delay     int // Delay between frames in 100ths of a second
+	maxFrames int
+
+	frames chan *image.RGBA
+	done   chan struct{}
+
+	mu     sync.Mutex
+	count  int
+	pixels int64 // Pixels of the frames accepted so far
+	closed bool
+	anim   gif.GIF
+}
+
+// NewGIFRecorder starts a recorder writing to 'path' on Close.
+// 'delay' is the time between frames in 100ths of a second and 'maxFrames' bounds the memory used (0 = unlimited).
+func NewGIFRecorder(path string, delay, maxFrames int) *GIFRecorder {
+	if delay < 1 {
+		delay = 1
+	}
+	r := &GIFRecorder{
+		path:      path,
+		delay:     delay,
+		maxFrames: maxFrames,
+		frames:    make(chan *image.RGBA, 8),
+		done:      make(chan struct{}),
+	}
+	go r.encodeLoop()
+	return r
+}
+
+// Path returns the destination file of the recording
+func (r *GIFRecorder) Path() string {
+	return r.path
+}
+
+// Frames returns the number of frames accepted so far
+func (r *GIFRecorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// MemoryBytes returns the approximate memory of the frames kept until Close: one byte per pixel once quantized
+func (r *GIFRecorder) MemoryBytes() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pixels
+}
+
+// Full reports whether the recorder reached maxFrames
+func (r *GIFRecorder) Full() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.maxFrames > 0 && r.count >= r.maxFrames
+}
+
+// AddFrame queues a frame for encoding, the recorder takes ownership of 'img'.
+// It returns false when the frame was dropped because the recorder is full or closed.
+func (r *GIFRecorder) AddFrame(img *image.RGBA) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || (r.maxFrames > 0 && r.count >= r.maxFrames) {
+		return false
+	}
+	r.count++
+	r.pixels += int64(img.Bounds().Dx() * img.Bounds().Dy())
+	// Sending under the lock guarantees Close never closes the channel under our feet
+	r.frames <- img
+	return true
+}
+
+// Close waits for the pending frames to be quantized and writes the GIF file
+func (r *GIFRecorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrRecorderClosed
+	}
+	r.closed = true
+	close(r.frames)
+	r.mu.Unlock()
+
+	<-r.done
+
+	if len(r.anim.Image) == 0 {
+		return fmt.Errorf("capture: no frame recorded for %s", r.path)
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("capture: cannot create directory: %w", err)
+		}
+	}
+	f, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("capture: cannot create gif file: %w", err)
+	}
+	defer f.Close()
+	if err := gif.EncodeAll(f, &r.anim); err != nil {
+		return fmt.Errorf("capture: cannot encode gif: %w", err)
+	}
+	return nil
+}
+
+// encodeLoop converts the RGBA frames to paletted images (the expensive part)
+func (r *GIFRecorder) encodeLoop() {
+	defer close(r.done)
+	for img := range r.frames {
+		bounds := img.Bounds()
+		paletted := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+		r.anim.Image = append(r.anim.Image, paletted)
+		r.anim.Delay = append(r.anim.Delay, r.delay)
+	}
+}
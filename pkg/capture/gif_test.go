@@ -0,0 +1,62 @@
+package capture
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGIFRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "test.gif")
+	r := NewGIFRecorder(path, 5, 3)
+
+	for i := 0; i < 5; i++ {
+		img := image.NewRGBA(image.Rect(10, 10, 42, 26)) // offset bounds like a SubImage
+		img.Set(10+i, 10, color.RGBA{R: 255, A: 255})
+		accepted := r.AddFrame(img)
+		if i < 3 && !accepted {
+			t.Errorf("Expected frame %d to be accepted", i)
+		}
+		if i >= 3 && accepted {
+			t.Errorf("Expected frame %d to be dropped (maxFrames=3)", i)
+		}
+	}
+	if !r.Full() {
+		t.Error("Expected recorder to be full")
+	}
+	if got := r.MemoryBytes(); got != 3*32*16 {
+		t.Errorf("Expected one byte per pixel of the 3 frames, got %d", got)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if r.AddFrame(image.NewRGBA(image.Rect(0, 0, 1, 1))) {
+		t.Error("Expected AddFrame to fail after Close")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected gif file to exist: %v", err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("DecodeAll() error = %v", err)
+	}
+	if len(anim.Image) != 3 {
+		t.Errorf("Expected 3 frames, got %d", len(anim.Image))
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 32 || b.Dy() != 16 {
+		t.Errorf("Expected 32x16 frames, got %v", b)
+	}
+}
+
+func TestGIFRecorder_Empty(t *testing.T) {
+	r := NewGIFRecorder(filepath.Join(t.TempDir(), "empty.gif"), 5, 0)
+	if err := r.Close(); err == nil {
+		t.Error("Expected an error when closing a recorder without frames")
+	}
+}
@@ -0,0 +1,28 @@
+package capture
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// SavePNG writes a still image to 'path', creating its directory if needed
+func SavePNG(path string, img image.Image) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("capture: cannot create directory: %w", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("capture: cannot create file: %w", err)
+	}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(f, img); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("capture: cannot encode PNG: %w", err)
+	}
+	return f.Close()
+}
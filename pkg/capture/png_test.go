@@ -0,0 +1,35 @@
+package capture
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSavePNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "still.png")
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	img.Set(3, 2, color.RGBA{R: 255, A: 255})
+	if err := SavePNG(path, img); err != nil {
+		t.Fatalf("SavePNG() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	decoded, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("Cannot decode the PNG: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("Expected bounds %v, got %v", img.Bounds(), decoded.Bounds())
+	}
+	if r, _, _, _ := decoded.At(3, 2).RGBA(); r != 0xffff {
+		t.Errorf("Expected the red pixel to be kept, got %v", decoded.At(3, 2))
+	}
+}
@@ -0,0 +1,25 @@
+package capture
+
+import "image"
+
+// Recorder captures frames to a file: an animated GIF (GIFRecorder) or a video encoded by ffmpeg (FFmpegRecorder)
+type Recorder interface {
+	// AddFrame queues a frame, the recorder takes ownership of 'img'.
+	// It returns false when the frame was dropped because the recorder is full or closed.
+	AddFrame(img *image.RGBA) bool
+	// Close finishes the file once the pending frames are encoded
+	Close() error
+	// Path returns the destination file
+	Path() string
+	// Frames returns the number of frames accepted so far
+	Frames() int
+	// Full reports whether the recorder reached its maximum number of frames
+	Full() bool
+	// MemoryBytes returns the approximate memory held by the frames not written to the file yet
+	MemoryBytes() int64
+}
+
+var (
+	_ Recorder = (*GIFRecorder)(nil)
+	_ Recorder = (*FFmpegRecorder)(nil)
+)
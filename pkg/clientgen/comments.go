@@ -0,0 +1,89 @@
+// Package clientgen generates the client types of the streaming API from the protobuf definitions:
+// TypeScript interfaces, Python dataclasses and a Markdown reference of the schema. They follow the
+// protobuf JSON mapping of the /snapshots stream, so a web viewer or a notebook decodes it without
+// protoc or a protobuf runtime. Run `go run ./cmd/pbgen` after editing pb/simulation.proto.
+package clientgen
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Comments are the comments of a .proto file, keyed by the name of the element they document:
+// "WorldSnapshot", "WorldSnapshot.tick", "TeamColor.TEAM_RED", "SwarmObserver.StreamSnapshots".
+// The compiled descriptors of protoc-gen-go do not keep them.
+type Comments map[string]string
+
+var (
+	protoBlock = regexp.MustCompile(`^(message|enum|service)\s+(\w+)\s*\{`)
+	protoField = regexp.MustCompile(`^(?:repeated\s+|optional\s+)?[\w.]+\s+(\w+)\s*=\s*\d+`)
+	protoValue = regexp.MustCompile(`^(\w+)\s*=\s*-?\d+`)
+	protoRPC   = regexp.MustCompile(`^rpc\s+(\w+)`)
+)
+
+// ParseComments collects the leading comments (the // lines right above an element) and the
+// trailing comment (on the line of the element) of the messages, enums, services and their members
+func ParseComments(src []byte) Comments {
+	comments := Comments{}
+	var stack []string // Enclosing blocks, innermost last
+	var kinds []string
+	var leading []string
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		code, trailing, _ := strings.Cut(line, "//")
+		code = strings.TrimSpace(code)
+		trailing = strings.TrimSpace(trailing)
+		if code == "" {
+			if line == "" {
+				leading = nil // A blank line detaches the comments above it
+			} else {
+				leading = append(leading, trailing)
+			}
+			continue
+		}
+
+		key := ""
+		parent := strings.Join(stack, ".")
+		switch {
+		case protoBlock.MatchString(code):
+			m := protoBlock.FindStringSubmatch(code)
+			stack = append(stack, m[2])
+			kinds = append(kinds, m[1])
+			key = strings.Join(stack, ".")
+		case len(kinds) > 0 && kinds[len(kinds)-1] == "service" && protoRPC.MatchString(code):
+			key = parent + "." + protoRPC.FindStringSubmatch(code)[1]
+		case len(kinds) > 0 && kinds[len(kinds)-1] == "enum" && protoValue.MatchString(code):
+			key = parent + "." + protoValue.FindStringSubmatch(code)[1]
+		case len(kinds) > 0 && kinds[len(kinds)-1] == "message" && protoField.MatchString(code):
+			key = parent + "." + protoField.FindStringSubmatch(code)[1]
+		}
+		if key != "" {
+			doc := leading
+			if trailing != "" {
+				doc = append(doc[:len(doc):len(doc)], trailing)
+			}
+			// Also recorded without comment: Generate checks that the elements exist
+			comments[key] = strings.Join(doc, "\n")
+		}
+		// Also closes the empty blocks: message GetState {}
+		if strings.HasSuffix(code, "}") && len(stack) > 0 {
+			stack, kinds = stack[:len(stack)-1], kinds[:len(kinds)-1]
+		}
+		leading = nil
+	}
+	return comments
+}
+
+// has reports whether the element 'key' is declared in the source
+func (c Comments) has(key string) bool {
+	_, ok := c[key]
+	return ok
+}
+
+// oneLine joins the lines of a comment, for the table cells and the trailing comments
+func oneLine(doc string) string {
+	return strings.ReplaceAll(doc, "\n", " ")
+}
@@ -0,0 +1,335 @@
+package clientgen
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// header starts every generated file, 'comment' is the line comment marker of the language
+func header(comment string, fd protoreflect.FileDescriptor) string {
+	return fmt.Sprintf("%s Code generated by cmd/pbgen from %s. DO NOT EDIT.\n", comment, fd.Path())
+}
+
+// isInt64 reports whether the protobuf JSON mapping encodes the field as a string
+func isInt64(f protoreflect.FieldDescriptor) bool {
+	switch f.Kind() {
+	case protoreflect.Int64Kind, protoreflect.Uint64Kind, protoreflect.Sint64Kind,
+		protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		return true
+	}
+	return false
+}
+
+// TypeScript returns the interfaces of the messages and the union types of the enums
+func TypeScript(fd protoreflect.FileDescriptor, comments Comments) []byte {
+	var b strings.Builder
+	b.WriteString(header("//", fd))
+	b.WriteString("//\n// Protobuf JSON mapping of the messages, as streamed by GET /snapshots: every field is present,\n")
+	b.WriteString("// 64-bit integers are strings, enums the names of their values and unset messages null.\n")
+
+	tsDoc := func(indent, doc string) {
+		if doc == "" {
+			return
+		}
+		lines := strings.Split(doc, "\n")
+		if len(lines) == 1 {
+			fmt.Fprintf(&b, "%s/** %s */\n", indent, doc)
+			return
+		}
+		fmt.Fprintf(&b, "%s/**\n", indent)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "%s * %s\n", indent, line)
+		}
+		fmt.Fprintf(&b, "%s */\n", indent)
+	}
+
+	enums := fd.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		e := enums.Get(i)
+		b.WriteString("\n")
+		tsDoc("", comments[string(e.Name())])
+		values := e.Values()
+		names := make([]string, values.Len())
+		for j := range names {
+			names[j] = fmt.Sprintf("%q", values.Get(j).Name())
+		}
+		fmt.Fprintf(&b, "export type %s = %s;\n", e.Name(), strings.Join(names, " | "))
+	}
+
+	messages := fd.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		m := messages.Get(i)
+		b.WriteString("\n")
+		tsDoc("", comments[string(m.Name())])
+		fields := m.Fields()
+		if fields.Len() == 0 {
+			fmt.Fprintf(&b, "export type %s = Record<string, never>;\n", m.Name())
+			continue
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", m.Name())
+		for j := 0; j < fields.Len(); j++ {
+			f := fields.Get(j)
+			tsDoc("  ", comments[string(m.Name())+"."+string(f.Name())])
+			fmt.Fprintf(&b, "  %s: %s;\n", f.JSONName(), tsType(f))
+		}
+		b.WriteString("}\n")
+	}
+	return []byte(b.String())
+}
+
+func tsType(f protoreflect.FieldDescriptor) string {
+	var t string
+	switch {
+	case f.Kind() == protoreflect.MessageKind:
+		t = string(f.Message().Name())
+		if !f.IsList() {
+			return t + " | null"
+		}
+	case f.Kind() == protoreflect.EnumKind:
+		t = string(f.Enum().Name())
+	case f.Kind() == protoreflect.BoolKind:
+		t = "boolean"
+	case f.Kind() == protoreflect.StringKind, f.Kind() == protoreflect.BytesKind, isInt64(f):
+		t = "string"
+	default:
+		t = "number"
+	}
+	if f.IsList() {
+		return t + "[]"
+	}
+	return t
+}
+
+// Python returns the dataclasses of the messages, each with a from_json class method decoding
+// its protobuf JSON mapping, and the enums as str enums
+func Python(fd protoreflect.FileDescriptor, comments Comments) []byte {
+	var b strings.Builder
+	b.WriteString(header("#", fd))
+	fmt.Fprintf(&b, "\"\"\"Types of the messages of %s.\n\n", fd.Path())
+	b.WriteString("from_json decodes the protobuf JSON mapping streamed by GET /snapshots (see swarm_client.py):\n")
+	b.WriteString("64-bit integers are converted from strings and the missing fields get their default value.\n\"\"\"\n\n")
+	b.WriteString("from __future__ import annotations\n\nimport enum\nfrom dataclasses import dataclass, field\nfrom typing import Any, List, Optional\n")
+
+	pyDoc := func(indent, doc string) {
+		if doc == "" {
+			return
+		}
+		if !strings.Contains(doc, "\n") {
+			fmt.Fprintf(&b, "%s\"\"\"%s\"\"\"\n\n", indent, doc)
+			return
+		}
+		fmt.Fprintf(&b, "%s\"\"\"%s\n%s\"\"\"\n\n", indent, strings.ReplaceAll(doc, "\n", "\n"+indent), indent)
+	}
+
+	enums := fd.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		e := enums.Get(i)
+		fmt.Fprintf(&b, "\n\nclass %s(str, enum.Enum):\n", e.Name())
+		pyDoc("    ", comments[string(e.Name())])
+		values := e.Values()
+		for j := 0; j < values.Len(); j++ {
+			v := values.Get(j)
+			fmt.Fprintf(&b, "    %s = %q\n", v.Name(), v.Name())
+		}
+	}
+
+	messages := fd.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		m := messages.Get(i)
+		fmt.Fprintf(&b, "\n\n@dataclass\nclass %s:\n", m.Name())
+		pyDoc("    ", comments[string(m.Name())])
+		fields := m.Fields()
+		for j := 0; j < fields.Len(); j++ {
+			f := fields.Get(j)
+			if doc := comments[string(m.Name())+"."+string(f.Name())]; doc != "" {
+				fmt.Fprintf(&b, "    #: %s\n", oneLine(doc))
+			}
+			typ, def := pyType(f)
+			fmt.Fprintf(&b, "    %s: %s = %s\n", f.Name(), typ, def)
+		}
+		if fields.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("    @classmethod\n")
+		fmt.Fprintf(&b, "    def from_json(cls, d: dict[str, Any]) -> %s:\n", m.Name())
+		if fields.Len() == 0 {
+			b.WriteString("        return cls()\n")
+			continue
+		}
+		b.WriteString("        return cls(\n")
+		for j := 0; j < fields.Len(); j++ {
+			f := fields.Get(j)
+			fmt.Fprintf(&b, "            %s=%s,\n", f.Name(), pyDecode(f))
+		}
+		b.WriteString("        )\n")
+	}
+	return []byte(b.String())
+}
+
+// pyScalar returns the Python type of a single value of the field and its default value
+func pyScalar(f protoreflect.FieldDescriptor) (typ, def string) {
+	switch f.Kind() {
+	case protoreflect.MessageKind:
+		return string(f.Message().Name()), "None"
+	case protoreflect.EnumKind:
+		e := f.Enum()
+		return string(e.Name()), fmt.Sprintf("%s.%s", e.Name(), e.Values().Get(0).Name())
+	case protoreflect.BoolKind:
+		return "bool", "False"
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return "str", `""`
+	case protoreflect.DoubleKind, protoreflect.FloatKind:
+		return "float", "0.0"
+	}
+	return "int", "0"
+}
+
+func pyType(f protoreflect.FieldDescriptor) (typ, def string) {
+	typ, def = pyScalar(f)
+	switch {
+	case f.IsList():
+		return "List[" + typ + "]", "field(default_factory=list)"
+	case f.Kind() == protoreflect.MessageKind:
+		return "Optional[" + typ + "]", def
+	}
+	return typ, def
+}
+
+// pyDecode returns the expression converting the JSON value of the field in the dict 'd'
+func pyDecode(f protoreflect.FieldDescriptor) string {
+	typ, def := pyScalar(f)
+	convert := func(v string) string {
+		switch f.Kind() {
+		case protoreflect.MessageKind:
+			return typ + ".from_json(" + v + ")"
+		default:
+			return typ + "(" + v + ")"
+		}
+	}
+	name := f.JSONName()
+	switch {
+	case f.IsList():
+		return fmt.Sprintf("[%s for v in d.get(%q) or []]", convert("v"), name)
+	case f.Kind() == protoreflect.MessageKind:
+		return fmt.Sprintf("%s if d.get(%q) is not None else None", convert(fmt.Sprintf("d[%q]", name)), name)
+	case f.Kind() == protoreflect.EnumKind:
+		return convert(fmt.Sprintf("d.get(%q, %q)", name, f.Enum().Values().Get(0).Name()))
+	}
+	return convert(fmt.Sprintf("d.get(%q, %s)", name, def))
+}
+
+// Markdown returns the reference of the messages, enums and services, with their JSON names and types
+func Markdown(fd protoreflect.FileDescriptor, comments Comments) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- Code generated by cmd/pbgen from %s. DO NOT EDIT. -->\n\n", fd.Path())
+	fmt.Fprintf(&b, "# Schema of `%s`\n\n", fd.Path())
+	b.WriteString("The JSON column is the name of the field in the protobuf JSON mapping streamed by `GET /snapshots`:\n")
+	b.WriteString("every field is present, 64-bit integers are strings, enums the names of their values and unset messages `null`.\n")
+	b.WriteString("The gRPC service streams the same messages in the binary protobuf encoding.\n")
+
+	cell := func(doc string) string {
+		return strings.ReplaceAll(oneLine(doc), "|", `\|`)
+	}
+	paragraph := func(doc string) {
+		if doc != "" {
+			fmt.Fprintf(&b, "%s\n\n", oneLine(doc))
+		}
+	}
+
+	services := fd.Services()
+	for i := 0; i < services.Len(); i++ {
+		s := services.Get(i)
+		fmt.Fprintf(&b, "\n## service %s\n\n", s.Name())
+		paragraph(comments[string(s.Name())])
+		b.WriteString("| RPC | Request | Response | Description |\n|---|---|---|---|\n")
+		methods := s.Methods()
+		for j := 0; j < methods.Len(); j++ {
+			m := methods.Get(j)
+			response := fmt.Sprintf("[%s](#%s)", m.Output().Name(), strings.ToLower(string(m.Output().Name())))
+			if m.IsStreamingServer() {
+				response = "stream " + response
+			}
+			fmt.Fprintf(&b, "| %s | [%s](#%s) | %s | %s |\n", m.Name(), m.Input().Name(),
+				strings.ToLower(string(m.Input().Name())), response, cell(comments[string(s.Name())+"."+string(m.Name())]))
+		}
+	}
+
+	enums := fd.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		e := enums.Get(i)
+		fmt.Fprintf(&b, "\n## %s\n\n", e.Name())
+		paragraph(comments[string(e.Name())])
+		b.WriteString("| Value | Number | Description |\n|---|---|---|\n")
+		values := e.Values()
+		for j := 0; j < values.Len(); j++ {
+			v := values.Get(j)
+			fmt.Fprintf(&b, "| `%s` | %d | %s |\n", v.Name(), v.Number(), cell(comments[string(e.Name())+"."+string(v.Name())]))
+		}
+	}
+
+	messages := fd.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		m := messages.Get(i)
+		fmt.Fprintf(&b, "\n## %s\n\n", m.Name())
+		paragraph(comments[string(m.Name())])
+		fields := m.Fields()
+		if fields.Len() == 0 {
+			b.WriteString("No field.\n")
+			continue
+		}
+		b.WriteString("| Field | JSON | Type | Description |\n|---|---|---|---|\n")
+		for j := 0; j < fields.Len(); j++ {
+			f := fields.Get(j)
+			fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s |\n", f.Name(), f.JSONName(), mdType(f), cell(comments[string(m.Name())+"."+string(f.Name())]))
+		}
+	}
+	return []byte(b.String())
+}
+
+func mdType(f protoreflect.FieldDescriptor) string {
+	var t string
+	switch f.Kind() {
+	case protoreflect.MessageKind:
+		name := string(f.Message().Name())
+		t = fmt.Sprintf("[%s](#%s)", name, strings.ToLower(name))
+	case protoreflect.EnumKind:
+		name := string(f.Enum().Name())
+		t = fmt.Sprintf("[%s](#%s)", name, strings.ToLower(name))
+	default:
+		t = f.Kind().String()
+		if isInt64(f) {
+			t += " (string)"
+		}
+	}
+	if f.IsList() {
+		return "repeated " + t
+	}
+	return t
+}
+
+// Files generated by Generate, relative to the clients directory
+const (
+	TypeScriptFile = "typescript/simulation_types.ts"
+	PythonFile     = "python/simulation_types.py"
+	MarkdownFile   = "SCHEMA.md"
+)
+
+// Generate returns the generated files of the compiled descriptor 'fd' documented with the
+// comments of its source 'src', keyed by their path in the clients directory
+func Generate(fd protoreflect.FileDescriptor, src []byte) (map[string][]byte, error) {
+	comments := ParseComments(src)
+	// A stale source would document the wrong fields
+	messages := fd.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		if name := string(messages.Get(i).Name()); !comments.has(name) {
+			return nil, fmt.Errorf("clientgen: message %s not found in the proto source", name)
+		}
+	}
+	return map[string][]byte{
+		TypeScriptFile: TypeScript(fd, comments),
+		PythonFile:     Python(fd, comments),
+		MarkdownFile:   Markdown(fd, comments),
+	}, nil
+}
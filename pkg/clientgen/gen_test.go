@@ -0,0 +1,79 @@
+package clientgen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestParseComments(t *testing.T) {
+	src := []byte(`syntax = "proto3";
+
+// Ignored: detached by the blank line
+
+// A point
+// of the world
+message Vector {
+  double x = 1; // Horizontal
+  // Vertical
+  double y = 2;
+}
+message Empty {}
+enum Team {
+  TEAM_RED = 1; // The hunters
+}
+service Observer {
+  // Streams
+  rpc Stream(Empty) returns (stream Vector);
+}
+`)
+	c := ParseComments(src)
+	want := map[string]string{
+		"Vector":          "A point\nof the world",
+		"Vector.x":        "Horizontal",
+		"Vector.y":        "Vertical",
+		"Empty":           "",
+		"Team.TEAM_RED":   "The hunters",
+		"Observer.Stream": "Streams",
+	}
+	for key, doc := range want {
+		if got, ok := c[key]; !ok || got != doc {
+			t.Errorf("Comment of %s = %q (found %v), expected %q", key, got, ok, doc)
+		}
+	}
+	if c.has("Empty.x") || !c.has("Team") {
+		t.Errorf("Unexpected elements %v", c)
+	}
+}
+
+// TestGenerate_upToDate fails when the files of the clients directory were not regenerated after
+// a change of pb/simulation.proto or of the generator: run `go run ./cmd/pbgen`
+func TestGenerate_upToDate(t *testing.T) {
+	src, err := os.ReadFile("../../pb/simulation.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := Generate(pb.File_pb_simulation_proto, src)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for name, data := range files {
+		current, err := os.ReadFile(filepath.Join("../../clients", name))
+		if err != nil {
+			t.Errorf("Cannot read the generated %s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(current, data) {
+			t.Errorf("clients/%s is out of date, run go run ./cmd/pbgen", name)
+		}
+	}
+}
+
+func TestGenerate_staleSource(t *testing.T) {
+	if _, err := Generate(pb.File_pb_simulation_proto, []byte("message Vector {}")); err == nil {
+		t.Error("Expected a source without the messages of the descriptor to be rejected")
+	}
+}
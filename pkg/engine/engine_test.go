@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func testParams() *Params {
+	return &Params{
+		WorldWidth: 1000, WorldHeight: 800,
+		DetectionRadius: 50, DefenseRadius: 40, ContactRadius: 12,
+		VisualRange: 70, ProtectedRange: 20,
+		MaxSpeed: 4, MinSpeed: 2, Aggression: 0.8,
+		CenteringFactor: 0.0005, AvoidFactor: 0.05, MatchingFactor: 0.05, TurnFactor: 0.2,
+		RedStrategy: StrategyClassicHunter, BlueStrategy: StrategyClassicBoids,
+	}
+}
+
+func randomSwarm(seed uint64, n int, p *Params) *Swarm {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	s := New(rand.New(rand.NewPCG(seed, 1)))
+	for i := 0; i < n; i++ {
+		color := pb.TeamColor_TEAM_BLUE
+		if i%10 == 0 {
+			color = pb.TeamColor_TEAM_RED
+		}
+		s.Add(color, rng.Float64()*p.WorldWidth, rng.Float64()*p.WorldHeight, rng.Float64()*2-1, rng.Float64()*2-1)
+	}
+	return s
+}
+
+func TestGrid_queryMatchesBruteForce(t *testing.T) {
+	p := testParams()
+	s := randomSwarm(1, 2000, p)
+	// A few entities outside of the world, as the soft boundaries allow
+	s.Add(pb.TeamColor_TEAM_BLUE, -30, 10, 0, 0)
+	s.Add(pb.TeamColor_TEAM_BLUE, 1020, 820, 0, 0)
+	s.grid.build(s.PosX, s.PosY, p.WorldWidth, p.WorldHeight, 70)
+
+	const radius = 45.0
+	for _, center := range [][2]float64{{0, 0}, {500, 400}, {-10, 20}, {1000, 800}, {990, 5}} {
+		found := map[int32]bool{}
+		s.grid.query(center[0], center[1], radius, func(i int32) {
+			found[i] = true
+		})
+		for i := range s.PosX {
+			dx, dy := s.PosX[i]-center[0], s.PosY[i]-center[1]
+			if dx*dx+dy*dy < radius*radius && !found[int32(i)] {
+				t.Fatalf("Entity %d at (%.1f,%.1f) missed around %v", i, s.PosX[i], s.PosY[i], center)
+			}
+		}
+	}
+}
+
+func TestSwarm_fightRules(t *testing.T) {
+	p := testParams()
+	p.MaxSpeed, p.MinSpeed = 0.001, 0
+
+	// A lone blue is converted
+	s := New(rand.New(rand.NewPCG(1, 1)))
+	s.Add(pb.TeamColor_TEAM_RED, 500, 400, 0, 0)
+	s.Add(pb.TeamColor_TEAM_BLUE, 505, 400, 0, 0)
+	if _, err := s.Step(p); err != nil {
+		t.Fatal(err)
+	}
+	if s.Color[1] != pb.TeamColor_TEAM_RED {
+		t.Errorf("Expected the lone blue to be converted, got %s", s.Color[1])
+	}
+
+	// A blue with 3 defenders converts the attacker
+	s = New(rand.New(rand.NewPCG(1, 1)))
+	s.Add(pb.TeamColor_TEAM_RED, 500, 400, 0, 0)
+	s.Add(pb.TeamColor_TEAM_BLUE, 505, 400, 0, 0)
+	for i := 0; i < 3; i++ {
+		s.Add(pb.TeamColor_TEAM_BLUE, 520, 390+float64(i)*10, 0, 0)
+	}
+	conversions, err := s.Step(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Color[0] != pb.TeamColor_TEAM_BLUE || s.Color[1] != pb.TeamColor_TEAM_BLUE {
+		t.Errorf("Expected the attacker to be converted, got %s and %s", s.Color[0], s.Color[1])
+	}
+	if len(conversions) != 1 || conversions[0] != (Conversion{Index: 0, From: pb.TeamColor_TEAM_RED, To: pb.TeamColor_TEAM_BLUE}) {
+		t.Errorf("Unexpected conversions %v", conversions)
+	}
+
+	// The same 3 defenders are not enough when 4 are needed
+	p.DefendersNeeded = 4
+	s = New(rand.New(rand.NewPCG(1, 1)))
+	s.Add(pb.TeamColor_TEAM_RED, 500, 400, 0, 0)
+	s.Add(pb.TeamColor_TEAM_BLUE, 505, 400, 0, 0)
+	for i := 0; i < 3; i++ {
+		s.Add(pb.TeamColor_TEAM_BLUE, 520, 390+float64(i)*10, 0, 0)
+	}
+	if _, err := s.Step(p); err != nil {
+		t.Fatal(err)
+	}
+	if s.Color[1] != pb.TeamColor_TEAM_RED {
+		t.Errorf("Expected the victim to be converted with 4 defenders needed, got %s", s.Color[1])
+	}
+}
+
+func TestSwarm_truce(t *testing.T) {
+	p := testParams()
+	p.MaxSpeed, p.MinSpeed = 0.001, 0
+	p.Truce = true
+	s := New(rand.New(rand.NewPCG(1, 1)))
+	s.Add(pb.TeamColor_TEAM_RED, 500, 400, 0, 0)
+	s.Add(pb.TeamColor_TEAM_BLUE, 505, 400, 0, 0)
+	conversions, err := s.Step(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversions) != 0 || s.Color[1] != pb.TeamColor_TEAM_BLUE {
+		t.Errorf("Expected no fight during a truce, got %v", conversions)
+	}
+	p.Truce = false
+	if _, err := s.Step(p); err != nil {
+		t.Fatal(err)
+	}
+	if s.Color[1] != pb.TeamColor_TEAM_RED {
+		t.Errorf("Expected the blue to be converted once the truce is over, got %s", s.Color[1])
+	}
+}
+
+func TestSwarm_multipleAttackers(t *testing.T) {
+	p := testParams()
+	p.MaxSpeed, p.MinSpeed = 0.001, 0
+
+	// Two reds on a lone blue: converted once
+	s := New(rand.New(rand.NewPCG(1, 1)))
+	s.Add(pb.TeamColor_TEAM_RED, 495, 400, 0, 0)
+	s.Add(pb.TeamColor_TEAM_RED, 505, 400, 0, 0)
+	s.Add(pb.TeamColor_TEAM_BLUE, 500, 400, 0, 0)
+	conversions, err := s.Step(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversions) != 1 || conversions[0].Index != 2 {
+		t.Errorf("Expected the blue to be converted once, got %v", conversions)
+	}
+
+	// Two reds on a defended blue, the second one with a pack of 2 reds around it
+	defended := func(policy string) []pb.TeamColor {
+		p.ContactPolicy = policy
+		s := New(rand.New(rand.NewPCG(1, 1)))
+		s.Add(pb.TeamColor_TEAM_RED, 490, 400, 0, 0)
+		s.Add(pb.TeamColor_TEAM_RED, 500, 390, 0, 0)
+		s.Add(pb.TeamColor_TEAM_RED, 500, 360, 0, 0)
+		s.Add(pb.TeamColor_TEAM_RED, 505, 362, 0, 0)
+		s.Add(pb.TeamColor_TEAM_BLUE, 500, 400, 0, 0)
+		for i := 0; i < 3; i++ {
+			s.Add(pb.TeamColor_TEAM_BLUE, 520, 390+float64(i)*10, 0, 0)
+		}
+		if _, err := s.Step(p); err != nil {
+			t.Fatal(err)
+		}
+		return s.Color[:2]
+	}
+	if got := defended(ContactSimultaneous); got[0] != pb.TeamColor_TEAM_BLUE || got[1] != pb.TeamColor_TEAM_BLUE {
+		t.Errorf("Expected both attackers to be converted, got %v", got)
+	}
+	if got := defended(ContactStrongest); got[0] != pb.TeamColor_TEAM_RED || got[1] != pb.TeamColor_TEAM_BLUE {
+		t.Errorf("Expected only the strongest attacker to be converted, got %v", got)
+	}
+}
+
+func TestSwarm_stepIsDeterministic(t *testing.T) {
+	p := testParams()
+	p.RedStrategy = StrategyPackHunter
+	a, b := randomSwarm(7, 500, p), randomSwarm(7, 500, p)
+	for tick := 0; tick < 50; tick++ {
+		if _, err := a.Step(p); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.Step(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range a.PosX {
+		if a.PosX[i] != b.PosX[i] || a.PosY[i] != b.PosY[i] || a.Color[i] != b.Color[i] {
+			t.Fatalf("Entity %d differs between two runs of the same seed", i)
+		}
+	}
+	// 8 float arrays, the colors and 2 grid arrays per entity
+	if got := a.MemoryBytes(); got < 500*(8*8+3*4) {
+		t.Errorf("Expected MemoryBytes to count the arrays of 500 entities, got %d", got)
+	}
+}
+
+func TestSwarm_stepRejectsUnknownStrategy(t *testing.T) {
+	p := testParams()
+	p.BlueStrategy = "scripted:flock.lua"
+	if _, err := randomSwarm(1, 10, p).Step(p); err == nil {
+		t.Errorf("Expected an error for a strategy the engine does not implement")
+	}
+	if Supports(p.BlueStrategy) || !Supports(StrategyPackHunter) {
+		t.Errorf("Supports disagrees with Step")
+	}
+}
+
+func BenchmarkSwarm_Step50k(b *testing.B) {
+	p := testParams()
+	p.WorldWidth, p.WorldHeight = 8000, 6000
+	s := randomSwarm(1, 50000, p)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Step(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSwarm_stepScalesWithTimeStep(t *testing.T) {
+	p := testParams()
+	lone := func() *Swarm {
+		s := New(rand.New(rand.NewPCG(1, 1)))
+		s.Add(pb.TeamColor_TEAM_BLUE, 500, 400, p.MinSpeed, 0)
+		return s
+	}
+	nominal := lone()
+	for range 2 {
+		if _, err := nominal.Step(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	double := lone()
+	p.TimeStep = 2
+	if _, err := double.Step(p); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(nominal.PosX[0]-double.PosX[0]) > 1e-9 || nominal.PosY[0] != double.PosY[0] {
+		t.Errorf("Expected the same position at any time step, got (%v, %v) and (%v, %v)",
+			nominal.PosX[0], nominal.PosY[0], double.PosX[0], double.PosY[0])
+	}
+}
+
+// integrateFor runs 'steps' steps of 'dt' from (x, v) under the acceleration 'force(x)', evaluated once
+// per step at its start like the steering forces, and returns the final position and velocity
+func integrateFor(scheme integrator, x, v, dt float64, steps int, force func(x float64) float64) (float64, float64) {
+	for range steps {
+		v0 := v
+		v += force(x) * dt
+		x, _ = scheme.integrate(x, 0, v0, 0, v, 0, dt)
+	}
+	return x, v
+}
+
+func TestIntegrator_accuracy(t *testing.T) {
+	// A constant force over a large time step: x(T) = v0*T + a*T²/2
+	const a, v0, dt, steps = 0.5, 1.0, 4.0, 10
+	T := dt * steps
+	exact := v0*T + a*T*T/2
+	constant := func(float64) float64 { return a }
+
+	verletX, _ := integrateFor(verlet, 0, v0, dt, steps, constant)
+	eulerX, _ := integrateFor(euler, 0, v0, dt, steps, constant)
+	semiX, _ := integrateFor(semiImplicit, 0, v0, dt, steps, constant)
+	if math.Abs(verletX-exact) > 1e-9 {
+		t.Errorf("Expected Verlet to be exact for a constant force, got %v instead of %v", verletX, exact)
+	}
+	// Both Euler schemes are off by a*dt*T/2, on opposite sides
+	want := a * dt * T / 2
+	if math.Abs(exact-eulerX-want) > 1e-9 || math.Abs(semiX-exact-want) > 1e-9 {
+		t.Errorf("Expected Euler %v and semi-implicit %v to miss %v by %v", eulerX, semiX, exact, want)
+	}
+}
+
+func TestIntegrator_stability(t *testing.T) {
+	// A stiff spring (k*dt² = 0.4): the energy of the exact solution is constant
+	const k, dt, steps = 0.1, 2.0, 200
+	spring := func(x float64) float64 { return -k * x }
+	energy := func(x, v float64) float64 { return v*v/2 + k*x*x/2 }
+	initial := energy(100, 0)
+
+	semi := energy(integrateFor(semiImplicit, 100, 0, dt, steps, spring))
+	ver := energy(integrateFor(verlet, 100, 0, dt, steps, spring))
+	eul := energy(integrateFor(euler, 100, 0, dt, steps, spring))
+	if semi > 2*initial {
+		t.Errorf("Expected the semi-implicit scheme to keep the energy bounded, got %v from %v", semi, initial)
+	}
+	// With a single force evaluation per step, the other two gain energy, Euler twice as fast
+	if !(eul > ver && ver > 2*initial) {
+		t.Errorf("Expected the energy to grow faster with Euler (%v) than with Verlet (%v), from %v", eul, ver, initial)
+	}
+}
+
+func TestSwarm_stepIntegrator(t *testing.T) {
+	p := testParams()
+	positions := map[string]float64{}
+	for _, name := range []string{"", IntegratorSemiImplicit, IntegratorEuler, IntegratorVerlet} {
+		// A lone boid near the left wall is pushed right by the soft boundaries
+		s := New(rand.New(rand.NewPCG(1, 1)))
+		s.Add(pb.TeamColor_TEAM_BLUE, 50, 400, p.MinSpeed, 0)
+		p.Integrator = name
+		if _, err := s.Step(p); err != nil {
+			t.Fatal(err)
+		}
+		positions[name] = s.PosX[0]
+	}
+	if positions[""] != positions[IntegratorSemiImplicit] {
+		t.Errorf("Expected the semi-implicit scheme by default, got %v and %v", positions[""], positions[IntegratorSemiImplicit])
+	}
+	if !(positions[IntegratorEuler] < positions[IntegratorVerlet] && positions[IntegratorVerlet] < positions[IntegratorSemiImplicit]) {
+		t.Errorf("Expected Verlet between the Euler schemes, got %v", positions)
+	}
+	p.Integrator = "runge-kutta"
+	if _, err := randomSwarm(1, 10, p).Step(p); err == nil {
+		t.Error("Expected an error for an unknown integrator")
+	}
+}
@@ -0,0 +1,84 @@
+package engine
+
+// grid is a uniform grid stored as a counting sort of the entity indexes by cell:
+// the entities of cell c are items[start[c]:start[c+1]]. Rebuilding it every step
+// costs two passes over the positions and no allocation once the arrays are large enough.
+type grid struct {
+	cellSize float64
+	cols     int
+	rows     int
+	start    []int32
+	items    []int32
+	cells    []int32 // Cell of every entity, kept between the two passes
+}
+
+// build sorts the entities by cell. Positions outside of the world go to the border cells,
+// query clamps its range the same way so they are still found.
+func (g *grid) build(xs, ys []float64, width, height, cellSize float64) {
+	g.cellSize = cellSize
+	g.cols = int(width/cellSize) + 1
+	g.rows = int(height/cellSize) + 1
+	n := len(xs)
+	g.start = resizeInt32(g.start, g.cols*g.rows+1)
+	g.items = resizeInt32(g.items, n)
+	g.cells = resizeInt32(g.cells, n)
+	clear(g.start)
+
+	for i := range xs {
+		c := int32(g.col(xs[i]) + g.row(ys[i])*g.cols)
+		g.cells[i] = c
+		g.start[c+1]++
+	}
+	for c := 1; c < len(g.start); c++ {
+		g.start[c] += g.start[c-1]
+	}
+	// Fill each cell in index order, start[c] is used as the write cursor then restored
+	for i, c := range g.cells {
+		g.items[g.start[c]] = int32(i)
+		g.start[c]++
+	}
+	for c := len(g.start) - 1; c > 0; c-- {
+		g.start[c] = g.start[c-1]
+	}
+	g.start[0] = 0
+}
+
+// query calls fn for every entity of the cells overlapping the square of half side 'radius'
+// around (x, y). The caller checks the actual distance.
+func (g *grid) query(x, y, radius float64, fn func(i int32)) {
+	minCol, maxCol := g.col(x-radius), g.col(x+radius)
+	minRow, maxRow := g.row(y-radius), g.row(y+radius)
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			c := col + row*g.cols
+			for _, i := range g.items[g.start[c]:g.start[c+1]] {
+				fn(i)
+			}
+		}
+	}
+}
+
+func (g *grid) col(x float64) int {
+	return clampIndex(x/g.cellSize, g.cols)
+}
+
+func (g *grid) row(y float64) int {
+	return clampIndex(y/g.cellSize, g.rows)
+}
+
+func clampIndex(v float64, n int) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= float64(n-1) {
+		return n - 1
+	}
+	return int(v)
+}
+
+func resizeInt32(s []int32, n int) []int32 {
+	if cap(s) < n {
+		return make([]int32, n)
+	}
+	return s[:n]
+}
@@ -0,0 +1,63 @@
+package fx
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestSystemBurst(t *testing.T) {
+	s := NewSystem(100)
+	center := geometry.Vector2D{X: 50, Y: 50}
+	red := color.RGBA{R: 255, A: 255}
+	s.Burst(center, red, BurstStyle{Count: 10, Speed: 100, Life: 1, Size: 2, Drag: 0.5})
+	if s.Len() != 10 {
+		t.Fatalf("Expected 10 particles, got %d", s.Len())
+	}
+	for _, p := range s.Particles() {
+		if p.Pos != center || p.Color != red || p.Alpha() != 1 {
+			t.Fatalf("Expected the particles to start opaque at the center, got %+v", p)
+		}
+		if speed := p.Vel.Len(); speed < 30-1e-9 || speed > 100+1e-9 {
+			t.Errorf("Initial speed %.2f is outside 30-100", speed)
+		}
+	}
+
+	s.Update(0.1)
+	for _, p := range s.Particles() {
+		if p.Pos == center {
+			t.Errorf("Expected the particles to move away from the center")
+		}
+		if a := p.Alpha(); a <= 0 || a >= 1 {
+			t.Errorf("Expected the particles to fade, alpha = %.2f", a)
+		}
+	}
+	// Every particle lives at most 1s
+	s.Update(1)
+	if s.Len() != 0 {
+		t.Errorf("Expected every particle to be dead after its life, %d left", s.Len())
+	}
+}
+
+func TestSystemCap(t *testing.T) {
+	s := NewSystem(25)
+	first := color.RGBA{R: 1, A: 255}
+	last := color.RGBA{B: 1, A: 255}
+	style := BurstStyle{Count: 10, Speed: 1, Life: 1}
+	s.Burst(geometry.Vector2D{}, first, style)
+	s.Burst(geometry.Vector2D{}, first, style)
+	s.Burst(geometry.Vector2D{}, last, style)
+	if s.Len() != 25 {
+		t.Fatalf("Expected the system to stay at its cap, got %d particles", s.Len())
+	}
+	// The oldest particles made room
+	ps := s.Particles()
+	if ps[0].Color != first || ps[len(ps)-1].Color != last || ps[len(ps)-11].Color != first {
+		t.Errorf("Expected the 5 oldest particles to be removed")
+	}
+	s.Reset()
+	if s.Len() != 0 {
+		t.Errorf("Expected Reset to remove every particle")
+	}
+}
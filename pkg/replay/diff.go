@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// Divergence compares the snapshots of the same tick in two recordings
+type Divergence struct {
+	Tick uint64
+	// Matched counts the entities present in both snapshots, Missing the ones present in only one
+	Matched int
+	Missing int
+	// ColorChanges counts the matched entities that are not in the same team
+	ColorChanges int
+	// Mean and Max displacement of the matched entities, in world units
+	Mean float64
+	Max  float64
+}
+
+// Diverged reports whether the snapshots differ by more than 'epsilon' world units,
+// or by any entity or team
+func (d Divergence) Diverged(epsilon float64) bool {
+	return d.Missing > 0 || d.ColorChanges > 0 || d.Max > epsilon
+}
+
+// Displacements returns, for every entity of 'a' also present in 'b', the distance between its two positions
+func Displacements(a, b *pb.WorldSnapshot) map[string]float64 {
+	positions := make(map[string]*pb.Vector, len(b.GetActors()))
+	for _, actor := range b.GetActors() {
+		positions[actor.Id] = actor.Position
+	}
+	out := make(map[string]float64, len(a.GetActors()))
+	for _, actor := range a.GetActors() {
+		if p, ok := positions[actor.Id]; ok {
+			out[actor.Id] = math.Hypot(actor.Position.GetX()-p.GetX(), actor.Position.GetY()-p.GetY())
+		}
+	}
+	return out
+}
+
+// Compare measures how far the snapshot 'b' is from 'a', entities are matched by ID
+func Compare(a, b *pb.WorldSnapshot) Divergence {
+	d := Divergence{Tick: a.GetTick()}
+	others := make(map[string]*pb.ActorState, len(b.GetActors()))
+	for _, actor := range b.GetActors() {
+		others[actor.Id] = actor
+	}
+	var sum float64
+	for _, actor := range a.GetActors() {
+		other, ok := others[actor.Id]
+		if !ok {
+			d.Missing++
+			continue
+		}
+		d.Matched++
+		if other.Color != actor.Color {
+			d.ColorChanges++
+		}
+		dist := math.Hypot(actor.Position.GetX()-other.Position.GetX(), actor.Position.GetY()-other.Position.GetY())
+		sum += dist
+		d.Max = max(d.Max, dist)
+	}
+	d.Missing += len(b.GetActors()) - d.Matched
+	if d.Matched > 0 {
+		d.Mean = sum / float64(d.Matched)
+	}
+	return d
+}
+
+// Diff compares two recordings tick by tick, only the ticks present in both are compared
+func Diff(a, b []*pb.WorldSnapshot) []Divergence {
+	var out []Divergence
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch ta, tb := a[i].GetTick(), b[j].GetTick(); {
+		case ta < tb:
+			i++
+		case ta > tb:
+			j++
+		default:
+			out = append(out, Compare(a[i], b[j]))
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// FirstDivergence returns the first tick where the recordings differ by more than 'epsilon' world units
+func FirstDivergence(divergences []Divergence, epsilon float64) (uint64, bool) {
+	for _, d := range divergences {
+		if d.Diverged(epsilon) {
+			return d.Tick, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func diffFrame(tick uint64, x float64, color pb.TeamColor, ids ...string) *pb.WorldSnapshot {
+	snap := &pb.WorldSnapshot{Tick: tick}
+	for _, id := range ids {
+		snap.Actors = append(snap.Actors, &pb.ActorState{Id: id, Color: color, Position: &pb.Vector{X: x, Y: 10}})
+	}
+	return snap
+}
+
+func TestDiff(t *testing.T) {
+	red, blue := pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE
+	a := []*pb.WorldSnapshot{
+		diffFrame(1, 0, red, "Red-000", "Red-001"),
+		diffFrame(2, 0, red, "Red-000", "Red-001"),
+		diffFrame(3, 0, red, "Red-000", "Red-001"),
+	}
+	b := []*pb.WorldSnapshot{
+		diffFrame(1, 0, red, "Red-000", "Red-001"),
+		// tick 2 was not recorded
+		diffFrame(3, 4, blue, "Red-000", "Red-002"),
+	}
+
+	divergences := Diff(a, b)
+	if len(divergences) != 2 || divergences[0].Tick != 1 || divergences[1].Tick != 3 {
+		t.Fatalf("Expected ticks 1 and 3 compared, got %+v", divergences)
+	}
+	if divergences[0].Diverged(0) {
+		t.Errorf("Expected identical frames at tick 1, got %+v", divergences[0])
+	}
+	want := Divergence{Tick: 3, Matched: 1, Missing: 2, ColorChanges: 1, Mean: 4, Max: 4}
+	if divergences[1] != want {
+		t.Errorf("Compare() = %+v, want %+v", divergences[1], want)
+	}
+	if tick, ok := FirstDivergence(divergences, 0.01); !ok || tick != 3 {
+		t.Errorf("FirstDivergence() = %d, %v, want 3", tick, ok)
+	}
+	if d := Displacements(a[2], b[1]); len(d) != 1 || d["Red-000"] != 4 {
+		t.Errorf("Displacements() = %v", d)
+	}
+}
@@ -0,0 +1,216 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// HighlightKind is the type of "interesting" moment found in a recording
+type HighlightKind string
+
+const (
+	// HighlightConversionCascade : the red population grows fast (mass conversion of blues)
+	HighlightConversionCascade HighlightKind = "conversion-cascade"
+	// HighlightDefense : the red population shrinks fast (blues successfully defend)
+	HighlightDefense HighlightKind = "successful-defense"
+	// HighlightNearExtinction : a team is about to disappear
+	HighlightNearExtinction HighlightKind = "near-extinction"
+	// HighlightGameOver : the last frame, one team won
+	HighlightGameOver HighlightKind = "game-over"
+)
+
+// Highlight is a tick range of a recording worth watching
+type Highlight struct {
+	Kind        HighlightKind `json:"kind"`
+	StartTick   uint64        `json:"startTick"`
+	EndTick     uint64        `json:"endTick"`
+	Score       float64       `json:"score"` // Fraction of the population involved
+	Description string        `json:"description"`
+}
+
+// Sample is the population of the world at a given tick
+type Sample struct {
+	Tick uint64
+	Red  int
+	Blue int
+}
+
+// HighlightOptions tunes the detection heuristics
+type HighlightOptions struct {
+	// Window is the number of ticks over which population swings are measured
+	Window uint64
+	// SwingThreshold is the fraction of the total population that must switch team within Window
+	SwingThreshold float64
+	// ExtinctionThreshold is the fraction of the total population under which a team is "nearly extinct"
+	ExtinctionThreshold float64
+}
+
+// DefaultHighlightOptions returns heuristics suited to the default configuration
+func DefaultHighlightOptions() HighlightOptions {
+	return HighlightOptions{
+		Window:              120, // ~2 seconds at 60 TPS
+		SwingThreshold:      0.10,
+		ExtinctionThreshold: 0.05,
+	}
+}
+
+// SamplesFromSnapshots extracts the population time series of a recording
+func SamplesFromSnapshots(frames []*pb.WorldSnapshot) []Sample {
+	samples := make([]Sample, 0, len(frames))
+	for _, f := range frames {
+		samples = append(samples, Sample{Tick: f.GetTick(), Red: int(f.GetRedCount()), Blue: int(f.GetBlueCount())})
+	}
+	return samples
+}
+
+// DetectHighlights scans the population time series (ordered by tick) and returns
+// the interesting tick ranges, sorted by start tick. Overlapping ranges of the same kind are merged.
+func DetectHighlights(samples []Sample, opts HighlightOptions) []Highlight {
+	var found []Highlight
+	if len(samples) == 0 {
+		return found
+	}
+
+	// 1. Population swings over a sliding window
+	start := 0
+	for end := 1; end < len(samples); end++ {
+		for samples[end].Tick-samples[start].Tick > opts.Window {
+			start++
+		}
+		total := samples[end].Red + samples[end].Blue
+		if total == 0 {
+			continue
+		}
+		delta := samples[end].Red - samples[start].Red
+		swing := float64(abs(delta)) / float64(total)
+		if swing < opts.SwingThreshold {
+			continue
+		}
+		h := Highlight{
+			StartTick: samples[start].Tick,
+			EndTick:   samples[end].Tick,
+			Score:     swing,
+		}
+		if delta > 0 {
+			h.Kind = HighlightConversionCascade
+			h.Description = fmt.Sprintf("%d blues converted", delta)
+		} else {
+			h.Kind = HighlightDefense
+			h.Description = fmt.Sprintf("%d reds converted by defenders", -delta)
+		}
+		found = append(found, h)
+	}
+
+	// 2. Near extinctions (a team is below the threshold but still alive)
+	var current *Highlight
+	for _, s := range samples {
+		total := s.Red + s.Blue
+		smallest := min(s.Red, s.Blue)
+		if total > 0 && smallest > 0 && float64(smallest)/float64(total) <= opts.ExtinctionThreshold {
+			if current == nil {
+				team := "red"
+				if s.Blue < s.Red {
+					team = "blue"
+				}
+				current = &Highlight{
+					Kind:        HighlightNearExtinction,
+					StartTick:   s.Tick,
+					Score:       1 - float64(smallest)/float64(total),
+					Description: fmt.Sprintf("%s team down to %d", team, smallest),
+				}
+			}
+			current.EndTick = s.Tick
+			continue
+		}
+		if current != nil {
+			found = append(found, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		found = append(found, *current)
+	}
+
+	// 3. Game over
+	last := samples[len(samples)-1]
+	if last.Red+last.Blue > 0 && (last.Red == 0 || last.Blue == 0) {
+		winner := "blue"
+		if last.Blue == 0 {
+			winner = "red"
+		}
+		found = append(found, Highlight{
+			Kind:        HighlightGameOver,
+			StartTick:   last.Tick,
+			EndTick:     last.Tick,
+			Score:       1,
+			Description: winner + " team wins",
+		})
+	}
+
+	return mergeHighlights(found)
+}
+
+// mergeHighlights sorts the highlights and merges the overlapping ones of the same kind
+func mergeHighlights(found []Highlight) []Highlight {
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Kind != found[j].Kind {
+			return found[i].Kind < found[j].Kind
+		}
+		return found[i].StartTick < found[j].StartTick
+	})
+	merged := make([]Highlight, 0, len(found))
+	for _, h := range found {
+		if n := len(merged); n > 0 && merged[n-1].Kind == h.Kind && h.StartTick <= merged[n-1].EndTick {
+			last := &merged[n-1]
+			last.EndTick = max(last.EndTick, h.EndTick)
+			if h.Score > last.Score {
+				last.Score = h.Score
+				last.Description = h.Description
+			}
+			continue
+		}
+		merged = append(merged, h)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].StartTick < merged[j].StartTick
+	})
+	return merged
+}
+
+// HighlightsPath returns the path of the highlights index of a recording
+func HighlightsPath(recordingPath string) string {
+	return recordingPath + ".highlights.json"
+}
+
+// WriteHighlights saves the highlights index as JSON
+func WriteHighlights(path string, highlights []Highlight) error {
+	b, err := json.MarshalIndent(highlights, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// ReadHighlights loads a highlights index written by WriteHighlights
+func ReadHighlights(path string) ([]Highlight, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var highlights []Highlight
+	if err := json.Unmarshal(b, &highlights); err != nil {
+		return nil, fmt.Errorf("replay: invalid highlights index: %w", err)
+	}
+	return highlights, nil
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
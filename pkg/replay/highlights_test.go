@@ -0,0 +1,61 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectHighlights(t *testing.T) {
+	// 100 entities: stable, then a cascade of conversions, then blues nearly extinct and game over
+	var samples []Sample
+	red := 10
+	for tick := uint64(0); tick <= 1000; tick += 10 {
+		switch {
+		case tick > 300 && tick <= 400:
+			red += 4 // +40 reds in 100 ticks
+		case tick > 400 && red < 100:
+			red++
+		}
+		samples = append(samples, Sample{Tick: tick, Red: red, Blue: 100 - red})
+	}
+
+	highlights := DetectHighlights(samples, DefaultHighlightOptions())
+
+	kinds := make(map[HighlightKind]Highlight)
+	for _, h := range highlights {
+		if _, dup := kinds[h.Kind]; dup && h.Kind != HighlightConversionCascade {
+			t.Errorf("Expected a single merged %s highlight, got several: %v", h.Kind, highlights)
+		}
+		kinds[h.Kind] = h
+	}
+	cascade, ok := kinds[HighlightConversionCascade]
+	if !ok {
+		t.Fatalf("Expected a conversion cascade, got %v", highlights)
+	}
+	if cascade.StartTick > 310 || cascade.EndTick < 400 {
+		t.Errorf("Expected cascade to cover ticks 310-400, got %d-%d", cascade.StartTick, cascade.EndTick)
+	}
+	if _, ok := kinds[HighlightNearExtinction]; !ok {
+		t.Errorf("Expected a near-extinction highlight, got %v", highlights)
+	}
+	if _, ok := kinds[HighlightDefense]; ok {
+		t.Errorf("Did not expect a defense highlight, got %v", highlights)
+	}
+	if h, ok := kinds[HighlightGameOver]; !ok || h.StartTick != 1000 {
+		t.Errorf("Expected game over at tick 1000, got %v", highlights)
+	}
+	for i := 1; i < len(highlights); i++ {
+		if highlights[i].StartTick < highlights[i-1].StartTick {
+			t.Errorf("Expected highlights sorted by start tick, got %v", highlights)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "run.highlights.json")
+	if err := WriteHighlights(path, highlights); err != nil {
+		t.Fatalf("WriteHighlights() error = %v", err)
+	}
+	loaded, err := ReadHighlights(path)
+	if err != nil || len(loaded) != len(highlights) {
+		t.Errorf("ReadHighlights() = %v, %v; want %d highlights", loaded, err, len(highlights))
+	}
+}
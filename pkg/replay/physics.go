@@ -0,0 +1,216 @@
+package replay
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// PhysicsHeader is the first row of a physics validation file
+var PhysicsHeader = []string{"tick", "entities", "kinetic_energy", "momentum_x", "momentum_y", "momentum", "max_speed", "violations"}
+
+// Physics are the conserved quantities of a snapshot, every entity having a unit mass. The rules
+// of the simulation do not conserve them (the boids accelerate and brake), but they stay bounded:
+// a sudden jump shows a force or an integration step that makes the system numerically explode.
+type Physics struct {
+	Tick     uint64
+	Entities int
+	// KineticEnergy is the sum of v²/2, MomentumX and MomentumY the sum of the velocities
+	KineticEnergy float64
+	MomentumX     float64
+	MomentumY     float64
+	MaxSpeed      float64
+}
+
+// PhysicsOf computes the physics of the entities listed in a snapshot
+func PhysicsOf(snap *pb.WorldSnapshot) Physics {
+	p := Physics{Tick: snap.GetTick(), Entities: len(snap.GetActors())}
+	for _, actor := range snap.GetActors() {
+		vx, vy := actor.Velocity.GetX(), actor.Velocity.GetY()
+		p.KineticEnergy += (vx*vx + vy*vy) / 2
+		p.MomentumX += vx
+		p.MomentumY += vy
+		p.MaxSpeed = max(p.MaxSpeed, math.Hypot(vx, vy))
+	}
+	return p
+}
+
+// Momentum returns the norm of the total momentum
+func (p Physics) Momentum() float64 {
+	return math.Hypot(p.MomentumX, p.MomentumY)
+}
+
+// MeanEnergy returns the kinetic energy per entity (0 without entity)
+func (p Physics) MeanEnergy() float64 {
+	if p.Entities == 0 {
+		return 0
+	}
+	return p.KineticEnergy / float64(p.Entities)
+}
+
+// Record returns the CSV row of the physics, in the order of PhysicsHeader
+func (p Physics) Record(violations []PhysicsViolation) []string {
+	kinds := make([]string, len(violations))
+	for i, v := range violations {
+		kinds[i] = string(v.Kind)
+	}
+	return []string{
+		strconv.FormatUint(p.Tick, 10),
+		strconv.Itoa(p.Entities),
+		strconv.FormatFloat(p.KineticEnergy, 'f', 4, 64),
+		strconv.FormatFloat(p.MomentumX, 'f', 4, 64),
+		strconv.FormatFloat(p.MomentumY, 'f', 4, 64),
+		strconv.FormatFloat(p.Momentum(), 'f', 4, 64),
+		strconv.FormatFloat(p.MaxSpeed, 'f', 4, 64),
+		strings.Join(kinds, ";"),
+	}
+}
+
+// ViolationKind is the type of a PhysicsViolation
+type ViolationKind string
+
+const (
+	// ViolationNonFinite : a velocity is NaN or infinite
+	ViolationNonFinite ViolationKind = "non-finite"
+	// ViolationSpeed : an entity is faster than PhysicsLimits.MaxSpeed
+	ViolationSpeed ViolationKind = "speed"
+	// ViolationEnergyJump : the kinetic energy per entity grew faster than PhysicsLimits.EnergyJump in one tick
+	ViolationEnergyJump ViolationKind = "energy-jump"
+)
+
+// PhysicsViolation is a tick where the physics left the limits
+type PhysicsViolation struct {
+	Tick  uint64
+	Kind  ViolationKind
+	Value float64
+	Limit float64
+}
+
+func (v PhysicsViolation) String() string {
+	return fmt.Sprintf("tick %d: %s %.4g (limit %.4g)", v.Tick, v.Kind, v.Value, v.Limit)
+}
+
+// PhysicsLimits are the bounds checked by a PhysicsValidator, a zero limit is not checked
+type PhysicsLimits struct {
+	// MaxSpeed is the highest speed of an entity
+	MaxSpeed float64
+	// EnergyJump is the largest relative increase of the kinetic energy per entity between
+	// two consecutive ticks (0.5 = +50%)
+	EnergyJump float64
+}
+
+// DefaultPhysicsLimits accepts speeds up to 50% above the configured max speed
+// (the steering overshoots it before clamping) and energy jumps up to +50% per tick
+func DefaultPhysicsLimits(maxSpeed float64) PhysicsLimits {
+	return PhysicsLimits{MaxSpeed: maxSpeed * 1.5, EnergyJump: 0.5}
+}
+
+// PhysicsValidator checks the physics of consecutive ticks against its limits,
+// which may be changed between two calls (e.g. when the max speed slider moves)
+type PhysicsValidator struct {
+	Limits  PhysicsLimits
+	prev    Physics
+	hasPrev bool
+}
+
+// NewPhysicsValidator creates a validator without history
+func NewPhysicsValidator(limits PhysicsLimits) *PhysicsValidator {
+	return &PhysicsValidator{Limits: limits}
+}
+
+// Check returns the violations of a tick, nil when everything is within the limits
+func (v *PhysicsValidator) Check(p Physics) []PhysicsViolation {
+	var violations []PhysicsViolation
+	if !isFinite(p.KineticEnergy) || !isFinite(p.MomentumX) || !isFinite(p.MomentumY) {
+		// Nothing else is meaningful, and the next tick is compared with a sane one
+		return []PhysicsViolation{{Tick: p.Tick, Kind: ViolationNonFinite, Value: p.KineticEnergy}}
+	}
+	if v.Limits.MaxSpeed > 0 && p.MaxSpeed > v.Limits.MaxSpeed {
+		violations = append(violations, PhysicsViolation{Tick: p.Tick, Kind: ViolationSpeed, Value: p.MaxSpeed, Limit: v.Limits.MaxSpeed})
+	}
+	if v.hasPrev && v.Limits.EnergyJump > 0 && v.prev.MeanEnergy() > 0 {
+		if jump := p.MeanEnergy()/v.prev.MeanEnergy() - 1; jump > v.Limits.EnergyJump {
+			violations = append(violations, PhysicsViolation{Tick: p.Tick, Kind: ViolationEnergyJump, Value: jump, Limit: v.Limits.EnergyJump})
+		}
+	}
+	v.prev, v.hasPrev = p, true
+	return violations
+}
+
+// Reset forgets the previous tick, e.g. when the simulation restarts
+func (v *PhysicsValidator) Reset() {
+	v.hasPrev = false
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// PhysicsWriter appends one CSV row of physics per tick to a file
+type PhysicsWriter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// CreatePhysics creates (or truncates) the physics file at 'path' and writes its header
+func CreatePhysics(path string) (*PhysicsWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: cannot create physics file: %w", err)
+	}
+	w := &PhysicsWriter{f: f, w: csv.NewWriter(f)}
+	if err := w.w.Write(PhysicsHeader); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("replay: cannot write physics header: %w", err)
+	}
+	return w, nil
+}
+
+// Write appends the physics of one tick with its violations
+func (w *PhysicsWriter) Write(p Physics, violations []PhysicsViolation) error {
+	if err := w.w.Write(p.Record(violations)); err != nil {
+		return fmt.Errorf("replay: cannot write physics: %w", err)
+	}
+	return nil
+}
+
+// Flush writes the buffered rows to the file
+func (w *PhysicsWriter) Flush() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("replay: cannot write physics: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the file
+func (w *PhysicsWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// RecordPhysics validates and writes the physics of every snapshot received until the channel is closed.
+// onViolation, if not nil, is called for every violation.
+func RecordPhysics(w *PhysicsWriter, v *PhysicsValidator, snapshots <-chan *pb.WorldSnapshot, onViolation func(PhysicsViolation)) error {
+	for snap := range snapshots {
+		p := PhysicsOf(snap)
+		violations := v.Check(p)
+		if onViolation != nil {
+			for _, violation := range violations {
+				onViolation(violation)
+			}
+		}
+		if err := w.Write(p, violations); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,105 @@
+package replay
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestPhysicsOf(t *testing.T) {
+	snap := &pb.WorldSnapshot{
+		Tick: 7,
+		Actors: []*pb.ActorState{
+			{Id: "Red-000", Velocity: &pb.Vector{X: 3, Y: 4}},
+			{Id: "Blue-000", Velocity: &pb.Vector{X: -1}},
+		},
+	}
+	p := PhysicsOf(snap)
+	if p.Entities != 2 || p.KineticEnergy != 13 || p.MomentumX != 2 || p.MomentumY != 4 || p.MaxSpeed != 5 {
+		t.Errorf("PhysicsOf() = %+v", p)
+	}
+	want := []string{"7", "2", "13.0000", "2.0000", "4.0000", "4.4721", "5.0000", "speed;energy-jump"}
+	violations := []PhysicsViolation{{Kind: ViolationSpeed}, {Kind: ViolationEnergyJump}}
+	if got := p.Record(violations); !reflect.DeepEqual(got, want) {
+		t.Errorf("Record() = %v, expected %v", got, want)
+	}
+}
+
+func TestPhysicsValidator(t *testing.T) {
+	v := NewPhysicsValidator(DefaultPhysicsLimits(4))
+	steady := Physics{Tick: 1, Entities: 10, KineticEnergy: 50, MaxSpeed: 4}
+	if got := v.Check(steady); got != nil {
+		t.Errorf("Expected no violation, got %v", got)
+	}
+	steady.Tick = 2
+	steady.KineticEnergy = 60 // +20%
+	if got := v.Check(steady); got != nil {
+		t.Errorf("Expected a small increase to be accepted, got %v", got)
+	}
+
+	explosion := Physics{Tick: 3, Entities: 10, KineticEnergy: 600, MaxSpeed: 11}
+	got := v.Check(explosion)
+	if len(got) != 2 || got[0].Kind != ViolationSpeed || got[0].Limit != 6 || got[1].Kind != ViolationEnergyJump {
+		t.Errorf("Expected a speed and an energy violation, got %v", got)
+	}
+
+	nan := Physics{Tick: 4, Entities: 10, KineticEnergy: math.NaN()}
+	if got := v.Check(nan); len(got) != 1 || got[0].Kind != ViolationNonFinite {
+		t.Errorf("Expected a non-finite violation, got %v", got)
+	}
+
+	// The population doubling does not change the energy per entity
+	v.Reset()
+	v.Check(Physics{Tick: 1, Entities: 10, KineticEnergy: 50})
+	if got := v.Check(Physics{Tick: 2, Entities: 20, KineticEnergy: 100}); got != nil {
+		t.Errorf("Expected the energy to be compared per entity, got %v", got)
+	}
+}
+
+func TestRecordPhysics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "physics.csv")
+	w, err := CreatePhysics(path)
+	if err != nil {
+		t.Fatalf("CreatePhysics() error = %v", err)
+	}
+	snapshots := make(chan *pb.WorldSnapshot, 3)
+	for tick, speed := range []float64{2, 2, 20} {
+		snapshots <- &pb.WorldSnapshot{
+			Tick:   uint64(tick + 1),
+			Actors: []*pb.ActorState{{Id: "Red-000", Velocity: &pb.Vector{X: speed}}},
+		}
+	}
+	close(snapshots)
+	var violations []PhysicsViolation
+	v := NewPhysicsValidator(DefaultPhysicsLimits(4))
+	if err := RecordPhysics(w, v, snapshots, func(pv PhysicsViolation) { violations = append(violations, pv) }); err != nil {
+		t.Fatalf("RecordPhysics() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(violations) != 2 || violations[0].Tick != 3 {
+		t.Errorf("Expected the speed and energy violations of tick 3, got %v", violations)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Cannot parse the physics file: %v", err)
+	}
+	if len(rows) != 4 || !reflect.DeepEqual(rows[0], PhysicsHeader) {
+		t.Fatalf("Expected the header and 3 rows, got %v", rows)
+	}
+	if rows[2][7] != "" || rows[3][7] != "speed;energy-jump" {
+		t.Errorf("Unexpected violations column %q, %q", rows[2][7], rows[3][7])
+	}
+}
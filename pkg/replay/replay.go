@@ -0,0 +1,221 @@
+// Package replay stores simulation runs as recordings (a stream of size-delimited pb.WorldSnapshot messages)
+// and analyzes them afterwards.
+package replay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// DefaultSyncInterval is the period at which a Writer flushes and syncs its file to disk
+const DefaultSyncInterval = 5 * time.Second
+
+// ErrTruncated is returned when a recording ends in the middle of a snapshot,
+// usually because the process writing it crashed. The snapshots before are intact.
+var ErrTruncated = errors.New("replay: recording truncated")
+
+// Writer appends snapshots to a recording file. The file is opened in append-only mode and
+// synced to disk every sync interval, so a crash loses at most the last interval of the run
+// (Recover then removes the snapshot torn by the crash).
+type Writer struct {
+	f            *os.File
+	bw           *bufio.Writer
+	syncInterval time.Duration
+	lastSync     time.Time
+}
+
+// WriterOption configures a Writer
+type WriterOption func(w *Writer)
+
+// WithSyncInterval sets the period of the flush and fsync (DefaultSyncInterval by default),
+// 0 disables the periodic sync: the data reaches the disk on Flush and Close only
+func WithSyncInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.syncInterval = interval
+	}
+}
+
+// Create creates (or truncates) the recording file at 'path'
+func Create(path string, opts ...WriterOption) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("replay: cannot create recording: %w", err)
+	}
+	w := &Writer{f: f, bw: bufio.NewWriter(f), syncInterval: DefaultSyncInterval, lastSync: time.Now()}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
+}
+
+// Write appends one snapshot to the recording
+func (w *Writer) Write(snap *pb.WorldSnapshot) error {
+	if _, err := protodelim.MarshalTo(w.bw, snap); err != nil {
+		return fmt.Errorf("replay: cannot write snapshot: %w", err)
+	}
+	if w.syncInterval > 0 && time.Since(w.lastSync) >= w.syncInterval {
+		return w.Sync()
+	}
+	return nil
+}
+
+// Flush writes the buffered snapshots to the file
+func (w *Writer) Flush() error {
+	return w.bw.Flush()
+}
+
+// Sync flushes the buffered snapshots and commits the file to stable storage
+func (w *Writer) Sync() error {
+	w.lastSync = time.Now()
+	if err := w.bw.Flush(); err != nil {
+		return fmt.Errorf("replay: cannot flush recording: %w", err)
+	}
+	if err := w.f.Sync(); err != nil {
+		return fmt.Errorf("replay: cannot sync recording: %w", err)
+	}
+	return nil
+}
+
+// Close syncs and closes the recording file
+func (w *Writer) Close() error {
+	if err := w.Sync(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Recovery describes what Recover found in a recording
+type Recovery struct {
+	Frames  int   // Intact snapshots
+	Size    int64 // Size of the intact part, in bytes
+	Dropped int64 // Bytes of the torn snapshot removed from the end
+}
+
+// Recover checks a recording left by a previous run and truncates the snapshot torn by a crash,
+// if any, so that the file can be read and appended to again
+func Recover(path string) (Recovery, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return Recovery{}, fmt.Errorf("replay: cannot open recording: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Recovery{}, fmt.Errorf("replay: cannot open recording: %w", err)
+	}
+
+	r := &countingReader{br: bufio.NewReader(f)}
+	var rec Recovery
+	for {
+		if err := protodelim.UnmarshalFrom(r, &pb.WorldSnapshot{}); err != nil {
+			break
+		}
+		rec.Frames++
+		rec.Size = r.n
+	}
+	rec.Dropped = info.Size() - rec.Size
+	if rec.Dropped > 0 {
+		if err := f.Truncate(rec.Size); err != nil {
+			return rec, fmt.Errorf("replay: cannot truncate recording: %w", err)
+		}
+		if err := f.Sync(); err != nil {
+			return rec, fmt.Errorf("replay: cannot sync recording: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+// countingReader counts the bytes consumed by protodelim
+type countingReader struct {
+	br *bufio.Reader
+	n  int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.br.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.br.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// Record writes every snapshot received on 'snapshots' until the channel is closed
+func Record(w *Writer, snapshots <-chan *pb.WorldSnapshot) error {
+	for snap := range snapshots {
+		if err := w.Write(snap); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Reader reads the snapshots of a recording in order
+type Reader struct {
+	f  *os.File
+	br *bufio.Reader
+}
+
+// Open opens the recording file at 'path'
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: cannot open recording: %w", err)
+	}
+	return &Reader{f: f, br: bufio.NewReader(f)}, nil
+}
+
+// Next returns the next snapshot, io.EOF at the end of the recording,
+// or ErrTruncated when the recording ends in the middle of a snapshot
+func (r *Reader) Next() (*pb.WorldSnapshot, error) {
+	snap := &pb.WorldSnapshot{}
+	if err := protodelim.UnmarshalFrom(r.br, snap); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrTruncated
+		}
+		return nil, fmt.Errorf("replay: cannot read snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// Close closes the recording file
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+// ReadAll loads every snapshot of a recording in memory. The snapshots read before an error
+// are returned with it, e.g. the intact part of a recording cut by a crash with ErrTruncated.
+func ReadAll(path string) ([]*pb.WorldSnapshot, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var frames []*pb.WorldSnapshot
+	for {
+		snap, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return frames, nil
+		}
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, snap)
+	}
+}
@@ -0,0 +1,124 @@
+package replay
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestWriterReader_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.swarm")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for tick := uint64(1); tick <= 3; tick++ {
+		snap := &pb.WorldSnapshot{
+			Tick:      tick,
+			RedCount:  int32(tick),
+			BlueCount: 10,
+			Actors: []*pb.ActorState{
+				{Id: "Red-000", Color: pb.TeamColor_TEAM_RED, Position: &pb.Vector{X: float64(tick), Y: 2}},
+			},
+		}
+		if err := w.Write(snap); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	frames, err := ReadAll(path)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("Expected 3 frames, got %d", len(frames))
+	}
+	if frames[2].Tick != 3 || frames[2].RedCount != 3 || frames[2].Actors[0].Position.X != 3 {
+		t.Errorf("Unexpected last frame %v", frames[2])
+	}
+}
+
+// writeRecording writes 'n' snapshots without closing the writer, like a run that crashes
+func writeRecording(t *testing.T, path string, n int, opts ...WriterOption) *Writer {
+	t.Helper()
+	w, err := Create(path, opts...)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for tick := uint64(1); tick <= uint64(n); tick++ {
+		if err := w.Write(&pb.WorldSnapshot{Tick: tick, RedCount: 5, BlueCount: 5}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	return w
+}
+
+func TestWriter_SyncInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.swarm")
+	// Every write is synced: the data is on disk before Close
+	w := writeRecording(t, path, 3, WithSyncInterval(1))
+	defer w.Close()
+	frames, err := ReadAll(path)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(frames) != 3 {
+		t.Errorf("Expected 3 synced frames, got %d", len(frames))
+	}
+
+	// No periodic sync: everything is still buffered
+	other := filepath.Join(t.TempDir(), "run.swarm")
+	w2 := writeRecording(t, other, 3, WithSyncInterval(0))
+	defer w2.Close()
+	if frames, _ := ReadAll(other); len(frames) != 0 {
+		t.Errorf("Expected no frame before Flush, got %d", len(frames))
+	}
+}
+
+func TestRecover_TornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.swarm")
+	w := writeRecording(t, path, 4)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cut the last snapshot in the middle, as a crash during a write would
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	frames, err := ReadAll(path)
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("ReadAll() error = %v, want ErrTruncated", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("Expected the 3 intact frames, got %d", len(frames))
+	}
+
+	rec, err := Recover(path)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if rec.Frames != 3 || rec.Dropped == 0 {
+		t.Errorf("Unexpected recovery %+v", rec)
+	}
+	frames, err = ReadAll(path)
+	if err != nil || len(frames) != 3 {
+		t.Fatalf("After Recover, ReadAll() = %d frames, %v", len(frames), err)
+	}
+
+	// An intact recording is left untouched
+	rec, err = Recover(path)
+	if err != nil || rec.Dropped != 0 || rec.Frames != 3 {
+		t.Errorf("Recover() on an intact recording = %+v, %v", rec, err)
+	}
+}
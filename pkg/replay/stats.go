@@ -0,0 +1,141 @@
+package replay
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// StatsHeader is the first row of a statistics file, the civilians last: the files written before
+// them keep their columns
+var StatsHeader = []string{"tick", "red", "blue", "conversions", "red_speed", "blue_speed", "tick_duration_us",
+	"neutral", "neutral_speed"}
+
+// Stats are the per-tick statistics of a snapshot
+type Stats struct {
+	Tick        uint64
+	Red         int32
+	Blue        int32
+	Neutral     int32
+	Conversions uint32
+	// RedSpeed, BlueSpeed and NeutralSpeed are the average speeds of the listed entities of each
+	// team and of the civilians
+	RedSpeed     float64
+	BlueSpeed    float64
+	NeutralSpeed float64
+	// TickDuration is the wall time the engine spent on the tick
+	TickDuration time.Duration
+}
+
+// StatsOf computes the statistics of a snapshot
+func StatsOf(snap *pb.WorldSnapshot) Stats {
+	s := Stats{
+		Tick:         snap.GetTick(),
+		Red:          snap.GetRedCount(),
+		Blue:         snap.GetBlueCount(),
+		Neutral:      snap.GetNeutralCount(),
+		Conversions:  snap.GetConversions(),
+		TickDuration: time.Duration(snap.GetTickDuration()),
+	}
+	var reds, blues, neutrals int
+	for _, actor := range snap.GetActors() {
+		speed := math.Hypot(actor.Velocity.GetX(), actor.Velocity.GetY())
+		switch actor.Color {
+		case pb.TeamColor_TEAM_RED:
+			s.RedSpeed += speed
+			reds++
+		case pb.TeamColor_TEAM_BLUE:
+			s.BlueSpeed += speed
+			blues++
+		case pb.TeamColor_TEAM_NEUTRAL:
+			s.NeutralSpeed += speed
+			neutrals++
+		}
+	}
+	if reds > 0 {
+		s.RedSpeed /= float64(reds)
+	}
+	if blues > 0 {
+		s.BlueSpeed /= float64(blues)
+	}
+	if neutrals > 0 {
+		s.NeutralSpeed /= float64(neutrals)
+	}
+	return s
+}
+
+// Record returns the CSV row of the statistics, in the order of StatsHeader
+func (s Stats) Record() []string {
+	return []string{
+		strconv.FormatUint(s.Tick, 10),
+		strconv.FormatInt(int64(s.Red), 10),
+		strconv.FormatInt(int64(s.Blue), 10),
+		strconv.FormatUint(uint64(s.Conversions), 10),
+		strconv.FormatFloat(s.RedSpeed, 'f', 4, 64),
+		strconv.FormatFloat(s.BlueSpeed, 'f', 4, 64),
+		strconv.FormatInt(s.TickDuration.Microseconds(), 10),
+		strconv.FormatInt(int64(s.Neutral), 10),
+		strconv.FormatFloat(s.NeutralSpeed, 'f', 4, 64),
+	}
+}
+
+// StatsWriter appends one CSV row of statistics per snapshot to a file
+type StatsWriter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// CreateStats creates (or truncates) the statistics file at 'path' and writes its header
+func CreateStats(path string) (*StatsWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: cannot create statistics file: %w", err)
+	}
+	w := &StatsWriter{f: f, w: csv.NewWriter(f)}
+	if err := w.w.Write(StatsHeader); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("replay: cannot write statistics header: %w", err)
+	}
+	return w, nil
+}
+
+// Write appends the statistics of one snapshot
+func (w *StatsWriter) Write(snap *pb.WorldSnapshot) error {
+	if err := w.w.Write(StatsOf(snap).Record()); err != nil {
+		return fmt.Errorf("replay: cannot write statistics: %w", err)
+	}
+	return nil
+}
+
+// Flush writes the buffered rows to the file
+func (w *StatsWriter) Flush() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("replay: cannot write statistics: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the file
+func (w *StatsWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// RecordStats writes the statistics of every snapshot received until the channel is closed
+func RecordStats(w *StatsWriter, snapshots <-chan *pb.WorldSnapshot) error {
+	for snap := range snapshots {
+		if err := w.Write(snap); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
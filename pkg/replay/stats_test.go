@@ -0,0 +1,69 @@
+package replay
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestStatsOf(t *testing.T) {
+	snap := &pb.WorldSnapshot{
+		Tick:         7,
+		RedCount:     1,
+		BlueCount:    2,
+		NeutralCount: 1,
+		Conversions:  3,
+		TickDuration: int64(1500 * time.Microsecond),
+		Actors: []*pb.ActorState{
+			{Id: "Red-000", Color: pb.TeamColor_TEAM_RED, Velocity: &pb.Vector{X: 3, Y: 4}},
+			{Id: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Velocity: &pb.Vector{X: 1}},
+			{Id: "Blue-001", Color: pb.TeamColor_TEAM_BLUE, Velocity: &pb.Vector{Y: -3}},
+			// A civilian is not a blue
+			{Id: "Neutral-000", Color: pb.TeamColor_TEAM_NEUTRAL, Velocity: &pb.Vector{X: 0.5}},
+		},
+	}
+	want := []string{"7", "1", "2", "3", "5.0000", "2.0000", "1500", "1", "0.5000"}
+	if got := StatsOf(snap).Record(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Record() = %v, expected %v", got, want)
+	}
+}
+
+func TestRecordStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.csv")
+	w, err := CreateStats(path)
+	if err != nil {
+		t.Fatalf("CreateStats() error = %v", err)
+	}
+	snapshots := make(chan *pb.WorldSnapshot, 3)
+	for tick := uint64(1); tick <= 3; tick++ {
+		snapshots <- &pb.WorldSnapshot{Tick: tick, RedCount: int32(tick)}
+	}
+	close(snapshots)
+	if err := RecordStats(w, snapshots); err != nil {
+		t.Fatalf("RecordStats() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Cannot parse the statistics file: %v", err)
+	}
+	if len(rows) != 4 || !reflect.DeepEqual(rows[0], StatsHeader) {
+		t.Fatalf("Expected the header and 3 rows, got %v", rows)
+	}
+	if rows[3][0] != "3" || rows[3][1] != "3" {
+		t.Errorf("Unexpected last row %v", rows[3])
+	}
+}
@@ -0,0 +1,90 @@
+package replay
+
+// SteadyStateOptions tunes the steady-state heuristic
+type SteadyStateOptions struct {
+	// Window is the number of ticks over which the population must stay stable
+	Window uint64
+	// Tolerance is the largest change of the red fraction of the population allowed within Window
+	Tolerance float64
+}
+
+// DefaultSteadyStateOptions returns heuristics suited to the default configuration
+func DefaultSteadyStateOptions() SteadyStateOptions {
+	return SteadyStateOptions{
+		Window:    300, // ~5 seconds at 60 TPS
+		Tolerance: 0.02,
+	}
+}
+
+// SteadyStateDetector flags when the population dynamics settled: the red fraction of the
+// population varied by less than Tolerance over the last Window ticks. Batch experiments
+// start averaging there, so that the spawn transient does not contaminate their metrics.
+type SteadyStateDetector struct {
+	opts SteadyStateOptions
+	// samples of the last Window ticks, in tick order
+	samples []Sample
+	first   uint64 // tick of the first sample ever added
+	started bool
+	since   uint64 // tick at which the current steady state started
+	steady  bool
+}
+
+// NewSteadyStateDetector creates a detector with no samples
+func NewSteadyStateDetector(opts SteadyStateOptions) *SteadyStateDetector {
+	return &SteadyStateDetector{opts: opts}
+}
+
+// Add appends the population of a tick (ticks must increase) and reports whether the
+// population is in steady state
+func (d *SteadyStateDetector) Add(s Sample) bool {
+	if !d.started {
+		d.first, d.started = s.Tick, true
+	}
+	d.samples = append(d.samples, s)
+	drop := 0
+	for s.Tick-d.samples[drop].Tick > d.opts.Window {
+		drop++
+	}
+	d.samples = d.samples[drop:]
+
+	steady := s.Tick-d.first >= d.opts.Window && d.spread() <= d.opts.Tolerance
+	if steady && !d.steady {
+		d.since = d.samples[0].Tick
+	}
+	d.steady = steady
+	return steady
+}
+
+// spread returns the range of the red fraction over the retained samples
+func (d *SteadyStateDetector) spread() float64 {
+	lo, hi := 1.0, 0.0
+	for _, s := range d.samples {
+		total := s.Red + s.Blue
+		if total == 0 {
+			continue
+		}
+		f := float64(s.Red) / float64(total)
+		lo, hi = min(lo, f), max(hi, f)
+	}
+	return max(0, hi-lo)
+}
+
+// Steady reports whether the last sample added is in steady state
+func (d *SteadyStateDetector) Steady() bool {
+	return d.steady
+}
+
+// Since returns the first tick of the current steady state, ok is false when the population is not steady
+func (d *SteadyStateDetector) Since() (tick uint64, ok bool) {
+	return d.since, d.steady
+}
+
+// SteadyStateTick scans the population time series (ordered by tick) and returns the tick
+// at which the steady state that lasts until the end of the series started
+func SteadyStateTick(samples []Sample, opts SteadyStateOptions) (uint64, bool) {
+	d := NewSteadyStateDetector(opts)
+	for _, s := range samples {
+		d.Add(s)
+	}
+	return d.Since()
+}
@@ -0,0 +1,51 @@
+package replay
+
+import "testing"
+
+func TestSteadyStateDetector(t *testing.T) {
+	opts := SteadyStateOptions{Window: 10, Tolerance: 0.05}
+	d := NewSteadyStateDetector(opts)
+
+	// Transient: the reds convert one blue per tick during 20 ticks, then the population stays put
+	var firstSteady uint64
+	for tick := uint64(1); tick <= 50; tick++ {
+		red := 10 + int(min(tick, 20))
+		if d.Add(Sample{Tick: tick, Red: red, Blue: 100 - red}) && firstSteady == 0 {
+			firstSteady = tick
+		}
+	}
+	// A 5% swing of 100 entities is 5 conversions within the window
+	if firstSteady != 25 {
+		t.Errorf("Expected the steady state to be flagged at tick 25, got %d", firstSteady)
+	}
+	if since, ok := d.Since(); !ok || since != 15 {
+		t.Errorf("Since() = %d, %v, expected 15, true", since, ok)
+	}
+
+	// A new swing ends the steady state
+	if d.Add(Sample{Tick: 51, Red: 60, Blue: 40}) {
+		t.Errorf("Expected the swing to end the steady state")
+	}
+	if _, ok := d.Since(); ok {
+		t.Errorf("Expected no steady state after the swing")
+	}
+}
+
+func TestSteadyStateDetector_needsAFullWindow(t *testing.T) {
+	d := NewSteadyStateDetector(SteadyStateOptions{Window: 10, Tolerance: 0.05})
+	for tick := uint64(1); tick <= 10; tick++ {
+		if d.Add(Sample{Tick: tick, Red: 5, Blue: 5}) {
+			t.Fatalf("Steady state flagged at tick %d before a full window was observed", tick)
+		}
+	}
+	if !d.Add(Sample{Tick: 11, Red: 5, Blue: 5}) {
+		t.Errorf("Expected the steady state once the window is full")
+	}
+}
+
+func TestSteadyStateTick(t *testing.T) {
+	samples := []Sample{{Tick: 1, Red: 1, Blue: 9}, {Tick: 5, Red: 5, Blue: 5}, {Tick: 10, Red: 5, Blue: 5}, {Tick: 20, Red: 5, Blue: 5}}
+	if tick, ok := SteadyStateTick(samples, SteadyStateOptions{Window: 10, Tolerance: 0.01}); !ok || tick != 10 {
+		t.Errorf("SteadyStateTick() = %d, %v, expected 10, true", tick, ok)
+	}
+}
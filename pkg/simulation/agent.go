@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Observation is what an AgentPolicy knows before steering an entity: its own state and the
+// entities it perceives, friends within VisualRange and targets within DetectionRadius
+type Observation struct {
+	ID      string            `json:"id"`
+	Team    pb.TeamColor      `json:"team"`
+	Pos     geometry.Vector2D `json:"pos"`
+	Vel     geometry.Vector2D `json:"vel"`
+	Friends []Neighbor        `json:"friends"`
+	Targets []Neighbor        `json:"targets"`
+	// Size of the world, the entities bounce off its walls
+	WorldWidth  float64 `json:"worldWidth"`
+	WorldHeight float64 `json:"worldHeight"`
+}
+
+// Neighbor is an entity perceived by the observed one, Offset is its position relative to it
+type Neighbor struct {
+	ID     string            `json:"id"`
+	Offset geometry.Vector2D `json:"offset"`
+	Vel    geometry.Vector2D `json:"vel"`
+}
+
+// Action is the steering chosen by an AgentPolicy for one tick: a force added to the velocity,
+// capped to MaxSpeed like the velocity it produces
+type Action struct {
+	Steer geometry.Vector2D `json:"steer"`
+}
+
+// AgentPolicy chooses the steering of an entity from what it observes, e.g. a trained neural network.
+// A PolicyBehavior delegates the movement of the entities of a team to it. The actor engine runs the
+// individuals concurrently: Act must be safe for concurrent use.
+type AgentPolicy interface {
+	Act(obs *Observation) Action
+}
+
+// AgentPolicyFunc adapts a function to an AgentPolicy
+type AgentPolicyFunc func(obs *Observation) Action
+
+func (f AgentPolicyFunc) Act(obs *Observation) Action {
+	return f(obs)
+}
+
+// RegisterPolicy registers a behavior named 'name' that delegates to 'policy' (see RegisterBehavior):
+// select it as the strategy of a team to play a trained policy against the scripted boids
+func RegisterPolicy(name string, team pb.TeamColor, policy AgentPolicy) {
+	RegisterBehavior(name, team, func() Behavior { return &PolicyBehavior{Policy: policy} })
+}
+
+// PolicyBehavior is the Behavior of an entity steered by an AgentPolicy instead of built-in rules
+type PolicyBehavior struct {
+	Policy AgentPolicy
+}
+
+func (b *PolicyBehavior) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	obs := &Observation{
+		ID:          me.ID,
+		Team:        me.Color,
+		Pos:         me.Pos,
+		Vel:         me.Vel,
+		Friends:     neighborsOf(me.Pos, perception.GetFriends()),
+		Targets:     neighborsOf(me.Pos, perception.GetTargets()),
+		WorldWidth:  cfg.WorldWidth,
+		WorldHeight: cfg.WorldHeight,
+	}
+	steerEntity(me, b.Policy.Act(obs), cfg)
+}
+
+// steerEntity applies an action to an entity and moves it
+func steerEntity(me *Entity, action Action, cfg *Config) {
+	me.ApplyForce(behavior.Truncate(action.Steer, cfg.MaxSpeed))
+	me.ClampVelocity(0, cfg.MaxSpeed)
+	me.UpdatePhysics()
+	me.BounceOffWalls(cfg.WorldWidth, cfg.WorldHeight)
+}
+
+// neighborsOf returns the perceived states relative to 'from'
+func neighborsOf(from geometry.Vector2D, states []*pb.ActorState) []Neighbor {
+	if len(states) == 0 {
+		return nil
+	}
+	neighbors := make([]Neighbor, len(states))
+	for i, s := range states {
+		neighbors[i] = Neighbor{
+			ID:     s.GetId(),
+			Offset: GeomVector2DFromProto(s.GetPosition()).Sub(from),
+			Vel:    GeomVector2DFromProto(s.GetVelocity()),
+		}
+	}
+	return neighbors
+}
@@ -0,0 +1,216 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// ErrEnvNotReset is returned by AgentEnv.Step before the first Reset
+var ErrEnvNotReset = errors.New("simulation: agent env not reset")
+
+// agentEnvCount numbers the behaviors registered by the AgentEnvs
+var agentEnvCount atomic.Uint64
+
+// AgentEnvOptions configures an AgentEnv
+type AgentEnvOptions struct {
+	// Team is the team steered by the actions, the other one keeps its configured strategy
+	Team pb.TeamColor
+	// MaxTicks truncates the episodes, 0 lets them run until the game over
+	MaxTicks uint64
+	// Reward scores a step for the team from the snapshots before and after it, TeamReward when nil
+	Reward func(prev, next *pb.WorldSnapshot, team pb.TeamColor) float64
+	// Engine runs the episodes, NewLocalEngine (reproducible) when nil. The ecs engine only runs
+	// the built-in strategies: it cannot host a policy.
+	Engine EngineFactory
+}
+
+// AgentStep is the result of AgentEnv.Step
+type AgentStep struct {
+	// Observations of the entities of the team after the step, by ID: the converted entities leave it
+	Observations map[string]*Observation
+	Reward       float64
+	// Done is set at the game over, Truncated when the episode reached MaxTicks
+	Done      bool
+	Truncated bool
+	Snapshot  *pb.WorldSnapshot
+}
+
+// AgentEnv is a gym-style environment on top of the headless Runner to train an AgentPolicy against
+// the scripted behaviors: Reset starts an episode, Step applies the actions of the entities of the
+// team for one tick and returns what they observe next and the reward of the team.
+// An AgentEnv is not safe for concurrent use, run one per goroutine to train in parallel.
+type AgentEnv struct {
+	cfg      Config
+	opts     AgentEnvOptions
+	strategy string // Behavior of the team, delegating to the env
+	runner   *Runner
+	last     *pb.WorldSnapshot
+
+	mu      sync.Mutex // The actor engine moves the individuals concurrently
+	actions map[string]Action
+}
+
+// NewAgentEnv returns an environment playing the episodes with 'cfg', call Reset to start one
+func NewAgentEnv(cfg *Config, opts AgentEnvOptions) (*AgentEnv, error) {
+	if opts.Team != pb.TeamColor_TEAM_RED && opts.Team != pb.TeamColor_TEAM_BLUE {
+		return nil, fmt.Errorf("the team of an agent env must be red or blue, got %s", opts.Team)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.Reward == nil {
+		opts.Reward = TeamReward
+	}
+	if opts.Engine == nil {
+		opts.Engine = NewLocalEngine
+	}
+	e := &AgentEnv{cfg: *cfg, opts: opts, actions: make(map[string]Action)}
+	e.strategy = fmt.Sprintf("agent-env-%d", agentEnvCount.Add(1))
+	RegisterPolicy(e.strategy, opts.Team, e)
+	if opts.Team == pb.TeamColor_TEAM_RED {
+		e.cfg.RedStrategy = e.strategy
+	} else {
+		e.cfg.BlueStrategy = e.strategy
+	}
+	return e, nil
+}
+
+// Act is the policy of the entities of the team: the action given to Step, none when missing
+func (e *AgentEnv) Act(obs *Observation) Action {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.actions[obs.ID]
+}
+
+// Reset stops the current episode and starts a new one with 'seed' (0 picks a random seed),
+// it returns the observations of the spawned entities of the team
+func (e *AgentEnv) Reset(ctx context.Context, seed uint64) (map[string]*Observation, error) {
+	if err := e.Close(ctx); err != nil {
+		return nil, err
+	}
+	cfg := e.cfg
+	cfg.Seed = seed
+	runner, err := NewRunner(ctx, &cfg, WithEngine(e.opts.Engine))
+	if err != nil {
+		return nil, err
+	}
+	e.runner = runner
+	e.setActions(nil)
+	// The first tick reports the spawned population
+	snap, err := runner.Step(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.last = snap
+	return e.observe(snap), nil
+}
+
+// Step applies the actions (by entity ID, the entities without action coast) for one tick
+func (e *AgentEnv) Step(ctx context.Context, actions map[string]Action) (*AgentStep, error) {
+	if e.runner == nil {
+		return nil, ErrEnvNotReset
+	}
+	e.setActions(actions)
+	snap, err := e.runner.Step(ctx)
+	if err != nil {
+		return nil, err
+	}
+	step := &AgentStep{
+		Observations: e.observe(snap),
+		Reward:       e.opts.Reward(e.last, snap, e.opts.Team),
+		Done:         snap.GetIsGameOver(),
+		Truncated:    e.opts.MaxTicks > 0 && e.runner.Tick() >= e.opts.MaxTicks && !snap.GetIsGameOver(),
+		Snapshot:     snap,
+	}
+	e.last = snap
+	return step, nil
+}
+
+// Close stops the current episode
+func (e *AgentEnv) Close(ctx context.Context) error {
+	if e.runner == nil {
+		return nil
+	}
+	err := e.runner.Stop(ctx)
+	e.runner, e.last = nil, nil
+	return err
+}
+
+func (e *AgentEnv) setActions(actions map[string]Action) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clear(e.actions)
+	for id, a := range actions {
+		e.actions[id] = a
+	}
+}
+
+// observe returns the observations of the entities of the team in a snapshot, with the same ranges as
+// the perception of the world (friends within VisualRange, targets within DetectionRadius)
+func (e *AgentEnv) observe(snap *pb.WorldSnapshot) map[string]*Observation {
+	cfg := e.runner.Config()
+	friendSq, targetSq := cfg.VisualRange*cfg.VisualRange, cfg.DetectionRadius*cfg.DetectionRadius
+	observations := make(map[string]*Observation)
+	actors := snap.GetActors()
+	for _, me := range actors {
+		if me.GetColor() != e.opts.Team {
+			continue
+		}
+		pos := GeomVector2DFromProto(me.GetPosition())
+		obs := &Observation{
+			ID:          me.GetId(),
+			Team:        me.GetColor(),
+			Pos:         pos,
+			Vel:         GeomVector2DFromProto(me.GetVelocity()),
+			WorldWidth:  cfg.WorldWidth,
+			WorldHeight: cfg.WorldHeight,
+		}
+		for _, other := range actors {
+			if other.GetId() == me.GetId() {
+				continue
+			}
+			offset := GeomVector2DFromProto(other.GetPosition()).Sub(pos)
+			n := Neighbor{ID: other.GetId(), Offset: offset, Vel: GeomVector2DFromProto(other.GetVelocity())}
+			switch {
+			case other.GetColor() == me.GetColor() && offset.LenSqr() < friendSq:
+				obs.Friends = append(obs.Friends, n)
+			case other.GetColor() != me.GetColor() && offset.LenSqr() < targetSq:
+				obs.Targets = append(obs.Targets, n)
+			}
+		}
+		observations[obs.ID] = obs
+	}
+	return observations
+}
+
+// TeamReward is the default reward of an AgentEnv: the change of the share of the population of
+// the team during the step, plus 1 for a win and -1 for a loss at the game over
+func TeamReward(prev, next *pb.WorldSnapshot, team pb.TeamColor) float64 {
+	reward := teamShare(next, team) - teamShare(prev, team)
+	if next.GetIsGameOver() {
+		if teamShare(next, team) > 0 {
+			reward++
+		} else {
+			reward--
+		}
+	}
+	return reward
+}
+
+// teamShare returns the fraction of the population of a snapshot in 'team'
+func teamShare(snap *pb.WorldSnapshot, team pb.TeamColor) float64 {
+	total := snap.GetRedCount() + snap.GetBlueCount()
+	if total == 0 {
+		return 0
+	}
+	count := snap.GetBlueCount()
+	if team == pb.TeamColor_TEAM_RED {
+		count = snap.GetRedCount()
+	}
+	return float64(count) / float64(total)
+}
@@ -0,0 +1,112 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestPolicyBehavior(t *testing.T) {
+	cfg := DefaultConfig()
+	var seen *Observation
+	b := &PolicyBehavior{Policy: AgentPolicyFunc(func(obs *Observation) Action {
+		seen = obs
+		return Action{Steer: geometry.Vector2D{X: 100}} // Capped to MaxSpeed
+	})}
+	me := &Entity{ID: "red-1", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 100, Y: 100}}
+	perception := &pb.Perception{Targets: []*pb.ActorState{
+		{Id: "blue-1", Position: &pb.Vector{X: 110, Y: 90}},
+	}}
+	b.Update(me, perception, cfg)
+
+	if seen == nil || seen.ID != "red-1" || len(seen.Targets) != 1 || len(seen.Friends) != 0 {
+		t.Fatalf("Unexpected observation %+v", seen)
+	}
+	if off := seen.Targets[0].Offset; off.X != 10 || off.Y != -10 {
+		t.Errorf("Expected the target at (10,-10) from the entity, got %v", off)
+	}
+	if me.Vel.X != cfg.MaxSpeed || me.Pos.X <= 100 {
+		t.Errorf("Expected the entity steered right at max speed, got vel %v pos %v", me.Vel, me.Pos)
+	}
+}
+
+func TestAgentEnv(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 3
+	cfg.NumBlueAtStart = 10
+	cfg.LogLevel = "error"
+	// Far from the walls, the steered entities do not bounce
+	cfg.WorldHeight = 4000
+	if _, err := NewAgentEnv(cfg, AgentEnvOptions{}); err == nil {
+		t.Error("Expected an env without team to be rejected")
+	}
+	env, err := NewAgentEnv(cfg, AgentEnvOptions{Team: pb.TeamColor_TEAM_RED, MaxTicks: 5})
+	if err != nil {
+		t.Fatalf("NewAgentEnv() error = %v", err)
+	}
+	ctx := context.Background()
+	defer env.Close(ctx)
+	if _, err := env.Step(ctx, nil); err != ErrEnvNotReset {
+		t.Errorf("Expected ErrEnvNotReset before Reset, got %v", err)
+	}
+
+	obs, err := env.Reset(ctx, 42)
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if len(obs) != 3 {
+		t.Fatalf("Expected the observations of the 3 reds, got %d", len(obs))
+	}
+	actions := make(map[string]Action)
+	for id := range obs {
+		actions[id] = Action{Steer: geometry.Vector2D{Y: -cfg.MaxSpeed * 2}}
+	}
+	var step *AgentStep
+	for range 4 {
+		if step, err = env.Step(ctx, actions); err != nil {
+			t.Fatalf("Step() error = %v", err)
+		}
+	}
+	for id, o := range step.Observations {
+		if o.Team != pb.TeamColor_TEAM_RED {
+			t.Errorf("Observation of %s from another team: %+v", id, o)
+		}
+		if _, steered := actions[id]; steered && o.Vel.Y >= 0 {
+			t.Errorf("Expected %s steered up, got %v", id, o.Vel)
+		}
+	}
+	if !step.Truncated && !step.Done {
+		t.Errorf("Expected the episode truncated after 5 ticks, got %+v", step)
+	}
+
+	// Same seed, same actions: same episode
+	first := step.Snapshot
+	if _, err := env.Reset(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		if step, err = env.Step(ctx, actions); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if step.Snapshot.GetActors()[0].GetPosition().GetY() != first.GetActors()[0].GetPosition().GetY() {
+		t.Error("Expected a reset with the same seed to replay the episode")
+	}
+}
+
+func TestTeamReward(t *testing.T) {
+	prev := &pb.WorldSnapshot{RedCount: 5, BlueCount: 15}
+	next := &pb.WorldSnapshot{RedCount: 10, BlueCount: 10}
+	if r := TeamReward(prev, next, pb.TeamColor_TEAM_RED); r != 0.25 {
+		t.Errorf("Expected +0.25 for red, got %f", r)
+	}
+	if r := TeamReward(prev, next, pb.TeamColor_TEAM_BLUE); r != -0.25 {
+		t.Errorf("Expected -0.25 for blue, got %f", r)
+	}
+	over := &pb.WorldSnapshot{RedCount: 20, IsGameOver: true}
+	if r := TeamReward(next, over, pb.TeamColor_TEAM_BLUE); r != -1.5 {
+		t.Errorf("Expected -0.5 and the loss for blue, got %f", r)
+	}
+}
@@ -0,0 +1,165 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// defaultAttractorStrength and defaultAttractorRadius are the initial settings of the next attractor of the Game
+	defaultAttractorStrength = 0.15
+	defaultAttractorRadius   = 150.0
+)
+
+// noAttractor is listed by the Game while no attractor is placed
+const noAttractor = "none"
+
+// Attractor is a point pulling (Strength > 0) or pushing (Strength < 0) the entities within Radius
+// of it, the harder the closer they are
+type Attractor struct {
+	Pos      geometry.Vector2D
+	Strength float64 // Force at the center, per nominal tick
+	Radius   float64
+}
+
+// Repulsor reports whether the attractor pushes the entities away
+func (a Attractor) Repulsor() bool {
+	return a.Strength < 0
+}
+
+func (a Attractor) String() string {
+	kind := "attractor"
+	if a.Repulsor() {
+		kind = "repulsor"
+	}
+	return fmt.Sprintf("%s (%.0f, %.0f)", kind, a.Pos.X, a.Pos.Y)
+}
+
+// force returns the force of the attractor on an entity at 'pos': toward it (or away from it),
+// fading linearly from Strength at the center to nothing at Radius
+func (a Attractor) force(pos geometry.Vector2D) geometry.Vector2D {
+	d := a.Pos.Sub(pos)
+	dist := d.Len()
+	if dist == 0 || dist >= a.Radius {
+		return geometry.Vector2D{}
+	}
+	return d.Mul(a.Strength * (1 - dist/a.Radius) / dist)
+}
+
+// Attractors links the world to whoever places attractors and repulsors, e.g. the Game with the
+// mouse. Give it to the world with WithAttractors. An Attractors is safe for concurrent use.
+type Attractors struct {
+	mu   sync.Mutex
+	list []Attractor
+	// version counts the changes, the world only publishes a new list when it changed
+	version uint64
+}
+
+// Add places 'a' in the world
+func (at *Attractors) Add(a Attractor) {
+	at.mu.Lock()
+	at.list = append(at.list, a)
+	at.version++
+	at.mu.Unlock()
+}
+
+// Remove removes the attractor at 'index' of List, it returns false when there is none
+func (at *Attractors) Remove(index int) bool {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	if index < 0 || index >= len(at.list) {
+		return false
+	}
+	at.list = append(at.list[:index:index], at.list[index+1:]...)
+	at.version++
+	return true
+}
+
+// Clear removes every attractor
+func (at *Attractors) Clear() {
+	at.mu.Lock()
+	at.list = nil
+	at.version++
+	at.mu.Unlock()
+}
+
+// List returns the attractors in the order they were placed, nil when there is none. It must not be modified.
+func (at *Attractors) List() []Attractor {
+	if at == nil {
+		return nil
+	}
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.list
+}
+
+// changedSince returns the attractors and their version when they changed since 'version'
+func (at *Attractors) changedSince(version uint64) ([]Attractor, uint64, bool) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.list, at.version, at.version != version
+}
+
+// WithAttractors makes the world apply the attractors of 'at' to its entities
+func WithAttractors(at *Attractors) WorldOption {
+	return func(w *world) {
+		w.attractors.control = at
+	}
+}
+
+// attractorTracker keeps the version of the attractors the individuals see
+type attractorTracker struct {
+	control *Attractors
+	version uint64
+}
+
+// updateAttractors publishes the attractors to the individuals when they changed
+func (w *world) updateAttractors() {
+	t := &w.attractors
+	if t.control == nil {
+		return
+	}
+	list, version, changed := t.control.changedSince(t.version)
+	if !changed {
+		return
+	}
+	t.version = version
+	if len(list) == 0 {
+		w.live.attractors.Store(nil)
+		return
+	}
+	// Add only appends past the end of a published list, Remove and Clear build a new one
+	w.live.attractors.Store(&list)
+}
+
+// applyAttractors adds the forces of the attractors within reach of the entity
+func applyAttractors(me *Entity, list *[]Attractor) {
+	if list == nil {
+		return
+	}
+	for _, a := range *list {
+		me.ApplyForce(a.force(me.Pos))
+	}
+}
+
+var (
+	attractorColor = color.RGBA{R: 90, G: 220, B: 120, A: 200}
+	repulsorColor  = color.RGBA{R: 255, G: 140, B: 40, A: 200}
+)
+
+// drawAttractors draws every attractor as a dot ringed by its reach: green when it pulls, orange when it pushes
+func drawAttractors(r Renderer, list []Attractor) {
+	for _, a := range list {
+		clr := attractorColor
+		if a.Repulsor() {
+			clr = repulsorColor
+		}
+		x, y := float32(a.Pos.X), float32(a.Pos.Y)
+		r.FillCircle(x, y, 4, clr)
+		clr.A /= 3
+		r.StrokeCircle(x, y, float32(a.Radius), 1, clr)
+	}
+}
@@ -0,0 +1,89 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestAttractor_force(t *testing.T) {
+	pull := Attractor{Pos: geometry.Vector2D{X: 100, Y: 100}, Strength: 0.2, Radius: 50}
+	push := Attractor{Pos: pull.Pos, Strength: -0.2, Radius: 50}
+	tests := []struct {
+		name string
+		a    Attractor
+		pos  geometry.Vector2D
+		want geometry.Vector2D
+	}{
+		{"pulls toward it", pull, geometry.Vector2D{X: 75, Y: 100}, geometry.Vector2D{X: 0.1}},
+		{"fades with the distance", pull, geometry.Vector2D{X: 100, Y: 140}, geometry.Vector2D{Y: -0.2 * 0.2}},
+		{"pushes away from it", push, geometry.Vector2D{X: 75, Y: 100}, geometry.Vector2D{X: -0.1}},
+		{"nothing beyond the radius", pull, geometry.Vector2D{X: 160, Y: 100}, geometry.Vector2D{}},
+		{"nothing at the center", pull, pull.Pos, geometry.Vector2D{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.force(tt.pos); !nearForce(got, tt.want) {
+				t.Errorf("force(%v) = %v, expected %v", tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorld_updateAttractors(t *testing.T) {
+	control := &Attractors{}
+	w := newWorld(nil, &Config{WorldWidth: 400, WorldHeight: 400}, WithAttractors(control))
+	w.updateAttractors()
+	if w.live.attractors.Load() != nil {
+		t.Fatal("Expected no attractor before one is placed")
+	}
+
+	pull := Attractor{Pos: geometry.Vector2D{X: 100, Y: 100}, Strength: 0.2, Radius: 50}
+	push := Attractor{Pos: geometry.Vector2D{X: 300, Y: 300}, Strength: -0.2, Radius: 80}
+	control.Add(pull)
+	control.Add(push)
+	w.updateAttractors()
+	published := w.live.attractors.Load()
+	if published == nil || len(*published) != 2 {
+		t.Fatalf("Expected both attractors published, got %v", published)
+	}
+	w.updateAttractors()
+	if w.live.attractors.Load() != published {
+		t.Error("Expected the same list while nothing changed")
+	}
+
+	if control.Remove(2) {
+		t.Error("Expected no attractor at index 2")
+	}
+	control.Remove(0)
+	w.updateAttractors()
+	if got := *w.live.attractors.Load(); len(got) != 1 || got[0] != push {
+		t.Errorf("Expected only the repulsor left, got %v", got)
+	}
+	if (*published)[0] != pull {
+		t.Error("Expected Remove to leave the published list unchanged")
+	}
+
+	control.Clear()
+	w.updateAttractors()
+	if w.live.attractors.Load() != nil || control.List() != nil {
+		t.Error("Expected no attractor after Clear")
+	}
+}
+
+func TestIndividual_attractors(t *testing.T) {
+	live := newLiveConfig(DefaultConfig())
+	live.attractors.Store(&[]Attractor{{Pos: geometry.Vector2D{X: 400, Y: 300}, Strength: 0.5, Radius: 200}})
+	for _, color := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE, NeutralColor} {
+		free := newIndividual(color, 300, 300, 0, 2, newLiveConfig(DefaultConfig()), nil)
+		pulled := newIndividual(color, 300, 300, 0, 2, live, nil)
+		free.setID("Entity-000")
+		pulled.setID("Entity-000")
+		calm, attracted := free.handleTick(&pb.Tick{}), pulled.handleTick(&pb.Tick{})
+		if attracted.Position.X <= calm.Position.X {
+			t.Errorf("%v: expected the attractor to pull the entity right, got x %f without and %f with it",
+				color, calm.Position.X, attracted.Position.X)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/sound"
+)
+
+const (
+	// DefaultSoundVolume is the volume when Config.SoundVolume is 0
+	DefaultSoundVolume = 0.5
+	// conversionSoundGap is the shortest time between two conversion chirps: a tick may convert
+	// dozens of entities, and overlapping chirps only make noise
+	conversionSoundGap = 80 * time.Millisecond
+	// ambientLevel is the volume of the ambient loop relative to the effects
+	ambientLevel = 0.4
+	// audioQueueSize bounds the events waiting for their sound
+	audioQueueSize = 64
+)
+
+// soundVolume returns the volume of the config (DefaultSoundVolume when not set)
+func soundVolume(cfg *Config) float64 {
+	if cfg.SoundVolume == 0 {
+		return DefaultSoundVolume
+	}
+	return cfg.SoundVolume
+}
+
+// validateSoundFile checks that a custom sound has a supported format, an empty path is the built-in sound
+func validateSoundFile(field, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case "", ".wav", ".mp3", ".ogg":
+		return nil
+	}
+	return fmt.Errorf("%s %q: unsupported format (use a wav, mp3 or ogg file)", field, path)
+}
+
+// decodeSound reads a wav, mp3 or ogg file as 16-bit stereo PCM at the sample rate of the built-in sounds
+func decodeSound(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read sound: %w", err)
+	}
+	var stream io.Reader
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		stream, err = wav.DecodeWithSampleRate(sound.SampleRate, bytes.NewReader(data))
+	case ".mp3":
+		stream, err = mp3.DecodeWithSampleRate(sound.SampleRate, bytes.NewReader(data))
+	case ".ogg":
+		stream, err = vorbis.DecodeWithSampleRate(sound.SampleRate, bytes.NewReader(data))
+	default:
+		return nil, validateSoundFile("sound", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode sound %s: %w", path, err)
+	}
+	pcm, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode sound %s: %w", path, err)
+	}
+	return pcm, nil
+}
+
+// Audio plays the sounds of the simulation events: a chirp per conversion, a fanfare at the game
+// over and an ambient loop. The audio device is opened the first time the sound is enabled.
+type Audio struct {
+	// Sink receives the events from the world goroutine
+	Sink    *EventQueue
+	pending []Event
+
+	conversion, gameOver, ambient []byte
+	ctx                           *audio.Context
+	ambientPlayer                 *audio.Player
+	lastConversion                time.Time
+}
+
+// NewAudio loads the sounds of the config, the built-in sound replaces a custom sound that cannot
+// be loaded (the error is returned with the usable Audio)
+func NewAudio(cfg *Config) (*Audio, error) {
+	a := &Audio{Sink: NewEventQueue(audioQueueSize, EventConversion, EventGameOver)}
+	var errs []error
+	load := func(path string, builtin func(sampleRate int) []byte) []byte {
+		if path != "" {
+			pcm, err := decodeSound(path)
+			if err == nil {
+				return pcm
+			}
+			errs = append(errs, err)
+		}
+		return builtin(sound.SampleRate)
+	}
+	a.conversion = load(cfg.ConversionSound, sound.Conversion)
+	a.gameOver = load(cfg.GameOverSound, sound.GameOver)
+	a.ambient = load(cfg.AmbientSound, sound.Ambient)
+	if len(errs) > 0 {
+		return a, fmt.Errorf("using built-in sounds: %w", errs[0])
+	}
+	return a, nil
+}
+
+// Update plays the sounds of the events received since the last call when 'enabled', at 'volume' (0-1).
+// Disabling the sound pauses the ambient loop and skips the events.
+func (a *Audio) Update(enabled bool, volume float64) {
+	a.pending = a.Sink.Drain(a.pending[:0])
+	if !enabled {
+		if a.ambientPlayer != nil && a.ambientPlayer.IsPlaying() {
+			a.ambientPlayer.Pause()
+		}
+		return
+	}
+	if a.ctx == nil {
+		// Only one context per process: a restarted Game reuses it
+		if a.ctx = audio.CurrentContext(); a.ctx == nil {
+			a.ctx = audio.NewContext(sound.SampleRate)
+		}
+		a.ambientPlayer, _ = a.ctx.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(a.ambient), int64(len(a.ambient))))
+	}
+	if a.ambientPlayer != nil {
+		a.ambientPlayer.SetVolume(volume * ambientLevel)
+		if !a.ambientPlayer.IsPlaying() {
+			a.ambientPlayer.Play()
+		}
+	}
+
+	for _, e := range a.pending {
+		switch e.Kind {
+		case EventConversion:
+			if time.Since(a.lastConversion) < conversionSoundGap {
+				continue
+			}
+			a.lastConversion = time.Now()
+			a.play(a.conversion, volume)
+		case EventGameOver:
+			a.play(a.gameOver, volume)
+		}
+	}
+}
+
+// play starts a one-shot sound, the player is released once it finished
+func (a *Audio) play(pcm []byte, volume float64) {
+	p := a.ctx.NewPlayerFromBytes(pcm)
+	p.SetVolume(volume)
+	p.Play()
+}
+
+// Reset skips the events not played yet, e.g. on restart
+func (a *Audio) Reset() {
+	a.Sink.Drain(nil)
+}
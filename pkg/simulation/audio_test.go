@@ -0,0 +1,93 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/sound"
+)
+
+// writeWAV writes 16-bit stereo PCM as a wav file
+func writeWAV(t *testing.T, path string, pcm []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	le := func(v any) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+	buf.WriteString("RIFF")
+	le(uint32(36 + len(pcm)))
+	buf.WriteString("WAVEfmt ")
+	le(uint32(16))
+	le(uint16(1)) // PCM
+	le(uint16(2)) // Stereo
+	le(uint32(sound.SampleRate))
+	le(uint32(sound.SampleRate * 4))
+	le(uint16(4))
+	le(uint16(16))
+	buf.WriteString("data")
+	le(uint32(len(pcm)))
+	buf.Write(pcm)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewAudio(t *testing.T) {
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "pop.wav")
+	pcm := sound.GameOver(sound.SampleRate)[:4000]
+	writeWAV(t, custom, pcm)
+
+	cfg := DefaultConfig()
+	cfg.ConversionSound = custom
+	a, err := NewAudio(cfg)
+	if err != nil {
+		t.Fatalf("NewAudio() error = %v", err)
+	}
+	if !bytes.Equal(a.conversion, pcm) {
+		t.Errorf("Expected the custom conversion sound, got %d bytes", len(a.conversion))
+	}
+	if !bytes.Equal(a.gameOver, sound.GameOver(sound.SampleRate)) {
+		t.Errorf("Expected the built-in game over sound")
+	}
+
+	// A missing file falls back to the built-in sound
+	cfg.AmbientSound = filepath.Join(dir, "missing.ogg")
+	a, err = NewAudio(cfg)
+	if err == nil || !strings.Contains(err.Error(), "built-in") {
+		t.Errorf("Expected an error for the missing file, got %v", err)
+	}
+	if a == nil || len(a.ambient) != len(sound.Ambient(sound.SampleRate)) {
+		t.Errorf("Expected the built-in ambient loop to replace the missing file")
+	}
+}
+
+func TestAudioDisabled(t *testing.T) {
+	a, _ := NewAudio(DefaultConfig())
+	a.Sink.HandleEvent(Event{Kind: EventConversion})
+	a.Sink.HandleEvent(Event{Kind: EventGameOver})
+	// Disabled: the events are skipped and no audio device is opened
+	a.Update(false, 1)
+	if a.ctx != nil || len(a.Sink.Drain(nil)) != 0 {
+		t.Errorf("Expected the events to be skipped without opening the audio device")
+	}
+}
+
+func TestConfigValidateSound(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SoundVolume = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a volume above 1 to be rejected")
+	}
+	cfg.SoundVolume = 0.8
+	cfg.GameOverSound = "tada.flac"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gameOverSound") {
+		t.Errorf("Expected the flac file to be rejected, got %v", err)
+	}
+	cfg.GameOverSound = "tada.MP3"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/bt"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// StrategyTreePrefix prefixes the strategies evaluating a behavior tree, e.g. "bt:behaviors/cautious.json"
+const StrategyTreePrefix = "bt"
+
+func init() {
+	RegisterBehaviorResolver(StrategyTreePrefix, func(path string) (Behavior, error) {
+		tree, err := loadTree(path)
+		if err != nil {
+			return nil, err
+		}
+		return &TreeBehavior{tree: tree}, nil
+	})
+}
+
+// treeMove moves an entity for one tick, like Behavior.Update
+type treeMove func(me *Entity, perception *pb.Perception, cfg *Config)
+
+// treeRegistry holds the conditions and the actions of the behavior trees. The conditions read the
+// perception of the entity, the actions pick the move of the tick: the last one picked runs once
+// the tree is evaluated, so an entity never moves twice in a tick.
+var treeRegistry = bt.Registry[*TreeBehavior]{
+	Conditions: map[string]func(arg float64) bt.Condition[*TreeBehavior]{
+		// seesTargets: at least one entity of the other team is within DetectionRadius
+		"seesTargets": func(float64) bt.Condition[*TreeBehavior] {
+			return func(b *TreeBehavior) bool { return len(b.perception.GetTargets()) > 0 }
+		},
+		// seesFriends: at least 'arg' friends (1 when 0) are within VisualRange
+		"seesFriends": func(arg float64) bt.Condition[*TreeBehavior] {
+			return func(b *TreeBehavior) bool { return float64(len(b.perception.GetFriends())) >= max(arg, 1) }
+		},
+		// targetWithin: the closest target is closer than 'arg'
+		"targetWithin": func(arg float64) bt.Condition[*TreeBehavior] {
+			return func(b *TreeBehavior) bool {
+				_, distSq := closestTo(b.me.Pos, b.perception.GetTargets())
+				return distSq < arg*arg
+			}
+		},
+		// outnumbered: the entity sees more targets than friends, itself included
+		"outnumbered": func(float64) bt.Condition[*TreeBehavior] {
+			return func(b *TreeBehavior) bool {
+				return len(b.perception.GetTargets()) > len(b.perception.GetFriends())+1
+			}
+		},
+		// nearWall: the entity is closer than 'arg' to a wall of the world
+		"nearWall": func(arg float64) bt.Condition[*TreeBehavior] {
+			return func(b *TreeBehavior) bool {
+				p := b.me.Pos
+				return min(p.X, p.Y, b.cfg.WorldWidth-p.X, b.cfg.WorldHeight-p.Y) < arg
+			}
+		},
+	},
+	Actions: map[string]func(arg float64) bt.Action[*TreeBehavior]{
+		"chase":    treeHunt(func(b *TreeBehavior) treeMove { return b.hunter.Update }),
+		"packHunt": treeHunt(func(b *TreeBehavior) treeMove { return b.pack.Update }),
+		"flock":    treeAction((&ClassicBoids{}).Update),
+		"flee":     treeAction(flee),
+		"wander": treeAction(func(me *Entity, _ *pb.Perception, cfg *Config) {
+			(&ClassicHunter{}).Update(me, &pb.Perception{}, cfg)
+		}),
+	},
+}
+
+// treeAction returns an action picking 'move' for the tick, it always succeeds
+func treeAction(move treeMove) func(arg float64) bt.Action[*TreeBehavior] {
+	return func(float64) bt.Action[*TreeBehavior] {
+		return func(b *TreeBehavior) bt.Status {
+			b.move = move
+			return bt.Success
+		}
+	}
+}
+
+// treeHunt returns an action picking the move of a hunter of the individual, which keeps its path
+// between the ticks, it always succeeds
+func treeHunt(hunter func(b *TreeBehavior) treeMove) func(arg float64) bt.Action[*TreeBehavior] {
+	return func(float64) bt.Action[*TreeBehavior] {
+		return func(b *TreeBehavior) bt.Status {
+			b.move = hunter(b)
+			return bt.Success
+		}
+	}
+}
+
+// behaviorTree is a loaded tree, it keeps no state and is shared by the individuals
+type behaviorTree struct {
+	modTime time.Time
+	root    bt.Node[*TreeBehavior]
+}
+
+var (
+	treesMu sync.Mutex
+	trees   = make(map[string]*behaviorTree)
+)
+
+// loadTree builds the tree defined in the JSON file at 'path' once, again when the file changed
+func loadTree(path string) (*behaviorTree, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read behavior tree: %w", err)
+	}
+	treesMu.Lock()
+	defer treesMu.Unlock()
+	if t, ok := trees[path]; ok && t.modTime.Equal(info.ModTime()) {
+		return t, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read behavior tree: %w", err)
+	}
+	root, err := bt.Parse(data, treeRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("behavior tree %s: %w", path, err)
+	}
+	t := &behaviorTree{modTime: info.ModTime(), root: root}
+	trees[path] = t
+	return t, nil
+}
+
+// TreeBehavior moves an entity with a behavior tree defined in JSON (see pkg/bt), e.g. flee when
+// outnumbered, else chase a visible target, else flock. The conditions are seesTargets,
+// seesFriends, targetWithin, outnumbered and nearWall, the actions chase, packHunt, flock, flee and
+// wander. An evaluation picking no action lets the entity coast.
+type TreeBehavior struct {
+	tree *behaviorTree
+	// The hunters of the chase and packHunt actions, with the path of the individual
+	hunter ClassicHunter
+	pack   PackHunter
+	// Blackboard of the evaluation in progress
+	me         *Entity
+	perception *pb.Perception
+	cfg        *Config
+	move       treeMove
+}
+
+func (b *TreeBehavior) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	b.me, b.perception, b.cfg, b.move = me, perception, cfg, nil
+	b.tree.root.Tick(b)
+	if b.move != nil {
+		b.move(me, perception, cfg)
+	} else {
+		steerEntity(me, Action{}, cfg)
+	}
+	b.me, b.perception, b.cfg = nil, nil, nil
+}
+
+// flee steers away from the closest target at full speed, or coasts when none is visible
+func flee(me *Entity, perception *pb.Perception, cfg *Config) {
+	var steer geometry.Vector2D
+	if closest, distSq := closestTo(me.Pos, perception.GetTargets()); distSq < math.MaxFloat64 {
+		steer = behavior.Flee(me.Pos, me.Vel, closest, cfg.MaxSpeed)
+	}
+	steerEntity(me, Action{Steer: steer}, cfg)
+}
+
+// closestTo returns the position of the state closest to 'from' and its squared distance,
+// math.MaxFloat64 when there is none
+func closestTo(from geometry.Vector2D, states []*pb.ActorState) (geometry.Vector2D, float64) {
+	var closest geometry.Vector2D
+	best := math.MaxFloat64
+	for _, s := range states {
+		pos := GeomVector2DFromProto(s.GetPosition())
+		if d := from.DistanceSquaredTo(pos); d < best {
+			closest, best = pos, d
+		}
+	}
+	return closest, best
+}
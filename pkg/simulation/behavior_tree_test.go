@@ -0,0 +1,73 @@
+package simulation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestTreeBehavior(t *testing.T) {
+	b, err := NewBehavior(StrategyTreePrefix + ":../../behaviors/cautious.json")
+	if err != nil {
+		t.Fatalf("NewBehavior() error = %v", err)
+	}
+	cfg := DefaultConfig()
+	red := func(id string, x, y float64) *pb.ActorState {
+		return &pb.ActorState{Id: id, Color: pb.TeamColor_TEAM_RED, Position: &pb.Vector{X: x, Y: y}}
+	}
+	blue := func(id string, x, y float64) *pb.ActorState {
+		return &pb.ActorState{Id: id, Color: pb.TeamColor_TEAM_BLUE, Position: &pb.Vector{X: x, Y: y}}
+	}
+
+	// Outnumbered: flees away from the closest blue at full speed
+	me := &Entity{ID: "red-1", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 200, Y: 200}}
+	b.Update(me, &pb.Perception{Targets: []*pb.ActorState{blue("b1", 200, 220), blue("b2", 200, 260), blue("b3", 200, 300)}}, cfg)
+	if me.Vel.X != 0 || me.Vel.Y != -cfg.MaxSpeed {
+		t.Errorf("Expected the red to flee up at max speed, got %v", me.Vel)
+	}
+
+	// One blue: chases it
+	me = &Entity{ID: "red-1", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 200, Y: 200}}
+	b.Update(me, &pb.Perception{Targets: []*pb.ActorState{blue("b1", 200, 260)}}, cfg)
+	if me.Vel.Y <= 0 {
+		t.Errorf("Expected the red to chase down, got %v", me.Vel)
+	}
+
+	// No target but friends: flocks, which keeps the minimum speed
+	me = &Entity{ID: "red-1", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 200, Y: 200}, Vel: geometry.Vector2D{X: 0.5}}
+	b.Update(me, &pb.Perception{Friends: []*pb.ActorState{red("r2", 210, 200), red("r3", 190, 200)}}, cfg)
+	if speed := me.Vel.Len(); speed < cfg.MinSpeed-1e-9 {
+		t.Errorf("Expected the red to flock at least at min speed, got %.3f", speed)
+	}
+
+	// The tree is valid for both teams
+	cfg.RedStrategy = StrategyTreePrefix + ":../../behaviors/cautious.json"
+	cfg.BlueStrategy = cfg.RedStrategy
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the tree valid for both teams, got %v", err)
+	}
+}
+
+func TestTreeBehavior_errors(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"json":      `{"type": "selector"`,
+		"action":    `{"type": "action", "name": "teleport"}`,
+		"condition": `{"type": "sequence", "children": [{"type": "condition", "name": "hungry"}]}`,
+	} {
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewBehavior(StrategyTreePrefix + ":" + path); err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: expected an error naming the file, got %v", name, err)
+		}
+	}
+	if _, err := NewBehavior(StrategyTreePrefix + ":" + filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
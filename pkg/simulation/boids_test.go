@@ -3,6 +3,7 @@ package simulation
 import (
 	"testing"
 
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
 )
 
@@ -18,7 +19,7 @@ func TestComputeBoidUpdate_Separation(t *testing.T) {
 	}
 	me := &Entity{
 		ID:    "me",
-		Color: TeamColor_TEAM_BLUE,
+		Color: pb.TeamColor_TEAM_BLUE,
 		Pos: geometry.Vector2D{
 			X: 0,
 			Y: 0,
@@ -28,8 +29,8 @@ func TestComputeBoidUpdate_Separation(t *testing.T) {
 			Y: 0,
 		},
 	}
-	friends := []*ActorState{
-		{Position: &Vector{X: 1, Y: 0}, Velocity: &Vector{X: 0, Y: 0}},
+	friends := []*pb.ActorState{
+		{Position: &pb.Vector{X: 1, Y: 0}, Velocity: &pb.Vector{X: 0, Y: 0}},
 	}
 
 	force := ComputeBoidUpdate(me, friends, cfg)
@@ -51,7 +52,7 @@ func TestComputeBoidUpdate_Cohesion(t *testing.T) {
 	}
 	me := &Entity{
 		ID:    "me",
-		Color: TeamColor_TEAM_BLUE,
+		Color: pb.TeamColor_TEAM_BLUE,
 		Pos: geometry.Vector2D{
 			X: 0,
 			Y: 0,
@@ -61,8 +62,8 @@ func TestComputeBoidUpdate_Cohesion(t *testing.T) {
 			Y: 0,
 		},
 	}
-	friends := []*ActorState{
-		{Position: &Vector{X: 5, Y: 0}, Velocity: &Vector{X: 0, Y: 0}},
+	friends := []*pb.ActorState{
+		{Position: &pb.Vector{X: 5, Y: 0}, Velocity: &pb.Vector{X: 0, Y: 0}},
 	}
 
 	force := ComputeBoidUpdate(me, friends, cfg)
@@ -83,7 +84,7 @@ func TestComputeBoidUpdate_Alignment(t *testing.T) {
 	}
 	me := &Entity{
 		ID:    "me",
-		Color: TeamColor_TEAM_BLUE,
+		Color: pb.TeamColor_TEAM_BLUE,
 		Pos: geometry.Vector2D{
 			X: 0,
 			Y: 0,
@@ -93,8 +94,8 @@ func TestComputeBoidUpdate_Alignment(t *testing.T) {
 			Y: 0,
 		},
 	}
-	friends := []*ActorState{
-		{Position: &Vector{X: 5, Y: 0}, Velocity: &Vector{X: 1, Y: 0}},
+	friends := []*pb.ActorState{
+		{Position: &pb.Vector{X: 5, Y: 0}, Velocity: &pb.Vector{X: 1, Y: 0}},
 	}
 
 	force := ComputeBoidUpdate(me, friends, cfg)
@@ -0,0 +1,149 @@
+package simulation
+
+import "image/color"
+
+// MaxCameraZoom is the largest magnification of a Camera
+const MaxCameraZoom = 16.0
+
+// Camera maps the world to the screen for zoom and pan: the screen shows the area of the world
+// starting at (X, Y), magnified Zoom times. It never shows anything outside of the world: when the
+// world is smaller than the screen in one direction it is centered, with letterbox bars on the sides.
+type Camera struct {
+	X, Y    float64
+	Zoom    float64
+	worldW  float64
+	worldH  float64
+	screenW float64
+	screenH float64
+	// offsetX and offsetY are the width of the letterbox bars, on each side
+	offsetX float64
+	offsetY float64
+}
+
+// NewCamera creates a camera showing the whole world, or its top left corner when it is larger than the screen
+func NewCamera(worldW, worldH, screenW, screenH float64) *Camera {
+	c := &Camera{Zoom: 1, worldW: worldW, worldH: worldH, screenW: screenW, screenH: screenH}
+	c.clamp()
+	return c
+}
+
+// Reset shows the whole world again
+func (c *Camera) Reset() {
+	c.X, c.Y, c.Zoom = 0, 0, c.minZoom()
+	c.clamp()
+}
+
+// Resize changes the size of the screen (e.g. the window was resized): a camera showing the
+// whole world keeps showing it, otherwise the view keeps its top left corner and its zoom
+func (c *Camera) Resize(screenW, screenH float64) {
+	whole := !c.Zoomed()
+	c.screenW, c.screenH = screenW, screenH
+	if whole {
+		c.Reset()
+		return
+	}
+	c.clamp()
+}
+
+// Zoomed reports whether the screen shows only a part of the world
+func (c *Camera) Zoomed() bool {
+	minX, minY, maxX, maxY := c.Visible()
+	return minX > 0 || minY > 0 || maxX < c.worldW || maxY < c.worldH
+}
+
+// Visible returns the area of the world on screen
+func (c *Camera) Visible() (minX, minY, maxX, maxY float64) {
+	return c.X, c.Y, c.X + min(c.screenW/c.Zoom, c.worldW), c.Y + min(c.screenH/c.Zoom, c.worldH)
+}
+
+// Letterbox returns the width of the bars left and right of the world, and the height of those above and below it
+func (c *Camera) Letterbox() (x, y float64) {
+	return c.offsetX, c.offsetY
+}
+
+// WorldToScreen converts world coordinates to screen coordinates
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x-c.X)*c.Zoom + c.offsetX, (y-c.Y)*c.Zoom + c.offsetY
+}
+
+// ScreenToWorld converts screen coordinates (e.g. the cursor) to world coordinates
+func (c *Camera) ScreenToWorld(sx, sy float64) (float64, float64) {
+	return c.X + (sx-c.offsetX)/c.Zoom, c.Y + (sy-c.offsetY)/c.Zoom
+}
+
+// CenterOn moves the camera so that (x, y) is at the center of the screen, as far as the world allows
+func (c *Camera) CenterOn(x, y float64) {
+	c.X = x - c.screenW/c.Zoom/2
+	c.Y = y - c.screenH/c.Zoom/2
+	c.clamp()
+}
+
+// Pan moves the camera by (dx, dy) screen pixels
+func (c *Camera) Pan(dx, dy float64) {
+	c.X += dx / c.Zoom
+	c.Y += dy / c.Zoom
+	c.clamp()
+}
+
+// ZoomAt multiplies the zoom by 'factor', keeping the world point under the screen point (sx, sy) in place
+func (c *Camera) ZoomAt(factor, sx, sy float64) {
+	x, y := c.ScreenToWorld(sx, sy)
+	c.Zoom *= factor
+	// The letterbox bars shrink as the world grows
+	c.clamp()
+	c.X = x - (sx-c.offsetX)/c.Zoom
+	c.Y = y - (sy-c.offsetY)/c.Zoom
+	c.clamp()
+}
+
+// minZoom is the zoom where the whole world fits the screen, letterboxed in the other direction
+func (c *Camera) minZoom() float64 {
+	if c.worldW <= 0 || c.worldH <= 0 {
+		return 1
+	}
+	return min(c.screenW/c.worldW, c.screenH/c.worldH, MaxCameraZoom)
+}
+
+func (c *Camera) clamp() {
+	c.Zoom = min(max(c.Zoom, c.minZoom()), MaxCameraZoom)
+	c.offsetX = max(c.screenW-c.worldW*c.Zoom, 0) / 2
+	c.offsetY = max(c.screenH-c.worldH*c.Zoom, 0) / 2
+	c.X = min(max(c.X, 0), max(c.worldW-c.screenW/c.Zoom, 0))
+	c.Y = min(max(c.Y, 0), max(c.worldH-c.screenH/c.Zoom, 0))
+}
+
+// cameraRenderer draws the world layer through a camera: it converts the positions to the
+// screen and scales the sprites and radii by the zoom
+type cameraRenderer struct {
+	Renderer
+	camera *Camera
+}
+
+func (r cameraRenderer) DrawSprite(sprite Sprite, x, y, angle, scale float64, tint [4]float32) {
+	sx, sy := r.camera.WorldToScreen(x, y)
+	r.Renderer.DrawSprite(sprite, sx, sy, angle, scale*r.camera.Zoom, tint)
+}
+
+func (r cameraRenderer) FillCircle(x, y, radius float32, clr color.RGBA) {
+	sx, sy := r.camera.WorldToScreen(float64(x), float64(y))
+	r.Renderer.FillCircle(float32(sx), float32(sy), radius*float32(r.camera.Zoom), clr)
+}
+
+func (r cameraRenderer) StrokeCircle(x, y, radius, width float32, clr color.RGBA) {
+	sx, sy := r.camera.WorldToScreen(float64(x), float64(y))
+	r.Renderer.StrokeCircle(float32(sx), float32(sy), radius*float32(r.camera.Zoom), width, clr)
+}
+
+func (r cameraRenderer) StrokeLine(x0, y0, x1, y1, width float32, clr color.RGBA) {
+	sx0, sy0 := r.camera.WorldToScreen(float64(x0), float64(y0))
+	sx1, sy1 := r.camera.WorldToScreen(float64(x1), float64(y1))
+	r.Renderer.StrokeLine(float32(sx0), float32(sy0), float32(sx1), float32(sy1), width, clr)
+}
+
+// throughCamera returns 'r' unchanged when the camera shows the world as is
+func throughCamera(r Renderer, camera *Camera) Renderer {
+	if camera == nil || camera.Zoom == 1 && camera.X == 0 && camera.Y == 0 && camera.offsetX == 0 && camera.offsetY == 0 {
+		return r
+	}
+	return cameraRenderer{Renderer: r, camera: camera}
+}
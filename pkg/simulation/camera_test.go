@@ -0,0 +1,115 @@
+package simulation
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestCamera(t *testing.T) {
+	c := NewCamera(1000, 500, 1000, 500)
+	if c.Zoomed() {
+		t.Fatal("Expected a new camera to show the whole world")
+	}
+
+	// Zoom x2 on the center: the world point under the cursor stays in place
+	c.ZoomAt(2, 500, 250)
+	if minX, minY, maxX, maxY := c.Visible(); minX != 250 || minY != 125 || maxX != 750 || maxY != 375 {
+		t.Errorf("Visible() = %v %v %v %v, expected the center of the world", minX, minY, maxX, maxY)
+	}
+	if x, y := c.ScreenToWorld(500, 250); x != 500 || y != 250 {
+		t.Errorf("Expected the center to stay under the cursor, got %v, %v", x, y)
+	}
+	if sx, sy := c.WorldToScreen(300, 200); sx != 100 || sy != 150 {
+		t.Errorf("WorldToScreen(300, 200) = %v, %v", sx, sy)
+	}
+	if !c.Zoomed() {
+		t.Error("Expected the camera to be zoomed in")
+	}
+
+	// Never outside of the world
+	c.CenterOn(0, 1000)
+	if minX, minY, _, maxY := c.Visible(); minX != 0 || minY != 250 || maxY != 500 {
+		t.Errorf("Expected the bottom left corner, got %v %v ... %v", minX, minY, maxY)
+	}
+	c.Pan(-100, 0)
+	if c.X != 0 {
+		t.Errorf("Expected the pan to stop at the edge, got X = %v", c.X)
+	}
+	c.Pan(200, 0)
+	if c.X != 100 {
+		t.Errorf("Expected 200 pixels to move 100 world units at zoom 2, got X = %v", c.X)
+	}
+
+	// Zoom limits
+	c.ZoomAt(0.01, 0, 0)
+	if c.Zoom != 1 || c.Zoomed() {
+		t.Errorf("Expected the zoom to stop at the whole world, got %v", c.Zoom)
+	}
+	c.ZoomAt(1000, 0, 0)
+	if c.Zoom != MaxCameraZoom {
+		t.Errorf("Expected the zoom to stop at %v, got %v", MaxCameraZoom, c.Zoom)
+	}
+	c.Reset()
+	if c.Zoomed() {
+		t.Error("Expected Reset to show the whole world")
+	}
+}
+
+func TestCamera_resize(t *testing.T) {
+	c := NewCamera(1000, 500, 1000, 500)
+	// A smaller window still shows the whole world
+	c.Resize(500, 250)
+	if c.Zoom != 0.5 || c.Zoomed() {
+		t.Fatalf("Expected the whole world at zoom 0.5, got %v", c.Zoom)
+	}
+	// A zoomed view keeps its corner and its zoom
+	c.ZoomAt(4, 0, 0)
+	c.Pan(100, 0)
+	c.Resize(1000, 500)
+	if minX, minY, maxX, maxY := c.Visible(); c.Zoom != 2 || minX != 50 || minY != 0 || maxX != 550 || maxY != 250 {
+		t.Errorf("Expected the view from (50, 0) at zoom 2, got %v %v %v %v at %v", minX, minY, maxX, maxY, c.Zoom)
+	}
+
+	// A square window fits the whole world, centered between two letterbox bars
+	c = NewCamera(1000, 500, 1000, 500)
+	c.Resize(1000, 1000)
+	if x, y := c.Letterbox(); c.Zoom != 1 || c.Zoomed() || x != 0 || y != 250 {
+		t.Fatalf("Expected bars of 250 above and below the world at zoom 1, got %v %v at %v", x, y, c.Zoom)
+	}
+	if x, y := c.ScreenToWorld(500, 250); x != 500 || y != 0 {
+		t.Errorf("Expected the top of the world under the top bar, got %v, %v", x, y)
+	}
+	if _, _, maxX, maxY := c.Visible(); maxX != 1000 || maxY != 500 {
+		t.Errorf("Expected the whole world visible, got %v %v", maxX, maxY)
+	}
+	// Zooming in on the center: the bars disappear as the world grows past the screen
+	c.ZoomAt(2, 500, 500)
+	if x, y := c.Letterbox(); x != 0 || y != 0 || c.X != 250 || c.Y != 0 {
+		t.Errorf("Expected no bars and the center of the world, got %v %v from (%v, %v)", x, y, c.X, c.Y)
+	}
+}
+
+// recordingRenderer keeps the circles drawn
+type recordingRenderer struct {
+	HashRenderer
+	circles [][3]float32
+}
+
+func (r *recordingRenderer) StrokeCircle(x, y, radius, width float32, clr color.RGBA) {
+	r.circles = append(r.circles, [3]float32{x, y, radius})
+}
+
+func TestCameraRenderer(t *testing.T) {
+	r := &recordingRenderer{HashRenderer: *NewHashRenderer()}
+	c := NewCamera(1000, 1000, 1000, 1000)
+	if throughCamera(r, c) != Renderer(r) {
+		t.Error("Expected no conversion without zoom")
+	}
+	c.ZoomAt(4, 0, 0)
+	c.Pan(400, 800)
+	throughCamera(r, c).StrokeCircle(150, 220, 10, 1, color.RGBA{})
+	if got := r.circles[0]; math.Abs(float64(got[0]-200)) > 1e-3 || math.Abs(float64(got[1]-80)) > 1e-3 || got[2] != 40 {
+		t.Errorf("Expected a circle of radius 40 at (200, 80), got %v", got)
+	}
+}
@@ -0,0 +1,239 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/capture"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+// Capture settings, the defaults of Config.CaptureFormat, CaptureEvery and CaptureSeconds
+const (
+	DefaultCaptureFormat  = CaptureGIF
+	DefaultCaptureEvery   = 3  // Grab one frame every N draws (~20 fps at 60 fps)
+	DefaultCaptureSeconds = 30 // Bound the memory used by a GIF recording
+	captureDir            = "captures"
+	// captureMinRegion is the smallest region (in pixels) accepted from a mouse drag
+	captureMinRegion = 16
+)
+
+// Capture formats: a GIF is encoded in the process, the videos are encoded by ffmpeg (see capture.FFmpegRecorder)
+const (
+	CaptureGIF  = "gif"
+	CaptureMP4  = "mp4"
+	CaptureWebM = "webm"
+)
+
+// captureSettings returns the format, the number of draws between two frames and the
+// maximum number of frames of the recordings configured in 'cfg'
+func captureSettings(cfg *Config) (format string, every, maxFrames int) {
+	format, every, seconds := cfg.CaptureFormat, cfg.CaptureEvery, cfg.CaptureSeconds
+	if format == "" {
+		format = DefaultCaptureFormat
+	}
+	if every <= 0 {
+		every = DefaultCaptureEvery
+	}
+	if seconds <= 0 {
+		seconds = DefaultCaptureSeconds
+	}
+	return format, every, max(1, int(seconds*ebiten.DefaultTPS/float64(every)))
+}
+
+// newRecorder creates the recorder of the configured format, writing to a timestamped file of captureDir
+func newRecorder(cfg *Config) capture.Recorder {
+	format, every, maxFrames := captureSettings(cfg)
+	path := filepath.Join(captureDir, fmt.Sprintf("swarm-%s.%s", time.Now().Format("20060102-150405"), format))
+	if format == CaptureGIF {
+		return capture.NewGIFRecorder(path, every*100/ebiten.DefaultTPS, maxFrames)
+	}
+	return capture.NewFFmpegRecorder(path, float64(ebiten.DefaultTPS)/float64(every), maxFrames)
+}
+
+// recordButtonLabel is the label of the record button while not recording
+func recordButtonLabel(cfg *Config) string {
+	format, _, _ := captureSettings(cfg)
+	return "Record " + strings.ToUpper(format)
+}
+
+// canWriteFiles is false in the browser, where the capture tools are not available
+const canWriteFiles = runtime.GOOS != "js"
+
+// gifCapture holds the state of the GIF or video recording of the world (UI panel excluded)
+type gifCapture struct {
+	recorder capture.Recorder
+	every    int // Draws between two frames
+	frame    int
+
+	// region is the recorded rectangle, empty means the whole screen
+	region image.Rectangle
+	// selecting is true while the user drags the region rectangle
+	selecting bool
+	dragging  bool
+	dragStart image.Point
+
+	recordButton *ui.Button
+	regionButton *ui.Button
+	followWidget *ui.Checkbox
+}
+
+// toggleGIFRecording starts or stops the recording, the file is written when stopping
+func (g *Game) toggleGIFRecording() {
+	c := &g.capture
+	if c.recorder == nil {
+		c.recorder = newRecorder(g.cfg)
+		_, c.every, _ = captureSettings(g.cfg)
+		c.frame = 0
+		c.recordButton.Label = "Stop Recording"
+		return
+	}
+	g.stopGIFRecording()
+}
+
+func (g *Game) stopGIFRecording() {
+	c := &g.capture
+	if c.recorder == nil {
+		return
+	}
+	recorder := c.recorder
+	c.recorder = nil
+	c.recordButton.Label = recordButtonLabel(g.cfg)
+	// Encoding takes a while for long recordings, don't block the game loop
+	go func() {
+		if err := recorder.Close(); err != nil {
+			g.engine.Logger().Errorf("Capture failed: %v", err)
+			return
+		}
+		g.engine.Logger().Infof("Capture saved to %s (%d frames)", recorder.Path(), recorder.Frames())
+	}()
+}
+
+// cycleGIFRegion switches between full screen and a user selected region
+func (g *Game) cycleGIFRegion() {
+	c := &g.capture
+	if c.selecting || !c.region.Empty() {
+		c.selecting = false
+		c.dragging = false
+		c.region = image.Rectangle{}
+		c.regionButton.Label = "GIF Region: full screen"
+		return
+	}
+	c.selecting = true
+	c.regionButton.Label = "GIF Region: drag on world"
+}
+
+// updateCapture handles the region selection drag, returns true when it used the mouse
+func (g *Game) updateCapture(blocked bool) bool {
+	c := &g.capture
+	if !c.selecting {
+		return false
+	}
+	mx, my := ebiten.CursorPosition()
+	if !c.dragging {
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && !blocked {
+			c.dragging = true
+			c.dragStart = image.Pt(mx, my)
+			return true
+		}
+		return false
+	}
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return true
+	}
+	// Drag finished
+	c.dragging = false
+	r := image.Rectangle{Min: c.dragStart, Max: image.Pt(mx, my)}.Canon()
+	if r.Dx() < captureMinRegion || r.Dy() < captureMinRegion {
+		return true // too small, keep selecting
+	}
+	c.selecting = false
+	c.region = r
+	c.regionButton.Label = fmt.Sprintf("GIF Region: %dx%d", r.Dx(), r.Dy())
+	return true
+}
+
+// captureRect returns the rectangle to record this frame
+func (g *Game) captureRect(screen *ebiten.Image) image.Rectangle {
+	c := &g.capture
+	bounds := screen.Bounds()
+	if c.region.Empty() {
+		return bounds
+	}
+	r := c.region
+	if c.followWidget.Value {
+		// Keep the region size, centered on the selected entity
+		if me, _, ok := g.inspector.info(g); ok {
+			x, y := g.camera.WorldToScreen(me.Pos.X, me.Pos.Y)
+			center := image.Pt(int(x), int(y))
+			r = r.Sub(r.Min).Add(center.Sub(image.Pt(r.Dx()/2, r.Dy()/2)))
+			// Clamp inside the screen without changing the size
+			if r.Min.X < bounds.Min.X {
+				r = r.Add(image.Pt(bounds.Min.X-r.Min.X, 0))
+			}
+			if r.Min.Y < bounds.Min.Y {
+				r = r.Add(image.Pt(0, bounds.Min.Y-r.Min.Y))
+			}
+			if r.Max.X > bounds.Max.X {
+				r = r.Sub(image.Pt(r.Max.X-bounds.Max.X, 0))
+			}
+			if r.Max.Y > bounds.Max.Y {
+				r = r.Sub(image.Pt(0, r.Max.Y-bounds.Max.Y))
+			}
+			c.region = r
+		}
+	}
+	return r.Intersect(bounds)
+}
+
+// captureFrame grabs the recorded region of the screen, it must be called
+// after the world is drawn and before the UI overlays.
+func (g *Game) captureFrame(screen *ebiten.Image) {
+	c := &g.capture
+	if c.recorder == nil {
+		return
+	}
+	c.frame++
+	if c.frame%c.every != 0 {
+		return
+	}
+	r := g.captureRect(screen)
+	if r.Empty() {
+		return
+	}
+	img := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	screen.SubImage(r).(*ebiten.Image).ReadPixels(img.Pix)
+	if !c.recorder.AddFrame(img) {
+		// Max duration reached
+		g.stopGIFRecording()
+	}
+}
+
+// drawCaptureOverlay shows the recorded region and the recording indicator
+func (g *Game) drawCaptureOverlay(screen *ebiten.Image) {
+	c := &g.capture
+	var r image.Rectangle
+	switch {
+	case c.dragging:
+		mx, my := ebiten.CursorPosition()
+		r = image.Rectangle{Min: c.dragStart, Max: image.Pt(mx, my)}.Canon()
+	case !c.region.Empty():
+		r = c.region
+	}
+	clr := color.RGBA{R: 255, G: 255, B: 255, A: 180}
+	if c.recorder != nil {
+		clr = color.RGBA{R: 255, G: 40, B: 40, A: 255}
+		vector.FillCircle(screen, float32(screen.Bounds().Dx()/2), 15, 6, clr, true)
+	}
+	if !r.Empty() {
+		vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 1, clr, true)
+	}
+}
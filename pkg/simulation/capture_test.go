@@ -0,0 +1,21 @@
+package simulation
+
+import "testing"
+
+func TestCaptureSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	if format, every, maxFrames := captureSettings(cfg); format != CaptureGIF || every != 3 || maxFrames != 600 {
+		t.Errorf("Expected 30s of GIF at 20 fps by default, got %s every %d, %d frames", format, every, maxFrames)
+	}
+	cfg.CaptureFormat, cfg.CaptureEvery, cfg.CaptureSeconds = CaptureMP4, 1, 10
+	if format, every, maxFrames := captureSettings(cfg); format != CaptureMP4 || every != 1 || maxFrames != 600 {
+		t.Errorf("Expected 10s of MP4 at 60 fps, got %s every %d, %d frames", format, every, maxFrames)
+	}
+	if recordButtonLabel(cfg) != "Record MP4" {
+		t.Errorf("Unexpected label %q", recordButtonLabel(cfg))
+	}
+	cfg.CaptureFormat = "avi"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
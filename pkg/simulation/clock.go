@@ -0,0 +1,114 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+const (
+	// maxSimRate is the highest Config.SimRate, in ticks per second
+	maxSimRate = 1000
+	// maxTicksInFlight is the most ticks an asynchronous engine may have received and not finished
+	// yet, the clock skips the next ones meanwhile
+	maxTicksInFlight = 2
+)
+
+// asyncEngine is an Engine whose Send returns before the tick is done, e.g. the actor engine: it
+// reports the ticks it has not finished yet so that the SimClock does not queue them up
+type asyncEngine interface {
+	ticksInFlight() int
+}
+
+// SimClock ticks an engine at a fixed rate on its own goroutine (see Config.SimRate),
+// so that a slow frame of the renderer no longer slows down the simulation:
+// the renderer only consumes the snapshots, dropping those it has no time to draw.
+// When the engine falls behind, the late ticks are skipped rather than queued: the Send of a
+// synchronous engine holds the clock, and an asynchronous one (see asyncEngine) gets no new tick
+// while maxTicksInFlight are in flight.
+type SimClock struct {
+	ticks   atomic.Uint64
+	skipped atomic.Uint64
+	paused  atomic.Bool
+	// speed is the float64 bits of the ticks sent per period
+	speed  atomic.Uint64
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartSimClock sends a Tick to 'engine' 'rate' times per second until Stop is called
+// or the engine stops. Every Tick covers 1/rate second of simulation time.
+func StartSimClock(ctx context.Context, engine Engine, rate float64) *SimClock {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &SimClock{cancel: cancel, done: make(chan struct{})}
+	c.SetSpeed(1)
+	period := time.Duration(float64(time.Second) / rate)
+	async, _ := engine.(asyncEngine)
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		// Ticks owed at the current speed, below 1 a period may send none
+		budget := 0.0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if c.paused.Load() {
+					continue
+				}
+				budget += c.Speed()
+				for ; budget >= 1; budget-- {
+					if async != nil && async.ticksInFlight() >= maxTicksInFlight {
+						c.skipped.Add(1)
+						continue
+					}
+					if err := engine.Send(ctx, &pb.Tick{DeltaTime: int64(period)}); err != nil {
+						return
+					}
+					c.ticks.Add(1)
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// Ticks returns the number of ticks sent so far
+func (c *SimClock) Ticks() uint64 {
+	return c.ticks.Load()
+}
+
+// Skipped returns the number of ticks skipped so far because the engine was late
+func (c *SimClock) Skipped() uint64 {
+	return c.skipped.Load()
+}
+
+// SetPaused suspends or resumes the ticks
+func (c *SimClock) SetPaused(paused bool) {
+	c.paused.Store(paused)
+}
+
+// SetSpeed changes the number of ticks sent per period (1 by default) without changing the
+// simulation time covered by each Tick: at 2 the simulation runs twice as fast
+func (c *SimClock) SetSpeed(speed float64) {
+	c.speed.Store(math.Float64bits(speed))
+}
+
+// Speed returns the number of ticks sent per period
+func (c *SimClock) Speed() float64 {
+	return math.Float64frombits(c.speed.Load())
+}
+
+// Stop stops the clock and waits for its last tick to be sent, it is safe to call on a nil clock
+func (c *SimClock) Stop() {
+	if c == nil {
+		return
+	}
+	c.cancel()
+	<-c.done
+}
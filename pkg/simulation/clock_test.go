@@ -0,0 +1,118 @@
+package simulation
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSimClock(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 2
+	cfg.NumBlueAtStart = 3
+	snapshotCh := make(chan *pb.WorldSnapshot, 1)
+	engine, err := NewLocalEngine(ctx, snapshotCh, cfg)
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	defer engine.Stop(ctx)
+
+	clock := StartSimClock(ctx, engine, 500)
+	// Nobody reads the snapshots for a while: the world keeps ticking, dropping them
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.Ticks() < 10 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if clock.Ticks() < 10 {
+		t.Fatalf("Expected the clock to tick without a reader, got %d ticks", clock.Ticks())
+	}
+	if snap := <-snapshotCh; snap.GetTick() == 0 {
+		t.Errorf("Expected a snapshot of a ticked world")
+	}
+
+	clock.SetSpeed(4)
+	before := clock.Ticks()
+	time.Sleep(50 * time.Millisecond)
+	if clock.Ticks()-before < 4 {
+		t.Errorf("Expected several ticks per period at x4, got %d", clock.Ticks()-before)
+	}
+
+	clock.SetPaused(true)
+	time.Sleep(20 * time.Millisecond) // a tick may be in flight
+	paused := clock.Ticks()
+	time.Sleep(50 * time.Millisecond)
+	if clock.Ticks() != paused {
+		t.Errorf("Expected no tick while paused, got %d more", clock.Ticks()-paused)
+	}
+	clock.Stop()
+	var nilClock *SimClock
+	nilClock.Stop()
+}
+
+// slowEngine is an asynchronous engine taking 'delay' per tick, one tick at a time
+type slowEngine struct {
+	delay    time.Duration
+	inFlight atomic.Int32
+	done     atomic.Int32
+	queue    chan struct{}
+}
+
+func newSlowEngine(delay time.Duration) *slowEngine {
+	e := &slowEngine{delay: delay, queue: make(chan struct{}, 1000)}
+	go func() {
+		for range e.queue {
+			time.Sleep(e.delay)
+			e.done.Add(1)
+			e.inFlight.Add(-1)
+		}
+	}()
+	return e
+}
+
+func (e *slowEngine) Send(_ context.Context, msg proto.Message) error {
+	if _, ok := msg.(*pb.Tick); ok {
+		e.inFlight.Add(1)
+		e.queue <- struct{}{}
+	}
+	return nil
+}
+
+func (e *slowEngine) ticksInFlight() int { return int(e.inFlight.Load()) }
+func (e *slowEngine) State(context.Context, string) (*pb.ActorState, error) {
+	return nil, ErrUnknownEntity
+}
+func (e *slowEngine) Logger() Logger             { return &logSwarm{} }
+func (e *slowEngine) Stop(context.Context) error { return nil }
+
+func TestSimClock_slowEngine(t *testing.T) {
+	engine := newSlowEngine(20 * time.Millisecond)
+	clock := StartSimClock(context.Background(), engine, 500)
+	time.Sleep(200 * time.Millisecond)
+	clock.Stop()
+	if clock.Skipped() == 0 {
+		t.Errorf("Expected the clock to skip the ticks of a slow engine, sent %d", clock.Ticks())
+	}
+	if pending := engine.ticksInFlight(); pending > maxTicksInFlight {
+		t.Errorf("Expected at most %d ticks queued, got %d", maxTicksInFlight, pending)
+	}
+	if sent := clock.Ticks(); sent > uint64(engine.done.Load())+maxTicksInFlight {
+		t.Errorf("Expected the ticks sent to follow the engine, %d sent for %d done", sent, engine.done.Load())
+	}
+}
+
+func TestConfig_simRateRange(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SimRate = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative simRate to be rejected")
+	}
+	cfg.SimRate = 30
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected simRate 30 to be valid, got %v", err)
+	}
+}
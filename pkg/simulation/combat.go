@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// Names of the built-in combat rules selectable with Config.CombatRule
+const (
+	CombatDeterministic     = "deterministic"      // Enough defenders convert the attackers, otherwise the victim converts (default)
+	CombatProbabilistic     = "probabilistic"      // The more defenders, the more likely the attackers convert
+	CombatEnergy            = "energy"             // The rested defenders must outweigh the rested attackers
+	CombatMutualDestruction = "mutual-destruction" // Fights kill instead of converting
+)
+
+// CombatRule decides the outcome of a Fight, only valid during the call to Resolve. A rule is
+// created for every world, which runs all its fights on one goroutine.
+type CombatRule interface {
+	Resolve(f *Fight)
+}
+
+// CombatRuleFunc adapts a function to a CombatRule
+type CombatRuleFunc func(f *Fight)
+
+func (fn CombatRuleFunc) Resolve(f *Fight) {
+	fn(f)
+}
+
+// CombatRuleFactory creates a fresh CombatRule instance
+type CombatRuleFactory func() CombatRule
+
+var (
+	combatRulesMu sync.RWMutex
+	combatRules   = make(map[string]CombatRuleFactory)
+)
+
+func init() {
+	RegisterCombatRule(CombatDeterministic, func() CombatRule { return CombatRuleFunc(deterministicRule) })
+	RegisterCombatRule(CombatProbabilistic, func() CombatRule { return CombatRuleFunc(probabilisticRule) })
+	RegisterCombatRule(CombatEnergy, func() CombatRule { return CombatRuleFunc(energyRule) })
+	RegisterCombatRule(CombatMutualDestruction, func() CombatRule { return CombatRuleFunc(mutualDestructionRule) })
+}
+
+// RegisterCombatRule adds (or replaces) a named combat rule in the registry
+func RegisterCombatRule(name string, factory CombatRuleFactory) {
+	combatRulesMu.Lock()
+	defer combatRulesMu.Unlock()
+	combatRules[name] = factory
+}
+
+// NewCombatRule creates a CombatRule by name, CombatDeterministic when empty
+func NewCombatRule(name string) (CombatRule, error) {
+	if name == "" {
+		name = CombatDeterministic
+	}
+	combatRulesMu.RLock()
+	factory, ok := combatRules[name]
+	combatRulesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown combatRule %q (use one of %v)", name, CombatRuleNames())
+	}
+	return factory(), nil
+}
+
+// CombatRuleNames returns the names of the registered combat rules, the default first
+func CombatRuleNames() []string {
+	combatRulesMu.RLock()
+	defer combatRulesMu.RUnlock()
+	names := make([]string, 0, len(combatRules))
+	for name := range combatRules {
+		if name != CombatDeterministic {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return append([]string{CombatDeterministic}, names...)
+}
+
+// Fight is a victim and the reds attacking it during a tick, handed to the CombatRule once the
+// contacts of the tick are known (see contacts.go)
+type Fight struct {
+	Victim *Entity
+	// Attackers are the reds fighting the victim, by ID: those cooling down from an earlier attack
+	// are left out, and only the strongest one fights with ContactStrongest
+	Attackers []*Entity
+	// Defenders are the blues defending the victim (see Config.DefenseCount), nearest first
+	Defenders []*Entity
+	// DefendersNeeded is the number of defenders set by Config.DefendersNeeded
+	DefendersNeeded int
+	// Rand is the random stream of the fights of the world, seeded by Config.Seed
+	Rand *rand.Rand
+
+	w *world
+}
+
+// Defended reports whether the victim has DefendersNeeded defenders
+func (f *Fight) Defended() bool {
+	return len(f.Defenders) >= f.DefendersNeeded
+}
+
+// Convert switches 'e' to the team 'color' at the end of the tick, the victim credited to its first
+// attacker. An entity converts or dies at most once per tick: later calls are ignored, as those for
+// an entity under PowerUpImmunity.
+func (f *Fight) Convert(e *Entity, color pb.TeamColor) {
+	if f.w.immune(e) {
+		return
+	}
+	attacker := e
+	if e == f.Victim {
+		attacker = f.Attackers[0]
+	}
+	f.w.convertOnce(e.ID, color, attacker.ID, f.Victim.ID)
+}
+
+// Kill removes 'e' from the world once the fights of the tick are over, like CommandQueue.Despawn
+func (f *Fight) Kill(e *Entity) {
+	if f.w.converted[e.ID] || f.w.immune(e) {
+		return
+	}
+	f.w.converted[e.ID] = true
+	f.w.killed = append(f.w.killed, e.ID)
+}
+
+// deterministicRule converts the attackers of a defended victim to blue, the victim to red otherwise
+func deterministicRule(f *Fight) {
+	if f.Defended() {
+		for _, attacker := range f.Attackers {
+			f.Convert(attacker, pb.TeamColor_TEAM_BLUE)
+		}
+		return
+	}
+	f.Convert(f.Victim, pb.TeamColor_TEAM_RED)
+}
+
+// probabilisticRule converts the attackers to blue with a chance of d / (d + DefendersNeeded) for d
+// defenders (none without defenders, one in two with as many as needed), the victim to red otherwise
+func probabilisticRule(f *Fight) {
+	defenders := float64(len(f.Defenders))
+	if f.Rand.Float64() < defenders/(defenders+float64(f.DefendersNeeded)) {
+		for _, attacker := range f.Attackers {
+			f.Convert(attacker, pb.TeamColor_TEAM_BLUE)
+		}
+		return
+	}
+	f.Convert(f.Victim, pb.TeamColor_TEAM_RED)
+}
+
+// energyRule weighs the energy of both sides: a red is worth 1 - Fatigue, a blue 1 - Hunger (see
+// Config.RedStamina and Config.FoodRate). The attackers convert to blue when the defenders outweigh
+// DefendersNeeded times the attackers, the victim to red otherwise: as deterministicRule against a
+// single rested red, but a tired red is repelled by fewer blues and a starving flock defends worse.
+func energyRule(f *Fight) {
+	var defense, attack float64
+	for _, e := range f.Defenders {
+		defense += 1 - e.Hunger
+	}
+	for _, e := range f.Attackers {
+		attack += 1 - e.Fatigue
+	}
+	if defense > 0 && defense >= float64(f.DefendersNeeded)*attack {
+		for _, attacker := range f.Attackers {
+			f.Convert(attacker, pb.TeamColor_TEAM_BLUE)
+		}
+		return
+	}
+	f.Convert(f.Victim, pb.TeamColor_TEAM_RED)
+}
+
+// mutualDestructionRule kills instead of converting: the attackers of a defended victim die,
+// otherwise the victim dies with its first attacker
+func mutualDestructionRule(f *Fight) {
+	if f.Defended() {
+		for _, attacker := range f.Attackers {
+			f.Kill(attacker)
+		}
+		return
+	}
+	f.Kill(f.Victim)
+	f.Kill(f.Attackers[0])
+}
@@ -0,0 +1,523 @@
+package simulation
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"google.golang.org/protobuf/proto"
+)
+
+// scriptSwarm is a swarm without individuals: the test scripts the states they would report
+// and the swarm records every message the world tells them
+type scriptSwarm struct {
+	logSwarm
+	w    *world
+	told []toldMessage
+}
+
+type toldMessage struct {
+	id  string
+	msg proto.Message
+}
+
+func (s *scriptSwarm) tell(id string, msg proto.Message) bool {
+	if _, ok := s.w.entities[id]; !ok {
+		return false
+	}
+	s.told = append(s.told, toldMessage{id: id, msg: msg})
+	return true
+}
+
+// convertOrder is a Convert message told to an entity, e.g. "Blue-000->TEAM_RED"
+type convertOrder string
+
+// newScriptedWorld creates a world on a scriptSwarm, with an empty population
+func newScriptedWorld(cfg *Config, opts ...WorldOption) (*world, *scriptSwarm) {
+	w := newWorld(make(chan *pb.WorldSnapshot, 1), cfg, opts...)
+	s := &scriptSwarm{w: w}
+	w.swarm = s
+	return w, s
+}
+
+// report feeds the world the states the individuals would send before the next tick
+func (s *scriptSwarm) report(states ...*pb.ActorState) {
+	for _, state := range states {
+		s.w.handle(state)
+	}
+}
+
+// tick runs one tick and returns the Convert messages it emitted, in order
+func (s *scriptSwarm) tick() []convertOrder {
+	s.told = s.told[:0]
+	s.w.handle(&pb.Tick{})
+	var converts []convertOrder
+	for _, m := range s.told {
+		if c, ok := m.msg.(*pb.Convert); ok {
+			converts = append(converts, convertOrder(fmt.Sprintf("%s->%s", m.id, c.TargetColor)))
+		}
+	}
+	return converts
+}
+
+func redAt(id string, x, y float64) *pb.ActorState {
+	return &pb.ActorState{Id: id, Color: pb.TeamColor_TEAM_RED, Position: &pb.Vector{X: x, Y: y}, Velocity: &pb.Vector{}}
+}
+
+func blueAt(id string, x, y float64) *pb.ActorState {
+	return &pb.ActorState{Id: id, Color: pb.TeamColor_TEAM_BLUE, Position: &pb.Vector{X: x, Y: y}, Velocity: &pb.Vector{}}
+}
+
+// combatConfig is a world where contacts happen within 10 units and defenders count within 40 units
+func combatConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.DetectionRadius = 50
+	cfg.DefenseRadius = 40
+	cfg.ContactRadius = 10
+	return cfg
+}
+
+func TestCombat_rules(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []*pb.ActorState
+		want   []convertOrder
+	}{
+		{
+			name:   "lone victim is converted",
+			states: []*pb.ActorState{redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100)},
+			want:   []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name: "two defenders are not enough",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100),
+				blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130),
+			},
+			want: []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name: "three defenders convert the attacker",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100),
+				blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 90, 90),
+			},
+			want: []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+		{
+			name: "defenders are counted around the victim, within the defense radius",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100),
+				blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 146, 100),
+			},
+			want: []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name: "red neighbors do not defend",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100),
+				redAt("Red-001", 120, 100), redAt("Red-002", 105, 130), redAt("Red-003", 90, 90),
+			},
+			want: []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name:   "no contact beyond the contact radius",
+			states: []*pb.ActorState{redAt("Red-000", 100, 100), blueAt("Blue-000", 111, 100)},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, s := newScriptedWorld(combatConfig())
+			s.report(tt.states...)
+			if got := s.tick(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Converts = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// movingBlueAt is blueAt with a velocity
+func movingBlueAt(id string, x, y, vx, vy float64) *pb.ActorState {
+	state := blueAt(id, x, y)
+	state.Velocity = &pb.Vector{X: vx, Y: vy}
+	return state
+}
+
+func TestCombat_defenseRules(t *testing.T) {
+	// Blue-000 attacked by Red-000 from its left, with three defenders on its right
+	attack := []*pb.ActorState{redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100)}
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		states []*pb.ActorState
+		want   []convertOrder
+	}{
+		{
+			name:   "two defenders are enough when two are needed",
+			mutate: func(c *Config) { c.DefendersNeeded = 2 },
+			states: []*pb.ActorState{blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130)},
+			want:   []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+		{
+			name:   "three defenders are not enough when four are needed",
+			mutate: func(c *Config) { c.DefendersNeeded = 4 },
+			states: []*pb.ActorState{blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 90, 90)},
+			want:   []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name:   "nearest counts the defenders beyond the defense radius",
+			mutate: func(c *Config) { c.DefenseCount = DefenseCountNearest },
+			states: []*pb.ActorState{blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 155, 100)},
+			want:   []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+		{
+			name:   "nearest ignores the defenders behind closer reds",
+			mutate: func(c *Config) { c.DefenseCount = DefenseCountNearest },
+			states: []*pb.ActorState{
+				redAt("Red-001", 110, 110), redAt("Red-002", 110, 90), redAt("Red-003", 100, 110), redAt("Red-004", 100, 90),
+				redAt("Red-005", 115, 100), redAt("Red-006", 105, 115),
+				blueAt("Blue-001", 130, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 80, 80),
+			},
+			want: []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name:   "facing defenders convert the attacker",
+			mutate: func(c *Config) { c.DefenseFacing = true },
+			states: []*pb.ActorState{movingBlueAt("Blue-001", 120, 100, -1, 0), movingBlueAt("Blue-002", 105, 130, 0, -1), movingBlueAt("Blue-003", 90, 90, 1, 1)},
+			want:   []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+		{
+			name:   "defenders heading away do not defend",
+			mutate: func(c *Config) { c.DefenseFacing = true },
+			states: []*pb.ActorState{movingBlueAt("Blue-001", 120, 100, 1, 0), movingBlueAt("Blue-002", 105, 130, 0, -1), movingBlueAt("Blue-003", 90, 90, 1, 1)},
+			want:   []convertOrder{"Blue-000->TEAM_RED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := combatConfig()
+			tt.mutate(cfg)
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			_, s := newScriptedWorld(cfg)
+			s.report(append(slices.Clone(attack), tt.states...)...)
+			if got := s.tick(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Converts = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.DefendersNeeded = -1 },
+		func(c *Config) { c.DefenseCount = "cone" },
+		func(c *Config) { c.Engine, c.DefenseCount = EngineECS, DefenseCountNearest },
+		func(c *Config) { c.Engine, c.DefenseFacing = EngineECS, true },
+	} {
+		bad := combatConfig()
+		mutate(bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestCombat_scriptedApproach(t *testing.T) {
+	_, s := newScriptedWorld(combatConfig())
+	// The attacker closes in on a victim with two defenders, a third one joins at the last step
+	script := []struct {
+		states []*pb.ActorState
+		want   []convertOrder
+	}{
+		{[]*pb.ActorState{redAt("Red-000", 60, 100), blueAt("Blue-000", 100, 100), blueAt("Blue-001", 110, 110), blueAt("Blue-002", 110, 90)}, nil},
+		{[]*pb.ActorState{redAt("Red-000", 80, 100)}, nil},
+		{[]*pb.ActorState{redAt("Red-000", 95, 100)}, []convertOrder{"Blue-000->TEAM_RED"}},
+		{[]*pb.ActorState{blueAt("Blue-003", 120, 100)}, []convertOrder{"Red-000->TEAM_BLUE"}},
+	}
+	for i, step := range script {
+		s.report(step.states...)
+		if got := s.tick(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("Tick %d: converts = %v, expected %v", i+1, got, step.want)
+		}
+	}
+}
+
+func TestCombat_convertCarriesTheNewTeamStrategy(t *testing.T) {
+	cfg := combatConfig()
+	cfg.RedStrategy = StrategyPackHunter
+	_, s := newScriptedWorld(cfg)
+	s.report(redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100))
+	s.tick()
+	for _, m := range s.told {
+		if c, ok := m.msg.(*pb.Convert); ok {
+			if c.Strategy != StrategyPackHunter {
+				t.Errorf("Expected the victim to get the red strategy, got %q", c.Strategy)
+			}
+			return
+		}
+	}
+	t.Error("Expected a Convert message")
+}
+
+func TestCombat_multipleAttackers(t *testing.T) {
+	// Blue-000 at (100, 100) with three defenders on its right, out of reach of the attackers
+	defended := []*pb.ActorState{
+		blueAt("Blue-000", 100, 100), blueAt("Blue-001", 120, 90), blueAt("Blue-002", 120, 100), blueAt("Blue-003", 120, 110),
+	}
+	// Red-001 has a pack of two reds above it, out of contact with the victim
+	pack := []*pb.ActorState{redAt("Red-002", 100, 60), redAt("Red-003", 105, 62)}
+	tests := []struct {
+		name   string
+		policy string
+		states []*pb.ActorState
+		want   []convertOrder
+	}{
+		{
+			name:   "a lone victim of two attackers is converted once",
+			states: []*pb.ActorState{redAt("Red-000", 95, 100), redAt("Red-001", 105, 100), blueAt("Blue-000", 100, 100)},
+			want:   []convertOrder{"Blue-000->TEAM_RED"},
+		},
+		{
+			name:   "a defended victim converts all its attackers",
+			states: append([]*pb.ActorState{redAt("Red-000", 92, 100), redAt("Red-001", 100, 92)}, defended...),
+			want:   []convertOrder{"Red-000->TEAM_BLUE", "Red-001->TEAM_BLUE"},
+		},
+		{
+			name:   "only the strongest attacker fights",
+			policy: ContactStrongest,
+			states: append(append([]*pb.ActorState{redAt("Red-000", 92, 100), redAt("Red-001", 100, 92)}, pack...), defended...),
+			want:   []convertOrder{"Red-001->TEAM_BLUE"},
+		},
+		{
+			name:   "a tie between the strongest goes to the lowest ID",
+			policy: ContactStrongest,
+			states: append([]*pb.ActorState{redAt("Red-001", 100, 92), redAt("Red-000", 92, 100)}, defended...),
+			want:   []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+		{
+			name: "an attacker repelled by two victims converts once",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 95, 100), blueAt("Blue-001", 105, 100),
+				blueAt("Blue-002", 100, 120), blueAt("Blue-003", 100, 80), blueAt("Blue-004", 80, 100),
+			},
+			want: []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The outcome does not depend on the order in which the entities arrived
+			for _, reversed := range []bool{false, true} {
+				cfg := combatConfig()
+				cfg.ContactPolicy = tt.policy
+				_, s := newScriptedWorld(cfg)
+				states := slices.Clone(tt.states)
+				if reversed {
+					slices.Reverse(states)
+				}
+				s.report(states...)
+				if got := s.tick(); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Converts = %v, expected %v (reversed: %v)", got, tt.want, reversed)
+				}
+			}
+		})
+	}
+}
+
+func TestCombat_spawnProtection(t *testing.T) {
+	cfg := combatConfig()
+	cfg.SpawnProtectionTicks = 2
+	w, s := newScriptedWorld(cfg)
+	// The initial population spawned at tick 0: protected during ticks 1 and 2
+	s.report(redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100))
+	for tick := 1; tick <= 2; tick++ {
+		if got := s.tick(); got != nil {
+			t.Fatalf("Tick %d: expected no convert during the protection, got %v", tick, got)
+		}
+	}
+	if got, want := s.tick(), []convertOrder{"Blue-000->TEAM_RED"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tick 3: converts = %v, expected %v", got, want)
+	}
+
+	// A blue spawned during tick 3 next to a red is protected until tick 5
+	blue := w.spawnEntity(pb.TeamColor_TEAM_BLUE, geometry.Vector2D{X: 300, Y: 300}, geometry.Vector2D{})
+	s.report(redAt("Blue-000", 105, 100), redAt("Red-001", 305, 300))
+	for tick := 4; tick <= 5; tick++ {
+		if got := s.tick(); got != nil {
+			t.Fatalf("Tick %d: expected the spawned blue to be protected, got %v", tick, got)
+		}
+	}
+	if got, want := s.tick(), []convertOrder{convertOrder(blue.ID + "->TEAM_RED")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tick 6: converts = %v, expected %v", got, want)
+	}
+}
+
+func TestConfig_contactPolicy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContactPolicy = "first-come"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown contactPolicy to be rejected")
+	}
+	cfg.ContactPolicy = ContactStrongest
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected %s to be valid, got %v", ContactStrongest, err)
+	}
+}
+
+func TestCombat_attackCooldown(t *testing.T) {
+	cfg := combatConfig()
+	cfg.AttackCooldown = 3
+	_, s := newScriptedWorld(cfg)
+	// One red touching two blues: it only attacks the first one, then cools down
+	s.report(redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100), blueAt("Blue-001", 100, 105))
+	if got, want := s.tick(), []convertOrder{"Blue-000->TEAM_RED"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tick 1: converts = %v, expected %v", got, want)
+	}
+	s.report(redAt("Blue-000", 500, 500))
+	for tick := 2; tick <= 3; tick++ {
+		if got := s.tick(); got != nil {
+			t.Fatalf("Tick %d: expected the red to cool down, got %v", tick, got)
+		}
+	}
+	if got, want := s.tick(), []convertOrder{"Blue-001->TEAM_RED"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tick 4: converts = %v, expected %v", got, want)
+	}
+}
+
+func TestCombat_combatRules(t *testing.T) {
+	// Blue-000 attacked by Red-000, with the defenders of each case
+	attack := []*pb.ActorState{redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100)}
+	three := []*pb.ActorState{blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 90, 90)}
+	tests := []struct {
+		name   string
+		rule   string
+		states []*pb.ActorState
+		want   []convertOrder
+		dead   []string
+	}{
+		{name: "deterministic: defended", rule: CombatDeterministic, states: three, want: []convertOrder{"Red-000->TEAM_BLUE"}},
+		{name: "probabilistic: no chance without defenders", rule: CombatProbabilistic, want: []convertOrder{"Blue-000->TEAM_RED"}},
+		{name: "energy: rested defenders", rule: CombatEnergy, states: three, want: []convertOrder{"Red-000->TEAM_BLUE"}},
+		{name: "energy: a lone victim", rule: CombatEnergy, want: []convertOrder{"Blue-000->TEAM_RED"}},
+		{name: "mutual destruction: defended", rule: CombatMutualDestruction, states: three, dead: []string{"Red-000"}},
+		{name: "mutual destruction: alone", rule: CombatMutualDestruction, dead: []string{"Blue-000", "Red-000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := combatConfig()
+			cfg.CombatRule = tt.rule
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			var dead []string
+			sink := EventSinkFunc(func(e Event) {
+				if e.Kind == EventDeath {
+					dead = append(dead, e.ID)
+				}
+			})
+			w, s := newScriptedWorld(cfg, WithEventSink(sink))
+			s.report(append(slices.Clone(attack), tt.states...)...)
+			if got := s.tick(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Converts = %v, expected %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(dead, tt.dead) {
+				t.Errorf("Deaths = %v, expected %v", dead, tt.dead)
+			}
+			for _, id := range tt.dead {
+				if _, ok := w.entities[id]; ok {
+					t.Errorf("Expected %s to leave the world", id)
+				}
+			}
+		})
+	}
+}
+
+func TestCombat_energyRule(t *testing.T) {
+	cfg := combatConfig()
+	cfg.CombatRule = CombatEnergy
+	cfg.RedStamina = 20
+	live := newLiveConfig(cfg)
+	live.food.Store(&foodState{})
+	// spend runs an individual for 'ticks' real ticks, chasing a blue far ahead, and returns the state it
+	// reports at 'x' near the fight: its energy is only known to the world through that state
+	spend := func(color pb.TeamColor, id string, ticks int, x float64) *pb.ActorState {
+		ind := newIndividual(color, 100, 300, cfg.MaxSpeed, 0, live, nil)
+		ind.setID(id)
+		prey := &pb.Perception{Targets: []*pb.ActorState{blueAt("Blue-999", 700, 300)}}
+		var state *pb.ActorState
+		for range ticks {
+			state = ind.handleTick(&pb.Tick{Context: prey})
+		}
+		state.Position = &pb.Vector{X: x, Y: 100}
+		return state
+	}
+
+	// A red exhausted by its chase is repelled by a single defender
+	w, s := newScriptedWorld(cfg)
+	tired := spend(pb.TeamColor_TEAM_RED, "Red-000", 16, 100)
+	s.report(tired, blueAt("Blue-000", 105, 100), blueAt("Blue-001", 120, 100))
+	if got := w.entities["Red-000"].Fatigue; got < 0.7 || got != tired.Fatigue {
+		t.Fatalf("Expected the world to see the fatigue of the red, got %f for %f", got, tired.Fatigue)
+	}
+	if got := s.tick(); !reflect.DeepEqual(got, []convertOrder{"Red-000->TEAM_BLUE"}) {
+		t.Errorf("Converts = %v, expected the tired red converted", got)
+	}
+
+	// As many defenders as needed repel a rested red while fed, not once they starve
+	fight := func(hungerTicks int) []convertOrder {
+		w, s := newScriptedWorld(cfg)
+		s.report(spend(pb.TeamColor_TEAM_RED, "Red-000", 1, 100), blueAt("Blue-000", 105, 100))
+		for n := range cfg.DefendersNeeded {
+			id := fmt.Sprintf("Blue-%03d", n+1)
+			s.report(spend(pb.TeamColor_TEAM_BLUE, id, hungerTicks, 110+float64(n)*5))
+			if hungerTicks > 1 && w.entities[id].Hunger < 0.5 {
+				t.Fatalf("Expected the world to see the hunger of %s, got %f", id, w.entities[id].Hunger)
+			}
+		}
+		return s.tick()
+	}
+	if got := fight(1); !reflect.DeepEqual(got, []convertOrder{"Red-000->TEAM_BLUE"}) {
+		t.Errorf("Converts = %v, expected the fed blues to repel the red", got)
+	}
+	if got := fight(1200); !reflect.DeepEqual(got, []convertOrder{"Blue-000->TEAM_RED"}) {
+		t.Errorf("Converts = %v, expected the starving blues to lose their friend", got)
+	}
+}
+
+func TestCombat_registeredRule(t *testing.T) {
+	var fights []string
+	RegisterCombatRule("test-spare", func() CombatRule {
+		return CombatRuleFunc(func(f *Fight) {
+			fights = append(fights, fmt.Sprintf("%s by %d with %d defenders", f.Victim.ID, len(f.Attackers), len(f.Defenders)))
+		})
+	})
+	if !slices.Contains(CombatRuleNames(), "test-spare") || CombatRuleNames()[0] != CombatDeterministic {
+		t.Errorf("Expected the registered rule after the default, got %v", CombatRuleNames())
+	}
+	cfg := combatConfig()
+	cfg.CombatRule = "test-spare"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	_, s := newScriptedWorld(cfg)
+	s.report(redAt("Red-000", 95, 100), redAt("Red-001", 105, 100), blueAt("Blue-000", 100, 100), blueAt("Blue-001", 120, 100))
+	if got := s.tick(); got != nil {
+		t.Errorf("Expected no conversion, got %v", got)
+	}
+	if want := []string{"Blue-000 by 2 with 1 defenders"}; !reflect.DeepEqual(fights, want) {
+		t.Errorf("Fights = %v, expected %v", fights, want)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.CombatRule = "duel" },
+		func(c *Config) { c.Engine, c.CombatRule = EngineECS, CombatEnergy },
+	} {
+		bad := combatConfig()
+		mutate(bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package simulation
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultCommandEvery is the number of ticks between two orders when Config.CommandEvery is 0
+	defaultCommandEvery = 30
+	// defaultCommandWeight is the weight of the orders when Config.CommandWeight is 0
+	defaultCommandWeight = 0.05
+	// scatterReach is how far, in DetectionRadius, the members of a team run from a scatter point
+	scatterReach = 2.0
+)
+
+// OrderKind is what a Commander orders its team to do
+type OrderKind string
+
+// Kinds of the orders of a Commander
+const (
+	OrderNone    OrderKind = ""        // Every member moves with the strategy of its team
+	OrderRegroup OrderKind = "regroup" // Gather at Order.Point
+	OrderScatter OrderKind = "scatter" // Run away from Order.Point
+	OrderFocus   OrderKind = "focus"   // Chase the enemy Order.Target
+)
+
+// Order is what a Commander tells its whole team, blended by every member into its steering with
+// the weight Config.CommandWeight on top of the strategy of its team
+type Order struct {
+	Kind  OrderKind
+	Point geometry.Vector2D
+	// Target is the ID of the enemy to focus, Point follows it until it leaves the enemy team
+	Target string
+}
+
+// Unit is an entity as a Commander sees it
+type Unit struct {
+	ID       string
+	Pos, Vel geometry.Vector2D
+	// Friends is the number of its teammates within DefenseRadius
+	Friends int
+}
+
+// TeamBrief is the aggregated view of the world a Commander receives before each order
+type TeamBrief struct {
+	Team pb.TeamColor
+	Tick uint64
+	// Units are the members of the team and Enemies those of the other team, in arrival order
+	Units, Enemies []Unit
+	// Centroid is the mean position of the units, Spread their mean distance to it
+	Centroid, EnemyCentroid geometry.Vector2D
+	Spread                  float64
+}
+
+// Commander is the strategist of a team: every CommandEvery ticks it orders the team from the brief
+// of the world, its order is told to every member. It runs in the commander actor of its team, a
+// child of the world actor like the individuals (see commander).
+type Commander interface {
+	Command(brief *TeamBrief, cfg *Config) Order
+}
+
+// CommanderFunc adapts a function to a Commander
+type CommanderFunc func(brief *TeamBrief, cfg *Config) Order
+
+func (f CommanderFunc) Command(brief *TeamBrief, cfg *Config) Order {
+	return f(brief, cfg)
+}
+
+// WithCommander makes 'c' command 'team' instead of the built-in commander of the team, when
+// Config.CommandTeam selects it
+func WithCommander(team pb.TeamColor, c Commander) WorldOption {
+	return func(w *world) {
+		if team == pb.TeamColor_TEAM_RED {
+			w.commanders.red = c
+		} else {
+			w.commanders.blue = c
+		}
+	}
+}
+
+// RedCommander is the built-in commander of the reds: it focuses the pack on the most isolated blue,
+// one with fewer friends around it than the defenders needed to repel an attack (the nearest to the
+// pack on a tie), and regroups the pack at its centroid while every blue is well defended
+func RedCommander(brief *TeamBrief, cfg *Config) Order {
+	if len(brief.Units) == 0 || len(brief.Enemies) == 0 {
+		return Order{}
+	}
+	prey := slices.MinFunc(brief.Enemies, func(a, b Unit) int {
+		return cmp.Or(cmp.Compare(a.Friends, b.Friends),
+			cmp.Compare(a.Pos.DistanceSquaredTo(brief.Centroid), b.Pos.DistanceSquaredTo(brief.Centroid)))
+	})
+	if prey.Friends < cfg.defendersNeeded() {
+		return Order{Kind: OrderFocus, Point: prey.Pos, Target: prey.ID}
+	}
+	return Order{Kind: OrderRegroup, Point: brief.Centroid}
+}
+
+// BlueCommander is the built-in commander of the blues: it scatters the flock away from a red
+// within DetectionRadius of its centroid, and regroups a flock spread wider than VisualRange
+func BlueCommander(brief *TeamBrief, cfg *Config) Order {
+	if len(brief.Units) == 0 {
+		return Order{}
+	}
+	if len(brief.Enemies) > 0 {
+		threat := slices.MinFunc(brief.Enemies, func(a, b Unit) int {
+			return cmp.Compare(a.Pos.DistanceSquaredTo(brief.Centroid), b.Pos.DistanceSquaredTo(brief.Centroid))
+		})
+		if threat.Pos.DistanceTo(brief.Centroid) < cfg.DetectionRadius {
+			return Order{Kind: OrderScatter, Point: threat.Pos}
+		}
+	}
+	if brief.Spread > cfg.VisualRange {
+		return Order{Kind: OrderRegroup, Point: brief.Centroid}
+	}
+	return Order{}
+}
+
+// commanderID returns the name of the actor commanding 'team'
+func commanderID(team pb.TeamColor) string {
+	if team == pb.TeamColor_TEAM_RED {
+		return "Red-Commander"
+	}
+	return "Blue-Commander"
+}
+
+// commander is the commander actor of a team: the world tells it a TeamBrief at every tick, it
+// orders the team every CommandEvery ticks and tells the order to the members that do not know it
+// yet. In between, a focus order follows its target and is dropped once it left the enemy team.
+// The world dismisses it with an empty Order when CommandTeam no longer selects its team.
+type commander struct {
+	team       pb.TeamColor
+	strategist Commander
+	cfg        *liveConfig
+	order      Order
+	// told are the members that know the current order, seen those of the brief being handled
+	told, seen map[string]bool
+}
+
+func newCommander(team pb.TeamColor, strategist Commander, cfg *liveConfig) *commander {
+	return &commander{team: team, strategist: strategist, cfg: cfg, told: make(map[string]bool), seen: make(map[string]bool)}
+}
+
+// handle processes one message sent to the commander by the world, 'tell' sends its orders to the
+// members of the team
+func (c *commander) handle(msg proto.Message, tell func(id string, msg proto.Message) bool) {
+	switch msg := msg.(type) {
+	case *pb.TeamBrief:
+		c.handleBrief(msg, tell)
+	case *pb.Order:
+		c.order = Order{}
+		dismissed := &pb.Order{Team: c.team}
+		for _, id := range slices.Sorted(maps.Keys(c.told)) {
+			tell(id, dismissed)
+		}
+		clear(c.told)
+	}
+}
+
+func (c *commander) handleBrief(msg *pb.TeamBrief, tell func(id string, msg proto.Message) bool) {
+	cfg := c.cfg.Load()
+	brief := teamBriefFromProto(msg)
+	every := uint64(cfg.CommandEvery)
+	if every == 0 {
+		every = defaultCommandEvery
+	}
+	previous := c.order
+	switch {
+	case (brief.Tick-1)%every == 0:
+		c.order = c.strategist.Command(brief, cfg)
+	case c.order.Kind == OrderFocus:
+		if i := slices.IndexFunc(brief.Enemies, func(u Unit) bool { return u.ID == c.order.Target }); i >= 0 {
+			c.order.Point = brief.Enemies[i].Pos
+		} else {
+			c.order = Order{}
+		}
+	}
+	if !c.order.same(previous) {
+		clear(c.told)
+	}
+	order := c.order.toProto(c.team)
+	clear(c.seen)
+	for _, u := range brief.Units {
+		if !c.told[u.ID] {
+			tell(u.ID, order)
+		}
+		c.seen[u.ID] = true
+	}
+	// The members that left the team forgot the order, they are told again if they come back
+	c.told, c.seen = c.seen, c.told
+}
+
+// same reports whether the members following 'o' need not be told 'other': the members chasing
+// a focus target follow it themselves
+func (o Order) same(other Order) bool {
+	if o.Kind == OrderFocus {
+		return other.Kind == OrderFocus && o.Target == other.Target
+	}
+	return o == other
+}
+
+// commanderTracker keeps the commanders of the world
+type commanderTracker struct {
+	// red and blue replace the built-in commanders (see WithCommander)
+	red, blue Commander
+	// spawned are the teams whose commander actor runs, briefed those briefed at the last tick
+	spawned, briefed map[pb.TeamColor]bool
+}
+
+// of returns the commander of 'team'
+func (t *commanderTracker) of(team pb.TeamColor) Commander {
+	switch {
+	case team == pb.TeamColor_TEAM_RED && t.red != nil:
+		return t.red
+	case team == pb.TeamColor_TEAM_RED:
+		return CommanderFunc(RedCommander)
+	case t.blue != nil:
+		return t.blue
+	}
+	return CommanderFunc(BlueCommander)
+}
+
+// updateCommanders briefs the commanders of CommandTeam, spawning the actor of a team the first time
+// it is selected, and dismisses the commander of a team no longer selected
+func (w *world) updateCommanders() {
+	t := &w.commanders
+	if w.cfg.CommandTeam == "" && len(t.briefed) == 0 {
+		return
+	}
+	if t.spawned == nil {
+		t.spawned, t.briefed = make(map[pb.TeamColor]bool), make(map[pb.TeamColor]bool)
+	}
+	var red, blue []*pb.Unit
+	if w.cfg.CommandTeam != "" {
+		red, blue = w.units()
+	}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		id := commanderID(team)
+		switch {
+		case teamSelected(w.cfg.CommandTeam, team):
+			if !t.spawned[team] {
+				w.swarm.spawnCommander(id, newCommander(team, t.of(team), w.live))
+				t.spawned[team] = true
+			}
+			units, enemies := red, blue
+			if team == pb.TeamColor_TEAM_BLUE {
+				units, enemies = blue, red
+			}
+			w.swarm.tell(id, newTeamBrief(team, w.tick, units, enemies))
+			t.briefed[team] = true
+		case t.briefed[team]:
+			w.swarm.tell(id, &pb.Order{Team: team})
+			delete(t.briefed, team)
+		}
+	}
+}
+
+// units returns the members of both teams as the commanders see them, in arrival order
+func (w *world) units() (red, blue []*pb.Unit) {
+	for _, e := range w.order {
+		if e.Color == NeutralColor {
+			continue
+		}
+		unit := &pb.Unit{
+			Id:       e.ID,
+			Position: GeomVector2DToProto(e.Pos),
+			Velocity: GeomVector2DToProto(e.Vel),
+			Friends:  int32(w.countFriendsInRadius(e.Pos, w.defenseRadius, e.Color, e.ID)),
+		}
+		if e.Color == pb.TeamColor_TEAM_RED {
+			red = append(red, unit)
+		} else {
+			blue = append(blue, unit)
+		}
+	}
+	return red, blue
+}
+
+// newTeamBrief aggregates the world as seen by the commander of 'team', sharing the units with the
+// brief of the other team
+func newTeamBrief(team pb.TeamColor, tick uint64, units, enemies []*pb.Unit) *pb.TeamBrief {
+	b := &pb.TeamBrief{Team: team, Tick: tick, Units: units, Enemies: enemies}
+	center := centroid(units)
+	for _, u := range units {
+		b.Spread += GeomVector2DFromProto(u.Position).DistanceTo(center) / float64(len(units))
+	}
+	b.Centroid = GeomVector2DToProto(center)
+	b.EnemyCentroid = GeomVector2DToProto(centroid(enemies))
+	return b
+}
+
+// centroid returns the mean position of 'units', the origin when there is none
+func centroid(units []*pb.Unit) geometry.Vector2D {
+	var sum geometry.Vector2D
+	for _, u := range units {
+		sum = sum.Add(GeomVector2DFromProto(u.Position))
+	}
+	if len(units) == 0 {
+		return sum
+	}
+	return sum.Mul(1 / float64(len(units)))
+}
+
+// teamBriefFromProto converts the brief told to a commander actor for its Commander
+func teamBriefFromProto(p *pb.TeamBrief) *TeamBrief {
+	return &TeamBrief{
+		Team:          p.Team,
+		Tick:          p.Tick,
+		Units:         unitsFromProto(p.Units),
+		Enemies:       unitsFromProto(p.Enemies),
+		Centroid:      GeomVector2DFromProto(p.Centroid),
+		EnemyCentroid: GeomVector2DFromProto(p.EnemyCentroid),
+		Spread:        p.Spread,
+	}
+}
+
+func unitsFromProto(units []*pb.Unit) []Unit {
+	converted := make([]Unit, len(units))
+	for i, u := range units {
+		converted[i] = Unit{ID: u.Id, Pos: GeomVector2DFromProto(u.Position), Vel: GeomVector2DFromProto(u.Velocity), Friends: int(u.Friends)}
+	}
+	return converted
+}
+
+// toProto converts the order of the commander of 'team' to the message told to its members
+func (o Order) toProto(team pb.TeamColor) *pb.Order {
+	return &pb.Order{Team: team, Kind: string(o.Kind), Point: GeomVector2DToProto(o.Point), Target: o.Target}
+}
+
+// orderFromProto converts the order told to a member
+func orderFromProto(p *pb.Order) Order {
+	return Order{Kind: OrderKind(p.Kind), Point: GeomVector2DFromProto(p.Point), Target: p.Target}
+}
+
+// applyOrder blends the last order of the commander of its team into the steering of 'me' with the
+// weight CommandWeight: regroup arrives at the point, scatter flees it within scatterReach, focus
+// seeks the target, where 'me' sees it or where the commander last saw it
+func applyOrder(me *Entity, order *Order, perception *pb.Perception, cfg *Config) {
+	weight := cfg.CommandWeight
+	if weight == 0 {
+		weight = defaultCommandWeight
+	}
+	var steer geometry.Vector2D
+	switch order.Kind {
+	case OrderRegroup:
+		steer = behavior.Arrive(me.Pos, me.Vel, order.Point, cfg.MaxSpeed, cfg.VisualRange)
+	case OrderScatter:
+		if me.Pos.DistanceTo(order.Point) > cfg.DetectionRadius*scatterReach {
+			return
+		}
+		steer = behavior.Flee(me.Pos, me.Vel, order.Point, cfg.MaxSpeed)
+	case OrderFocus:
+		point := order.Point
+		for _, target := range perception.GetTargets() {
+			if target.Id == order.Target {
+				point = GeomVector2DFromProto(target.Position)
+				break
+			}
+		}
+		steer = behavior.Seek(me.Pos, me.Vel, point, cfg.MaxSpeed)
+	default:
+		return
+	}
+	me.ApplyForce(steer.Mul(weight))
+}
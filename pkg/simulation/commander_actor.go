@@ -0,0 +1,56 @@
+//go:build !js
+
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/tochemey/goakt/v3/actor"
+	"github.com/tochemey/goakt/v3/goaktpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// commanderActor runs the commander of a team as a GoAkt actor, child of the world actor like the
+// individuals it tells its orders to
+type commanderActor struct {
+	*commander
+	ctx *actor.ReceiveContext // Context of the message being processed
+	// pids caches the PIDs of the members told an order, siblings of the commander
+	pids map[string]*actor.PID
+}
+
+var _ actor.Actor = (*commanderActor)(nil)
+
+func (c *commanderActor) PreStart(ctx *actor.Context) error {
+	ctx.ActorSystem().Logger().Infof("%s takes command", ctx.ActorName())
+	return nil
+}
+
+func (c *commanderActor) Receive(ctx *actor.ReceiveContext) {
+	c.ctx = ctx
+	switch msg := ctx.Message().(type) {
+	case *goaktpb.PostStart:
+	case *pb.TeamBrief, *pb.Order:
+		c.handle(msg, c.tell)
+	default:
+		ctx.Unhandled()
+	}
+}
+
+func (c *commanderActor) PostStop(ctx *actor.Context) error {
+	ctx.ActorSystem().Logger().Infof("%s dismissed", ctx.ActorName())
+	return nil
+}
+
+// tell sends 'msg' to the member 'id' of the team, false when it is not running
+func (c *commanderActor) tell(id string, msg proto.Message) bool {
+	pid := c.pids[id]
+	if pid == nil {
+		var err error
+		if pid, err = c.ctx.Self().Parent().Child(id); err != nil {
+			return false
+		}
+		c.pids[id] = pid
+	}
+	c.ctx.Tell(pid, msg)
+	return true
+}
@@ -0,0 +1,140 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestRedCommander(t *testing.T) {
+	cfg := DefaultConfig()
+	brief := &TeamBrief{
+		Team:     pb.TeamColor_TEAM_RED,
+		Units:    []Unit{{ID: "Red-000", Pos: geometry.Vector2D{X: 100, Y: 100}}},
+		Centroid: geometry.Vector2D{X: 100, Y: 100},
+		Enemies: []Unit{
+			{ID: "Blue-000", Pos: geometry.Vector2D{X: 150, Y: 100}, Friends: 4},
+			{ID: "Blue-001", Pos: geometry.Vector2D{X: 500, Y: 100}, Friends: 1},
+			{ID: "Blue-002", Pos: geometry.Vector2D{X: 400, Y: 100}, Friends: 1},
+		},
+	}
+	if got := RedCommander(brief, cfg); got.Kind != OrderFocus || got.Target != "Blue-002" || got.Point != brief.Enemies[2].Pos {
+		t.Errorf("Expected a focus on the nearest isolated blue, got %+v", got)
+	}
+	brief.Enemies = brief.Enemies[:1]
+	if got := RedCommander(brief, cfg); got.Kind != OrderRegroup || got.Point != brief.Centroid {
+		t.Errorf("Expected the pack to regroup while every blue is defended, got %+v", got)
+	}
+}
+
+func TestBlueCommander(t *testing.T) {
+	cfg := DefaultConfig()
+	brief := &TeamBrief{
+		Team:     pb.TeamColor_TEAM_BLUE,
+		Units:    []Unit{{ID: "Blue-000"}},
+		Centroid: geometry.Vector2D{X: 100, Y: 100},
+		Enemies:  []Unit{{ID: "Red-000", Pos: geometry.Vector2D{X: 130, Y: 100}}},
+	}
+	if got := BlueCommander(brief, cfg); got.Kind != OrderScatter || got.Point != brief.Enemies[0].Pos {
+		t.Errorf("Expected the flock to scatter from the close red, got %+v", got)
+	}
+	brief.Enemies[0].Pos.X = 600
+	brief.Spread = cfg.VisualRange + 1
+	if got := BlueCommander(brief, cfg); got.Kind != OrderRegroup {
+		t.Errorf("Expected the spread flock to regroup, got %+v", got)
+	}
+	brief.Spread = 0
+	if got := BlueCommander(brief, cfg); got.Kind != OrderNone {
+		t.Errorf("Expected no order, got %+v", got)
+	}
+}
+
+func TestWorld_updateCommanders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart, cfg.NumBlueAtStart = 1, 2
+	cfg.CommandTeam, cfg.CommandEvery = TeamRed, 10
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	bad := *cfg
+	bad.Engine = EngineECS
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected the commanders to be rejected by the ecs engine")
+	}
+	var briefs []*TeamBrief
+	custom := CommanderFunc(func(brief *TeamBrief, cfg *Config) Order {
+		briefs = append(briefs, brief)
+		return RedCommander(brief, cfg)
+	})
+	engine, err := NewLocalEngine(context.Background(), make(chan *pb.WorldSnapshot, 1), cfg, WithCommander(pb.TeamColor_TEAM_RED, custom))
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	e := engine.(*localEngine)
+	w := e.world
+	for id, pos := range map[string]geometry.Vector2D{"Red-000": {X: 100, Y: 100}, "Blue-000": {X: 300, Y: 100}, "Blue-001": {X: 800, Y: 600}} {
+		w.entities[id].Pos = pos
+		e.members[id].State.Pos = pos
+	}
+	tick := func() {
+		if err := engine.Send(context.Background(), &pb.Tick{}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	// The world briefs the commander actor of the reds, which tells its order to the pack
+	tick()
+	if len(e.commanders) != 1 || e.commanders[commanderID(pb.TeamColor_TEAM_RED)] == nil {
+		t.Fatalf("Expected the commander of the reds only, got %v", e.commanders)
+	}
+	if len(briefs) != 1 || len(briefs[0].Units) != 1 || len(briefs[0].Enemies) != 2 {
+		t.Fatalf("Expected one brief of the red team, got %v", briefs)
+	}
+	if got := e.members["Red-000"].order; got.Kind != OrderFocus || got.Target != "Blue-000" {
+		t.Fatalf("Expected Red-000 to focus Blue-000, got %+v", got)
+	}
+	if got := e.members["Blue-000"].order; got.Kind != OrderNone {
+		t.Errorf("Expected no blue order, got %+v", got)
+	}
+
+	// Briefed at every tick, it drops the focus once the target turned red, until the next order
+	w.entities["Blue-000"].Color = pb.TeamColor_TEAM_RED
+	e.members["Blue-000"].handleConversion(e.log, &pb.Convert{TargetColor: pb.TeamColor_TEAM_RED})
+	tick()
+	if got := e.members["Red-000"].order; got.Kind != OrderNone || len(briefs) != 1 {
+		t.Errorf("Expected the focus dropped until the next order, got %+v after %d briefs", got, len(briefs))
+	}
+	for range 9 {
+		tick()
+	}
+	// The converted blue joined the pack and is told the new order too
+	for _, id := range []string{"Red-000", "Blue-000"} {
+		if got := e.members[id].order; got.Kind != OrderFocus || got.Target != "Blue-001" || len(briefs) != 2 {
+			t.Errorf("Expected %s to focus Blue-001 after the second order, got %+v after %d briefs", id, got, len(briefs))
+		}
+	}
+
+	// A team no longer selected is dismissed
+	w.cfg.CommandTeam = ""
+	tick()
+	if got := e.members["Red-000"].order; got.Kind != OrderNone {
+		t.Errorf("Expected the order withdrawn with the commander, got %+v", got)
+	}
+
+	// A member blends the order into its steering, chasing the target where it sees it
+	focus := &Order{Kind: OrderFocus, Point: geometry.Vector2D{X: 200, Y: 100}, Target: "Blue-001"}
+	red := &Entity{Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 100, Y: 100}}
+	red.beginStep(1, "")
+	applyOrder(red, focus, nil, cfg)
+	if red.Vel.X <= 0 || red.Vel.Y != 0 {
+		t.Errorf("Expected the red pulled toward its focus, got %v", red.Vel)
+	}
+	red = &Entity{Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 100, Y: 100}}
+	red.beginStep(1, "")
+	applyOrder(red, focus, &pb.Perception{Targets: []*pb.ActorState{blueAt("Blue-001", 100, 200)}}, cfg)
+	if red.Vel.X != 0 || red.Vel.Y <= 0 {
+		t.Errorf("Expected the red pulled toward the target in sight, got %v", red.Vel)
+	}
+}
@@ -2,9 +2,13 @@ package simulation
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/engine"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -17,9 +21,12 @@ type Config struct {
 
 	// Population
 	// NumRedAtStart is the initial number of Red (Aggressive) actors.
-	NumRedAtStart int `json:"numRedAtStart"`
+	NumRedAtStart int `json:"numRedAtStart" flag:"num-red"`
 	// NumBlueAtStart is the initial number of Blue (Flocking) actors.
-	NumBlueAtStart int `json:"numBlueAtStart"`
+	NumBlueAtStart int `json:"numBlueAtStart" flag:"num-blue"`
+	// NumNeutralAtStart is the initial number of civilians, of no team: they wander and convert to
+	// the team touching them with the most entities (see neutral.go). Not supported by the ecs engine.
+	NumNeutralAtStart int `json:"numNeutralAtStart,omitempty" flag:"num-neutral"`
 
 	// Interaction Radii
 	// DetectionRadius is the radius within which Red actors can detect Blue actors.
@@ -53,6 +60,216 @@ type Config struct {
 	// MinSpeed is the minimum speed a Blue actor tries to maintain.
 	MinSpeed float64 `json:"minSpeed"`
 
+	// Obstacles are solid discs "x,y,radius" separated by semicolons (see ParseObstacles), the boids
+	// steer around them with whiskers reaching ObstacleLookahead ahead (0 = 60). Not supported by the
+	// ecs engine.
+	Obstacles         string  `json:"obstacles,omitempty"`
+	ObstacleLookahead float64 `json:"obstacleLookahead,omitempty"`
+
+	// Terrain covers the world with grounds changing how the entities move (see terrain.go): mud halves
+	// their speeds, a highway multiplies them by 1.5, ice weakens their turn at the edges. It is a list
+	// of rectangles "kind:x,y,width,height" separated by semicolons, or the path of a PNG image drawn
+	// with the colors of the grounds (see ParseTerrain). Not supported by the ecs engine.
+	Terrain string `json:"terrain,omitempty"`
+
+	// NavField is a navigation flow field the NavTeam follows (see navfield.go): "x,y" leads to that
+	// point around the obstacles, anything else is the path of a text file of arrows drawn by hand
+	// (see behavior.ParseNavField). NavWeight is the weight of its force (0 = 0.05), NavTeam is red,
+	// blue (default) or both. Not supported by the ecs engine.
+	NavField  string  `json:"navField,omitempty"`
+	NavWeight float64 `json:"navWeight,omitempty"`
+	NavTeam   string  `json:"navTeam,omitempty"`
+
+	// LeaderTeam gives a leader to red, blue or both teams (see leader.go): it heads around the loop of
+	// waypoints "x,y;x,y;..." of LeaderPath, or to the mouse cursor without path, and its flockmates
+	// seeing it are pulled toward it with the weight LeaderWeight (0 = 0.02). Not supported by the
+	// ecs engine.
+	LeaderTeam   string  `json:"leaderTeam,omitempty"`
+	LeaderPath   string  `json:"leaderPath,omitempty"`
+	LeaderWeight float64 `json:"leaderWeight,omitempty"`
+
+	// CommandTeam gives a commander actor to red, blue or both teams (see commander.go): briefed by the
+	// world at every tick, it orders its team every CommandEvery ticks (0 = 30) to regroup, scatter or
+	// focus an enemy, an order every member blends into its steering with the weight CommandWeight
+	// (0 = 0.05). Not supported by the ecs engine.
+	CommandTeam   string  `json:"commandTeam,omitempty"`
+	CommandEvery  int     `json:"commandEvery,omitempty"`
+	CommandWeight float64 `json:"commandWeight,omitempty"`
+
+	// FormationSize, FormationSpacing and FormationWeight tune the blue strategies "formation-v",
+	// "-line", "-circle" and "-grid" (see formation.go): the blues fly in formations of FormationSize
+	// (0 = 12) with slots FormationSpacing apart (0 = 30), pulled toward their slot with the weight
+	// FormationWeight (0 = 0.1) on top of the boids rules.
+	FormationSize    int     `json:"formationSize,omitempty"`
+	FormationSpacing float64 `json:"formationSpacing,omitempty"`
+	FormationWeight  float64 `json:"formationWeight,omitempty"`
+
+	// Pheromones lays two decaying fields over the world (see pheromone.go): the reds deposit danger
+	// where they go and a burst of it where they convert a blue, the blues their scent. The blues
+	// steer away from the danger, the reds follow the scent, with the weight PheromoneWeight
+	// (0 = 0.05). Every tick a cell spreads the fraction PheromoneDiffusion (0 = 0.1) of its amount
+	// to its neighbors and loses the fraction PheromoneEvaporation (0 = 0.02). Not supported by the
+	// ecs engine.
+	Pheromones           bool    `json:"pheromones,omitempty"`
+	PheromoneDiffusion   float64 `json:"pheromoneDiffusion,omitempty"`
+	PheromoneEvaporation float64 `json:"pheromoneEvaporation,omitempty"`
+	PheromoneWeight      float64 `json:"pheromoneWeight,omitempty"`
+
+	// FoodRate spawns food items at random places, FoodRate per tick (e.g. 0.05: one every 20 ticks),
+	// up to FoodMax (0 = 40) at once (see food.go). The blues grow hungry, starving ones fly at half
+	// speed: the hungrier a blue, the harder it heads to the food in sight, and eating an item
+	// satisfies the hunger FoodEnergy (0 = 0.5, 1 = fully fed). Not supported by the ecs engine.
+	FoodRate   float64 `json:"foodRate,omitempty"`
+	FoodMax    int     `json:"foodMax,omitempty"`
+	FoodEnergy float64 `json:"foodEnergy,omitempty"`
+
+	// PowerUpRate spawns pickups at random places, PowerUpRate per tick, up to PowerUpMax (0 = 6) at
+	// once (see powerup.go). A red or a blue touching a pickup collects its effect for PowerUpDuration
+	// ticks (0 = 300): a speed boost, an immunity to the fights or, for a blue, a larger defense
+	// radius. Not supported by the ecs engine.
+	PowerUpRate     float64 `json:"powerUpRate,omitempty"`
+	PowerUpMax      int     `json:"powerUpMax,omitempty"`
+	PowerUpDuration int     `json:"powerUpDuration,omitempty"`
+
+	// WindStrength is a force (pixels per tick, per tick) pushing every entity toward WindDirection
+	// (degrees clockwise from the right of the screen), 0 disables it (see wind.go). WindMode is
+	// "constant" (default), "rotating" to turn it clockwise a full turn every WindPeriod ticks
+	// (0 = 600) or "noise" for gusts around WindDirection, a new one every WindPeriod ticks. Not
+	// supported by the ecs engine.
+	WindStrength  float64 `json:"windStrength,omitempty"`
+	WindDirection float64 `json:"windDirection,omitempty"`
+	WindMode      string  `json:"windMode,omitempty"`
+	WindPeriod    int     `json:"windPeriod,omitempty"`
+
+	// ReproduceTicks and ReproduceMeals let the blues give birth to a new blue next to them (see
+	// reproduction.go): once they survived ReproduceTicks ticks as blues, or ate ReproduceMeals food
+	// items (see FoodRate), since their spawn, their conversion or their last birth; 0 disables
+	// each. No birth takes the population above MaxPopulation (0 = twice the initial population).
+	// Not supported by the ecs engine.
+	ReproduceTicks int `json:"reproduceTicks,omitempty"`
+	ReproduceMeals int `json:"reproduceMeals,omitempty"`
+	MaxPopulation  int `json:"maxPopulation,omitempty"`
+
+	// Lifespan is the number of ticks an entity lives after its spawn, ± 20%, before it dies of old
+	// age and leaves the world (see lifespan.go), 0 = forever. Not supported by the ecs engine.
+	Lifespan int `json:"lifespan,omitempty"`
+
+	// AttackCooldown is the number of ticks a red waits after an attack before the next one, 0 = none.
+	// RedStamina is the number of ticks a red can chase at full speed before it is exhausted and
+	// flies at half speed until it rested, 0 = unlimited (see stamina.go). Not supported by the ecs
+	// engine.
+	AttackCooldown int `json:"attackCooldown,omitempty"`
+	RedStamina     int `json:"redStamina,omitempty"`
+
+	// Team Strategies (names of registered behaviors, see strategy.go), "script:<file>" runs the
+	// updateRed or updateBlue function of a Starlark script (see ScriptBehavior), "bt:<file>" a
+	// behavior tree defined in JSON (see TreeBehavior)
+	// RedStrategy is the behavior used by Red actors. Default: classic-hunter
+	RedStrategy string `json:"redStrategy,omitempty"`
+	// BlueStrategy is the behavior used by Blue actors. Default: classic-boids
+	BlueStrategy string `json:"blueStrategy,omitempty"`
+
+	// SpatialIndex selects the neighbor search structure: grid (default), quadtree or auto.
+	// The quadtree stays fast when the density is very uneven (e.g. all blues clumped in a corner),
+	// auto switches between both as the flock clumps and spreads, logging every switch.
+	SpatialIndex string `json:"spatialIndex,omitempty"`
+
+	// ContactPolicy resolves the contacts of several reds with the same blue in one tick:
+	// simultaneous (default, each of them fights it) or strongest (only the red with the largest pack).
+	ContactPolicy string `json:"contactPolicy,omitempty"`
+
+	// DefendersNeeded is the number of blues around a victim, the victim excluded, that convert its
+	// attackers to blue instead (0 = 3). DefenseCount selects the blues that defend it: radius
+	// (default, all of them within DefenseRadius) or nearest (those among the 7 entities nearest to
+	// the victim within twice DefenseRadius, see defense.go). With DefenseFacing, only the blues
+	// heading toward the attacker defend. DefenseCount and DefenseFacing are not supported by the
+	// ecs engine.
+	DefendersNeeded int    `json:"defendersNeeded,omitempty"`
+	DefenseCount    string `json:"defenseCount,omitempty"`
+	DefenseFacing   bool   `json:"defenseFacing,omitempty"`
+
+	// CombatRule names the rule deciding the fights (see combat.go): deterministic (default,
+	// enough defenders convert the attackers, otherwise the victim converts), probabilistic, energy,
+	// mutual-destruction, or a rule added with RegisterCombatRule. Only the deterministic rule is
+	// supported by the ecs engine.
+	CombatRule string `json:"combatRule,omitempty"`
+
+	// SpawnProtectionTicks protects an entity during the tick of its spawn and this many ticks after it:
+	// it neither attacks nor can be attacked, so that the entities spawned by a scenario wave or a
+	// tick hook in the middle of the enemies are not converted at once. 0 (default) disables it.
+	SpawnProtectionTicks int `json:"spawnProtectionTicks,omitempty"`
+
+	// Integrator moves the entities with their velocity: semi-implicit (default), euler or verlet
+	// (exact for a constant force, see integrator.go).
+	Integrator string `json:"integrator,omitempty"`
+
+	// Engine selects what runs the simulation: actor (default), local or ecs.
+	// The ecs engine handles 50k+ entities but only runs the built-in strategies, without tick hooks.
+	Engine string `json:"engine,omitempty"`
+
+	// SimCores caps the number of cores running the actors at once, so that the renderer keeps
+	// a core at high entity counts: 0 means no limit, a negative value counts from the number of
+	// CPUs (-1 keeps one core for the render thread). Only used by the actor engine.
+	SimCores int `json:"simCores,omitempty"`
+
+	// SimRate is the number of ticks per second run by an internal clock (see SimClock),
+	// independent of the frame rate: 0 runs one tick per rendered frame.
+	SimRate float64 `json:"simRate,omitempty"`
+
+	// Seed initializes the random generators of the world, 0 picks a random seed.
+	// Every entity draws from its own stream derived from the seed and its ID.
+	// Runs are only reproducible with the local engine (actors run concurrently).
+	Seed uint64 `json:"seed,omitempty"`
+	// Rounds plays a tournament of N rounds (0 disables it): the game restarts by itself after each
+	// game over, with the next seed when Seed is set, and a scoreboard counts the wins of each team
+	// and their mean time-to-win
+	Rounds int `json:"rounds,omitempty"`
+
+	// StatsFile is the CSV file receiving one row of statistics per tick (see replay.StatsHeader),
+	// empty disables the export. Not available in the browser.
+	StatsFile string `json:"statsFile,omitempty"`
+	// PhysicsFile enables the physics validation: the kinetic energy and momentum of every tick are
+	// checked (see replay.PhysicsValidator) and exported to this CSV file. Not available in the browser.
+	PhysicsFile string `json:"physicsFile,omitempty"`
+
+	// ScreenshotKey is the key saving the current frame to a PNG in the captures directory,
+	// an Ebiten key name (F12 by default). ScreenshotUI includes the panel and the overlays.
+	ScreenshotKey string `json:"screenshotKey,omitempty"`
+	ScreenshotUI  bool   `json:"screenshotUI,omitempty"`
+	// ScreenshotOnGameOver saves the final state of every round to a PNG, and ThumbnailEvery the state
+	// every N ticks (0 disables them), in a directory of the captures named by the run ID (see NewRunID):
+	// the batch and headless runs leave stills next to their results. Not available in the browser.
+	ScreenshotOnGameOver bool `json:"screenshotOnGameOver,omitempty"`
+	ThumbnailEvery       int  `json:"thumbnailEvery,omitempty"`
+
+	// CaptureFormat is the format of the Record button: gif (default), or mp4 and webm encoded by
+	// ffmpeg, which must be in the PATH. A frame is grabbed every CaptureEvery draws (3 by default,
+	// ~20 fps) and a recording stops after CaptureSeconds (30 by default).
+	CaptureFormat  string  `json:"captureFormat,omitempty"`
+	CaptureEvery   int     `json:"captureEvery,omitempty"`
+	CaptureSeconds float64 `json:"captureSeconds,omitempty"`
+
+	// Theme is the look of the panel and the windows: dark (default) or light (see ui.ThemeNames).
+	// UIPadding (pixels) and UIFontScale (1 to 4) override the margin and the text size of the preset.
+	Theme       string  `json:"theme,omitempty"`
+	UIPadding   float64 `json:"uiPadding,omitempty"`
+	UIFontScale float64 `json:"uiFontScale,omitempty"`
+	// UIScale enlarges the panel, the overlays and their text (0.5 to 4, 1 by default) on top of the
+	// device scale factor of the display: the game draws at the resolution of HiDPI screens
+	UIScale float64 `json:"uiScale,omitempty"`
+	// Fullscreen starts the game in full screen (F11 toggles it), the world is scaled to fit the
+	// screen and the panel takes its height: for the demos on a projector
+	Fullscreen bool `json:"fullscreen,omitempty"`
+
+	// Sound plays a chirp at the conversions, a fanfare at the game over and an ambient loop, at
+	// SoundVolume (0-1, 0.5 by default). The sounds are synthesized unless ConversionSound,
+	// GameOverSound or AmbientSound name a wav, mp3 or ogg file (not available in the browser).
+	Sound           bool    `json:"sound,omitempty"`
+	SoundVolume     float64 `json:"soundVolume,omitempty"`
+	ConversionSound string  `json:"conversionSound,omitempty"`
+	GameOverSound   string  `json:"gameOverSound,omitempty"`
+	AmbientSound    string  `json:"ambientSound,omitempty"`
+
 	// Logging
 	// LogLevel sets the logging level (debug, info, warn, error). Default: info
 	LogLevel string `json:"logLevel"`
@@ -84,6 +301,9 @@ func DefaultConfig() *Config {
 		MaxSpeed:               4.0,
 		MinSpeed:               2.0,
 		Aggression:             0.8,
+		DefendersNeeded:        defendersToRepel,
+		RedStrategy:            StrategyClassicHunter,
+		BlueStrategy:           StrategyClassicBoids,
 		LogLevel:               "info",
 		LogFormat:              "json",
 		DisplayDetectionCircle: false,
@@ -91,6 +311,21 @@ func DefaultConfig() *Config {
 	}
 }
 
+// StrategyFor returns the name of the behavior configured for a team
+func (c *Config) StrategyFor(team pb.TeamColor) string {
+	name := c.BlueStrategy
+	switch team {
+	case pb.TeamColor_TEAM_RED:
+		name = c.RedStrategy
+	case NeutralColor:
+		return StrategyCivilian
+	}
+	if name == "" {
+		return DefaultStrategy(team)
+	}
+	return name
+}
+
 func (c *Config) Validate() error {
 	if c.DefenseRadius > c.DetectionRadius {
 		return fmt.Errorf("defenseRadius (%f) cannot exceed detectionRadius (%f)",
@@ -104,6 +339,225 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("minSpeed (%f) must be < maxSpeed (%f)",
 			c.MinSpeed, c.MaxSpeed)
 	}
+	switch c.SpatialIndex {
+	case "", SpatialIndexGrid, SpatialIndexQuadtree, SpatialIndexAuto:
+	default:
+		return fmt.Errorf("unknown spatialIndex %q (use %s, %s or %s)", c.SpatialIndex, SpatialIndexGrid, SpatialIndexQuadtree, SpatialIndexAuto)
+	}
+	if c.SimRate < 0 || c.SimRate > maxSimRate {
+		return fmt.Errorf("simRate (%f) must be between 0 and %d ticks per second", c.SimRate, maxSimRate)
+	}
+	switch c.ContactPolicy {
+	case "", ContactSimultaneous, ContactStrongest:
+	default:
+		return fmt.Errorf("unknown contactPolicy %q (use %s or %s)", c.ContactPolicy, ContactSimultaneous, ContactStrongest)
+	}
+	if c.NumNeutralAtStart < 0 {
+		return fmt.Errorf("numNeutralAtStart (%d) must be >= 0", c.NumNeutralAtStart)
+	}
+	if c.Engine == EngineECS && c.NumNeutralAtStart > 0 {
+		return fmt.Errorf("civilians are not supported by the %s engine", EngineECS)
+	}
+	if c.DefendersNeeded < 0 {
+		return fmt.Errorf("defendersNeeded (%d) must be >= 0", c.DefendersNeeded)
+	}
+	switch c.DefenseCount {
+	case "", DefenseCountRadius, DefenseCountNearest:
+	default:
+		return fmt.Errorf("unknown defenseCount %q (use %s or %s)", c.DefenseCount, DefenseCountRadius, DefenseCountNearest)
+	}
+	if c.Engine == EngineECS && (c.DefenseCount == DefenseCountNearest || c.DefenseFacing) {
+		return fmt.Errorf("defenseCount %s and defenseFacing are not supported by the %s engine", DefenseCountNearest, EngineECS)
+	}
+	if _, err := NewCombatRule(c.CombatRule); err != nil {
+		return err
+	}
+	if c.Engine == EngineECS && c.CombatRule != "" && c.CombatRule != CombatDeterministic {
+		return fmt.Errorf("combatRule %s is not supported by the %s engine", c.CombatRule, EngineECS)
+	}
+	if c.SpawnProtectionTicks < 0 {
+		return fmt.Errorf("spawnProtectionTicks (%d) must be >= 0", c.SpawnProtectionTicks)
+	}
+	switch c.Integrator {
+	case "", IntegratorSemiImplicit, IntegratorEuler, IntegratorVerlet:
+	default:
+		return fmt.Errorf("unknown integrator %q (use %s, %s or %s)", c.Integrator, IntegratorSemiImplicit, IntegratorEuler, IntegratorVerlet)
+	}
+	switch c.Engine {
+	case "", EngineActor, EngineLocal, EngineECS:
+	default:
+		return fmt.Errorf("unknown engine %q (use %s, %s or %s)", c.Engine, EngineActor, EngineLocal, EngineECS)
+	}
+	if err := checkScreenshotKey(c); err != nil {
+		return err
+	}
+	switch c.CaptureFormat {
+	case "", CaptureGIF, CaptureMP4, CaptureWebM:
+	default:
+		return fmt.Errorf("unknown captureFormat %q (use %s, %s or %s)", c.CaptureFormat, CaptureGIF, CaptureMP4, CaptureWebM)
+	}
+	if _, err := c.UITheme(); err != nil {
+		return err
+	}
+	if c.UIScale != 0 && (c.UIScale < 0.5 || c.UIScale > 4) {
+		return fmt.Errorf("uiScale (%f) must be between 0.5 and 4", c.UIScale)
+	}
+	if c.Rounds < 0 {
+		return fmt.Errorf("rounds (%d) must be >= 0", c.Rounds)
+	}
+	if _, err := ParseObstacles(c.Obstacles); err != nil {
+		return fmt.Errorf("invalid obstacles: %w", err)
+	}
+	if c.ObstacleLookahead < 0 {
+		return fmt.Errorf("obstacleLookahead (%f) must be >= 0", c.ObstacleLookahead)
+	}
+	if c.Engine == EngineECS && c.Obstacles != "" {
+		return fmt.Errorf("obstacles are not supported by the %s engine", EngineECS)
+	}
+	if c.Terrain != "" {
+		if _, err := c.buildTerrain(); err != nil {
+			return fmt.Errorf("invalid terrain: %w", err)
+		}
+		if c.Engine == EngineECS {
+			return fmt.Errorf("terrain is not supported by the %s engine", EngineECS)
+		}
+	}
+	if c.NavField != "" {
+		if _, err := c.buildNavField(); err != nil {
+			return err
+		}
+		if c.Engine == EngineECS {
+			return fmt.Errorf("navField is not supported by the %s engine", EngineECS)
+		}
+	}
+	if c.NavWeight < 0 {
+		return fmt.Errorf("navWeight (%f) must be >= 0", c.NavWeight)
+	}
+	switch c.NavTeam {
+	case "", TeamBlue, TeamRed, TeamBoth:
+	default:
+		return fmt.Errorf("navTeam (%q) must be %s, %s or %s", c.NavTeam, TeamBlue, TeamRed, TeamBoth)
+	}
+	switch c.LeaderTeam {
+	case "", TeamBlue, TeamRed, TeamBoth:
+	default:
+		return fmt.Errorf("leaderTeam (%q) must be %s, %s or %s", c.LeaderTeam, TeamBlue, TeamRed, TeamBoth)
+	}
+	if _, err := ParseLeaderPath(c.LeaderPath); err != nil {
+		return fmt.Errorf("invalid leaderPath: %w", err)
+	}
+	if c.LeaderWeight < 0 {
+		return fmt.Errorf("leaderWeight (%f) must be >= 0", c.LeaderWeight)
+	}
+	if c.FormationSize < 0 {
+		return fmt.Errorf("formationSize (%d) must be >= 0", c.FormationSize)
+	}
+	if c.FormationSpacing < 0 {
+		return fmt.Errorf("formationSpacing (%f) must be >= 0", c.FormationSpacing)
+	}
+	if c.FormationWeight < 0 {
+		return fmt.Errorf("formationWeight (%f) must be >= 0", c.FormationWeight)
+	}
+	if c.Engine == EngineECS && c.LeaderTeam != "" {
+		return fmt.Errorf("leaders are not supported by the %s engine", EngineECS)
+	}
+	switch c.CommandTeam {
+	case "", TeamBlue, TeamRed, TeamBoth:
+	default:
+		return fmt.Errorf("commandTeam (%q) must be %s, %s or %s", c.CommandTeam, TeamBlue, TeamRed, TeamBoth)
+	}
+	if c.CommandEvery < 0 || c.CommandWeight < 0 {
+		return fmt.Errorf("commandEvery (%d) and commandWeight (%f) cannot be negative", c.CommandEvery, c.CommandWeight)
+	}
+	if c.Engine == EngineECS && c.CommandTeam != "" {
+		return fmt.Errorf("commanders are not supported by the %s engine", EngineECS)
+	}
+	if c.PheromoneDiffusion < 0 || c.PheromoneDiffusion > 1 {
+		return fmt.Errorf("pheromoneDiffusion (%f) must be between 0 and 1", c.PheromoneDiffusion)
+	}
+	if c.PheromoneEvaporation < 0 || c.PheromoneEvaporation > 1 {
+		return fmt.Errorf("pheromoneEvaporation (%f) must be between 0 and 1", c.PheromoneEvaporation)
+	}
+	if c.PheromoneWeight < 0 {
+		return fmt.Errorf("pheromoneWeight (%f) must be >= 0", c.PheromoneWeight)
+	}
+	if c.Engine == EngineECS && c.Pheromones {
+		return fmt.Errorf("pheromones are not supported by the %s engine", EngineECS)
+	}
+	if c.FoodRate < 0 {
+		return fmt.Errorf("foodRate (%f) must be >= 0", c.FoodRate)
+	}
+	if c.FoodMax < 0 {
+		return fmt.Errorf("foodMax (%d) must be >= 0", c.FoodMax)
+	}
+	if c.FoodEnergy < 0 || c.FoodEnergy > 1 {
+		return fmt.Errorf("foodEnergy (%f) must be between 0 and 1", c.FoodEnergy)
+	}
+	if c.Engine == EngineECS && c.FoodRate > 0 {
+		return fmt.Errorf("food is not supported by the %s engine", EngineECS)
+	}
+	if c.PowerUpRate < 0 || c.PowerUpMax < 0 || c.PowerUpDuration < 0 {
+		return fmt.Errorf("powerUpRate (%f), powerUpMax (%d) and powerUpDuration (%d) cannot be negative",
+			c.PowerUpRate, c.PowerUpMax, c.PowerUpDuration)
+	}
+	if c.Engine == EngineECS && c.PowerUpRate > 0 {
+		return fmt.Errorf("power-ups are not supported by the %s engine", EngineECS)
+	}
+	if c.WindStrength < 0 || c.WindPeriod < 0 {
+		return fmt.Errorf("windStrength (%f) and windPeriod (%d) cannot be negative", c.WindStrength, c.WindPeriod)
+	}
+	switch c.WindMode {
+	case "", WindConstant, WindRotating, WindNoise:
+	default:
+		return fmt.Errorf("unknown windMode %q (use %s, %s or %s)", c.WindMode, WindConstant, WindRotating, WindNoise)
+	}
+	if c.Engine == EngineECS && c.WindStrength > 0 {
+		return fmt.Errorf("wind is not supported by the %s engine", EngineECS)
+	}
+	if c.ReproduceTicks < 0 || c.ReproduceMeals < 0 || c.MaxPopulation < 0 {
+		return fmt.Errorf("reproduceTicks (%d), reproduceMeals (%d) and maxPopulation (%d) cannot be negative",
+			c.ReproduceTicks, c.ReproduceMeals, c.MaxPopulation)
+	}
+	if c.Engine == EngineECS && (c.ReproduceTicks > 0 || c.ReproduceMeals > 0) {
+		return fmt.Errorf("reproduction is not supported by the %s engine", EngineECS)
+	}
+	if c.Lifespan < 0 {
+		return fmt.Errorf("lifespan (%d) must be >= 0", c.Lifespan)
+	}
+	if c.Engine == EngineECS && c.Lifespan > 0 {
+		return fmt.Errorf("lifespan is not supported by the %s engine", EngineECS)
+	}
+	if c.AttackCooldown < 0 || c.RedStamina < 0 {
+		return fmt.Errorf("attackCooldown (%d) and redStamina (%d) cannot be negative", c.AttackCooldown, c.RedStamina)
+	}
+	if c.Engine == EngineECS && (c.AttackCooldown > 0 || c.RedStamina > 0) {
+		return fmt.Errorf("attackCooldown and redStamina are not supported by the %s engine", EngineECS)
+	}
+	if c.ThumbnailEvery < 0 {
+		return fmt.Errorf("thumbnailEvery (%d) must be >= 0", c.ThumbnailEvery)
+	}
+	if c.CaptureEvery < 0 || c.CaptureSeconds < 0 {
+		return fmt.Errorf("captureEvery (%d) and captureSeconds (%f) cannot be negative", c.CaptureEvery, c.CaptureSeconds)
+	}
+	if c.SoundVolume < 0 || c.SoundVolume > 1 {
+		return fmt.Errorf("soundVolume (%f) must be between 0 and 1", c.SoundVolume)
+	}
+	if err := errors.Join(validateSoundFile("conversionSound", c.ConversionSound),
+		validateSoundFile("gameOverSound", c.GameOverSound), validateSoundFile("ambientSound", c.AmbientSound)); err != nil {
+		return err
+	}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		b, err := NewBehavior(c.StrategyFor(team))
+		if err != nil {
+			return fmt.Errorf("invalid strategy for %s: %w", team, err)
+		}
+		if script, ok := b.(*ScriptBehavior); ok && !script.Defines(team) {
+			return fmt.Errorf("invalid strategy for %s: the script has no %s function", team, scriptFunctions[team])
+		}
+		if c.Engine == EngineECS && !engine.Supports(c.StrategyFor(team)) {
+			return fmt.Errorf("strategy %q of %s is not supported by the %s engine", c.StrategyFor(team), team, EngineECS)
+		}
+	}
 	return nil
 }
 
@@ -153,3 +607,24 @@ func LoadConfig(configFile string, schemaFile string) (*Config, error) {
 
 	return &cfg, nil
 }
+
+// UITheme returns the preset named by Theme with the UIPadding and UIFontScale overrides
+func (c *Config) UITheme() (ui.Theme, error) {
+	theme, err := ui.ThemeByName(c.Theme)
+	if err != nil {
+		return ui.Theme{}, err
+	}
+	switch {
+	case c.UIPadding < 0:
+		return ui.Theme{}, fmt.Errorf("uiPadding (%f) cannot be negative", c.UIPadding)
+	case c.UIFontScale != 0 && (c.UIFontScale < 1 || c.UIFontScale > 4):
+		return ui.Theme{}, fmt.Errorf("uiFontScale (%f) must be between 1 and 4", c.UIFontScale)
+	}
+	if c.UIPadding > 0 {
+		theme.Padding = c.UIPadding
+	}
+	if c.UIFontScale > 0 {
+		theme.FontScale = c.UIFontScale
+	}
+	return theme, nil
+}
@@ -0,0 +1,177 @@
+package simulation
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ConfigOverrides holds the Config fields set on the command line, by JSON name.
+// Only the flags that were actually passed are recorded, so they override config.json
+// without resetting the other fields to the flag defaults.
+type ConfigOverrides struct {
+	values map[string]string
+}
+
+// RegisterConfigFlags adds one flag per Config field to 'fs'.
+// The flag name is the `flag` struct tag when present, the kebab-case JSON name otherwise
+// (maxSpeed -> -max-speed). Call ApplyOverrides after fs.Parse.
+func RegisterConfigFlags(fs *flag.FlagSet) *ConfigOverrides {
+	o := &ConfigOverrides{values: make(map[string]string)}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		fs.Var(&overrideValue{o: o, name: name, typ: field.Type}, flagName(field),
+			fmt.Sprintf("override %q of the config file (%s)", name, field.Type.Kind()))
+	}
+	return o
+}
+
+// Set records an override by JSON name, as if the matching flag had been passed
+func (o *ConfigOverrides) Set(name, value string) error {
+	field, ok := configField(name)
+	if !ok {
+		return fmt.Errorf("unknown config field %q", name)
+	}
+	if err := checkValue(field.Type, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	o.values[name] = value
+	return nil
+}
+
+// Names returns the JSON names of the overridden fields, sorted
+func (o *ConfigOverrides) Names() []string {
+	names := make([]string, 0, len(o.values))
+	for name := range o.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyOverrides merges the command-line values into c and validates the result.
+// A nil or empty ConfigOverrides leaves c unchanged.
+func (c *Config) ApplyOverrides(o *ConfigOverrides) error {
+	if o == nil {
+		return nil
+	}
+	v := reflect.ValueOf(c).Elem()
+	for _, name := range o.Names() {
+		field, _ := configField(name)
+		if err := setValue(v.FieldByIndex(field.Index), o.values[name]); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	if len(o.values) == 0 {
+		return nil
+	}
+	return c.Validate()
+}
+
+// overrideValue is the flag.Value of a Config field
+type overrideValue struct {
+	o    *ConfigOverrides
+	name string
+	typ  reflect.Type
+}
+
+func (v *overrideValue) String() string {
+	if v == nil || v.o == nil {
+		return ""
+	}
+	return v.o.values[v.name]
+}
+
+func (v *overrideValue) Set(s string) error {
+	if err := checkValue(v.typ, s); err != nil {
+		return err
+	}
+	v.o.values[v.name] = s
+	return nil
+}
+
+// IsBoolFlag allows -display-detection-circle without a value
+func (v *overrideValue) IsBoolFlag() bool {
+	return v.typ.Kind() == reflect.Bool
+}
+
+func configField(name string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func flagName(field reflect.StructField) string {
+	if name := field.Tag.Get("flag"); name != "" {
+		return name
+	}
+	var b strings.Builder
+	for _, r := range jsonName(field) {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// checkValue parses 's' as a value of type 't' without storing it
+func checkValue(t reflect.Type, s string) error {
+	return setValue(reflect.New(t).Elem(), s)
+}
+
+func setValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Int:
+		i, err := strconv.ParseInt(s, 10, 0)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Kind())
+	}
+	return nil
+}
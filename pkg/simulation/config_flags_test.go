@@ -0,0 +1,57 @@
+package simulation
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	overrides := RegisterConfigFlags(fs)
+	err := fs.Parse([]string{"--num-red", "12", "-world-width=640", "--max-speed", "9.5",
+		"-red-strategy", StrategyPackHunter, "-display-detection-circle", "-seed", "7"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	if err := cfg.ApplyOverrides(overrides); err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+	if cfg.NumRedAtStart != 12 || cfg.WorldWidth != 640 || cfg.MaxSpeed != 9.5 ||
+		cfg.RedStrategy != StrategyPackHunter || !cfg.DisplayDetectionCircle || cfg.Seed != 7 {
+		t.Errorf("Overrides not applied: %+v", cfg)
+	}
+	// Fields without a flag keep the values of the config file
+	if cfg.NumBlueAtStart != DefaultConfig().NumBlueAtStart {
+		t.Errorf("Expected numBlueAtStart to be untouched, got %d", cfg.NumBlueAtStart)
+	}
+}
+
+func TestApplyOverridesErrors(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterConfigFlags(fs)
+	if err := fs.Parse([]string{"--num-red", "many"}); err == nil {
+		t.Errorf("Expected a parse error for a non-integer count")
+	}
+
+	overrides := RegisterConfigFlags(flag.NewFlagSet("test", flag.ContinueOnError))
+	if err := overrides.Set("unknownField", "1"); err == nil {
+		t.Errorf("Expected an error for an unknown field")
+	}
+	// The merged config is validated
+	if err := overrides.Set("minSpeed", "100"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := DefaultConfig().ApplyOverrides(overrides); err == nil {
+		t.Errorf("Expected minSpeed >= maxSpeed to be rejected")
+	}
+
+	var none *ConfigOverrides
+	if err := DefaultConfig().ApplyOverrides(none); err != nil {
+		t.Errorf("Expected nil overrides to be a no-op, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package simulation
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// ConfigGroup identifies a set of related live parameters. The parameters of a group only make
+// sense together (e.g. the boids factors), so the world never applies a group partially:
+// UpdateConfig messages are staged and applied at the next tick boundary, all groups at once,
+// and the individuals read an immutable copy of the config for the whole tick.
+type ConfigGroup uint8
+
+const (
+	GroupRadii      ConfigGroup = 1 << iota // Detection, defense, contact, visual and protected ranges
+	GroupMotion                             // Max/min speed and aggression
+	GroupBoids                              // Centering, avoid, matching and turn factors
+	GroupPopulation                         // Initial populations, used on restart
+	GroupDisplay                            // Debug circles
+)
+
+var configGroupNames = []struct {
+	group ConfigGroup
+	name  string
+}{
+	{GroupRadii, "radii"},
+	{GroupMotion, "motion"},
+	{GroupBoids, "boids"},
+	{GroupPopulation, "population"},
+	{GroupDisplay, "display"},
+}
+
+// String lists the groups of the set, e.g. "radii+boids"
+func (g ConfigGroup) String() string {
+	var names []string
+	for _, n := range configGroupNames {
+		if g&n.group != 0 {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "+")
+}
+
+// changedGroups returns the groups whose parameters differ between 'a' and 'b'
+func changedGroups(a, b *Config) ConfigGroup {
+	var changed ConfigGroup
+	if a.DetectionRadius != b.DetectionRadius || a.DefenseRadius != b.DefenseRadius || a.ContactRadius != b.ContactRadius ||
+		a.VisualRange != b.VisualRange || a.ProtectedRange != b.ProtectedRange {
+		changed |= GroupRadii
+	}
+	if a.MaxSpeed != b.MaxSpeed || a.MinSpeed != b.MinSpeed || a.Aggression != b.Aggression {
+		changed |= GroupMotion
+	}
+	if a.CenteringFactor != b.CenteringFactor || a.AvoidFactor != b.AvoidFactor ||
+		a.MatchingFactor != b.MatchingFactor || a.TurnFactor != b.TurnFactor {
+		changed |= GroupBoids
+	}
+	if a.NumRedAtStart != b.NumRedAtStart || a.NumBlueAtStart != b.NumBlueAtStart ||
+		a.NumNeutralAtStart != b.NumNeutralAtStart {
+		changed |= GroupPopulation
+	}
+	if a.DisplayDetectionCircle != b.DisplayDetectionCircle || a.DisplayDefenseCircle != b.DisplayDefenseCircle {
+		changed |= GroupDisplay
+	}
+	return changed
+}
+
+// applyUpdate copies the parameters of an UpdateConfig message into 'cfg'
+func applyUpdate(cfg *Config, msg *pb.UpdateConfig) {
+	cfg.DetectionRadius = msg.GetDetectionRadius()
+	cfg.DefenseRadius = msg.GetDefenseRadius()
+	cfg.ContactRadius = msg.GetContactRadius()
+	cfg.VisualRange = msg.GetVisualRange()
+	cfg.ProtectedRange = msg.GetProtectedRange()
+
+	cfg.MaxSpeed = msg.GetMaxSpeed()
+	cfg.MinSpeed = msg.GetMinSpeed()
+	cfg.Aggression = msg.GetAggression()
+
+	cfg.CenteringFactor = msg.GetCenteringFactor()
+	cfg.AvoidFactor = msg.GetAvoidFactor()
+	cfg.MatchingFactor = msg.GetMatchingFactor()
+	cfg.TurnFactor = msg.GetTurnFactor()
+
+	// Note: Population parameters (NumRedAtStart, NumBlueAtStart)
+	// are stored but require a simulation restart to take effect
+	cfg.NumRedAtStart = int(msg.GetNumRedAtStart())
+	cfg.NumBlueAtStart = int(msg.GetNumBlueAtStart())
+
+	cfg.DisplayDetectionCircle = msg.GetDisplayDetectionCircle()
+	cfg.DisplayDefenseCircle = msg.GetDisplayDefenseCircle()
+}
+
+// applyPendingConfig applies the last UpdateConfig received since the previous tick
+func (w *world) applyPendingConfig() {
+	msg := w.pendingConfig
+	if msg == nil {
+		return
+	}
+	w.pendingConfig = nil
+	before := *w.cfg
+	applyUpdate(w.cfg, msg)
+	w.syncRadii()
+	if changed := changedGroups(&before, w.cfg); changed != 0 {
+		w.swarm.logger().Debugf("Config groups %s applied at tick %d", changed, w.tick)
+	}
+}
+
+// syncRadii copies the radii used by the spatial queries from the config
+func (w *world) syncRadii() {
+	w.detectionRadius = w.cfg.DetectionRadius
+	w.defenseRadius = w.cfg.DefenseRadius
+	w.visualRange = w.cfg.VisualRange
+}
+
+// liveConfig publishes the config of the world to its individuals. They may run concurrently
+// with the world (actor engine), so they never see the world's Config but an immutable copy,
+// replaced as a whole when the world config changed at a tick boundary.
+type liveConfig struct {
+	current atomic.Pointer[Config]
+	// leaders are the leaders of the teams and their targets, nil without LeaderTeam (see leader.go)
+	leaders atomic.Pointer[leaderState]
+	// formation is the slot of every blue in formation, nil when they fly free (see formation.go)
+	formation atomic.Pointer[formationState]
+	// pheromones are the fields the entities smell, nil without Pheromones (see pheromone.go)
+	pheromones atomic.Pointer[pheromoneState]
+	// food are the food items and what the blues ate, nil without FoodRate (see food.go)
+	food atomic.Pointer[foodState]
+	// powerUps are the effects of the pickups on the entities, nil without PowerUpRate (see powerup.go)
+	powerUps atomic.Pointer[powerUpState]
+	// wind is the force blowing on every entity, nil without WindStrength (see wind.go)
+	wind atomic.Pointer[geometry.Vector2D]
+	// attractors pull or push the entities near them, nil when there is none (see attractor.go)
+	attractors atomic.Pointer[[]Attractor]
+}
+
+func newLiveConfig(cfg *Config) *liveConfig {
+	l := &liveConfig{}
+	l.publish(cfg)
+	return l
+}
+
+// Load returns the current copy, it must not be modified
+func (l *liveConfig) Load() *Config {
+	return l.current.Load()
+}
+
+// publish stores a copy of 'cfg' if it differs from the current one
+func (l *liveConfig) publish(cfg *Config) {
+	if current := l.current.Load(); current != nil && *current == *cfg {
+		return
+	}
+	snapshot := *cfg
+	l.current.Store(&snapshot)
+}
@@ -0,0 +1,69 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestUpdateConfigAppliedAtTickBoundary(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart, cfg.NumBlueAtStart = 0, 0
+	caller := cfg
+	w := newWorld(make(chan *pb.WorldSnapshot, 1), cfg)
+	w.swarm = &logSwarm{}
+	cfg = w.cfg
+	seen := w.live.Load()
+
+	first := cfg.UpdateMessage()
+	first.AvoidFactor, first.MatchingFactor = 0.5, 0.5
+	second := cfg.UpdateMessage()
+	second.AvoidFactor, second.MatchingFactor = 0.1, 0.2
+	second.DetectionRadius = 80
+	w.handle(first)
+	w.handle(second)
+
+	// Nothing moves mid-tick, neither for the world nor for the individuals
+	if cfg.AvoidFactor != DefaultConfig().AvoidFactor || w.detectionRadius != DefaultConfig().DetectionRadius {
+		t.Fatalf("Expected the update to be staged until the next tick, got avoidFactor %f", cfg.AvoidFactor)
+	}
+	if w.live.Load() != seen {
+		t.Fatalf("Expected the individuals to keep their config until the next tick")
+	}
+
+	w.handle(&pb.Tick{})
+	// The last update wins as a whole, groups are never mixed
+	if cfg.AvoidFactor != 0.1 || cfg.MatchingFactor != 0.2 || w.detectionRadius != 80 {
+		t.Errorf("Expected the last update to be applied, got avoid %f, matching %f, detection %f",
+			cfg.AvoidFactor, cfg.MatchingFactor, w.detectionRadius)
+	}
+	live := w.live.Load()
+	if live == seen || *live != *cfg {
+		t.Errorf("Expected the individuals to get a new copy of the config")
+	}
+	if live == cfg {
+		t.Errorf("Expected the individuals to read a copy, not the world config")
+	}
+	if caller == cfg || caller.AvoidFactor != DefaultConfig().AvoidFactor {
+		t.Errorf("Expected the world to leave the config of the caller alone, got avoid %f", caller.AvoidFactor)
+	}
+
+	// An unchanged config is not copied again
+	w.handle(&pb.Tick{})
+	if w.live.Load() != live {
+		t.Errorf("Expected the same copy when nothing changed")
+	}
+}
+
+func TestChangedGroups(t *testing.T) {
+	a := DefaultConfig()
+	b := DefaultConfig()
+	if got := changedGroups(a, b); got != 0 || got.String() != "none" {
+		t.Errorf("Expected no change, got %s", got)
+	}
+	b.TurnFactor = 0.9
+	b.ContactRadius = 3
+	if got := changedGroups(a, b); got != GroupRadii|GroupBoids || got.String() != "radii+boids" {
+		t.Errorf("Expected radii+boids, got %s", got)
+	}
+}
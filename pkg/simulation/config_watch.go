@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// configReloadDelay groups the burst of events produced by a single save in a text editor
+const configReloadDelay = 200 * time.Millisecond
+
+// WatchConfig reloads 'configFile' every time it changes and calls onChange with the new, validated config.
+// Files that cannot be loaded (bad JSON, schema or validation error) are passed to onError and ignored.
+// The directory is watched rather than the file, so editors replacing the file on save are supported.
+// It blocks until ctx is done.
+func WatchConfig(ctx context.Context, configFile, schemaFile string, onChange func(cfg *Config), onError func(err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("cannot create config watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	target, err := filepath.Abs(configFile)
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("cannot watch %s: %w", configFile, err)
+	}
+
+	// The timer is armed on the first event and fires once the file is quiet
+	reload := time.NewTimer(configReloadDelay)
+	reload.Stop()
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if name, _ := filepath.Abs(ev.Name); name != target || !ev.Has(fsnotify.Write|fsnotify.Create) {
+				continue
+			}
+			reload.Reset(configReloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			onError(err)
+		case <-reload.C:
+			cfg, err := LoadConfig(configFile, schemaFile)
+			if err != nil {
+				onError(err)
+				continue
+			}
+			onChange(cfg)
+		}
+	}
+}
+
+// UpdateMessage returns the pb.UpdateConfig carrying the live parameters of the config
+func (c *Config) UpdateMessage() *pb.UpdateConfig {
+	return &pb.UpdateConfig{
+		DetectionRadius:        c.DetectionRadius,
+		DefenseRadius:          c.DefenseRadius,
+		ContactRadius:          c.ContactRadius,
+		VisualRange:            c.VisualRange,
+		ProtectedRange:         c.ProtectedRange,
+		MaxSpeed:               c.MaxSpeed,
+		MinSpeed:               c.MinSpeed,
+		Aggression:             c.Aggression,
+		CenteringFactor:        c.CenteringFactor,
+		AvoidFactor:            c.AvoidFactor,
+		MatchingFactor:         c.MatchingFactor,
+		TurnFactor:             c.TurnFactor,
+		NumRedAtStart:          int32(c.NumRedAtStart),
+		NumBlueAtStart:         int32(c.NumBlueAtStart),
+		DisplayDetectionCircle: c.DisplayDetectionCircle,
+		DisplayDefenseCircle:   c.DisplayDefenseCircle,
+	}
+}
@@ -0,0 +1,76 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("fsnotify is not available in WASM")
+	}
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	writeConfig := func(cfg *Config) {
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(configFile, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(DefaultConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Config, 4)
+	errs := make(chan error, 4)
+	go func() {
+		_ = WatchConfig(ctx, configFile, "../../config_schema.json",
+			func(cfg *Config) { changes <- cfg },
+			func(err error) { errs <- err })
+	}()
+	time.Sleep(100 * time.Millisecond) // Let the watcher start
+
+	edited := DefaultConfig()
+	edited.MaxSpeed = 7.5
+	writeConfig(edited)
+
+	select {
+	case cfg := <-changes:
+		if cfg.MaxSpeed != 7.5 {
+			t.Errorf("Expected reloaded MaxSpeed 7.5, got %f", cfg.MaxSpeed)
+		}
+	case err := <-errs:
+		t.Fatalf("Unexpected reload error: %v", err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Config change was not detected")
+	}
+
+	// An invalid file is reported and ignored
+	invalid := DefaultConfig()
+	invalid.MinSpeed = invalid.MaxSpeed + 1
+	writeConfig(invalid)
+	select {
+	case <-errs:
+	case cfg := <-changes:
+		t.Fatalf("Did not expect invalid config to be applied: %+v", cfg)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Invalid config change was not reported")
+	}
+}
+
+func TestConfig_UpdateMessage(t *testing.T) {
+	cfg := DefaultConfig()
+	msg := cfg.UpdateMessage()
+	if msg.GetMaxSpeed() != cfg.MaxSpeed || msg.GetDetectionRadius() != cfg.DetectionRadius ||
+		int(msg.GetNumBlueAtStart()) != cfg.NumBlueAtStart {
+		t.Errorf("UpdateMessage does not match config: %v", msg)
+	}
+}
@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// Names of the contact resolution policies selectable with Config.ContactPolicy
+const (
+	ContactSimultaneous = "simultaneous" // Every attacker of a victim fights it (default)
+	ContactStrongest    = "strongest"    // Only the attacker with the largest pack fights it
+)
+
+// defendersToRepel is the number of blues around a victim needed to convert the attacker instead,
+// unless Config.DefendersNeeded says otherwise
+const defendersToRepel = 3
+
+// contact is a red attacker touching a blue victim, found by the neighbor scan
+type contact struct {
+	attacker, victim *Entity
+}
+
+// spawnProtected reports whether 'e' can neither attack nor be attacked yet (see Config.SpawnProtectionTicks)
+func (w *world) spawnProtected(e *Entity) bool {
+	return e.protected(w.tick, w.cfg.SpawnProtectionTicks)
+}
+
+// resolveContacts applies the rules of engagement to the contacts of the tick once the scan is over,
+// so that the outcome no longer depends on the order in which the index visits the entities:
+// every contact is judged on the teams at the start of the tick, the victims by ID and
+// their attackers by ID, and an entity converts at most once per tick.
+//
+// The outcome of each victim is decided by the CombatRule of Config.CombatRule (see combat.go), the
+// entities it kills leave the world once all the victims are resolved.
+func (w *world) resolveContacts() {
+	contacts := w.contacts
+	if len(contacts) == 0 {
+		return
+	}
+	slices.SortFunc(contacts, func(a, b contact) int {
+		return cmp.Or(strings.Compare(a.victim.ID, b.victim.ID), strings.Compare(a.attacker.ID, b.attacker.ID))
+	})
+	clear(w.converted)
+	for start := 0; start < len(contacts); {
+		end := start + 1
+		for end < len(contacts) && contacts[end].victim == contacts[start].victim {
+			end++
+		}
+		w.resolveVictim(contacts[start:end])
+		start = end
+	}
+	clear(contacts)
+	w.contacts = contacts[:0]
+	for _, id := range w.killed {
+		w.despawn(id)
+	}
+	w.killed = w.killed[:0]
+}
+
+// resolveVictim resolves the contacts of one victim, sorted by attacker ID. The attackers cooling
+// down from an earlier attack (see Config.AttackCooldown) do not fight, the others start cooling down.
+func (w *world) resolveVictim(contacts []contact) {
+	victim := contacts[0].victim
+	contacts = slices.DeleteFunc(contacts, func(c contact) bool { return w.coolingDown(c.attacker) })
+	if len(contacts) == 0 {
+		return
+	}
+	if w.cfg.ContactPolicy == ContactStrongest {
+		strongest := w.strongestAttacker(contacts)
+		contacts = contacts[strongest : strongest+1]
+	}
+	f := &w.fight
+	f.w, f.Victim, f.Attackers = w, victim, f.Attackers[:0]
+	for _, c := range contacts {
+		c.attacker.attackTick = w.tick
+		f.Attackers = append(f.Attackers, c.attacker)
+	}
+	// With DefenseFacing, the defenders face the first attacker, the one credited on a failed defense
+	f.Defenders = w.defendersOf(victim, f.Attackers[0])
+	f.DefendersNeeded = w.cfg.defendersNeeded()
+	if w.combatRand == nil {
+		w.combatRand = w.entityRand("combat")
+	}
+	f.Rand = w.combatRand
+	w.combatRule().Resolve(f)
+	clear(f.Attackers)
+	f.Victim, f.Defenders = nil, nil
+}
+
+// combatRule returns the CombatRule of the world, created from Config.CombatRule on the first fight
+func (w *world) combatRule() CombatRule {
+	if w.combat == nil {
+		rule, err := NewCombatRule(w.cfg.CombatRule)
+		if err != nil {
+			// Validated with the config: an unknown rule only comes from a config never validated
+			w.swarm.logger().Errorf("Combat: %v, using %s", err, CombatDeterministic)
+			rule, _ = NewCombatRule(CombatDeterministic)
+		}
+		w.combat = rule
+	}
+	return w.combat
+}
+
+// strongestAttacker returns the index of the attacker with the most reds within the defense radius,
+// the first one (lowest ID) on a tie
+func (w *world) strongestAttacker(contacts []contact) int {
+	best, bestPack := 0, -1
+	for i, c := range contacts {
+		pack := w.countFriendsInRadius(c.attacker.Pos, w.defenseRadius, pb.TeamColor_TEAM_RED, c.attacker.ID)
+		if pack > bestPack {
+			best, bestPack = i, pack
+		}
+	}
+	return best
+}
+
+// convertOnce calls sendConvert unless 'targetID' was already converted during this tick
+func (w *world) convertOnce(targetID string, newColor pb.TeamColor, attackerID, victimID string) {
+	if w.converted[targetID] {
+		return
+	}
+	w.converted[targetID] = true
+	w.sendConvert(targetID, newColor, attackerID, victimID)
+}
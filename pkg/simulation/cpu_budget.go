@@ -0,0 +1,44 @@
+package simulation
+
+import "runtime"
+
+// cpuBudget bounds the number of goroutines running simulation work at once. With the actor
+// engine every individual is a goroutine: at high entity counts they occupy every P of the Go
+// scheduler and the render thread waits for a free one, so FPS collapses while TPS holds.
+// Go cannot pin goroutines to cores, but goroutines waiting for a token are parked and leave
+// their P to the renderer. A nil budget does not limit anything.
+type cpuBudget struct {
+	tokens chan struct{}
+}
+
+// newCPUBudget returns a budget of 'cores' concurrent workers, nil when cores is 0 (no limit)
+func newCPUBudget(cores int) *cpuBudget {
+	if cores <= 0 {
+		return nil
+	}
+	return &cpuBudget{tokens: make(chan struct{}, cores)}
+}
+
+// acquire waits for a free core
+func (b *cpuBudget) acquire() {
+	if b != nil {
+		b.tokens <- struct{}{}
+	}
+}
+
+// release gives back the core taken by acquire
+func (b *cpuBudget) release() {
+	if b != nil {
+		<-b.tokens
+	}
+}
+
+// ResolveSimCores turns Config.SimCores into a number of cores: 0 means no limit,
+// a negative value counts from the number of CPUs (-1 keeps one core for the renderer).
+// The result is never below 1 when a limit is requested.
+func ResolveSimCores(simCores int) int {
+	if simCores >= 0 {
+		return simCores
+	}
+	return max(1, runtime.NumCPU()+simCores)
+}
@@ -0,0 +1,59 @@
+package simulation
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolveSimCores(t *testing.T) {
+	if got := ResolveSimCores(0); got != 0 {
+		t.Errorf("Expected 0 (no limit), got %d", got)
+	}
+	if got := ResolveSimCores(3); got != 3 {
+		t.Errorf("Expected 3, got %d", got)
+	}
+	if got, want := ResolveSimCores(-1), max(1, runtime.NumCPU()-1); got != want {
+		t.Errorf("Expected %d, got %d", want, got)
+	}
+	if got := ResolveSimCores(-10000); got != 1 {
+		t.Errorf("Expected at least 1 core, got %d", got)
+	}
+}
+
+func TestCPUBudget_boundsConcurrency(t *testing.T) {
+	budget := newCPUBudget(2)
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			budget.acquire()
+			defer budget.release()
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if p := peak.Load(); p > 2 {
+		t.Errorf("Expected at most 2 concurrent workers, got %d", p)
+	}
+
+	// No budget, no limit
+	var none *cpuBudget
+	none.acquire()
+	none.release()
+	if newCPUBudget(0) != nil {
+		t.Errorf("Expected a nil budget for 0 cores")
+	}
+}
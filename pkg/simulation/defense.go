@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Names of the ways of counting the defenders selectable with Config.DefenseCount
+const (
+	DefenseCountRadius  = "radius"  // Every blue within DefenseRadius of the victim defends it (default)
+	DefenseCountNearest = "nearest" // Only the blues among the entities nearest to the victim defend it
+)
+
+// defenseCountNames lists the ways of counting the defenders, the default first
+var defenseCountNames = []string{DefenseCountRadius, DefenseCountNearest}
+
+const (
+	// defenseNeighbors is the number of nearest entities a victim looks at with DefenseCountNearest,
+	// the topological neighborhood of the starlings
+	defenseNeighbors = 7
+	// nearestReach is how far, in DefenseRadius, a victim looks for its nearest neighbors
+	nearestReach = 2.0
+)
+
+// defendersNeeded returns Config.DefendersNeeded, defendersToRepel when it is 0
+func (c *Config) defendersNeeded() int {
+	if c.DefendersNeeded > 0 {
+		return c.DefendersNeeded
+	}
+	return defendersToRepel
+}
+
+// neighbor is an entity found around a victim, with its squared distance to it
+type neighbor struct {
+	distSq float64
+	e      *Entity
+}
+
+// defenderScan collects the entities around a victim, except the victim, and the defenders among them
+type defenderScan struct {
+	victim  *Entity
+	found   []neighbor
+	list    []*Entity
+	visitFn func(pos geometry.Vector2D, e *Entity) bool
+}
+
+func (s *defenderScan) visit(pos geometry.Vector2D, e *Entity) bool {
+	if e != s.victim {
+		s.found = append(s.found, neighbor{distSq: pos.DistanceSquaredTo(s.victim.Pos), e: e})
+	}
+	return true
+}
+
+// defendersOf returns the blues defending 'victim' from 'attacker', selected by Config.DefenseCount
+// and Config.DefenseFacing, nearest first. The slice is reused by the next call.
+func (w *world) defendersOf(victim, attacker *Entity) []*Entity {
+	s := &w.defenders
+	s.victim, s.found, s.list = victim, s.found[:0], s.list[:0]
+	nearest := w.cfg.DefenseCount == DefenseCountNearest
+	radius := w.defenseRadius
+	boosted := !nearest && w.cfg.PowerUpRate > 0
+	switch {
+	case nearest:
+		radius *= nearestReach
+	case boosted:
+		// The blues under PowerUpDefense defend farther, the others are filtered below
+		radius *= defenseBoost
+	}
+	w.index.Query(victim.Pos, radius, s.visitFn)
+	// Sorted by ID on a tie, so that the defenders do not depend on the index
+	slices.SortFunc(s.found, func(a, b neighbor) int {
+		return cmp.Or(cmp.Compare(a.distSq, b.distSq), cmp.Compare(a.e.ID, b.e.ID))
+	})
+	found := s.found
+	if nearest {
+		found = found[:min(len(found), defenseNeighbors)]
+	}
+	for _, n := range found {
+		if boosted && n.distSq > w.defenseReach(n.e)*w.defenseReach(n.e) {
+			continue
+		}
+		if n.e.Color == pb.TeamColor_TEAM_BLUE && (!w.cfg.DefenseFacing || facing(n.e, attacker)) {
+			s.list = append(s.list, n.e)
+		}
+	}
+	clear(s.found)
+	s.victim = nil
+	return s.list
+}
+
+// facing reports whether the defender 'e' heads toward 'target'
+func facing(e, target *Entity) bool {
+	return e.Vel.Dot(target.Pos.Sub(e.Pos)) > 0
+}
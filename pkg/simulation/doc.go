@@ -0,0 +1,43 @@
+// Package simulation runs the "Red Virus vs Blue Flock" swarm: every entity is a GoAkt actor
+// and a world actor owns the authoritative state, the spatial grid and the combat rules.
+//
+// # Stable API
+//
+// The following identifiers follow semantic versioning: they will not change in an
+// incompatible way before the next major version.
+//
+//   - Config, DefaultConfig, LoadConfig and the Config methods, ConfigGroup, SimTicksPerSecond, SimTime, TickDuration
+//   - Runner, NewRunner, RunnerOption, WithActorSystem, WithEngine, WithWarmup, WithWorldOptions, WithRunID, NewRunID
+//   - Engine, EngineFactory, ActorEngine, NewLocalEngine, NewECSEngine, SelectEngine, Logger, SimClock, StartSimClock
+//   - WorldOption, WithTickHook, WithTickTiming, TickHook, WorldView, CommandQueue, NewCommandQueue,
+//     WithCommandQueue, PoolStats
+//   - Event, EventKind, EventSink, EventSinkFunc, WithEventSink, EventLog, NewEventLog, CreateEventLog, EventFeed, NewEventFeed
+//   - SnapshotHub, NewSnapshotHub, WithSnapshotHub, SnapshotPool, NewSnapshotPool, WithSnapshotPool,
+//     SnapshotView, NewSnapshotView, WithSnapshotView
+//   - Behavior, BehaviorFactory, BehaviorResolver, RegisterBehavior, RegisterBehaviorResolver,
+//     NewBehavior, BehaviorNames, DefaultStrategy and the built-in behaviors
+//   - Entity and its steering helpers (ComputeBoidUpdate, FromProto, GeomVector2DFromProto)
+//   - the messages of package pb and the geometry package
+//
+// Everything else exported here (Game, Inspector, ObserverServer, LiveView...) belongs to the
+// bundled front-ends of cmd/simulation and may change in any release.
+// The actors themselves are internal: drive a simulation through a Runner (headless) or a Game (Ebiten).
+//
+// # Engines
+//
+// The default engine runs every entity as a GoAkt actor. GoAkt does not build for WASM,
+// so browser builds (GOOS=js) use the local engine, which runs the same world and
+// individuals sequentially in a single goroutine. With a non-zero Config.Seed the local
+// engine is deterministic: the same seed renders the same frames, which DrawWorld and a
+// HashRenderer turn into cheap end-to-end regression tests.
+//
+// For very large populations (50k+), Config.Engine "ecs" selects NewECSEngine: the rules
+// of the built-in strategies run as a struct-of-arrays loop (package engine), without
+// actors, messages or tick hooks. The Game and the Runner drive every engine the same way.
+//
+// # Agents
+//
+// An AgentPolicy steers the entities of a team from their Observation instead of the built-in
+// rules: RegisterPolicy makes it a strategy. To train one, an AgentEnv plays gym-style episodes
+// (Reset, then Step with the actions of the team) against the scripted behaviors of the other team.
+package simulation
@@ -0,0 +1,73 @@
+package simulation
+
+import (
+	"image/color"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/fx"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// effectQueueSize bounds the conversions waiting for their burst, past it the oldest are skipped
+const effectQueueSize = 512
+
+// Colors of the conversion bursts, by the team the entity joined
+var (
+	redBurstColor  = color.RGBA{R: 255, G: 120, B: 40, A: 255}
+	blueBurstColor = color.RGBA{R: 80, G: 200, B: 255, A: 255}
+)
+
+// Effects shows a burst of particles where an entity switches team: the velocity flip of a
+// converted entity is hard to notice in a dense flock. Register Sink on the world, then call
+// Update once per frame and Draw after the world layer.
+type Effects struct {
+	// Sink receives the conversions from the world goroutine
+	Sink      *EventQueue
+	particles *fx.System
+	pending   []Event
+}
+
+// NewEffects creates the effects of a Game, without particle
+func NewEffects() *Effects {
+	return &Effects{
+		Sink:      NewEventQueue(effectQueueSize, EventConversion),
+		particles: fx.NewSystem(fx.DefaultMaxParticles),
+	}
+}
+
+// Update starts the bursts of the conversions received since the last call, unless 'enabled'
+// is false, and moves the particles by 'dt' seconds
+func (ef *Effects) Update(dt float64, enabled bool) {
+	ef.pending = ef.Sink.Drain(ef.pending[:0])
+	if enabled {
+		for _, e := range ef.pending {
+			clr := blueBurstColor
+			if e.Color == teamLabel(pb.TeamColor_TEAM_RED) {
+				clr = redBurstColor
+			}
+			ef.particles.Burst(geometry.Vector2D{X: e.X, Y: e.Y}, clr, fx.ConversionBurst)
+		}
+	}
+	ef.particles.Update(dt)
+}
+
+// Draw emits the particles, fading with their age
+func (ef *Effects) Draw(r Renderer) {
+	for _, p := range ef.particles.Particles() {
+		a := p.Alpha()
+		// color.RGBA is alpha-premultiplied
+		clr := color.RGBA{R: uint8(float64(p.Color.R) * a), G: uint8(float64(p.Color.G) * a), B: uint8(float64(p.Color.B) * a), A: uint8(float64(p.Color.A) * a)}
+		r.FillCircle(float32(p.Pos.X), float32(p.Pos.Y), float32(p.Size), clr)
+	}
+}
+
+// Len returns the number of live particles
+func (ef *Effects) Len() int {
+	return ef.particles.Len()
+}
+
+// Reset removes the particles and the conversions not shown yet, e.g. on restart
+func (ef *Effects) Reset() {
+	ef.Sink.Drain(nil)
+	ef.particles.Reset()
+}
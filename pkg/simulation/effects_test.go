@@ -0,0 +1,50 @@
+package simulation
+
+import (
+	"image/color"
+	"testing"
+)
+
+// circleRenderer records the filled circles
+type circleRenderer struct {
+	HashRenderer
+	fills []color.RGBA
+}
+
+func (r *circleRenderer) FillCircle(x, y, radius float32, clr color.RGBA) {
+	r.fills = append(r.fills, clr)
+}
+
+func TestEffects(t *testing.T) {
+	effects := NewEffects()
+	effects.Sink.HandleEvent(Event{Kind: EventSpawn, X: 10, Y: 10})
+	effects.Sink.HandleEvent(Event{Kind: EventConversion, ID: "Blue-000", Color: "RED", X: 100, Y: 100})
+
+	effects.Update(0, false)
+	if effects.Len() != 0 {
+		t.Fatalf("Expected no burst when the effects are disabled, got %d particles", effects.Len())
+	}
+	effects.Sink.HandleEvent(Event{Kind: EventConversion, ID: "Blue-000", Color: "RED", X: 100, Y: 100})
+	effects.Update(0.1, true)
+	if effects.Len() == 0 {
+		t.Fatalf("Expected a burst for the conversion")
+	}
+
+	r := &circleRenderer{}
+	effects.Draw(r)
+	if len(r.fills) != effects.Len() {
+		t.Fatalf("Expected one circle per particle, got %d for %d", len(r.fills), effects.Len())
+	}
+	for _, clr := range r.fills {
+		if clr.A == 0 || clr.A == 255 || clr.R > clr.A {
+			t.Errorf("Expected a fading premultiplied color, got %v", clr)
+		}
+	}
+
+	effects.Sink.HandleEvent(Event{Kind: EventConversion, Color: "BLUE"})
+	effects.Reset()
+	effects.Update(0, true)
+	if effects.Len() != 0 {
+		t.Errorf("Expected Reset to drop the particles and the pending conversions")
+	}
+}
@@ -0,0 +1,38 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrEngineStopped is returned when a message is sent to a stopped engine
+var ErrEngineStopped = errors.New("simulation: engine stopped")
+
+// ErrUnknownEntity is returned by Engine.State for an id that is not part of the world
+var ErrUnknownEntity = errors.New("simulation: unknown entity")
+
+// Engine runs one world and publishes its snapshots on the channel given to its EngineFactory.
+// The Game and the Runner only talk to the simulation through this interface.
+type Engine interface {
+	// Send delivers a control message (pb.Tick, pb.UpdateConfig, pb.SetStrategy) to the world
+	Send(ctx context.Context, msg proto.Message) error
+	// State returns the live state of one entity, the deadline of ctx bounds the wait
+	State(ctx context.Context, id string) (*pb.ActorState, error)
+	Logger() Logger
+	// Stop shuts the world down, the engine cannot be restarted
+	Stop(ctx context.Context) error
+}
+
+// EngineFactory creates a world, spawns its population and returns the Engine driving it
+type EngineFactory func(ctx context.Context, snapshotCh chan<- *pb.WorldSnapshot, cfg *Config, opts ...WorldOption) (Engine, error)
+
+// Logger is the subset of the GoAkt logger used by the simulation
+type Logger interface {
+	Debugf(format string, args ...any)
+	Info(args ...any)
+	Infof(format string, args ...any)
+	Errorf(format string, args ...any)
+}
@@ -0,0 +1,83 @@
+//go:build !js
+
+package simulation
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/tochemey/goakt/v3/actor"
+	"google.golang.org/protobuf/proto"
+)
+
+// actorStateTimeout bounds Engine.State when the context has no deadline
+const actorStateTimeout = time.Second
+
+// actorEngine is the default engine: the world and every individual are GoAkt actors
+type actorEngine struct {
+	system actor.ActorSystem
+	pid    *actor.PID
+	// inFlight counts the ticks sent to the world and not finished yet (see asyncEngine)
+	inFlight *atomic.Int32
+}
+
+var _ asyncEngine = (*actorEngine)(nil)
+
+// ActorEngine returns the factory of the default engine, spawning the world in 'system' (already started)
+func ActorEngine(system actor.ActorSystem) EngineFactory {
+	return func(ctx context.Context, snapshotCh chan<- *pb.WorldSnapshot, cfg *Config, opts ...WorldOption) (Engine, error) {
+		world := newWorldActor(newWorld(snapshotCh, cfg, opts...), newCPUBudget(ResolveSimCores(cfg.SimCores)))
+		pid, err := system.Spawn(ctx, "world", world)
+		if err != nil {
+			return nil, err
+		}
+		return &actorEngine{system: system, pid: pid, inFlight: &world.ticksInFlight}, nil
+	}
+}
+
+func (e *actorEngine) Send(ctx context.Context, msg proto.Message) error {
+	_, tick := msg.(*pb.Tick)
+	if tick {
+		e.inFlight.Add(1)
+	}
+	err := actor.Tell(ctx, e.pid, msg)
+	if err != nil && tick {
+		e.inFlight.Add(-1)
+	}
+	return err
+}
+
+// ticksInFlight returns the ticks Send told the world which did not publish their snapshot yet
+func (e *actorEngine) ticksInFlight() int {
+	return int(e.inFlight.Load())
+}
+
+func (e *actorEngine) State(ctx context.Context, id string) (*pb.ActorState, error) {
+	pid, err := e.system.LocalActor(id)
+	if err != nil || pid == nil {
+		return nil, ErrUnknownEntity
+	}
+	timeout := actorStateTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	resp, err := actor.Ask(ctx, pid, &pb.GetState{}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := resp.(*pb.ActorState)
+	if !ok {
+		return nil, ErrUnknownEntity
+	}
+	return state, nil
+}
+
+func (e *actorEngine) Logger() Logger {
+	return e.system.Logger()
+}
+
+func (e *actorEngine) Stop(ctx context.Context) error {
+	return e.pid.Shutdown(ctx)
+}
@@ -0,0 +1,120 @@
+//go:build !js
+
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"github.com/tochemey/goakt/v3/actor"
+	"github.com/tochemey/goakt/v3/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// nopActor ignores every message
+type nopActor struct{}
+
+func (*nopActor) PreStart(*actor.Context) error { return nil }
+func (*nopActor) Receive(*actor.ReceiveContext) {}
+func (*nopActor) PostStop(*actor.Context) error { return nil }
+
+// lookupSwarm is the world actor without its pidsCache: tell resolves the PID of every individual
+// by name in the actor system, the lookup the cache replaced
+type lookupSwarm struct {
+	*worldActor
+}
+
+func (l lookupSwarm) tell(id string, msg proto.Message) bool {
+	pid, err := l.ctx.ActorSystem().LocalActor(id)
+	if err != nil || pid == nil {
+		return false
+	}
+	l.ctx.Tell(pid, msg)
+	return true
+}
+
+// contactsBench resolves the same contacts of a world actor at every Tick, inside an actor as during
+// a step of the world: the converts go through worldActor.tell
+type contactsBench struct {
+	a        *worldActor
+	contacts []contact
+	done     chan struct{}
+}
+
+func (*contactsBench) PreStart(*actor.Context) error { return nil }
+func (*contactsBench) PostStop(*actor.Context) error { return nil }
+
+func (c *contactsBench) Receive(ctx *actor.ReceiveContext) {
+	if _, ok := ctx.Message().(*pb.Tick); !ok {
+		return
+	}
+	c.a.ctx = ctx
+	c.a.w.contacts = append(c.a.w.contacts, c.contacts...)
+	c.a.w.resolveContacts()
+	c.done <- struct{}{}
+}
+
+// BenchmarkWorldActor_resolveContacts resolves a tick of contacts between undefended pairs of a red
+// and a blue: every victim converts through sendConvert. worldActor.tell finds the PID of the
+// individual in pidsCache, as on every conversion of the actor engine (pidsCache), compared with
+// a lookup by name in the actor system (LocalActor).
+func BenchmarkWorldActor_resolveContacts(b *testing.B) {
+	const pairs = 1000
+	ctx := context.Background()
+	system, err := actor.NewActorSystem("bench", actor.WithLogger(log.DiscardLogger))
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := system.Start(ctx); err != nil {
+		b.Fatal(err)
+	}
+	defer system.Stop(ctx)
+
+	w := newWorld(nil, combatConfig())
+	a := newWorldActor(w, nil)
+	contacts := make([]contact, 0, pairs)
+	for i := range pairs {
+		// Far enough from each other for no blue to defend another pair
+		red := &Entity{ID: fmt.Sprintf("Red-%03d", i), Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: float64(i) * 100, Y: 100}}
+		blue := &Entity{ID: fmt.Sprintf("Blue-%03d", i), Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: float64(i)*100 + 5, Y: 100}}
+		for _, e := range []*Entity{red, blue} {
+			w.addEntity(e)
+			pid, err := system.Spawn(ctx, e.ID, &nopActor{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			a.pidsCache[e.ID] = pid
+		}
+		contacts = append(contacts, contact{attacker: red, victim: blue})
+	}
+	w.rebuildGrid()
+	bench := &contactsBench{a: a, contacts: contacts, done: make(chan struct{})}
+	pid, err := system.Spawn(ctx, "world", bench)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, lookup := range []bool{false, true} {
+		name := "pidsCache"
+		w.swarm = a
+		if lookup {
+			name = "LocalActor"
+			w.swarm = lookupSwarm{a}
+		}
+		b.Run(name, func(b *testing.B) {
+			w.tickConversions = 0
+			for b.Loop() {
+				if err := actor.Tell(ctx, pid, &pb.Tick{}); err != nil {
+					b.Fatal(err)
+				}
+				<-bench.done
+			}
+			if w.tickConversions == 0 {
+				b.Fatal("Expected the victims converted")
+			}
+		})
+	}
+}
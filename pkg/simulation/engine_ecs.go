@@ -0,0 +1,307 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/engine"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"google.golang.org/protobuf/proto"
+)
+
+// Names of the engines selectable with Config.Engine
+const (
+	EngineActor = "actor" // Default: one GoAkt actor per individual
+	EngineLocal = "local" // Same world and individuals in a single goroutine
+	EngineECS   = "ecs"   // Struct-of-arrays loop of pkg/engine, for very large populations
+)
+
+// ecsEngine drives an engine.Swarm: no world, no individual, only the built-in strategies.
+// Tick hooks are not supported, the snapshot hub, view and pool are.
+type ecsEngine struct {
+	mu         sync.Mutex
+	swarm      *engine.Swarm
+	ids        []string
+	index      map[string]int
+	cfg        *Config
+	pending    *pb.UpdateConfig
+	snapshotCh chan<- *pb.WorldSnapshot
+	hub        *SnapshotHub
+	view       *SnapshotView
+	snapshots  *SnapshotPool
+	viewport   *pb.SetViewport
+	log        Logger
+	tick       uint64
+	stopped    bool
+	// conversions and wall time (see WithTickTiming) of the last tick, reported in its snapshots
+	tickConversions uint32
+	timeTicks       bool
+	tickDuration    time.Duration
+	// events only reports spawns, conversions (without their contact) and the game over
+	events   eventBus
+	gameOver bool
+	// memory receives the size of the arrays, nil unless WithMemoryUsage
+	memory *MemoryUsage
+	// --- Benchmark Stats ---
+	conversions int
+	lastLogTick uint64
+	lastLogTime time.Time
+}
+
+// NewECSEngine is the EngineFactory of the struct-of-arrays engine (see pkg/engine).
+// It places the population like the other engines, but runs a single random stream.
+func NewECSEngine(_ context.Context, snapshotCh chan<- *pb.WorldSnapshot, cfg *Config, opts ...WorldOption) (Engine, error) {
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if name := cfg.StrategyFor(team); !engine.Supports(name) {
+			return nil, fmt.Errorf("%s engine cannot run strategy %q of %s", EngineECS, name, team)
+		}
+	}
+	// The options only configure a world: keep what the engine can honor
+	options := newWorld(nil, cfg, opts...)
+	seed := resolveSeed(cfg.Seed)
+	// The updates change a copy: the caller (e.g. the Game) keeps reading 'cfg' meanwhile
+	own := *cfg
+	e := &ecsEngine{
+		swarm:       engine.New(entityRand(seed, "ecs")),
+		index:       make(map[string]int),
+		cfg:         &own,
+		snapshotCh:  snapshotCh,
+		hub:         options.hub,
+		view:        options.view,
+		timeTicks:   options.timeTicks,
+		events:      options.events,
+		memory:      options.memory,
+		snapshots:   options.snapshotPool(),
+		log:         newStdLogger(cfg.LogLevel),
+		lastLogTime: time.Now(),
+	}
+	if len(options.tickHooks) > 0 {
+		e.log.Errorf("Tick hooks are ignored by the %s engine", EngineECS)
+	}
+	if len(options.external) > 0 {
+		e.log.Errorf("Command queues are ignored by the %s engine", EngineECS)
+	}
+	if options.attractors.control != nil {
+		e.log.Errorf("Attractors are ignored by the %s engine", EngineECS)
+	}
+	if options.scare.control != nil {
+		e.log.Errorf("Scares are ignored by the %s engine", EngineECS)
+	}
+
+	var numRed, numBlue int
+	spawnLayout(cfg, seed, func(color pb.TeamColor, pos, vel geometry.Vector2D) {
+		id := fmt.Sprintf("Blue-%03d", numBlue)
+		if color == pb.TeamColor_TEAM_RED {
+			id = fmt.Sprintf("Red-%03d", numRed)
+			numRed++
+		} else {
+			numBlue++
+		}
+		e.index[id] = e.swarm.Add(color, pos.X, pos.Y, vel.X, vel.Y)
+		e.ids = append(e.ids, id)
+		if e.events.enabled() {
+			e.events.publish(Event{Kind: EventSpawn, ID: id, Color: teamLabel(color), X: pos.X, Y: pos.Y})
+		}
+	})
+	e.log.Infof("ECS engine started with %d entities", e.swarm.Len())
+	return e, nil
+}
+
+func (e *ecsEngine) Send(_ context.Context, msg proto.Message) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		return ErrEngineStopped
+	}
+	switch msg := msg.(type) {
+	case *pb.Tick:
+		e.step(msg)
+	case *pb.UpdateConfig:
+		// Staged until the next tick boundary, like the world does
+		e.pending = msg
+	case *pb.SetStrategy:
+		if !engine.Supports(msg.GetName()) {
+			e.log.Errorf("Cannot switch %s strategy: %q is not supported by the %s engine", msg.GetTeam(), msg.GetName(), EngineECS)
+			return nil
+		}
+		if msg.GetTeam() == pb.TeamColor_TEAM_RED {
+			e.cfg.RedStrategy = msg.GetName()
+		} else {
+			e.cfg.BlueStrategy = msg.GetName()
+		}
+		e.log.Infof("Team %s now uses strategy %s", msg.GetTeam(), msg.GetName())
+	case *pb.SetViewport:
+		e.viewport = viewportOf(msg)
+	}
+	return nil
+}
+
+// step runs one tick and publishes its snapshot
+func (e *ecsEngine) step(msg *pb.Tick) {
+	start := time.Now()
+	e.tick++
+	if e.pending != nil {
+		applyUpdate(e.cfg, e.pending)
+		e.pending = nil
+	}
+	params := e.params()
+	params.TimeStep = tickScale(msg.GetDeltaTime())
+	// Every entity spawned with the world, at tick 0 (see Config.SpawnProtectionTicks)
+	params.Truce = e.tick <= uint64(e.cfg.SpawnProtectionTicks)
+	conversions, err := e.swarm.Step(&params)
+	if err != nil {
+		e.log.Errorf("Tick %d: %v", e.tick, err)
+		return
+	}
+	e.conversions += len(conversions)
+	e.tickConversions = uint32(len(conversions))
+	if e.events.enabled() {
+		for _, c := range conversions {
+			e.events.publish(Event{Kind: EventConversion, Tick: e.tick, ID: e.ids[c.Index], Color: teamLabel(c.To),
+				X: e.swarm.PosX[c.Index], Y: e.swarm.PosY[c.Index]})
+		}
+	}
+	if e.timeTicks {
+		e.tickDuration = time.Since(start)
+	}
+
+	snapshot := e.buildSnapshot(e.viewport)
+	if snapshot.IsGameOver && !e.gameOver {
+		e.gameOver = true
+		e.events.publish(gameOverEvent(snapshot))
+	}
+	select {
+	case e.snapshotCh <- snapshot:
+	default:
+		// UI busy, skip frame
+		e.snapshots.Put(snapshot)
+	}
+	if e.hub != nil || e.view != nil {
+		if e.viewport != nil {
+			snapshot = e.buildSnapshot(nil)
+		}
+		if e.hub != nil {
+			e.hub.Publish(snapshot)
+		}
+		if e.view != nil {
+			e.view.publish(snapshot)
+		}
+	}
+	e.logBenchmarks()
+	if e.memory != nil && e.tick%memoryReportTicks == 0 {
+		e.reportMemory()
+	}
+}
+
+// reportMemory records the memory of the arrays of the swarm, its grid included, and of the IDs
+func (e *ecsEngine) reportMemory() {
+	ids := int64(len(e.ids)) * (int64(unsafe.Sizeof(""))*2 + 8 + mapEntryOverhead) // ids and index
+	for _, id := range e.ids {
+		ids += int64(len(id))
+	}
+	e.memory.Set(MemoryEntities, e.swarm.MemoryBytes()+ids)
+	e.memory.Set(MemoryGrid, 0)
+}
+
+func (e *ecsEngine) params() engine.Params {
+	c := e.cfg
+	return engine.Params{
+		WorldWidth:      c.WorldWidth,
+		WorldHeight:     c.WorldHeight,
+		DetectionRadius: c.DetectionRadius,
+		DefenseRadius:   c.DefenseRadius,
+		ContactRadius:   c.ContactRadius,
+		VisualRange:     c.VisualRange,
+		ProtectedRange:  c.ProtectedRange,
+		MaxSpeed:        c.MaxSpeed,
+		MinSpeed:        c.MinSpeed,
+		Aggression:      c.Aggression,
+		CenteringFactor: c.CenteringFactor,
+		AvoidFactor:     c.AvoidFactor,
+		MatchingFactor:  c.MatchingFactor,
+		TurnFactor:      c.TurnFactor,
+		RedStrategy:     c.StrategyFor(pb.TeamColor_TEAM_RED),
+		BlueStrategy:    c.StrategyFor(pb.TeamColor_TEAM_BLUE),
+		ContactPolicy:   c.ContactPolicy,
+		DefendersNeeded: c.DefendersNeeded,
+		Integrator:      c.Integrator,
+	}
+}
+
+func (e *ecsEngine) buildSnapshot(view *pb.SetViewport) *pb.WorldSnapshot {
+	s := e.swarm
+	snapshot := e.snapshots.get(s.Len())
+	snapshot.Tick = e.tick
+	snapshot.Conversions = e.tickConversions
+	snapshot.TickDuration = int64(e.tickDuration)
+	for i := 0; i < s.Len(); i++ {
+		if countEntity(snapshot, view, s.Color[i], s.PosX[i], s.PosY[i]) {
+			e.fillState(nextActor(snapshot), i)
+		}
+	}
+	setGameOver(snapshot)
+	return snapshot
+}
+
+func (e *ecsEngine) fillState(state *pb.ActorState, i int) {
+	s := e.swarm
+	// The engine has no hunger nor fatigue: zeroed, a recycled message may carry those of the world
+	fillActorState(state, e.ids[i], s.Color[i], s.PosX[i], s.PosY[i], s.VelX[i], s.VelY[i], 0, 0, 0)
+}
+
+// logBenchmarks reports the speed of the engine every benchmarkIntervalTicks
+func (e *ecsEngine) logBenchmarks() {
+	ticks := e.tick - e.lastLogTick
+	if ticks < benchmarkIntervalTicks {
+		return
+	}
+	simTime := SimTime(ticks)
+	wallTime := time.Since(e.lastLogTime)
+	red, blue := e.swarm.Count()
+	e.log.Infof("📊 ECS: Ticks %d-%d: %s sim in %s wall (x%.2f) | Red %d, Blue %d | Conversions: %d",
+		e.lastLogTick+1, e.tick, simTime, wallTime.Round(time.Millisecond), simTime.Seconds()/wallTime.Seconds(),
+		red, blue, e.conversions)
+	e.conversions = 0
+	e.lastLogTick = e.tick
+	e.lastLogTime = time.Now()
+}
+
+func (e *ecsEngine) State(_ context.Context, id string) (*pb.ActorState, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i, ok := e.index[id]
+	if !ok {
+		return nil, ErrUnknownEntity
+	}
+	state := &pb.ActorState{}
+	e.fillState(state, i)
+	return state, nil
+}
+
+func (e *ecsEngine) Logger() Logger {
+	return e.log
+}
+
+func (e *ecsEngine) Stop(_ context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = true
+	e.log.Info("World is shutdown...")
+	return nil
+}
+
+// SelectEngine returns the factory of the engine named by cfg.Engine,
+// or 'fallback' for the actor engine (which needs an actor system, see ActorEngine)
+func SelectEngine(cfg *Config, fallback EngineFactory) EngineFactory {
+	switch cfg.Engine {
+	case EngineLocal:
+		return NewLocalEngine
+	case EngineECS:
+		return NewECSEngine
+	}
+	return fallback
+}
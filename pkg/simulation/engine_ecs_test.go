@@ -0,0 +1,115 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/engine"
+)
+
+func TestECSEngine(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 2
+	cfg.NumBlueAtStart = 3
+	cfg.Seed = 42
+	snapshotCh := make(chan *pb.WorldSnapshot, 1)
+
+	engine, err := NewECSEngine(ctx, snapshotCh, cfg)
+	if err != nil {
+		t.Fatalf("NewECSEngine failed: %v", err)
+	}
+	before, err := engine.State(ctx, "Red-000")
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+
+	// Same starting positions as the world with the same seed
+	local, _ := NewLocalEngine(ctx, make(chan *pb.WorldSnapshot, 1), cfg)
+	if want, _ := local.State(ctx, "Red-000"); want.GetPosition().GetX() != before.GetPosition().GetX() {
+		t.Errorf("Expected Red-000 to start at %v, got %v", want.GetPosition(), before.GetPosition())
+	}
+
+	if err := engine.Send(ctx, &pb.Tick{}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	snap := <-snapshotCh
+	if snap.GetTick() != 1 || len(snap.GetActors()) != 5 || snap.GetRedCount() != 2 {
+		t.Errorf("Expected a snapshot of tick 1 with 2 of 5 actors red, got tick %d with %d/%d", snap.GetTick(), snap.GetRedCount(), len(snap.GetActors()))
+	}
+	after, _ := engine.State(ctx, "Red-000")
+	if after.GetPosition().GetX() == before.GetPosition().GetX() && after.GetPosition().GetY() == before.GetPosition().GetY() {
+		t.Errorf("Expected Red-000 to move during the tick, still at %v", after.GetPosition())
+	}
+	if _, err := engine.State(ctx, "nobody"); !errors.Is(err, ErrUnknownEntity) {
+		t.Errorf("Expected ErrUnknownEntity, got %v", err)
+	}
+
+	// Strategies the engine does not implement are refused
+	if err := engine.Send(ctx, &pb.SetStrategy{Team: pb.TeamColor_TEAM_RED, Name: "scripted:x"}); err != nil || cfg.RedStrategy != StrategyClassicHunter {
+		t.Errorf("Expected the unsupported strategy to be ignored, got %v and %q", err, cfg.RedStrategy)
+	}
+	// The switch is the engine's own: the caller keeps reading its config meanwhile
+	if err := engine.Send(ctx, &pb.SetStrategy{Team: pb.TeamColor_TEAM_RED, Name: StrategyPackHunter}); err != nil || cfg.RedStrategy != StrategyClassicHunter {
+		t.Errorf("Expected the strategy switched on a copy of the config, got %v and %q", err, cfg.RedStrategy)
+	}
+	if got := engine.(*ecsEngine).cfg.RedStrategy; got != StrategyPackHunter {
+		t.Errorf("Expected the engine to use %s, got %q", StrategyPackHunter, got)
+	}
+
+	if err := engine.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := engine.Send(ctx, &pb.Tick{}); !errors.Is(err, ErrEngineStopped) {
+		t.Errorf("Expected ErrEngineStopped after Stop, got %v", err)
+	}
+}
+
+func TestRunner_selectsEngineFromConfig(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.Engine = EngineECS
+	cfg.NumRedAtStart = 5
+	cfg.NumBlueAtStart = 200
+	runner, err := NewRunner(ctx, cfg)
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	defer runner.Stop(ctx)
+	if _, ok := runner.engine.(*ecsEngine); !ok {
+		t.Fatalf("Expected the ecs engine, got %T", runner.engine)
+	}
+	snap, err := runner.Run(ctx, 100, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := snap.GetRedCount() + snap.GetBlueCount(); got != 205 {
+		t.Errorf("Expected 205 entities, got %d", got)
+	}
+}
+
+func TestConfig_ecsEngineNeedsBuiltinStrategies(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Engine = EngineECS
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected the default strategies to run on the ecs engine: %v", err)
+	}
+	RegisterBehavior("test-ecs-custom", pb.TeamColor_TEAM_BLUE, func() Behavior { return &ClassicBoids{} })
+	cfg.BlueStrategy = "test-ecs-custom"
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Expected a registered behavior to be refused by the ecs engine")
+	}
+	cfg.Engine = "gpu"
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Expected an unknown engine to be refused")
+	}
+}
+
+func TestECSEngine_defaultParamsMatchDefaultConfig(t *testing.T) {
+	e := &ecsEngine{cfg: DefaultConfig()}
+	if got, want := e.params(), engine.DefaultParams(); got != want {
+		t.Errorf("engine.DefaultParams() = %+v, want the params of DefaultConfig %+v", want, got)
+	}
+}
@@ -0,0 +1,167 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// localEngine runs the same world and individuals as the actor engine, without GoAkt:
+// the messages sent by the world during a step are queued, then delivered in order once
+// the step is over, like the mailboxes would. It is used where goakt does not build (WASM).
+type localEngine struct {
+	mu         sync.Mutex
+	world      *world
+	members    map[string]*individual
+	commanders map[string]*commander
+	inbox      []localMessage
+	log        Logger
+	stopped    bool
+}
+
+type localMessage struct {
+	id  string
+	msg proto.Message
+}
+
+// NewLocalEngine is the EngineFactory of the single goroutine engine
+func NewLocalEngine(_ context.Context, snapshotCh chan<- *pb.WorldSnapshot, cfg *Config, opts ...WorldOption) (Engine, error) {
+	e := &localEngine{
+		world:      newWorld(snapshotCh, cfg, opts...),
+		members:    make(map[string]*individual),
+		commanders: make(map[string]*commander),
+		log:        newStdLogger(cfg.LogLevel),
+	}
+	e.world.swarm = e
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.world.start()
+	e.flush()
+	return e, nil
+}
+
+func (e *localEngine) Send(_ context.Context, msg proto.Message) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		return ErrEngineStopped
+	}
+	e.world.handle(msg)
+	e.flush()
+	return nil
+}
+
+// flush delivers the queued messages to the individuals and the commanders, the answers of the
+// individuals go straight to the world, the orders of the commanders are queued after the others
+func (e *localEngine) flush() {
+	for idx := 0; idx < len(e.inbox); idx++ {
+		m := e.inbox[idx]
+		if c, ok := e.commanders[m.id]; ok {
+			c.handle(m.msg, e.tell)
+			continue
+		}
+		ind := e.members[m.id]
+		switch msg := m.msg.(type) {
+		case *pb.Tick:
+			e.world.handle(ind.handleTick(msg))
+		case *pb.Convert:
+			ind.handleConversion(e.log, msg)
+		case *pb.SetStrategy:
+			ind.handleSetStrategy(e.log, msg)
+		case *pb.Respawn:
+			ind.handleRespawn(e.log, msg)
+		case *pb.Move:
+			ind.handleMove(msg)
+		case *pb.Order:
+			ind.handleOrder(msg)
+		}
+	}
+	e.inbox = e.inbox[:0]
+}
+
+func (e *localEngine) State(_ context.Context, id string) (*pb.ActorState, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ind, ok := e.members[id]
+	if !ok {
+		return nil, ErrUnknownEntity
+	}
+	return ind.makeState(), nil
+}
+
+func (e *localEngine) Logger() Logger {
+	return e.log
+}
+
+func (e *localEngine) Stop(_ context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = true
+	e.log.Info("World is shutdown...")
+	return nil
+}
+
+// swarm implementation
+
+func (e *localEngine) spawn(id string, ind *individual) {
+	ind.setID(id)
+	e.members[id] = ind
+}
+
+func (e *localEngine) spawnCommander(id string, c *commander) {
+	e.commanders[id] = c
+}
+
+func (e *localEngine) tell(id string, msg proto.Message) bool {
+	if _, ok := e.members[id]; !ok && e.commanders[id] == nil {
+		return false
+	}
+	e.inbox = append(e.inbox, localMessage{id: id, msg: msg})
+	return true
+}
+
+func (e *localEngine) logger() Logger {
+	return e.log
+}
+
+// stdLogger is the Logger of the local engine, writing to stderr with the standard log package
+type stdLogger struct {
+	*log.Logger
+	debug bool
+	quiet bool // Only the errors are written (levels warn and error)
+}
+
+func newStdLogger(level string) *stdLogger {
+	return &stdLogger{
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+		debug:  level == "debug",
+		quiet:  level == "warn" || level == "error",
+	}
+}
+
+func (l *stdLogger) Debugf(format string, args ...any) {
+	if l.debug {
+		l.Printf("DEBUG "+format, args...)
+	}
+}
+
+func (l *stdLogger) Info(args ...any) {
+	if !l.quiet {
+		l.Print("INFO " + fmt.Sprint(args...))
+	}
+}
+
+func (l *stdLogger) Infof(format string, args ...any) {
+	if !l.quiet {
+		l.Printf("INFO "+format, args...)
+	}
+}
+
+func (l *stdLogger) Errorf(format string, args ...any) {
+	l.Printf("ERROR "+format, args...)
+}
@@ -0,0 +1,50 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestLocalEngine(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 2
+	cfg.NumBlueAtStart = 3
+	snapshotCh := make(chan *pb.WorldSnapshot, 1)
+
+	engine, err := NewLocalEngine(ctx, snapshotCh, cfg)
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	before, err := engine.State(ctx, "Red-000")
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+
+	if err := engine.Send(ctx, &pb.Tick{}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	snap := <-snapshotCh
+	if snap.GetTick() != 1 || len(snap.GetActors()) != 5 {
+		t.Errorf("Expected a snapshot of tick 1 with 5 actors, got tick %d with %d actors", snap.GetTick(), len(snap.GetActors()))
+	}
+
+	// The tick was delivered synchronously to the individuals
+	after, _ := engine.State(ctx, "Red-000")
+	if after.GetPosition().GetX() == before.GetPosition().GetX() && after.GetPosition().GetY() == before.GetPosition().GetY() {
+		t.Errorf("Expected Red-000 to move during the tick, still at %v", after.GetPosition())
+	}
+	if _, err := engine.State(ctx, "nobody"); !errors.Is(err, ErrUnknownEntity) {
+		t.Errorf("Expected ErrUnknownEntity, got %v", err)
+	}
+
+	if err := engine.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := engine.Send(ctx, &pb.Tick{}); !errors.Is(err, ErrEngineStopped) {
+		t.Errorf("Expected ErrEngineStopped after Stop, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package simulation
 
 import (
+	"math/rand/v2"
+
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
 )
 
@@ -10,15 +13,82 @@ type Entity struct {
 	Color pb.TeamColor
 	Pos   geometry.Vector2D
 	Vel   geometry.Vector2D
+	// Generation counts the lives of a pooled ID (see pool.go)
+	Generation uint32
 
 	// You can add fields here that are NEVER sent over the network
 	// e.g., energy, health, state-machine-timer
-	//Energy float64
+
+	// Hunger is the energy a blue lacks, from 0 (fed) to 1 (starving), see food.go
+	Hunger float64
+	// Fatigue is the stamina a red spent chasing, from 0 (rested) to 1 (exhausted), see stamina.go
+	Fatigue   float64
+	exhausted bool
+
+	// Rand is the random source of the behaviors moving this entity, nil means the global source
+	Rand *rand.Rand
+
+	// gridCell is the cell of the world grid holding the entity (see gridIndex)
+	gridCell gridKey
+	// dt is the time step of the current tick in nominal ticks, 0 means 1 (see DeltaTime)
+	dt float64
+	// spawnTick is the tick during which the entity spawned, 0 for the initial population (see protected)
+	spawnTick uint64
+	// lifeTick is the tick from which a blue counts its survival toward its next birth, 0 while it
+	// is red, and meals the food items it ate since then (see reproduction.go)
+	lifeTick uint64
+	meals    int
+	// lifeFactor scales Config.Lifespan for this life, 0 until drawn (see lifespan.go)
+	lifeFactor float64
+	// attackTick is the tick of the last attack of a red, 0 before its first (see Config.AttackCooldown)
+	attackTick uint64
+	// powerUps is the tick until which each effect of a pickup lasts (see powerup.go)
+	powerUps [numPowerUps]uint64
+	// integrator moves the entity in UpdatePhysics, startVel is its velocity before the forces
+	// of the current tick (see beginStep and integrator.go)
+	integrator string
+	startVel   geometry.Vector2D
+}
+
+// Float64 returns a random number in [0.0,1.0) from the entity random source
+func (e *Entity) Float64() float64 {
+	if e.Rand == nil {
+		return rand.Float64()
+	}
+	return e.Rand.Float64()
+}
+
+// DeltaTime returns the time step of the current tick in nominal ticks (see TickDuration):
+// 1 at the default rate, 2 when a tick covers twice the simulation time
+func (e *Entity) DeltaTime() float64 {
+	if e.dt == 0 {
+		return 1
+	}
+	return e.dt
+}
+
+// beginStep starts a tick of 'dt' nominal ticks, integrated with 'scheme' (see Config.Integrator)
+func (e *Entity) beginStep(dt float64, scheme string) {
+	e.dt = dt
+	e.integrator = scheme
+	e.startVel = e.Vel
 }
 
-// UpdatePhysics applies the velocity to Entity position
+// ApplyForce adds a steering force, given per nominal tick, to the velocity
+func (e *Entity) ApplyForce(force geometry.Vector2D) {
+	e.Vel = e.Vel.Add(force.Mul(e.DeltaTime()))
+}
+
+// UpdatePhysics applies the velocity to Entity position for the time step of the tick,
+// with the integration scheme given to beginStep
 func (e *Entity) UpdatePhysics() {
-	e.Pos = e.Pos.Add(e.Vel)
+	e.Pos = integrate(e.integrator, e.Pos, e.startVel, e.Vel, e.DeltaTime())
+}
+
+// protected reports whether the entity is still under spawn protection at 'tick':
+// during its spawn tick and the 'ticks' following ones
+func (e *Entity) protected(tick uint64, ticks int) bool {
+	return ticks > 0 && tick <= e.spawnTick+uint64(ticks)
 }
 
 // DistanceTo gives the cartesian distance from this Entity and the other
@@ -34,13 +104,21 @@ func (e *Entity) DistanceSquaredTo(other *Entity) float64 {
 // ToProto converts the clean Entity into the Protobuf "Envelope"
 func (e *Entity) ToProto() *pb.ActorState {
 	return &pb.ActorState{
-		Id:       e.ID,
-		Color:    e.Color,
-		Position: GeomVector2DToProto(e.Pos),
-		Velocity: GeomVector2DToProto(e.Vel),
+		Id:         e.ID,
+		Color:      e.Color,
+		Position:   GeomVector2DToProto(e.Pos),
+		Velocity:   GeomVector2DToProto(e.Vel),
+		Generation: e.Generation,
+		Hunger:     e.Hunger,
+		Fatigue:    e.Fatigue,
 	}
 }
 
+// FillProto writes the entity into an existing message, reusing its vectors (see SnapshotPool)
+func (e *Entity) FillProto(p *pb.ActorState) {
+	fillActorState(p, e.ID, e.Color, e.Pos.X, e.Pos.Y, e.Vel.X, e.Vel.Y, e.Generation, e.Hunger, e.Fatigue)
+}
+
 // UpdateFromProto updates the entity's state from a Protobuf message
 // without allocating new memory.
 func (e *Entity) UpdateFromProto(p *pb.ActorState) {
@@ -49,15 +127,13 @@ func (e *Entity) UpdateFromProto(p *pb.ActorState) {
 	e.Vel = GeomVector2DFromProto(p.Velocity)
 	// Optional: Sync color if dynamic conversion happens outside the world
 	e.Color = p.Color
+	// The individual spends and recovers its energy, the world weighs it in the fights (see energyRule)
+	e.Hunger = p.Hunger
+	e.Fatigue = p.Fatigue
 }
 
 func (e *Entity) ClampVelocity(minSpeed, maxSpeed float64) {
-	speed := e.Vel.Len()
-	if speed > maxSpeed {
-		e.Vel = e.Vel.Mul(maxSpeed / speed)
-	} else if speed < minSpeed && speed > 0 {
-		e.Vel = e.Vel.Mul(minSpeed / speed)
-	}
+	e.Vel = behavior.ClampSpeed(e.Vel, minSpeed, maxSpeed)
 }
 
 func (e *Entity) BounceOffWalls(width, height float64) {
@@ -84,17 +160,8 @@ func (e *Entity) BounceOffWalls(width, height float64) {
 }
 
 func (e *Entity) SoftBoundaries(width, height, turnFactor float64) {
-	margin := 100.0
-	if e.Pos.X < margin {
-		e.Vel.X += turnFactor
-	} else if e.Pos.X > width-margin {
-		e.Vel.X -= turnFactor
-	}
-	if e.Pos.Y < margin {
-		e.Vel.Y += turnFactor
-	} else if e.Pos.Y > height-margin {
-		e.Vel.Y -= turnFactor
-	}
+	const margin = 100.0
+	e.ApplyForce(behavior.Contain(e.Pos, width, height, margin, turnFactor))
 }
 
 func (e *Entity) Seek(target geometry.Vector2D, strength, maxSpeed float64) {
@@ -105,7 +172,7 @@ func (e *Entity) Seek(target geometry.Vector2D, strength, maxSpeed float64) {
 	if desired.LenSqr() > 0 {
 		desired = desired.Normalize().Mul(strength)
 		// 3. Apply Steering Force (simply adding to velocity here for "drift" style)
-		e.Vel = e.Vel.Add(desired)
+		e.ApplyForce(desired)
 
 		// 4. Cap Speed immediately so we don't explode
 		e.ClampVelocity(0, maxSpeed)
@@ -115,10 +182,13 @@ func (e *Entity) Seek(target geometry.Vector2D, strength, maxSpeed float64) {
 // FromProto (if needed) converts incoming messages back to Entities
 func FromProto(p *pb.ActorState) *Entity {
 	return &Entity{
-		ID:    p.Id,
-		Color: p.Color,
-		Pos:   GeomVector2DFromProto(p.Position),
-		Vel:   GeomVector2DFromProto(p.Velocity),
+		ID:         p.Id,
+		Color:      p.Color,
+		Pos:        GeomVector2DFromProto(p.Position),
+		Vel:        GeomVector2DFromProto(p.Velocity),
+		Generation: p.Generation,
+		Hunger:     p.Hunger,
+		Fatigue:    p.Fatigue,
 	}
 }
 
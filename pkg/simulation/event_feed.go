@@ -0,0 +1,29 @@
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+const (
+	// eventFeedSize is the number of events listed by the Game
+	eventFeedSize = 6
+	// eventFeedWidth is the room left for the longest event line, in pixels
+	eventFeedWidth      = 300.0
+	eventFeedLineHeight = 16
+)
+
+// drawEventFeed lists the last events of the world in the bottom right corner, above the population chart
+func (g *Game) drawEventFeed(screen *ebiten.Image) {
+	if !g.widgetShowFeed.Value {
+		return
+	}
+	events := g.events.Events()
+	lineHeight := scaled(eventFeedLineHeight)
+	x, y := g.layout.Place(ui.AnchorBottomRight, scaled(eventFeedWidth), float64(len(events))*lineHeight)
+	for i, e := range events {
+		printOverlay(screen, fmt.Sprintf("[%6d] %s", e.Tick, e), x, y+float64(i)*lineHeight)
+	}
+}
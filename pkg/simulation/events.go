@@ -0,0 +1,250 @@
+package simulation
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// EventKind is the type of an Event
+type EventKind string
+
+const (
+	// EventConversion : an entity was ordered to switch team after a contact
+	EventConversion EventKind = "conversion"
+	// EventGameOver : a team is extinct
+	EventGameOver EventKind = "game-over"
+	// EventSpawn : an entity joined the world (initial population or CommandQueue.Spawn)
+	EventSpawn EventKind = "spawn"
+	// EventDeath : an entity left the world (CommandQueue.Despawn or old age, see Config.Lifespan)
+	EventDeath EventKind = "death"
+)
+
+// Event is something that happened in the world, only the fields relevant to its Kind are set
+type Event struct {
+	Kind EventKind `json:"kind"`
+	Tick uint64    `json:"tick"`
+	// ID is the entity that switched team, spawned or died, Color its team afterwards
+	ID    string `json:"id,omitempty"`
+	Color string `json:"color,omitempty"`
+	// From is the team a converted entity left, NEUTRAL for a captured civilian
+	From string `json:"from,omitempty"`
+	// X and Y locate that entity when the event happened, e.g. to draw an effect there
+	X float64 `json:"x,omitempty"`
+	Y float64 `json:"y,omitempty"`
+	// Attacker and Victim of a conversion: the victim is converted, unless its defenders
+	// convert the attacker (ID tells which one switched). Empty for conversions ordered by a tick hook.
+	Attacker string `json:"attacker,omitempty"`
+	Victim   string `json:"victim,omitempty"`
+	// Winner of the game
+	Winner string `json:"winner,omitempty"`
+}
+
+// String describes the event in a few words, e.g. for the in-game feed
+func (e Event) String() string {
+	switch e.Kind {
+	case EventConversion:
+		switch {
+		case e.Attacker == "":
+			return fmt.Sprintf("%s turned %s", e.ID, e.Color)
+		case e.ID == e.Attacker:
+			return fmt.Sprintf("%s defended, %s turned %s", e.Victim, e.Attacker, e.Color)
+		default:
+			return fmt.Sprintf("%s converted %s", e.Attacker, e.Victim)
+		}
+	case EventGameOver:
+		return fmt.Sprintf("%s wins", e.Winner)
+	case EventSpawn:
+		return fmt.Sprintf("%s spawned", e.ID)
+	case EventDeath:
+		return fmt.Sprintf("%s died", e.ID)
+	}
+	return string(e.Kind)
+}
+
+// EventSink receives the events of the world. HandleEvent is invoked synchronously by the world
+// goroutine, in order: it must be fast and safe to use with the readers of the sink.
+type EventSink interface {
+	HandleEvent(e Event)
+}
+
+// EventSinkFunc adapts a function to an EventSink
+type EventSinkFunc func(e Event)
+
+func (f EventSinkFunc) HandleEvent(e Event) {
+	f(e)
+}
+
+// WithEventSink makes the world send its events to 'sink' (sinks are invoked in registration order)
+func WithEventSink(sink EventSink) WorldOption {
+	return func(w *world) {
+		if sink != nil {
+			w.events.sinks = append(w.events.sinks, sink)
+		}
+	}
+}
+
+// eventBus dispatches the events of an engine to its sinks
+type eventBus struct {
+	sinks []EventSink
+}
+
+func (b *eventBus) enabled() bool {
+	return len(b.sinks) > 0
+}
+
+func (b *eventBus) publish(e Event) {
+	for _, sink := range b.sinks {
+		sink.HandleEvent(e)
+	}
+}
+
+// gameOverEvent returns the event of a snapshot where a team is extinct
+func gameOverEvent(snap *pb.WorldSnapshot) Event {
+	winner := pb.TeamColor_TEAM_BLUE
+	if snap.RedCount > 0 {
+		winner = pb.TeamColor_TEAM_RED
+	}
+	return Event{Kind: EventGameOver, Tick: snap.Tick, Winner: teamLabel(winner)}
+}
+
+// ============================================================================
+// Sinks
+// ============================================================================
+
+// EventLog writes the events as JSON lines, one object per event
+type EventLog struct {
+	mu  sync.Mutex
+	bw  *bufio.Writer
+	enc *json.Encoder
+	c   io.Closer
+	err error
+}
+
+// NewEventLog writes the events to 'w', call Flush to push the buffered lines
+func NewEventLog(w io.Writer) *EventLog {
+	bw := bufio.NewWriter(w)
+	return &EventLog{bw: bw, enc: json.NewEncoder(bw)}
+}
+
+// CreateEventLog creates (or truncates) the JSON lines file at 'path'
+func CreateEventLog(path string) (*EventLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create event log: %w", err)
+	}
+	l := NewEventLog(f)
+	l.c = f
+	return l, nil
+}
+
+// HandleEvent appends the event, the first write error is kept (see Flush)
+func (l *EventLog) HandleEvent(e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		l.err = l.enc.Encode(e)
+	}
+}
+
+// Flush writes the buffered events and returns the first error met since the log was created
+func (l *EventLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		l.err = l.bw.Flush()
+	}
+	return l.err
+}
+
+// Close flushes the log and closes its file when it was created by CreateEventLog
+func (l *EventLog) Close() error {
+	err := l.Flush()
+	if l.c != nil {
+		if closeErr := l.c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// EventFeed keeps the last events of some kinds, for display (see Game)
+type EventFeed struct {
+	mu     sync.Mutex
+	kinds  []EventKind
+	events []Event
+	size   int
+}
+
+// NewEventFeed keeps the last 'size' events of the given kinds (every kind when none is given)
+func NewEventFeed(size int, kinds ...EventKind) *EventFeed {
+	return &EventFeed{kinds: kinds, size: size, events: make([]Event, 0, size)}
+}
+
+func (f *EventFeed) HandleEvent(e Event) {
+	if f.size <= 0 || len(f.kinds) > 0 && !slices.Contains(f.kinds, e.Kind) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.events) == f.size {
+		copy(f.events, f.events[1:])
+		f.events = f.events[:f.size-1]
+	}
+	f.events = append(f.events, e)
+}
+
+// Events returns a copy of the events kept, oldest first
+func (f *EventFeed) Events() []Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.events)
+}
+
+// Reset forgets the events kept
+func (f *EventFeed) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = f.events[:0]
+}
+
+// EventQueue buffers the events of some kinds until the reader drains them, e.g. the Game starting
+// an effect per conversion. At most 'size' events wait: the oldest are dropped when the reader lags.
+type EventQueue struct {
+	mu     sync.Mutex
+	kinds  []EventKind
+	events []Event
+	size   int
+}
+
+// NewEventQueue buffers up to 'size' events of the given kinds (every kind when none is given)
+func NewEventQueue(size int, kinds ...EventKind) *EventQueue {
+	return &EventQueue{kinds: kinds, size: size}
+}
+
+func (q *EventQueue) HandleEvent(e Event) {
+	if q.size <= 0 || len(q.kinds) > 0 && !slices.Contains(q.kinds, e.Kind) {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) == q.size {
+		q.events = append(q.events[:0], q.events[1:]...)
+	}
+	q.events = append(q.events, e)
+}
+
+// Drain appends the waiting events to 'dst', oldest first, and empties the queue
+func (q *EventQueue) Drain(dst []Event) []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dst = append(dst, q.events...)
+	q.events = q.events[:0]
+	return dst
+}
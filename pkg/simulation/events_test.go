@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestEvents_world(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 2
+	cfg.NumBlueAtStart = 5
+	cfg.Seed = 1
+
+	var events []Event
+	sink := EventSinkFunc(func(e Event) { events = append(events, e) })
+	// Every blue is converted at the first tick, then one red leaves the world
+	hook := func(view *WorldView, cmds *CommandQueue) {
+		switch view.Tick() {
+		case 1:
+			view.Each(func(e Entity) bool {
+				if e.Color == pb.TeamColor_TEAM_BLUE {
+					cmds.Convert(e.ID, pb.TeamColor_TEAM_RED)
+				}
+				return true
+			})
+		case 2:
+			cmds.Despawn("Red-000")
+		}
+	}
+	runner, err := NewRunner(ctx, cfg, WithEngine(NewLocalEngine), WithWorldOptions(WithEventSink(sink), WithTickHook(hook)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer runner.Stop(ctx)
+	if _, err := runner.Run(ctx, 10, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[EventKind]int)
+	for _, e := range events {
+		counts[e.Kind]++
+		switch e.Kind {
+		case EventConversion:
+			if e.Color != "RED" || e.Tick != 1 || e.Attacker != "" {
+				t.Errorf("Unexpected conversion %+v", e)
+			}
+			if e.X == 0 && e.Y == 0 {
+				t.Errorf("Expected the conversion to be located, got %+v", e)
+			}
+		case EventGameOver:
+			if e.Winner != "RED" {
+				t.Errorf("Expected RED to win, got %+v", e)
+			}
+		case EventDeath:
+			if e.ID != "Red-000" || e.Tick != 2 {
+				t.Errorf("Unexpected death %+v", e)
+			}
+		}
+	}
+	want := map[EventKind]int{EventSpawn: 7, EventConversion: 5, EventDeath: 1, EventGameOver: 1}
+	for kind, n := range want {
+		if counts[kind] != n {
+			t.Errorf("Expected %d %s events, got %d", n, kind, counts[kind])
+		}
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewEventLog(&buf)
+	log.HandleEvent(Event{Kind: EventConversion, Tick: 3, ID: "Blue-001", Color: "RED", Attacker: "Red-000", Victim: "Blue-001"})
+	log.HandleEvent(Event{Kind: EventGameOver, Tick: 9, Winner: "RED"})
+	if err := log.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 JSON lines, got %q", buf.String())
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Kind != EventConversion || e.Attacker != "Red-000" || e.Victim != "Blue-001" {
+		t.Errorf("Unexpected decoded event %+v", e)
+	}
+	if strings.Contains(lines[1], "attacker") {
+		t.Errorf("Expected the empty fields to be omitted: %s", lines[1])
+	}
+}
+
+func TestEventFeed(t *testing.T) {
+	feed := NewEventFeed(2, EventConversion)
+	feed.HandleEvent(Event{Kind: EventSpawn, ID: "Blue-000"})
+	for tick := uint64(1); tick <= 3; tick++ {
+		feed.HandleEvent(Event{Kind: EventConversion, Tick: tick, ID: "Blue-000", Attacker: "Red-000", Victim: "Blue-000"})
+	}
+	events := feed.Events()
+	if len(events) != 2 || events[0].Tick != 2 || events[1].Tick != 3 {
+		t.Errorf("Expected the last 2 conversions, got %+v", events)
+	}
+	if got := events[1].String(); got != "Red-000 converted Blue-000" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestEventQueue(t *testing.T) {
+	queue := NewEventQueue(2, EventConversion)
+	queue.HandleEvent(Event{Kind: EventSpawn, ID: "Blue-000"})
+	for tick := uint64(1); tick <= 3; tick++ {
+		queue.HandleEvent(Event{Kind: EventConversion, Tick: tick, ID: "Blue-000"})
+	}
+	events := queue.Drain(nil)
+	if len(events) != 2 || events[0].Tick != 2 || events[1].Tick != 3 {
+		t.Errorf("Expected the last 2 conversions, got %+v", events)
+	}
+	if events = queue.Drain(events[:0]); len(events) != 0 {
+		t.Errorf("Expected Drain to empty the queue, got %+v", events)
+	}
+}
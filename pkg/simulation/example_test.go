@@ -0,0 +1,101 @@
+package simulation_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/simulation"
+)
+
+// Run a headless simulation for a few ticks
+func ExampleRunner() {
+	ctx := context.Background()
+	cfg := simulation.DefaultConfig()
+	cfg.NumRedAtStart = 3
+	cfg.NumBlueAtStart = 20
+
+	runner, err := simulation.NewRunner(ctx, cfg)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer runner.Stop(ctx)
+
+	last, err := runner.Run(ctx, 50, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(last.GetRedCount()+last.GetBlueCount() == 23)
+	// Output: true
+}
+
+// Count the entities of each team from inside the world loop
+func ExampleWithTickHook() {
+	ctx := context.Background()
+	hook := func(view *simulation.WorldView, cmds *simulation.CommandQueue) {
+		if view.Tick() == 1 {
+			red, blue := view.Counts()
+			fmt.Printf("red=%d blue=%d\n", red, blue)
+		}
+	}
+	cfg := simulation.DefaultConfig()
+	runner, err := simulation.NewRunner(ctx, cfg, simulation.WithWorldOptions(simulation.WithTickHook(hook)))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer runner.Stop(ctx)
+	_, _ = runner.Run(ctx, 2, nil)
+	// Output: red=5 blue=30
+}
+
+// Switch the red team to another registered behavior
+func ExampleRunner_SetStrategy() {
+	ctx := context.Background()
+	runner, err := simulation.NewRunner(ctx, simulation.DefaultConfig())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer runner.Stop(ctx)
+
+	fmt.Println(runner.SetStrategy(ctx, pb.TeamColor_TEAM_RED, simulation.StrategyPackHunter))
+	fmt.Println(runner.SetStrategy(ctx, pb.TeamColor_TEAM_RED, "unknown") != nil)
+	// Output:
+	// <nil>
+	// true
+}
+
+// Skip the spawn transient, then average the red fraction once the population settled
+func ExampleWithWarmup() {
+	ctx := context.Background()
+	cfg := simulation.DefaultConfig()
+	cfg.Seed = 1
+	runner, err := simulation.NewRunner(ctx, cfg, simulation.WithEngine(simulation.NewLocalEngine), simulation.WithWarmup(60))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer runner.Stop(ctx)
+
+	steady := replay.NewSteadyStateDetector(replay.DefaultSteadyStateOptions())
+	var sum float64
+	var n int
+	_, _ = runner.Run(ctx, 600, func(snap *pb.WorldSnapshot) bool {
+		red, blue := int(snap.GetRedCount()), int(snap.GetBlueCount())
+		if steady.Add(replay.Sample{Tick: snap.GetTick(), Red: red, Blue: blue}) {
+			sum += float64(red) / float64(red+blue)
+			n++
+		}
+		return true
+	})
+	mean := 0.0
+	if n > 0 {
+		mean = sum / float64(n)
+	}
+	fmt.Println(runner.Warmup(), runner.Tick() > runner.Warmup(), mean >= 0 && mean <= 1)
+	// Output: 60 true true
+}
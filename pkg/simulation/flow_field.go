@@ -0,0 +1,130 @@
+package simulation
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// flowArrowLength is the length of the arrow of the fastest cell, as a fraction of the cell size
+const flowArrowLength = 0.45
+
+// FlowField is the average velocity of the entities per cell of a grid covering the world,
+// drawn as arrows by DrawWorld (see WorldDrawOptions.FlowField) to show the alignment of the
+// flock and the turbulent areas. The cells are those of the spatial grid of the world
+// (see gridCellSize) unless another size is set.
+type FlowField struct {
+	cellSize   float64
+	cols, rows int
+	cells      []flowCell
+}
+
+type flowCell struct {
+	vx, vy float64 // sum of the velocities
+	speed  float64 // sum of the speeds
+	n      int
+}
+
+// NewFlowField creates the field of a world of the given size
+func NewFlowField(width, height, cellSize float64) *FlowField {
+	f := &FlowField{}
+	f.Resize(width, height, cellSize)
+	return f
+}
+
+// Resize changes the world or cell size, the field is empty until the next Update
+func (f *FlowField) Resize(width, height, cellSize float64) {
+	f.cellSize = max(cellSize, 1)
+	f.cols = max(int(math.Ceil(width/f.cellSize)), 1)
+	f.rows = max(int(math.Ceil(height/f.cellSize)), 1)
+	if n := f.cols * f.rows; cap(f.cells) >= n {
+		f.cells = f.cells[:n]
+		clear(f.cells)
+	} else {
+		f.cells = make([]flowCell, n)
+	}
+}
+
+// CellSize returns the size of the cells
+func (f *FlowField) CellSize() float64 {
+	return f.cellSize
+}
+
+// Update averages the velocities of the entities of 'snap', the entities outside the world are ignored
+func (f *FlowField) Update(snap *pb.WorldSnapshot) {
+	clear(f.cells)
+	for _, a := range snap.GetActors() {
+		col := int(math.Floor(a.GetPosition().GetX() / f.cellSize))
+		row := int(math.Floor(a.GetPosition().GetY() / f.cellSize))
+		if col < 0 || col >= f.cols || row < 0 || row >= f.rows {
+			continue
+		}
+		c := &f.cells[row*f.cols+col]
+		vx, vy := a.GetVelocity().GetX(), a.GetVelocity().GetY()
+		c.vx += vx
+		c.vy += vy
+		c.speed += math.Hypot(vx, vy)
+		c.n++
+	}
+}
+
+// Cell returns the average velocity of the entities in the cell, their count, and their alignment:
+// the norm of the average velocity over the average speed, 1 when they all fly the same way,
+// close to 0 in turbulent cells
+func (f *FlowField) Cell(col, row int) (vx, vy float64, n int, alignment float64) {
+	if col < 0 || col >= f.cols || row < 0 || row >= f.rows {
+		return 0, 0, 0, 0
+	}
+	c := f.cells[row*f.cols+col]
+	if c.n == 0 {
+		return 0, 0, 0, 0
+	}
+	vx, vy = c.vx/float64(c.n), c.vy/float64(c.n)
+	if c.speed > 0 {
+		alignment = math.Hypot(vx, vy) / (c.speed / float64(c.n))
+	}
+	return vx, vy, c.n, alignment
+}
+
+// drawFlowField draws an arrow per occupied cell, scaled by the fastest cell and colored
+// from red (turbulent) to green (aligned)
+func drawFlowField(r Renderer, f *FlowField) {
+	var fastest float64
+	for row := 0; row < f.rows; row++ {
+		for col := 0; col < f.cols; col++ {
+			vx, vy, _, _ := f.Cell(col, row)
+			fastest = max(fastest, math.Hypot(vx, vy))
+		}
+	}
+	if fastest == 0 {
+		return
+	}
+	scale := f.cellSize * flowArrowLength / fastest
+	for row := 0; row < f.rows; row++ {
+		for col := 0; col < f.cols; col++ {
+			vx, vy, n, alignment := f.Cell(col, row)
+			if n == 0 || vx == 0 && vy == 0 {
+				continue
+			}
+			x := (float64(col) + 0.5) * f.cellSize
+			y := (float64(row) + 0.5) * f.cellSize
+			clr := color.RGBA{R: uint8(255 * (1 - alignment)), G: uint8(255 * alignment), B: 60, A: 200}
+			drawArrow(r, x, y, vx*scale, vy*scale, clr)
+		}
+	}
+}
+
+// drawArrow draws the vector (dx, dy) centered on (x, y)
+func drawArrow(r Renderer, x, y, dx, dy float64, clr color.RGBA) {
+	x0, y0 := x-dx/2, y-dy/2
+	x1, y1 := x+dx/2, y+dy/2
+	r.StrokeLine(float32(x0), float32(y0), float32(x1), float32(y1), 1, clr)
+	// Head: two strokes at ±30° from the shaft, a third of its length
+	angle := math.Atan2(dy, dx)
+	head := math.Hypot(dx, dy) / 3
+	for _, side := range []float64{-1, 1} {
+		a := angle + math.Pi - side*math.Pi/6
+		r.StrokeLine(float32(x1), float32(y1), float32(x1+head*math.Cos(a)), float32(y1+head*math.Sin(a)), 1, clr)
+	}
+}
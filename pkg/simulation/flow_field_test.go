@@ -0,0 +1,52 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func movingAt(x, y, vx, vy float64) *pb.ActorState {
+	return &pb.ActorState{Position: &pb.Vector{X: x, Y: y}, Velocity: &pb.Vector{X: vx, Y: vy}}
+}
+
+func TestFlowField(t *testing.T) {
+	f := NewFlowField(100, 50, 40)
+	f.Update(&pb.WorldSnapshot{Actors: []*pb.ActorState{
+		// Cell (0, 0): aligned
+		movingAt(10, 10, 2, 0), movingAt(30, 5, 4, 0),
+		// Cell (2, 1): opposite directions
+		movingAt(90, 45, 0, 3), movingAt(85, 41, 0, -3),
+		// Outside of the world
+		movingAt(-5, 10, 1, 1), movingAt(10, 120, 1, 1),
+	}})
+
+	if vx, vy, n, alignment := f.Cell(0, 0); vx != 3 || vy != 0 || n != 2 || math.Abs(alignment-1) > 1e-9 {
+		t.Errorf("Cell(0, 0) = %v, %v, %d, %v, expected an aligned pair going right at 3", vx, vy, n, alignment)
+	}
+	if vx, vy, n, alignment := f.Cell(2, 1); vx != 0 || vy != 0 || n != 2 || alignment != 0 {
+		t.Errorf("Cell(2, 1) = %v, %v, %d, %v, expected a turbulent pair", vx, vy, n, alignment)
+	}
+	if _, _, n, _ := f.Cell(1, 0); n != 0 {
+		t.Errorf("Expected an empty cell, got %d entities", n)
+	}
+	if _, _, n, _ := f.Cell(3, 0); n != 0 {
+		t.Errorf("Expected no cell outside of the grid, got %d entities", n)
+	}
+
+	// Only the moving cell gets an arrow: a shaft and two strokes for the head
+	r := NewHashRenderer()
+	drawFlowField(r, f)
+	if r.Calls() != 3 {
+		t.Errorf("Expected one arrow (3 strokes), got %d draw calls", r.Calls())
+	}
+
+	f.Resize(100, 50, 20)
+	if f.CellSize() != 20 {
+		t.Errorf("Expected a cell size of 20, got %v", f.CellSize())
+	}
+	if _, _, n, _ := f.Cell(0, 0); n != 0 {
+		t.Errorf("Expected an empty field after Resize, got %d entities", n)
+	}
+}
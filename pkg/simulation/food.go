@@ -0,0 +1,179 @@
+package simulation
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// defaultFoodMax is the largest number of food items in the world when Config.FoodMax is 0
+	defaultFoodMax = 40
+	// defaultFoodEnergy is the hunger a food item satisfies when Config.FoodEnergy is 0
+	defaultFoodEnergy = 0.5
+	// foodReach is the distance under which a blue eats a food item
+	foodReach = 8.0
+	// foodMargin keeps the food items away from the walls
+	foodMargin = 20.0
+	// hungerRate is the hunger a blue gains per nominal tick: starving after 30 seconds at 60 TPS
+	hungerRate = 1.0 / 1800
+	// foodSeekWeight is the weight of the pull of a starving blue toward the closest food in sight,
+	// a fed blue does not look for food
+	foodSeekWeight = 0.3
+	// starvingSpeed is the fraction of MaxSpeed a starving blue cannot exceed
+	starvingSpeed = 0.5
+)
+
+// foodState is the food of the world at a tick, published to the individuals: where the items
+// are, and how many items each blue ate during the last tick
+type foodState struct {
+	items []geometry.Vector2D
+	eaten map[string]int
+}
+
+// Food links the world to whoever shows its food items, e.g. the Game. Give it to the world with
+// WithFood. A Food is safe for concurrent use.
+type Food struct {
+	items atomic.Pointer[[]geometry.Vector2D]
+}
+
+// Items returns where the food items are, nil without Config.FoodRate. It must not be modified.
+func (f *Food) Items() []geometry.Vector2D {
+	if items := f.items.Load(); items != nil {
+		return *items
+	}
+	return nil
+}
+
+// WithFood makes the world publish its food items to 'f' at every tick
+func WithFood(f *Food) WorldOption {
+	return func(w *world) {
+		w.food.control = f
+	}
+}
+
+// foodTracker keeps the food of the world from a tick to the next
+type foodTracker struct {
+	control *Food
+	items   []geometry.Vector2D
+	// due is the number of items to spawn, the fraction of an item left by FoodRate carries over
+	due float64
+	// rng places the items, a stream of the world seed (see entityRand)
+	rng *rand.Rand
+}
+
+// updateFood spawns FoodRate items per tick at random places of the world, up to FoodMax, then
+// lets every blue within foodReach of an item eat it, and publishes the food
+func (w *world) updateFood() {
+	t := &w.food
+	if w.cfg.FoodRate == 0 {
+		if w.live.food.Load() != nil {
+			t.items, t.due = nil, 0
+			w.live.food.Store(nil)
+			if t.control != nil {
+				t.control.items.Store(nil)
+			}
+		}
+		return
+	}
+	if t.rng == nil {
+		t.rng = w.entityRand("food")
+	}
+	limit := w.cfg.FoodMax
+	if limit == 0 {
+		limit = defaultFoodMax
+	}
+	t.due += w.cfg.FoodRate
+	for ; t.due >= 1; t.due-- {
+		if len(t.items) >= limit {
+			t.due = 0
+			break
+		}
+		if pos, ok := w.randomSpot(t.rng); ok {
+			t.items = append(t.items, pos)
+		}
+	}
+
+	state := &foodState{eaten: make(map[string]int)}
+	for _, e := range w.order {
+		if e.Color != pb.TeamColor_TEAM_BLUE || len(t.items) == 0 {
+			continue
+		}
+		t.items = slices.DeleteFunc(t.items, func(item geometry.Vector2D) bool {
+			if e.Pos.DistanceSquaredTo(item) > foodReach*foodReach {
+				return false
+			}
+			state.eaten[e.ID]++
+			return true
+		})
+	}
+	state.items = slices.Clone(t.items)
+	w.live.food.Store(state)
+	if t.control != nil {
+		t.control.items.Store(&state.items)
+	}
+}
+
+// randomSpot returns a random place of the world drawn from 'rng' for a food item or a pickup, false
+// when the few places tried were all inside an obstacle
+func (w *world) randomSpot(rng *rand.Rand) (geometry.Vector2D, bool) {
+	obstacles := w.cfg.ObstacleList()
+	for range 8 {
+		pos := geometry.Vector2D{
+			X: foodMargin + rng.Float64()*math.Max(w.cfg.WorldWidth-2*foodMargin, 0),
+			Y: foodMargin + rng.Float64()*math.Max(w.cfg.WorldHeight-2*foodMargin, 0),
+		}
+		if !slices.ContainsFunc(obstacles, func(o behavior.Obstacle) bool { return o.Contains(pos) }) {
+			return pos, true
+		}
+	}
+	return geometry.Vector2D{}, false
+}
+
+// forage makes a blue hungrier, satisfies its hunger with the food it ate during the last tick and
+// pulls it toward the closest food in sight, the harder the hungrier
+func forage(me *Entity, food *foodState, cfg *Config) {
+	if food == nil || me.Color != pb.TeamColor_TEAM_BLUE {
+		return
+	}
+	me.Hunger = min(me.Hunger+hungerRate*me.DeltaTime(), 1)
+	if n := food.eaten[me.ID]; n > 0 {
+		energy := cfg.FoodEnergy
+		if energy == 0 {
+			energy = defaultFoodEnergy
+		}
+		me.Hunger = max(me.Hunger-float64(n)*energy, 0)
+	}
+	closest, found := geometry.Vector2D{}, false
+	bestSq := cfg.VisualRange * cfg.VisualRange
+	for _, item := range food.items {
+		if d := me.Pos.DistanceSquaredTo(item); d < bestSq {
+			closest, found, bestSq = item, true, d
+		}
+	}
+	if found {
+		me.ApplyForce(behavior.Seek(me.Pos, me.Vel, closest, cfg.MaxSpeed).Mul(foodSeekWeight * me.Hunger))
+	}
+}
+
+// starve slows down a starving blue, after its strategy moved it
+func starve(me *Entity, food *foodState, cfg *Config) {
+	if food != nil && me.Color == pb.TeamColor_TEAM_BLUE && me.Hunger >= 1 {
+		me.ClampVelocity(0, cfg.MaxSpeed*starvingSpeed)
+	}
+}
+
+// foodTint colors the food sprite
+var foodTint = [4]float32{0.4, 1, 0.3, 1}
+
+// drawFood draws a sprite for every food item
+func drawFood(r Renderer, items []geometry.Vector2D) {
+	for _, item := range items {
+		r.DrawSprite(SpriteFood, item.X, item.Y, 0, 1, foodTint)
+	}
+}
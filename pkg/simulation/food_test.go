@@ -0,0 +1,96 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestConfigFood(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FoodRate = 0.05
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.FoodRate = -1 },
+		func(c *Config) { c.FoodMax = -1 },
+		func(c *Config) { c.FoodEnergy = 2 },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestWorld_updateFood(t *testing.T) {
+	cfg := &Config{WorldWidth: 400, WorldHeight: 400, DetectionRadius: 100, FoodRate: 0.5, FoodMax: 3, Seed: 7}
+	control := &Food{}
+	w := newWorld(nil, cfg, WithFood(control))
+	cfg = w.cfg
+	w.updateFood()
+	if got := len(control.Items()); got != 0 {
+		t.Fatalf("Expected the first item after two ticks, got %d", got)
+	}
+	for range 10 {
+		w.updateFood()
+	}
+	items := control.Items()
+	if len(items) != 3 {
+		t.Fatalf("Expected FoodMax items, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.X < foodMargin || item.X > 400-foodMargin || item.Y < foodMargin || item.Y > 400-foodMargin {
+			t.Errorf("Expected the food inside the margins, got %v", item)
+		}
+	}
+
+	// A blue on an item eats it, a red does not
+	w.addEntity(&Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED, Pos: items[0]})
+	w.addEntity(&Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: items[1].Add(geometry.Vector2D{X: 3})})
+	cfg.FoodRate = 0.01
+	w.updateFood()
+	state := w.live.food.Load()
+	if state.eaten["Blue-000"] != 1 || state.eaten["Red-000"] != 0 || len(state.items) != 2 {
+		t.Errorf("Expected the blue to eat one item, got %v and %d left", state.eaten, len(state.items))
+	}
+
+	cfg.FoodRate = 0
+	w.updateFood()
+	if w.live.food.Load() != nil || control.Items() != nil {
+		t.Error("Expected no food without FoodRate")
+	}
+}
+
+func TestForage(t *testing.T) {
+	cfg := DefaultConfig()
+	food := &foodState{items: []geometry.Vector2D{{X: 130, Y: 100}}, eaten: map[string]int{"Blue-001": 1}}
+
+	hungry := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 100}, Hunger: 0.8}
+	forage(hungry, food, cfg)
+	if hungry.Hunger <= 0.8 || hungry.Vel.X <= 0 {
+		t.Errorf("Expected the blue hungrier and heading to the food, got %v and %v", hungry.Hunger, hungry.Vel)
+	}
+
+	fed := &Entity{ID: "Blue-001", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 100}, Hunger: 0.7}
+	forage(fed, food, cfg)
+	if fed.Hunger > 0.21 {
+		t.Errorf("Expected the item eaten to satisfy half of the hunger, got %v", fed.Hunger)
+	}
+
+	starving := &Entity{Color: pb.TeamColor_TEAM_BLUE, Vel: geometry.Vector2D{X: cfg.MaxSpeed}, Hunger: 1}
+	starve(starving, food, cfg)
+	if starving.Vel.Len() > cfg.MaxSpeed*starvingSpeed+1e-9 {
+		t.Errorf("Expected a starving blue slowed down, got %v", starving.Vel)
+	}
+	red := &Entity{Color: pb.TeamColor_TEAM_RED, Vel: geometry.Vector2D{X: cfg.MaxSpeed}, Hunger: 1}
+	starve(red, food, cfg)
+	forage(red, food, cfg)
+	if red.Vel.X != cfg.MaxSpeed {
+		t.Errorf("Expected the reds to ignore the food, got %v", red.Vel)
+	}
+}
@@ -0,0 +1,108 @@
+package simulation
+
+import (
+	"strings"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// StrategyFormationPrefix prefixes the blue strategies flying in formation, followed by a shape of
+// behavior.FormationShapes, e.g. "formation-v"
+const StrategyFormationPrefix = "formation-"
+
+const (
+	// defaultFormationSize is the number of blues per formation when Config.FormationSize is 0
+	defaultFormationSize = 12
+	// defaultFormationSpacing is the distance between neighbor slots when Config.FormationSpacing is 0
+	defaultFormationSpacing = 30.0
+	// defaultFormationWeight is the weight of the pull toward the slot when Config.FormationWeight is 0
+	defaultFormationWeight = 0.1
+)
+
+func init() {
+	// The boids rules still apply: the world adds the pull toward the slot (see applyFormation)
+	for _, shape := range behavior.FormationShapes {
+		RegisterBehavior(StrategyFormationPrefix+shape, pb.TeamColor_TEAM_BLUE, func() Behavior { return &ClassicBoids{} })
+	}
+}
+
+// formationShape returns the shape of the formation strategy of the blues, "" when they fly free
+func (c *Config) formationShape() string {
+	shape, ok := strings.CutPrefix(c.StrategyFor(pb.TeamColor_TEAM_BLUE), StrategyFormationPrefix)
+	if !ok {
+		return ""
+	}
+	return shape
+}
+
+func (c *Config) formationSpacing() float64 {
+	if c.FormationSpacing == 0 {
+		return defaultFormationSpacing
+	}
+	return c.FormationSpacing
+}
+
+// formationState is the slot of every blue in formation, published by the world at every tick
+type formationState struct {
+	slots map[string]geometry.Vector2D
+}
+
+// updateFormation splits the blues, by order of arrival, into formations of FormationSize flying
+// the shape of the blue strategy. A formation is centered on the centroid of its members and heads
+// along their mean velocity: it moves as they flock, and each member is given the next slot.
+func (w *world) updateFormation() {
+	shape := w.cfg.formationShape()
+	if shape == "" {
+		if w.live.formation.Load() != nil {
+			w.live.formation.Store(nil)
+		}
+		return
+	}
+	size := w.cfg.FormationSize
+	if size == 0 {
+		size = defaultFormationSize
+	}
+	var members []*Entity
+	for _, e := range w.order {
+		if e.Color == pb.TeamColor_TEAM_BLUE {
+			members = append(members, e)
+		}
+	}
+	state := &formationState{slots: make(map[string]geometry.Vector2D, len(members))}
+	for start := 0; start < len(members); start += size {
+		squad := members[start:min(start+size, len(members))]
+		var anchor, vel geometry.Vector2D
+		for _, e := range squad {
+			anchor = anchor.Add(e.Pos)
+			vel = vel.Add(e.Vel)
+		}
+		anchor = anchor.Mul(1 / float64(len(squad)))
+		heading := geometry.Vector2D{X: 1}
+		if vel.Len() > 1e-9 {
+			heading = vel.Normalize()
+		}
+		for i, offset := range behavior.FormationSlots(shape, len(squad), w.cfg.formationSpacing()) {
+			state.slots[squad[i].ID] = behavior.FormationPlace(anchor, heading, offset)
+		}
+	}
+	w.live.formation.Store(state)
+}
+
+// applyFormation pulls 'me' toward its slot in the formation with the weight FormationWeight, on
+// top of the boids rules of its strategy
+func applyFormation(me *Entity, formation *formationState, cfg *Config) {
+	if formation == nil {
+		return
+	}
+	slot, ok := formation.slots[me.ID]
+	if !ok {
+		return
+	}
+	weight := cfg.FormationWeight
+	if weight == 0 {
+		weight = defaultFormationWeight
+	}
+	me.ApplyForce(behavior.Arrive(me.Pos, me.Vel, slot, cfg.MaxSpeed, cfg.formationSpacing()).Mul(weight))
+}
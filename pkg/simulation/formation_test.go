@@ -0,0 +1,68 @@
+package simulation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestConfigFormation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BlueStrategy = StrategyFormationPrefix + "v"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !slices.Contains(BehaviorNames(pb.TeamColor_TEAM_BLUE), StrategyFormationPrefix+"grid") {
+		t.Error("Expected the formations among the blue strategies")
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.FormationSize = -1 },
+		func(c *Config) { c.FormationSpacing = -1 },
+		func(c *Config) { c.FormationWeight = -1 },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestWorld_updateFormation(t *testing.T) {
+	cfg := &Config{WorldWidth: 1000, WorldHeight: 1000, DetectionRadius: 100, FormationSize: 2, FormationSpacing: 20}
+	w := newWorld(nil, cfg)
+	cfg = w.cfg
+	w.addEntity(&Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 100}, Vel: geometry.Vector2D{X: 1}})
+	w.addEntity(&Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED})
+	w.addEntity(&Entity{ID: "Blue-001", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 140}, Vel: geometry.Vector2D{X: 1}})
+	w.addEntity(&Entity{ID: "Blue-002", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 500, Y: 500}})
+
+	w.updateFormation()
+	if w.live.formation.Load() != nil {
+		t.Fatal("Expected no formation while the blues fly free")
+	}
+
+	cfg.BlueStrategy = StrategyFormationPrefix + "line"
+	w.updateFormation()
+	slots := w.live.formation.Load().slots
+	if len(slots) != 3 {
+		t.Fatalf("Expected a slot for each blue, got %v", slots)
+	}
+	// Abreast across the heading +X, around the centroid (100,120), 20 apart
+	if a, b := slots["Blue-000"], slots["Blue-001"]; a.X != 100 || b.X != 100 || a.DistanceTo(b) != 20 || a.Y+b.Y != 240 {
+		t.Errorf("Unexpected line %v and %v", a, b)
+	}
+	// Alone in its formation: its slot is where it is
+	if got := slots["Blue-002"]; got != (geometry.Vector2D{X: 500, Y: 500}) {
+		t.Errorf("Expected the last blue alone on its slot, got %v", got)
+	}
+
+	me := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 100}}
+	applyFormation(me, w.live.formation.Load(), DefaultConfig())
+	if me.Vel.Y <= 0 {
+		t.Errorf("Expected Blue-000 to be pulled down toward its slot %v, got %v", slots["Blue-000"], me.Vel)
+	}
+}
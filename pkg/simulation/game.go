@@ -3,57 +3,147 @@ package simulation
 import (
 	"context"
 	"fmt"
+	"image"
 	"image/color"
 	"math"
+	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
-	"github.com/tochemey/goakt/v3/actor"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui/text"
 )
 
 // Pre-rendered sprites for fast batched drawing
 var (
-	whiteImage    = ebiten.NewImage(3, 3)
-	redSpaceship  *ebiten.Image
-	blueSpaceship *ebiten.Image
-	trailSprite   *ebiten.Image
+	whiteImage     = ebiten.NewImage(3, 3)
+	redSpaceship   *ebiten.Image
+	blueSpaceship  *ebiten.Image
+	trailSprite    *ebiten.Image
+	foodSprite     *ebiten.Image
+	civilianSprite *ebiten.Image
+	pickupSprite   *ebiten.Image
 )
 
-const drawTrails = false
+// letterboxColor fills the screen around the world, see drawLetterbox
+var letterboxColor = color.RGBA{R: 30, G: 30, B: 36, A: 255}
 
 type Game struct {
 	ctx        context.Context
-	System     actor.ActorSystem
-	worldPID   *actor.PID
+	engine     Engine
+	newEngine  EngineFactory
 	snapshotCh chan *pb.WorldSnapshot
 	lastState  *pb.WorldSnapshot
-
-	// trails will store trail history: Map[ActorID] -> List of Positions
-	trails map[string][]geometry.Vector2D
+	// worldOpts are re-applied every time the world is (re)spawned
+	worldOpts []WorldOption
+	// snapshots recycles the snapshots once the next one replaced them
+	snapshots *SnapshotPool
+
+	// trails will store trail history of the red entities, under a global memory cap
+	trails *Trails
+	// history of the population, drawn as a chart in the corner or expanded with the C key
+	history         *PopulationHistory
+	chartExpanded   bool
+	widgetShowChart *ui.Checkbox
+	// events are the last events of the world, listed above the chart
+	events *EventFeed
+	// summary accumulates the statistics of the run, shown by summaryScreen at the game over
+	summary       *RunSummary
+	summaryScreen summaryScreen
+	// tournament scores the rounds when Config.Rounds is set (nil otherwise), roundOver is when
+	// the current round ended, zero while it is played
+	tournament     *Tournament
+	roundOver      time.Time
+	widgetShowFeed *ui.Checkbox
+	// effects bursts particles where the conversions happen, when widgetShowEffects is checked
+	effects           *Effects
+	widgetShowEffects *ui.Checkbox
+	// memory accounts the memory of the subsystems, listed when widgetShowMemory is checked
+	memory           memoryHUD
+	widgetShowMemory *ui.Checkbox
+	// audio plays the sounds of the events when widgetSound is checked
+	audio        *Audio
+	widgetSound  *ui.Checkbox
+	widgetVolume *ui.Slider
+	// flow is the average velocity per grid cell, drawn as arrows when widgetShowFlow is checked
+	flow           *FlowField
+	widgetShowFlow *ui.Checkbox
+	// widgetShowNav draws the navigation field of Config.NavField
+	widgetShowNav *ui.Checkbox
+	// leaders head to the mouse cursor when the config has a LeaderTeam but no LeaderPath
+	leaders *Leaders
+	// widgetShowPheromones draws the heatmap of the pheromones the world publishes to 'pheromones'
+	widgetShowPheromones *ui.Checkbox
+	pheromones           *Pheromones
+	// food are the food items the world publishes
+	food *Food
+	// powerUps are the pickups and the effects the world publishes
+	powerUps *PowerUps
+	// wind blows with the strength and the direction of the wind sliders, its force drawn as an arrow
+	wind *Wind
+	// scare follows the cursor while the key of ActionScare is held, drawn as a repulsor (see updateScare)
+	scare *Scare
+	// physics validates the kinetic energy and momentum of every snapshot, charted when widgetShowPhysics is checked
+	physics           *PhysicsHistory
+	widgetShowPhysics *ui.Checkbox
+	// camera zooms and pans the world layer, the minimap shows where it is when zoomed in
+	camera            *Camera
+	lastCursor        image.Point
+	minimap           minimap
+	minimapDragging   bool
+	widgetShowMinimap *ui.Checkbox
+	// layout anchors the overlays to the edges of the screen, which has the size of the window
+	layout *ui.Layout
+	// viewport is the visible area sent to the world, nil when the whole world is on screen
+	viewport *pb.SetViewport
 
 	// UI Controls
 	panel *ui.UIPanel
 
 	// Widget references for easy access
-	widgetDetectionRadius  *ui.Slider
-	widgetDefenseRadius    *ui.Slider
-	widgetContactRadius    *ui.Slider
-	widgetVisualRange      *ui.Slider
-	widgetProtectedRange   *ui.Slider
-	widgetMaxSpeed         *ui.Slider
-	widgetMinSpeed         *ui.Slider
-	widgetAggression       *ui.Slider
-	widgetCenteringFactor  *ui.Slider
-	widgetAvoidFactor      *ui.Slider
-	widgetMatchingFactor   *ui.Slider
-	widgetTurnFactor       *ui.Slider
+	widgetDetectionRadius *ui.Slider
+	widgetDefenseRadius   *ui.Slider
+	widgetContactRadius   *ui.Slider
+	widgetVisualRange     *ui.Slider
+	widgetProtectedRange  *ui.Slider
+	widgetMaxSpeed        *ui.Slider
+	widgetMinSpeed        *ui.Slider
+	widgetAggression      *ui.Slider
+	widgetCenteringFactor *ui.Slider
+	widgetAvoidFactor     *ui.Slider
+	widgetMatchingFactor  *ui.Slider
+	widgetTurnFactor      *ui.Slider
+	widgetWindStrength    *ui.Slider
+	widgetWindDirection   *ui.Slider
+	// The clicks spawn entities (the default), place attractors or repulsors, or select an entity
+	// in widgetClickMode (see mouse.go): the attractors with the strength and the radius of the
+	// sliders, listed by widgetAttractorList; a shift-click spawns widgetBurstSize entities
+	widgetClickMode         *ui.Dropdown
+	widgetBurstSize         *ui.Slider
+	widgetAttractorStrength *ui.Slider
+	widgetAttractorRadius   *ui.Slider
+	widgetAttractorList     *ui.Dropdown
+	attractors              *Attractors
+	// commands spawn the entities of the clicks, spawnRand scatters the bursts
+	commands  *CommandQueue
+	spawnRand *rand.Rand
+	// rightPress is where the right button was pressed, rightPending when it was pressed over the world
+	rightPress             image.Point
+	rightPending           bool
 	widgetNumRed           *ui.Slider
 	widgetNumBlue          *ui.Slider
+	widgetNumNeutral       *ui.Slider
+	widgetSeed             *ui.TextInput
+	widgetSpatialIndex     *ui.Dropdown
+	widgetDefenders        *ui.Slider
+	widgetDefenseCount     *ui.Dropdown
+	widgetDefenseFacing    *ui.Checkbox
+	widgetCombatRule       *ui.Dropdown
 	widgetDisplayDetection *ui.Checkbox
 	widgetDisplayDefense   *ui.Checkbox
 
@@ -62,6 +152,61 @@ type Game struct {
 	// Toggle button for panel
 	toggleButton *ui.Button
 
+	// Click-to-select entity inspector
+	inspector *Inspector
+
+	// Detachable floating windows drawn above the simulation
+	windows             []*ui.Window
+	inspectorWindow     *ui.Window
+	widgetDetachInspect *ui.Checkbox
+
+	// GIF recording of the world
+	capture gifCapture
+	// screenshotPending saves the next frame to a PNG, requested with the screenshot key or the Screenshot button,
+	// to screenshotPath when set (the stills of thumbnails) or to a timestamped file of the captures
+	screenshotPending  bool
+	screenshotPath     string
+	widgetScreenshotUI *ui.Checkbox
+	// thumbnails picks the snapshots saved as stills in the directory of runID, see Config.ThumbnailEvery
+	thumbnails thumbnailSchedule
+	runID      string
+
+	// Keyboard shortcuts (see keybindings.go), listed by the help overlay when showHelp is set
+	keyBindings []KeyBinding
+	keyActions  map[KeyAction]func()
+	showHelp    bool
+	// paused stops the ticks, speed is the index in gameSpeeds and tickBudget the ticks owed below x1
+	paused     bool
+	speed      int
+	tickBudget float64
+
+	// Strategy currently requested for each team
+	teamStrategies  map[pb.TeamColor]string
+	strategyButtons map[pb.TeamColor]*ui.Button
+
+	// Configs loaded by WatchConfig, applied on the game goroutine
+	reloadCh chan *Config
+
+	// Presets saved from the panel, presetName is the last one saved or loaded
+	presetName       string
+	loadPresetButton *ui.Button
+
+	// Macro recording and replay of the panel interactions (see macro.go)
+	macroRecorder     *MacroRecorder
+	macroPlayer       *MacroPlayer
+	macroName         string
+	recordMacroButton *ui.Button
+	replayMacroButton *ui.Button
+
+	// ticksSent counts the ticks sent to the current engine
+	ticksSent uint64
+	// clock ticks the engine when Config.SimRate is set, nil when every Update sends a tick
+	clock *SimClock
+	// interp smooths the snapshots of a clock ticking at another rate than the display,
+	// frame is the interpolated snapshot drawn (both nil without clock)
+	interp *Interpolator
+	frame  *pb.WorldSnapshot
+
 	// Restart flag
 	restartRequested bool
 
@@ -72,92 +217,300 @@ type Game struct {
 	drawAvg            float64 // Rolling average in ms
 }
 
-func GetNewGame(ctx context.Context, cfg *Config, system actor.ActorSystem) *Game {
+// applyTheme selects the theme of the config for the widgets created next (validated with the config),
+// its text and margins enlarged 'scale' times
+func applyTheme(cfg *Config, scale float64) {
+	if theme, err := cfg.UITheme(); err == nil {
+		theme.FontScale *= scale
+		theme.Padding *= scale
+		ui.SetTheme(theme)
+	}
+}
+
+// addParameterSlider adds the slider of one of the PanelParameters
+func addParameterSlider(panel *ui.UIPanel, cfg *Config, name string) *ui.Slider {
+	p := panelParameter(name)
+	slider := panel.AddSlider(p.Label, p.Description, p.Min, p.Max, panelValue(cfg, name))
+	slider.Step = p.Step
+	slider.Integer = p.Integer
+	return slider
+}
+
+// GetNewGame creates the Ebiten game and spawns the world with the given engine
+// (ActorEngine(system) by default, NewLocalEngine in the browser), unless cfg.Engine names another one.
+// Optional WorldOption values (e.g. WithTickHook) are kept and re-applied on every restart.
+func GetNewGame(ctx context.Context, cfg *Config, newEngine EngineFactory, opts ...WorldOption) *Game {
 	// 1. Create Channels for communication
 	snapshotCh := make(chan *pb.WorldSnapshot, 10) // Buffer to avoid blocking
+	snapshots := NewSnapshotPool()
+	events := NewEventFeed(eventFeedSize, EventConversion, EventDeath, EventGameOver)
+	summary := NewRunSummary()
+	effects := NewEffects()
+	sounds, soundErr := NewAudio(cfg)
+	memory := NewMemoryUsage()
+	leaders := &Leaders{}
+	pheromones := &Pheromones{}
+	food := &Food{}
+	powerUps := &PowerUps{}
+	wind := &Wind{}
+	attractors := &Attractors{}
+	scare := &Scare{}
+	commands := NewCommandQueue()
+	opts = append(opts[:len(opts):len(opts)], WithSnapshotPool(snapshots), WithEventSink(events), WithEventSink(summary), WithEventSink(effects.Sink),
+		WithEventSink(sounds.Sink), WithMemoryUsage(memory), WithLeaders(leaders),
+		WithPheromones(pheromones), WithFood(food), WithPowerUps(powerUps),
+		WithWind(wind))
+	if cfg.Engine != EngineECS {
+		// The ECS engine has no command queue, attractors nor scare: it cannot spawn the entities of
+		// the clicks, place the attractors (see useClick) nor scare the entities (see updateScare)
+		opts = append(opts, WithCommandQueue(commands), WithAttractors(attractors), WithScare(scare))
+	}
 
-	// 2. Spawn World Actor
+	// 2. Spawn World
 	// We pass the channel to the World so it can push updates to us.
-	// Note: NewWorldActor signature is (snapshotCh, cfg)
-	worldActor := NewWorldActor(snapshotCh, cfg)
-	worldPID, err := system.Spawn(ctx, "world", worldActor)
+	engine, err := SelectEngine(cfg, newEngine)(ctx, snapshotCh, cfg, opts...)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to spawn world: %v", err))
 	}
+	if soundErr != nil {
+		engine.Logger().Errorf("Audio: %v", soundErr)
+	}
+
+	// Validated with the config: an invalid name only comes from a config never validated
+	if err := checkScreenshotKey(cfg); err != nil {
+		panic(fmt.Sprintf("Invalid config: %v", err))
+	}
 
-	// 3. Initialize UI Panel with all configuration widgets
-	panel := ui.NewUIPanel(10, 10, 280, float64(cfg.WorldHeight)-20)
+	// 3. Initialize UI Panel with all configuration widgets, in the theme of the config, at the
+	// scale of the display: the screen has its pixels (see Layout)
+	scale := uiScale(cfg)
+	setOverlayScale(scale)
+	applyTheme(cfg, scale)
+	panel := ui.NewUIPanel(scaled(10), scaled(10), scaled(280), cfg.WorldHeight*deviceScale()-scaled(20))
 
 	// Add sections and widgets
 	panel.AddSection("Interaction Radii")
-	widgetDetectionRadius := panel.AddSlider("Detection Radius", 10, 300, cfg.DetectionRadius)
-	widgetDefenseRadius := panel.AddSlider("Defense Radius", 10, 300, cfg.DefenseRadius)
-	widgetContactRadius := panel.AddSlider("Contact Radius", 5, 50, cfg.ContactRadius)
-	widgetVisualRange := panel.AddSlider("Visual Range", 10, 150, cfg.VisualRange)
-	widgetProtectedRange := panel.AddSlider("Protected Range", 5, 50, cfg.ProtectedRange)
+	widgetDetectionRadius := addParameterSlider(panel, cfg, "detectionRadius")
+	widgetDefenseRadius := addParameterSlider(panel, cfg, "defenseRadius")
+	widgetContactRadius := addParameterSlider(panel, cfg, "contactRadius")
+	widgetVisualRange := addParameterSlider(panel, cfg, "visualRange")
+	widgetProtectedRange := addParameterSlider(panel, cfg, "protectedRange")
 	panel.EndSection()
 
 	panel.AddSection("Physics & Behavior")
-	widgetMaxSpeed := panel.AddSlider("Max Speed", 1, 10, cfg.MaxSpeed)
-	widgetMinSpeed := panel.AddSlider("Min Speed", 0.5, 8, cfg.MinSpeed)
-	widgetAggression := panel.AddSlider("Aggression", 0.1, 2.0, cfg.Aggression)
+	widgetMaxSpeed := addParameterSlider(panel, cfg, "maxSpeed")
+	widgetMinSpeed := addParameterSlider(panel, cfg, "minSpeed")
+	widgetAggression := addParameterSlider(panel, cfg, "aggression")
 	panel.EndSection()
 
 	panel.AddSection("Boids Flocking")
-	widgetCenteringFactor := panel.AddSlider("Centering Factor", 0.0001, 0.01, cfg.CenteringFactor)
-	widgetAvoidFactor := panel.AddSlider("Avoid Factor", 0.001, 0.2, cfg.AvoidFactor)
-	widgetMatchingFactor := panel.AddSlider("Matching Factor", 0.001, 0.2, cfg.MatchingFactor)
-	widgetTurnFactor := panel.AddSlider("Turn Factor", 0.05, 1.0, cfg.TurnFactor)
+	widgetCenteringFactor := addParameterSlider(panel, cfg, "centeringFactor")
+	widgetAvoidFactor := addParameterSlider(panel, cfg, "avoidFactor")
+	widgetMatchingFactor := addParameterSlider(panel, cfg, "matchingFactor")
+	widgetTurnFactor := addParameterSlider(panel, cfg, "turnFactor")
+	panel.EndSection()
+
+	panel.AddSection("Wind")
+	widgetWindStrength := addParameterSlider(panel, cfg, "windStrength")
+	widgetWindDirection := addParameterSlider(panel, cfg, "windDirection")
+	panel.EndSection()
+
+	panel.AddSection("Mouse")
+	widgetClickMode := panel.AddDropdown("Click", clickModes, defaultClickMode(cfg))
+	widgetBurstSize := panel.AddSlider("Burst Size", "Number of entities a shift-click spawns around the cursor.", 2, 50, defaultBurstSize)
+	widgetBurstSize.Integer = true
+	panel.EndSection()
+
+	panel.AddSection("Attractors")
+	widgetAttractorStrength := panel.AddSlider("Strength", "Pull (or push) of the next attractor at its center, fading to nothing at its radius.", 0.02, 0.5, defaultAttractorStrength)
+	widgetAttractorStrength.Step = 0.01
+	widgetAttractorRadius := panel.AddSlider("Radius", "Reach of the next attractor.", 20, 400, defaultAttractorRadius)
+	widgetAttractorRadius.Step = 10
+	widgetAttractorList := panel.AddDropdown("Placed", []string{noAttractor}, 0)
+	removeAttractorButton := panel.AddButton("Remove Selected", nil)
+	clearAttractorsButton := panel.AddButton("Clear All", nil)
+	panel.EndSection()
+
+	panel.AddSection("Team Strategies")
+	// Cycle buttons: callbacks are set after creating the game
+	redStrategyButton := panel.AddButton(strategyButtonLabel(pb.TeamColor_TEAM_RED, cfg.StrategyFor(pb.TeamColor_TEAM_RED)), nil)
+	blueStrategyButton := panel.AddButton(strategyButtonLabel(pb.TeamColor_TEAM_BLUE, cfg.StrategyFor(pb.TeamColor_TEAM_BLUE)), nil)
 	panel.EndSection()
 
 	panel.AddSection("Population (Restart Required)")
-	widgetNumRed := panel.AddSlider("Red Actors", 1, 300, float64(cfg.NumRedAtStart))
-	widgetNumBlue := panel.AddSlider("Blue Actors", 1, 1000, float64(cfg.NumBlueAtStart))
+	widgetNumRed := addParameterSlider(panel, cfg, "numRedAtStart")
+	widgetNumBlue := addParameterSlider(panel, cfg, "numBlueAtStart")
+	widgetNumNeutral := addParameterSlider(panel, cfg, "numNeutralAtStart")
+	widgetSeed := panel.AddTextInput("Seed (0 = random)", strconv.FormatUint(cfg.Seed, 10))
+	widgetSeed.Accept = ui.AcceptDigits
+	widgetSeed.MaxLen = 20
+	widgetSpatialIndex := panel.AddDropdown("Spatial Index", spatialIndexNames, 0)
+	widgetSpatialIndex.Select(cfg.SpatialIndex)
+	panel.EndSection()
+
+	panel.AddSection("Combat (Restart Required)")
+	widgetDefenders := addParameterSlider(panel, cfg, "defendersNeeded")
+	widgetDefenders.Value = float64(cfg.defendersNeeded())
+	widgetDefenseCount := panel.AddDropdown("Defense Count", defenseCountNames, 0)
+	widgetDefenseCount.Select(cfg.DefenseCount)
+	widgetDefenseFacing := panel.AddCheckbox("Defenders Must Face", "Only the blues heading toward the attacker defend the victim.", cfg.DefenseFacing)
+	widgetCombatRule := panel.AddDropdown("Combat Rule", CombatRuleNames(), 0)
+	widgetCombatRule.Select(cfg.CombatRule)
 	panel.EndSection()
 
 	panel.AddSection("Visualization")
-	widgetDisplayDetection := panel.AddCheckbox("Show Detection Circle", cfg.DisplayDetectionCircle)
-	widgetDisplayDefense := panel.AddCheckbox("Show Defense Circle", cfg.DisplayDefenseCircle)
+	widgetDisplayDetection := panel.AddCheckbox("Show Detection Circle", "Draws the detection radius around every red.", cfg.DisplayDetectionCircle)
+	widgetDisplayDefense := panel.AddCheckbox("Show Defense Circle", "Draws the defense radius around every blue.", cfg.DisplayDefenseCircle)
+	widgetDetachInspect := panel.AddCheckbox("Detach Inspector Window", "Shows the inspected entity in a separate window instead of over the world.", false)
+	widgetShowChart := panel.AddCheckbox("Show Population Chart", "Red and blue populations over the last minute (C expands it).", true)
+	widgetShowFeed := panel.AddCheckbox("Show Event Feed", "Lists the last conversions, defenses, spawns and deaths.", true)
+	widgetShowEffects := panel.AddCheckbox("Show Conversion Effects", "A burst of particles where an entity switched team.", true)
+	widgetShowFlow := panel.AddCheckbox("Show Flow Field", "Arrows showing the mean direction of the entities in each area.", false)
+	widgetShowNav := panel.AddCheckbox("Show Navigation Field", "Arrows showing where the navigation field (--nav-field) leads in each area.", cfg.NavField != "")
+	widgetShowPheromones := panel.AddCheckbox("Show Pheromones", "Heatmap of the pheromones (--pheromones): the danger left by the reds, the scent of the blues.", cfg.Pheromones)
+	widgetShowPhysics := panel.AddCheckbox("Show Physics Validation", "Chart of the energy and momentum of the flock: a sudden jump reveals a numerical problem.", cfg.PhysicsFile != "")
+	widgetShowMinimap := panel.AddCheckbox("Show Minimap", "Overview of the whole world, click it to move the camera.", true)
+	widgetShowMemory := panel.AddCheckbox("Show Memory Usage", "Memory used by the entities, the spatial index and the snapshots.", false)
 	panel.EndSection()
 
+	panel.AddSection("Audio")
+	widgetSound := panel.AddCheckbox("Sound", "Plays a sound on every conversion.", cfg.Sound)
+	widgetVolume := panel.AddSlider("Volume", "Volume of the sound effects.", 0, 1, soundVolume(cfg))
+	widgetVolume.Step = 0.05
+	panel.EndSection()
+
+	// No file system in the browser
+	var recordGIFButton, gifRegionButton, savePresetButton, loadPresetButton, recordMacroButton, replayMacroButton *ui.Button
+	var widgetGIFFollow, widgetScreenshotUI *ui.Checkbox
+	var screenshotButton *ui.Button
+	if canWriteFiles {
+		panel.AddSection("Capture")
+		screenshotButton = panel.AddButton(fmt.Sprintf("Screenshot (%s)", screenshotKeyName(cfg)), nil)
+		widgetScreenshotUI = panel.AddCheckbox("Screenshot Includes UI", "Keeps the panel and the charts in the screenshots.", cfg.ScreenshotUI)
+		recordGIFButton = panel.AddButton(recordButtonLabel(cfg), nil)
+		gifRegionButton = panel.AddButton("GIF Region: full screen", nil)
+		widgetGIFFollow = panel.AddCheckbox("GIF Region Follows Selection", "The recorded region moves with the inspected entity.", false)
+		panel.EndSection()
+
+		panel.AddSection("Presets")
+		savePresetButton = panel.AddButton("Save Preset", nil)
+		loadPresetButton = panel.AddButton("Load Preset", nil)
+		panel.EndSection()
+
+		panel.AddSection("Macros")
+		recordMacroButton = panel.AddButton("Record Macro", nil)
+		replayMacroButton = panel.AddButton("Replay Macro", nil)
+		panel.EndSection()
+	}
+
 	panel.AddSection("Actions")
 	// We'll set the onclick callback after creating the game
 	restartButton := panel.AddButton("Restart Simulation", nil)
 	panel.EndSection()
 
 	// Create toggle button (positioned at top-left when panel is hidden)
-	toggleButton := ui.NewButton(10, 10, 120, 35, "⚙ Settings", nil)
+	toggleButton := ui.NewButton(scaled(10), scaled(10), scaled(120), scaled(35), "≡ Settings", nil)
 
 	game := &Game{
-		ctx:                    ctx,
-		System:                 system,
-		worldPID:               worldPID,
-		snapshotCh:             snapshotCh,
-		lastState:              &pb.WorldSnapshot{}, // Avoid nil pointer
-		trails:                 make(map[string][]geometry.Vector2D),
-		panel:                  panel,
-		widgetDetectionRadius:  widgetDetectionRadius,
-		widgetDefenseRadius:    widgetDefenseRadius,
-		widgetContactRadius:    widgetContactRadius,
-		widgetVisualRange:      widgetVisualRange,
-		widgetProtectedRange:   widgetProtectedRange,
-		widgetMaxSpeed:         widgetMaxSpeed,
-		widgetMinSpeed:         widgetMinSpeed,
-		widgetAggression:       widgetAggression,
-		widgetCenteringFactor:  widgetCenteringFactor,
-		widgetAvoidFactor:      widgetAvoidFactor,
-		widgetMatchingFactor:   widgetMatchingFactor,
-		widgetTurnFactor:       widgetTurnFactor,
-		widgetNumRed:           widgetNumRed,
-		widgetNumBlue:          widgetNumBlue,
-		widgetDisplayDetection: widgetDisplayDetection,
-		widgetDisplayDefense:   widgetDisplayDefense,
-		toggleButton:           toggleButton,
-		restartRequested:       false,
-		cfg:                    cfg,
+		ctx:                     ctx,
+		engine:                  engine,
+		newEngine:               newEngine,
+		snapshotCh:              snapshotCh,
+		worldOpts:               opts,
+		snapshots:               snapshots,
+		lastState:               &pb.WorldSnapshot{}, // Avoid nil pointer
+		trails:                  NewTrails(DefaultMaxTrailPoints),
+		thumbnails:              newThumbnailSchedule(cfg),
+		runID:                   NewRunID(cfg.Seed),
+		history:                 NewPopulationHistory(DefaultHistoryTicks),
+		widgetShowChart:         widgetShowChart,
+		events:                  events,
+		summary:                 summary,
+		tournament:              NewTournament(cfg.Rounds),
+		widgetShowFeed:          widgetShowFeed,
+		effects:                 effects,
+		widgetShowEffects:       widgetShowEffects,
+		memory:                  memoryHUD{usage: memory},
+		widgetShowMemory:        widgetShowMemory,
+		audio:                   sounds,
+		widgetSound:             widgetSound,
+		widgetVolume:            widgetVolume,
+		flow:                    &FlowField{},
+		widgetShowFlow:          widgetShowFlow,
+		widgetShowNav:           widgetShowNav,
+		leaders:                 leaders,
+		widgetShowPheromones:    widgetShowPheromones,
+		pheromones:              pheromones,
+		food:                    food,
+		powerUps:                powerUps,
+		wind:                    wind,
+		scare:                   scare,
+		physics:                 NewPhysicsHistory(DefaultHistoryTicks),
+		widgetShowPhysics:       widgetShowPhysics,
+		camera:                  NewCamera(cfg.WorldWidth, cfg.WorldHeight, cfg.WorldWidth, cfg.WorldHeight),
+		layout:                  ui.NewLayout(cfg.WorldWidth, cfg.WorldHeight, scaled(overlayMargin)),
+		widgetShowMinimap:       widgetShowMinimap,
+		panel:                   panel,
+		widgetDetectionRadius:   widgetDetectionRadius,
+		widgetDefenseRadius:     widgetDefenseRadius,
+		widgetContactRadius:     widgetContactRadius,
+		widgetVisualRange:       widgetVisualRange,
+		widgetProtectedRange:    widgetProtectedRange,
+		widgetMaxSpeed:          widgetMaxSpeed,
+		widgetMinSpeed:          widgetMinSpeed,
+		widgetAggression:        widgetAggression,
+		widgetCenteringFactor:   widgetCenteringFactor,
+		widgetAvoidFactor:       widgetAvoidFactor,
+		widgetMatchingFactor:    widgetMatchingFactor,
+		widgetTurnFactor:        widgetTurnFactor,
+		widgetWindStrength:      widgetWindStrength,
+		widgetWindDirection:     widgetWindDirection,
+		widgetClickMode:         widgetClickMode,
+		widgetBurstSize:         widgetBurstSize,
+		widgetAttractorStrength: widgetAttractorStrength,
+		widgetAttractorRadius:   widgetAttractorRadius,
+		widgetAttractorList:     widgetAttractorList,
+		attractors:              attractors,
+		commands:                commands,
+		spawnRand:               rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		widgetNumRed:            widgetNumRed,
+		widgetNumBlue:           widgetNumBlue,
+		widgetNumNeutral:        widgetNumNeutral,
+		widgetSeed:              widgetSeed,
+		widgetSpatialIndex:      widgetSpatialIndex,
+		widgetDefenders:         widgetDefenders,
+		widgetDefenseCount:      widgetDefenseCount,
+		widgetDefenseFacing:     widgetDefenseFacing,
+		widgetCombatRule:        widgetCombatRule,
+		widgetDisplayDetection:  widgetDisplayDetection,
+		widgetDisplayDefense:    widgetDisplayDefense,
+		toggleButton:            toggleButton,
+		inspector:               NewInspector(),
+		widgetDetachInspect:     widgetDetachInspect,
+		keyBindings:             KeyBindings(cfg),
+		speed:                   defaultGameSpeed,
+		widgetScreenshotUI:      widgetScreenshotUI,
+		capture: gifCapture{
+			recordButton: recordGIFButton,
+			regionButton: gifRegionButton,
+			followWidget: widgetGIFFollow,
+		},
+		teamStrategies: map[pb.TeamColor]string{
+			pb.TeamColor_TEAM_RED:  cfg.StrategyFor(pb.TeamColor_TEAM_RED),
+			pb.TeamColor_TEAM_BLUE: cfg.StrategyFor(pb.TeamColor_TEAM_BLUE),
+		},
+		strategyButtons: map[pb.TeamColor]*ui.Button{
+			pb.TeamColor_TEAM_RED:  redStrategyButton,
+			pb.TeamColor_TEAM_BLUE: blueStrategyButton,
+		},
+		reloadCh:         make(chan *Config, 1),
+		restartRequested: false,
+		cfg:              cfg,
 	}
 
 	// Set up callbacks now that game exists
+	game.keyActions = game.keyActionFuncs()
+	game.summaryScreen = newSummaryScreen(game)
 	restartButton.OnClick = func() {
 		game.restartRequested = true
 	}
@@ -166,6 +519,47 @@ func GetNewGame(ctx context.Context, cfg *Config, system actor.ActorSystem) *Gam
 		game.panel.Toggle()
 	}
 
+	// The screen shows the whole world: trails leaving it are evicted first
+	game.trails.SetViewport(0, 0, cfg.WorldWidth, cfg.WorldHeight)
+
+	// Floating windows
+	game.inspectorWindow = ui.NewWindow("Inspector", cfg.WorldWidth*deviceScale()-scaled(220), scaled(150), scaled(200), scaled(150), func(canvas *ebiten.Image) {
+		game.inspector.DrawDetached(canvas, game)
+	})
+	game.inspectorWindow.Visible = false
+	game.windows = append(game.windows, game.inspectorWindow)
+
+	if canWriteFiles {
+		screenshotButton.OnClick = game.requestScreenshot
+		recordGIFButton.OnClick = game.toggleGIFRecording
+		gifRegionButton.OnClick = game.cycleGIFRegion
+		savePresetButton.OnClick = game.savePreset
+		loadPresetButton.OnClick = game.loadNextPreset
+		game.loadPresetButton = loadPresetButton
+		recordMacroButton.OnClick = game.toggleMacroRecording
+		replayMacroButton.OnClick = game.replayNextMacro
+		game.recordMacroButton = recordMacroButton
+		game.replayMacroButton = replayMacroButton
+	}
+
+	removeAttractorButton.OnClick = func() {
+		if game.attractors.Remove(widgetAttractorList.Selected) {
+			game.refreshAttractorList()
+		}
+	}
+	clearAttractorsButton.OnClick = func() {
+		game.attractors.Clear()
+		game.refreshAttractorList()
+	}
+	redStrategyButton.OnClick = func() {
+		game.cycleStrategy(pb.TeamColor_TEAM_RED, redStrategyButton)
+	}
+	blueStrategyButton.OnClick = func() {
+		game.cycleStrategy(pb.TeamColor_TEAM_BLUE, blueStrategyButton)
+	}
+
+	game.startClock()
+
 	return game
 }
 
@@ -184,6 +578,41 @@ func (g *Game) Update() error {
 	if g.panel.IsCollapsed && g.panel.X == g.panel.TargetX {
 		g.toggleButton.Update()
 	}
+	g.updateSummary()
+
+	// Floating windows get the mouse first (topmost is last)
+	windowCaptured := false
+	g.syncDetachedWindows()
+	for idx := len(g.windows) - 1; idx >= 0; idx-- {
+		if g.windows[idx].Update() {
+			windowCaptured = true
+			break
+		}
+	}
+	if !g.inspectorWindow.Visible {
+		// Closed with its close box
+		g.widgetDetachInspect.Value = false
+	}
+	g.inspector.Detached = g.inspectorWindow.Visible
+
+	// GIF region selection, then entity selection (ignore clicks landing on the UI)
+	overUI := windowCaptured || g.isCursorOverUI()
+	captureUsed := g.updateCapture(overUI)
+	cameraUsed := g.updateCamera(overUI || captureUsed || g.capture.selecting)
+	clicked := g.useClick(overUI || captureUsed || cameraUsed || g.capture.selecting)
+	g.inspector.Update(g, overUI || captureUsed || cameraUsed || g.capture.selecting || clicked)
+	g.steerLeaders(overUI)
+	g.updateScare(overUI)
+
+	// Config file edited on disk
+	select {
+	case cfg := <-g.reloadCh:
+		g.applyConfig(cfg)
+		g.engine.Logger().Info("Config reloaded")
+	default:
+	}
+
+	g.handleKeys()
 
 	// Check for restart request
 	if g.restartRequested {
@@ -194,16 +623,40 @@ func (g *Game) Update() error {
 	// 2. Retrieve Latest State (Non-blocking) EARLY, so we can check IsGameOver before ticking
 	select {
 	case snap := <-g.snapshotCh:
+		if g.interp != nil {
+			// The interpolator keeps the previous snapshots: they are not recycled
+			g.interp.Push(snap, time.Now())
+		} else {
+			// Nothing keeps a reference to the previous snapshot past this point
+			g.snapshots.Put(g.lastState)
+			g.trails.Update(snap)
+		}
 		g.lastState = snap
-		g.updateTrails(snap)
+		g.scheduleThumbnail(snap)
+		g.history.Add(snap)
+		g.summary.Observe(snap)
+		g.physics.Add(snap, g.cfg.MaxSpeed)
 	default:
 		// Use previous state if new one isn't ready
 	}
+	g.updateTournament()
+	if g.interp != nil {
+		if frame := g.interp.Frame(time.Now()); frame != nil {
+			g.frame = frame
+			g.trails.Update(frame)
+		}
+	}
+	// The particles live in wall time, they keep fading while the game is over
+	g.effects.Update(1/float64(ebiten.TPS()), g.widgetShowEffects.Value)
+	g.audio.Update(g.widgetSound.Value, g.widgetVolume.Value)
+	g.updateMemory()
 	// ONLY send a Tick if the game is NOT over.
 	// This effectively "freezes" the simulation in the final state.
 	if !g.lastState.IsGameOver {
+		g.updateMacro()
+
 		// Send all updated configuration values to the world
-		actor.Tell(g.ctx, g.worldPID, &pb.UpdateConfig{
+		_ = g.engine.Send(g.ctx, &pb.UpdateConfig{
 			DetectionRadius:        g.widgetDetectionRadius.Value,
 			DefenseRadius:          g.widgetDefenseRadius.Value,
 			ContactRadius:          g.widgetContactRadius.Value,
@@ -221,9 +674,18 @@ func (g *Game) Update() error {
 			DisplayDetectionCircle: g.widgetDisplayDetection.Value,
 			DisplayDefenseCircle:   g.widgetDisplayDefense.Value,
 		})
+		g.wind.Set(g.widgetWindStrength.Value, g.widgetWindDirection.Value)
 
-		// Trigger Simulation Step
-		actor.Tell(g.ctx, g.worldPID, &pb.Tick{})
+		// Trigger Simulation Step, one tick lasts 1/TPS of simulation time whatever the speed
+		if g.clock == nil && !g.paused {
+			for range g.ticksDue() {
+				_ = g.engine.Send(g.ctx, &pb.Tick{DeltaTime: int64(time.Second) / int64(ebiten.TPS())})
+				g.ticksSent++
+			}
+		}
+	}
+	if g.clock != nil {
+		g.clock.SetPaused(g.lastState.IsGameOver || g.paused)
 	}
 
 	return nil
@@ -236,152 +698,73 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.drawAvg = g.drawAvg*0.95 + float64(g.lastDrawDuration.Microseconds())/1000.0*0.05
 	}()
 
-	// 1. Draw all actors from the last known snapshot
-	if g.lastState != nil {
-		for _, entity := range g.lastState.Actors {
-			if entity.Color == pb.TeamColor_TEAM_RED {
-				if drawTrails {
-					// --- 1. NEW: Draw Glowing Trail ---
-					if trace, ok := g.trails[entity.Id]; ok && len(trace) > 1 {
-						for i, pos := range trace {
-							// Calculate progress (0.0 = tail, 1.0 = engine)
-							p := float64(i) / float64(len(trace))
-
-							// Size varies: 0 at tail, 6 at engine
-							radius := float32(3.0 * p)
-
-							// Color Logic: Fire Gradient
-							// Tail is Red/Transparent, Head is Yellow/White
-							var r, gr, b, a uint8
-							if p > 0.8 {
-								// Core (White/Yellow)
-								r, gr, b, a = 255, 255, 100, 200
-							} else if p > 0.5 {
-								// Middle (Orange)
-								r, gr, b, a = 255, 140, 0, 150
-							} else {
-								// Tail (Red fading out)
-								r, gr, b, a = 255, 0, 0, uint8(100*p)
-							}
-
-							// Draw the puff
-							vector.FillCircle(screen, float32(pos.X), float32(pos.Y), radius, color.RGBA{R: r, G: gr, B: b, A: a}, true)
-						}
-					}
-				} else {
-					// --- 1. OPTIMIZED: Draw Glowing Trail (Sprite Batching) ---
-					if trace, ok := g.trails[entity.Id]; ok && len(trace) > 1 {
-						// Re-use a single Options struct to avoid allocation overhead in the loop
-						trailOp := &ebiten.DrawImageOptions{}
-
-						// Center of the 8x8 sprite
-						originOffset := 4.0
-
-						for i, pos := range trace {
-							// Progress: 0.0 (Tail) -> 1.0 (Engine)
-							p := float64(i) / float64(len(trace))
-
-							// Skip the very tail if it's too faint
-							if p < 0.2 {
-								continue
-							}
-
-							trailOp.GeoM.Reset()
-							trailOp.ColorScale.Reset()
-
-							// 1. Scale:
-							// Start small (0.5), grow to 1.5 at the engine
-							scale := 0.5 + p
-							trailOp.GeoM.Translate(-originOffset, -originOffset) // Center pivot
-							trailOp.GeoM.Scale(scale, scale)
-							trailOp.GeoM.Translate(pos.X, pos.Y) // Move to world position
-
-							// 2. Color Logic (Fire Gradient):
-							// We use ColorScale to tint the white sprite.
-							// High alpha at head, fading to 0 at tail.
-							alpha := float32(p * 0.8) // Max opacity 0.8
-
-							if p > 0.8 {
-								// White/Yellow Core
-								trailOp.ColorScale.Scale(1, 1, 0.5, alpha)
-							} else if p > 0.5 {
-								// Orange Body
-								trailOp.ColorScale.Scale(1, 0.5, 0, alpha)
-							} else {
-								// Red/Smoke Tail
-								trailOp.ColorScale.Scale(0.8, 0, 0, alpha)
-							}
-
-							screen.DrawImage(trailSprite, trailOp)
-						}
-					}
-				}
-
-				// --- 2. Existing Detection Circle (Keep this) ---
-				if g.widgetDisplayDetection.Value {
-					clr := color.RGBA{R: 255, G: 50, B: 50, A: 255}
-					vector.StrokeCircle(
-						screen,
-						float32(entity.Position.X),
-						float32(entity.Position.Y),
-						float32(g.widgetDetectionRadius.Value),
-						1,
-						clr,
-						true,
-					)
-				}
-				// Use pre-rendered sprite (batched by Ebiten automatically)
-				op := &ebiten.DrawImageOptions{}
-				// Center the origin of the image (assuming 16x16 sprite)
-				w, h := redSpaceship.Bounds().Dx(), redSpaceship.Bounds().Dy()
-				op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
-
-				// Rotate to match velocity
-				// Note: The sprite should be drawn facing "Right" (0 radians) by default.
-				// Since my ASCII art is a saucer facing "Up", we add math.Pi/2 (90 deg)
-				// to align the top of the saucer with the movement vector.
-				angle := math.Atan2(entity.Velocity.Y, entity.Velocity.X)
-				op.GeoM.Rotate(angle + math.Pi/2)
-
-				// Move to actual position in world
-				op.GeoM.Translate(entity.Position.X, entity.Position.Y)
-
-				screen.DrawImage(redSpaceship, op)
-			} else {
-				// --- BLUE BOIDS (The Arrow Jets) ---
-				// Optional: Draw Defense Radius ring
-				if g.widgetDisplayDefense.Value {
-					clr := color.RGBA{R: 50, G: 100, B: 255, A: 50}
-					vector.StrokeCircle(
-						screen,
-						float32(entity.Position.X),
-						float32(entity.Position.Y),
-						float32(g.widgetDefenseRadius.Value),
-						1,
-						clr,
-						true,
-					)
-				}
-				// 2. Draw the Blue Sprite
-				op := &ebiten.DrawImageOptions{}
-
-				// Center the sprite
-				w, h := blueSpaceship.Bounds().Dx(), blueSpaceship.Bounds().Dy()
-				op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
-
-				// Rotation:
-				// Align the "Up" facing sprite with the velocity vector
-				angle := math.Atan2(entity.Velocity.Y, entity.Velocity.X)
-				op.GeoM.Rotate(angle + math.Pi/2)
-
-				// Position
-				op.GeoM.Translate(entity.Position.X, entity.Position.Y)
-
-				screen.DrawImage(blueSpaceship, op)
-			}
-		}
+	// 1. Draw all actors
+	g.drawWorldLayer(screen)
+
+	// Record the world before any overlay is drawn
+	g.captureFrame(screen)
+	g.takePendingScreenshot(screen)
+
+	g.drawOverlays(screen)
+	g.drawCaptureOverlay(screen)
+}
+
+// drawWorldLayer draws the actors from the last known snapshot, or between the last ones with a clock
+func (g *Game) drawWorldLayer(screen *ebiten.Image) {
+	state := g.lastState
+	if g.frame != nil {
+		state = g.frame
+	}
+	var danger, scent *behavior.Pheromone
+	if g.widgetShowPheromones.Value {
+		danger, scent = g.pheromones.Fields()
+	}
+	DrawWorld(throughCamera(ebitenRenderer{screen}, g.camera), state, g.trails, WorldDrawOptions{
+		ShowDetection:   g.widgetDisplayDetection.Value,
+		DetectionRadius: g.widgetDetectionRadius.Value,
+		ShowDefense:     g.widgetDisplayDefense.Value,
+		DefenseRadius:   g.widgetDefenseRadius.Value,
+		FlowField:       g.flowField(),
+		Obstacles:       g.cfg.ObstacleList(),
+		Terrain:         g.cfg.TerrainMap(),
+		NavField:        g.navField(),
+		Leaders:         g.leaders.IDs(),
+		LeaderPath:      g.leaderPath(),
+		Danger:          danger,
+		Scent:           scent,
+		Food:            g.food.Items(),
+		PowerUps:        g.powerUps,
+		Attractors:      g.attractors.List(),
+	})
+	drawWind(throughCamera(ebitenRenderer{screen}, g.camera), g.wind.Force(), g.cfg.WorldWidth)
+	if scare, ok := g.scare.Repulsor(); ok {
+		drawAttractors(throughCamera(ebitenRenderer{screen}, g.camera), []Attractor{scare})
+	}
+	g.effects.Draw(throughCamera(ebitenRenderer{screen}, g.camera))
+	drawLetterbox(screen, g.camera)
+}
 
+// drawLetterbox fills the bars around the world when the window does not have its aspect ratio
+func drawLetterbox(screen *ebiten.Image, camera *Camera) {
+	x, y := camera.Letterbox()
+	w, h := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+	bx, by := float32(x), float32(y)
+	if bx > 0 {
+		vector.FillRect(screen, 0, 0, bx, h, letterboxColor, false)
+		vector.FillRect(screen, w-bx, 0, bx, h, letterboxColor, false)
 	}
+	if by > 0 {
+		vector.FillRect(screen, 0, 0, w, by, letterboxColor, false)
+		vector.FillRect(screen, 0, h-by, w, by, letterboxColor, false)
+	}
+}
+
+// drawOverlays draws everything above the world: inspector, UI panel, charts, windows and stats
+func (g *Game) drawOverlays(screen *ebiten.Image) {
+	g.layout.Reset(float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy()))
+
+	// Selected entity info box
+	g.inspector.Draw(screen, g)
 
 	// 2. Draw UI Panel
 	g.panel.Draw(screen)
@@ -389,42 +772,508 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	// Draw toggle button when panel is hidden
 	if g.panel.IsCollapsed {
 		g.toggleButton.Draw(screen)
-		ebitenutil.DebugPrintAt(screen, g.toggleButton.Label,
-			int(g.toggleButton.X+15), int(g.toggleButton.Y+12))
+		ui.DrawText(screen, g.toggleButton.Label,
+			int(g.toggleButton.X+scaled(15)), int(g.toggleButton.Y+scaled(12)), g.toggleButton.TextColor)
 	}
 
-	// 3. Draw the New Stats Bar
+	// 3. Right side, from the top: population bar, performance stats, memory and minimap
 	g.drawStatsBar(screen)
+	g.drawPerformanceStats(screen)
+	g.drawMemoryHUD(screen)
+	g.drawMinimap(screen)
+	// From the bottom: population chart and event feed on the right, physics in the middle
+	g.drawPopulationCharts(screen)
+	g.drawPhysicsValidation(screen)
+	g.drawEventFeed(screen)
+	g.drawScoreboard(screen)
+
+	// Floating windows on top of everything
+	for _, w := range g.windows {
+		w.Draw(screen)
+	}
 
 	// 4. Draw Game Over Overlay
-	if g.lastState.IsGameOver {
-		// Simple centered text
-		msg := fmt.Sprintf("GAME OVER\n%s is the WINNER !", g.lastState.Winner)
-		// You can use basic printing or fancy vector text here
-		ebitenutil.DebugPrintAt(screen, msg, int(g.cfg.WorldWidth/2-40), int(g.cfg.WorldHeight/2))
+	g.drawSummary(screen)
+	g.drawHelp(screen)
+}
+
+// roundPause is how long the summary of a round of the tournament stays before the next round starts
+const roundPause = 5 * time.Second
+
+// updateTournament records the round at its game over, then restarts the next one after roundPause,
+// with the next seed when the seed is fixed. The summary stays after the last round.
+func (g *Game) updateTournament() {
+	t := g.tournament
+	if t == nil || !g.lastState.GetIsGameOver() {
+		return
 	}
+	if g.roundOver.IsZero() {
+		g.roundOver = time.Now()
+		t.Record(g.summary.Stats())
+		g.engine.Logger().Infof("Round %d/%d won by %s", t.Played(), t.Rounds, g.lastState.GetWinner())
+		return
+	}
+	if t.Done() || time.Since(g.roundOver) < roundPause {
+		return
+	}
+	if g.cfg.Seed != 0 {
+		g.widgetSeed.Text = strconv.FormatUint(g.cfg.Seed+1, 10)
+	}
+	g.restartRequested = true
+}
 
-	// Display timing breakdown for performance analysis
-	// Display performance stats (moved to right side to avoid overlap with panel)
-	msg := fmt.Sprintf("FPS: %.2f\nTPS: %.2f\n\nUpdate: %.2fms\nDraw:   %.2fms\nTotal:  %.2fms",
+// drawScoreboard shows the round and the score of the tournament at the top of the screen
+func (g *Game) drawScoreboard(screen *ebiten.Image) {
+	if g.tournament == nil {
+		return
+	}
+	placeOverlay(screen, g.layout, ui.AnchorTop, g.tournament.Scoreboard())
+}
+
+// drawPerformanceStats displays the timing breakdown for performance analysis, on the right side
+func (g *Game) drawPerformanceStats(screen *ebiten.Image) {
+	msg := fmt.Sprintf("FPS: %.2f\nTPS: %.2f\n\nUpdate: %.2fms\nDraw:   %.2fms\nTotal:  %.2fms\nTrails: %d/%d pts\n%s  H: help",
 		ebiten.ActualFPS(),
 		ebiten.ActualTPS(),
 		g.updateAvg,
 		g.drawAvg,
-		g.updateAvg+g.drawAvg)
-	// Print stats on the right side
-	ebitenutil.DebugPrintAt(screen, msg, int(g.cfg.WorldWidth)-150, 50)
+		g.updateAvg+g.drawAvg,
+		g.trails.Points(), DefaultMaxTrailPoints,
+		g.speedLabel())
+	placeOverlay(screen, g.layout, ui.AnchorTopRight, msg)
+}
+
+// ReloadConfig asks the game to apply a new config at the next frame, it is safe to call
+// from any goroutine (e.g. the onChange callback of WatchConfig). Only the last pending config is kept.
+func (g *Game) ReloadConfig(cfg *Config) {
+	for {
+		select {
+		case g.reloadCh <- cfg:
+			return
+		default:
+		}
+		// Replace the config still pending
+		select {
+		case <-g.reloadCh:
+		default:
+		}
+	}
+}
+
+// readWidgets copies the current widget values into 'cfg'
+func (g *Game) readWidgets(cfg *Config) {
+	cfg.DetectionRadius = g.widgetDetectionRadius.Value
+	cfg.DefenseRadius = g.widgetDefenseRadius.Value
+	cfg.ContactRadius = g.widgetContactRadius.Value
+	cfg.VisualRange = g.widgetVisualRange.Value
+	cfg.ProtectedRange = g.widgetProtectedRange.Value
+	cfg.MaxSpeed = g.widgetMaxSpeed.Value
+	cfg.MinSpeed = g.widgetMinSpeed.Value
+	cfg.Aggression = g.widgetAggression.Value
+	cfg.CenteringFactor = g.widgetCenteringFactor.Value
+	cfg.AvoidFactor = g.widgetAvoidFactor.Value
+	cfg.MatchingFactor = g.widgetMatchingFactor.Value
+	cfg.TurnFactor = g.widgetTurnFactor.Value
+	cfg.WindStrength = g.widgetWindStrength.Value
+	cfg.WindDirection = g.widgetWindDirection.Value
+	cfg.NumRedAtStart = int(g.widgetNumRed.Value)
+	cfg.NumBlueAtStart = int(g.widgetNumBlue.Value)
+	cfg.NumNeutralAtStart = int(g.widgetNumNeutral.Value)
+	// Only digits can be typed, an empty seed keeps the current one
+	if seed, err := strconv.ParseUint(g.widgetSeed.Text, 10, 64); err == nil {
+		cfg.Seed = seed
+	}
+	cfg.SpatialIndex = g.widgetSpatialIndex.Value()
+	cfg.DefendersNeeded = int(g.widgetDefenders.Value)
+	cfg.DefenseCount = g.widgetDefenseCount.Value()
+	cfg.DefenseFacing = g.widgetDefenseFacing.Value
+	cfg.CombatRule = g.widgetCombatRule.Value()
+	cfg.DisplayDetectionCircle = g.widgetDisplayDetection.Value
+	cfg.DisplayDefenseCircle = g.widgetDisplayDefense.Value
+	cfg.Sound = g.widgetSound.Value
+	cfg.SoundVolume = g.widgetVolume.Value
+}
+
+// currentPreset returns the current widget values and team strategies
+func (g *Game) currentPreset(name string) Preset {
+	cfg := *g.cfg
+	g.readWidgets(&cfg)
+	cfg.RedStrategy = g.teamStrategies[pb.TeamColor_TEAM_RED]
+	cfg.BlueStrategy = g.teamStrategies[pb.TeamColor_TEAM_BLUE]
+	return NewPreset(name, &cfg)
+}
+
+// savePreset writes the current widget values and team strategies as a new preset
+func (g *Game) savePreset() {
+	name := NextPresetName(PresetsDir)
+	path, err := SavePreset(PresetsDir, g.currentPreset(name))
+	if err != nil {
+		g.engine.Logger().Errorf("Cannot save preset: %v", err)
+		return
+	}
+	g.presetName = name
+	g.engine.Logger().Infof("Preset saved to %s", path)
+}
+
+// loadNextPreset loads the preset following the current one (in name order) into the widgets
+func (g *Game) loadNextPreset() {
+	names, err := ListPresets(PresetsDir)
+	if err != nil || len(names) == 0 {
+		g.engine.Logger().Infof("No preset to load in %s/", PresetsDir)
+		return
+	}
+	next := names[0]
+	for idx, name := range names {
+		if name == g.presetName {
+			next = names[(idx+1)%len(names)]
+			break
+		}
+	}
+	preset, err := LoadPreset(PresetsDir, next)
+	if err != nil {
+		g.engine.Logger().Errorf("Cannot load preset: %v", err)
+		return
+	}
+	cfg := *g.cfg
+	if err := preset.Apply(&cfg); err != nil {
+		g.engine.Logger().Errorf("Cannot load preset: %v", err)
+		return
+	}
+	g.applyConfig(&cfg)
+	_ = g.engine.Send(g.ctx, cfg.UpdateMessage())
+	g.presetName = next
+	g.loadPresetButton.Label = "Load Preset: " + next
+	g.engine.Logger().Infof("Preset %s loaded", next)
+}
+
+// toggleMacroRecording restarts the simulation and records the panel interactions,
+// or stops the recording and saves it
+func (g *Game) toggleMacroRecording() {
+	if g.macroRecorder != nil {
+		g.stopMacro()
+		return
+	}
+	// A macro replays against the same seed
+	if g.cfg.Seed == 0 {
+		g.cfg.Seed = rand.Uint64()
+		g.widgetSeed.Text = strconv.FormatUint(g.cfg.Seed, 10)
+	}
+	g.restartSimulation()
+	g.macroRecorder = NewMacroRecorder(NextMacroName(MacrosDir), g.cfg.Seed, g.currentPreset(""))
+	g.recordMacroButton.Label = "Stop Recording Macro"
+	g.engine.Logger().Infof("Recording macro %s (seed %d)", g.macroRecorder.Macro().Name, g.cfg.Seed)
+}
+
+// replayNextMacro loads the macro following the current one (in name order) and replays it
+// against a fresh run of its seed
+func (g *Game) replayNextMacro() {
+	names, err := ListMacros(MacrosDir)
+	if err != nil || len(names) == 0 {
+		g.engine.Logger().Infof("No macro to replay in %s/", MacrosDir)
+		return
+	}
+	next := names[0]
+	for idx, name := range names {
+		if name == g.macroName {
+			next = names[(idx+1)%len(names)]
+			break
+		}
+	}
+	macro, err := LoadMacro(MacrosDir, next)
+	if err != nil {
+		g.engine.Logger().Errorf("Cannot load macro: %v", err)
+		return
+	}
+	cfg := *g.cfg
+	if err := macro.Start.Apply(&cfg); err != nil {
+		g.engine.Logger().Errorf("Cannot replay macro %s: %v", next, err)
+		return
+	}
+	cfg.Seed = macro.Seed
+	*g.cfg = cfg
+	g.applyConfig(g.cfg)
+	g.restartSimulation()
+	g.macroPlayer = NewMacroPlayer(macro)
+	g.macroName = next
+	g.replayMacroButton.Label = "Replaying: " + next
+	g.engine.Logger().Infof("Replaying macro %s (%d events, seed %d)", next, len(macro.Events), macro.Seed)
+}
+
+// updateMacro applies the replayed events due at the next tick and records the interactions
+func (g *Game) updateMacro() {
+	tick := g.sentTicks() + 1
+	if g.macroPlayer != nil {
+		params, ok, err := g.macroPlayer.Advance(tick)
+		if err != nil {
+			g.engine.Logger().Errorf("Macro replay stopped: %v", err)
+			g.stopMacro()
+			return
+		}
+		if ok {
+			cfg := *g.cfg
+			if err := params.Apply(&cfg); err != nil {
+				g.engine.Logger().Errorf("Macro replay stopped at tick %d: %v", tick, err)
+				g.stopMacro()
+				return
+			}
+			g.applyConfig(&cfg)
+		}
+		if g.macroPlayer.Done() {
+			g.engine.Logger().Infof("Macro %s replayed", g.macroName)
+			g.stopMacro()
+		}
+	}
+	if g.macroRecorder != nil {
+		g.macroRecorder.Observe(tick, g.currentPreset(""))
+	}
+}
+
+// flowField returns the flow field on the current spatial grid, nil when it is hidden
+func (g *Game) flowField() *FlowField {
+	if !g.widgetShowFlow.Value {
+		return nil
+	}
+	g.flow.Resize(g.cfg.WorldWidth, g.cfg.WorldHeight,
+		gridCellSize(g.widgetDetectionRadius.Value, g.widgetDefenseRadius.Value, g.widgetVisualRange.Value))
+	return g.flow
+}
+
+// steerLeaders makes the leaders head to the mouse cursor while it is over the world, when they
+// have no LeaderPath to follow. 'blocked' is true when the cursor is over the UI.
+func (g *Game) steerLeaders(blocked bool) {
+	if g.cfg.LeaderTeam == "" || g.cfg.LeaderPath != "" || blocked {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	x, y := g.camera.ScreenToWorld(float64(mx), float64(my))
+	if x >= 0 && x <= g.cfg.WorldWidth && y >= 0 && y <= g.cfg.WorldHeight {
+		g.leaders.SetTarget(geometry.Vector2D{X: x, Y: y})
+	}
+}
+
+// refreshAttractorList lists the placed attractors in widgetAttractorList, keeping the selection in range
+func (g *Game) refreshAttractorList() {
+	list := g.attractors.List()
+	options := []string{noAttractor}
+	if len(list) > 0 {
+		options = make([]string, len(list))
+		for idx, a := range list {
+			options[idx] = fmt.Sprintf("%d. %s", idx+1, a)
+		}
+	}
+	g.widgetAttractorList.Options = options
+	g.widgetAttractorList.Selected = min(g.widgetAttractorList.Selected, len(options)-1)
+}
+
+// leaderPath returns the waypoints of the leaders, nil when they follow the cursor
+func (g *Game) leaderPath() []geometry.Vector2D {
+	if g.cfg.LeaderTeam == "" {
+		return nil
+	}
+	path, _ := ParseLeaderPath(g.cfg.LeaderPath)
+	return path
+}
+
+// navField returns the navigation field of the config, nil when it is hidden
+func (g *Game) navField() *behavior.NavField {
+	if !g.widgetShowNav.Value {
+		return nil
+	}
+	return g.cfg.NavigationField()
+}
+
+// sentTicks returns the number of ticks sent to the current engine. With a SimClock, the config
+// sent now may apply a few ticks later: macros are only tick-exact without one.
+func (g *Game) sentTicks() uint64 {
+	if g.clock != nil {
+		return g.clock.Ticks()
+	}
+	return g.ticksSent
+}
+
+// startClock starts ticking the current engine when Config.SimRate is set
+func (g *Game) startClock() {
+	if g.cfg.SimRate > 0 {
+		g.clock = StartSimClock(g.ctx, g.engine, g.cfg.SimRate)
+		g.clock.SetSpeed(gameSpeeds[g.speed])
+		g.interp = NewInterpolator(DefaultInterpolationDelay)
+	}
+}
+
+// stopMacro ends the replay, or ends and saves the recording
+func (g *Game) stopMacro() {
+	if g.macroPlayer != nil {
+		g.macroPlayer = nil
+		g.replayMacroButton.Label = "Replay Macro"
+	}
+	if g.macroRecorder == nil {
+		return
+	}
+	macro := g.macroRecorder.Macro()
+	g.macroRecorder = nil
+	g.recordMacroButton.Label = "Record Macro"
+	path, err := SaveMacro(MacrosDir, macro)
+	if err != nil {
+		g.engine.Logger().Errorf("Cannot save macro: %v", err)
+		return
+	}
+	g.macroName = macro.Name
+	g.engine.Logger().Infof("Macro with %d events saved to %s", len(macro.Events), path)
+}
+
+// applyConfig moves the sliders to the values of 'cfg', they are sent to the world with the next
+// UpdateConfig. World size and population changes take effect on restart.
+func (g *Game) applyConfig(cfg *Config) {
+	g.widgetDetectionRadius.Value = cfg.DetectionRadius
+	g.widgetDefenseRadius.Value = cfg.DefenseRadius
+	g.widgetContactRadius.Value = cfg.ContactRadius
+	g.widgetVisualRange.Value = cfg.VisualRange
+	g.widgetProtectedRange.Value = cfg.ProtectedRange
+	g.widgetMaxSpeed.Value = cfg.MaxSpeed
+	g.widgetMinSpeed.Value = cfg.MinSpeed
+	g.widgetAggression.Value = cfg.Aggression
+	g.widgetCenteringFactor.Value = cfg.CenteringFactor
+	g.widgetAvoidFactor.Value = cfg.AvoidFactor
+	g.widgetMatchingFactor.Value = cfg.MatchingFactor
+	g.widgetTurnFactor.Value = cfg.TurnFactor
+	g.widgetWindStrength.Value = cfg.WindStrength
+	g.widgetWindDirection.Value = cfg.WindDirection
+	g.widgetNumRed.Value = float64(cfg.NumRedAtStart)
+	g.widgetNumBlue.Value = float64(cfg.NumBlueAtStart)
+	g.widgetNumNeutral.Value = float64(cfg.NumNeutralAtStart)
+	g.widgetSeed.Text = strconv.FormatUint(cfg.Seed, 10)
+	if !g.widgetSpatialIndex.Select(cfg.SpatialIndex) {
+		g.widgetSpatialIndex.Select(SpatialIndexGrid)
+	}
+	g.widgetDefenders.Value = float64(cfg.defendersNeeded())
+	if !g.widgetDefenseCount.Select(cfg.DefenseCount) {
+		g.widgetDefenseCount.Select(DefenseCountRadius)
+	}
+	g.widgetDefenseFacing.Value = cfg.DefenseFacing
+	if !g.widgetCombatRule.Select(cfg.CombatRule) {
+		g.widgetCombatRule.Select(CombatDeterministic)
+	}
+	g.widgetDisplayDetection.Value = cfg.DisplayDetectionCircle
+	g.widgetDisplayDefense.Value = cfg.DisplayDefenseCircle
+	g.widgetSound.Value = cfg.Sound
+	g.widgetVolume.Value = soundVolume(cfg)
+	g.keyBindings = KeyBindings(cfg)
+
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if name := cfg.StrategyFor(team); name != g.teamStrategies[team] {
+			g.SetStrategy(team, name)
+			g.strategyButtons[team].Label = strategyButtonLabel(team, name)
+		}
+	}
+}
+
+// cycleStrategy switches a team to the next registered strategy, live
+func (g *Game) cycleStrategy(team pb.TeamColor, button *ui.Button) {
+	names := BehaviorNames(team)
+	if len(names) == 0 {
+		return
+	}
+	current := g.teamStrategies[team]
+	next := names[0]
+	for idx, name := range names {
+		if name == current {
+			next = names[(idx+1)%len(names)]
+			break
+		}
+	}
+	g.SetStrategy(team, next)
+	button.Label = strategyButtonLabel(team, next)
+}
+
+// viewportMargin extends the viewport sent to the world so that the sprites and circles
+// of the entities just outside the visible area are still drawn
+func (g *Game) viewportMargin() float64 {
+	margin := 16.0
+	if g.cfg.DisplayDetectionCircle {
+		margin = max(margin, g.cfg.DetectionRadius)
+	}
+	if g.cfg.DisplayDefenseCircle {
+		margin = max(margin, g.cfg.DefenseRadius)
+	}
+	return margin
+}
+
+// SetViewport tells the world which area of the world is on screen (for a camera or a zoom):
+// the snapshots then only list the entities inside it, the others are only counted.
+// An empty area (max <= min) shows the whole world again.
+func (g *Game) SetViewport(minX, minY, maxX, maxY float64) {
+	g.trails.SetViewport(minX, minY, maxX, maxY)
+	g.viewport = nil
+	if maxX > minX && maxY > minY {
+		margin := g.viewportMargin()
+		// Never modified once sent: the world keeps it
+		g.viewport = &pb.SetViewport{MinX: minX - margin, MinY: minY - margin, MaxX: maxX + margin, MaxY: maxY + margin}
+	}
+	_ = g.engine.Send(g.ctx, g.viewportMessage())
+}
+
+// viewportMessage returns the message sending the current viewport to the world
+func (g *Game) viewportMessage() *pb.SetViewport {
+	if g.viewport == nil {
+		return &pb.SetViewport{}
+	}
+	return g.viewport
+}
+
+// SetStrategy asks the world to hot-swap the strategy of a team
+func (g *Game) SetStrategy(team pb.TeamColor, name string) {
+	g.teamStrategies[team] = name
+	_ = g.engine.Send(g.ctx, &pb.SetStrategy{Team: team, Name: name})
+}
+
+func strategyButtonLabel(team pb.TeamColor, name string) string {
+	return fmt.Sprintf("%s: %s", teamLabel(team), name)
+}
+
+// syncDetachedWindows shows the windows whose "detach" checkbox was just ticked
+func (g *Game) syncDetachedWindows() {
+	if g.widgetDetachInspect.Value && !g.inspectorWindow.Visible && !g.inspector.Detached {
+		g.inspectorWindow.Visible = true
+	} else if !g.widgetDetachInspect.Value {
+		g.inspectorWindow.Visible = false
+	}
+}
 
+// isCursorOverUI reports whether the mouse cursor is over the panel or the settings button
+func (g *Game) isCursorOverUI() bool {
+	mx, my := ebiten.CursorPosition()
+	x, y := float64(mx), float64(my)
+	for _, w := range g.windows {
+		if w.Contains(x, y) {
+			return true
+		}
+	}
+	if !g.panel.IsCollapsed || g.panel.X != g.panel.TargetX {
+		if x >= g.panel.X && x <= g.panel.X+g.panel.Width && y >= g.panel.Y && y <= g.panel.Y+g.panel.Height {
+			return true
+		}
+	}
+	if g.summaryVisible() && image.Pt(mx, my).In(g.summaryScreen.rect) {
+		return true
+	}
+	b := g.toggleButton
+	return g.panel.IsCollapsed && x >= b.X && x <= b.X+b.Width && y >= b.Y && y <= b.Y+b.Height
 }
 
 func (g *Game) drawStatsBar(screen *ebiten.Image) {
-	if g.lastState == nil {
+	drawPopulationBar(screen, g.lastState, g.layout)
+}
+
+// drawPopulationBar draws the red/neutral/blue ratio of a snapshot in the top right corner of 'layout'
+func drawPopulationBar(screen *ebiten.Image, snap *pb.WorldSnapshot, layout *ui.Layout) {
+	if snap == nil {
 		return
 	}
 
-	reds := float32(g.lastState.RedCount)
-	blues := float32(g.lastState.BlueCount)
-	total := reds + blues
+	reds := float32(snap.RedCount)
+	blues := float32(snap.BlueCount)
+	civilians := float32(snap.NeutralCount)
+	total := reds + blues + civilians
 
 	// Avoid divide by zero at start
 	if total == 0 {
@@ -432,79 +1281,72 @@ func (g *Game) drawStatsBar(screen *ebiten.Image) {
 	}
 
 	// --- Configuration ---
-	barWidth := float32(200.0)
-	barHeight := float32(20.0)
-	marginTop := float32(10.0)
-	marginRight := float32(10.0)
+	barWidth := float32(scaled(200))
+	barHeight := float32(scaled(20))
 
-	// Calculate Position (Top Right)
-	// screen.Bounds().Dx() gives current window width
-	screenW := float32(screen.Bounds().Dx())
-	x := screenW - barWidth - marginRight
-	y := marginTop
+	// Calculate Position (Top Right), with the counts under the bar
+	left, top := layout.Place(ui.AnchorTopRight, float64(barWidth), float64(barHeight)+scaled(5)+text.LineHeight(overlayText.Size))
+	x, y := float32(left), float32(top)
 
 	// Calculate Ratios
 	redRatio := reds / total
 	redW := barWidth * redRatio
-	blueW := barWidth - redW
+	neutralW := barWidth * civilians / total
+	blueW := barWidth - redW - neutralW
 
 	// --- Draw Bars ---
 	// 1. Red Bar (Left side of the stack)
 	vector.FillRect(screen, x, y, redW, barHeight, color.RGBA{R: 255, G: 50, B: 50, A: 255}, true)
 
-	// 2. Blue Bar (Right side, starts where Red ends)
-	vector.FillRect(screen, x+redW, y, blueW, barHeight, color.RGBA{R: 50, G: 100, B: 255, A: 255}, true)
+	// 2. Neutral Bar (Middle, only with civilians)
+	if neutralW > 0 {
+		vector.FillRect(screen, x+redW, y, neutralW, barHeight, shipColors[SpriteCivilian], true)
+	}
+
+	// 3. Blue Bar (Right side, starts where Neutral ends)
+	vector.FillRect(screen, x+redW+neutralW, y, blueW, barHeight, color.RGBA{R: 50, G: 100, B: 255, A: 255}, true)
 
 	// --- Draw Text Below ---
 	// Position text under the respective colors
 
 	// Red Count
 	redMsg := fmt.Sprintf("%d", int(reds))
-	ebitenutil.DebugPrintAt(screen, redMsg, int(x), int(y+barHeight+5))
+	printOverlay(screen, redMsg, float64(x), float64(y+barHeight)+scaled(5))
 
-	// Blue Count (Aligned to the end of the bar roughly)
+	// Blue Count, aligned to the end of the bar
 	blueMsg := fmt.Sprintf("%d", int(blues))
-	// A simple hack to align right: subtract estimated text width (approx 8px per char)
-	textOffset := float32(len(blueMsg) * 8)
-	ebitenutil.DebugPrintAt(screen, blueMsg, int(x+barWidth-textOffset), int(y+barHeight+5))
-}
-
-// Add this new method to pkg/simulation/game.go
-func (g *Game) updateTrails(snap *pb.WorldSnapshot) {
-	// Track which IDs are currently Red so we can delete trails for dead/converted actors
-	activeRedIDs := make(map[string]bool)
-
-	for _, a := range snap.Actors {
-		if a.Color == pb.TeamColor_TEAM_RED {
-			activeRedIDs[a.Id] = true
-
-			// Convert Proto Vector to Geometry Vector
-			pos := geometry.Vector2D{X: a.Position.X, Y: a.Position.Y}
+	printOverlayRight(screen, blueMsg, float64(x+barWidth), float64(y+barHeight)+scaled(5))
 
-			// Append to history
-			if list, ok := g.trails[a.Id]; ok {
-				g.trails[a.Id] = append(list, pos)
-			} else {
-				g.trails[a.Id] = []geometry.Vector2D{pos}
-			}
-
-			// Limit trail length (e.g., keep last 20 frames)
-			maxLen := 20
-			if len(g.trails[a.Id]) > maxLen {
-				g.trails[a.Id] = g.trails[a.Id][1:]
-			}
-		}
+	// Neutral Count, centered under the bar
+	if snap.NeutralCount > 0 {
+		neutralMsg := fmt.Sprintf("%d", snap.NeutralCount)
+		printOverlay(screen, neutralMsg, float64(x+barWidth/2)-text.Width(neutralMsg, overlayText)/2, float64(y+barHeight)+scaled(5))
 	}
+}
 
-	// Cleanup: Remove trails for actors that are no longer Red
-	for id := range g.trails {
-		if !activeRedIDs[id] {
-			delete(g.trails, id)
-		}
+// Layout gives the screen the size of the window in the pixels of the display, crisp on HiDPI screens:
+// the world is scaled to fit it (letterboxed) unless the camera is zoomed in, the panel takes the
+// height of the window and the overlays stay anchored to its edges
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if outsideWidth <= 0 || outsideHeight <= 0 {
+		// Minimized
+		return int(g.layout.Width), int(g.layout.Height)
+	}
+	scale := deviceScale()
+	w, h := math.Ceil(float64(outsideWidth)*scale), math.Ceil(float64(outsideHeight)*scale)
+	if w != g.layout.Width || h != g.layout.Height {
+		g.resize(w, h)
 	}
+	return int(w), int(h)
 }
 
-func (g *Game) Layout(w, h int) (int, int) { return int(g.cfg.WorldWidth), int(g.cfg.WorldHeight) }
+// resize follows a new size of the window
+func (g *Game) resize(w, h float64) {
+	g.layout.Reset(w, h)
+	g.camera.Resize(w, h)
+	g.trails.SetViewport(g.camera.Visible())
+	g.panel.Height = h - 2*g.layout.Margin
+}
 
 func init() {
 	whiteImage.Fill(color.RGBA{R: 100, G: 200, B: 255, A: 255})
@@ -568,6 +1410,45 @@ func init() {
 
 	// ---  Pre-render a "Soft Puff" for the trail ---
 	// A small 8x8 white circle with alpha gradient (so it looks like glowing gas)
+	// --- Food: a small berry with a leaf, white so that it is tinted when drawn ---
+	foodSprite = generateSprite([]string{
+		"...L.",
+		"..LW.",
+		".WWW.",
+		"WWWWW",
+		"WWWWW",
+		".WWW.",
+	}, map[rune]color.RGBA{
+		'W': {R: 255, G: 255, B: 255, A: 255},
+		'L': {R: 150, G: 255, B: 150, A: 255},
+	})
+
+	// --- Civilian: a small gray pod with a window ---
+	civilianSprite = generateSprite([]string{
+		"..G..",
+		".GWG.",
+		"GGGGG",
+		"GGGGG",
+		".G.G.",
+	}, map[rune]color.RGBA{
+		'G': shipColors[SpriteCivilian],
+		'W': {R: 255, G: 255, B: 255, A: 255},
+	})
+
+	// --- Pickup: a white gem, tinted with the color of its power-up when drawn ---
+	pickupSprite = generateSprite([]string{
+		"...W...",
+		"..WWW..",
+		".WWSWW.",
+		"WWSSSWW",
+		".WWSWW.",
+		"..WWW..",
+		"...W...",
+	}, map[rune]color.RGBA{
+		'W': {R: 255, G: 255, B: 255, A: 255},
+		'S': {R: 180, G: 180, B: 180, A: 255},
+	})
+
 	trailSprite = ebiten.NewImage(8, 8)
 	cx, cy := 3.5, 3.5
 	r := 3.5
@@ -609,30 +1490,40 @@ func generateSprite(design []string, palette map[rune]color.RGBA) *ebiten.Image
 // restartSimulation stops the current world and spawns a new one with current config
 func (g *Game) restartSimulation() {
 	// Stop current world
-	if g.worldPID != nil {
-		_ = g.worldPID.Shutdown(g.ctx)
+	g.clock.Stop()
+	g.clock, g.interp, g.frame = nil, nil, nil
+	if g.engine != nil {
+		_ = g.engine.Stop(g.ctx)
 	}
 
 	// Clear trails
-	g.trails = make(map[string][]geometry.Vector2D)
+	g.trails.Reset()
+	g.history.Reset()
+	g.physics.Reset()
+	g.events.Reset()
+	g.summary.Reset()
+	g.summaryScreen.exported = ""
+	g.roundOver = time.Time{}
+	if g.tournament != nil && g.tournament.Done() {
+		// Restarting after the last round starts a new tournament
+		g.tournament.Reset()
+	}
+	g.effects.Reset()
+	g.audio.Reset()
+
+	// Entities of the previous world are gone
+	g.inspector.Clear()
+
+	// A macro only makes sense from the start of a run
+	g.stopMacro()
+	g.ticksSent = 0
+	g.paused, g.tickBudget = false, 0
 
 	// Update config with current widget values
-	g.cfg.DetectionRadius = g.widgetDetectionRadius.Value
-	g.cfg.DefenseRadius = g.widgetDefenseRadius.Value
-	g.cfg.ContactRadius = g.widgetContactRadius.Value
-	g.cfg.VisualRange = g.widgetVisualRange.Value
-	g.cfg.ProtectedRange = g.widgetProtectedRange.Value
-	g.cfg.MaxSpeed = g.widgetMaxSpeed.Value
-	g.cfg.MinSpeed = g.widgetMinSpeed.Value
-	g.cfg.Aggression = g.widgetAggression.Value
-	g.cfg.CenteringFactor = g.widgetCenteringFactor.Value
-	g.cfg.AvoidFactor = g.widgetAvoidFactor.Value
-	g.cfg.MatchingFactor = g.widgetMatchingFactor.Value
-	g.cfg.TurnFactor = g.widgetTurnFactor.Value
-	g.cfg.NumRedAtStart = int(g.widgetNumRed.Value)
-	g.cfg.NumBlueAtStart = int(g.widgetNumBlue.Value)
-	g.cfg.DisplayDetectionCircle = g.widgetDisplayDetection.Value
-	g.cfg.DisplayDefenseCircle = g.widgetDisplayDefense.Value
+	g.readWidgets(g.cfg)
+	// A new run, its stills go to a new directory
+	g.thumbnails = newThumbnailSchedule(g.cfg)
+	g.runID = NewRunID(g.cfg.Seed)
 
 	// Reset game over state
 	g.lastState = &pb.WorldSnapshot{
@@ -640,11 +1531,14 @@ func (g *Game) restartSimulation() {
 	}
 
 	// Spawn new world
-	worldActor := NewWorldActor(g.snapshotCh, g.cfg)
-	worldPID, err := g.System.Spawn(g.ctx, "world", worldActor)
+	engine, err := SelectEngine(g.cfg, g.newEngine)(g.ctx, g.snapshotCh, g.cfg, g.worldOpts...)
 	if err != nil {
-		// If spawn fails, keep the old PID
+		// If spawn fails, keep the old engine
 		return
 	}
-	g.worldPID = worldPID
+	g.engine = engine
+	if g.viewport != nil {
+		_ = g.engine.Send(g.ctx, g.viewportMessage())
+	}
+	g.startClock()
 }
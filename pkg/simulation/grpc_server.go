@@ -0,0 +1,53 @@
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/grpc"
+)
+
+// snapshotStreamBuffer is the number of snapshots queued per gRPC client before frames are dropped
+const snapshotStreamBuffer = 4
+
+// ObserverServer implements the pb.SwarmObserver gRPC service on top of a SnapshotHub
+type ObserverServer struct {
+	pb.UnimplementedSwarmObserverServer
+	hub *SnapshotHub
+}
+
+// NewObserverServer creates the service, register it with RegisterObserverServer
+func NewObserverServer(hub *SnapshotHub) *ObserverServer {
+	return &ObserverServer{hub: hub}
+}
+
+// RegisterObserverServer creates the service and registers it on a grpc.Server
+func RegisterObserverServer(s *grpc.Server, hub *SnapshotHub) *ObserverServer {
+	srv := NewObserverServer(hub)
+	pb.RegisterSwarmObserverServer(s, srv)
+	return srv
+}
+
+// StreamSnapshots streams the world snapshots until the client disconnects
+func (s *ObserverServer) StreamSnapshots(req *pb.StreamRequest, stream grpc.ServerStreamingServer[pb.WorldSnapshot]) error {
+	snapshots, cancel := s.hub.Subscribe(snapshotStreamBuffer)
+	defer cancel()
+
+	every := int(req.GetEveryNTicks())
+	count := 0
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case snap, ok := <-snapshots:
+			if !ok {
+				return nil
+			}
+			count++
+			if every > 1 && count%every != 0 {
+				continue
+			}
+			if err := stream.Send(snap); err != nil {
+				return err
+			}
+		}
+	}
+}
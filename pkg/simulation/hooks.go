@@ -0,0 +1,305 @@
+package simulation
+
+import (
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// TickHook is a custom callback invoked synchronously by the world once per tick,
+// after the spatial grid is rebuilt and before perceptions are dispatched to the individuals.
+// It runs inside the world goroutine: it must be fast and must not keep
+// references to the view or the queue after returning.
+type TickHook func(view *WorldView, cmds *CommandQueue)
+
+// WorldOption configures optional features of a world
+type WorldOption func(w *world)
+
+// WithTickHook registers a TickHook on the world (hooks run in registration order)
+func WithTickHook(hook TickHook) WorldOption {
+	return func(w *world) {
+		if hook != nil {
+			w.tickHooks = append(w.tickHooks, hook)
+		}
+	}
+}
+
+// WithTickTiming makes the world report the wall time spent on each tick in its snapshots
+// (WorldSnapshot.TickDuration). It is off by default so that a seeded run always produces the same snapshots.
+func WithTickTiming() WorldOption {
+	return func(w *world) {
+		w.timeTicks = true
+	}
+}
+
+// ============================================================================
+// Read-only World View
+// ============================================================================
+
+// WorldView gives a TickHook read access to the world entity store.
+// All accessors return copies so the authoritative state cannot be mutated by accident,
+// use the CommandQueue to request changes.
+type WorldView struct {
+	w *world
+}
+
+// Tick returns the number of simulation steps executed so far
+func (v *WorldView) Tick() uint64 {
+	return v.w.tick
+}
+
+// SimTime returns the simulation time elapsed so far (see SimTicksPerSecond)
+func (v *WorldView) SimTime() time.Duration {
+	return SimTime(v.w.tick)
+}
+
+// Config returns a copy of the current world configuration
+func (v *WorldView) Config() Config {
+	return *v.w.cfg
+}
+
+// Len returns the number of entities in the world
+func (v *WorldView) Len() int {
+	return len(v.w.entities)
+}
+
+// Entity returns a copy of the entity with the given id
+func (v *WorldView) Entity(id string) (Entity, bool) {
+	e, ok := v.w.entities[id]
+	if !ok {
+		return Entity{}, false
+	}
+	return *e, true
+}
+
+// Each calls fn with a copy of every entity until fn returns false.
+// The entities come by arrival, so that a seeded run makes the same calls every time.
+func (v *WorldView) Each(fn func(e Entity) bool) {
+	for _, e := range v.w.order {
+		if !fn(*e) {
+			return
+		}
+	}
+}
+
+// Counts returns the current number of red and blue entities, the civilians are in neither
+func (v *WorldView) Counts() (red, blue int) {
+	for _, e := range v.w.order {
+		switch e.Color {
+		case pb.TeamColor_TEAM_RED:
+			red++
+		case pb.TeamColor_TEAM_BLUE:
+			blue++
+		}
+	}
+	return red, blue
+}
+
+// Rand returns the random stream 'name' of the world: it only depends on the seed and the name,
+// like the streams of the entities, so that a seeded run places the spawned entities identically
+func (v *WorldView) Rand(name string) *rand.Rand {
+	return v.w.entityRand(name)
+}
+
+// Pool returns the occupancy of the entity pool (live, parked and recycled entities)
+func (v *WorldView) Pool() PoolStats {
+	return v.w.poolStats()
+}
+
+// CountInRadius returns the number of entities of 'color' within 'radius' of 'center'.
+// It uses the spatial grid, so it is cheap enough to be called many times per tick.
+func (v *WorldView) CountInRadius(center geometry.Vector2D, radius float64, color pb.TeamColor) int {
+	return v.w.countFriendsInRadius(center, radius, color, "")
+}
+
+// ============================================================================
+// Command Queue
+// ============================================================================
+
+// worldCommand is a deferred mutation applied by the world at the tick boundary
+type worldCommand func(w *world)
+
+// CommandQueue collects the mutations requested outside of the simulation step: by the tick hooks,
+// or by other goroutines (HTTP handlers, tools) through a queue given to WithCommandQueue.
+// Commands are applied in order at the tick boundary, so they never race the step.
+// The methods are safe for concurrent use.
+type CommandQueue struct {
+	mu   sync.Mutex
+	cmds []worldCommand
+	// spare is the slice of the last drain, reused by the next one
+	spare []worldCommand
+}
+
+// NewCommandQueue creates an empty queue, see WithCommandQueue
+func NewCommandQueue() *CommandQueue {
+	return &CommandQueue{}
+}
+
+// WithCommandQueue makes the world apply the commands of 'q' at the start of every tick,
+// before the spatial index is updated and the tick hooks run. Other goroutines push the commands:
+// those pushed together by a single call of Append are applied at the same tick. Several queues
+// can be given (e.g. the one of a Lab and the one of the Game), drained in the order of the options.
+// The ECS engine ignores it, like the tick hooks.
+func WithCommandQueue(q *CommandQueue) WorldOption {
+	return func(w *world) {
+		w.external = append(w.external, q)
+	}
+}
+
+// Len returns the number of pending commands
+func (q *CommandQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.cmds)
+}
+
+// Append moves the pending commands of 'other' to the end of q, at once
+func (q *CommandQueue) Append(other *CommandQueue) {
+	other.mu.Lock()
+	cmds := other.cmds
+	other.cmds = nil
+	other.mu.Unlock()
+	q.mu.Lock()
+	q.cmds = append(q.cmds, cmds...)
+	q.mu.Unlock()
+}
+
+func (q *CommandQueue) push(cmd worldCommand) {
+	q.mu.Lock()
+	q.cmds = append(q.cmds, cmd)
+	q.mu.Unlock()
+}
+
+// drain applies the pending commands in order. The commands pushed meanwhile,
+// by another goroutine or by the commands themselves, wait for the next drain.
+func (q *CommandQueue) drain(w *world) {
+	q.mu.Lock()
+	cmds := q.cmds
+	q.cmds = q.spare
+	q.spare = nil
+	q.mu.Unlock()
+	for _, cmd := range cmds {
+		cmd(w)
+	}
+	clear(cmds)
+	q.mu.Lock()
+	if q.spare == nil {
+		q.spare = cmds[:0]
+	}
+	q.mu.Unlock()
+}
+
+// Convert asks the entity with the given id to switch to 'color'
+func (q *CommandQueue) Convert(id string, color pb.TeamColor) {
+	q.push(func(w *world) {
+		w.sendConvert(id, color, "", "")
+	})
+}
+
+// Spawn adds an entity of 'color' at 'pos' moving at 'vel', recycling a despawned one when possible
+func (q *CommandQueue) Spawn(color pb.TeamColor, pos, vel geometry.Vector2D) {
+	q.push(func(w *world) {
+		w.spawnEntity(color, pos, vel)
+	})
+}
+
+// SpawnWith is Spawn, then calls 'onSpawn' with the ID of the new entity (in the world goroutine)
+func (q *CommandQueue) SpawnWith(color pb.TeamColor, pos, vel geometry.Vector2D, onSpawn func(id string)) {
+	q.push(func(w *world) {
+		onSpawn(w.spawnEntity(color, pos, vel).ID)
+	})
+}
+
+// Despawn removes the entity with the given id from the world, its ID is recycled by a later Spawn
+func (q *CommandQueue) Despawn(id string) {
+	q.push(func(w *world) {
+		w.despawn(id)
+	})
+}
+
+// Teleport moves the entity with the given id to 'pos' with the velocity 'vel', its team and strategy are kept
+func (q *CommandQueue) Teleport(id string, pos, vel geometry.Vector2D) {
+	q.push(func(w *world) {
+		w.teleport(id, pos, vel)
+	})
+}
+
+// UpdateConfig applies 'fn' to the live world configuration.
+// Note that the Game pushes its slider values every frame, so parameters exposed in the UI
+// will be overwritten on the next frame when running with the graphical front-end.
+func (q *CommandQueue) UpdateConfig(fn func(cfg *Config)) {
+	q.push(func(w *world) {
+		fn(w.cfg)
+		w.syncRadii()
+	})
+}
+
+// SetParameter sets the config field with the JSON name 'name' (e.g. "maxSpeed") to 'value', parsed
+// like the matching command-line flag. The name and the value are checked at once, the whole config
+// is validated when the command is applied: an invalid result is logged and discarded.
+// A new strategy is forwarded to its team, like SetStrategy. The note of UpdateConfig applies.
+func (q *CommandQueue) SetParameter(name, value string) error {
+	o := &ConfigOverrides{values: make(map[string]string)}
+	if err := o.Set(name, value); err != nil {
+		return err
+	}
+	q.push(func(w *world) {
+		w.setParameters(o)
+	})
+	return nil
+}
+
+// runTickHooks invokes every registered hook then applies the queued commands
+func (w *world) runTickHooks() {
+	if len(w.tickHooks) == 0 {
+		return
+	}
+	view := &WorldView{w: w}
+	for _, hook := range w.tickHooks {
+		hook(view, &w.commands)
+	}
+	w.commands.drain(w)
+}
+
+// drainCommands applies the commands of the queues of WithCommandQueue
+func (w *world) drainCommands() {
+	for _, q := range w.external {
+		q.drain(w)
+	}
+}
+
+// teleport moves the entity 'id' and tells its individual, it returns false when the entity is unknown
+func (w *world) teleport(id string, pos, vel geometry.Vector2D) bool {
+	e, ok := w.entities[id]
+	if !ok {
+		return false
+	}
+	e.Pos, e.Vel = pos, vel
+	w.gridDirty = true
+	// Same life, same team: the individual keeps its behavior, its needs and its order
+	if w.swarm.tell(id, &pb.Move{Position: GeomVector2DToProto(pos), Velocity: GeomVector2DToProto(vel)}) {
+		w.msgSentCount++
+	}
+	return true
+}
+
+// setParameters applies 'o' to a copy of the config, which replaces it once validated
+func (w *world) setParameters(o *ConfigOverrides) {
+	cfg := *w.cfg
+	if err := cfg.ApplyOverrides(o); err != nil {
+		w.swarm.logger().Errorf("Cannot set %s: %v", strings.Join(o.Names(), ", "), err)
+		return
+	}
+	before := w.cfg
+	w.cfg = &cfg
+	w.syncRadii()
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if name := cfg.StrategyFor(team); name != before.StrategyFor(team) {
+			w.setTeamStrategy(&pb.SetStrategy{Team: team, Name: name})
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package simulation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestWorldActor_runTickHooks(t *testing.T) {
+	cfg := &Config{
+		WorldWidth:      1000,
+		WorldHeight:     1000,
+		DetectionRadius: 100,
+		DefenseRadius:   50,
+		VisualRange:     70,
+	}
+	var seenTick uint64
+	var seenRed, seenBlue, seenNear int
+	hook := func(view *WorldView, cmds *CommandQueue) {
+		seenTick = view.Tick()
+		seenRed, seenBlue = view.Counts()
+		seenNear = view.CountInRadius(geometry.Vector2D{X: 100, Y: 100}, 30, pb.TeamColor_TEAM_BLUE)
+		cmds.UpdateConfig(func(c *Config) {
+			c.DetectionRadius = 120
+		})
+	}
+	w := newWorld(nil, cfg, WithTickHook(hook))
+	w.addEntity(&Entity{ID: "r1", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 500, Y: 500}})
+	w.addEntity(&Entity{ID: "b1", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 110}})
+	w.addEntity(&Entity{ID: "b2", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 300, Y: 300}})
+	w.tick = 7
+	w.rebuildGrid()
+
+	w.runTickHooks()
+
+	if seenTick != 7 {
+		t.Errorf("Expected hook to see tick 7, got %d", seenTick)
+	}
+	if seenRed != 1 || seenBlue != 2 {
+		t.Errorf("Expected 1 red and 2 blues, got %d red and %d blue", seenRed, seenBlue)
+	}
+	if seenNear != 1 {
+		t.Errorf("Expected 1 blue near (100,100), got %d", seenNear)
+	}
+	if w.detectionRadius != 120 || w.cfg.DetectionRadius != 120 {
+		t.Errorf("Expected queued UpdateConfig to set detection radius to 120, got %f", w.detectionRadius)
+	}
+	if w.commands.Len() != 0 {
+		t.Errorf("Expected command queue to be drained, got %d pending", w.commands.Len())
+	}
+}
+
+func TestWorldView_eachByArrival(t *testing.T) {
+	w := newWorld(nil, &Config{WorldWidth: 400, WorldHeight: 400, DetectionRadius: 100})
+	arrival := []string{"Red-002", "Blue-000", "Red-000", "Blue-001", "Red-001"}
+	for _, id := range arrival {
+		color := pb.TeamColor_TEAM_BLUE
+		if id[0] == 'R' {
+			color = pb.TeamColor_TEAM_RED
+		}
+		w.addEntity(&Entity{ID: id, Color: color})
+	}
+	view := &WorldView{w: w}
+	for range 5 {
+		var seen []string
+		view.Each(func(e Entity) bool {
+			seen = append(seen, e.ID)
+			return true
+		})
+		if !slices.Equal(seen, arrival) {
+			t.Fatalf("Expected the entities by arrival %v, got %v", arrival, seen)
+		}
+	}
+
+	var first []string
+	view.Each(func(e Entity) bool {
+		first = append(first, e.ID)
+		return len(first) < 2
+	})
+	if !slices.Equal(first, arrival[:2]) {
+		t.Errorf("Expected Each to stop after the first two entities, got %v", first)
+	}
+	if red, blue := view.Counts(); red != 3 || blue != 2 {
+		t.Errorf("Expected 3 red and 2 blues, got %d red and %d blue", red, blue)
+	}
+}
+
+func TestWorld_commandQueue(t *testing.T) {
+	q := NewCommandQueue()
+	w, s := newScriptedWorld(combatConfig(), WithCommandQueue(q))
+	s.report(redAt("Red-000", 100, 100), blueAt("Blue-000", 500, 500))
+
+	// Pushed from other goroutines while nothing ticks
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Teleport("Blue-000", geometry.Vector2D{X: 300, Y: 200}, geometry.Vector2D{X: 1})
+		q.Spawn(pb.TeamColor_TEAM_BLUE, geometry.Vector2D{X: 700, Y: 700}, geometry.Vector2D{})
+	}()
+	<-done
+	if err := q.SetParameter("maxSpeed", "7.5"); err != nil {
+		t.Fatalf("SetParameter failed: %v", err)
+	}
+	if err := q.SetParameter("noSuchField", "1"); err == nil {
+		t.Error("Expected an unknown parameter to be rejected at once")
+	}
+	if err := q.SetParameter("maxSpeed", "fast"); err == nil {
+		t.Error("Expected an invalid value to be rejected at once")
+	}
+	if q.Len() != 3 {
+		t.Fatalf("Expected 3 pending commands, got %d", q.Len())
+	}
+
+	s.tick()
+	if q.Len() != 0 {
+		t.Errorf("Expected the queue to be drained by the tick, got %d pending", q.Len())
+	}
+	e := w.entities["Blue-000"]
+	if e.Pos != (geometry.Vector2D{X: 300, Y: 200}) {
+		t.Errorf("Expected Blue-000 to be teleported to (300, 200), got %v", e.Pos)
+	}
+	var moved bool
+	for _, m := range s.told {
+		if mv, ok := m.msg.(*pb.Move); ok && m.id == "Blue-000" {
+			moved = mv.GetPosition().GetX() == 300 && mv.GetPosition().GetY() == 200
+		}
+		if _, ok := m.msg.(*pb.Respawn); ok {
+			t.Errorf("Expected a teleport not to respawn %s", m.id)
+		}
+	}
+	if !moved {
+		t.Error("Expected the individual of Blue-000 to be told its new position")
+	}
+	if len(w.order) != 3 {
+		t.Errorf("Expected the spawned entity to join the world, got %d entities", len(w.order))
+	}
+	if w.cfg.MaxSpeed != 7.5 || w.live.current.Load().MaxSpeed != 7.5 {
+		t.Errorf("Expected maxSpeed 7.5 for the world and its individuals, got %g", w.cfg.MaxSpeed)
+	}
+
+	// An invalid config is discarded when applied
+	_ = q.SetParameter("minSpeed", "100")
+	s.tick()
+	if w.cfg.MinSpeed == 100 {
+		t.Error("Expected a minSpeed above maxSpeed to be discarded")
+	}
+}
+
+func TestIndividual_handleMove(t *testing.T) {
+	ind := newIndividual(pb.TeamColor_TEAM_RED, 300, 300, 0, 2, newLiveConfig(DefaultConfig()), nil)
+	ind.setID("Red-000")
+	if err := ind.setBehavior(StrategyPackHunter); err != nil {
+		t.Fatalf("setBehavior failed: %v", err)
+	}
+	ind.State.Hunger, ind.State.Fatigue, ind.State.exhausted = 0.4, 0.9, true
+	ind.order = Order{Kind: OrderFocus, Target: "Blue-000"}
+
+	ind.handleMove(&pb.Move{Position: &pb.Vector{X: 50, Y: 60}, Velocity: &pb.Vector{X: 1}})
+	if ind.State.Pos != (geometry.Vector2D{X: 50, Y: 60}) || ind.State.Vel != (geometry.Vector2D{X: 1}) {
+		t.Errorf("Expected the individual at (50, 60) heading +X, got %v and %v", ind.State.Pos, ind.State.Vel)
+	}
+	if ind.strategy != StrategyPackHunter || ind.State.Hunger != 0.4 || ind.State.Fatigue != 0.9 || !ind.State.exhausted {
+		t.Errorf("Expected the strategy and the needs kept, got %q, hunger %f, fatigue %f", ind.strategy, ind.State.Hunger, ind.State.Fatigue)
+	}
+	if ind.order.Kind != OrderFocus || ind.order.Target != "Blue-000" {
+		t.Errorf("Expected the order kept, got %+v", ind.order)
+	}
+}
+
+func TestWorld_commandQueues(t *testing.T) {
+	first, second := NewCommandQueue(), NewCommandQueue()
+	w, s := newScriptedWorld(combatConfig(), WithCommandQueue(first), WithCommandQueue(second))
+	s.report(blueAt("Blue-000", 500, 500))
+	second.Teleport("Blue-000", geometry.Vector2D{X: 200, Y: 200}, geometry.Vector2D{})
+	first.Teleport("Blue-000", geometry.Vector2D{X: 100, Y: 100}, geometry.Vector2D{})
+	s.tick()
+	if first.Len() != 0 || second.Len() != 0 {
+		t.Errorf("Expected both queues drained, got %d and %d pending", first.Len(), second.Len())
+	}
+	if got := w.entities["Blue-000"].Pos; got != (geometry.Vector2D{X: 200, Y: 200}) {
+		t.Errorf("Expected the queues drained in the order of the options, got %v", got)
+	}
+}
+
+func TestCommandQueue_Append(t *testing.T) {
+	q, batch := NewCommandQueue(), NewCommandQueue()
+	q.Despawn("Red-000")
+	batch.Convert("Blue-000", pb.TeamColor_TEAM_RED)
+	batch.Despawn("Blue-001")
+	q.Append(batch)
+	if q.Len() != 3 || batch.Len() != 0 {
+		t.Errorf("Expected the 2 commands of the batch to move to the queue, got %d and %d", q.Len(), batch.Len())
+	}
+}
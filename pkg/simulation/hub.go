@@ -0,0 +1,82 @@
+package simulation
+
+import (
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// SnapshotHub fans out the snapshots produced by the world to any number of subscribers
+// (network streams, recorders...) besides the Game channel.
+// Published snapshots are shared: subscribers must treat them as read-only.
+type SnapshotHub struct {
+	mu     sync.RWMutex
+	subs   map[int]chan *pb.WorldSnapshot
+	nextID int
+	latest *pb.WorldSnapshot
+}
+
+// NewSnapshotHub creates an empty hub
+func NewSnapshotHub() *SnapshotHub {
+	return &SnapshotHub{
+		subs: make(map[int]chan *pb.WorldSnapshot),
+	}
+}
+
+// WithSnapshotHub makes the world publish every snapshot to the hub
+func WithSnapshotHub(hub *SnapshotHub) WorldOption {
+	return func(w *world) {
+		w.hub = hub
+	}
+}
+
+// Subscribe registers a new subscriber with a channel of size 'buffer'.
+// The returned cancel function unregisters it and closes the channel.
+func (h *SnapshotHub) Subscribe(buffer int) (<-chan *pb.WorldSnapshot, func()) {
+	ch := make(chan *pb.WorldSnapshot, buffer)
+	h.mu.Lock()
+	id := h.nextID
+	h.nextID++
+	h.subs[id] = ch
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// Publish sends the snapshot to every subscriber without blocking:
+// slow subscribers simply miss the frame, like the Game does.
+func (h *SnapshotHub) Publish(snap *pb.WorldSnapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.latest = snap
+	for _, ch := range h.subs {
+		select {
+		case ch <- snap:
+		default:
+			// Subscriber busy, skip frame
+		}
+	}
+}
+
+// Latest returns the last published snapshot (nil before the first tick)
+func (h *SnapshotHub) Latest() *pb.WorldSnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.latest
+}
+
+// Subscribers returns the number of active subscribers
+func (h *SnapshotHub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
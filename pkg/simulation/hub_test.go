@@ -0,0 +1,38 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestSnapshotHub_PublishSubscribe(t *testing.T) {
+	hub := NewSnapshotHub()
+	fast, cancelFast := hub.Subscribe(2)
+	slow, cancelSlow := hub.Subscribe(0) // never ready: must not block Publish
+
+	snap := &pb.WorldSnapshot{RedCount: 1}
+	hub.Publish(snap)
+
+	if got := <-fast; got != snap {
+		t.Errorf("Expected subscriber to receive the published snapshot")
+	}
+	if hub.Latest() != snap {
+		t.Errorf("Expected Latest() to return the published snapshot")
+	}
+	select {
+	case <-slow:
+		t.Error("Did not expect unbuffered subscriber to receive a snapshot")
+	default:
+	}
+
+	cancelSlow()
+	cancelSlow() // cancel is idempotent
+	if hub.Subscribers() != 1 {
+		t.Errorf("Expected 1 subscriber after cancel, got %d", hub.Subscribers())
+	}
+	cancelFast()
+	if _, ok := <-fast; ok {
+		t.Error("Expected channel to be closed after cancel")
+	}
+}
@@ -1,13 +1,10 @@
 package simulation
 
 import (
-	"math"
-	"math/rand"
+	"math/rand/v2"
 
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
-	"github.com/tochemey/goakt/v3/actor"
-	"github.com/tochemey/goakt/v3/goaktpb"
 )
 
 const (
@@ -15,155 +12,84 @@ const (
 	ColorBlue = "🔵 BLUE"
 )
 
-type Individual struct {
-	ID             string
-	State          *Entity
-	visibleTargets []*pb.ActorState // Enemies
-	visibleFriends []*pb.ActorState // Allies
-	cfg            *Config
+// individual is one member of the swarm. It only sees the world through the perception
+// sent with every Tick and reports its new state back. The engine decides how it runs
+// (as a GoAkt actor, see individual_actor.go, or as a plain struct in the local engine).
+type individual struct {
+	ID         string
+	State      *Entity
+	perception *pb.Perception // Enemies (Targets) and Allies (Friends) visible at last tick
+	behavior   Behavior       // Movement logic of the current team strategy
+	strategy   string         // Registered name of behavior
+	cfg        *liveConfig    // Config of the world, read once per message
+	scaled     scaledConfig   // Config of the world scaled by the terrain and the power-ups
+	order      Order          // Last order of the commander of its team, see commander.go
 }
 
-var _ actor.Actor = (*Individual)(nil)
-
-func NewIndividual(color pb.TeamColor, startX, startY, vx, vy float64, cfg *Config) *Individual {
-	return &Individual{
+func newIndividual(color pb.TeamColor, startX, startY, vx, vy float64, cfg *liveConfig, rng *rand.Rand) *individual {
+	i := &individual{
 		State: &Entity{
 			// ID set in PreStart or derived later
 			Color: color,
 			Pos:   geometry.Vector2D{X: startX, Y: startY},
 			Vel:   geometry.Vector2D{X: vx, Y: vy},
+			Rand:  rng,
 		},
-		cfg: cfg,
+		perception: &pb.Perception{},
+		cfg:        cfg,
+	}
+	if err := i.setBehavior(cfg.Load().StrategyFor(color)); err != nil {
+		_ = i.setBehavior(DefaultStrategy(color))
 	}
+	return i
 }
 
-// ============================================================================
-// Actor Lifecycle Hooks
-// ============================================================================
-
-func (i *Individual) PreStart(ctx *actor.Context) error {
-	i.ID = ctx.ActorName()
-	i.State.ID = i.ID // <--- FIX: Ensure State has the ID
-	i.Log(ctx.ActorSystem(), "Born: %s (%s) at %s",
-		i.ID, i.State.Color, i.State.Pos)
-	return nil
-}
-
-func (i *Individual) PostStop(ctx *actor.Context) error {
-	i.Log(ctx.ActorSystem(), "Death: %s", ctx.ActorName())
-	return nil
+// setID names the individual once the engine registered it
+func (i *individual) setID(id string) {
+	i.ID = id
+	i.State.ID = id // <--- FIX: Ensure State has the ID
 }
 
 // ============================================================================
-// Message Routing (Entry Point)
+// Shared Behaviors
 // ============================================================================
 
-func (i *Individual) Receive(ctx *actor.ReceiveContext) {
-	// Route to appropriate behavior based on current color
-	if i.State.Color == pb.TeamColor_TEAM_RED {
-		ctx.Become(i.RedBehavior)
-		i.RedBehavior(ctx)
-	} else {
-		ctx.Become(i.BlueBehavior)
-		i.BlueBehavior(ctx)
+// handleTick moves the individual with the current team strategy
+// (ClassicHunter for red, ClassicBoids for blue by default) and returns its new state
+func (i *individual) handleTick(msg *pb.Tick) *pb.ActorState {
+	// EXTRACT PERCEPTION
+	if msg.Context != nil {
+		i.perception = msg.Context
 	}
-}
-
-// ============================================================================
-// RED BEHAVIOR: Aggressive Hunter
-// ============================================================================
-
-func (i *Individual) RedBehavior(ctx *actor.ReceiveContext) {
-	switch msg := ctx.Message().(type) {
-
-	case *goaktpb.PostStart:
-		i.ID = ctx.Self().Name()
-		i.State.ID = i.ID // <--- FIX: Ensure State has the ID
-		i.Log(ctx.ActorSystem(), "%s started in RED mode", i.ID)
-
-	case *pb.Tick:
-		// EXTRACT PERCEPTION
-		if msg.Context != nil {
-			i.visibleTargets = msg.Context.Targets
-			i.visibleFriends = msg.Context.Friends
-		}
-		i.updateAsRed()
-		i.reportState(ctx)
-
-	case *pb.Convert:
-		i.handleConversion(ctx, msg)
-
-	case *pb.GetState:
-		i.respondState(ctx)
-
-	default:
-		ctx.Unhandled()
+	cfg := i.movingConfig()
+	i.State.beginStep(tickScale(msg.DeltaTime), cfg.Integrator)
+	if wind := i.cfg.wind.Load(); wind != nil {
+		i.State.ApplyForce(*wind)
 	}
-}
-
-func (i *Individual) updateAsRed() {
-	if len(i.visibleTargets) > 0 {
-		i.chaseClosestTarget()
-	} else {
-		// Wander when no targets visible
-		jitter := geometry.Vector2D{
-			X: (rand.Float64() - 0.5) * 0.15,
-			Y: (rand.Float64() - 0.5) * 0.15,
-		}
-		i.State.Vel = i.State.Vel.Add(jitter)
+	applyAttractors(i.State, i.cfg.attractors.Load())
+	applyPerceivedForce(i.State, msg.Context)
+	if i.State.Color == NeutralColor {
+		// The civilians have no team: no leader, order, field nor hunger, only their wandering
+		i.behavior.Update(i.State, i.perception, cfg)
+		return i.makeState()
 	}
-	i.State.UpdatePhysics() // Pos += Vel
-	i.State.BounceOffWalls(i.cfg.WorldWidth, i.cfg.WorldHeight)
-}
-
-// ============================================================================
-// BLUE BEHAVIOR: Flocking Prey
-// ============================================================================
-
-func (i *Individual) BlueBehavior(ctx *actor.ReceiveContext) {
-	switch msg := ctx.Message().(type) {
-
-	case *goaktpb.PostStart:
-		i.ID = ctx.Self().Name()
-		i.State.ID = i.ID // <--- FIX: Ensure State has the ID
-		i.Log(ctx.ActorSystem(), "%s started in BLUE mode", i.ID)
-
-	case *pb.Tick:
-		// EXTRACT PERCEPTION
-		if msg.Context != nil {
-			i.visibleTargets = msg.Context.Targets
-			i.visibleFriends = msg.Context.Friends
-		}
-		i.updateAsBlue()
-		i.reportState(ctx)
-
-	case *pb.Convert:
-		i.handleConversion(ctx, msg)
-
-	case *pb.GetState:
-		i.respondState(ctx)
-
-	default:
-		ctx.Unhandled()
+	applyNavigation(i.State, cfg)
+	applyFormation(i.State, i.cfg.formation.Load(), cfg)
+	applyPheromones(i.State, i.cfg.pheromones.Load(), cfg)
+	applyOrder(i.State, &i.order, i.perception, cfg)
+	food := i.cfg.food.Load()
+	forage(i.State, food, cfg)
+	// A steered leader moves on its own: only its behavior is skipped, it still starves and tires
+	if !leadOrFollow(i.State, i.perception, i.cfg.leaders.Load(), cfg) {
+		i.behavior.Update(i.State, i.perception, cfg)
 	}
+	starve(i.State, food, cfg)
+	tire(i.State, cfg)
+	return i.makeState()
 }
 
-func (i *Individual) updateAsBlue() {
-	// Apply boids flocking rules
-	force := ComputeBoidUpdate(i.State, i.visibleFriends, i.cfg)
-	//i.updateSoftTurnPosition()
-
-	i.State.Vel = i.State.Vel.Add(force) // Apply force
-	i.State.SoftBoundaries(i.cfg.WorldWidth, i.cfg.WorldHeight, i.cfg.TurnFactor)
-	i.State.ClampVelocity(i.cfg.MinSpeed, i.cfg.MaxSpeed)
-	i.State.UpdatePhysics()
-}
-
-// ============================================================================
-// Shared Behaviors
-// ============================================================================
-
-func (i *Individual) handleConversion(ctx *actor.ReceiveContext, msg *pb.Convert) {
+// handleConversion switches the individual to another team and swaps its behavior for the one of the team
+func (i *individual) handleConversion(log Logger, msg *pb.Convert) {
 	if msg.TargetColor == i.State.Color {
 		return // Already this color
 	}
@@ -171,89 +97,124 @@ func (i *Individual) handleConversion(ctx *actor.ReceiveContext, msg *pb.Convert
 	oldColor := i.State.Color
 	i.State.Color = msg.TargetColor
 
-	i.Log(ctx.ActorSystem(), "%s converting: %s → %s",
-		ctx.Self().Name(), oldColor, i.State.Color)
+	i.Log(log, "%s converting: %s → %s",
+		i.ID, oldColor, i.State.Color)
 
-	// Switch behavior function
-	if i.State.Color == pb.TeamColor_TEAM_RED {
-		ctx.Become(i.RedBehavior)
-	} else {
-		ctx.Become(i.BlueBehavior)
+	// Adopt the strategy of the new team
+	strategy := msg.Strategy
+	if strategy == "" {
+		strategy = i.cfg.Load().StrategyFor(i.State.Color)
+	}
+	if err := i.setBehavior(strategy); err != nil {
+		i.Log(log, "%s cannot use strategy %q: %v", i.ID, strategy, err)
+		_ = i.setBehavior(DefaultStrategy(i.State.Color))
 	}
 
 	// Visual feedback: "Explosion" Bounce effect
 	i.State.Vel.Mul(-1.5)
 
-	// Reset sensory memory
-	i.visibleTargets = nil
-	i.visibleFriends = nil
+	// Reset sensory memory, and the order of the former team
+	i.perception = &pb.Perception{}
+	i.order = Order{}
 }
 
-func (i *Individual) reportState(ctx *actor.ReceiveContext) {
-	//i.Log(ctx.ActorSystem(), "%s reportState i.State.Pos %s \tVel: %s", i.ID, i.State.Pos, i.State.Vel)
-	state := i.makeState()
-	// Reply to sender (should be World)
-	if ctx.Sender() != nil && ctx.Sender() != ctx.ActorSystem().NoSender() {
-		ctx.Tell(ctx.Sender(), state)
+// handleRespawn recycles a parked individual: it takes the new state and the strategy of its team
+func (i *individual) handleRespawn(log Logger, msg *pb.Respawn) {
+	state := msg.GetState()
+	i.State.Color = state.GetColor()
+	i.State.Pos = GeomVector2DFromProto(state.GetPosition())
+	i.State.Vel = GeomVector2DFromProto(state.GetVelocity())
+	i.State.Generation = state.GetGeneration()
+	i.State.Hunger, i.State.Fatigue, i.State.exhausted = 0, 0, false
+	i.perception = &pb.Perception{}
+	i.order = Order{}
+
+	if err := i.setBehavior(msg.GetStrategy()); err != nil {
+		_ = i.setBehavior(DefaultStrategy(i.State.Color))
 	}
+	i.Log(log, "%s respawned (generation %d) as %s at %s", i.ID, i.State.Generation, i.State.Color, i.State.Pos)
 }
 
-func (i *Individual) respondState(ctx *actor.ReceiveContext) {
-	//i.Log(ctx.ActorSystem(), "%s respondState i.State.Pos %s \tVel: %s", i.ID, i.State.Pos, i.State.Vel)
-	ctx.Response(i.makeState())
+// handleMove places the individual elsewhere: its strategy, its needs and its order are kept
+func (i *individual) handleMove(msg *pb.Move) {
+	i.State.Pos = GeomVector2DFromProto(msg.GetPosition())
+	i.State.Vel = GeomVector2DFromProto(msg.GetVelocity())
 }
 
-func (i *Individual) makeState() *pb.ActorState {
-	return i.State.ToProto()
+// handleOrder keeps the order of the commander of our team until the next one
+func (i *individual) handleOrder(msg *pb.Order) {
+	// An order sent before a conversion is meant for the former team
+	if msg.Team == i.State.Color {
+		i.order = orderFromProto(msg)
+	}
 }
 
-// ============================================================================
-// Physics / Movement
-// ============================================================================
-
-func (i *Individual) chaseClosestTarget() {
-	if len(i.visibleTargets) == 0 {
+// handleSetStrategy hot-swaps the behavior when the strategy of our team changes
+func (i *individual) handleSetStrategy(log Logger, msg *pb.SetStrategy) {
+	if msg.Team != i.State.Color || msg.Name == i.strategy {
 		return
 	}
+	if err := i.setBehavior(msg.Name); err != nil {
+		i.Log(log, "%s cannot use strategy %q: %v", i.ID, msg.Name, err)
+		return
+	}
+	i.Log(log, "%s now uses strategy %s", i.ID, msg.Name)
+}
 
-	// Find nearest enemy
-	var closest *pb.ActorState
-	minDistSq := math.MaxFloat64
-
-	for _, target := range i.visibleTargets {
-		distSq := i.State.Pos.DistanceSquaredTo(GeomVector2DFromProto(target.Position))
-
-		if distSq < minDistSq {
-			minDistSq = distSq
-			closest = target
-		}
+// setBehavior instantiates the named behavior from the registry
+func (i *individual) setBehavior(name string) error {
+	b, err := NewBehavior(name)
+	if err != nil {
+		return err
 	}
+	i.behavior = b
+	i.strategy = name
+	return nil
+}
 
-	if closest == nil {
-		return
+// movingConfig returns the config the individual moves with during this tick: the config of the
+// world, with the speeds and the turn factor scaled by the terrain under it (see terrain.go) and its
+// speed power-up (see powerup.go)
+func (i *individual) movingConfig() *Config {
+	cfg := i.cfg.Load()
+	speed, turn := cfg.terrainFactors(i.State.Pos)
+	if i.cfg.powerUps.Load().of(i.ID).Has(PowerUpSpeed) {
+		speed *= speedBoost
 	}
+	return i.scaled.of(cfg, speed, turn)
+}
 
-	// Calculate pursuit vector
-	pursuit := GeomVector2DFromProto(closest.Position).Sub(i.State.Pos)
-	length := i.State.Pos.DistanceTo(GeomVector2DFromProto(closest.Position))
+// scaledConfig is a copy of a config with scaled speeds and turn factor, kept from a tick to the
+// next while neither the config nor the factors change
+type scaledConfig struct {
+	from        *Config
+	speed, turn float64
+	cfg         Config
+}
 
-	if length > 0 {
-		pursuit.Normalize().Mul(i.cfg.Aggression)
-		i.State.Vel = i.State.Vel.Add(pursuit)
+// of returns 'cfg' with MaxSpeed and MinSpeed multiplied by 'speed' and TurnFactor by 'turn',
+// 'cfg' itself when both are 1
+func (s *scaledConfig) of(cfg *Config, speed, turn float64) *Config {
+	if speed == 1 && turn == 1 {
+		return cfg
 	}
-
-	// Cap at max speed
-	speed := i.State.Vel.Len()
-	if speed > i.cfg.MaxSpeed {
-		scale := i.cfg.MaxSpeed / speed
-		i.State.Vel = i.State.Vel.Mul(scale)
+	if s.from != cfg || s.speed != speed || s.turn != turn {
+		s.from, s.speed, s.turn, s.cfg = cfg, speed, turn, *cfg
+		s.cfg.MaxSpeed *= speed
+		s.cfg.MinSpeed *= speed
+		s.cfg.TurnFactor *= turn
 	}
+	return &s.cfg
+}
+
+func (i *individual) makeState() *pb.ActorState {
+	return i.State.ToProto()
 }
 
 // ============================================================================
 // Utilities
 // ============================================================================
 
-func (i *Individual) Log(sys actor.ActorSystem, format string, args ...interface{}) {
-	sys.Logger().Debugf("[%s] "+format, append([]interface{}{i.ID}, args...)...)
+func (i *individual) Log(log Logger, format string, args ...interface{}) {
+	log.Debugf("[%s] "+format, append([]interface{}{i.ID}, args...)...)
 }
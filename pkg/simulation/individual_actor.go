@@ -0,0 +1,82 @@
+//go:build !js
+
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/tochemey/goakt/v3/actor"
+	"github.com/tochemey/goakt/v3/goaktpb"
+)
+
+// individualActor runs an individual as a GoAkt actor, child of the world actor
+type individualActor struct {
+	*individual
+	budget *cpuBudget
+}
+
+var _ actor.Actor = (*individualActor)(nil)
+
+// ============================================================================
+// Actor Lifecycle Hooks
+// ============================================================================
+
+func (i *individualActor) PreStart(ctx *actor.Context) error {
+	i.setID(ctx.ActorName())
+	i.Log(ctx.ActorSystem().Logger(), "Born: %s (%s) at %s",
+		i.ID, i.State.Color, i.State.Pos)
+	return nil
+}
+
+func (i *individualActor) PostStop(ctx *actor.Context) error {
+	i.Log(ctx.ActorSystem().Logger(), "Death: %s", ctx.ActorName())
+	return nil
+}
+
+// ============================================================================
+// Message Handling
+// ============================================================================
+
+// Receive handles the messages of both teams: the movement of the individual lives in its
+// Behavior, swapped by a conversion or a respawn instead of switching the receive function
+func (i *individualActor) Receive(ctx *actor.ReceiveContext) {
+	switch msg := ctx.Message().(type) {
+
+	case *goaktpb.PostStart:
+		i.setID(ctx.Self().Name())
+		i.Log(ctx.Logger(), "%s started in %s mode", i.ID, i.State.Color)
+
+	case *pb.Tick:
+		i.budget.acquire()
+		state := i.handleTick(msg)
+		i.budget.release()
+		i.reportState(ctx, state)
+
+	case *pb.Convert:
+		i.handleConversion(ctx.Logger(), msg)
+
+	case *pb.Respawn:
+		i.handleRespawn(ctx.Logger(), msg)
+
+	case *pb.Move:
+		i.handleMove(msg)
+
+	case *pb.SetStrategy:
+		i.handleSetStrategy(ctx.Logger(), msg)
+
+	case *pb.Order:
+		i.handleOrder(msg)
+
+	case *pb.GetState:
+		ctx.Response(i.makeState())
+
+	default:
+		ctx.Unhandled()
+	}
+}
+
+func (i *individualActor) reportState(ctx *actor.ReceiveContext, state *pb.ActorState) {
+	// Reply to sender (should be World)
+	if ctx.Sender() != nil && ctx.Sender() != ctx.ActorSystem().NoSender() {
+		ctx.Tell(ctx.Sender(), state)
+	}
+}
@@ -0,0 +1,213 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+const (
+	// inspectorPickRadius is the max distance in pixels between a click and an entity to select it
+	inspectorPickRadius = 15.0
+	// inspectorLiveEvery is the number of frames between two live GetState requests
+	inspectorLiveEvery  = 30
+	inspectorAskTimeout = 100 * time.Millisecond
+)
+
+// Inspector lets the user click an entity to select it, once "select entity" is picked in the Click
+// list of the Game (the clicks spawn by default, see mouse.go). It shows a floating info box with the
+// data found in the last snapshot, refreshed with a GetState Ask to the actor.
+type Inspector struct {
+	SelectedID string
+	// Live enables the periodic GetState Ask to the selected actor
+	Live bool
+	// Detached moves the info box into its own floating window
+	Detached bool
+
+	live     *pb.ActorState      // last answer received from the actor
+	liveCh   chan *pb.ActorState // answers from the Ask goroutine
+	inFlight bool
+	frames   int
+}
+
+// NewInspector creates an inspector with live GetState requests enabled
+func NewInspector() *Inspector {
+	return &Inspector{
+		Live:   true,
+		liveCh: make(chan *pb.ActorState, 1),
+	}
+}
+
+// Clear removes the current selection
+func (in *Inspector) Clear() {
+	in.SelectedID = ""
+	in.live = nil
+}
+
+// Select picks the entity of the snapshot closest to (x, y) within inspectorPickRadius.
+// Clicking in the void clears the selection.
+func (in *Inspector) Select(snap *pb.WorldSnapshot, x, y float64) {
+	in.Clear()
+	if snap == nil {
+		return
+	}
+	bestDistSq := inspectorPickRadius * inspectorPickRadius
+	for _, a := range snap.Actors {
+		dx := a.Position.GetX() - x
+		dy := a.Position.GetY() - y
+		if d := dx*dx + dy*dy; d < bestDistSq {
+			bestDistSq = d
+			in.SelectedID = a.Id
+		}
+	}
+}
+
+// Update handles the mouse selection and the live refresh of the selected actor.
+// 'blocked' is true when the cursor is over another UI element (panel, buttons).
+func (in *Inspector) Update(g *Game, blocked bool) {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && !blocked {
+		mx, my := ebiten.CursorPosition()
+		x, y := g.camera.ScreenToWorld(float64(mx), float64(my))
+		in.Select(g.lastState, x, y)
+		in.frames = 0
+	}
+
+	// Drain the answer of a previous Ask (non-blocking)
+	select {
+	case st := <-in.liveCh:
+		in.inFlight = false
+		if st != nil && st.Id == in.SelectedID {
+			in.live = st
+		}
+	default:
+	}
+
+	if in.SelectedID == "" || !in.Live || in.inFlight {
+		return
+	}
+	if in.frames%inspectorLiveEvery == 0 {
+		in.inFlight = true
+		go in.askState(g.ctx, g.engine, in.SelectedID)
+	}
+	in.frames++
+}
+
+// askState runs outside the game loop so a slow actor never stalls the rendering
+func (in *Inspector) askState(ctx context.Context, engine Engine, id string) {
+	ctx, cancel := context.WithTimeout(ctx, inspectorAskTimeout)
+	defer cancel()
+	state, _ := engine.State(ctx, id)
+	in.liveCh <- state
+}
+
+// Draw highlights the selected entity and renders the floating info box next to it.
+// When the inspector is detached, only the selection ring is drawn here and the
+// info is rendered by DrawDetached inside its own window.
+func (in *Inspector) Draw(screen *ebiten.Image, g *Game) {
+	me, msg, ok := in.info(g)
+	if !ok {
+		return
+	}
+
+	// Selection ring
+	x, y := g.camera.WorldToScreen(me.Pos.X, me.Pos.Y)
+	vector.StrokeCircle(screen, float32(x), float32(y), 12, 2,
+		color.RGBA{R: 255, G: 255, B: 0, A: 255}, true)
+
+	if in.Detached {
+		return
+	}
+
+	// Info box, kept inside the screen
+	boxW, boxH := scaled(190), scaled(100)
+	if in.live != nil {
+		boxH += scaled(48)
+	}
+	bx, by := x+scaled(20), y-boxH/2
+	screenW, screenH := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+	if bx+boxW > screenW {
+		bx = x - scaled(20) - boxW
+	}
+	if by < 0 {
+		by = 0
+	} else if by+boxH > screenH {
+		by = screenH - boxH
+	}
+	vector.FillRect(screen, float32(bx), float32(by), float32(boxW), float32(boxH),
+		color.RGBA{R: 20, G: 20, B: 30, A: 220}, true)
+	vector.StrokeRect(screen, float32(bx), float32(by), float32(boxW), float32(boxH), 1,
+		color.RGBA{R: 255, G: 255, B: 0, A: 255}, true)
+	printOverlay(screen, msg, bx+6, by+4)
+}
+
+// DrawDetached renders the info of the selected entity into the canvas of a ui.Window
+func (in *Inspector) DrawDetached(canvas *ebiten.Image, g *Game) {
+	_, msg, ok := in.info(g)
+	if !ok {
+		msg = "Click an entity\nto inspect it"
+		if g.widgetClickMode.Value() != clickSelect {
+			msg = fmt.Sprintf("Pick %q\nin the Click list,\nthen click an entity", clickSelect)
+		}
+	}
+	printOverlay(canvas, msg, 6, 4)
+}
+
+// info finds the selected entity in the last snapshot and formats its description
+func (in *Inspector) info(g *Game) (*Entity, string, bool) {
+	if in.SelectedID == "" || g.lastState == nil {
+		return nil, "", false
+	}
+	var selected *pb.ActorState
+	for _, a := range g.lastState.Actors {
+		if a.Id == in.SelectedID {
+			selected = a
+			break
+		}
+	}
+	if selected == nil {
+		return nil, "", false
+	}
+
+	me := FromProto(selected)
+	friends, enemies := 0, 0
+	visualSq := g.widgetVisualRange.Value * g.widgetVisualRange.Value
+	detectionSq := g.widgetDetectionRadius.Value * g.widgetDetectionRadius.Value
+	for _, a := range g.lastState.Actors {
+		if a.Id == me.ID {
+			continue
+		}
+		distSq := me.Pos.DistanceSquaredTo(GeomVector2DFromProto(a.Position))
+		if a.Color == me.Color {
+			if distSq < visualSq {
+				friends++
+			}
+		} else if distSq < detectionSq {
+			enemies++
+		}
+	}
+
+	msg := fmt.Sprintf("ID:    %s\nColor: %s\nPos:   %s\nVel:   %s (%.2f)\nFriends: %d\nEnemies: %d",
+		me.ID, teamLabel(me.Color), me.Pos, me.Vel, me.Vel.Len(), friends, enemies)
+	if in.live != nil {
+		live := FromProto(in.live)
+		msg += fmt.Sprintf("\n-- live --\nPos:   %s\nVel:   %s", live.Pos, live.Vel)
+	}
+	return me, msg, true
+}
+
+// teamLabel returns the ASCII label of a team color (the Go fonts have no emojis)
+func teamLabel(c pb.TeamColor) string {
+	switch c {
+	case pb.TeamColor_TEAM_RED:
+		return "RED"
+	case NeutralColor:
+		return "NEUTRAL"
+	}
+	return "BLUE"
+}
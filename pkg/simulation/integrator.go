@@ -0,0 +1,27 @@
+package simulation
+
+import "github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+
+// Names of the integration schemes selectable with Config.Integrator. The behaviors turn their
+// steering forces into a new velocity, the scheme decides which velocity moves the entity:
+// with a force 'a' over 'dt' nominal ticks, the semi-implicit scheme overshoots the exact
+// position by a*dt²/2 and the explicit one falls short by as much, which shows at high speeds and
+// large tick multipliers. Verlet is exact for a constant force, the semi-implicit scheme is the
+// only one keeping the energy of a spring-like force (soft boundaries, cohesion) bounded.
+const (
+	IntegratorSemiImplicit = "semi-implicit" // The velocity after the forces (default, the scheme of the previous releases)
+	IntegratorEuler        = "euler"         // The velocity at the start of the tick (explicit Euler)
+	IntegratorVerlet       = "verlet"        // The mean of both (velocity Verlet)
+)
+
+// integrate returns the position reached from 'pos' after 'dt' nominal ticks, the velocity going
+// from 'v0' at the start of the tick to 'v1' after the forces; an empty scheme is IntegratorSemiImplicit
+func integrate(scheme string, pos, v0, v1 geometry.Vector2D, dt float64) geometry.Vector2D {
+	switch scheme {
+	case IntegratorEuler:
+		return pos.Add(v0.Mul(dt))
+	case IntegratorVerlet:
+		return pos.Add(v0.Add(v1).Mul(dt / 2))
+	}
+	return pos.Add(v1.Mul(dt))
+}
@@ -0,0 +1,48 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestEntity_integrators(t *testing.T) {
+	// A constant force over 10 ticks of 4 nominal ticks: x(T) = v0*T + a*T²/2
+	const a, v0, dt, steps = 0.5, 1.0, 4.0, 10
+	T := dt * steps
+	exact := v0*T + a*T*T/2
+	run := func(scheme string) float64 {
+		e := &Entity{Vel: geometry.Vector2D{X: v0}}
+		for range steps {
+			e.beginStep(dt, scheme)
+			e.ApplyForce(geometry.Vector2D{X: a})
+			e.UpdatePhysics()
+		}
+		return e.Pos.X
+	}
+	if got := run(IntegratorVerlet); math.Abs(got-exact) > 1e-9 {
+		t.Errorf("Expected Verlet to be exact for a constant force, got %v instead of %v", got, exact)
+	}
+	want := a * dt * T / 2
+	if got := run(IntegratorEuler); math.Abs(exact-got-want) > 1e-9 {
+		t.Errorf("Expected Euler to fall short by %v, got %v instead of %v", want, got, exact)
+	}
+	if got := run(IntegratorSemiImplicit); math.Abs(got-exact-want) > 1e-9 || run("") != got {
+		t.Errorf("Expected the default semi-implicit scheme to overshoot by %v, got %v instead of %v", want, got, exact)
+	}
+}
+
+func TestConfig_integrator(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, name := range []string{"", IntegratorSemiImplicit, IntegratorEuler, IntegratorVerlet} {
+		cfg.Integrator = name
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected integrator %q to be valid, got %v", name, err)
+		}
+	}
+	cfg.Integrator = "runge-kutta"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown integrator to be rejected")
+	}
+}
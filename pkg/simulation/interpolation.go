@@ -0,0 +1,187 @@
+package simulation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+const (
+	// DefaultInterpolationDelay is how far behind the newest snapshot an Interpolator renders, in snapshots
+	DefaultInterpolationDelay = 2
+	// interpolationBuffer is the number of snapshots kept by an Interpolator
+	interpolationBuffer = 16
+	// interpolationMaxDrift is the distance to the target playhead, in snapshots, beyond which
+	// the playhead jumps instead of catching up smoothly (e.g. after a long stall of the link)
+	interpolationMaxDrift = 8
+	// interpolationCatchUp is the largest speed-up or slow-down of the playback used to go back to the target
+	interpolationCatchUp = 0.1
+)
+
+// Interpolator is a jitter buffer for snapshots arriving irregularly (network viewers, or a
+// simulation ticking at its own rate): it renders a few snapshots behind the newest one and
+// interpolates the entities between the two snapshots around its playhead, so the motion stays
+// smooth when snapshots arrive late, in bursts or not at all.
+// Push and Frame may be called from different goroutines.
+type Interpolator struct {
+	mu    sync.Mutex
+	delay float64
+	// buffered snapshots, ordered by tick
+	buffer []*pb.WorldSnapshot
+	// playhead is the tick rendered, tickDuration the wall time of one tick estimated from the arrivals
+	playhead     float64
+	tickDuration float64 // in seconds
+	lastArrival  time.Time
+	lastFrame    time.Time
+	// ticksPerSnapshot is the estimated spacing of the snapshots in ticks (streams can skip ticks),
+	// snapshotInterval in seconds
+	ticksPerSnapshot float64
+	snapshotInterval float64
+	// out is the interpolated snapshot returned by Frame, rebuilt every frame
+	out *pb.WorldSnapshot
+}
+
+// NewInterpolator renders 'delay' snapshots behind the newest one (DefaultInterpolationDelay when <= 0)
+func NewInterpolator(delay int) *Interpolator {
+	if delay <= 0 {
+		delay = DefaultInterpolationDelay
+	}
+	return &Interpolator{
+		delay:            float64(delay),
+		tickDuration:     TickDuration.Seconds(),
+		ticksPerSnapshot: 1,
+		snapshotInterval: TickDuration.Seconds(),
+		out:              &pb.WorldSnapshot{},
+	}
+}
+
+// Push adds a snapshot received at 'now'. Snapshots older than the newest one are ignored.
+func (ip *Interpolator) Push(snap *pb.WorldSnapshot, now time.Time) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	if n := len(ip.buffer); n > 0 {
+		newest := ip.buffer[n-1]
+		if snap.GetTick() <= newest.GetTick() {
+			return
+		}
+		// Smooth estimates of the spacing of the snapshots, in ticks and in time. Averaged separately,
+		// the snapshots arriving in bursts (no time between them) still give the right tick duration.
+		ticks := float64(snap.GetTick() - newest.GetTick())
+		ip.ticksPerSnapshot += (ticks - ip.ticksPerSnapshot) * 0.1
+		ip.snapshotInterval += (max(now.Sub(ip.lastArrival).Seconds(), 0) - ip.snapshotInterval) * 0.1
+		ip.tickDuration = ip.snapshotInterval / ip.ticksPerSnapshot
+	} else {
+		ip.playhead = float64(snap.GetTick())
+		ip.lastFrame = now
+	}
+	ip.lastArrival = now
+	ip.buffer = append(ip.buffer, snap)
+	if len(ip.buffer) > interpolationBuffer {
+		ip.buffer = ip.buffer[len(ip.buffer)-interpolationBuffer:]
+	}
+}
+
+// Len returns the number of buffered snapshots
+func (ip *Interpolator) Len() int {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	return len(ip.buffer)
+}
+
+// Reset empties the buffer, e.g. when the simulation restarts
+func (ip *Interpolator) Reset() {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	ip.buffer = ip.buffer[:0]
+	ip.ticksPerSnapshot, ip.snapshotInterval = 1, TickDuration.Seconds()
+	ip.tickDuration = ip.snapshotInterval
+}
+
+// Frame advances the playhead to 'now' and returns the snapshot to render, nil before the first Push.
+// The snapshot is reused by the next call: it must not be kept or modified.
+func (ip *Interpolator) Frame(now time.Time) *pb.WorldSnapshot {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	if len(ip.buffer) == 0 {
+		return nil
+	}
+	newest := float64(ip.buffer[len(ip.buffer)-1].GetTick())
+	oldest := float64(ip.buffer[0].GetTick())
+	target := max(oldest, newest-ip.delay*ip.ticksPerSnapshot)
+
+	// Play at the estimated tick rate, a bit faster or slower to stay on the target
+	speed := 1.0
+	switch drift := (target - ip.playhead) / ip.ticksPerSnapshot; {
+	case drift > interpolationMaxDrift || drift < -interpolationMaxDrift:
+		ip.playhead = target
+	case drift > 0.5:
+		speed += interpolationCatchUp
+	case drift < -0.5:
+		speed -= interpolationCatchUp
+	}
+	if ip.tickDuration > 0 {
+		ip.playhead += now.Sub(ip.lastFrame).Seconds() / ip.tickDuration * speed
+	}
+	ip.lastFrame = now
+	// Never past the newest snapshot (no extrapolation) nor before the oldest one
+	ip.playhead = min(max(ip.playhead, oldest), newest)
+
+	// The two snapshots around the playhead
+	i := sort.Search(len(ip.buffer), func(i int) bool { return float64(ip.buffer[i].GetTick()) > ip.playhead })
+	a := ip.buffer[max(i-1, 0)]
+	if i == len(ip.buffer) {
+		return a
+	}
+	b := ip.buffer[i]
+	t := (ip.playhead - float64(a.GetTick())) / float64(b.GetTick()-a.GetTick())
+	interpolateSnapshot(ip.out, a, b, t)
+	// The snapshots older than 'a' will not be rendered again
+	if i > 1 {
+		ip.buffer = append(ip.buffer[:0], ip.buffer[i-1:]...)
+	}
+	return ip.out
+}
+
+// interpolateSnapshot fills 'out' with the entities of 'a' moved towards their position in 'b' by t (0..1).
+// The entities missing from 'b' keep their position in 'a', the counts and game state are those of 'a'.
+func interpolateSnapshot(out, a, b *pb.WorldSnapshot, t float64) {
+	next := make(map[string]*pb.ActorState, len(b.GetActors()))
+	for _, actor := range b.GetActors() {
+		next[actor.Id] = actor
+	}
+	actors := out.Actors[:0]
+	for i, actor := range a.GetActors() {
+		var state *pb.ActorState
+		if i < cap(actors) {
+			actors = actors[:i+1]
+			state = actors[i]
+		}
+		if state == nil {
+			state = &pb.ActorState{Position: &pb.Vector{}, Velocity: &pb.Vector{}}
+			if i < len(actors) {
+				actors[i] = state
+			} else {
+				actors = append(actors, state)
+			}
+		}
+		state.Id, state.Color, state.Generation = actor.Id, actor.Color, actor.Generation
+		pos, vel := actor.GetPosition(), actor.GetVelocity()
+		state.Position.X, state.Position.Y = pos.GetX(), pos.GetY()
+		state.Velocity.X, state.Velocity.Y = vel.GetX(), vel.GetY()
+		// A recycled ID is another entity: do not slide it from the position of its previous life
+		if other, ok := next[actor.Id]; ok && other.Generation == actor.Generation {
+			state.Position.X += (other.GetPosition().GetX() - pos.GetX()) * t
+			state.Position.Y += (other.GetPosition().GetY() - pos.GetY()) * t
+			state.Velocity.X += (other.GetVelocity().GetX() - vel.GetX()) * t
+			state.Velocity.Y += (other.GetVelocity().GetY() - vel.GetY()) * t
+		}
+	}
+	out.Actors = actors
+	out.Tick = a.GetTick()
+	out.RedCount, out.BlueCount = a.GetRedCount(), a.GetBlueCount()
+	out.OffscreenRed, out.OffscreenBlue = a.GetOffscreenRed(), a.GetOffscreenBlue()
+	out.IsGameOver, out.Winner = a.GetIsGameOver(), a.GetWinner()
+	out.Conversions, out.TickDuration = a.GetConversions(), a.GetTickDuration()
+}
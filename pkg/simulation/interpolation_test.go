@@ -0,0 +1,125 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// movingSnapshot returns a snapshot where entity "r1" is at x = 10 * tick
+func movingSnapshot(tick uint64) *pb.WorldSnapshot {
+	return &pb.WorldSnapshot{Tick: tick, RedCount: 1, Actors: []*pb.ActorState{
+		{Id: "r1", Color: pb.TeamColor_TEAM_RED, Position: &pb.Vector{X: 10 * float64(tick)}, Velocity: &pb.Vector{X: 10}},
+	}}
+}
+
+func TestInterpolateSnapshot(t *testing.T) {
+	a := &pb.WorldSnapshot{Tick: 4, RedCount: 2, BlueCount: 1, Actors: []*pb.ActorState{
+		{Id: "r1", Position: &pb.Vector{X: 0, Y: 10}, Velocity: &pb.Vector{X: 1}},
+		{Id: "r2", Position: &pb.Vector{X: 50, Y: 50}},
+		{Id: "b1", Generation: 1, Position: &pb.Vector{X: 100, Y: 100}},
+	}}
+	b := &pb.WorldSnapshot{Tick: 6, RedCount: 1, BlueCount: 2, Actors: []*pb.ActorState{
+		{Id: "b1", Generation: 2, Position: &pb.Vector{X: 0, Y: 0}},
+		{Id: "r1", Position: &pb.Vector{X: 20, Y: 30}, Velocity: &pb.Vector{X: 3}},
+	}}
+	out := &pb.WorldSnapshot{}
+	interpolateSnapshot(out, a, b, 0.5)
+
+	if out.Tick != 4 || out.RedCount != 2 || out.BlueCount != 1 || len(out.Actors) != 3 {
+		t.Fatalf("Expected the state of the older snapshot, got %v", out)
+	}
+	if r1 := out.Actors[0]; r1.Position.X != 10 || r1.Position.Y != 20 || r1.Velocity.X != 2 {
+		t.Errorf("Expected r1 half-way, got %v", r1)
+	}
+	// r2 is gone in b, b1 is another entity reusing the ID: both stay where they were
+	if r2 := out.Actors[1]; r2.Position.X != 50 || r2.Position.Y != 50 {
+		t.Errorf("Expected r2 not to move, got %v", r2)
+	}
+	if b1 := out.Actors[2]; b1.Position.X != 100 || b1.Generation != 1 {
+		t.Errorf("Expected the recycled b1 not to slide, got %v", b1)
+	}
+
+	// The output is rebuilt in place, shorter when entities left
+	interpolateSnapshot(out, b, b, 0)
+	if len(out.Actors) != 2 || out.Actors[0].Id != "b1" || out.Actors[1].Position.X != 20 {
+		t.Errorf("Expected the actors of b, got %v", out.Actors)
+	}
+	if a.Actors[0].Position.X != 0 {
+		t.Error("Expected the input snapshots to be left untouched")
+	}
+}
+
+func TestInterpolator_empty(t *testing.T) {
+	ip := NewInterpolator(0)
+	if frame := ip.Frame(time.Now()); frame != nil {
+		t.Errorf("Expected no frame before the first snapshot, got %v", frame)
+	}
+}
+
+func TestInterpolator_steadyStream(t *testing.T) {
+	ip := NewInterpolator(2)
+	start := time.Unix(0, 0)
+	frameDuration := TickDuration / 4
+	lastX := 10.0
+	for tick := uint64(1); tick <= 40; tick++ {
+		now := start.Add(time.Duration(tick) * TickDuration)
+		ip.Push(movingSnapshot(tick), now)
+		for f := 0; f < 4; f++ {
+			frame := ip.Frame(now.Add(time.Duration(f) * frameDuration))
+			x := frame.Actors[0].Position.X
+			if x < lastX || x-lastX > 10*1.2/4+1e-9 {
+				t.Fatalf("Tick %d frame %d: x moved from %v to %v, expected a smooth motion", tick, f, lastX, x)
+			}
+			lastX = x
+		}
+	}
+	// Rendered about two snapshots behind the newest one (x = 400 at tick 40)
+	if lag := 400 - lastX; math.Abs(lag-20) > 10 {
+		t.Errorf("Expected to render ~2 ticks behind the stream, got %v ticks", lag/10)
+	}
+	if ip.Len() > 4 {
+		t.Errorf("Expected the rendered snapshots to be dropped, %d buffered", ip.Len())
+	}
+}
+
+func TestInterpolator_jitterAndLoss(t *testing.T) {
+	ip := NewInterpolator(3)
+	start := time.Unix(0, 0)
+	frameDuration := TickDuration / 2
+	lastX := 10.0
+	next := uint64(1)
+	for frame := 0; frame < 200; frame++ {
+		now := start.Add(time.Duration(frame) * frameDuration)
+		// Snapshots arrive in bursts of three every three ticks, one in five is lost
+		if frame%6 == 0 {
+			for range 3 {
+				if next%5 != 0 {
+					ip.Push(movingSnapshot(next), now)
+				}
+				next++
+			}
+		}
+		x := ip.Frame(now).Actors[0].Position.X
+		if x < lastX {
+			t.Fatalf("Frame %d: x went back from %v to %v", frame, lastX, x)
+		}
+		if x-lastX > 10 {
+			t.Fatalf("Frame %d: x jumped from %v to %v", frame, lastX, x)
+		}
+		lastX = x
+	}
+
+	// Late and duplicate snapshots are ignored
+	n := ip.Len()
+	ip.Push(movingSnapshot(1), start)
+	if ip.Len() != n {
+		t.Errorf("Expected an old snapshot to be ignored")
+	}
+	ip.Reset()
+	if ip.Len() != 0 {
+		t.Errorf("Expected an empty buffer after Reset")
+	}
+}
@@ -0,0 +1,236 @@
+package simulation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui/text"
+)
+
+// KeyAction is something the player can do from the keyboard, see KeyBindings
+type KeyAction string
+
+const (
+	ActionPause           KeyAction = "pause"
+	ActionRestart         KeyAction = "restart"
+	ActionTogglePanel     KeyAction = "togglePanel"
+	ActionToggleDetection KeyAction = "toggleDetection"
+	ActionSpeedUp         KeyAction = "speedUp"
+	ActionSpeedDown       KeyAction = "speedDown"
+	ActionScreenshot      KeyAction = "screenshot"
+	ActionExpandChart     KeyAction = "expandChart"
+	ActionResetCamera     KeyAction = "resetCamera"
+	ActionFullscreen      KeyAction = "fullscreen"
+	ActionHelp            KeyAction = "help"
+	// ActionScare is held, not pressed: the entities near the cursor flee it meanwhile (see updateScare)
+	ActionScare KeyAction = "scare"
+)
+
+// gameSpeeds are the simulation speeds offered by ActionSpeedUp and ActionSpeedDown, in ticks per frame
+var gameSpeeds = []float64{0.25, 0.5, 1, 2, 4}
+
+// defaultGameSpeed is the index of x1 in gameSpeeds
+const defaultGameSpeed = 2
+
+// KeyBinding is one keyboard shortcut of the Game
+type KeyBinding struct {
+	Key         ebiten.Key
+	Action      KeyAction
+	Description string
+}
+
+// gameKeyBindings are the keyboard shortcuts of the Game, listed in this order by the help overlay
+var gameKeyBindings = []KeyBinding{
+	{ebiten.KeySpace, ActionPause, "Pause / resume"},
+	{ebiten.KeyR, ActionRestart, "Restart the simulation"},
+	{ebiten.KeyTab, ActionTogglePanel, "Show / hide the panel"},
+	{ebiten.KeyD, ActionToggleDetection, "Show / hide the detection circles"},
+	{ebiten.KeyUp, ActionSpeedUp, "Speed up"},
+	{ebiten.KeyDown, ActionSpeedDown, "Slow down"},
+	{ebiten.KeyC, ActionExpandChart, "Expand / shrink the population chart"},
+	{ebiten.KeyHome, ActionResetCamera, "Show the whole world"},
+	{ebiten.KeyF11, ActionFullscreen, "Full screen / window"},
+	{ebiten.KeyH, ActionHelp, "Show / hide this help"},
+	// Not S: a screenshotKey cannot reuse a key of the Game, and S was a common choice for it
+	{ebiten.KeyX, ActionScare, "Hold to scare the entities near the cursor away"},
+}
+
+// KeyBindings returns the keyboard shortcuts of the Game followed by the screenshot key of
+// cfg.ScreenshotKey, left out when the files cannot be written (browser)
+func KeyBindings(cfg *Config) []KeyBinding {
+	bindings := gameKeyBindings[:len(gameKeyBindings):len(gameKeyBindings)]
+	if canWriteFiles {
+		// Validated with the config: an invalid name only comes from a config never validated
+		if key, err := screenshotKey(cfg); err == nil {
+			bindings = append(bindings, KeyBinding{key, ActionScreenshot, "Save a screenshot"})
+		}
+	}
+	return bindings
+}
+
+// keyAction returns the action bound to 'key', false when the key is free
+func keyAction(bindings []KeyBinding, key ebiten.Key) (KeyAction, bool) {
+	for _, b := range bindings {
+		if b.Key == key {
+			return b.Action, true
+		}
+	}
+	return "", false
+}
+
+// checkScreenshotKey rejects an unknown screenshotKey, or one already bound to another shortcut of the Game
+func checkScreenshotKey(cfg *Config) error {
+	key, err := screenshotKey(cfg)
+	if err != nil {
+		return err
+	}
+	if action, taken := keyAction(gameKeyBindings, key); taken {
+		return fmt.Errorf("screenshotKey %q is already bound to %s", screenshotKeyName(cfg), action)
+	}
+	return nil
+}
+
+// handleKeys runs the action of every key just pressed, unless a text input of the panel has the keyboard
+func (g *Game) handleKeys() {
+	if ui.KeyboardCaptured() {
+		return
+	}
+	for _, b := range g.keyBindings {
+		if !inpututil.IsKeyJustPressed(b.Key) {
+			continue
+		}
+		if action := g.keyActions[b.Action]; action != nil {
+			action()
+		}
+	}
+}
+
+// updateScare makes the entities around the cursor flee it while the key of ActionScare is held over
+// the world, the classic boids demo interaction: the world adds the push to their perception.
+// 'blocked' is true when the cursor is over the UI.
+func (g *Game) updateScare(blocked bool) {
+	key, ok := bindingKey(g.keyBindings, ActionScare)
+	if g.cfg.Engine == EngineECS {
+		if ok && !blocked && !ui.KeyboardCaptured() && inpututil.IsKeyJustPressed(key) {
+			g.engine.Logger().Infof("The %s engine cannot scare the entities", EngineECS)
+		}
+		return
+	}
+	if ok && !blocked && !ui.KeyboardCaptured() && ebiten.IsKeyPressed(key) {
+		mx, my := ebiten.CursorPosition()
+		x, y := g.camera.ScreenToWorld(float64(mx), float64(my))
+		if x >= 0 && x <= g.cfg.WorldWidth && y >= 0 && y <= g.cfg.WorldHeight {
+			g.scare.Set(geometry.Vector2D{X: x, Y: y})
+			return
+		}
+	}
+	g.scare.Stop()
+}
+
+// bindingKey returns the key bound to 'action', false when there is none
+func bindingKey(bindings []KeyBinding, action KeyAction) (ebiten.Key, bool) {
+	for _, b := range bindings {
+		if b.Action == action {
+			return b.Key, true
+		}
+	}
+	return 0, false
+}
+
+// keyActionFuncs maps every KeyAction to what it does on the Game
+func (g *Game) keyActionFuncs() map[KeyAction]func() {
+	return map[KeyAction]func(){
+		ActionPause: func() {
+			g.paused = !g.paused
+		},
+		ActionRestart: func() {
+			g.restartRequested = true
+		},
+		ActionTogglePanel: func() {
+			g.panel.Toggle()
+		},
+		ActionToggleDetection: func() {
+			g.widgetDisplayDetection.Value = !g.widgetDisplayDetection.Value
+		},
+		ActionSpeedUp: func() {
+			g.setSpeed(g.speed + 1)
+		},
+		ActionSpeedDown: func() {
+			g.setSpeed(g.speed - 1)
+		},
+		ActionScreenshot: g.requestScreenshot,
+		ActionExpandChart: func() {
+			g.chartExpanded = !g.chartExpanded
+		},
+		ActionResetCamera: func() {
+			g.camera.Reset()
+			g.trails.SetViewport(g.camera.Visible())
+		},
+		ActionFullscreen: func() {
+			// Layout follows the new size of the screen
+			ebiten.SetFullscreen(!ebiten.IsFullscreen())
+		},
+		ActionHelp: func() {
+			g.showHelp = !g.showHelp
+		},
+	}
+}
+
+// setSpeed selects gameSpeeds[i], clamped to the speeds offered
+func (g *Game) setSpeed(i int) {
+	g.speed = max(0, min(i, len(gameSpeeds)-1))
+	if g.clock != nil {
+		g.clock.SetSpeed(gameSpeeds[g.speed])
+	}
+}
+
+// speedLabel shows the current speed, or that the simulation is paused
+func (g *Game) speedLabel() string {
+	if g.paused {
+		return "PAUSED"
+	}
+	return fmt.Sprintf("Speed: x%g", gameSpeeds[g.speed])
+}
+
+// ticksDue returns the number of ticks to send this frame at the current speed:
+// below x1 a tick is only sent every few frames
+func (g *Game) ticksDue() int {
+	g.tickBudget += gameSpeeds[g.speed]
+	n := int(g.tickBudget)
+	g.tickBudget -= float64(n)
+	return n
+}
+
+// helpText lists the key bindings, one per line, then what the clicks on the world do in 'clickMode'
+func helpText(bindings []KeyBinding, clickMode string) string {
+	var b strings.Builder
+	b.WriteString("Keyboard shortcuts\n\n")
+	for _, binding := range bindings {
+		fmt.Fprintf(&b, "%-10s %s\n", binding.Key, binding.Description)
+	}
+	fmt.Fprintf(&b, "\nMouse (Click list of the panel: %s)\n\n%s\n", clickMode, clickHelp[clickMode])
+	if clickMode != clickSelect {
+		fmt.Fprintf(&b, "Pick %q in the Click list to inspect an entity\n", clickSelect)
+	}
+	b.WriteString("Right drag pans, the wheel zooms on the cursor\n")
+	return b.String()
+}
+
+// drawHelp lists the key bindings and the clicks in a box at the center of the screen, toggled with H
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	if !g.showHelp {
+		return
+	}
+	help := helpText(g.keyBindings, g.widgetClickMode.Value())
+	w, h := text.Measure(help, overlayText)
+	pad := scaled(10)
+	w, h = w+2*pad, h+2*pad
+	x, y := g.layout.Place(ui.AnchorCenter, w, h)
+	vector.FillRect(screen, float32(x), float32(y), float32(w), float32(h), chartBackground, true)
+	printOverlay(screen, help, x+pad, y+pad)
+}
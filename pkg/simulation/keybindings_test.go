@@ -0,0 +1,71 @@
+package simulation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestKeyBindings(t *testing.T) {
+	cfg := DefaultConfig()
+	bindings := KeyBindings(cfg)
+	seen := map[ebiten.Key]KeyAction{}
+	for _, b := range bindings {
+		if other, ok := seen[b.Key]; ok {
+			t.Errorf("Key %s bound to %s and %s", b.Key, other, b.Action)
+		}
+		seen[b.Key] = b.Action
+	}
+	if action, _ := keyAction(bindings, ebiten.KeySpace); action != ActionPause {
+		t.Errorf("Expected Space to pause, got %q", action)
+	}
+	if action, _ := keyAction(bindings, ebiten.KeyF11); action != ActionFullscreen {
+		t.Errorf("Expected F11 to toggle the full screen, got %q", action)
+	}
+	if action, ok := keyAction(bindings, ebiten.KeyF12); canWriteFiles && (!ok || action != ActionScreenshot) {
+		t.Errorf("Expected F12 to take a screenshot, got %q", action)
+	}
+	if key, ok := bindingKey(bindings, ActionScare); !ok || key != ebiten.KeyX {
+		t.Errorf("Expected X held to scare, got %s", key)
+	}
+	if text := helpText(bindings, clickSpawn); !strings.Contains(text, "Pause / resume") {
+		t.Errorf("Expected the help to list the pause, got %q", text)
+	}
+	if text := helpText(bindings, clickSpawn); !strings.Contains(text, clickHelp[clickSpawn]) || !strings.Contains(text, `"select entity"`) {
+		t.Errorf("Expected the help to tell what a click does and how to inspect, got %q", text)
+	}
+	if text := helpText(bindings, clickSelect); !strings.Contains(text, clickHelp[clickSelect]) || strings.Contains(text, "Pick") {
+		t.Errorf("Expected the help to tell that a click selects, got %q", text)
+	}
+
+	cfg.ScreenshotKey = "Space"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a screenshotKey bound to another action to be rejected")
+	}
+	cfg.ScreenshotKey = "S"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected S free for the screenshots, got %v", err)
+	}
+}
+
+func TestGame_ticksDue(t *testing.T) {
+	g := &Game{speed: defaultGameSpeed}
+	for range 10 {
+		if n := g.ticksDue(); n != 1 {
+			t.Fatalf("Expected one tick per frame at x1, got %d", n)
+		}
+	}
+	g.setSpeed(len(gameSpeeds)) // clamped to the fastest
+	if n := g.ticksDue(); n != int(gameSpeeds[len(gameSpeeds)-1]) {
+		t.Errorf("Expected %g ticks per frame, got %d", gameSpeeds[len(gameSpeeds)-1], n)
+	}
+	g.setSpeed(-1) // clamped to x0.25
+	total := 0
+	for range 8 {
+		total += g.ticksDue()
+	}
+	if total != 2 {
+		t.Errorf("Expected 2 ticks in 8 frames at x0.25, got %d", total)
+	}
+}
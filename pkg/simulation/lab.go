@@ -0,0 +1,413 @@
+package simulation
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+//go:embed web/lab.html
+var labPage []byte
+
+// LabRun is the metadata of the run controlled by a Lab
+type LabRun struct {
+	ID       string            `json:"id"` // See NewRunID, names the directory of the stills of the run
+	Started  time.Time         `json:"started"`
+	Engine   string            `json:"engine"`
+	Seed     uint64            `json:"seed"`
+	Restarts int               `json:"restarts"`
+	Labels   map[string]string `json:"labels,omitempty"` // Set with SetLabel (version, host...)
+}
+
+// LabStatus is the state of the simulation reported by the Lab API
+type LabStatus struct {
+	Run        LabRun              `json:"run"`
+	Tick       uint64              `json:"tick"`
+	Red        int32               `json:"red"`
+	Blue       int32               `json:"blue"`
+	GameOver   bool                `json:"gameOver"`
+	Winner     string              `json:"winner,omitempty"`
+	Paused     bool                `json:"paused"`
+	Config     *Config             `json:"config"`
+	Strategies map[string]string   `json:"strategies"` // Current strategy per team
+	Behaviors  map[string][]string `json:"behaviors"`  // Strategies available per team
+	Parameters []PanelParameter    `json:"parameters"` // Sliders of the dashboard
+}
+
+// Lab runs a simulation without the Ebiten client and serves its control API with a dashboard page:
+// live view of the world, population chart, the sliders of the UIPanel, pause, resume and restart.
+//
+//	GET  /              dashboard
+//	GET  /ws            live view stream (see LiveView)
+//	GET  /snapshots     complete snapshots as newline-delimited JSON, ?every=N (see clients/)
+//	GET  /api/status    LabStatus
+//	GET  /api/history   population of the last minute, []PopulationSample
+//	PUT  /api/config    change the config (JSON object with the fields to change, population on restart)
+//	POST /api/commands  apply a JSON array of LabCommand at the next tick boundary
+//	POST /api/pause, /api/resume, /api/restart
+type Lab struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	cfg       *Config
+	newEngine EngineFactory
+	worldOpts []WorldOption
+	engine    Engine
+	clock     *SimClock
+	detach    context.CancelFunc // Stops consuming the snapshots of the current world
+	latest    *pb.WorldSnapshot
+	history   *PopulationHistory
+	paused    bool
+	run       LabRun
+	live      *LiveView
+	stop      context.CancelFunc
+	// commands are drained by every world the Lab spawns (see WithCommandQueue)
+	commands *CommandQueue
+}
+
+// LabCommand is one mutation requested with POST /api/commands, see CommandQueue
+type LabCommand struct {
+	Op    string  `json:"op"`             // spawn, despawn, convert, teleport or set
+	ID    string  `json:"id,omitempty"`   // Entity of despawn, convert and teleport
+	Team  string  `json:"team,omitempty"` // RED or BLUE, for spawn and convert
+	X     float64 `json:"x,omitempty"`    // Position and velocity of spawn and teleport
+	Y     float64 `json:"y,omitempty"`
+	VX    float64 `json:"vx,omitempty"`
+	VY    float64 `json:"vy,omitempty"`
+	Name  string  `json:"name,omitempty"` // Config field of set, by JSON name (e.g. maxSpeed)
+	Value string  `json:"value,omitempty"`
+}
+
+// NewLab spawns the world with the engine selected by cfg (newEngine by default) and ticks it with
+// a SimClock at cfg.SimRate, SimTicksPerSecond when it is not set. The world publishes its snapshots
+// to 'hub', which may be shared with other observers (gRPC, recording); a new hub is used when nil.
+func NewLab(ctx context.Context, cfg *Config, newEngine EngineFactory, hub *SnapshotHub, opts ...WorldOption) (*Lab, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if hub == nil {
+		hub = NewSnapshotHub()
+		opts = append(opts[:len(opts):len(opts)], WithSnapshotHub(hub))
+	}
+	commands := NewCommandQueue()
+	opts = append(opts[:len(opts):len(opts)], WithCommandQueue(commands))
+	ctx, stop := context.WithCancel(ctx)
+	l := &Lab{
+		ctx:       ctx,
+		cfg:       cfg,
+		newEngine: newEngine,
+		worldOpts: opts,
+		latest:    &pb.WorldSnapshot{},
+		history:   NewPopulationHistory(DefaultHistoryTicks),
+		run:       LabRun{ID: NewRunID(cfg.Seed), Started: time.Now(), Engine: cfg.Engine, Seed: cfg.Seed, Labels: map[string]string{}},
+		live:      NewLiveView(hub, cfg),
+		stop:      stop,
+		commands:  commands,
+	}
+	if l.run.Engine == "" {
+		l.run.Engine = "default"
+	}
+	if err := l.spawn(); err != nil {
+		stop()
+		return nil, err
+	}
+	return l, nil
+}
+
+// spawn starts a world with the current config, the lock must be held (or the Lab not shared yet)
+func (l *Lab) spawn() error {
+	snapshotCh := make(chan *pb.WorldSnapshot, 10)
+	engine, err := SelectEngine(l.cfg, l.newEngine)(l.ctx, snapshotCh, l.cfg, l.worldOpts...)
+	if err != nil {
+		return fmt.Errorf("cannot spawn world: %w", err)
+	}
+	l.engine = engine
+	// A channel per world: the late snapshots of the previous one are never mixed with the new ones
+	ctx, detach := context.WithCancel(l.ctx)
+	l.detach = detach
+	// The stills of a restarted run go to a directory of their own
+	runID := l.run.ID
+	if l.run.Restarts > 0 {
+		runID = fmt.Sprintf("%s-restart%d", runID, l.run.Restarts)
+	}
+	go l.consume(ctx, snapshotCh, NewThumbnails(l.cfg, runID))
+	rate := l.cfg.SimRate
+	if rate <= 0 {
+		rate = SimTicksPerSecond
+	}
+	l.clock = StartSimClock(l.ctx, engine, rate)
+	l.clock.SetPaused(l.paused)
+	return nil
+}
+
+// consume keeps the last snapshot and the population history, pauses the clock once the game is over
+// and saves the stills of 'thumbnails'
+func (l *Lab) consume(ctx context.Context, snapshotCh <-chan *pb.WorldSnapshot, thumbnails *Thumbnails) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case snap := <-snapshotCh:
+			l.mu.Lock()
+			if ctx.Err() == nil {
+				l.latest = snap
+				l.history.Add(snap)
+				l.clock.SetPaused(l.paused || snap.GetIsGameOver())
+			}
+			engine := l.engine
+			l.mu.Unlock()
+			if err := thumbnails.Observe(snap); err != nil {
+				engine.Logger().Errorf("Thumbnail failed: %v", err)
+			}
+		}
+	}
+}
+
+// SetLabel adds an entry to the metadata of the run shown by the dashboard
+func (l *Lab) SetLabel(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.run.Labels[key] = value
+}
+
+// Status returns the current state of the simulation
+func (l *Lab) Status() LabStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	run := l.run
+	run.Labels = maps.Clone(l.run.Labels)
+	strategies := make(map[string]string, 2)
+	behaviors := make(map[string][]string, 2)
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		strategies[teamLabel(team)] = l.cfg.StrategyFor(team)
+		behaviors[teamLabel(team)] = BehaviorNames(team)
+	}
+	cfg := *l.cfg
+	return LabStatus{
+		Run:        run,
+		Tick:       l.latest.GetTick(),
+		Red:        l.latest.GetRedCount(),
+		Blue:       l.latest.GetBlueCount(),
+		GameOver:   l.latest.GetIsGameOver(),
+		Winner:     l.latest.GetWinner(),
+		Paused:     l.paused,
+		Config:     &cfg,
+		Strategies: strategies,
+		Behaviors:  behaviors,
+		Parameters: PanelParameters,
+	}
+}
+
+// History returns the population samples of the last minute, oldest first
+func (l *Lab) History() []PopulationSample {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	samples := make([]PopulationSample, l.history.Len())
+	for i := range samples {
+		samples[i] = l.history.At(i)
+	}
+	return samples
+}
+
+// ApplyConfig sends the live parameters and the strategies of 'cfg' to the world, the populations
+// are used by the next Restart. The config is not modified afterwards.
+func (l *Lab) ApplyConfig(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if _, err := NewBehavior(cfg.StrategyFor(team)); err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.engine.Send(l.ctx, cfg.UpdateMessage()); err != nil {
+		return err
+	}
+	if cfg.WindStrength != l.cfg.WindStrength || cfg.WindDirection != l.cfg.WindDirection ||
+		cfg.WindMode != l.cfg.WindMode || cfg.WindPeriod != l.cfg.WindPeriod {
+		// The wind is not part of UpdateConfig: it reaches the world through the command queue
+		strength, direction, mode, period := cfg.WindStrength, cfg.WindDirection, cfg.WindMode, cfg.WindPeriod
+		l.commands.UpdateConfig(func(c *Config) {
+			c.WindStrength, c.WindDirection, c.WindMode, c.WindPeriod = strength, direction, mode, period
+		})
+	}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if name := cfg.StrategyFor(team); name != l.cfg.StrategyFor(team) {
+			if err := l.engine.Send(l.ctx, &pb.SetStrategy{Team: team, Name: name}); err != nil {
+				return err
+			}
+		}
+	}
+	// The live view and the thumbnails keep a pointer to the previous config: it is replaced, never modified
+	l.cfg = cfg
+	return nil
+}
+
+// Queue checks every command, then queues them all to be applied at the same tick boundary:
+// none is queued when one is invalid. The commands still pending on Restart go to the new world.
+func (l *Lab) Queue(cmds []LabCommand) error {
+	l.mu.Lock()
+	cfg := l.cfg
+	l.mu.Unlock()
+	if cfg.Engine == EngineECS {
+		return fmt.Errorf("the %s engine does not accept commands", EngineECS)
+	}
+	batch := NewCommandQueue()
+	for i, cmd := range cmds {
+		if err := cmd.queue(batch, cfg); err != nil {
+			return fmt.Errorf("command %d (%s): %w", i, cmd.Op, err)
+		}
+	}
+	l.commands.Append(batch)
+	return nil
+}
+
+// queue checks the command and adds it to 'q'
+func (c LabCommand) queue(q *CommandQueue, cfg *Config) error {
+	pos, vel := geometry.Vector2D{X: c.X, Y: c.Y}, geometry.Vector2D{X: c.VX, Y: c.VY}
+	if (c.Op == "spawn" || c.Op == "teleport") &&
+		(c.X < 0 || c.X > cfg.WorldWidth || c.Y < 0 || c.Y > cfg.WorldHeight) {
+		return fmt.Errorf("(%g, %g) is outside the world", c.X, c.Y)
+	}
+	if (c.Op == "despawn" || c.Op == "convert" || c.Op == "teleport") && c.ID == "" {
+		return fmt.Errorf("missing id")
+	}
+	switch c.Op {
+	case "spawn", "convert":
+		team, err := parseTeam(c.Team)
+		if err != nil {
+			return err
+		}
+		if c.Op == "spawn" {
+			q.Spawn(team, pos, vel)
+		} else {
+			q.Convert(c.ID, team)
+		}
+	case "despawn":
+		q.Despawn(c.ID)
+	case "teleport":
+		q.Teleport(c.ID, pos, vel)
+	case "set":
+		return q.SetParameter(c.Name, c.Value)
+	default:
+		return fmt.Errorf("unknown op %q (use spawn, despawn, convert, teleport or set)", c.Op)
+	}
+	return nil
+}
+
+// SetPaused suspends or resumes the simulation
+func (l *Lab) SetPaused(paused bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paused = paused
+	l.clock.SetPaused(paused || l.latest.GetIsGameOver())
+}
+
+// Restart stops the world and spawns a new one with the current config
+func (l *Lab) Restart() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.detach()
+	l.clock.Stop()
+	_ = l.engine.Stop(l.ctx)
+	l.latest = &pb.WorldSnapshot{}
+	l.history.Reset()
+	l.run.Restarts++
+	l.run.Seed = l.cfg.Seed
+	return l.spawn()
+}
+
+// Stop stops the simulation, the handlers then report the last state
+func (l *Lab) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.detach()
+	l.clock.Stop()
+	err := l.engine.Stop(ctx)
+	l.stop()
+	return err
+}
+
+// Handler returns the http.Handler of the dashboard and of the control API
+func (l *Lab) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(labPage)
+	})
+	mux.HandleFunc("GET /ws", l.live.serveStream)
+	mux.HandleFunc("GET /snapshots", l.live.serveSnapshots)
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, l.Status())
+	})
+	mux.HandleFunc("GET /api/history", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, l.History())
+	})
+	mux.HandleFunc("PUT /api/config", l.serveConfig)
+	mux.HandleFunc("POST /api/commands", l.serveCommands)
+	mux.HandleFunc("POST /api/pause", func(w http.ResponseWriter, _ *http.Request) {
+		l.SetPaused(true)
+		writeJSON(w, http.StatusOK, l.Status())
+	})
+	mux.HandleFunc("POST /api/resume", func(w http.ResponseWriter, _ *http.Request) {
+		l.SetPaused(false)
+		writeJSON(w, http.StatusOK, l.Status())
+	})
+	mux.HandleFunc("POST /api/restart", func(w http.ResponseWriter, _ *http.Request) {
+		if err := l.Restart(); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, l.Status())
+	})
+	return mux
+}
+
+// serveConfig applies the fields of the JSON object of the request to the current config
+func (l *Lab) serveConfig(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	cfg := *l.cfg
+	l.mu.Unlock()
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid config: %w", err))
+		return
+	}
+	if err := l.ApplyConfig(&cfg); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, l.Status())
+}
+
+// serveCommands queues the JSON array of LabCommand of the request
+func (l *Lab) serveCommands(w http.ResponseWriter, r *http.Request) {
+	var cmds []LabCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmds); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid commands: %w", err))
+		return
+	}
+	if err := l.Queue(cmds); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]int{"queued": len(cmds)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,183 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLab(t *testing.T) (*Lab, *httptest.Server) {
+	t.Helper()
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.Seed = 1
+	cfg.SimRate = 500
+	cfg.NumRedAtStart, cfg.NumBlueAtStart = 3, 30
+	lab, err := NewLab(ctx, cfg, NewLocalEngine, nil)
+	if err != nil {
+		t.Fatalf("NewLab failed: %v", err)
+	}
+	lab.SetLabel("version", "test")
+	server := httptest.NewServer(lab.Handler())
+	t.Cleanup(func() {
+		server.Close()
+		_ = lab.Stop(ctx)
+	})
+	return lab, server
+}
+
+// labCall sends a request to the lab API and decodes its JSON answer
+func labCall(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer res.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid JSON: %v", method, url, err)
+		}
+	}
+	return res.StatusCode
+}
+
+// waitForTick polls the status until the simulation reached 'tick'
+func waitForTick(t *testing.T, url string, tick uint64) LabStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var status LabStatus
+		labCall(t, "GET", url+"/api/status", "", &status)
+		if status.Tick >= tick {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Simulation stuck at tick %d, expected %d", status.Tick, tick)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLab_status(t *testing.T) {
+	_, server := newTestLab(t)
+	status := waitForTick(t, server.URL, 10)
+	if status.Red+status.Blue != 33 || status.Run.Seed != 1 || status.Run.Labels["version"] != "test" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if len(status.Parameters) != len(PanelParameters) || len(status.Behaviors["RED"]) == 0 {
+		t.Errorf("Expected the panel parameters and the behaviors, got %+v", status)
+	}
+	var history []PopulationSample
+	labCall(t, "GET", server.URL+"/api/history", "", &history)
+	if len(history) == 0 || history[len(history)-1].Red+history[len(history)-1].Blue != 33 {
+		t.Errorf("Expected the population history, got %v", history)
+	}
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); res.StatusCode != http.StatusOK || !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Expected the dashboard page, got %d %s", res.StatusCode, ct)
+	}
+}
+
+func TestLab_config(t *testing.T) {
+	_, server := newTestLab(t)
+	var status LabStatus
+	if code := labCall(t, "PUT", server.URL+"/api/config", `{"maxSpeed": 7.5, "numBlueAtStart": 40}`, &status); code != http.StatusOK {
+		t.Fatalf("Expected the change to be accepted, got %d", code)
+	}
+	if status.Config.MaxSpeed != 7.5 || status.Config.NumBlueAtStart != 40 || status.Config.ContactRadius != DefaultConfig().ContactRadius {
+		t.Errorf("Expected only the sent fields to change, got %+v", status.Config)
+	}
+
+	var failure map[string]string
+	if code := labCall(t, "PUT", server.URL+"/api/config", `{"minSpeed": 9}`, &failure); code != http.StatusUnprocessableEntity || failure["error"] == "" {
+		t.Errorf("Expected an invalid config to be rejected, got %d %v", code, failure)
+	}
+	if code := labCall(t, "PUT", server.URL+"/api/config", `{"redStrategy": "no-such-strategy"}`, &failure); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected an unknown strategy to be rejected, got %d", code)
+	}
+	if code := labCall(t, "PUT", server.URL+"/api/config", `not json`, &failure); code != http.StatusBadRequest {
+		t.Errorf("Expected a malformed body to be rejected, got %d", code)
+	}
+
+	// The population applies on restart
+	labCall(t, "POST", server.URL+"/api/restart", "", &status)
+	if status.Run.Restarts != 1 {
+		t.Errorf("Expected one restart, got %d", status.Run.Restarts)
+	}
+	status = waitForTick(t, server.URL, 5)
+	if status.Red+status.Blue != 43 {
+		t.Errorf("Expected the new population after the restart, got %d red and %d blue", status.Red, status.Blue)
+	}
+}
+
+func TestLab_pause(t *testing.T) {
+	_, server := newTestLab(t)
+	waitForTick(t, server.URL, 5)
+	var status LabStatus
+	labCall(t, "POST", server.URL+"/api/pause", "", &status)
+	if !status.Paused {
+		t.Fatal("Expected the simulation to be paused")
+	}
+	time.Sleep(50 * time.Millisecond) // Last tick in flight
+	labCall(t, "GET", server.URL+"/api/status", "", &status)
+	paused := status.Tick
+	time.Sleep(100 * time.Millisecond)
+	labCall(t, "GET", server.URL+"/api/status", "", &status)
+	if status.Tick != paused {
+		t.Errorf("Expected no tick while paused, went from %d to %d", paused, status.Tick)
+	}
+	labCall(t, "POST", server.URL+"/api/resume", "", &status)
+	waitForTick(t, server.URL, paused+5)
+}
+
+func TestLab_commands(t *testing.T) {
+	_, server := newTestLab(t)
+	waitForTick(t, server.URL, 5)
+	var queued map[string]int
+	body := `[{"op": "spawn", "team": "RED", "x": 100, "y": 100}, {"op": "set", "name": "maxSpeed", "value": "6.5"}]`
+	if code := labCall(t, "POST", server.URL+"/api/commands", body, &queued); code != http.StatusAccepted || queued["queued"] != 2 {
+		t.Fatalf("Expected the commands to be queued, got %d %v", code, queued)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	var status LabStatus
+	for time.Now().Before(deadline) {
+		labCall(t, "GET", server.URL+"/api/status", "", &status)
+		if status.Red+status.Blue == 34 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status.Red+status.Blue != 34 {
+		t.Errorf("Expected the spawned entity in the world, got %d red and %d blue", status.Red, status.Blue)
+	}
+
+	var failure map[string]string
+	for _, body := range []string{
+		`[{"op": "spawn", "team": "RED", "x": -1, "y": 100}]`,
+		`[{"op": "convert", "team": "GREEN", "id": "Red-000"}]`,
+		`[{"op": "teleport", "x": 10, "y": 10}]`,
+		`[{"op": "set", "name": "maxSpeed", "value": "fast"}]`,
+		`[{"op": "explode"}]`,
+	} {
+		if code := labCall(t, "POST", server.URL+"/api/commands", body, &failure); code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected %s to be rejected, got %d", body, code)
+		}
+	}
+	if code := labCall(t, "POST", server.URL+"/api/commands", `not json`, &failure); code != http.StatusBadRequest {
+		t.Errorf("Expected a malformed body to be rejected, got %d", code)
+	}
+}
@@ -0,0 +1,256 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// defaultLeaderWeight is the weight of the pull toward the leader when Config.LeaderWeight is 0
+	defaultLeaderWeight = 0.02
+	// leaderReach is the distance at which a leader reached a waypoint of Config.LeaderPath
+	leaderReach = 30.0
+	// leaderSlowRadius is the distance to its target under which a leader slows down
+	leaderSlowRadius = 60.0
+	// leaderTurn is the largest steering force of a leader per tick: it turns, not teleports
+	leaderTurn = 0.3
+)
+
+// ParseLeaderPath parses the waypoints "x,y" separated by semicolons of Config.LeaderPath,
+// e.g. "200,200;1000,200;1000,750;200,750"
+func ParseLeaderPath(spec string) ([]geometry.Vector2D, error) {
+	var path []geometry.Vector2D
+	for i, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		point, ok := parseNavTarget(item)
+		if !ok {
+			return nil, fmt.Errorf("waypoint %d: %q is not x,y", i+1, item)
+		}
+		path = append(path, point)
+	}
+	return path, nil
+}
+
+// Leaders links the world to whoever steers the leaders of Config.LeaderTeam when there is no
+// LeaderPath, e.g. the Game with the mouse cursor: it sets their target and tells which entities
+// lead. Give it to the world with WithLeaders. A Leaders is safe for concurrent use.
+type Leaders struct {
+	mu        sync.Mutex
+	target    geometry.Vector2D
+	hasTarget bool
+	ids       []string
+}
+
+// SetTarget makes the leaders head to 'pos'
+func (l *Leaders) SetTarget(pos geometry.Vector2D) {
+	l.mu.Lock()
+	l.target, l.hasTarget = pos, true
+	l.mu.Unlock()
+}
+
+// ClearTarget lets the leaders move with the strategy of their team
+func (l *Leaders) ClearTarget() {
+	l.mu.Lock()
+	l.hasTarget = false
+	l.mu.Unlock()
+}
+
+// Target returns the target of the leaders, false when there is none
+func (l *Leaders) Target() (geometry.Vector2D, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.target, l.hasTarget
+}
+
+// IDs returns the IDs of the current leaders
+func (l *Leaders) IDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.ids...)
+}
+
+func (l *Leaders) setIDs(state *leaderState) {
+	l.mu.Lock()
+	l.ids = l.ids[:0]
+	for _, lead := range state.teams() {
+		if lead.id != "" {
+			l.ids = append(l.ids, lead.id)
+		}
+	}
+	l.mu.Unlock()
+}
+
+// WithLeaders makes the world take the target of its leaders from 'l' when the config has no
+// LeaderPath, and report the leaders to it
+func WithLeaders(l *Leaders) WorldOption {
+	return func(w *world) {
+		w.leaders.control = l
+	}
+}
+
+// teamLeader is the leader of a team at a tick: its ID and where it heads
+type teamLeader struct {
+	id      string
+	target  geometry.Vector2D
+	steered bool // false: the leader has no target and moves with the strategy of its team
+}
+
+// leaderState is what the individuals know of the leaders, published by the world at every tick
+type leaderState struct {
+	red, blue teamLeader
+}
+
+// of returns the leader of 'team'
+func (s *leaderState) of(team pb.TeamColor) *teamLeader {
+	if team == pb.TeamColor_TEAM_RED {
+		return &s.red
+	}
+	return &s.blue
+}
+
+func (s *leaderState) teams() [2]*teamLeader {
+	return [2]*teamLeader{&s.red, &s.blue}
+}
+
+// leaderTracker keeps the leaders of the world from a tick to the next
+type leaderTracker struct {
+	control *Leaders
+	// path is the parsed LeaderPath of 'spec'
+	spec string
+	path []geometry.Vector2D
+	// waypoint is the index in path of the waypoint each leader heads to
+	red, blue struct {
+		id       string
+		waypoint int
+	}
+}
+
+// updateLeaders designates the leader of every team of LeaderTeam and publishes where they head:
+// a leader keeps its role until it leaves the team, then the first member of the team takes over
+func (w *world) updateLeaders() {
+	t := &w.leaders
+	if w.cfg.LeaderTeam == "" {
+		if w.live.leaders.Load() != nil {
+			w.live.leaders.Store(nil)
+			if t.control != nil {
+				t.control.setIDs(&leaderState{})
+			}
+		}
+		return
+	}
+	if t.spec != w.cfg.LeaderPath {
+		t.spec = w.cfg.LeaderPath
+		t.path, _ = ParseLeaderPath(t.spec) // Validated with the config
+		t.red.waypoint, t.blue.waypoint = 0, 0
+	}
+	state := &leaderState{}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if !teamSelected(w.cfg.LeaderTeam, team) {
+			continue
+		}
+		run := &t.blue
+		if team == pb.TeamColor_TEAM_RED {
+			run = &t.red
+		}
+		run.id = w.pickLeader(run.id, team)
+		if run.id == "" {
+			continue
+		}
+		lead := state.of(team)
+		lead.id = run.id
+		switch {
+		case len(t.path) > 0:
+			if w.entities[run.id].Pos.DistanceTo(t.path[run.waypoint]) < leaderReach {
+				run.waypoint = (run.waypoint + 1) % len(t.path)
+			}
+			lead.target, lead.steered = t.path[run.waypoint], true
+		case t.control != nil:
+			lead.target, lead.steered = t.control.Target()
+		}
+	}
+	w.live.leaders.Store(state)
+	if t.control != nil {
+		t.control.setIDs(state)
+	}
+}
+
+// pickLeader returns 'current' while it is a member of 'team', else the first member of the team
+// to arrive in the world, "" when the team is empty
+func (w *world) pickLeader(current string, team pb.TeamColor) string {
+	if e, ok := w.entities[current]; ok && e.Color == team {
+		return current
+	}
+	for _, e := range w.order {
+		if e.Color == team {
+			return e.ID
+		}
+	}
+	return ""
+}
+
+// leadOrFollow moves 'me' toward the target of its team when it is a steered leader and returns
+// true: the strategy of the team does not move it this tick. A flockmate seeing its leader is
+// pulled toward it, an extra cohesion of weight LeaderWeight.
+func leadOrFollow(me *Entity, perception *pb.Perception, leaders *leaderState, cfg *Config) bool {
+	if leaders == nil {
+		return false
+	}
+	lead := leaders.of(me.Color)
+	if lead.id == "" {
+		return false
+	}
+	if lead.id == me.ID {
+		if !lead.steered {
+			return false
+		}
+		steer := behavior.Arrive(me.Pos, me.Vel, lead.target, cfg.MaxSpeed, leaderSlowRadius)
+		me.ApplyForce(behavior.Truncate(steer, leaderTurn))
+		me.ApplyForce(behavior.AvoidObstacles(me.Pos, me.Vel, cfg.ObstacleList(), cfg.ObstacleReach()))
+		me.ClampVelocity(0, cfg.MaxSpeed)
+		me.UpdatePhysics()
+		me.BounceOffWalls(cfg.WorldWidth, cfg.WorldHeight)
+		return true
+	}
+	for _, friend := range perception.GetFriends() {
+		if friend.GetId() == lead.id {
+			weight := cfg.LeaderWeight
+			if weight == 0 {
+				weight = defaultLeaderWeight
+			}
+			me.ApplyForce(behavior.Attract(me.Pos, GeomVector2DFromProto(friend.GetPosition())).Mul(weight))
+			break
+		}
+	}
+	return false
+}
+
+// leaderColor rings the leaders and draws their path
+var leaderColor = color.RGBA{R: 255, G: 215, B: 0, A: 200}
+
+// drawLeaders rings the leaders of the snapshot and draws the loop of 'path'
+func drawLeaders(r Renderer, snap *pb.WorldSnapshot, ids []string, path []geometry.Vector2D) {
+	for i, p := range path {
+		next := path[(i+1)%len(path)]
+		r.StrokeLine(float32(p.X), float32(p.Y), float32(next.X), float32(next.Y), 1, leaderColor)
+		r.FillCircle(float32(p.X), float32(p.Y), 3, leaderColor)
+	}
+	if len(ids) == 0 {
+		return
+	}
+	for _, entity := range snap.Actors {
+		for _, id := range ids {
+			if entity.Id == id {
+				r.StrokeCircle(float32(entity.Position.X), float32(entity.Position.Y), 14, 2, leaderColor)
+			}
+		}
+	}
+}
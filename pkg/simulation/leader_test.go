@@ -0,0 +1,111 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestParseLeaderPath(t *testing.T) {
+	path, err := ParseLeaderPath(" 200,200; 1000,200 ;")
+	if err != nil {
+		t.Fatalf("ParseLeaderPath() error = %v", err)
+	}
+	if len(path) != 2 || path[1] != (geometry.Vector2D{X: 1000, Y: 200}) {
+		t.Errorf("Unexpected path %v", path)
+	}
+
+	cfg := DefaultConfig()
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.LeaderPath = "200;300,300" },
+		func(c *Config) { c.LeaderTeam = "green" },
+		func(c *Config) { c.LeaderWeight = -1 },
+		func(c *Config) { c.LeaderTeam, c.Engine = TeamBlue, EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestWorld_updateLeaders(t *testing.T) {
+	cfg := &Config{WorldWidth: 1000, WorldHeight: 1000, DetectionRadius: 100, LeaderTeam: TeamBlue, LeaderPath: "100,100;500,100"}
+	control := &Leaders{}
+	w := newWorld(nil, cfg, WithLeaders(control))
+	cfg = w.cfg
+	first := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 300, Y: 300}}
+	w.addEntity(&Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED})
+	w.addEntity(first)
+	w.addEntity(&Entity{ID: "Blue-001", Color: pb.TeamColor_TEAM_BLUE})
+
+	w.updateLeaders()
+	state := w.live.leaders.Load()
+	if state.blue.id != "Blue-000" || state.red.id != "" || state.blue.target != (geometry.Vector2D{X: 100, Y: 100}) {
+		t.Fatalf("Expected Blue-000 to lead the blues to the first waypoint, got %+v", state)
+	}
+	if ids := control.IDs(); len(ids) != 1 || ids[0] != "Blue-000" {
+		t.Errorf("Expected the leaders reported to the control, got %v", ids)
+	}
+
+	// Waypoint reached: on to the next one
+	first.Pos = geometry.Vector2D{X: 110, Y: 100}
+	w.updateLeaders()
+	if got := w.live.leaders.Load().blue.target; got != (geometry.Vector2D{X: 500, Y: 100}) {
+		t.Errorf("Expected the leader to head to the next waypoint, got %v", got)
+	}
+
+	// Converted: the next blue takes over
+	first.Color = pb.TeamColor_TEAM_RED
+	w.updateLeaders()
+	if got := w.live.leaders.Load().blue.id; got != "Blue-001" {
+		t.Errorf("Expected Blue-001 to take over, got %q", got)
+	}
+
+	cfg.LeaderTeam = ""
+	w.updateLeaders()
+	if w.live.leaders.Load() != nil || len(control.IDs()) != 0 {
+		t.Error("Expected no leader without LeaderTeam")
+	}
+}
+
+func TestLeadOrFollow(t *testing.T) {
+	cfg := DefaultConfig()
+	state := &leaderState{blue: teamLeader{id: "Blue-000", target: geometry.Vector2D{X: 500, Y: 100}, steered: true}}
+
+	leader := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 100}}
+	if !leadOrFollow(leader, &pb.Perception{}, state, cfg) || leader.Vel.X <= 0 || leader.Pos.X <= 100 {
+		t.Errorf("Expected the leader to move toward its target, got %v at %v", leader.Vel, leader.Pos)
+	}
+
+	follower := &Entity{ID: "Blue-001", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 100, Y: 120}}
+	perception := &pb.Perception{Friends: []*pb.ActorState{{Id: "Blue-000", Position: &pb.Vector{X: 100, Y: 100}}}}
+	if leadOrFollow(follower, perception, state, cfg) || follower.Vel.Y >= 0 {
+		t.Errorf("Expected the follower to be pulled up toward its leader, got %v", follower.Vel)
+	}
+
+	state.blue.steered = false
+	idle := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE}
+	if leadOrFollow(idle, &pb.Perception{}, state, cfg) {
+		t.Error("Expected a leader without target to move with its team")
+	}
+}
+
+func TestIndividual_leaderTires(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RedStamina = 10
+	live := newLiveConfig(cfg)
+	live.leaders.Store(&leaderState{red: teamLeader{id: "Red-000", target: geometry.Vector2D{X: 900, Y: 100}, steered: true}})
+	ind := newIndividual(pb.TeamColor_TEAM_RED, 100, 100, cfg.MaxSpeed, 0, live, nil)
+	ind.setID("Red-000")
+	ind.State.Fatigue = 0.95
+	for range 3 {
+		ind.handleTick(&pb.Tick{Context: &pb.Perception{}})
+	}
+	if !ind.State.exhausted || ind.State.Vel.Len() > cfg.MaxSpeed*exhaustedSpeed+1e-9 {
+		t.Errorf("Expected the leader sprinting to its target to tire like any red, got fatigue %f at speed %f",
+			ind.State.Fatigue, ind.State.Vel.Len())
+	}
+}
@@ -0,0 +1,37 @@
+package simulation
+
+import "fmt"
+
+// lifespanJitter spreads the lifespans: each entity lives Config.Lifespan ± 20%, so that the
+// initial population does not die all at once
+const lifespanJitter = 0.2
+
+// Age returns the number of ticks the entity lived at 'tick', since its spawn
+func (e *Entity) Age(tick uint64) uint64 {
+	if tick < e.spawnTick {
+		return 0
+	}
+	return tick - e.spawnTick
+}
+
+// updateLifespans removes the entities older than their lifespan (see Config.Lifespan) from the
+// world like CommandQueue.Despawn: they leave the grid and the snapshots, and a death event is sent
+func (w *world) updateLifespans() {
+	if w.cfg.Lifespan == 0 {
+		return
+	}
+	var dead []string
+	for _, e := range w.order {
+		if e.lifeFactor == 0 {
+			// Drawn once per life, from a stream of its own
+			rng := w.entityRand(fmt.Sprintf("lifespan/%s/%d", e.ID, e.Generation))
+			e.lifeFactor = 1 + lifespanJitter*(2*rng.Float64()-1)
+		}
+		if float64(e.Age(w.tick)) >= float64(w.cfg.Lifespan)*e.lifeFactor {
+			dead = append(dead, e.ID)
+		}
+	}
+	for _, id := range dead {
+		w.despawn(id)
+	}
+}
@@ -0,0 +1,57 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestWorld_updateLifespans(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart, cfg.NumBlueAtStart = 1, 2
+	cfg.Lifespan = 100
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	var deaths []string
+	sink := EventSinkFunc(func(e Event) {
+		if e.Kind == EventDeath {
+			deaths = append(deaths, e.ID)
+		}
+	})
+	engine, err := NewLocalEngine(context.Background(), make(chan *pb.WorldSnapshot, 1), cfg, WithEventSink(sink))
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	w := engine.(*localEngine).world
+
+	w.tick = 79
+	w.updateLifespans()
+	if len(w.entities) != 3 {
+		t.Fatalf("Expected no death before 80%% of the lifespan, got %d entities", len(w.entities))
+	}
+	w.tick = 120
+	w.updateLifespans()
+	if len(w.entities) != 0 || len(deaths) != 3 {
+		t.Fatalf("Expected every entity dead of old age after 120%% of the lifespan, got %d left and %v", len(w.entities), deaths)
+	}
+
+	// A newborn counts its age from its spawn
+	e := w.spawnEntity(pb.TeamColor_TEAM_BLUE, geometry.Vector2D{X: 10, Y: 10}, geometry.Vector2D{})
+	if e.Age(w.tick) != 0 || e.lifeFactor != 0 {
+		t.Fatalf("Expected a recycled entity to start a new life, got age %d", e.Age(w.tick))
+	}
+	w.tick += 50
+	w.updateLifespans()
+	if _, ok := w.entities[e.ID]; !ok {
+		t.Error("Expected the newborn to live on")
+	}
+
+	bad := *cfg
+	bad.Engine = EngineECS
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected the lifespan to be rejected by the ecs engine")
+	}
+}
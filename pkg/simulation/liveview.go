@@ -0,0 +1,205 @@
+package simulation
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// liveViewBuffer is the number of snapshots queued per browser before frames are dropped
+	liveViewBuffer = 4
+	// liveViewWriteTimeout closes the connection of a browser that stopped reading
+	liveViewWriteTimeout = 2 * time.Second
+)
+
+//go:embed web/liveview.html
+var liveViewPage []byte
+
+// LiveFrame is the compact JSON version of a WorldSnapshot pushed to the browsers.
+// Actors are flattened as [x, y, team, x, y, team, ...] with rounded positions
+// and team 0 for RED, 1 for BLUE, 2 for the civilians, which keeps a 1000 entities frame around 12 KB.
+type LiveFrame struct {
+	Tick    uint64  `json:"t"`
+	Width   float64 `json:"w"`
+	Height  float64 `json:"h"`
+	Red     int32   `json:"r"`
+	Blue    int32   `json:"b"`
+	Neutral int32   `json:"n,omitempty"`
+	Over    bool    `json:"o,omitempty"`
+	Winner  string  `json:"win,omitempty"`
+	Actors  []int32 `json:"a"`
+}
+
+// NewLiveFrame converts a snapshot for a world of size width x height
+func NewLiveFrame(snap *pb.WorldSnapshot, width, height float64) *LiveFrame {
+	f := &LiveFrame{
+		Tick:    snap.GetTick(),
+		Width:   width,
+		Height:  height,
+		Red:     snap.GetRedCount(),
+		Blue:    snap.GetBlueCount(),
+		Neutral: snap.GetNeutralCount(),
+		Over:    snap.GetIsGameOver(),
+		Winner:  snap.GetWinner(),
+		Actors:  make([]int32, 0, len(snap.GetActors())*3),
+	}
+	for _, a := range snap.GetActors() {
+		var team int32
+		switch a.Color {
+		case pb.TeamColor_TEAM_RED:
+			team = 0
+		case pb.TeamColor_TEAM_BLUE:
+			team = 1
+		case NeutralColor:
+			team = 2
+		default:
+			continue
+		}
+		f.Actors = append(f.Actors,
+			int32(math.Round(a.Position.GetX())),
+			int32(math.Round(a.Position.GetY())),
+			team)
+	}
+	return f
+}
+
+// LiveView serves a minimal HTML canvas page and a WebSocket endpoint pushing
+// the snapshots of a SnapshotHub, to watch the simulation from a browser.
+type LiveView struct {
+	hub *SnapshotHub
+	cfg *Config
+}
+
+// NewLiveView creates the bridge, 'cfg' gives the world size sent to the browsers
+func NewLiveView(hub *SnapshotHub, cfg *Config) *LiveView {
+	return &LiveView{hub: hub, cfg: cfg}
+}
+
+// Handler returns the http.Handler serving the page on "/", the stream on "/ws" and
+// the full snapshots on "/snapshots" (see serveSnapshots)
+func (lv *LiveView) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", lv.servePage)
+	mux.HandleFunc("GET /ws", lv.serveStream)
+	mux.HandleFunc("GET /snapshots", lv.serveSnapshots)
+	return mux
+}
+
+func (lv *LiveView) servePage(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(liveViewPage)
+}
+
+// serveStream pushes one JSON text message per snapshot until the browser disconnects
+func (lv *LiveView) serveStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return // Accept already wrote the HTTP error
+	}
+	defer conn.CloseNow()
+	// The page never sends anything, CloseRead handles the pings and the close frame
+	ctx := conn.CloseRead(r.Context())
+
+	snapshots, cancel := lv.hub.Subscribe(liveViewBuffer)
+	defer cancel()
+
+	if snap := lv.hub.Latest(); snap != nil {
+		if err := lv.send(ctx, conn, snap); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case snap, ok := <-snapshots:
+			if !ok {
+				conn.Close(websocket.StatusGoingAway, "simulation stopped")
+				return
+			}
+			if err := lv.send(ctx, conn, snap); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (lv *LiveView) send(ctx context.Context, conn *websocket.Conn, snap *pb.WorldSnapshot) error {
+	data, err := json.Marshal(NewLiveFrame(snap, lv.cfg.WorldWidth, lv.cfg.WorldHeight))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, liveViewWriteTimeout)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// snapshotJSON is the protobuf JSON mapping of the snapshots streamed by serveSnapshots, the types
+// generated in clients/ by cmd/pbgen decode it. The zero values are kept: every field is present.
+var snapshotJSON = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// serveSnapshots streams the complete WorldSnapshots as newline-delimited JSON (application/x-ndjson)
+// until the client disconnects. The "every" query parameter only sends one snapshot every N, like
+// StreamRequest.every_n_ticks; a slow client misses snapshots instead of queuing them.
+func (lv *LiveView) serveSnapshots(w http.ResponseWriter, r *http.Request) {
+	every := 1
+	if s := r.URL.Query().Get("every"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "every must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		every = n
+	}
+	snapshots, cancel := lv.hub.Subscribe(liveViewBuffer)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	write := func(snap *pb.WorldSnapshot) error {
+		data, err := snapshotJSON.Marshal(snap)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	if snap := lv.hub.Latest(); snap != nil {
+		if err := write(snap); err != nil {
+			return
+		}
+	}
+	count := 0
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case snap, ok := <-snapshots:
+			if !ok {
+				return
+			}
+			count++
+			if every > 1 && count%every != 0 {
+				continue
+			}
+			if err := write(snap); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package simulation
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestNewLiveFrame(t *testing.T) {
+	snap := &pb.WorldSnapshot{
+		Tick:         42,
+		RedCount:     1,
+		BlueCount:    1,
+		NeutralCount: 1,
+		Actors: []*pb.ActorState{
+			{Id: "r1", Color: pb.TeamColor_TEAM_RED, Position: &pb.Vector{X: 10.4, Y: 20.6}},
+			{Id: "b1", Color: pb.TeamColor_TEAM_BLUE, Position: &pb.Vector{X: 300, Y: 400}},
+			{Id: "n1", Color: NeutralColor, Position: &pb.Vector{X: 50, Y: 60}},
+		},
+	}
+	f := NewLiveFrame(snap, 800, 600)
+
+	want := []int32{10, 21, 0, 300, 400, 1, 50, 60, 2}
+	if len(f.Actors) != len(want) {
+		t.Fatalf("Expected %d values, got %d", len(want), len(f.Actors))
+	}
+	for i := range want {
+		if f.Actors[i] != want[i] {
+			t.Errorf("Actors[%d]: expected %d, got %d", i, want[i], f.Actors[i])
+		}
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	const expected = `{"t":42,"w":800,"h":600,"r":1,"b":1,"n":1,"a":[10,21,0,300,400,1,50,60,2]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestLiveView_snapshots(t *testing.T) {
+	hub := NewSnapshotHub()
+	hub.Publish(&pb.WorldSnapshot{Tick: 1})
+	server := httptest.NewServer(NewLiveView(hub, DefaultConfig()).Handler())
+	defer server.Close()
+
+	if res, err := http.Get(server.URL + "/snapshots?every=0"); err != nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an invalid every to be rejected, got %v %v", res.StatusCode, err)
+	}
+
+	res, err := http.Get(server.URL + "/snapshots?every=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	// The response started with the latest snapshot: the handler is subscribed
+	go func() {
+		for tick := uint64(2); tick <= 5; tick++ {
+			hub.Publish(&pb.WorldSnapshot{Tick: tick, RedCount: 1, Actors: []*pb.ActorState{{Id: "Red-000", Color: pb.TeamColor_TEAM_RED}}})
+		}
+	}()
+
+	lines := bufio.NewScanner(res.Body)
+	var ticks []string
+	for len(ticks) < 3 && lines.Scan() {
+		var snap map[string]any
+		if err := json.Unmarshal(lines.Bytes(), &snap); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", lines.Text(), err)
+		}
+		if _, ok := snap["isGameOver"]; !ok {
+			t.Errorf("Expected every field to be present, got %s", lines.Text())
+		}
+		ticks = append(ticks, snap["tick"].(string))
+	}
+	// The latest snapshot first, then one every 2
+	if len(ticks) != 3 || ticks[0] != "1" || ticks[1] != "3" || ticks[2] != "5" {
+		t.Errorf("Expected ticks 1, 3 and 5, got %v", ticks)
+	}
+}
@@ -0,0 +1,172 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MacrosDir is the directory where the UI saves and loads its macros
+const MacrosDir = "macros"
+
+// Macro is a recording of the parameter changes made in the UI panel during a run.
+// Replayed against a fresh run of the same seed, it reproduces experiments such as
+// "aggression dragged up at tick 2000": every change is applied at the tick it was made.
+type Macro struct {
+	Name string `json:"name"`
+	// Seed of the recorded run (runs are only reproducible with the local engine)
+	Seed uint64 `json:"seed,omitempty"`
+	// Start holds the parameters at tick 0
+	Start  Preset       `json:"start"`
+	Events []MacroEvent `json:"events"`
+}
+
+// MacroEvent is one interaction: the parameters changed before 'Tick', with their new values
+type MacroEvent struct {
+	Tick uint64 `json:"tick"`
+	// Elapsed is the wall time since the start of the recording, for information only
+	Elapsed time.Duration `json:"elapsed"`
+	// Set maps the config.json names of the changed parameters to their new value
+	Set map[string]any `json:"set"`
+}
+
+// MacroRecorder builds a Macro from the successive states of the panel
+type MacroRecorder struct {
+	macro   Macro
+	last    map[string]any
+	started time.Time
+}
+
+// NewMacroRecorder starts a recording from the parameters 'start' of a fresh run of 'seed'
+func NewMacroRecorder(name string, seed uint64, start Preset) *MacroRecorder {
+	start.Name = ""
+	return &MacroRecorder{
+		macro:   Macro{Name: name, Seed: seed, Start: start},
+		last:    presetFields(start),
+		started: time.Now(),
+	}
+}
+
+// Observe records the parameters of 'current' that changed since the previous call,
+// 'tick' is the tick they take effect at
+func (r *MacroRecorder) Observe(tick uint64, current Preset) {
+	fields := presetFields(current)
+	changed := make(map[string]any)
+	for name, value := range fields {
+		if name != "name" && r.last[name] != value {
+			changed[name] = value
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	r.last = fields
+	r.macro.Events = append(r.macro.Events, MacroEvent{Tick: tick, Elapsed: time.Since(r.started), Set: changed})
+}
+
+// Macro returns the recording so far
+func (r *MacroRecorder) Macro() Macro {
+	return r.macro
+}
+
+// MacroPlayer replays the events of a Macro tick by tick
+type MacroPlayer struct {
+	macro   Macro
+	next    int
+	current Preset
+}
+
+// NewMacroPlayer starts a replay from the start parameters of 'm'
+func NewMacroPlayer(m Macro) *MacroPlayer {
+	return &MacroPlayer{macro: m, current: m.Start}
+}
+
+// Advance applies the events due at or before 'tick' and returns the resulting parameters,
+// ok is false when no event was due
+func (p *MacroPlayer) Advance(tick uint64) (params Preset, ok bool, err error) {
+	for p.next < len(p.macro.Events) && p.macro.Events[p.next].Tick <= tick {
+		if err := p.current.set(p.macro.Events[p.next].Set); err != nil {
+			return p.current, false, fmt.Errorf("macro %q, tick %d: %w", p.macro.Name, p.macro.Events[p.next].Tick, err)
+		}
+		p.next++
+		ok = true
+	}
+	return p.current, ok, nil
+}
+
+// Done reports whether every event was replayed
+func (p *MacroPlayer) Done() bool {
+	return p.next >= len(p.macro.Events)
+}
+
+// presetFields returns the parameters of 'p' by config.json name
+func presetFields(p Preset) map[string]any {
+	b, _ := json.Marshal(p)
+	var fields map[string]any
+	_ = json.Unmarshal(b, &fields)
+	return fields
+}
+
+// set overwrites the parameters named in 'fields'
+func (p *Preset) set(fields map[string]any) error {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, p)
+}
+
+// MacroPath returns the file of the macro 'name' in 'dir'
+func MacroPath(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// SaveMacro writes the macro as indented JSON in 'dir' (created if needed) and returns the file path
+func SaveMacro(dir string, m Macro) (string, error) {
+	if !validFileName(m.Name) {
+		return "", fmt.Errorf("invalid macro name %q", m.Name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("cannot create macros directory: %w", err)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := MacroPath(dir, m.Name)
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("cannot write macro: %w", err)
+	}
+	return path, nil
+}
+
+// LoadMacro reads the macro 'name' from 'dir'
+func LoadMacro(dir, name string) (Macro, error) {
+	b, err := os.ReadFile(MacroPath(dir, name))
+	if err != nil {
+		return Macro{}, fmt.Errorf("cannot read macro: %w", err)
+	}
+	var m Macro
+	if err := json.Unmarshal(b, &m); err != nil {
+		return Macro{}, fmt.Errorf("cannot decode macro %q: %w", name, err)
+	}
+	m.Name = name
+	return m, nil
+}
+
+// ListMacros returns the sorted names of the macros saved in 'dir' (none when it does not exist)
+func ListMacros(dir string) ([]string, error) {
+	return listJSONFiles(dir)
+}
+
+// NextMacroName returns the first free "macro-NNN" name in 'dir'
+func NextMacroName(dir string) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("macro-%03d", i)
+		if _, err := os.Stat(MacroPath(dir, name)); os.IsNotExist(err) {
+			return name
+		}
+	}
+}
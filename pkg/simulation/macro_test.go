@@ -0,0 +1,111 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMacroRecorder_onlyRecordsChanges(t *testing.T) {
+	cfg := DefaultConfig()
+	rec := NewMacroRecorder("m", 7, NewPreset("ignored", cfg))
+	rec.Observe(1, NewPreset("", cfg))
+	cfg.Aggression = 1.6
+	rec.Observe(2000, NewPreset("", cfg))
+	rec.Observe(2001, NewPreset("", cfg))
+	cfg.DisplayDefenseCircle = true
+	cfg.RedStrategy = StrategyPackHunter
+	rec.Observe(2100, NewPreset("", cfg))
+
+	macro := rec.Macro()
+	if len(macro.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", macro.Events)
+	}
+	if e := macro.Events[0]; e.Tick != 2000 || len(e.Set) != 1 || e.Set["aggression"] != 1.6 {
+		t.Errorf("Unexpected first event %+v", e)
+	}
+	if e := macro.Events[1]; e.Tick != 2100 || len(e.Set) != 2 || e.Set["redStrategy"] != StrategyPackHunter {
+		t.Errorf("Unexpected second event %+v", e)
+	}
+
+	// Save, load and replay
+	dir := t.TempDir()
+	if _, err := SaveMacro(dir, macro); err != nil {
+		t.Fatalf("SaveMacro failed: %v", err)
+	}
+	if next := NextMacroName(dir); next != "macro-001" {
+		t.Errorf("Expected macro-001 to be free, got %s", next)
+	}
+	loaded, err := LoadMacro(dir, "m")
+	if err != nil {
+		t.Fatalf("LoadMacro failed: %v", err)
+	}
+	player := NewMacroPlayer(loaded)
+	if _, ok, _ := player.Advance(1999); ok {
+		t.Errorf("Expected no event before tick 2000")
+	}
+	params, ok, err := player.Advance(2000)
+	if err != nil || !ok || params.Aggression != 1.6 || params.DisplayDefenseCircle {
+		t.Errorf("Expected only the aggression change at tick 2000, got %+v (%v, %v)", params, ok, err)
+	}
+	params, _, _ = player.Advance(5000)
+	if !player.Done() || !params.DisplayDefenseCircle || params.RedStrategy != StrategyPackHunter || params.MaxSpeed != cfg.MaxSpeed {
+		t.Errorf("Expected every change replayed, got %+v", params)
+	}
+}
+
+func TestMacro_replayReproducesRun(t *testing.T) {
+	ctx := context.Background()
+	run := func(onTick func(tick uint64, r *Runner)) *pb.WorldSnapshot {
+		cfg := DefaultConfig()
+		cfg.Seed = 11
+		r, err := NewRunner(ctx, cfg, WithEngine(NewLocalEngine))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Stop(ctx)
+		for tick := uint64(1); tick <= 120; tick++ {
+			onTick(tick, r)
+			if _, err := r.Step(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return r.Latest()
+	}
+
+	// Original run: the aggression and the speed are changed live, and recorded
+	var rec *MacroRecorder
+	original := run(func(tick uint64, r *Runner) {
+		if rec == nil {
+			rec = NewMacroRecorder("live", r.Config().Seed, NewPreset("", r.Config()))
+		}
+		if tick == 40 || tick == 80 {
+			cfg := *r.Config()
+			cfg.Aggression += 1
+			cfg.MaxSpeed += 1
+			if err := r.ApplyConfig(ctx, &cfg); err != nil {
+				t.Fatal(err)
+			}
+			rec.Observe(tick, NewPreset("", &cfg))
+		}
+	})
+
+	// Replay against a fresh run of the same seed
+	player := NewMacroPlayer(rec.Macro())
+	replayed := run(func(tick uint64, r *Runner) {
+		if params, ok, _ := player.Advance(tick); ok {
+			cfg := *r.Config()
+			if err := params.Apply(&cfg); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.ApplyConfig(ctx, &cfg); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	if !proto.Equal(original, replayed) {
+		t.Errorf("Expected the replay to end in the same state as the original run")
+	}
+}
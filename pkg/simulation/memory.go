@@ -0,0 +1,135 @@
+package simulation
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Subsystems accounted by a MemoryUsage, in display order
+const (
+	MemoryEntities  = "entities"  // World state of the entities (world or ecs engine)
+	MemoryGrid      = "grid"      // Spatial index
+	MemoryTrails    = "trails"    // Trails of the red entities (Game)
+	MemorySnapshots = "snapshots" // Snapshots in flight between the world and the Game
+	MemoryRecording = "recording" // Frames of the GIF or video recording in progress
+)
+
+// MemorySubsystems lists the subsystems in display order
+var MemorySubsystems = []string{MemoryEntities, MemoryGrid, MemoryTrails, MemorySnapshots, MemoryRecording}
+
+const (
+	// memoryReportTicks is the period of the reports of the world, 2s at 60 ticks per second
+	memoryReportTicks = 120
+	// mapEntryOverhead approximates the memory of a map entry besides its key and value
+	mapEntryOverhead = 16
+)
+
+// MemoryUsage is the approximate memory attributable to each subsystem, in bytes. The world
+// (see WithMemoryUsage) and the Game update their own subsystems every few seconds, it is safe
+// to use from any goroutine. The figures are estimates from the sizes of the structures: they
+// tell which subsystem grows with the population, they do not add up to the heap of the process.
+type MemoryUsage struct {
+	mu    sync.Mutex
+	bytes map[string]int64
+}
+
+// NewMemoryUsage creates an empty accounting
+func NewMemoryUsage() *MemoryUsage {
+	return &MemoryUsage{bytes: make(map[string]int64)}
+}
+
+// Set records the memory of a subsystem
+func (m *MemoryUsage) Set(subsystem string, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes[subsystem] = bytes
+}
+
+// Get returns the last memory recorded for a subsystem (0 when never recorded)
+func (m *MemoryUsage) Get(subsystem string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bytes[subsystem]
+}
+
+// Total returns the sum of the subsystems
+func (m *MemoryUsage) Total() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total int64
+	for _, n := range m.bytes {
+		total += n
+	}
+	return total
+}
+
+// WithMemoryUsage makes the world record the memory of its entities and spatial index in 'm'
+// every memoryReportTicks ticks
+func WithMemoryUsage(m *MemoryUsage) WorldOption {
+	return func(w *world) {
+		w.memory = m
+	}
+}
+
+// reportMemory records the memory of the entities (live and parked) and of the spatial index
+func (w *world) reportMemory() {
+	perEntity := int64(unsafe.Sizeof(Entity{})) + int64(unsafe.Sizeof(individual{})) +
+		int64(unsafe.Sizeof(&Entity{}))*2 + mapEntryOverhead // order and entities map
+	var entities int64
+	for _, e := range w.order {
+		entities += perEntity + int64(len(e.ID))
+	}
+	entities += int64(len(w.pool.parked)) * perEntity
+	w.memory.Set(MemoryEntities, entities)
+
+	grid := int64(len(w.grid)) * (int64(unsafe.Sizeof(gridKey{})) + int64(unsafe.Sizeof([]*Entity{})) + mapEntryOverhead)
+	for _, cell := range w.grid {
+		grid += int64(cap(cell)) * int64(unsafe.Sizeof(&Entity{}))
+	}
+	if w.autoIndex != nil {
+		grid += w.autoIndex.quadtree.MemoryBytes() + int64(cap(w.autoIndex.counts))*8
+	} else if q, ok := w.index.(interface{ MemoryBytes() int64 }); ok {
+		grid += q.MemoryBytes()
+	}
+	w.memory.Set(MemoryGrid, grid)
+}
+
+// snapshotBytes approximates the memory of a snapshot with its actor states
+func snapshotBytes(snap *pb.WorldSnapshot) int64 {
+	if snap == nil {
+		return 0
+	}
+	n := int64(unsafe.Sizeof(pb.WorldSnapshot{})) + int64(cap(snap.Actors))*int64(unsafe.Sizeof(&pb.ActorState{}))
+	perActor := int64(unsafe.Sizeof(pb.ActorState{})) + 2*int64(unsafe.Sizeof(pb.Vector{}))
+	for _, a := range snap.Actors {
+		n += perActor + int64(len(a.Id))
+	}
+	return n
+}
+
+// MemoryBytes returns the approximate memory held by the trails
+func (t *Trails) MemoryBytes() int64 {
+	n := int64(len(t.paths)) * (int64(unsafe.Sizeof("")) + int64(unsafe.Sizeof([]geometry.Vector2D{})) + mapEntryOverhead)
+	for id, path := range t.paths {
+		n += int64(len(id)) + int64(cap(path))*int64(unsafe.Sizeof(geometry.Vector2D{}))
+	}
+	return n
+}
+
+// FormatBytes formats a size with a binary unit: 512 B, 1.5 KiB, 12.3 MiB
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exp])
+}
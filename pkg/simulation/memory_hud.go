@@ -0,0 +1,71 @@
+package simulation
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+const (
+	// memoryRefresh is the period of the measures of the Game, runtime.ReadMemStats stops the world
+	memoryRefresh = 2 * time.Second
+)
+
+// memoryHUD shows the memory of the subsystems next to the heap of the process
+type memoryHUD struct {
+	usage   *MemoryUsage
+	updated time.Time
+	// Heap in use and memory obtained from the OS by the Go runtime
+	heap, sys uint64
+}
+
+// updateMemory measures the subsystems of the Game and the heap every memoryRefresh while the HUD is shown
+func (g *Game) updateMemory() {
+	m := &g.memory
+	if !g.widgetShowMemory.Value || time.Since(m.updated) < memoryRefresh {
+		return
+	}
+	m.updated = time.Now()
+	m.usage.Set(MemoryTrails, g.trails.MemoryBytes())
+
+	// Waiting in the channel, kept by the interpolator and displayed: all about the size of the last one
+	inFlight := len(g.snapshotCh) + 1
+	if g.interp != nil {
+		inFlight += g.interp.Len()
+	}
+	m.usage.Set(MemorySnapshots, int64(inFlight)*snapshotBytes(g.lastState))
+
+	var recording int64
+	if g.capture.recorder != nil {
+		recording = g.capture.recorder.MemoryBytes()
+	}
+	m.usage.Set(MemoryRecording, recording)
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	m.heap, m.sys = stats.HeapAlloc, stats.Sys
+}
+
+// drawMemoryHUD lists the memory of the subsystems on the right side, under the performance stats
+func (g *Game) drawMemoryHUD(screen *ebiten.Image) {
+	m := &g.memory
+	if !g.widgetShowMemory.Value || m.updated.IsZero() {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Memory (every %s)\n", memoryRefresh)
+	fmt.Fprintf(&b, "%-10s %10s\n", "heap", FormatBytes(int64(m.heap)))
+	for _, name := range MemorySubsystems {
+		fmt.Fprintf(&b, "%-10s %10s\n", name, FormatBytes(m.usage.Get(name)))
+	}
+	// Actors, protobuf messages and everything else
+	if other := int64(m.heap) - m.usage.Total(); other > 0 {
+		fmt.Fprintf(&b, "%-10s %10s\n", "other", FormatBytes(other))
+	}
+	fmt.Fprintf(&b, "%-10s %10s", "from OS", FormatBytes(int64(m.sys)))
+	placeOverlay(screen, g.layout, ui.AnchorTopRight, b.String())
+}
@@ -0,0 +1,75 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestMemoryUsage_world(t *testing.T) {
+	for _, name := range []string{EngineLocal, EngineECS} {
+		ctx := context.Background()
+		cfg := DefaultConfig()
+		cfg.Engine = name
+		cfg.NumRedAtStart = 10
+		cfg.NumBlueAtStart = 100
+		memory := NewMemoryUsage()
+		snapshotCh := make(chan *pb.WorldSnapshot, 1)
+		engine, err := SelectEngine(cfg, NewLocalEngine)(ctx, snapshotCh, cfg, WithMemoryUsage(memory))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for tick := 0; tick < memoryReportTicks; tick++ {
+			if tick == memoryReportTicks-1 && memory.Get(MemoryEntities) != 0 {
+				t.Errorf("%s: expected the first report after %d ticks", name, memoryReportTicks)
+			}
+			if err := engine.Send(ctx, &pb.Tick{}); err != nil {
+				t.Fatal(err)
+			}
+			<-snapshotCh
+		}
+		// At least the position and velocity of the 110 entities
+		if got := memory.Get(MemoryEntities); got < 110*32 {
+			t.Errorf("%s: expected the entities to be accounted, got %s", name, FormatBytes(got))
+		}
+		if name == EngineLocal && memory.Get(MemoryGrid) == 0 {
+			t.Errorf("%s: expected the grid to be accounted", name)
+		}
+		_ = engine.Stop(ctx)
+	}
+}
+
+func TestTrailsMemoryBytes(t *testing.T) {
+	trails := NewTrails(0)
+	if trails.MemoryBytes() != 0 {
+		t.Errorf("Expected empty trails to hold nothing")
+	}
+	snap := &pb.WorldSnapshot{Actors: []*pb.ActorState{
+		{Id: "Red-000", Color: pb.TeamColor_TEAM_RED, Position: &pb.Vector{X: 1, Y: 1}},
+	}}
+	trails.Update(snap)
+	if got := trails.MemoryBytes(); got < 16 {
+		t.Errorf("Expected the trail to be accounted, got %d", got)
+	}
+	if got := snapshotBytes(snap); got < 100 {
+		t.Errorf("Expected the snapshot and its actor to be accounted, got %d", got)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:              "0 B",
+		1023:           "1023 B",
+		1536:           "1.5 KiB",
+		12_900_000:     "12.3 MiB",
+		3 << 30:        "3.0 GiB",
+		5 << 40:        "5.0 TiB",
+		int64(2) << 50: "2048.0 TiB",
+	}
+	for n, want := range tests {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package simulation
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+// Minimap in the top right corner, below the performance stats
+const (
+	minimapSize = 160.0 // Longest side, in pixels
+	// cameraZoomStep is the zoom factor of one notch of the mouse wheel
+	cameraZoomStep = 1.2
+)
+
+var (
+	minimapBackground = [4]byte{20, 20, 30, 200}
+	minimapRed        = [4]byte{255, 60, 60, 255}
+	minimapBlue       = [4]byte{80, 140, 255, 255}
+	minimapNeutral    = [4]byte{170, 170, 170, 255}
+	minimapViewport   = color.RGBA{R: 255, G: 255, B: 0, A: 255}
+)
+
+// minimap draws the whole world as one pixel per entity with the area shown by the camera
+type minimap struct {
+	img    *ebiten.Image
+	pixels []byte
+	rect   image.Rectangle // on screen
+}
+
+// minimapExtent returns the size on screen of the minimap of a world, with its aspect ratio
+func minimapExtent(worldW, worldH float64) (w, h float64) {
+	size := scaled(minimapSize)
+	w, h = size, size
+	if worldW > worldH {
+		h = max(size*worldH/worldW, 1)
+	} else {
+		w = max(size*worldW/worldH, 1)
+	}
+	return w, h
+}
+
+// worldAt converts a point of the minimap to world coordinates
+func (m *minimap) worldAt(p image.Point, worldW, worldH float64) (float64, float64) {
+	return float64(p.X-m.rect.Min.X) * worldW / float64(m.rect.Dx()),
+		float64(p.Y-m.rect.Min.Y) * worldH / float64(m.rect.Dy())
+}
+
+func (m *minimap) draw(screen *ebiten.Image, snap *pb.WorldSnapshot, camera *Camera, worldW, worldH float64) {
+	w, h := m.rect.Dx(), m.rect.Dy()
+	if m.img == nil || m.img.Bounds().Dx() != w || m.img.Bounds().Dy() != h {
+		m.img = ebiten.NewImage(w, h)
+		m.pixels = make([]byte, 4*w*h)
+	}
+	for i := 0; i < len(m.pixels); i += 4 {
+		copy(m.pixels[i:i+4], minimapBackground[:])
+	}
+	for _, a := range snap.GetActors() {
+		px := int(a.GetPosition().GetX() * float64(w) / worldW)
+		py := int(a.GetPosition().GetY() * float64(h) / worldH)
+		if px < 0 || px >= w || py < 0 || py >= h {
+			continue
+		}
+		clr := minimapBlue
+		switch a.Color {
+		case pb.TeamColor_TEAM_RED:
+			clr = minimapRed
+		case NeutralColor:
+			clr = minimapNeutral
+		}
+		copy(m.pixels[4*(py*w+px):], clr[:])
+	}
+	m.img.WritePixels(m.pixels)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(m.rect.Min.X), float64(m.rect.Min.Y))
+	screen.DrawImage(m.img, op)
+
+	minX, minY, maxX, maxY := camera.Visible()
+	sx, sy := float64(w)/worldW, float64(h)/worldH
+	vector.StrokeRect(screen, float32(float64(m.rect.Min.X)+minX*sx), float32(float64(m.rect.Min.Y)+minY*sy),
+		float32((maxX-minX)*sx), float32((maxY-minY)*sy), 1, minimapViewport, false)
+}
+
+// minimapVisible reports whether the minimap is drawn: only when the camera shows a part of the world
+func (g *Game) minimapVisible() bool {
+	return g.widgetShowMinimap.Value && g.camera.Zoomed()
+}
+
+// updateCamera handles the zoom (mouse wheel), the pan (right button drag, or a click on the
+// minimap), the Home key resets it (see keybindings.go). Returns true when it used the left mouse button.
+func (g *Game) updateCamera(blocked bool) bool {
+	mx, my := ebiten.CursorPosition()
+	cursor := image.Pt(mx, my)
+	before := *g.camera
+	used := false
+	switch {
+	case g.minimapVisible() && !blocked && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) &&
+		(g.minimapDragging || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && cursor.In(g.minimap.rect)):
+		// Jump to the clicked point, keep following the cursor while the button is held
+		g.minimapDragging = true
+		g.camera.CenterOn(g.minimap.worldAt(cursor, g.cfg.WorldWidth, g.cfg.WorldHeight))
+		used = true
+	default:
+		g.minimapDragging = false
+	}
+	if !blocked {
+		if _, dy := ebiten.Wheel(); dy != 0 {
+			factor := cameraZoomStep
+			if dy < 0 {
+				factor = 1 / factor
+			}
+			g.camera.ZoomAt(factor, float64(mx), float64(my))
+		}
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) && !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+			g.camera.Pan(float64(g.lastCursor.X-mx), float64(g.lastCursor.Y-my))
+		}
+	}
+	g.lastCursor = cursor
+	if *g.camera != before {
+		// The trails of the entities on screen are kept first
+		g.trails.SetViewport(g.camera.Visible())
+	}
+	return used
+}
+
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	if !g.minimapVisible() {
+		return
+	}
+	// Kept for the clicks of the next updates
+	w, h := minimapExtent(g.cfg.WorldWidth, g.cfg.WorldHeight)
+	x, y := g.layout.Place(ui.AnchorTopRight, w, h)
+	g.minimap.rect = image.Rect(int(x), int(y), int(x)+int(w), int(y)+int(h))
+	g.minimap.draw(screen, g.lastState, g.camera, g.cfg.WorldWidth, g.cfg.WorldHeight)
+}
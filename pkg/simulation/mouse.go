@@ -0,0 +1,134 @@
+package simulation
+
+import (
+	"image"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// What a click on the world does in the Game
+const (
+	clickSpawn     = "spawn"         // The left click spawns a red, the right click a blue
+	clickSelect    = "select entity" // The left click selects the entity to inspect
+	clickAttractor = "attractor"     // The left click places an attractor
+	clickRepulsor  = "repulsor"      // The left click places a repulsor
+)
+
+// clickModes lists what a click can do, the default first
+var clickModes = []string{clickSpawn, clickSelect, clickAttractor, clickRepulsor}
+
+// clickHelp tells what the clicks on the world do in each mode, for the help overlay
+var clickHelp = map[string]string{
+	clickSpawn:     "Left click spawns a red, right click a blue, Shift+click a burst",
+	clickSelect:    "Left click selects the entity to inspect",
+	clickAttractor: "Left click places an attractor",
+	clickRepulsor:  "Left click places a repulsor",
+}
+
+// defaultClickMode returns the index in clickModes of what a click does until another mode is
+// picked: spawning, or selecting with the ECS engine which cannot spawn at runtime
+func defaultClickMode(cfg *Config) int {
+	if cfg.Engine == EngineECS {
+		return slices.Index(clickModes, clickSelect)
+	}
+	return 0
+}
+
+const (
+	// defaultBurstSize is the initial number of entities of a shift-click in the Game
+	defaultBurstSize = 10
+	// spawnBurstRadius is the radius of the disc a burst of entities spawns in
+	spawnBurstRadius = 30.0
+	// rightClickSlop is the largest move (screen pixels) of a right click, a longer right drag pans
+	rightClickSlop = 4
+)
+
+// useClick spawns entities (the default), or places an attractor or a repulsor, where the world is
+// clicked, unless widgetClickMode lets the left click select an entity. 'blocked' is true when the click is for the
+// UI, the camera or the capture. It returns true when the click was used.
+func (g *Game) useClick(blocked bool) bool {
+	mode := g.widgetClickMode.Value()
+	if mode == clickSelect {
+		return false
+	}
+	left := !blocked && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	right := mode == clickSpawn && g.rightClicked(blocked)
+	if !left && !right {
+		return false
+	}
+	mx, my := ebiten.CursorPosition()
+	x, y := g.camera.ScreenToWorld(float64(mx), float64(my))
+	if x < 0 || x > g.cfg.WorldWidth || y < 0 || y > g.cfg.WorldHeight {
+		return false
+	}
+	pos := geometry.Vector2D{X: x, Y: y}
+	switch mode {
+	case clickAttractor, clickRepulsor:
+		if g.cfg.Engine == EngineECS {
+			g.engine.Logger().Infof("The %s engine cannot apply attractors", EngineECS)
+			return true
+		}
+		strength := g.widgetAttractorStrength.Value
+		if mode == clickRepulsor {
+			strength = -strength
+		}
+		g.attractors.Add(Attractor{Pos: pos, Strength: strength, Radius: g.widgetAttractorRadius.Value})
+		g.refreshAttractorList()
+		g.widgetAttractorList.Selected = len(g.widgetAttractorList.Options) - 1
+	case clickSpawn:
+		if g.cfg.Engine == EngineECS {
+			g.engine.Logger().Infof("The %s engine cannot spawn entities at runtime", EngineECS)
+			return true
+		}
+		color, n := pb.TeamColor_TEAM_RED, 1
+		if right {
+			color = pb.TeamColor_TEAM_BLUE
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			n = int(g.widgetBurstSize.Value)
+		}
+		queueSpawns(g.commands, g.cfg, g.spawnRand, color, pos, n)
+	}
+	return true
+}
+
+// rightClicked reports whether the right button was just released close to where it was pressed,
+// the press not being blocked: a longer right drag pans the camera
+func (g *Game) rightClicked(blocked bool) bool {
+	mx, my := ebiten.CursorPosition()
+	switch {
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight):
+		g.rightPress, g.rightPending = image.Pt(mx, my), !blocked
+	case inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight):
+		pending := g.rightPending
+		g.rightPending = false
+		d := image.Pt(mx, my).Sub(g.rightPress)
+		return pending && d.X*d.X+d.Y*d.Y <= rightClickSlop*rightClickSlop
+	}
+	return false
+}
+
+// queueSpawns queues 'n' entities of 'color' spawned at the same tick: the first one at 'center', the
+// others scattered within spawnBurstRadius of it inside the world, each with a random velocity like
+// the initial population (see spawnLayout)
+func queueSpawns(q *CommandQueue, cfg *Config, rng *rand.Rand, color pb.TeamColor, center geometry.Vector2D, n int) {
+	batch := NewCommandQueue()
+	for i := range n {
+		pos := center
+		if i > 0 {
+			// Uniform over the disc
+			angle, dist := rng.Float64()*2*math.Pi, spawnBurstRadius*math.Sqrt(rng.Float64())
+			pos.X = min(max(center.X+dist*math.Cos(angle), 0), cfg.WorldWidth)
+			pos.Y = min(max(center.Y+dist*math.Sin(angle), 0), cfg.WorldHeight)
+		}
+		vel := geometry.Vector2D{X: (rng.Float64() - 0.5) * 2, Y: (rng.Float64() - 0.5) * 2}
+		batch.Spawn(color, pos, vel)
+	}
+	q.Append(batch)
+}
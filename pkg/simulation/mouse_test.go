@@ -0,0 +1,51 @@
+package simulation
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestQueueSpawns(t *testing.T) {
+	cfg := combatConfig()
+	q := NewCommandQueue()
+	w, s := newScriptedWorld(cfg, WithCommandQueue(q))
+	s.report(redAt("Red-000", 100, 100))
+	center := geometry.Vector2D{X: 5, Y: 300}
+	queueSpawns(q, cfg, rand.New(rand.NewPCG(1, 2)), pb.TeamColor_TEAM_BLUE, center, 8)
+	if q.Len() != 8 {
+		t.Fatalf("Expected 8 spawns queued at once, got %d", q.Len())
+	}
+	s.tick()
+
+	var blues []*Entity
+	for _, e := range w.order {
+		if e.Color == pb.TeamColor_TEAM_BLUE {
+			blues = append(blues, e)
+		}
+	}
+	if len(blues) != 8 {
+		t.Fatalf("Expected 8 blues spawned at the same tick, got %d", len(blues))
+	}
+	if blues[0].Pos != center {
+		t.Errorf("Expected the first blue on the cursor, got %v", blues[0].Pos)
+	}
+	for _, e := range blues {
+		if e.Pos.DistanceTo(center) > spawnBurstRadius || e.Pos.X < 0 {
+			t.Errorf("Expected %s within the burst and the world, got %v", e.ID, e.Pos)
+		}
+	}
+}
+
+func TestDefaultClickMode(t *testing.T) {
+	cfg := DefaultConfig()
+	if mode := clickModes[defaultClickMode(cfg)]; mode != clickSpawn {
+		t.Errorf("Expected the clicks to spawn without picking a mode, got %q", mode)
+	}
+	cfg.Engine = EngineECS
+	if mode := clickModes[defaultClickMode(cfg)]; mode != clickSelect {
+		t.Errorf("Expected the clicks to select with the %s engine, got %q", EngineECS, mode)
+	}
+}
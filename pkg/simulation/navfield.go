@@ -0,0 +1,143 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// defaultNavWeight is the weight of the navigation force when Config.NavWeight is 0
+const defaultNavWeight = 0.05
+
+// Teams selected by Config.NavTeam and Config.LeaderTeam
+const (
+	TeamBlue = "blue"
+	TeamRed  = "red"
+	TeamBoth = "both"
+)
+
+// teamSelected reports whether the selection 'teams' (TeamBlue, TeamRed or TeamBoth) includes 'team'
+func teamSelected(teams string, team pb.TeamColor) bool {
+	switch teams {
+	case TeamBoth:
+		return true
+	case TeamRed:
+		return team == pb.TeamColor_TEAM_RED
+	case TeamBlue:
+		return team == pb.TeamColor_TEAM_BLUE
+	}
+	return false
+}
+
+// navFieldKey identifies a built field: the spec and everything the field depends on
+type navFieldKey struct {
+	spec          string
+	width, height float64
+	cellSize      float64
+	obstacles     string
+}
+
+// navFields caches the built fields, sampled every tick by every entity
+var navFields sync.Map // navFieldKey -> navFieldEntry
+
+type navFieldEntry struct {
+	field *behavior.NavField
+	err   error
+}
+
+// buildNavField builds the field of Config.NavField: "x,y" leads to that point around the obstacles
+// over the cells of the spatial grid, anything else is a file of arrows drawn by hand (see
+// behavior.ParseNavField)
+func (c *Config) buildNavField() (*behavior.NavField, error) {
+	key := navFieldKey{
+		spec:      c.NavField,
+		width:     c.WorldWidth,
+		height:    c.WorldHeight,
+		cellSize:  gridCellSize(c.DetectionRadius, c.DefenseRadius, c.VisualRange),
+		obstacles: c.Obstacles,
+	}
+	if cached, ok := navFields.Load(key); ok {
+		entry := cached.(navFieldEntry)
+		return entry.field, entry.err
+	}
+	var entry navFieldEntry
+	if target, ok := parseNavTarget(c.NavField); ok {
+		entry.field = behavior.TargetField(c.WorldWidth, c.WorldHeight, key.cellSize, target, c.ObstacleList())
+	} else if src, err := os.ReadFile(c.NavField); err != nil {
+		entry.err = fmt.Errorf("cannot read navigation field: %w", err)
+	} else if entry.field, err = behavior.ParseNavField(string(src), c.WorldWidth, c.WorldHeight); err != nil {
+		entry.err = fmt.Errorf("navigation field %s: %w", c.NavField, err)
+	}
+	navFields.Store(key, entry)
+	return entry.field, entry.err
+}
+
+// parseNavTarget parses the "x,y" target of a navigation field
+func parseNavTarget(spec string) (geometry.Vector2D, bool) {
+	xs, ys, ok := strings.Cut(spec, ",")
+	if !ok {
+		return geometry.Vector2D{}, false
+	}
+	x, errX := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	return geometry.Vector2D{X: x, Y: y}, errX == nil && errY == nil
+}
+
+// NavigationField returns the field followed by the NavTeam, nil when NavField is empty or invalid
+func (c *Config) NavigationField() *behavior.NavField {
+	if c.NavField == "" {
+		return nil
+	}
+	field, _ := c.buildNavField()
+	return field
+}
+
+// follows reports whether the entities of 'team' follow the navigation field, the blues by default
+func (c *Config) follows(team pb.TeamColor) bool {
+	if c.NavTeam == "" {
+		return team == pb.TeamColor_TEAM_BLUE
+	}
+	return teamSelected(c.NavTeam, team)
+}
+
+// applyNavigation adds the force following the navigation field to an entity of the NavTeam,
+// before its strategy moves it
+func applyNavigation(me *Entity, cfg *Config) {
+	if cfg.NavField == "" || !cfg.follows(me.Color) {
+		return
+	}
+	field := cfg.NavigationField()
+	if field == nil {
+		return
+	}
+	weight := cfg.NavWeight
+	if weight == 0 {
+		weight = defaultNavWeight
+	}
+	me.ApplyForce(behavior.Follow(me.Pos, me.Vel, field, cfg.MaxSpeed).Mul(weight))
+}
+
+// navArrowColor draws the navigation field
+var navArrowColor = color.RGBA{R: 80, G: 220, B: 255, A: 160}
+
+// drawNavField draws the direction of every cell of the field as an arrow
+func drawNavField(r Renderer, f *behavior.NavField) {
+	length := f.CellSize * flowArrowLength
+	for row := 0; row < f.Rows; row++ {
+		for col := 0; col < f.Cols; col++ {
+			dir := f.Dir(col, row)
+			if dir.LenSqr() == 0 {
+				continue
+			}
+			center := f.Center(col, row)
+			drawArrow(r, center.X, center.Y, dir.X*length, dir.Y*length, navArrowColor)
+		}
+	}
+}
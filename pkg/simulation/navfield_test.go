@@ -0,0 +1,63 @@
+package simulation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestConfigNavField(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NavField = "900,400"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if cfg.NavigationField() == nil {
+		t.Fatal("Expected a navigation field toward the target")
+	}
+
+	path := filepath.Join(t.TempDir(), "field.nav")
+	if err := os.WriteFile(path, []byte(">>v\n^<<\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.NavField = path
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.NavigationField().Sample(geometry.Vector2D{X: 1, Y: 1}); got != (geometry.Vector2D{X: 1}) {
+		t.Errorf("Expected the hand-drawn field to head right, got %v", got)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.NavField = filepath.Join(t.TempDir(), "missing.nav") },
+		func(c *Config) { c.NavWeight = -1 },
+		func(c *Config) { c.NavTeam = "green" },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestApplyNavigation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NavField = "900,400"
+	blue := &Entity{Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 300, Y: 400}}
+	red := &Entity{Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 300, Y: 400}}
+	applyNavigation(blue, cfg)
+	applyNavigation(red, cfg)
+	if blue.Vel.X <= 0 || red.Vel != (geometry.Vector2D{}) {
+		t.Errorf("Expected only the blue to head to the target, got %v and %v", blue.Vel, red.Vel)
+	}
+	cfg.NavTeam = TeamBoth
+	applyNavigation(red, cfg)
+	if red.Vel.X <= 0 {
+		t.Errorf("Expected the red to follow the field too, got %v", red.Vel)
+	}
+}
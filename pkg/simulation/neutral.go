@@ -0,0 +1,104 @@
+package simulation
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// NeutralColor is the color of the civilians of Config.NumNeutralAtStart: the entities of no team,
+// which either team converts on contact
+const NeutralColor = pb.TeamColor_TEAM_NEUTRAL
+
+// StrategyCivilian is the behavior of the civilians. It is registered for them only: BehaviorNames
+// never offers it to the red or the blue team.
+const StrategyCivilian = "civilian"
+
+func init() {
+	RegisterBehavior(StrategyCivilian, NeutralColor, func() Behavior { return &Civilian{} })
+}
+
+const (
+	// civilianSpeed is the fraction of MaxSpeed a civilian cannot exceed
+	civilianSpeed = 0.5
+	// civilianFear is the weight of the flight of a civilian from the closest combatant in sight
+	civilianFear = 0.1
+)
+
+// Civilian wanders slowly and flees the closest combatant it sees, whatever its team
+type Civilian struct{}
+
+func (c *Civilian) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	speed := cfg.MaxSpeed * civilianSpeed
+	me.ApplyForce(behavior.AvoidObstacles(me.Pos, me.Vel, cfg.ObstacleList(), cfg.ObstacleReach()))
+	var threat *pb.ActorState
+	minDistSq := math.MaxFloat64
+	for _, target := range perception.GetTargets() {
+		if distSq := me.Pos.DistanceSquaredTo(GeomVector2DFromProto(target.Position)); distSq < minDistSq {
+			threat, minDistSq = target, distSq
+		}
+	}
+	if threat != nil {
+		me.ApplyForce(behavior.Flee(me.Pos, me.Vel, GeomVector2DFromProto(threat.Position), speed).Mul(civilianFear))
+	}
+	wander(me)
+	me.ClampVelocity(0, speed)
+	me.UpdatePhysics()
+	me.BounceOffWalls(cfg.WorldWidth, cfg.WorldHeight)
+}
+
+// spawnNeutrals places the civilians of 'cfg' at random over the world, each slot from its own stream
+func spawnNeutrals(cfg *Config, seed uint64, spawn func(color pb.TeamColor, pos, vel geometry.Vector2D)) {
+	for i := 0; i < cfg.NumNeutralAtStart; i++ {
+		rng := entityRand(seed, fmt.Sprintf("spawn/neutral/%d", i))
+		pos := geometry.Vector2D{X: 50 + rng.Float64()*(cfg.WorldWidth-100), Y: 50 + rng.Float64()*(cfg.WorldHeight-100)}
+		vel := geometry.Vector2D{X: (rng.Float64() - 0.5) * 2, Y: (rng.Float64() - 0.5) * 2}
+		spawn(NeutralColor, pos, vel)
+	}
+}
+
+// resolveCaptures converts the civilians touched during the tick to the team with the most
+// entities touching them, credited to the first of them by ID. A civilian touched by as many reds
+// as blues stays neutral.
+func (w *world) resolveCaptures() {
+	captures := w.captures
+	if len(captures) == 0 {
+		return
+	}
+	slices.SortFunc(captures, func(a, b contact) int {
+		return cmp.Or(strings.Compare(a.victim.ID, b.victim.ID), strings.Compare(a.attacker.ID, b.attacker.ID))
+	})
+	for start := 0; start < len(captures); {
+		end := start + 1
+		for end < len(captures) && captures[end].victim == captures[start].victim {
+			end++
+		}
+		var red, blue int
+		var firstRed, firstBlue *Entity
+		for _, c := range captures[start:end] {
+			if c.attacker.Color == pb.TeamColor_TEAM_RED {
+				red++
+				firstRed = cmp.Or(firstRed, c.attacker)
+			} else {
+				blue++
+				firstBlue = cmp.Or(firstBlue, c.attacker)
+			}
+		}
+		victim := captures[start].victim
+		switch {
+		case red > blue:
+			w.convertOnce(victim.ID, pb.TeamColor_TEAM_RED, firstRed.ID, victim.ID)
+		case blue > red:
+			w.convertOnce(victim.ID, pb.TeamColor_TEAM_BLUE, firstBlue.ID, victim.ID)
+		}
+		start = end
+	}
+	clear(captures)
+	w.captures = captures[:0]
+}
@@ -0,0 +1,152 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func neutralAt(id string, x, y float64) *pb.ActorState {
+	return &pb.ActorState{Id: id, Color: NeutralColor, Position: &pb.Vector{X: x, Y: y}, Velocity: &pb.Vector{}}
+}
+
+func TestNeutral_captures(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []*pb.ActorState
+		want   []convertOrder
+	}{
+		{
+			name:   "a red captures a civilian",
+			states: []*pb.ActorState{redAt("Red-000", 100, 100), neutralAt("Neutral-000", 105, 100)},
+			want:   []convertOrder{"Neutral-000->TEAM_RED"},
+		},
+		{
+			name:   "a blue captures a civilian",
+			states: []*pb.ActorState{blueAt("Blue-000", 100, 100), neutralAt("Neutral-000", 105, 100)},
+			want:   []convertOrder{"Neutral-000->TEAM_BLUE"},
+		},
+		{
+			name: "the team with the most entities in contact wins",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 116, 100), blueAt("Blue-001", 108, 107),
+				neutralAt("Neutral-000", 108, 100),
+			},
+			want: []convertOrder{"Neutral-000->TEAM_BLUE"},
+		},
+		{
+			name: "a tie leaves the civilian neutral",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 110, 100), neutralAt("Neutral-000", 105, 100),
+			},
+			want: nil,
+		},
+		{
+			name:   "no capture beyond the contact radius",
+			states: []*pb.ActorState{redAt("Red-000", 100, 100), neutralAt("Neutral-000", 111, 100)},
+			want:   nil,
+		},
+		{
+			name:   "civilians do not capture each other",
+			states: []*pb.ActorState{neutralAt("Neutral-000", 100, 100), neutralAt("Neutral-001", 105, 100)},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, s := newScriptedWorld(combatConfig())
+			s.report(tt.states...)
+			if got := s.tick(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Converts = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeutral_perception(t *testing.T) {
+	w, s := newScriptedWorld(combatConfig())
+	s.report(redAt("Red-000", 100, 100), blueAt("Blue-000", 200, 100), neutralAt("Neutral-000", 130, 100), neutralAt("Neutral-001", 230, 100))
+	w.rebuildGrid()
+	ranges := scanRanges{detectionSq: 50 * 50}
+	if enemies, _ := w.scanNeighbors(w.entities["Red-000"], ranges); len(enemies) != 1 || enemies[0].Id != "Neutral-000" {
+		t.Errorf("Expected the red to chase the civilian, got %v", enemies)
+	}
+	if enemies, _ := w.scanNeighbors(w.entities["Blue-000"], ranges); len(enemies) != 0 {
+		t.Errorf("Expected the blue to ignore the civilian, got %v", enemies)
+	}
+	if enemies, _ := w.scanNeighbors(w.entities["Neutral-001"], ranges); len(enemies) != 1 || enemies[0].Id != "Blue-000" {
+		t.Errorf("Expected the civilian to see the blue, got %v", enemies)
+	}
+}
+
+func TestNeutral_spawnAndCount(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart, cfg.NumBlueAtStart, cfg.NumNeutralAtStart = 2, 3, 4
+	snaps := make(chan *pb.WorldSnapshot, 1)
+	engine, err := NewLocalEngine(context.Background(), snaps, cfg)
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	w := engine.(*localEngine).world
+	for i := range 4 {
+		e := w.entities[fmt.Sprintf("Neutral-%03d", i)]
+		if e == nil || e.Color != NeutralColor {
+			t.Fatalf("Expected civilian %d to spawn, got %v", i, e)
+		}
+		// Away from the teams: no capture on the first tick
+		e.Pos = geometry.Vector2D{X: 50 + 10*float64(i), Y: cfg.WorldHeight - 50}
+	}
+	w.rebuildGrid()
+	w.handle(&pb.Tick{})
+	snap := <-snaps
+	if snap.RedCount != 2 || snap.BlueCount != 3 || len(snap.Actors) != 9 {
+		t.Errorf("Expected 2 reds, 3 blues and 9 actors in the snapshot, got %d, %d and %d", snap.RedCount, snap.BlueCount, len(snap.Actors))
+	}
+	if snap.NeutralCount != 4 || snap.OffscreenRed+snap.OffscreenBlue != 0 {
+		t.Errorf("Expected the 4 civilians counted apart from the teams, got %d", snap.NeutralCount)
+	}
+}
+
+func TestCivilian_speed(t *testing.T) {
+	cfg := DefaultConfig()
+	me := &Entity{ID: "Neutral-000", Color: NeutralColor, Pos: geometry.Vector2D{X: 400, Y: 300}, Vel: geometry.Vector2D{Y: 20}}
+	perception := &pb.Perception{Targets: []*pb.ActorState{redAt("Red-000", 410, 300)}}
+	for range 10 {
+		(&Civilian{}).Update(me, perception, cfg)
+		if speed := me.Vel.Len(); speed > cfg.MaxSpeed*civilianSpeed+1e-9 {
+			t.Fatalf("Civilian speed %f exceeds %f", speed, cfg.MaxSpeed*civilianSpeed)
+		}
+	}
+	if me.Pos.X >= 400 {
+		t.Errorf("Expected the civilian to flee the red, got %v", me.Pos)
+	}
+}
+
+func TestConfig_numNeutralAtStart(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumNeutralAtStart = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for a negative numNeutralAtStart")
+	}
+	cfg.NumNeutralAtStart = 10
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	cfg.Engine = EngineECS
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected the ecs engine to reject the civilians")
+	}
+	if got := cfg.StrategyFor(NeutralColor); got != StrategyCivilian {
+		t.Errorf("StrategyFor(NeutralColor) = %q, expected %q", got, StrategyCivilian)
+	}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if slices.Contains(BehaviorNames(team), StrategyCivilian) {
+			t.Errorf("Expected %s not to be offered %q", team, StrategyCivilian)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// defaultObstacleLookahead is the reach of the whiskers of the obstacle avoidance when
+// Config.ObstacleLookahead is 0
+const defaultObstacleLookahead = 60.0
+
+// parsedObstacles caches the obstacles of the Config.Obstacles specs, read every tick by every boid
+var parsedObstacles sync.Map // spec -> []behavior.Obstacle
+
+// ParseObstacles parses a list of discs "x,y,radius" separated by semicolons, e.g. "300,400,50;700,200,80"
+func ParseObstacles(spec string) ([]behavior.Obstacle, error) {
+	var obstacles []behavior.Obstacle
+	for i, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("obstacle %d: %q is not x,y,radius", i+1, item)
+		}
+		var values [3]float64
+		for j, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, fmt.Errorf("obstacle %d: %q is not x,y,radius: %w", i+1, item, err)
+			}
+			values[j] = v
+		}
+		if values[2] <= 0 {
+			return nil, fmt.Errorf("obstacle %d: the radius (%f) must be positive", i+1, values[2])
+		}
+		obstacles = append(obstacles, behavior.Obstacle{Center: geometry.Vector2D{X: values[0], Y: values[1]}, Radius: values[2]})
+	}
+	return obstacles, nil
+}
+
+// ObstacleList returns the obstacles of the world, nil when Obstacles is empty or invalid
+func (c *Config) ObstacleList() []behavior.Obstacle {
+	if c.Obstacles == "" {
+		return nil
+	}
+	if cached, ok := parsedObstacles.Load(c.Obstacles); ok {
+		return cached.([]behavior.Obstacle)
+	}
+	obstacles, err := ParseObstacles(c.Obstacles)
+	if err != nil {
+		obstacles = nil
+	}
+	parsedObstacles.Store(c.Obstacles, obstacles)
+	return obstacles
+}
+
+// ObstacleReach returns the lookahead of the obstacle avoidance, defaultObstacleLookahead when 0
+func (c *Config) ObstacleReach() float64 {
+	if c.ObstacleLookahead == 0 {
+		return defaultObstacleLookahead
+	}
+	return c.ObstacleLookahead
+}
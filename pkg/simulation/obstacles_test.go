@@ -0,0 +1,46 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestParseObstacles(t *testing.T) {
+	obstacles, err := ParseObstacles(" 300,400,50 ; 700.5,200,80;")
+	if err != nil {
+		t.Fatalf("ParseObstacles() error = %v", err)
+	}
+	if len(obstacles) != 2 || obstacles[1].Center != (geometry.Vector2D{X: 700.5, Y: 200}) || obstacles[1].Radius != 80 {
+		t.Errorf("Unexpected obstacles %+v", obstacles)
+	}
+	for _, spec := range []string{"300,400", "a,b,c", "300,400,0", "1,2,3;4,5"} {
+		if _, err := ParseObstacles(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.Obstacles = "300,400"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected invalid obstacles to be rejected")
+	}
+	cfg.Obstacles = "300,400,50"
+	cfg.Engine = EngineECS
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected the obstacles to be rejected by the ecs engine")
+	}
+}
+
+func TestComputeBoidUpdate_avoidsObstacles(t *testing.T) {
+	cfg := DefaultConfig()
+	me := &Entity{Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 300, Y: 400}, Vel: geometry.Vector2D{X: 2}}
+	if force := ComputeBoidUpdate(me, nil, cfg); force != (geometry.Vector2D{}) {
+		t.Fatalf("Expected no force for a lonely boid, got %v", force)
+	}
+	cfg.Obstacles = "340,410,20"
+	if force := ComputeBoidUpdate(me, nil, cfg); force.Y >= 0 {
+		t.Errorf("Expected the boid to steer up around the obstacle, got %v", force)
+	}
+}
@@ -0,0 +1,56 @@
+package simulation
+
+// PanelParameter is a numeric Config field adjusted with a slider, in the UIPanel of the Game
+// and in the Lab dashboard, which both build their controls from PanelParameters
+type PanelParameter struct {
+	Section string `json:"section"`
+	Name    string `json:"name"` // JSON name of the Config field
+	Label   string `json:"label"`
+	// Description explains the parameter to a newcomer (tooltip of the slider)
+	Description string  `json:"description,omitempty"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	// Step is the increment of the slider (arrow keys, snapping of the drag), 0 for a continuous one
+	Step float64 `json:"step,omitempty"`
+	// Integer is true for the int fields, Restart for the parameters only applied when the simulation restarts
+	Integer bool `json:"integer,omitempty"`
+	Restart bool `json:"restart,omitempty"`
+}
+
+// PanelParameters are the sliders of the control panels, in display order
+var PanelParameters = []PanelParameter{
+	{Section: "Interaction Radii", Name: "detectionRadius", Label: "Detection Radius", Description: "How far a red sees the blues it chases.", Min: 10, Max: 300, Step: 1},
+	{Section: "Interaction Radii", Name: "defenseRadius", Label: "Defense Radius", Description: "Blues within this distance of an attacked blue defend it: with enough of them (Defenders Needed) the attacker turns blue.", Min: 10, Max: 300, Step: 1},
+	{Section: "Interaction Radii", Name: "contactRadius", Label: "Contact Radius", Description: "Distance at which a red touching a blue starts a fight.", Min: 5, Max: 50, Step: 1},
+	{Section: "Interaction Radii", Name: "visualRange", Label: "Visual Range", Description: "How far a blue sees the friends it flocks with (cohesion and alignment).", Min: 10, Max: 150, Step: 1},
+	{Section: "Interaction Radii", Name: "protectedRange", Label: "Protected Range", Description: "Blues closer than this push each other away (separation).", Min: 5, Max: 50, Step: 1},
+	{Section: "Physics & Behavior", Name: "maxSpeed", Label: "Max Speed", Description: "Top speed of every entity, in pixels per tick.", Min: 1, Max: 10, Step: 0.1},
+	{Section: "Physics & Behavior", Name: "minSpeed", Label: "Min Speed", Description: "Speed under which a blue speeds up again, so that the flock never stalls.", Min: 0.5, Max: 8, Step: 0.1},
+	{Section: "Physics & Behavior", Name: "aggression", Label: "Aggression", Description: "Strength of the pull of a red toward the blue it chases.", Min: 0.1, Max: 2.0, Step: 0.05},
+	{Section: "Boids Flocking", Name: "centeringFactor", Label: "Centering Factor", Description: "Cohesion: how strongly a blue steers toward the center of its visible friends.", Min: 0.0001, Max: 0.01, Step: 0.0001},
+	{Section: "Boids Flocking", Name: "avoidFactor", Label: "Avoid Factor", Description: "Separation: how strongly a blue steers away from the friends inside its protected range.", Min: 0.001, Max: 0.2, Step: 0.001},
+	{Section: "Boids Flocking", Name: "matchingFactor", Label: "Matching Factor", Description: "Alignment: how strongly a blue matches the mean velocity of its visible friends.", Min: 0.001, Max: 0.2, Step: 0.001},
+	{Section: "Boids Flocking", Name: "turnFactor", Label: "Turn Factor", Description: "How strongly the blues turn back near the edges of the world.", Min: 0.05, Max: 1.0, Step: 0.01},
+	{Section: "Wind", Name: "windStrength", Label: "Wind Strength", Description: "Force of the wind pushing every entity, 0 for none.", Min: 0, Max: 0.3, Step: 0.005},
+	{Section: "Wind", Name: "windDirection", Label: "Wind Direction", Description: "Where the wind blows, in degrees clockwise from the right of the world.", Min: 0, Max: 360, Step: 5},
+	{Section: "Population (Restart Required)", Name: "numRedAtStart", Label: "Red Actors", Description: "Number of reds spawned by the next restart.", Min: 1, Max: 300, Integer: true, Restart: true},
+	{Section: "Population (Restart Required)", Name: "numBlueAtStart", Label: "Blue Actors", Description: "Number of blues spawned by the next restart.", Min: 1, Max: 1000, Integer: true, Restart: true},
+	{Section: "Population (Restart Required)", Name: "numNeutralAtStart", Label: "Civilians", Description: "Number of civilians spawned by the next restart: of no team, they join the team touching them with the most entities.", Min: 0, Max: 300, Integer: true, Restart: true},
+	{Section: "Combat (Restart Required)", Name: "defendersNeeded", Label: "Defenders Needed", Description: "Number of blues defending an attacked blue that turn the attacker blue.", Min: 1, Max: 10, Integer: true, Restart: true},
+}
+
+// panelParameter returns the parameter of the Config field with this JSON name
+func panelParameter(name string) PanelParameter {
+	for _, p := range PanelParameters {
+		if p.Name == name {
+			return p
+		}
+	}
+	panic("simulation: no panel parameter " + name)
+}
+
+// panelValue returns the value of the parameter in 'cfg'
+func panelValue(cfg *Config, name string) float64 {
+	field, _ := configField(name)
+	return fieldValue(cfg, field)
+}
@@ -0,0 +1,47 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPanelParameters_steps(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, p := range PanelParameters {
+		if p.Integer {
+			continue
+		}
+		// Fine enough to reach every default value, coarse enough to cross the range with the arrow keys
+		if p.Step <= 0 || p.Step > (p.Max-p.Min)/10 || (p.Max-p.Min)/p.Step > 1000 {
+			t.Errorf("%s: step %v does not fit the range [%v, %v]", p.Name, p.Step, p.Min, p.Max)
+		}
+		if v := panelValue(cfg, p.Name); math.Abs(math.Remainder(v-p.Min, p.Step)) > 1e-9 {
+			t.Errorf("%s: the default %v is not on a step of %v", p.Name, v, p.Step)
+		}
+	}
+}
+
+func TestConfig_uiTheme(t *testing.T) {
+	cfg := DefaultConfig()
+	if theme, err := cfg.UITheme(); err != nil || theme.Name != "dark" {
+		t.Errorf("Expected the dark theme by default, got %q %v", theme.Name, err)
+	}
+	cfg.Theme, cfg.UIPadding, cfg.UIFontScale = "Light", 14, 2
+	theme, err := cfg.UITheme()
+	if err != nil || theme.Name != "light" || theme.Padding != 14 || theme.FontScale != 2 {
+		t.Errorf("Expected the light theme with the overrides, got %+v %v", theme, err)
+	}
+	for _, invalid := range []func(c *Config){
+		func(c *Config) { c.Theme = "neon" },
+		func(c *Config) { c.UIPadding = -1 },
+		func(c *Config) { c.UIFontScale = 0.5 },
+		func(c *Config) { c.UIScale = 0.25 },
+		func(c *Config) { c.UIScale = 5 },
+	} {
+		cfg := DefaultConfig()
+		invalid(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package simulation
+
+import (
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// repathTicks is the number of ticks a hunter follows its path before finding it again
+	repathTicks = 30
+	// pathClearance is the margin a hunter keeps from the obstacles when it heads straight to a waypoint
+	pathClearance = 10.0
+)
+
+// pathGridKey identifies a built grid: everything the grid depends on
+type pathGridKey struct {
+	width, height float64
+	cellSize      float64
+	obstacles     string
+}
+
+// pathGrids caches the grids the hunters find their way in
+var pathGrids sync.Map // pathGridKey -> *behavior.Grid
+
+// PathGrid returns the cells of the spatial grid blocked by the obstacles, in which the hunters find
+// their way to a target out of sight (see huntPath), nil when there is no obstacle
+func (c *Config) PathGrid() *behavior.Grid {
+	obstacles := c.ObstacleList()
+	if len(obstacles) == 0 {
+		return nil
+	}
+	key := pathGridKey{
+		width:     c.WorldWidth,
+		height:    c.WorldHeight,
+		cellSize:  gridCellSize(c.DetectionRadius, c.DefenseRadius, c.VisualRange),
+		obstacles: c.Obstacles,
+	}
+	if cached, ok := pathGrids.Load(key); ok {
+		return cached.(*behavior.Grid)
+	}
+	grid := behavior.NewGrid(key.width, key.height, key.cellSize, obstacles)
+	pathGrids.Store(key, grid)
+	return grid
+}
+
+// huntPath is the way of a hunter to its target around the obstacles: the waypoints found by A* over
+// the PathGrid, followed one after the other and found again every repathTicks or when the target
+// moved away from the end of the path. A nil huntPath always heads straight to the target.
+type huntPath struct {
+	waypoints []geometry.Vector2D
+	goal      geometry.Vector2D
+	age       int
+}
+
+// aim returns the point 'me' heads to for chasing 'target': the target itself when the way is
+// clear, or unreachable, else the next waypoint of the path
+func (p *huntPath) aim(me *Entity, target geometry.Vector2D, cfg *Config) geometry.Vector2D {
+	grid := cfg.PathGrid()
+	if p == nil || grid == nil || grid.Clear(me.Pos, target, pathClearance) {
+		if p != nil {
+			p.waypoints = p.waypoints[:0]
+		}
+		return target
+	}
+	p.age++
+	if len(p.waypoints) == 0 || p.age > repathTicks || p.goal.DistanceTo(target) > grid.CellSize {
+		p.waypoints = behavior.FindPath(grid, me.Pos, target, pathClearance)
+		p.goal, p.age = target, 0
+		if len(p.waypoints) == 0 {
+			return target
+		}
+	}
+	// Drop the waypoint reached, or passed when the next one is already in sight
+	for len(p.waypoints) > 1 && (me.Pos.DistanceTo(p.waypoints[0]) < grid.CellSize/2 ||
+		grid.Clear(me.Pos, p.waypoints[1], pathClearance)) {
+		p.waypoints = p.waypoints[1:]
+	}
+	return p.waypoints[0]
+}
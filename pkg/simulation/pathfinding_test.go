@@ -0,0 +1,51 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestHuntPath(t *testing.T) {
+	cfg := DefaultConfig()
+	me := &Entity{Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 400, Y: 400}}
+	target := geometry.Vector2D{X: 620, Y: 400}
+	var path huntPath
+	if got := path.aim(me, target, cfg); got != target {
+		t.Errorf("Expected to head straight to the target without obstacle, got %v", got)
+	}
+
+	// Hidden behind a disc: around it, through a waypoint above or below
+	cfg.Obstacles = "510,400,60"
+	waypoint := path.aim(me, target, cfg)
+	if math.Abs(waypoint.Y-400) < 60 || len(path.waypoints) < 2 {
+		t.Fatalf("Expected a waypoint around the obstacle, got %v on %v", waypoint, path.waypoints)
+	}
+	if got := path.aim(me, target, cfg); got != waypoint {
+		t.Errorf("Expected to keep the waypoint, got %v then %v", waypoint, got)
+	}
+	me.Pos = waypoint
+	if got := path.aim(me, target, cfg); got == waypoint {
+		t.Errorf("Expected the next waypoint once %v is reached", waypoint)
+	}
+
+	// A nil path always heads straight
+	if got := (*huntPath)(nil).aim(me, target, cfg); got != target {
+		t.Errorf("Expected a nil path to head to the target, got %v", got)
+	}
+}
+
+func TestClassicHunter_pathfinds(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Obstacles = "510,400,60"
+	me := &Entity{Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 400, Y: 400}, Vel: geometry.Vector2D{X: 1}}
+	perception := &pb.Perception{Targets: []*pb.ActorState{{
+		Id: "blue", Color: pb.TeamColor_TEAM_BLUE, Position: &pb.Vector{X: 620, Y: 400},
+	}}}
+	(&ClassicHunter{}).Update(me, perception, cfg)
+	if math.Abs(me.Vel.Y) < 0.5 {
+		t.Errorf("Expected the hunter to turn around the obstacle, got %v", me.Vel)
+	}
+}
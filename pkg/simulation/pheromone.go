@@ -0,0 +1,186 @@
+package simulation
+
+import (
+	"image/color"
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// pheromoneCellSize is the size of the cells of the pheromone fields
+	pheromoneCellSize = 20.0
+	// defaultPheromoneDiffusion is the fraction of a cell spread to its neighbors per tick when
+	// Config.PheromoneDiffusion is 0
+	defaultPheromoneDiffusion = 0.1
+	// defaultPheromoneEvaporation is the fraction of a cell lost per tick when
+	// Config.PheromoneEvaporation is 0
+	defaultPheromoneEvaporation = 0.02
+	// defaultPheromoneWeight is the weight of the pheromone force when Config.PheromoneWeight is 0
+	defaultPheromoneWeight = 0.05
+	// pheromoneTrail is the amount an entity deposits per tick where it is
+	pheromoneTrail = 1.0
+	// pheromoneAttack is the danger deposited where an entity was converted
+	pheromoneAttack = 25.0
+	// pheromoneMinSlope is the slope under which an entity ignores the field
+	pheromoneMinSlope = 0.01
+)
+
+// pheromoneState is what the entities smell, published by the world at every tick: the reds
+// deposit danger where they hunt and attack, the blues deposit their scent where they fly
+type pheromoneState struct {
+	danger, scent *behavior.Pheromone
+}
+
+// Pheromones links the world to whoever shows its pheromone fields, e.g. the Game with its heatmap.
+// Give it to the world with WithPheromones. A Pheromones is safe for concurrent use.
+type Pheromones struct {
+	state atomic.Pointer[pheromoneState]
+}
+
+// Fields returns the danger deposited by the reds and the scent of the blues, nil without
+// Config.Pheromones. They must not be modified.
+func (p *Pheromones) Fields() (danger, scent *behavior.Pheromone) {
+	state := p.state.Load()
+	if state == nil {
+		return nil, nil
+	}
+	return state.danger, state.scent
+}
+
+// WithPheromones makes the world publish its pheromone fields to 'p' at every tick
+func WithPheromones(p *Pheromones) WorldOption {
+	return func(w *world) {
+		w.pheromones.control = p
+	}
+}
+
+// pheromoneTracker keeps the pheromone fields of the world from a tick to the next
+type pheromoneTracker struct {
+	control *Pheromones
+	// grid is the grid of the fields, built for the world size and the obstacles of 'key'
+	key  pathGridKey
+	grid *behavior.Grid
+	// attacks are where entities were converted since the last update
+	attacks []geometry.Vector2D
+	state   *pheromoneState
+}
+
+// markAttack records a conversion at 'pos', deposited as danger at the next update
+func (t *pheromoneTracker) markAttack(pos geometry.Vector2D) {
+	t.attacks = append(t.attacks, pos)
+}
+
+// updatePheromones lets the fields of the last tick spread and evaporate, adds the deposits of the
+// entities and the conversions, then publishes them. The published fields are never modified: the
+// individuals may still read them while the next ones are computed.
+func (w *world) updatePheromones() {
+	t := &w.pheromones
+	if !w.cfg.Pheromones {
+		if t.state != nil {
+			t.state, t.attacks = nil, t.attacks[:0]
+			w.live.pheromones.Store(nil)
+			if t.control != nil {
+				t.control.state.Store(nil)
+			}
+		}
+		return
+	}
+	key := pathGridKey{width: w.cfg.WorldWidth, height: w.cfg.WorldHeight, cellSize: pheromoneCellSize, obstacles: w.cfg.Obstacles}
+	if t.grid == nil || t.key != key {
+		t.key, t.grid = key, behavior.NewGrid(key.width, key.height, key.cellSize, w.cfg.ObstacleList())
+		t.state = nil
+	}
+	var next pheromoneState
+	if t.state == nil {
+		next.danger, next.scent = behavior.NewPheromone(*t.grid), behavior.NewPheromone(*t.grid)
+	} else {
+		diffusion, evaporation := w.cfg.pheromoneRates()
+		next.danger = t.state.danger.Step(diffusion, evaporation)
+		next.scent = t.state.scent.Step(diffusion, evaporation)
+	}
+	for _, e := range w.order {
+		switch e.Color {
+		case pb.TeamColor_TEAM_RED:
+			next.danger.Deposit(e.Pos, pheromoneTrail)
+		case pb.TeamColor_TEAM_BLUE:
+			next.scent.Deposit(e.Pos, pheromoneTrail)
+		}
+	}
+	for _, pos := range t.attacks {
+		next.danger.Deposit(pos, pheromoneAttack)
+	}
+	t.attacks = t.attacks[:0]
+	t.state = &next
+	w.live.pheromones.Store(t.state)
+	if t.control != nil {
+		t.control.state.Store(t.state)
+	}
+}
+
+// pheromoneRates returns the diffusion and the evaporation of the fields
+func (c *Config) pheromoneRates() (diffusion, evaporation float64) {
+	diffusion, evaporation = c.PheromoneDiffusion, c.PheromoneEvaporation
+	if diffusion == 0 {
+		diffusion = defaultPheromoneDiffusion
+	}
+	if evaporation == 0 {
+		evaporation = defaultPheromoneEvaporation
+	}
+	return diffusion, evaporation
+}
+
+// applyPheromones steers a blue down the slope of the danger, away from the areas recently hunted
+// and attacked, and a red up the slope of the scent, along the trail of the blues, with the weight
+// PheromoneWeight
+func applyPheromones(me *Entity, state *pheromoneState, cfg *Config) {
+	if state == nil {
+		return
+	}
+	slope := state.scent.Gradient(me.Pos)
+	if me.Color == pb.TeamColor_TEAM_BLUE {
+		slope = state.danger.Gradient(me.Pos).Mul(-1)
+	}
+	if slope.Len() < pheromoneMinSlope {
+		return
+	}
+	weight := cfg.PheromoneWeight
+	if weight == 0 {
+		weight = defaultPheromoneWeight
+	}
+	me.ApplyForce(slope.Normalize().Mul(weight))
+}
+
+// drawPheromones draws the fields as a heatmap below the entities: the danger in red, the scent in
+// blue, each cell as opaque as its amount relative to the largest one of its field
+func drawPheromones(r Renderer, danger, scent *behavior.Pheromone) {
+	for _, field := range []struct {
+		p   *behavior.Pheromone
+		clr color.RGBA
+	}{{scent, color.RGBA{R: 40, G: 90, B: 255}}, {danger, color.RGBA{R: 255, G: 40, B: 40}}} {
+		if field.p == nil {
+			continue
+		}
+		top := field.p.Max()
+		if top == 0 {
+			continue
+		}
+		half := float32(field.p.CellSize / 2)
+		for row := 0; row < field.p.Rows; row++ {
+			for col := 0; col < field.p.Cols; col++ {
+				level := field.p.Level[row*field.p.Cols+col]
+				if level == 0 {
+					continue
+				}
+				clr := field.clr
+				clr.A = uint8(120 * level / top)
+				// A stroke as thick as the cell fills it
+				center := field.p.Center(col, row)
+				x, y := float32(center.X), float32(center.Y)
+				r.StrokeLine(x-half, y, x+half, y, 2*half, clr)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestConfigPheromones(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Pheromones = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.PheromoneDiffusion = 1.5 },
+		func(c *Config) { c.PheromoneEvaporation = -0.1 },
+		func(c *Config) { c.PheromoneWeight = -1 },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestWorld_updatePheromones(t *testing.T) {
+	cfg := &Config{WorldWidth: 400, WorldHeight: 400, DetectionRadius: 100, Pheromones: true}
+	control := &Pheromones{}
+	w := newWorld(nil, cfg, WithPheromones(control))
+	cfg = w.cfg
+	red := &Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 110, Y: 110}}
+	blue := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 310, Y: 310}}
+	w.addEntity(red)
+	w.addEntity(blue)
+
+	w.updatePheromones()
+	state := w.live.pheromones.Load()
+	if state.danger.At(red.Pos) != pheromoneTrail || state.scent.At(blue.Pos) != pheromoneTrail || state.danger.At(blue.Pos) != 0 {
+		t.Fatalf("Expected each team to deposit where it is, got danger %v and scent %v", state.danger.At(red.Pos), state.scent.At(blue.Pos))
+	}
+	if danger, _ := control.Fields(); danger != state.danger {
+		t.Error("Expected the fields published to the control")
+	}
+
+	// A conversion leaves a burst of danger, the last fields are kept untouched
+	w.pheromones.markAttack(blue.Pos)
+	w.updatePheromones()
+	next := w.live.pheromones.Load()
+	if next.danger.At(blue.Pos) < pheromoneAttack || state.danger.At(blue.Pos) != 0 {
+		t.Errorf("Expected a burst of danger where the blue was attacked, got %v", next.danger.At(blue.Pos))
+	}
+	if got := next.danger.At(red.Pos); got <= pheromoneTrail || got >= 2*pheromoneTrail {
+		t.Errorf("Expected the danger of the red to spread and evaporate before the next deposit, got %v", got)
+	}
+
+	cfg.Pheromones = false
+	w.updatePheromones()
+	if w.live.pheromones.Load() != nil {
+		t.Error("Expected no field without Pheromones")
+	}
+	if danger, scent := control.Fields(); danger != nil || scent != nil {
+		t.Error("Expected the control cleared without Pheromones")
+	}
+}
+
+func TestApplyPheromones(t *testing.T) {
+	cfg := &Config{WorldWidth: 400, WorldHeight: 400, DetectionRadius: 100, Pheromones: true}
+	w := newWorld(nil, cfg)
+	w.addEntity(&Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 110, Y: 110}})
+	w.addEntity(&Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 150, Y: 110}})
+	w.updatePheromones()
+	w.updatePheromones() // Spread to the neighbor cells
+	state := w.live.pheromones.Load()
+
+	// Right of the red's cell: the blue flees right, a red smelling the blue's scent heads to it
+	blue := &Entity{Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: 130, Y: 110}}
+	applyPheromones(blue, state, cfg)
+	if blue.Vel.X <= 0 {
+		t.Errorf("Expected the blue to steer away from the danger, got %v", blue.Vel)
+	}
+	red := &Entity{Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 130, Y: 110}}
+	applyPheromones(red, state, cfg)
+	if red.Vel.X <= 0 {
+		t.Errorf("Expected the red to follow the scent, got %v", red.Vel)
+	}
+}
@@ -0,0 +1,81 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui/text"
+)
+
+var (
+	chartEnergyLine   = color.RGBA{R: 255, G: 200, B: 0, A: 255}
+	chartMomentumLine = color.RGBA{R: 0, G: 220, B: 200, A: 255}
+	chartViolation    = color.RGBA{R: 255, G: 60, B: 60, A: 255}
+)
+
+// drawPhysicsChart draws the kinetic energy and the momentum of 'history' as two lines, each with its
+// own scale, in the rectangle (x, y, w, h), with the count of violations and the last one
+func drawPhysicsChart(screen *ebiten.Image, history *PhysicsHistory, x, y, w, h float32) {
+	vector.FillRect(screen, x, y, w, h, chartBackground, true)
+	n := history.Len()
+	if n < 2 {
+		return
+	}
+
+	maxEnergy, maxMomentum := 1.0, 1.0
+	for i := 0; i < n; i++ {
+		p := history.At(i)
+		if !isFinite(p.KineticEnergy) || !isFinite(p.Momentum()) {
+			continue
+		}
+		maxEnergy = max(maxEnergy, p.KineticEnergy)
+		maxMomentum = max(maxMomentum, p.Momentum())
+	}
+
+	step := max(1, n/int(w))
+	px := func(i int) float32 { return x + w*float32(i)/float32(n-1) }
+	py := func(v, top float64) float32 {
+		if !isFinite(v) {
+			v = top // A NaN is drawn at the top of the chart
+		}
+		return y + h - h*float32(min(v, top)/top)
+	}
+	line := func(value func(replay.Physics) float64, top float64, clr color.RGBA) {
+		prev := history.At(0)
+		prevIdx := 0
+		for i := step; i < n; i += step {
+			cur := history.At(i)
+			vector.StrokeLine(screen, px(prevIdx), py(value(prev), top), px(i), py(value(cur), top), 1, clr, true)
+			prev, prevIdx = cur, i
+		}
+	}
+	line(replay.Physics.Momentum, maxMomentum, chartMomentumLine)
+	line(func(p replay.Physics) float64 { return p.KineticEnergy }, maxEnergy, chartEnergyLine)
+
+	last := history.At(n - 1)
+	printOverlay(screen, fmt.Sprintf("energy %.0f\nmomentum %.0f", last.KineticEnergy, last.Momentum()), float64(x+4), float64(y+2))
+	if count, violation := history.Violations(); count > 0 {
+		vector.StrokeRect(screen, x, y, w, h, 2, chartViolation, true)
+		printOverlay(screen, fmt.Sprintf("%d violations, %s", count, violation), float64(x+4), float64(y+h+2))
+	}
+}
+
+// drawPhysicsValidation draws the physics chart of the Game at the bottom of the screen, centered
+func (g *Game) drawPhysicsValidation(screen *ebiten.Image) {
+	if !g.widgetShowPhysics.Value {
+		return
+	}
+	// With the room of the line of the violations under the chart
+	w, h := scaled(chartExpandedWidth), scaled(chartHeight)
+	x, y := g.layout.Place(ui.AnchorBottom, w, h+text.LineHeight(overlayText.Size))
+	drawPhysicsChart(screen, g.physics, float32(x), float32(y), float32(w), float32(h))
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
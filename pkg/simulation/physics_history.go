@@ -0,0 +1,77 @@
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+)
+
+// PhysicsHistory keeps the kinetic energy and momentum of the last ticks in a ring buffer and
+// validates them with a replay.PhysicsValidator, to spot a force or an integration change that
+// makes the system explode before it becomes visible on screen
+type PhysicsHistory struct {
+	samples   []replay.Physics
+	next      int // where the next sample goes
+	full      bool
+	validator *replay.PhysicsValidator
+	// violations counts the violations since the last Reset, lastViolation is the most recent one
+	violations    int
+	lastViolation replay.PhysicsViolation
+}
+
+// NewPhysicsHistory keeps the last 'capacity' samples (DefaultHistoryTicks when <= 0)
+func NewPhysicsHistory(capacity int) *PhysicsHistory {
+	if capacity <= 0 {
+		capacity = DefaultHistoryTicks
+	}
+	return &PhysicsHistory{
+		samples:   make([]replay.Physics, capacity),
+		validator: replay.NewPhysicsValidator(replay.PhysicsLimits{}),
+	}
+}
+
+// Add records the physics of a snapshot and returns its violations of the default limits for
+// 'maxSpeed'. The Game skips snapshots when it is late: the energy is then compared with an older tick.
+func (h *PhysicsHistory) Add(snap *pb.WorldSnapshot, maxSpeed float64) []replay.PhysicsViolation {
+	p := replay.PhysicsOf(snap)
+	h.validator.Limits = replay.DefaultPhysicsLimits(maxSpeed)
+	violations := h.validator.Check(p)
+	if len(violations) > 0 {
+		h.violations += len(violations)
+		h.lastViolation = violations[len(violations)-1]
+	}
+	h.samples[h.next] = p
+	h.next++
+	if h.next == len(h.samples) {
+		h.next = 0
+		h.full = true
+	}
+	return violations
+}
+
+// Len returns the number of samples kept
+func (h *PhysicsHistory) Len() int {
+	if h.full {
+		return len(h.samples)
+	}
+	return h.next
+}
+
+// At returns the sample 'i', 0 being the oldest
+func (h *PhysicsHistory) At(i int) replay.Physics {
+	if h.full {
+		i = (h.next + i) % len(h.samples)
+	}
+	return h.samples[i]
+}
+
+// Violations returns the number of violations since the last Reset and the most recent one
+func (h *PhysicsHistory) Violations() (int, replay.PhysicsViolation) {
+	return h.violations, h.lastViolation
+}
+
+// Reset forgets every sample and violation
+func (h *PhysicsHistory) Reset() {
+	h.next, h.full = 0, false
+	h.violations, h.lastViolation = 0, replay.PhysicsViolation{}
+	h.validator.Reset()
+}
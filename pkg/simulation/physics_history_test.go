@@ -0,0 +1,30 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+)
+
+func TestPhysicsHistory(t *testing.T) {
+	h := NewPhysicsHistory(3)
+	for tick, speed := range []float64{2, 2, 2, 30} {
+		h.Add(&pb.WorldSnapshot{
+			Tick:   uint64(tick + 1),
+			Actors: []*pb.ActorState{{Id: "Red-000", Velocity: &pb.Vector{X: speed}}},
+		}, 4)
+	}
+	if h.Len() != 3 || h.At(0).Tick != 2 || h.At(2).KineticEnergy != 450 {
+		t.Fatalf("Unexpected samples %+v %+v", h.At(0), h.At(2))
+	}
+	n, last := h.Violations()
+	if n != 2 || last.Tick != 4 || last.Kind != replay.ViolationEnergyJump {
+		t.Errorf("Expected the speed and energy violations of tick 4, got %d %v", n, last)
+	}
+
+	h.Reset()
+	if n, _ := h.Violations(); h.Len() != 0 || n != 0 {
+		t.Errorf("Expected an empty history after Reset, got %d samples and %d violations", h.Len(), n)
+	}
+}
@@ -0,0 +1,135 @@
+package simulation
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// entityPool keeps the members removed from the world (despawned) so that the next spawn
+// recycles their ID, their Entity and their individual (actor or struct) instead of
+// creating new ones: the actor registry, the pidsCache and the per-ID maps of the
+// front-ends (trails, inspector...) stop growing with the number of deaths.
+type entityPool struct {
+	// parked entities, the engine keeps their individual idle until a Respawn
+	parked   []*Entity
+	parkedID map[string]bool
+	// next index used to name new individuals of each team (Red-000, Blue-000...)
+	nextIndex map[pb.TeamColor]int
+	created   uint64
+	reused    uint64
+}
+
+func newEntityPool() entityPool {
+	return entityPool{
+		parkedID:  make(map[string]bool),
+		nextIndex: make(map[pb.TeamColor]int),
+	}
+}
+
+// PoolStats describes the occupancy of the entity pool
+type PoolStats struct {
+	Live    int    // Entities currently in the world
+	Parked  int    // Despawned entities waiting to be recycled
+	Created uint64 // Individuals created since the world started
+	Reused  uint64 // Spawns served by recycling a parked entity
+}
+
+// Occupancy returns the fraction of the allocated individuals that are alive (1 when nothing is parked)
+func (s PoolStats) Occupancy() float64 {
+	total := s.Live + s.Parked
+	if total == 0 {
+		return 1
+	}
+	return float64(s.Live) / float64(total)
+}
+
+// poolStats returns the current occupancy of the pool
+func (w *world) poolStats() PoolStats {
+	return PoolStats{
+		Live:    len(w.order),
+		Parked:  len(w.pool.parked),
+		Created: w.pool.created,
+		Reused:  w.pool.reused,
+	}
+}
+
+// spawnEntity adds a member to the world, recycling a parked one when available, and returns it
+func (w *world) spawnEntity(color pb.TeamColor, pos, vel geometry.Vector2D) *Entity {
+	if n := len(w.pool.parked); n > 0 {
+		e := w.pool.parked[n-1]
+		w.pool.parked = w.pool.parked[:n-1]
+		delete(w.pool.parkedID, e.ID)
+		w.pool.reused++
+
+		e.Color, e.Pos, e.Vel = color, pos, vel
+		e.spawnTick = w.tick
+		e.lifeTick, e.meals, e.lifeFactor, e.attackTick = 0, 0, 0, 0
+		e.powerUps = [numPowerUps]uint64{}
+		w.addEntity(e)
+		if w.swarm.tell(e.ID, &pb.Respawn{State: e.ToProto(), Strategy: w.cfg.StrategyFor(color)}) {
+			w.msgSentCount++
+		}
+		w.publishEntityEvent(EventSpawn, e)
+		return e
+	}
+
+	prefix := "Blue"
+	switch color {
+	case pb.TeamColor_TEAM_RED:
+		prefix = "Red"
+	case NeutralColor:
+		prefix = "Neutral"
+	}
+	// Skip the names of the entities the world did not spawn itself (e.g. reported by a scripted swarm)
+	name := fmt.Sprintf("%s-%03d", prefix, w.pool.nextIndex[color])
+	w.pool.nextIndex[color]++
+	for w.entities[name] != nil || w.pool.parkedID[name] {
+		name = fmt.Sprintf("%s-%03d", prefix, w.pool.nextIndex[color])
+		w.pool.nextIndex[color]++
+	}
+	w.pool.created++
+
+	w.swarm.spawn(name, newIndividual(color, pos.X, pos.Y, vel.X, vel.Y, w.live, w.entityRand(name)))
+
+	// We must insert the actor into the map NOW, so the very first Tick loop
+	// sees it and sends it a message.
+	e := &Entity{ID: name, Color: color, Pos: pos, Vel: vel, spawnTick: w.tick}
+	w.addEntity(e)
+	w.publishEntityEvent(EventSpawn, e)
+	return e
+}
+
+// publishEntityEvent sends a spawn or death event of 'e' to the event sinks
+func (w *world) publishEntityEvent(kind EventKind, e *Entity) {
+	if w.events.enabled() {
+		w.events.publish(Event{Kind: kind, Tick: w.tick, ID: e.ID, Color: teamLabel(e.Color), X: e.Pos.X, Y: e.Pos.Y})
+	}
+}
+
+// despawn removes a member from the world and parks it in the pool, it returns false when 'id' is unknown.
+// The individual stays idle (it receives no more ticks) until it is recycled.
+func (w *world) despawn(id string) bool {
+	e, ok := w.entities[id]
+	if !ok {
+		return false
+	}
+	delete(w.entities, id)
+	if idx := slices.Index(w.order, e); idx >= 0 {
+		w.order = slices.Delete(w.order, idx, idx+1)
+	}
+	// States still in flight from this life will be ignored
+	e.Generation++
+	w.pool.parked = append(w.pool.parked, e)
+	w.pool.parkedID[id] = true
+	w.gridDirty = true
+	w.publishEntityEvent(EventDeath, e)
+	return true
+}
+
+// isParked tells whether 'id' belongs to a despawned entity
+func (w *world) isParked(id string) bool {
+	return w.pool.parkedID[id]
+}
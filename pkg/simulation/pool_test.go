@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestEntityPoolRecyclesIDs(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 2
+	cfg.NumBlueAtStart = 3
+	var stats []PoolStats
+	hook := func(view *WorldView, cmds *CommandQueue) {
+		switch view.Tick() {
+		case 1:
+			cmds.Despawn("Red-000")
+		case 2:
+			cmds.Spawn(pb.TeamColor_TEAM_BLUE, geometry.Vector2D{X: 10, Y: 20}, geometry.Vector2D{X: 1})
+		case 3:
+			cmds.Spawn(pb.TeamColor_TEAM_RED, geometry.Vector2D{X: 30, Y: 40}, geometry.Vector2D{X: 1})
+		}
+		stats = append(stats, view.Pool())
+	}
+	runner, err := NewRunner(ctx, cfg, WithEngine(NewLocalEngine), WithWorldOptions(WithTickHook(hook)))
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	defer runner.Stop(ctx)
+
+	snap, _ := runner.Step(ctx)
+	if len(snap.Actors) != 4 || findActor(snap, "Red-000") != nil {
+		t.Fatalf("Expected Red-000 to be despawned, got %d actors", len(snap.Actors))
+	}
+
+	// The next spawn recycles the parked ID, even for the other team
+	snap, _ = runner.Step(ctx)
+	recycled := findActor(snap, "Red-000")
+	if len(snap.Actors) != 5 || recycled == nil {
+		t.Fatalf("Expected Red-000 to be recycled, got %d actors", len(snap.Actors))
+	}
+	if recycled.Color != pb.TeamColor_TEAM_BLUE || recycled.Generation != 1 {
+		t.Errorf("Expected a blue Red-000 in generation 1, got %s in generation %d", recycled.Color, recycled.Generation)
+	}
+	state, err := runner.State(ctx, "Red-000")
+	if err != nil || state.Color != pb.TeamColor_TEAM_BLUE || state.Generation != 1 {
+		t.Errorf("Expected the individual to be respawned in blue, got %v (%v)", state, err)
+	}
+
+	// The pool is empty: a new individual is created with the next free name
+	snap, _ = runner.Step(ctx)
+	if findActor(snap, "Red-002") == nil {
+		t.Errorf("Expected a new Red-002 once the pool is empty")
+	}
+
+	want := []PoolStats{
+		{Live: 5, Parked: 0, Created: 5, Reused: 0}, // hooks run before the commands are applied
+		{Live: 4, Parked: 1, Created: 5, Reused: 0},
+		{Live: 5, Parked: 0, Created: 5, Reused: 1},
+	}
+	for i, w := range want {
+		if stats[i] != w {
+			t.Errorf("Tick %d: expected pool %+v, got %+v", i+1, w, stats[i])
+		}
+	}
+	if occ := (PoolStats{Live: 3, Parked: 1}).Occupancy(); occ != 0.75 {
+		t.Errorf("Expected an occupancy of 0.75, got %f", occ)
+	}
+}
+
+func TestWorldIgnoresStaleGenerations(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 1
+	cfg.NumBlueAtStart = 1
+	engine, err := NewLocalEngine(context.Background(), make(chan *pb.WorldSnapshot, 1), cfg)
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	w := engine.(*localEngine).world
+	stale := w.entities["Blue-000"].ToProto()
+
+	w.despawn("Blue-000")
+	// A late report of a parked entity must not bring it back
+	w.handle(stale)
+	if _, ok := w.entities["Blue-000"]; ok {
+		t.Fatalf("Expected a parked entity to stay out of the world")
+	}
+
+	e := w.spawnEntity(pb.TeamColor_TEAM_RED, geometry.Vector2D{X: 5, Y: 5}, geometry.Vector2D{X: 1})
+	w.handle(stale)
+	if e.ID != "Blue-000" || e.Pos.X != 5 || e.Color != pb.TeamColor_TEAM_RED {
+		t.Errorf("Expected the stale state to be ignored after the respawn, got %+v", e)
+	}
+}
+
+func findActor(snap *pb.WorldSnapshot, id string) *pb.ActorState {
+	for _, a := range snap.GetActors() {
+		if a.Id == id {
+			return a
+		}
+	}
+	return nil
+}
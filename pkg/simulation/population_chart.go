@@ -0,0 +1,95 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+// Size of the population chart, in the corner or expanded (key C)
+const (
+	chartWidth          = 200.0
+	chartHeight         = 80.0
+	chartExpandedWidth  = 600.0
+	chartExpandedHeight = 300.0
+)
+
+var (
+	chartBackground     = color.RGBA{R: 20, G: 20, B: 30, A: 200}
+	chartRedLine        = color.RGBA{R: 255, G: 50, B: 50, A: 255}
+	chartBlueLine       = color.RGBA{R: 50, G: 100, B: 255, A: 255}
+	chartConversionLine = color.RGBA{R: 255, G: 200, B: 0, A: 255}
+)
+
+// populationSeries are population samples, oldest first: a PopulationHistory or the timeline of RunStats
+type populationSeries interface {
+	Len() int
+	At(i int) PopulationSample
+}
+
+// drawPopulationChart draws the red and blue populations of 'history' as two lines in the
+// rectangle (x, y, w, h), the conversions per second are drawn with their own scale when 'conversions' is set
+func drawPopulationChart(screen *ebiten.Image, history populationSeries, x, y, w, h float32, conversions bool) {
+	vector.FillRect(screen, x, y, w, h, chartBackground, true)
+	n := history.Len()
+	if n < 2 {
+		return
+	}
+
+	var maxCount int32 = 1
+	maxRate := 1.0
+	for i := 0; i < n; i++ {
+		s := history.At(i)
+		maxCount = max(maxCount, s.Red, s.Blue)
+		maxRate = max(maxRate, s.ConversionRate)
+	}
+
+	// One pixel column per sample at most, the samples in between are skipped
+	step := max(1, n/int(w))
+	px := func(i int) float32 { return x + w*float32(i)/float32(n-1) }
+	py := func(v, top float64) float32 { return y + h - h*float32(v/top) }
+	line := func(value func(PopulationSample) float64, top float64, clr color.RGBA) {
+		prev := history.At(0)
+		prevIdx := 0
+		for i := step; i < n; i += step {
+			cur := history.At(i)
+			vector.StrokeLine(screen, px(prevIdx), py(value(prev), top), px(i), py(value(cur), top), 1, clr, true)
+			prev, prevIdx = cur, i
+		}
+	}
+	if conversions {
+		line(func(s PopulationSample) float64 { return s.ConversionRate }, maxRate, chartConversionLine)
+	}
+	line(func(s PopulationSample) float64 { return float64(s.Red) }, float64(maxCount), chartRedLine)
+	line(func(s PopulationSample) float64 { return float64(s.Blue) }, float64(maxCount), chartBlueLine)
+
+	last := history.At(n - 1)
+	msg := fmt.Sprintf("max %d", maxCount)
+	if conversions {
+		msg += fmt.Sprintf("\nconv/s %.1f", last.ConversionRate)
+	}
+	printOverlay(screen, msg, float64(x+4), float64(y+2))
+}
+
+// drawPopulationCharts draws the chart of the Game in the bottom right corner, or the expanded stats view at the center
+func (g *Game) drawPopulationCharts(screen *ebiten.Image) {
+	if g.chartExpanded {
+		w, h := scaled(chartExpandedWidth), scaled(chartExpandedHeight)
+		x, y := g.layout.Place(ui.AnchorCenter, w, h)
+		drawPopulationChart(screen, g.history, float32(x), float32(y), float32(w), float32(h), true)
+		if last, ok := g.history.Last(); ok {
+			printOverlay(screen, fmt.Sprintf("tick %d  red %d  blue %d  (C to close)", last.Tick, last.Red, last.Blue),
+				x+4, y+h+4)
+		}
+		return
+	}
+	if !g.widgetShowChart.Value {
+		return
+	}
+	w, h := scaled(chartWidth), scaled(chartHeight)
+	x, y := g.layout.Place(ui.AnchorBottomRight, w, h)
+	drawPopulationChart(screen, g.history, float32(x), float32(y), float32(w), float32(h), false)
+}
@@ -0,0 +1,93 @@
+package simulation
+
+import "github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+
+// DefaultHistoryTicks is the length of the population history kept by the Game (one minute at 60 TPS)
+const DefaultHistoryTicks = 60 * SimTicksPerSecond
+
+// PopulationSample is the population of the world after one tick
+type PopulationSample struct {
+	Tick uint64 `json:"tick"`
+	Red  int32  `json:"red"`
+	Blue int32  `json:"blue"`
+	// ConversionRate is the number of conversions during the last second of simulation time
+	ConversionRate float64 `json:"conversionRate"`
+}
+
+// PopulationHistory keeps the last samples of the population in a ring buffer
+type PopulationHistory struct {
+	samples []PopulationSample
+	next    int // where the next sample goes
+	full    bool
+	// conversions of the last SimTicksPerSecond ticks, indexed by tick modulo SimTicksPerSecond
+	window [SimTicksPerSecond]tickConversions
+}
+
+type tickConversions struct {
+	tick        uint64
+	conversions uint32
+}
+
+// NewPopulationHistory keeps the last 'capacity' samples (DefaultHistoryTicks when <= 0)
+func NewPopulationHistory(capacity int) *PopulationHistory {
+	if capacity <= 0 {
+		capacity = DefaultHistoryTicks
+	}
+	return &PopulationHistory{samples: make([]PopulationSample, capacity)}
+}
+
+// Add records the population of a snapshot, the oldest sample is dropped once the buffer is full
+func (h *PopulationHistory) Add(snap *pb.WorldSnapshot) {
+	h.window[snap.Tick%SimTicksPerSecond] = tickConversions{tick: snap.Tick, conversions: snap.Conversions}
+	// Snapshots can be skipped (the UI only keeps the latest one), slots older than one second are ignored
+	var sum uint32
+	for _, c := range h.window {
+		if c.tick <= snap.Tick && snap.Tick-c.tick < SimTicksPerSecond {
+			sum += c.conversions
+		}
+	}
+	// During the first second the rate is extrapolated from the ticks already simulated
+	elapsed := max(1, min(snap.Tick, SimTicksPerSecond))
+
+	h.samples[h.next] = PopulationSample{
+		Tick:           snap.Tick,
+		Red:            snap.RedCount,
+		Blue:           snap.BlueCount,
+		ConversionRate: float64(sum) * SimTicksPerSecond / float64(elapsed),
+	}
+	h.next++
+	if h.next == len(h.samples) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// Len returns the number of samples kept
+func (h *PopulationHistory) Len() int {
+	if h.full {
+		return len(h.samples)
+	}
+	return h.next
+}
+
+// At returns the sample 'i', 0 being the oldest
+func (h *PopulationHistory) At(i int) PopulationSample {
+	if h.full {
+		i = (h.next + i) % len(h.samples)
+	}
+	return h.samples[i]
+}
+
+// Last returns the most recent sample, ok is false when the history is empty
+func (h *PopulationHistory) Last() (PopulationSample, bool) {
+	if h.Len() == 0 {
+		return PopulationSample{}, false
+	}
+	return h.At(h.Len() - 1), true
+}
+
+// Reset forgets every sample
+func (h *PopulationHistory) Reset() {
+	h.next, h.full = 0, false
+	h.window = [SimTicksPerSecond]tickConversions{}
+}
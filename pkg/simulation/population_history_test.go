@@ -0,0 +1,48 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestPopulationHistory(t *testing.T) {
+	h := NewPopulationHistory(3)
+	if _, ok := h.Last(); ok {
+		t.Fatal("Expected an empty history")
+	}
+	for tick := uint64(1); tick <= 5; tick++ {
+		h.Add(&pb.WorldSnapshot{Tick: tick, RedCount: int32(tick), BlueCount: int32(10 - tick)})
+	}
+	if h.Len() != 3 {
+		t.Fatalf("Expected 3 samples, got %d", h.Len())
+	}
+	for i, want := range []uint64{3, 4, 5} {
+		if s := h.At(i); s.Tick != want || s.Red != int32(want) || s.Blue != int32(10-want) {
+			t.Errorf("At(%d) = %+v, expected tick %d", i, s, want)
+		}
+	}
+
+	h.Reset()
+	if h.Len() != 0 {
+		t.Errorf("Expected an empty history after Reset, got %d samples", h.Len())
+	}
+}
+
+func TestPopulationHistory_conversionRate(t *testing.T) {
+	h := NewPopulationHistory(0)
+	// One conversion every tick for two seconds
+	for tick := uint64(1); tick <= 2*SimTicksPerSecond; tick++ {
+		h.Add(&pb.WorldSnapshot{Tick: tick, Conversions: 1})
+	}
+	if last, _ := h.Last(); math.Abs(last.ConversionRate-SimTicksPerSecond) > 1e-9 {
+		t.Errorf("Expected %d conversions/s, got %v", SimTicksPerSecond, last.ConversionRate)
+	}
+
+	// Skipped snapshots older than one second no longer count
+	h.Add(&pb.WorldSnapshot{Tick: 4 * SimTicksPerSecond, Conversions: 3})
+	if last, _ := h.Last(); math.Abs(last.ConversionRate-3) > 1e-9 {
+		t.Errorf("Expected 3 conversions/s, got %v", last.ConversionRate)
+	}
+}
@@ -0,0 +1,230 @@
+package simulation
+
+import (
+	"image/color"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// defaultPowerUpMax is the largest number of pickups in the world when Config.PowerUpMax is 0
+	defaultPowerUpMax = 6
+	// defaultPowerUpDuration is the number of ticks an effect lasts when Config.PowerUpDuration is 0
+	defaultPowerUpDuration = 300
+	// pickupReach is the distance under which an entity collects a pickup
+	pickupReach = 10.0
+	// speedBoost multiplies MaxSpeed for an entity under PowerUpSpeed
+	speedBoost = 1.5
+	// defenseBoost multiplies DefenseRadius for a blue under PowerUpDefense
+	defenseBoost = 1.5
+)
+
+// PowerUp is the effect of a pickup
+type PowerUp uint8
+
+// Effects of the pickups of Config.PowerUpRate
+const (
+	PowerUpSpeed    PowerUp = iota // The entity flies speedBoost times faster
+	PowerUpImmunity                // The fights neither convert nor kill the entity
+	PowerUpDefense                 // A blue defends the victims defenseBoost times farther
+	numPowerUps
+)
+
+var powerUpNames = [numPowerUps]string{"speed", "immunity", "defense"}
+
+func (p PowerUp) String() string {
+	if p < numPowerUps {
+		return powerUpNames[p]
+	}
+	return "unknown"
+}
+
+// PowerUpSet is the set of the effects active on an entity
+type PowerUpSet uint8
+
+// Has reports whether 'p' is in the set
+func (s PowerUpSet) Has(p PowerUp) bool {
+	return s&(1<<p) != 0
+}
+
+// Pickup is a power-up lying in the world, waiting for an entity to collect it
+type Pickup struct {
+	Kind PowerUp
+	Pos  geometry.Vector2D
+}
+
+// powerUpState is the power-ups of the world at a tick, published to the individuals: the effects
+// active on every entity having one
+type powerUpState struct {
+	active map[string]PowerUpSet
+}
+
+// of returns the effects active on the entity 'id'
+func (s *powerUpState) of(id string) PowerUpSet {
+	if s == nil {
+		return 0
+	}
+	return s.active[id]
+}
+
+// PowerUps links the world to whoever shows its pickups and the effects of the entities, e.g. the
+// Game. Give it to the world with WithPowerUps. A PowerUps is safe for concurrent use.
+type PowerUps struct {
+	pickups atomic.Pointer[[]Pickup]
+	state   atomic.Pointer[powerUpState]
+}
+
+// Pickups returns the pickups lying in the world, nil without Config.PowerUpRate. It must not be modified.
+func (p *PowerUps) Pickups() []Pickup {
+	if p == nil {
+		return nil
+	}
+	if pickups := p.pickups.Load(); pickups != nil {
+		return *pickups
+	}
+	return nil
+}
+
+// Active returns the effects active on the entity 'id'
+func (p *PowerUps) Active(id string) PowerUpSet {
+	if p == nil {
+		return 0
+	}
+	return p.state.Load().of(id)
+}
+
+// WithPowerUps makes the world publish its pickups and the effects of its entities to 'p' at every tick
+func WithPowerUps(p *PowerUps) WorldOption {
+	return func(w *world) {
+		w.powerUps.control = p
+	}
+}
+
+// powerUpTracker keeps the pickups of the world from a tick to the next
+type powerUpTracker struct {
+	control *PowerUps
+	pickups []Pickup
+	// due is the number of pickups to spawn, the fraction of a pickup left by PowerUpRate carries over
+	due float64
+	// rng places the pickups and draws their effect, a stream of the world seed (see entityRand)
+	rng *rand.Rand
+}
+
+// updatePowerUps spawns PowerUpRate pickups per tick, up to PowerUpMax, then lets every red or blue
+// within pickupReach of a pickup collect it for PowerUpDuration ticks, and publishes the effects
+func (w *world) updatePowerUps() {
+	t := &w.powerUps
+	if w.cfg.PowerUpRate == 0 {
+		if w.live.powerUps.Load() != nil {
+			t.pickups, t.due = nil, 0
+			for _, e := range w.order {
+				e.powerUps = [numPowerUps]uint64{}
+			}
+			w.live.powerUps.Store(nil)
+			if t.control != nil {
+				t.control.pickups.Store(nil)
+				t.control.state.Store(nil)
+			}
+		}
+		return
+	}
+	if t.rng == nil {
+		t.rng = w.entityRand("powerups")
+	}
+	limit := w.cfg.PowerUpMax
+	if limit == 0 {
+		limit = defaultPowerUpMax
+	}
+	t.due += w.cfg.PowerUpRate
+	for ; t.due >= 1; t.due-- {
+		if len(t.pickups) >= limit {
+			t.due = 0
+			break
+		}
+		if pos, ok := w.randomSpot(t.rng); ok {
+			t.pickups = append(t.pickups, Pickup{Kind: PowerUp(t.rng.IntN(int(numPowerUps))), Pos: pos})
+		}
+	}
+
+	duration := uint64(w.cfg.PowerUpDuration)
+	if duration == 0 {
+		duration = defaultPowerUpDuration
+	}
+	state := &powerUpState{active: make(map[string]PowerUpSet)}
+	for _, e := range w.order {
+		if e.Color == NeutralColor {
+			continue
+		}
+		t.pickups = slices.DeleteFunc(t.pickups, func(p Pickup) bool {
+			if e.Pos.DistanceSquaredTo(p.Pos) > pickupReach*pickupReach {
+				return false
+			}
+			e.powerUps[p.Kind] = w.tick + duration
+			return true
+		})
+		if set := e.activePowerUps(w.tick); set != 0 {
+			state.active[e.ID] = set
+		}
+	}
+	w.live.powerUps.Store(state)
+	if t.control != nil {
+		pickups := slices.Clone(t.pickups)
+		t.control.pickups.Store(&pickups)
+		t.control.state.Store(state)
+	}
+}
+
+// activePowerUps returns the effects of 'e' that did not expire at 'tick'
+func (e *Entity) activePowerUps(tick uint64) PowerUpSet {
+	var set PowerUpSet
+	for p, until := range e.powerUps {
+		if tick < until {
+			set |= 1 << p
+		}
+	}
+	return set
+}
+
+// immune reports whether the fights can neither convert nor kill 'e' (see PowerUpImmunity)
+func (w *world) immune(e *Entity) bool {
+	return w.cfg.PowerUpRate > 0 && e.activePowerUps(w.tick).Has(PowerUpImmunity)
+}
+
+// defenseReach returns the radius within which the blue 'e' defends a victim (see PowerUpDefense)
+func (w *world) defenseReach(e *Entity) float64 {
+	if w.cfg.PowerUpRate > 0 && e.activePowerUps(w.tick).Has(PowerUpDefense) {
+		return w.defenseRadius * defenseBoost
+	}
+	return w.defenseRadius
+}
+
+// powerUpColors are the colors of the pickups and of the rings around the entities under their effect
+var powerUpColors = [numPowerUps]color.RGBA{
+	PowerUpSpeed:    {R: 255, G: 220, B: 0, A: 255},
+	PowerUpImmunity: {R: 255, G: 255, B: 255, A: 255},
+	PowerUpDefense:  {R: 0, G: 255, B: 160, A: 255},
+}
+
+// drawPickups draws a sprite for every pickup, tinted with the color of its effect
+func drawPickups(r Renderer, pickups []Pickup) {
+	for _, p := range pickups {
+		clr := powerUpColors[p.Kind]
+		tint := [4]float32{float32(clr.R) / 255, float32(clr.G) / 255, float32(clr.B) / 255, 1}
+		r.DrawSprite(SpritePickup, p.Pos.X, p.Pos.Y, 0, 1, tint)
+	}
+}
+
+// drawPowerUps rings 'e' once per active effect, with the color of the effect
+func drawPowerUps(r Renderer, e *pb.ActorState, set PowerUpSet) {
+	radius := float32(10)
+	for p := range numPowerUps {
+		if set.Has(p) {
+			r.StrokeCircle(float32(e.Position.X), float32(e.Position.Y), radius, 1.5, powerUpColors[p])
+			radius += 3
+		}
+	}
+}
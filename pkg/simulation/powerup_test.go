@@ -0,0 +1,126 @@
+package simulation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestConfigPowerUps(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PowerUpRate = 0.01
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.PowerUpRate = -1 },
+		func(c *Config) { c.PowerUpMax = -1 },
+		func(c *Config) { c.PowerUpDuration = -1 },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestWorld_updatePowerUps(t *testing.T) {
+	cfg := &Config{WorldWidth: 400, WorldHeight: 400, DetectionRadius: 100, PowerUpRate: 0.5, PowerUpMax: 3, PowerUpDuration: 5, Seed: 7}
+	control := &PowerUps{}
+	w := newWorld(nil, cfg, WithPowerUps(control))
+	cfg = w.cfg
+	for range 10 {
+		w.tick++
+		w.updatePowerUps()
+	}
+	pickups := control.Pickups()
+	if len(pickups) != 3 {
+		t.Fatalf("Expected PowerUpMax pickups, got %d", len(pickups))
+	}
+
+	// The entities touching a pickup collect its effect, the civilians do not
+	w.addEntity(&Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED, Pos: pickups[0].Pos})
+	w.addEntity(&Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Pos: pickups[1].Pos.Add(geometry.Vector2D{X: 5})})
+	w.addEntity(&Entity{ID: "Neutral-000", Color: NeutralColor, Pos: pickups[2].Pos})
+	cfg.PowerUpRate = 0.01
+	w.tick++
+	w.updatePowerUps()
+	if got := control.Active("Red-000"); !got.Has(pickups[0].Kind) {
+		t.Errorf("Expected the red under %v, got %b", pickups[0].Kind, got)
+	}
+	if got := control.Active("Blue-000"); !got.Has(pickups[1].Kind) {
+		t.Errorf("Expected the blue under %v, got %b", pickups[1].Kind, got)
+	}
+	if got := control.Active("Neutral-000"); got != 0 || !reflect.DeepEqual(control.Pickups(), pickups[2:]) {
+		t.Errorf("Expected the civilian to leave its pickup, got %b and %v", got, control.Pickups())
+	}
+
+	// The effects expire after PowerUpDuration ticks
+	for range 5 {
+		w.tick++
+		w.updatePowerUps()
+	}
+	if got := control.Active("Red-000"); got != 0 {
+		t.Errorf("Expected the effect to expire, got %b", got)
+	}
+
+	cfg.PowerUpRate = 0
+	w.updatePowerUps()
+	if w.live.powerUps.Load() != nil || control.Pickups() != nil {
+		t.Error("Expected no power-up without PowerUpRate")
+	}
+}
+
+func TestPowerUps_combat(t *testing.T) {
+	cfg := combatConfig()
+	cfg.PowerUpRate = 0.001
+	tests := []struct {
+		name    string
+		powerUp PowerUp
+		id      string
+		states  []*pb.ActorState
+		want    []convertOrder
+	}{
+		{
+			name:    "an immune victim is not converted",
+			powerUp: PowerUpImmunity,
+			id:      "Blue-000",
+			states:  []*pb.ActorState{redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100)},
+			want:    nil,
+		},
+		{
+			name:    "a blue under the defense power-up defends farther",
+			powerUp: PowerUpDefense,
+			id:      "Blue-003",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100),
+				blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 160, 100),
+			},
+			want: []convertOrder{"Red-000->TEAM_BLUE"},
+		},
+		{
+			name:    "a blue without it does not",
+			powerUp: PowerUpSpeed,
+			id:      "Blue-003",
+			states: []*pb.ActorState{
+				redAt("Red-000", 100, 100), blueAt("Blue-000", 105, 100),
+				blueAt("Blue-001", 120, 100), blueAt("Blue-002", 105, 130), blueAt("Blue-003", 160, 100),
+			},
+			want: []convertOrder{"Blue-000->TEAM_RED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, s := newScriptedWorld(cfg)
+			s.report(tt.states...)
+			w.entities[tt.id].powerUps[tt.powerUp] = 1000
+			if got := s.tick(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Converts = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
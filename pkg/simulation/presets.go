@@ -0,0 +1,174 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PresetsDir is the directory where the UI saves and loads its presets
+const PresetsDir = "presets"
+
+// Preset is a named set of the live parameters exposed in the UI panel.
+// It uses the field names of config.json, world size, logging and seed are not part of it.
+type Preset struct {
+	Name string `json:"name"`
+
+	DetectionRadius float64 `json:"detectionRadius"`
+	DefenseRadius   float64 `json:"defenseRadius"`
+	ContactRadius   float64 `json:"contactRadius"`
+	VisualRange     float64 `json:"visualRange"`
+	ProtectedRange  float64 `json:"protectedRange"`
+
+	MaxSpeed   float64 `json:"maxSpeed"`
+	MinSpeed   float64 `json:"minSpeed"`
+	Aggression float64 `json:"aggression"`
+
+	CenteringFactor float64 `json:"centeringFactor"`
+	AvoidFactor     float64 `json:"avoidFactor"`
+	MatchingFactor  float64 `json:"matchingFactor"`
+	TurnFactor      float64 `json:"turnFactor"`
+
+	RedStrategy  string `json:"redStrategy,omitempty"`
+	BlueStrategy string `json:"blueStrategy,omitempty"`
+
+	NumRedAtStart  int `json:"numRedAtStart"`
+	NumBlueAtStart int `json:"numBlueAtStart"`
+
+	DisplayDetectionCircle bool `json:"displayDetectionCircle"`
+	DisplayDefenseCircle   bool `json:"displayDefenseCircle"`
+}
+
+// NewPreset captures the live parameters of 'cfg' under 'name'
+func NewPreset(name string, cfg *Config) Preset {
+	return Preset{
+		Name:                   name,
+		DetectionRadius:        cfg.DetectionRadius,
+		DefenseRadius:          cfg.DefenseRadius,
+		ContactRadius:          cfg.ContactRadius,
+		VisualRange:            cfg.VisualRange,
+		ProtectedRange:         cfg.ProtectedRange,
+		MaxSpeed:               cfg.MaxSpeed,
+		MinSpeed:               cfg.MinSpeed,
+		Aggression:             cfg.Aggression,
+		CenteringFactor:        cfg.CenteringFactor,
+		AvoidFactor:            cfg.AvoidFactor,
+		MatchingFactor:         cfg.MatchingFactor,
+		TurnFactor:             cfg.TurnFactor,
+		RedStrategy:            cfg.RedStrategy,
+		BlueStrategy:           cfg.BlueStrategy,
+		NumRedAtStart:          cfg.NumRedAtStart,
+		NumBlueAtStart:         cfg.NumBlueAtStart,
+		DisplayDetectionCircle: cfg.DisplayDetectionCircle,
+		DisplayDefenseCircle:   cfg.DisplayDefenseCircle,
+	}
+}
+
+// Apply copies the preset into 'cfg' and validates the result, 'cfg' is unchanged on error
+func (p Preset) Apply(cfg *Config) error {
+	next := *cfg
+	next.DetectionRadius = p.DetectionRadius
+	next.DefenseRadius = p.DefenseRadius
+	next.ContactRadius = p.ContactRadius
+	next.VisualRange = p.VisualRange
+	next.ProtectedRange = p.ProtectedRange
+	next.MaxSpeed = p.MaxSpeed
+	next.MinSpeed = p.MinSpeed
+	next.Aggression = p.Aggression
+	next.CenteringFactor = p.CenteringFactor
+	next.AvoidFactor = p.AvoidFactor
+	next.MatchingFactor = p.MatchingFactor
+	next.TurnFactor = p.TurnFactor
+	next.RedStrategy = p.RedStrategy
+	next.BlueStrategy = p.BlueStrategy
+	next.NumRedAtStart = p.NumRedAtStart
+	next.NumBlueAtStart = p.NumBlueAtStart
+	next.DisplayDetectionCircle = p.DisplayDetectionCircle
+	next.DisplayDefenseCircle = p.DisplayDefenseCircle
+	if err := next.Validate(); err != nil {
+		return fmt.Errorf("invalid preset %q: %w", p.Name, err)
+	}
+	*cfg = next
+	return nil
+}
+
+// PresetPath returns the file of the preset 'name' in 'dir'
+func PresetPath(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// SavePreset writes the preset as indented JSON in 'dir' (created if needed) and returns the file path
+func SavePreset(dir string, p Preset) (string, error) {
+	if !validFileName(p.Name) {
+		return "", fmt.Errorf("invalid preset name %q", p.Name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("cannot create presets directory: %w", err)
+	}
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := PresetPath(dir, p.Name)
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("cannot write preset: %w", err)
+	}
+	return path, nil
+}
+
+// validFileName reports whether 'name' can be used as a file name in the presets or macros directory
+func validFileName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// LoadPreset reads the preset 'name' from 'dir'
+func LoadPreset(dir, name string) (Preset, error) {
+	b, err := os.ReadFile(PresetPath(dir, name))
+	if err != nil {
+		return Preset{}, fmt.Errorf("cannot read preset: %w", err)
+	}
+	var p Preset
+	if err := json.Unmarshal(b, &p); err != nil {
+		return Preset{}, fmt.Errorf("cannot decode preset %q: %w", name, err)
+	}
+	// The file name wins, presets can be renamed by renaming the file
+	p.Name = name
+	return p, nil
+}
+
+// ListPresets returns the sorted names of the presets saved in 'dir' (none when it does not exist)
+func ListPresets(dir string) ([]string, error) {
+	return listJSONFiles(dir)
+}
+
+// listJSONFiles returns the sorted names of the .json files of 'dir', without extension
+func listJSONFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// NextPresetName returns the first free "preset-NNN" name in 'dir'
+func NextPresetName(dir string) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("preset-%03d", i)
+		if _, err := os.Stat(PresetPath(dir, name)); os.IsNotExist(err) {
+			return name
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package simulation
+
+import "testing"
+
+func TestPresetRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if names, err := ListPresets(dir + "/missing"); err != nil || len(names) != 0 {
+		t.Fatalf("Expected no preset in a missing directory, got %v (%v)", names, err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxSpeed = 6.5
+	cfg.RedStrategy = StrategyPackHunter
+	cfg.DisplayDefenseCircle = true
+	name := NextPresetName(dir)
+	if name != "preset-001" {
+		t.Errorf("Expected preset-001, got %s", name)
+	}
+	if _, err := SavePreset(dir, NewPreset(name, cfg)); err != nil {
+		t.Fatalf("SavePreset failed: %v", err)
+	}
+	if next := NextPresetName(dir); next != "preset-002" {
+		t.Errorf("Expected preset-002 once preset-001 exists, got %s", next)
+	}
+
+	names, err := ListPresets(dir)
+	if err != nil || len(names) != 1 || names[0] != name {
+		t.Fatalf("Expected [%s], got %v (%v)", name, names, err)
+	}
+	preset, err := LoadPreset(dir, name)
+	if err != nil {
+		t.Fatalf("LoadPreset failed: %v", err)
+	}
+
+	// World size and seed are not part of a preset
+	target := DefaultConfig()
+	target.WorldWidth = 640
+	target.Seed = 9
+	if err := preset.Apply(target); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if target.MaxSpeed != 6.5 || target.RedStrategy != StrategyPackHunter || !target.DisplayDefenseCircle {
+		t.Errorf("Preset not applied: %+v", target)
+	}
+	if target.WorldWidth != 640 || target.Seed != 9 {
+		t.Errorf("Expected world size and seed to be kept, got %+v", target)
+	}
+}
+
+func TestPresetErrors(t *testing.T) {
+	if _, err := SavePreset(t.TempDir(), Preset{Name: "../escape"}); err == nil {
+		t.Errorf("Expected a name with a path to be rejected")
+	}
+
+	// An invalid preset leaves the config untouched
+	bad := NewPreset("bad", DefaultConfig())
+	bad.MinSpeed = bad.MaxSpeed + 1
+	cfg := DefaultConfig()
+	if err := bad.Apply(cfg); err == nil {
+		t.Errorf("Expected minSpeed >= maxSpeed to be rejected")
+	}
+	if cfg.MinSpeed != DefaultConfig().MinSpeed {
+		t.Errorf("Expected the config to be unchanged, got minSpeed %f", cfg.MinSpeed)
+	}
+}
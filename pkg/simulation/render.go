@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// drawTrails selects the vector trails (slow) instead of the batched trail sprites
+const drawTrails = false
+
+// Sprite identifies one of the pre-rendered images of the world layer
+type Sprite uint8
+
+const (
+	SpriteRedShip  Sprite = iota // Red saucer, facing up
+	SpriteBlueShip               // Blue jet, facing up
+	SpriteTrail                  // White soft puff, tinted by the caller
+	SpriteFood                   // White berry with a leaf, tinted by the caller
+	SpriteCivilian               // Gray pod of the civilians, facing up
+	SpritePickup                 // White gem of the power-ups, tinted by the caller
+)
+
+// Renderer receives the draw list of the world layer, in drawing order.
+// The Game draws it with Ebiten, a HashRenderer only fingerprints it.
+type Renderer interface {
+	// DrawSprite draws 'sprite' centered on (x, y), rotated by 'angle' radians,
+	// scaled by 'scale' and tinted by multiplying its RGBA components by 'tint'
+	DrawSprite(sprite Sprite, x, y, angle, scale float64, tint [4]float32)
+	FillCircle(x, y, radius float32, clr color.RGBA)
+	StrokeCircle(x, y, radius, width float32, clr color.RGBA)
+	StrokeLine(x0, y0, x1, y1, width float32, clr color.RGBA)
+}
+
+// noTint leaves the sprite colors unchanged
+var noTint = [4]float32{1, 1, 1, 1}
+
+// WorldDrawOptions are the display settings of the world layer
+type WorldDrawOptions struct {
+	ShowDetection   bool
+	DetectionRadius float64
+	ShowDefense     bool
+	DefenseRadius   float64
+	// FlowField, when set, is updated with the snapshot and drawn below the entities
+	FlowField *FlowField
+	// Obstacles are drawn below the entities
+	Obstacles []behavior.Obstacle
+	// Terrain, when set, is drawn below everything else
+	Terrain *Terrain
+	// NavField, when set, is drawn as arrows below the entities
+	NavField *behavior.NavField
+	// Leaders are the IDs of the entities ringed as leaders, LeaderPath the loop they follow
+	Leaders    []string
+	LeaderPath []geometry.Vector2D
+	// Danger and Scent, when set, are the pheromone fields drawn as a heatmap below the entities
+	Danger, Scent *behavior.Pheromone
+	// Food are the food items, drawn below the entities
+	Food []geometry.Vector2D
+	// PowerUps, when set, has the pickups drawn below the entities and the effects ringing them
+	PowerUps *PowerUps
+	// Attractors are drawn below the entities, ringed by their reach
+	Attractors []Attractor
+}
+
+// obstacleColor fills the obstacles
+var obstacleColor = color.RGBA{R: 90, G: 90, B: 100, A: 255}
+
+// DrawWorld emits the draw list of a snapshot: for every entity its trail, its radius circle and its sprite
+func DrawWorld(r Renderer, snap *pb.WorldSnapshot, trails *Trails, opts WorldDrawOptions) {
+	if snap == nil {
+		return
+	}
+	if opts.Terrain != nil {
+		drawTerrain(r, opts.Terrain)
+	}
+	drawPheromones(r, opts.Danger, opts.Scent)
+	if opts.FlowField != nil {
+		opts.FlowField.Update(snap)
+		drawFlowField(r, opts.FlowField)
+	}
+	if opts.NavField != nil {
+		drawNavField(r, opts.NavField)
+	}
+	drawFood(r, opts.Food)
+	drawPickups(r, opts.PowerUps.Pickups())
+	drawAttractors(r, opts.Attractors)
+	for _, o := range opts.Obstacles {
+		r.FillCircle(float32(o.Center.X), float32(o.Center.Y), float32(o.Radius), obstacleColor)
+	}
+	for _, entity := range snap.Actors {
+		// Rotate to match velocity
+		// Note: The sprites are drawn facing "Up", so we add math.Pi/2 (90 deg)
+		// to align the top of the sprite with the movement vector.
+		angle := math.Atan2(entity.Velocity.Y, entity.Velocity.X) + math.Pi/2
+
+		switch entity.Color {
+		case NeutralColor:
+			// --- CIVILIANS (The Gray Pods), of no team: no trail nor radius ---
+			r.DrawSprite(SpriteCivilian, entity.Position.X, entity.Position.Y, angle, 1, noTint)
+		case pb.TeamColor_TEAM_RED:
+			drawTrail(r, trails.Trail(entity.Id))
+
+			// --- 2. Existing Detection Circle (Keep this) ---
+			if opts.ShowDetection {
+				r.StrokeCircle(float32(entity.Position.X), float32(entity.Position.Y), float32(opts.DetectionRadius), 1,
+					color.RGBA{R: 255, G: 50, B: 50, A: 255})
+			}
+			r.DrawSprite(SpriteRedShip, entity.Position.X, entity.Position.Y, angle, 1, noTint)
+		default:
+			// --- BLUE BOIDS (The Arrow Jets) ---
+			// Optional: Draw Defense Radius ring
+			if opts.ShowDefense {
+				r.StrokeCircle(float32(entity.Position.X), float32(entity.Position.Y), float32(opts.DefenseRadius), 1,
+					color.RGBA{R: 50, G: 100, B: 255, A: 50})
+			}
+			r.DrawSprite(SpriteBlueShip, entity.Position.X, entity.Position.Y, angle, 1, noTint)
+		}
+		if set := opts.PowerUps.Active(entity.Id); set != 0 {
+			drawPowerUps(r, entity, set)
+		}
+	}
+	drawLeaders(r, snap, opts.Leaders, opts.LeaderPath)
+}
+
+// drawTrail draws the glowing trail of a red entity, from the tail to the engine
+func drawTrail(r Renderer, trace []geometry.Vector2D) {
+	if len(trace) <= 1 {
+		return
+	}
+	for i, pos := range trace {
+		// Progress: 0.0 (Tail) -> 1.0 (Engine)
+		p := float64(i) / float64(len(trace))
+
+		if drawTrails {
+			// Size varies: 0 at tail, 6 at engine
+			radius := float32(3.0 * p)
+
+			// Color Logic: Fire Gradient
+			// Tail is Red/Transparent, Head is Yellow/White
+			var cr, cg, cb, ca uint8
+			if p > 0.8 {
+				// Core (White/Yellow)
+				cr, cg, cb, ca = 255, 255, 100, 200
+			} else if p > 0.5 {
+				// Middle (Orange)
+				cr, cg, cb, ca = 255, 140, 0, 150
+			} else {
+				// Tail (Red fading out)
+				cr, cg, cb, ca = 255, 0, 0, uint8(100*p)
+			}
+
+			// Draw the puff
+			r.FillCircle(float32(pos.X), float32(pos.Y), radius, color.RGBA{R: cr, G: cg, B: cb, A: ca})
+			continue
+		}
+
+		// --- OPTIMIZED: Sprite Batching ---
+		// Skip the very tail if it's too faint
+		if p < 0.2 {
+			continue
+		}
+		// Start small (0.5), grow to 1.5 at the engine
+		scale := 0.5 + p
+
+		// Color Logic (Fire Gradient): the white sprite is tinted,
+		// high alpha at head, fading to 0 at tail.
+		alpha := float32(p * 0.8) // Max opacity 0.8
+		var tint [4]float32
+		if p > 0.8 {
+			// White/Yellow Core
+			tint = [4]float32{1, 1, 0.5, alpha}
+		} else if p > 0.5 {
+			// Orange Body
+			tint = [4]float32{1, 0.5, 0, alpha}
+		} else {
+			// Red/Smoke Tail
+			tint = [4]float32{0.8, 0, 0, alpha}
+		}
+		r.DrawSprite(SpriteTrail, pos.X, pos.Y, 0, scale, tint)
+	}
+}
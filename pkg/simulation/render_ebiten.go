@@ -0,0 +1,120 @@
+package simulation
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui/text"
+)
+
+// ebitenRenderer draws the world layer on the screen with the pre-rendered sprites
+type ebitenRenderer struct {
+	screen *ebiten.Image
+}
+
+func (r ebitenRenderer) DrawSprite(sprite Sprite, x, y, angle, scale float64, tint [4]float32) {
+	var img *ebiten.Image
+	switch sprite {
+	case SpriteRedShip:
+		img = redSpaceship
+	case SpriteBlueShip:
+		img = blueSpaceship
+	case SpriteFood:
+		img = foodSprite
+	case SpriteCivilian:
+		img = civilianSprite
+	case SpritePickup:
+		img = pickupSprite
+	default:
+		img = trailSprite
+	}
+	op := &ebiten.DrawImageOptions{}
+	// Center the origin of the image
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Rotate(angle)
+	// Move to actual position in world
+	op.GeoM.Translate(x, y)
+	op.ColorScale.Scale(tint[0], tint[1], tint[2], tint[3])
+	r.screen.DrawImage(img, op)
+}
+
+func (r ebitenRenderer) FillCircle(x, y, radius float32, clr color.RGBA) {
+	vector.FillCircle(r.screen, x, y, radius, clr, true)
+}
+
+func (r ebitenRenderer) StrokeCircle(x, y, radius, width float32, clr color.RGBA) {
+	vector.StrokeCircle(r.screen, x, y, radius, width, clr, true)
+}
+
+func (r ebitenRenderer) StrokeLine(x0, y0, x1, y1, width float32, clr color.RGBA) {
+	vector.StrokeLine(r.screen, x0, y0, x1, y1, width, clr, true)
+}
+
+// overlayMargin is the room between the overlays, and between them and the edges of the screen
+const overlayMargin = 10.0
+
+// overlayText is the style of the stats and messages printed over the world: mono, so that their columns line up
+var overlayText = text.Options{Size: overlayTextSize, Mono: true}
+
+// overlayTextSize is the size of overlayText before the overlay scale
+const overlayTextSize = 12
+
+// overlayScale is the number of screen pixels of a pixel of the overlays: the Game draws at the
+// resolution of the display and enlarges them on HiDPI screens (see uiScale), 1 elsewhere
+var overlayScale = 1.0
+
+// setOverlayScale sizes the overlays and their text for 'scale' screen pixels per pixel
+func setOverlayScale(scale float64) {
+	overlayScale = scale
+	overlayText.Size = overlayTextSize * scale
+}
+
+// scaled converts a size of the overlays to screen pixels
+func scaled(v float64) float64 {
+	return v * overlayScale
+}
+
+// deviceScale returns the device scale factor of the current monitor (2 on a display scaled at 200%)
+func deviceScale() float64 {
+	if m := ebiten.Monitor(); m != nil && m.DeviceScaleFactor() > 0 {
+		return m.DeviceScaleFactor()
+	}
+	return 1
+}
+
+// uiScale returns the scale of the panel and the overlays of the Game: the device scale factor times cfg.UIScale
+func uiScale(cfg *Config) float64 {
+	scale := cfg.UIScale
+	if scale <= 0 {
+		scale = 1
+	}
+	return scale * deviceScale()
+}
+
+// printOverlay prints 'msg' in the overlay style with its top left corner at (x, y)
+func printOverlay(screen *ebiten.Image, msg string, x, y float64) {
+	text.Draw(screen, msg, x, y, overlayText)
+}
+
+// printOverlayRight prints 'msg' in the overlay style, the right edge of its longest line at 'x'
+func printOverlayRight(screen *ebiten.Image, msg string, x, y float64) {
+	text.Draw(screen, msg, x-text.Width(msg, overlayText), y, overlayText)
+}
+
+// placeOverlay prints 'msg' in the overlay style, in a box of its size placed at 'anchor' of 'layout'
+func placeOverlay(screen *ebiten.Image, layout *ui.Layout, anchor ui.Anchor, msg string) {
+	w, h := text.Measure(msg, overlayText)
+	x, y := layout.Place(anchor, w, h)
+	printOverlay(screen, msg, x, y)
+}
+
+// drawGameOver announces the winner in large letters at the center of a screen of w x h
+func drawGameOver(screen *ebiten.Image, winner string, w, h float64) {
+	title := text.Options{Size: scaled(40), Align: text.AlignCenter, Color: color.RGBA{R: 255, G: 220, B: 80, A: 255}}
+	text.Draw(screen, "GAME OVER", w/2, h/2-text.LineHeight(title.Size), title)
+	text.Draw(screen, winner+" is the WINNER !", w/2, h/2+scaled(8), text.Options{Size: scaled(20), Align: text.AlignCenter})
+}
@@ -0,0 +1,132 @@
+package simulation
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// shipColors are the flat colors of the ships drawn by an ImageRenderer, those of the population bar
+var shipColors = map[Sprite]color.RGBA{
+	SpriteRedShip:  {R: 255, G: 50, B: 50, A: 255},
+	SpriteBlueShip: {R: 50, G: 100, B: 255, A: 255},
+	SpriteCivilian: {R: 170, G: 170, B: 170, A: 255},
+}
+
+// ImageRenderer draws the world layer on an image.RGBA without Ebiten, for the runs without a window
+// (see Thumbnails): the ships are flat triangles and the trail puffs discs, no anti-aliasing.
+type ImageRenderer struct {
+	img *image.RGBA
+}
+
+// NewImageRenderer draws on 'img'
+func NewImageRenderer(img *image.RGBA) *ImageRenderer {
+	return &ImageRenderer{img: img}
+}
+
+func (r *ImageRenderer) DrawSprite(sprite Sprite, x, y, angle, scale float64, tint [4]float32) {
+	clr, ship := shipColors[sprite]
+	if sprite == SpriteFood || sprite == SpritePickup {
+		r.FillCircle(float32(x), float32(y), float32(3*scale), tinted(color.RGBA{R: 255, G: 255, B: 255, A: 255}, tint))
+		return
+	}
+	if !ship {
+		// The trail puff is white, the tint gives its color
+		r.fill(x, y, 3.5*scale, tinted(color.RGBA{R: 255, G: 255, B: 255, A: 255}, tint), func(dx, dy float64) bool {
+			return dx*dx+dy*dy <= 3.5*scale*3.5*scale
+		})
+		return
+	}
+	// Facing up like the sprites: the tip at (0, -7) before the rotation
+	sin, cos := math.Sincos(angle)
+	var pts [3][2]float64
+	for i, p := range [3][2]float64{{0, -7}, {-6, 5}, {6, 5}} {
+		px, py := p[0]*scale, p[1]*scale
+		pts[i] = [2]float64{px*cos - py*sin, px*sin + py*cos}
+	}
+	r.fill(x, y, 7*scale, tinted(clr, tint), func(dx, dy float64) bool {
+		return inTriangle(dx, dy, pts)
+	})
+}
+
+func (r *ImageRenderer) FillCircle(x, y, radius float32, clr color.RGBA) {
+	rr := float64(radius) * float64(radius)
+	r.fill(float64(x), float64(y), float64(radius), clr, func(dx, dy float64) bool {
+		return dx*dx+dy*dy <= rr
+	})
+}
+
+func (r *ImageRenderer) StrokeCircle(x, y, radius, width float32, clr color.RGBA) {
+	half := max(0.5, float64(width)/2)
+	r.fill(float64(x), float64(y), float64(radius)+half, clr, func(dx, dy float64) bool {
+		return math.Abs(math.Hypot(dx, dy)-float64(radius)) <= half
+	})
+}
+
+func (r *ImageRenderer) StrokeLine(x0, y0, x1, y1, width float32, clr color.RGBA) {
+	half := max(0.5, float64(width)/2)
+	cx, cy := float64(x0+x1)/2, float64(y0+y1)/2
+	ax, ay := float64(x0)-cx, float64(y0)-cy
+	bx, by := float64(x1)-cx, float64(y1)-cy
+	r.fill(cx, cy, math.Hypot(ax, ay)+half, clr, func(dx, dy float64) bool {
+		return segmentDistance(dx, dy, ax, ay, bx, by) <= half
+	})
+}
+
+// fill blends 'clr' on the pixels within 'reach' of (x, y) for which inside(dx, dy) is true,
+// (dx, dy) being the offset of the pixel center from (x, y)
+func (r *ImageRenderer) fill(x, y, reach float64, clr color.RGBA, inside func(dx, dy float64) bool) {
+	if clr.A == 0 {
+		return
+	}
+	b := r.img.Bounds()
+	minX, maxX := max(b.Min.X, int(math.Floor(x-reach))), min(b.Max.X-1, int(math.Ceil(x+reach)))
+	minY, maxY := max(b.Min.Y, int(math.Floor(y-reach))), min(b.Max.Y-1, int(math.Ceil(y+reach)))
+	for py := minY; py <= maxY; py++ {
+		for px := minX; px <= maxX; px++ {
+			if inside(float64(px)+0.5-x, float64(py)+0.5-y) {
+				r.blend(px, py, clr)
+			}
+		}
+	}
+}
+
+// blend draws 'clr' (straight alpha) over the pixel (x, y)
+func (r *ImageRenderer) blend(x, y int, clr color.RGBA) {
+	i := r.img.PixOffset(x, y)
+	p := r.img.Pix[i : i+4 : i+4]
+	a := uint32(clr.A)
+	p[0] = uint8((uint32(clr.R)*a + uint32(p[0])*(255-a)) / 255)
+	p[1] = uint8((uint32(clr.G)*a + uint32(p[1])*(255-a)) / 255)
+	p[2] = uint8((uint32(clr.B)*a + uint32(p[2])*(255-a)) / 255)
+	p[3] = uint8(a + uint32(p[3])*(255-a)/255)
+}
+
+// tinted multiplies the components of 'clr' by 'tint', like the ColorScale of the Ebiten sprites
+func tinted(clr color.RGBA, tint [4]float32) color.RGBA {
+	scale := func(c uint8, f float32) uint8 {
+		return uint8(max(0, min(255, float32(c)*f)))
+	}
+	return color.RGBA{R: scale(clr.R, tint[0]), G: scale(clr.G, tint[1]), B: scale(clr.B, tint[2]), A: scale(clr.A, tint[3])}
+}
+
+// inTriangle reports whether (x, y) is inside the triangle 'pts', whatever its winding
+func inTriangle(x, y float64, pts [3][2]float64) bool {
+	side := func(a, b [2]float64) float64 {
+		return (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+	}
+	d0, d1, d2 := side(pts[0], pts[1]), side(pts[1], pts[2]), side(pts[2], pts[0])
+	negative := d0 < 0 || d1 < 0 || d2 < 0
+	positive := d0 > 0 || d1 > 0 || d2 > 0
+	return !(negative && positive)
+}
+
+// segmentDistance returns the distance from (x, y) to the segment [a, b]
+func segmentDistance(x, y, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lenSq := dx*dx + dy*dy; lenSq > 0 {
+		t = max(0, min(1, ((x-ax)*dx+(y-ay)*dy)/lenSq))
+	}
+	return math.Hypot(x-ax-t*dx, y-ay-t*dy)
+}
@@ -0,0 +1,76 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// frameHashes runs a seeded simulation on the local engine and returns the hash of every frame
+func frameHashes(t *testing.T, seed uint64, ticks uint64) []uint64 {
+	t.Helper()
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.Seed = seed
+	runner, err := NewRunner(ctx, cfg, WithEngine(NewLocalEngine))
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	defer runner.Stop(ctx)
+
+	trails := NewTrails(DefaultMaxTrailPoints)
+	r := NewHashRenderer()
+	opts := WorldDrawOptions{ShowDetection: true, DetectionRadius: cfg.DetectionRadius, ShowDefense: true, DefenseRadius: cfg.DefenseRadius}
+	hashes := make([]uint64, 0, ticks)
+	_, err = runner.Run(ctx, ticks, func(snap *pb.WorldSnapshot) bool {
+		trails.Update(snap)
+		r.Reset()
+		DrawWorld(r, snap, trails, opts)
+		if r.Calls() == 0 {
+			t.Errorf("Expected draw calls at tick %d", snap.GetTick())
+		}
+		hashes = append(hashes, r.Sum64())
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return hashes
+}
+
+func TestSeededRunsRenderIdenticalFrames(t *testing.T) {
+	const ticks = 60
+	first := frameHashes(t, 42, ticks)
+	second := frameHashes(t, 42, ticks)
+	if len(first) != ticks || len(second) != ticks {
+		t.Fatalf("Expected %d frames, got %d and %d", ticks, len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Frame %d differs between two runs with the same seed: %x != %x", i+1, first[i], second[i])
+		}
+	}
+
+	other := frameHashes(t, 43, ticks)
+	if other[ticks-1] == first[ticks-1] {
+		t.Errorf("Expected another seed to render a different last frame")
+	}
+}
+
+func TestHashRendererDependsOnDrawOrder(t *testing.T) {
+	r := NewHashRenderer()
+	r.DrawSprite(SpriteRedShip, 1, 2, 0, 1, noTint)
+	r.DrawSprite(SpriteBlueShip, 3, 4, 0, 1, noTint)
+	ab := r.Sum64()
+	if r.Calls() != 2 {
+		t.Errorf("Expected 2 calls, got %d", r.Calls())
+	}
+
+	r.Reset()
+	r.DrawSprite(SpriteBlueShip, 3, 4, 0, 1, noTint)
+	r.DrawSprite(SpriteRedShip, 1, 2, 0, 1, noTint)
+	if r.Sum64() == ab {
+		t.Errorf("Expected the hash to change with the draw order")
+	}
+}
@@ -0,0 +1,129 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+)
+
+const (
+	// diffColorScale is the displacement drawn in full red, in world units
+	diffColorScale = 50.0
+	// Height of the divergence plot above the control bar
+	diffPlotHeight = 60.0
+)
+
+// replayDiff is the second recording compared by a ReplayViewer
+type replayDiff struct {
+	frames      []*pb.WorldSnapshot
+	divergences []replay.Divergence
+	maxMean     float64
+	firstTick   uint64
+	diverged    bool
+}
+
+// CompareWith overlays 'other', a recording of the same seed: every entity is linked to its
+// position in 'other' by a segment going from green to red with the displacement, and the mean
+// displacement over time is plotted above the scrubber. It returns the divergence per tick.
+func (v *ReplayViewer) CompareWith(other []*pb.WorldSnapshot) []replay.Divergence {
+	d := &replayDiff{frames: other, divergences: replay.Diff(v.frames, other)}
+	for _, div := range d.divergences {
+		d.maxMean = max(d.maxMean, div.Mean)
+	}
+	d.firstTick, d.diverged = replay.FirstDivergence(d.divergences, 0)
+	v.diff = d
+	return d.divergences
+}
+
+// jumpToDivergence shows the first frame where the recordings differ
+func (v *ReplayViewer) jumpToDivergence() {
+	if v.diff != nil && v.diff.diverged {
+		v.setPlaying(false)
+		v.seek(v.frameAt(v.diff.firstTick))
+	}
+}
+
+// at returns the snapshot of 'other' recorded at 'tick', nil when there is none
+func (d *replayDiff) at(tick uint64) *pb.WorldSnapshot {
+	i := sort.Search(len(d.frames), func(i int) bool { return d.frames[i].Tick >= tick })
+	if i == len(d.frames) || d.frames[i].Tick != tick {
+		return nil
+	}
+	return d.frames[i]
+}
+
+// divergenceAt returns the divergence measured at 'tick'
+func (d *replayDiff) divergenceAt(tick uint64) (replay.Divergence, bool) {
+	i := sort.Search(len(d.divergences), func(i int) bool { return d.divergences[i].Tick >= tick })
+	if i == len(d.divergences) || d.divergences[i].Tick != tick {
+		return replay.Divergence{}, false
+	}
+	return d.divergences[i], true
+}
+
+// displacementColor goes from green (no displacement) to red (diffColorScale and more)
+func displacementColor(dist float64) color.RGBA {
+	ratio := min(dist/diffColorScale, 1)
+	return color.RGBA{R: uint8(255 * ratio), G: uint8(255 * (1 - ratio)), B: 0, A: 255}
+}
+
+// drawDiff draws the displacement of the entities and the divergence plot
+func (v *ReplayViewer) drawDiff(screen *ebiten.Image) {
+	d := v.diff
+	snap := v.Snapshot()
+	if d == nil || snap == nil {
+		return
+	}
+	if other := d.at(snap.Tick); other != nil {
+		positions := make(map[string]*pb.Vector, len(other.Actors))
+		for _, a := range other.Actors {
+			positions[a.Id] = a.Position
+		}
+		for _, a := range snap.Actors {
+			p, ok := positions[a.Id]
+			if !ok {
+				continue
+			}
+			dist := math.Hypot(a.Position.X-p.X, a.Position.Y-p.Y)
+			if dist == 0 {
+				continue
+			}
+			clr := displacementColor(dist)
+			vector.StrokeLine(screen, float32(a.Position.X), float32(a.Position.Y), float32(p.X), float32(p.Y), 1, clr, true)
+			vector.StrokeCircle(screen, float32(p.X), float32(p.Y), 4, 1, clr, true)
+		}
+	}
+
+	// Mean displacement over the recording, the current frame marked by a vertical line
+	s := v.scrubber
+	top := float32(v.cfg.WorldHeight - replayBarHeight - diffPlotHeight)
+	vector.FillRect(screen, float32(s.X), top, float32(s.W), diffPlotHeight, color.RGBA{R: 20, G: 20, B: 30, A: 180}, true)
+	if len(v.frames) > 1 && d.maxMean > 0 {
+		var px, py float32
+		for i, div := range d.divergences {
+			x := float32(s.X + s.W*float64(v.frameAt(div.Tick))/float64(len(v.frames)-1))
+			y := top + diffPlotHeight - float32(div.Mean/d.maxMean)*(diffPlotHeight-4)
+			if i > 0 {
+				vector.StrokeLine(screen, px, py, x, y, 1, color.RGBA{R: 255, G: 120, B: 80, A: 255}, true)
+			}
+			px, py = x, y
+		}
+	}
+	cursor := float32(s.X + s.W*float64(v.frame)/float64(max(len(v.frames)-1, 1)))
+	vector.StrokeLine(screen, cursor, top, cursor, top+diffPlotHeight, 1, color.White, true)
+
+	msg := "recordings identical"
+	if d.diverged {
+		msg = fmt.Sprintf("first divergence at tick %d", d.firstTick)
+	}
+	if div, ok := d.divergenceAt(snap.Tick); ok {
+		msg += fmt.Sprintf(" | mean %.2f max %.2f | %d missing, %d switched team", div.Mean, div.Max, div.Missing, div.ColorChanges)
+	}
+	printOverlay(screen, msg, s.X+4, float64(top)+2)
+}
@@ -0,0 +1,288 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+// replaySpeeds are the playback speeds offered by the viewer, in recorded ticks per frame
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16}
+
+// Layout of the control bar at the bottom of the screen
+const (
+	replayBarHeight = 40.0
+	replayButtonW   = 70.0
+	replayButtonH   = 24.0
+	replayMargin    = 8.0
+)
+
+// ReplayViewer is an Ebiten game playing a recording (see package replay) with the renderer of
+// the Game: play/pause, speed, a frame scrubber and jumps between the highlights of the run.
+//
+// Keys: Space play/pause, Left/Right one frame back/forward, Up/Down speed, P/N previous/next highlight,
+// D first divergence with the recording set by CompareWith.
+type ReplayViewer struct {
+	frames     []*pb.WorldSnapshot
+	highlights []replay.Highlight
+	cfg        *Config
+	trails     *Trails
+
+	// frame is the index of the displayed snapshot, playhead the recorded tick being played
+	frame    int
+	playhead float64
+	playing  bool
+	speed    int // index in replaySpeeds
+
+	buttons     []*ui.Button
+	playButton  *ui.Button
+	speedButton *ui.Button
+	scrubber    *ui.Slider
+
+	// diff is the recording compared with this one, nil when there is none (see replay_diff.go)
+	diff *replayDiff
+}
+
+// NewReplayViewer creates a viewer of 'frames', paused on the first one.
+// The highlights (see replay.DetectHighlights) are the events the viewer can jump to,
+// cfg gives the size of the world and the display options.
+func NewReplayViewer(frames []*pb.WorldSnapshot, highlights []replay.Highlight, cfg *Config) *ReplayViewer {
+	applyTheme(cfg, 1)
+	v := &ReplayViewer{
+		frames:     frames,
+		highlights: highlights,
+		cfg:        cfg,
+		trails:     NewTrails(DefaultMaxTrailPoints),
+		speed:      2, // x1
+	}
+	y := cfg.WorldHeight - replayBarHeight + (replayBarHeight-replayButtonH)/2
+	x := replayMargin
+	addButton := func(label string, onClick func()) *ui.Button {
+		b := ui.NewButton(x, y, replayButtonW, replayButtonH, label, onClick)
+		v.buttons = append(v.buttons, b)
+		x += replayButtonW + replayMargin
+		return b
+	}
+	addButton("<< Event", v.previousHighlight)
+	v.playButton = addButton("Play", v.togglePlay)
+	v.speedButton = addButton("x1", v.nextSpeed)
+	addButton("Event >>", v.nextHighlight)
+
+	v.scrubber = ui.NewSlider(x, y+2, cfg.WorldWidth-x-replayMargin-60, "", 0, float64(max(len(frames)-1, 1)), 0)
+	v.seek(0)
+	return v
+}
+
+// Frame returns the index of the displayed snapshot
+func (v *ReplayViewer) Frame() int {
+	return v.frame
+}
+
+// Snapshot returns the displayed snapshot, nil for an empty recording
+func (v *ReplayViewer) Snapshot() *pb.WorldSnapshot {
+	if len(v.frames) == 0 {
+		return nil
+	}
+	return v.frames[v.frame]
+}
+
+// seek displays the frame 'i' (clamped to the recording), the trails are rebuilt from the frames before it
+func (v *ReplayViewer) seek(i int) {
+	if len(v.frames) == 0 {
+		return
+	}
+	i = max(0, min(i, len(v.frames)-1))
+	if i == v.frame+1 && v.trails.Len() > 0 {
+		v.trails.Update(v.frames[i])
+	} else if i != v.frame || v.trails.Len() == 0 {
+		v.trails.Reset()
+		for j := max(0, i-trailLength+1); j <= i; j++ {
+			v.trails.Update(v.frames[j])
+		}
+	}
+	v.frame = i
+	v.playhead = float64(v.frames[i].Tick)
+	v.scrubber.Value = float64(i)
+}
+
+// frameAt returns the index of the last frame recorded at or before 'tick'
+func (v *ReplayViewer) frameAt(tick uint64) int {
+	i := sort.Search(len(v.frames), func(i int) bool { return v.frames[i].Tick > tick })
+	return max(0, i-1)
+}
+
+// advance moves the playhead by the current speed and shows the frame it reached
+func (v *ReplayViewer) advance() {
+	if len(v.frames) == 0 {
+		return
+	}
+	v.playhead += replaySpeeds[v.speed]
+	target := v.frameAt(uint64(v.playhead))
+	if target > v.frame {
+		// Step through the skipped frames so that the trails stay continuous
+		playhead := v.playhead
+		for v.frame < target {
+			v.seek(v.frame + 1)
+		}
+		v.playhead = playhead
+	}
+	if v.frame == len(v.frames)-1 {
+		v.setPlaying(false)
+	}
+}
+
+func (v *ReplayViewer) setPlaying(playing bool) {
+	v.playing = playing
+	v.playButton.Label = "Play"
+	if playing {
+		v.playButton.Label = "Pause"
+	}
+}
+
+func (v *ReplayViewer) togglePlay() {
+	if !v.playing && v.frame == len(v.frames)-1 {
+		// Replay from the start once the end was reached
+		v.seek(0)
+	}
+	v.setPlaying(!v.playing)
+}
+
+func (v *ReplayViewer) nextSpeed() {
+	v.setSpeed((v.speed + 1) % len(replaySpeeds))
+}
+
+func (v *ReplayViewer) setSpeed(i int) {
+	v.speed = max(0, min(i, len(replaySpeeds)-1))
+	v.speedButton.Label = fmt.Sprintf("x%g", replaySpeeds[v.speed])
+}
+
+// currentTick returns the tick of the displayed snapshot
+func (v *ReplayViewer) currentTick() uint64 {
+	if s := v.Snapshot(); s != nil {
+		return s.Tick
+	}
+	return 0
+}
+
+// highlightFrame returns the index of the first frame recorded at or after the start of 'h'
+func (v *ReplayViewer) highlightFrame(h replay.Highlight) int {
+	i := sort.Search(len(v.frames), func(i int) bool { return v.frames[i].Tick >= h.StartTick })
+	return min(i, len(v.frames)-1)
+}
+
+// nextHighlight jumps to the first highlight starting after the displayed frame
+func (v *ReplayViewer) nextHighlight() {
+	for _, h := range v.highlights {
+		if frame := v.highlightFrame(h); frame > v.frame {
+			v.seek(frame)
+			return
+		}
+	}
+}
+
+// previousHighlight jumps to the last highlight starting before the displayed frame
+func (v *ReplayViewer) previousHighlight() {
+	for i := len(v.highlights) - 1; i >= 0; i-- {
+		if frame := v.highlightFrame(v.highlights[i]); frame < v.frame {
+			v.seek(frame)
+			return
+		}
+	}
+}
+
+// activeHighlight returns the highlight covering the displayed frame, if any
+func (v *ReplayViewer) activeHighlight() (replay.Highlight, bool) {
+	tick := v.currentTick()
+	for _, h := range v.highlights {
+		if h.StartTick <= tick && tick <= h.EndTick {
+			return h, true
+		}
+	}
+	return replay.Highlight{}, false
+}
+
+func (v *ReplayViewer) Update() error {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		v.togglePlay()
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		v.setPlaying(false)
+		v.seek(v.frame + 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		v.setPlaying(false)
+		v.seek(v.frame - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		v.setSpeed(v.speed + 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		v.setSpeed(v.speed - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyN):
+		v.nextHighlight()
+	case inpututil.IsKeyJustPressed(ebiten.KeyP):
+		v.previousHighlight()
+	case inpututil.IsKeyJustPressed(ebiten.KeyD):
+		v.jumpToDivergence()
+	}
+	for _, b := range v.buttons {
+		b.Update()
+	}
+
+	// Dragging the scrubber pauses the playback
+	v.scrubber.Update()
+	if frame := int(v.scrubber.Value + 0.5); frame != v.frame {
+		v.setPlaying(false)
+		v.seek(frame)
+	}
+
+	if v.playing {
+		v.advance()
+	}
+	return nil
+}
+
+func (v *ReplayViewer) Draw(screen *ebiten.Image) {
+	snap := v.Snapshot()
+	DrawWorld(ebitenRenderer{screen}, snap, v.trails, WorldDrawOptions{
+		ShowDetection:   v.cfg.DisplayDetectionCircle,
+		DetectionRadius: v.cfg.DetectionRadius,
+		ShowDefense:     v.cfg.DisplayDefenseCircle,
+		DefenseRadius:   v.cfg.DefenseRadius,
+	})
+	v.drawDiff(screen)
+	drawPopulationBar(screen, snap, ui.NewLayout(v.cfg.WorldWidth, v.cfg.WorldHeight, overlayMargin))
+
+	// Highlights as marks on the scrubber
+	s := v.scrubber
+	vector.FillRect(screen, 0, float32(v.cfg.WorldHeight-replayBarHeight), float32(v.cfg.WorldWidth), replayBarHeight,
+		color.RGBA{R: 20, G: 20, B: 30, A: 220}, true)
+	s.Draw(screen)
+	if len(v.frames) > 1 {
+		for _, h := range v.highlights {
+			x := s.X + s.W*float64(v.highlightFrame(h))/float64(len(v.frames)-1)
+			vector.FillRect(screen, float32(x)-1, float32(s.Y-4), 3, float32(s.H+8), color.RGBA{R: 255, G: 200, B: 0, A: 255}, true)
+		}
+	}
+	for _, b := range v.buttons {
+		b.Draw(screen)
+		ui.DrawText(screen, b.Label, int(b.X+8), int(b.Y+5), b.TextColor)
+	}
+
+	msg := fmt.Sprintf("REPLAY  frame %d/%d  tick %d  x%g", v.frame+1, len(v.frames), v.currentTick(), replaySpeeds[v.speed])
+	if h, ok := v.activeHighlight(); ok {
+		msg += fmt.Sprintf("\n%s: %s", h.Kind, h.Description)
+	}
+	printOverlay(screen, msg, replayMargin, replayMargin)
+	if snap != nil && snap.IsGameOver {
+		drawGameOver(screen, snap.Winner, v.cfg.WorldWidth, v.cfg.WorldHeight)
+	}
+}
+
+func (v *ReplayViewer) Layout(w, h int) (int, int) {
+	return int(v.cfg.WorldWidth), int(v.cfg.WorldHeight)
+}
@@ -0,0 +1,115 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/replay"
+)
+
+// replayFrames returns 'n' frames recorded every 'every' ticks, starting at tick 'every'
+func replayFrames(n int, every uint64) []*pb.WorldSnapshot {
+	frames := make([]*pb.WorldSnapshot, n)
+	for i := range frames {
+		frames[i] = redSnapshot(3, 0, float64(i))
+		frames[i].Tick = uint64(i+1) * every
+	}
+	return frames
+}
+
+func TestReplayViewer_playback(t *testing.T) {
+	v := NewReplayViewer(replayFrames(10, 2), nil, DefaultConfig())
+	if v.Frame() != 0 || v.playing {
+		t.Fatalf("Expected the viewer paused on the first frame, got frame %d", v.Frame())
+	}
+
+	// x1: one recorded tick per frame, so one snapshot every other update
+	v.togglePlay()
+	v.advance()
+	if v.Frame() != 0 {
+		t.Errorf("Expected frame 0 after one tick, got %d", v.Frame())
+	}
+	v.advance()
+	if v.Frame() != 1 {
+		t.Errorf("Expected frame 1 after two ticks, got %d", v.Frame())
+	}
+
+	// x8 reaches the end and pauses
+	v.setSpeed(5)
+	for range 5 {
+		v.advance()
+	}
+	if v.Frame() != 9 || v.playing {
+		t.Errorf("Expected a paused viewer on the last frame, got frame %d, playing %v", v.Frame(), v.playing)
+	}
+	// Play again starts over
+	v.togglePlay()
+	if v.Frame() != 0 || !v.playing {
+		t.Errorf("Expected the playback to restart, got frame %d", v.Frame())
+	}
+
+	v.seek(42)
+	if v.Frame() != 9 || v.scrubber.Value != 9 {
+		t.Errorf("Expected seek to clamp to the last frame, got %d (scrubber %v)", v.Frame(), v.scrubber.Value)
+	}
+	if v.trails.Len() != 3 || len(v.trails.Trail("Red-000")) != trailLength/2 {
+		t.Errorf("Expected the trails rebuilt from the previous frames, got %d trails of %d points", v.trails.Len(), len(v.trails.Trail("Red-000")))
+	}
+}
+
+func TestReplayViewer_jumpToHighlights(t *testing.T) {
+	highlights := []replay.Highlight{
+		{Kind: replay.HighlightConversionCascade, StartTick: 5, EndTick: 8},
+		{Kind: replay.HighlightGameOver, StartTick: 20, EndTick: 20},
+	}
+	v := NewReplayViewer(replayFrames(10, 2), highlights, DefaultConfig())
+
+	v.nextHighlight()
+	if v.currentTick() != 6 {
+		t.Errorf("Expected the first frame of the cascade, got tick %d", v.currentTick())
+	}
+	if h, ok := v.activeHighlight(); !ok || h.Kind != replay.HighlightConversionCascade {
+		t.Errorf("Expected tick 6 in the conversion cascade, got %v, %v", h, ok)
+	}
+	v.nextHighlight()
+	if v.currentTick() != 20 {
+		t.Errorf("Expected the game over at tick 20, got %d", v.currentTick())
+	}
+	// Nothing after the last highlight
+	v.nextHighlight()
+	if v.currentTick() != 20 {
+		t.Errorf("Expected to stay at tick 20, got %d", v.currentTick())
+	}
+	v.previousHighlight()
+	if v.currentTick() != 6 {
+		t.Errorf("Expected to jump back to the cascade, got tick %d", v.currentTick())
+	}
+	// Nothing before the first highlight
+	v.previousHighlight()
+	if v.currentTick() != 6 {
+		t.Errorf("Expected to stay at tick 6, got %d", v.currentTick())
+	}
+}
+
+func TestReplayViewer_compareWith(t *testing.T) {
+	frames := replayFrames(10, 1)
+	other := replayFrames(10, 1)
+	for _, snap := range other[6:] {
+		snap.Actors[0].Position.X += 3
+	}
+	v := NewReplayViewer(frames, nil, DefaultConfig())
+	divergences := v.CompareWith(other)
+	if len(divergences) != 10 {
+		t.Fatalf("Expected 10 compared ticks, got %d", len(divergences))
+	}
+	v.jumpToDivergence()
+	if v.currentTick() != 7 {
+		t.Errorf("Expected the first divergence at tick 7, got %d", v.currentTick())
+	}
+	if div, ok := v.diff.divergenceAt(7); !ok || div.Max != 3 || div.Matched != 4 {
+		t.Errorf("Unexpected divergence at tick 7: %+v", div)
+	}
+	if v.diff.at(42) != nil {
+		t.Errorf("Expected no snapshot of the other recording at tick 42")
+	}
+}
@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// birthSpread is the largest distance between a parent and its newborn
+const birthSpread = 15.0
+
+// maxPopulation returns the population above which no blue gives birth: Config.MaxPopulation,
+// twice the initial population when it is 0
+func (c *Config) maxPopulation() int {
+	if c.MaxPopulation > 0 {
+		return c.MaxPopulation
+	}
+	return 2 * (c.NumRedAtStart + c.NumBlueAtStart)
+}
+
+// updateReproduction lets the blues give birth to a blue next to them once they survived
+// ReproduceTicks as blues, or ate ReproduceMeals food items, since their spawn, their conversion or
+// their last birth. The newborns are spawned by the world like any other entity, as long as the
+// population stays below maxPopulation.
+func (w *world) updateReproduction() {
+	if w.cfg.ReproduceTicks == 0 && w.cfg.ReproduceMeals == 0 {
+		return
+	}
+	if w.birthRand == nil {
+		w.birthRand = w.entityRand("birth")
+	}
+	// What the blues ate during the last tick, published once
+	var eaten map[string]int
+	if food := w.live.food.Load(); food != nil {
+		eaten = food.eaten
+	}
+	var parents []*Entity
+	for _, e := range w.order {
+		if e.Color != pb.TeamColor_TEAM_BLUE {
+			e.lifeTick, e.meals = 0, 0
+			continue
+		}
+		if e.lifeTick == 0 {
+			e.lifeTick = w.tick
+		}
+		e.meals += eaten[e.ID]
+		if w.cfg.ReproduceTicks > 0 && w.tick-e.lifeTick >= uint64(w.cfg.ReproduceTicks) ||
+			w.cfg.ReproduceMeals > 0 && e.meals >= w.cfg.ReproduceMeals {
+			parents = append(parents, e)
+		}
+	}
+	limit := w.cfg.maxPopulation()
+	for _, parent := range parents {
+		if len(w.entities) >= limit {
+			return
+		}
+		parent.lifeTick, parent.meals = w.tick, 0
+		angle := 2 * math.Pi * w.birthRand.Float64()
+		offset := geometry.Vector2D{X: math.Cos(angle), Y: math.Sin(angle)}.Mul(birthSpread * w.birthRand.Float64())
+		pos := parent.Pos.Add(offset)
+		pos.X = min(max(pos.X, 0), w.cfg.WorldWidth)
+		pos.Y = min(max(pos.Y, 0), w.cfg.WorldHeight)
+		child := w.spawnEntity(pb.TeamColor_TEAM_BLUE, pos, parent.Vel)
+		child.lifeTick = w.tick
+	}
+}
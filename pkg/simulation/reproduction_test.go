@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestConfigReproduction(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReproduceTicks = 600
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.maxPopulation(); got != 2*(cfg.NumRedAtStart+cfg.NumBlueAtStart) {
+		t.Errorf("Expected twice the initial population by default, got %d", got)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.ReproduceMeals = -1 },
+		func(c *Config) { c.MaxPopulation = -1 },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestWorld_updateReproduction(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart, cfg.NumBlueAtStart = 1, 1
+	cfg.ReproduceTicks, cfg.ReproduceMeals, cfg.MaxPopulation = 3, 1, 4
+	engine, err := NewLocalEngine(context.Background(), make(chan *pb.WorldSnapshot, 1), cfg)
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	w := engine.(*localEngine).world
+	blue := w.entities["Blue-000"]
+
+	step := func() {
+		w.tick++
+		w.updateReproduction()
+	}
+	step() // The blue starts counting
+	step()
+	step()
+	if len(w.entities) != 2 {
+		t.Fatalf("Expected no birth before ReproduceTicks, got %d entities", len(w.entities))
+	}
+	step()
+	if len(w.entities) != 3 {
+		t.Fatalf("Expected a birth after ReproduceTicks, got %d entities", len(w.entities))
+	}
+	child := w.order[2]
+	if child.Color != pb.TeamColor_TEAM_BLUE || child.Pos.DistanceTo(blue.Pos) > birthSpread || child.Vel != blue.Vel {
+		t.Errorf("Expected a blue newborn next to its parent, got %+v", child)
+	}
+
+	// A meal is enough, as long as the population stays below MaxPopulation
+	w.live.food.Store(&foodState{eaten: map[string]int{"Blue-000": 1}})
+	step()
+	w.live.food.Store(&foodState{eaten: map[string]int{"Blue-000": 1, child.ID: 1}})
+	step()
+	if len(w.entities) != 4 {
+		t.Errorf("Expected the population capped at MaxPopulation, got %d", len(w.entities))
+	}
+}
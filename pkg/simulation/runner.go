@@ -0,0 +1,242 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// ErrRunnerStopped is returned by the Runner methods called after Stop
+var ErrRunnerStopped = errors.New("simulation: runner stopped")
+
+// Runner drives a simulation without any rendering: every call to Step advances
+// the world by one tick and returns the resulting snapshot.
+// It is the stable entry point for programs embedding the simulation (tests, sweeps, servers...).
+// A Runner is not safe for concurrent use.
+type Runner struct {
+	cfg        *Config
+	engine     Engine
+	newEngine  EngineFactory
+	release    func(ctx context.Context) error // Frees what NewRunner created for the engine
+	worldOpts  []WorldOption
+	snapshotCh chan *pb.WorldSnapshot
+	latest     *pb.WorldSnapshot
+	tick       uint64
+	warmup     uint64
+	stopped    bool
+	runID      string
+	thumbnails *Thumbnails // Stills of Config.ThumbnailEvery and Config.ScreenshotOnGameOver, nil without
+}
+
+// RunnerOption configures a Runner
+type RunnerOption func(r *Runner)
+
+// WithEngine selects the engine running the world, see ActorEngine and NewLocalEngine.
+// By default the Runner uses the engine named by Config.Engine, the actor engine in a private
+// actor system when it is empty (the local engine in WASM builds).
+func WithEngine(factory EngineFactory) RunnerOption {
+	return func(r *Runner) {
+		r.newEngine = factory
+	}
+}
+
+// WithWorldOptions passes options (tick hooks, snapshot hub...) to the world
+func WithWorldOptions(opts ...WorldOption) RunnerOption {
+	return func(r *Runner) {
+		r.worldOpts = append(r.worldOpts, opts...)
+	}
+}
+
+// WithWarmup runs 'ticks' steps in NewRunner before returning, so that the metrics collected
+// from the first Step do not include the spawn transient (see replay.SteadyStateDetector
+// to detect when the population dynamics settled)
+func WithWarmup(ticks uint64) RunnerOption {
+	return func(r *Runner) {
+		r.warmup = ticks
+	}
+}
+
+// WithRunID names the run, and so the directory of its stills (see Thumbnails):
+// NewRunID(cfg.Seed) by default. Give the runs of a sweep sharing a seed their own ID.
+func WithRunID(id string) RunnerOption {
+	return func(r *Runner) {
+		r.runID = id
+	}
+}
+
+// NewRunner validates the config, then spawns the world and its population.
+// The world runs on its own copy of cfg: send the changes made between two steps with ApplyConfig.
+func NewRunner(ctx context.Context, cfg *Config, opts ...RunnerOption) (*Runner, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	r := &Runner{
+		cfg:        cfg,
+		snapshotCh: make(chan *pb.WorldSnapshot, 10), // Room for the late snapshots of interrupted steps
+		latest:     &pb.WorldSnapshot{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.runID == "" {
+		r.runID = NewRunID(cfg.Seed)
+	}
+	r.thumbnails = NewThumbnails(cfg, r.runID)
+
+	if r.newEngine == nil {
+		r.newEngine = SelectEngine(cfg, nil)
+	}
+	if r.newEngine == nil {
+		factory, release, err := defaultEngine(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.newEngine = factory
+		r.release = release
+	}
+
+	engine, err := r.newEngine(ctx, r.snapshotCh, cfg, r.worldOpts...)
+	if err != nil {
+		if r.release != nil {
+			_ = r.release(ctx)
+		}
+		return nil, fmt.Errorf("cannot spawn world: %w", err)
+	}
+	r.engine = engine
+	for i := uint64(0); i < r.warmup; i++ {
+		if _, err := r.Step(ctx); err != nil {
+			_ = r.Stop(ctx)
+			return nil, fmt.Errorf("warm-up interrupted at tick %d: %w", r.tick, err)
+		}
+	}
+	return r, nil
+}
+
+// Config returns the config the Runner was created with, see ApplyConfig for the changes
+func (r *Runner) Config() *Config {
+	return r.cfg
+}
+
+// Tick returns the number of steps executed so far, warm-up included
+func (r *Runner) Tick() uint64 {
+	return r.tick
+}
+
+// Warmup returns the number of warm-up steps run by NewRunner
+func (r *Runner) Warmup() uint64 {
+	return r.warmup
+}
+
+// RunID returns the name of the run, see WithRunID
+func (r *Runner) RunID() string {
+	return r.runID
+}
+
+// Latest returns the snapshot returned by the last Step (empty before the first one)
+func (r *Runner) Latest() *pb.WorldSnapshot {
+	return r.latest
+}
+
+// Step advances the simulation by one tick and waits for the resulting snapshot.
+// Individuals move asynchronously: the snapshot shows the positions they reported before this tick.
+func (r *Runner) Step(ctx context.Context) (*pb.WorldSnapshot, error) {
+	if r.stopped {
+		return nil, ErrRunnerStopped
+	}
+	// Discard the snapshots of steps interrupted by their context
+	for len(r.snapshotCh) > 0 {
+		<-r.snapshotCh
+	}
+	if err := r.engine.Send(ctx, &pb.Tick{}); err != nil {
+		return nil, fmt.Errorf("cannot send tick: %w", err)
+	}
+	r.tick++
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case snap := <-r.snapshotCh:
+			if snap.GetTick() < r.tick {
+				continue // Late snapshot of a previous step
+			}
+			r.latest = snap
+			if err := r.thumbnails.Observe(snap); err != nil {
+				return snap, fmt.Errorf("cannot save thumbnail: %w", err)
+			}
+			return snap, nil
+		}
+	}
+}
+
+// Run steps the simulation until the game is over, 'maxTicks' steps were executed (0 means no limit)
+// or 'onStep' returns false. onStep may be nil. It returns the last snapshot.
+func (r *Runner) Run(ctx context.Context, maxTicks uint64, onStep func(snap *pb.WorldSnapshot) bool) (*pb.WorldSnapshot, error) {
+	for n := uint64(0); maxTicks == 0 || n < maxTicks; n++ {
+		snap, err := r.Step(ctx)
+		if err != nil {
+			return r.latest, err
+		}
+		if onStep != nil && !onStep(snap) {
+			break
+		}
+		if snap.GetIsGameOver() {
+			break
+		}
+	}
+	return r.latest, nil
+}
+
+// SetStrategy switches the behavior of a whole team, see BehaviorNames for the available names
+func (r *Runner) SetStrategy(ctx context.Context, team pb.TeamColor, name string) error {
+	if r.stopped {
+		return ErrRunnerStopped
+	}
+	if _, err := NewBehavior(name); err != nil {
+		return err
+	}
+	return r.engine.Send(ctx, &pb.SetStrategy{Team: team, Name: name})
+}
+
+// ApplyConfig sends the live parameters and the strategies of 'cfg' to the world
+// (e.g. from the onChange callback of WatchConfig). Population changes need a new Runner.
+func (r *Runner) ApplyConfig(ctx context.Context, cfg *Config) error {
+	if r.stopped {
+		return ErrRunnerStopped
+	}
+	if err := r.engine.Send(ctx, cfg.UpdateMessage()); err != nil {
+		return err
+	}
+	for _, team := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE} {
+		if name := cfg.StrategyFor(team); name != r.cfg.StrategyFor(team) {
+			if err := r.SetStrategy(ctx, team, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// State returns the live state of one entity
+func (r *Runner) State(ctx context.Context, id string) (*pb.ActorState, error) {
+	if r.stopped {
+		return nil, ErrRunnerStopped
+	}
+	return r.engine.State(ctx, id)
+}
+
+// Stop shuts down the world (and the actor system when it was created by NewRunner)
+func (r *Runner) Stop(ctx context.Context) error {
+	if r.stopped {
+		return nil
+	}
+	r.stopped = true
+	err := r.engine.Stop(ctx)
+	if r.release != nil {
+		if releaseErr := r.release(ctx); err == nil {
+			err = releaseErr
+		}
+	}
+	return err
+}
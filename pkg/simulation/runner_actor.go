@@ -0,0 +1,28 @@
+//go:build !js
+
+package simulation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tochemey/goakt/v3/actor"
+)
+
+// WithActorSystem runs the world with the actor engine in an existing (started) actor system
+// instead of a private one. The system is left running by Stop.
+func WithActorSystem(system actor.ActorSystem) RunnerOption {
+	return WithEngine(ActorEngine(system))
+}
+
+// defaultEngine starts a private actor system for the actor engine, release stops it
+func defaultEngine(ctx context.Context) (EngineFactory, func(ctx context.Context) error, error) {
+	system, err := actor.NewActorSystem("SwarmRunner", actor.WithActorInitMaxRetries(3))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create actor system: %w", err)
+	}
+	if err := system.Start(ctx); err != nil {
+		return nil, nil, fmt.Errorf("cannot start actor system: %w", err)
+	}
+	return ActorEngine(system), system.Stop, nil
+}
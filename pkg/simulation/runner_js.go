@@ -0,0 +1,10 @@
+//go:build js
+
+package simulation
+
+import "context"
+
+// defaultEngine is the local engine: goakt does not build for WASM
+func defaultEngine(context.Context) (EngineFactory, func(ctx context.Context) error, error) {
+	return NewLocalEngine, nil, nil
+}
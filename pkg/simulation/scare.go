@@ -0,0 +1,85 @@
+package simulation
+
+import (
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// scareStrength and scareRadius are the push and the reach of the scare tool
+	scareStrength = 0.6
+	scareRadius   = 120.0
+)
+
+// Scare links the world to whoever scares the entities, e.g. the Game while the key of ActionScare
+// is held. Give it to the world with WithScare. A Scare is safe for concurrent use.
+type Scare struct {
+	mu     sync.Mutex
+	pos    geometry.Vector2D
+	active bool
+}
+
+// Set scares the entities around 'pos' away until Stop
+func (s *Scare) Set(pos geometry.Vector2D) {
+	s.mu.Lock()
+	s.pos, s.active = pos, true
+	s.mu.Unlock()
+}
+
+// Stop ends the scare
+func (s *Scare) Stop() {
+	s.mu.Lock()
+	s.active = false
+	s.mu.Unlock()
+}
+
+// Repulsor returns the scare as the repulsor it acts as, false while there is none
+func (s *Scare) Repulsor() (Attractor, bool) {
+	if s == nil {
+		return Attractor{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Attractor{Pos: s.pos, Strength: -scareStrength, Radius: scareRadius}, s.active
+}
+
+// WithScare makes the world push the entities away from the scares of 's'
+func WithScare(s *Scare) WorldOption {
+	return func(w *world) {
+		w.scare.control = s
+	}
+}
+
+// scareTracker keeps the scare of the current tick
+type scareTracker struct {
+	control *Scare
+	// repulsor is the scare of the tick, valid while active
+	repulsor Attractor
+	active   bool
+}
+
+// updateScare reads the scare of the tick, the perceptions of the entities within its reach carry its force
+func (w *world) updateScare() {
+	w.scare.repulsor, w.scare.active = w.scare.control.Repulsor()
+}
+
+// scareForce returns the force of the scare of the tick on an entity at 'pos', nil when it is out of reach
+func (w *world) scareForce(pos geometry.Vector2D) *pb.Vector {
+	if !w.scare.active {
+		return nil
+	}
+	force := w.scare.repulsor.force(pos)
+	if force == (geometry.Vector2D{}) {
+		return nil
+	}
+	return &pb.Vector{X: force.X, Y: force.Y}
+}
+
+// applyPerceivedForce adds the transient force of the perception of the tick, e.g. a scare
+func applyPerceivedForce(me *Entity, perception *pb.Perception) {
+	if force := perception.GetForce(); force != nil {
+		me.ApplyForce(geometry.Vector2D{X: force.X, Y: force.Y})
+	}
+}
@@ -0,0 +1,62 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestWorld_scare(t *testing.T) {
+	control := &Scare{}
+	w, s := newScriptedWorld(&Config{WorldWidth: 400, WorldHeight: 400}, WithScare(control))
+	s.report(blueAt("Blue-000", 150, 100), blueAt("Blue-001", 350, 350))
+	forces := func() map[string]*pb.Vector {
+		s.tick()
+		got := map[string]*pb.Vector{}
+		for _, m := range s.told {
+			if tick, ok := m.msg.(*pb.Tick); ok {
+				got[m.id] = tick.GetContext().GetForce()
+			}
+		}
+		return got
+	}
+	if got := forces(); got["Blue-000"] != nil || got["Blue-001"] != nil {
+		t.Fatalf("Expected no force before the scare, got %v", got)
+	}
+
+	control.Set(geometry.Vector2D{X: 100, Y: 100})
+	got := forces()
+	if near := got["Blue-000"]; near == nil || near.X <= 0 || near.Y != 0 {
+		t.Errorf("Expected the close entity pushed right, away from the scare, got %v", near)
+	}
+	if far := got["Blue-001"]; far != nil {
+		t.Errorf("Expected the entity out of reach left alone, got %v", far)
+	}
+	if w.live.attractors.Load() != nil {
+		t.Error("Expected the scare out of the attractors")
+	}
+
+	control.Stop()
+	if got := forces(); got["Blue-000"] != nil {
+		t.Errorf("Expected no force once the scare stopped, got %v", got["Blue-000"])
+	}
+	if _, ok := control.Repulsor(); ok {
+		t.Error("Expected no repulsor to draw once the scare stopped")
+	}
+}
+
+func TestIndividual_perceivedForce(t *testing.T) {
+	for _, color := range []pb.TeamColor{pb.TeamColor_TEAM_RED, pb.TeamColor_TEAM_BLUE, NeutralColor} {
+		still := newIndividual(color, 300, 300, 0, 2, newLiveConfig(DefaultConfig()), nil)
+		scared := newIndividual(color, 300, 300, 0, 2, newLiveConfig(DefaultConfig()), nil)
+		still.setID("Entity-000")
+		scared.setID("Entity-000")
+		calm := still.handleTick(&pb.Tick{Context: &pb.Perception{}})
+		pushed := scared.handleTick(&pb.Tick{Context: &pb.Perception{Force: &pb.Vector{X: 0.5}}})
+		if pushed.Position.X <= calm.Position.X {
+			t.Errorf("%v: expected the force of the perception to push the entity right, got x %f without and %f with it",
+				color, calm.Position.X, pushed.Position.X)
+		}
+	}
+}
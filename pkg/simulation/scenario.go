@@ -0,0 +1,246 @@
+package simulation
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// DefaultGroupSpread is the radius of the disk where the entities of a SpawnGroup appear when Spread is not set
+const DefaultGroupSpread = 50.0
+
+// Scenario stages the arrival of entities in named waves, on top of the initial population of the
+// config, e.g. "wave 2 of 50 reds at tick 3000". It is run by a tick hook: see WithScenario.
+//
+//	{
+//	  "name": "Assault",
+//	  "groups": [
+//	    {"wave": "scouts", "team": "RED", "count": 5, "x": 100, "y": 100},
+//	    {"wave": "assault", "team": "RED", "count": 50, "x": 900, "y": 100, "spread": 80, "activateAt": 3000}
+//	  ]
+//	}
+type Scenario struct {
+	Name   string       `json:"name,omitempty"`
+	Groups []SpawnGroup `json:"groups"`
+}
+
+// SpawnGroup is a group of entities of one team entering the world together
+type SpawnGroup struct {
+	// Wave names the wave of the group, the groups of the same wave share its metrics (see WaveStats)
+	Wave string `json:"wave"`
+	// Team is RED or BLUE
+	Team  string `json:"team"`
+	Count int    `json:"count"`
+	// X and Y are the center of the disk of radius Spread (DefaultGroupSpread when 0) where the group appears
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Spread float64 `json:"spread,omitempty"`
+	// ActivateAt delays the group: its entities stay out of the world, idle, until this tick (0 = first tick)
+	ActivateAt uint64 `json:"activateAt,omitempty"`
+}
+
+// LoadScenario reads a scenario from a JSON file, it must then be validated against the config
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read scenario: %w", err)
+	}
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Validate checks the groups against the world of 'cfg', the ecs engine does not run tick hooks
+func (s *Scenario) Validate(cfg *Config) error {
+	if cfg.Engine == EngineECS {
+		return fmt.Errorf("scenarios are not supported by the %s engine", EngineECS)
+	}
+	if len(s.Groups) == 0 {
+		return errors.New("scenario without group")
+	}
+	for i, g := range s.Groups {
+		if g.Wave == "" {
+			return fmt.Errorf("group %d: missing wave name", i)
+		}
+		if _, err := parseTeam(g.Team); err != nil {
+			return fmt.Errorf("group %d (%s): %w", i, g.Wave, err)
+		}
+		if g.Count <= 0 {
+			return fmt.Errorf("group %d (%s): count must be positive", i, g.Wave)
+		}
+		if g.Spread < 0 {
+			return fmt.Errorf("group %d (%s): spread cannot be negative", i, g.Wave)
+		}
+		if g.X < 0 || g.X > cfg.WorldWidth || g.Y < 0 || g.Y > cfg.WorldHeight {
+			return fmt.Errorf("group %d (%s): (%g, %g) is outside the world", i, g.Wave, g.X, g.Y)
+		}
+	}
+	return nil
+}
+
+// parseTeam returns the team of a label (see teamLabel)
+func parseTeam(label string) (pb.TeamColor, error) {
+	switch label {
+	case "RED":
+		return pb.TeamColor_TEAM_RED, nil
+	case "BLUE":
+		return pb.TeamColor_TEAM_BLUE, nil
+	}
+	return pb.TeamColor_TEAM_UNSPECIFIED, fmt.Errorf("unknown team %q (use RED or BLUE)", label)
+}
+
+// WaveStats are the metrics of a wave: where the entities it brought are now.
+// An entity whose ID is recycled after a Despawn still counts for its first wave.
+type WaveStats struct {
+	Wave string `json:"wave"`
+	// ActivateAt is the tick of its first group, Triggered is set once every group spawned
+	ActivateAt uint64 `json:"activateAt"`
+	Triggered  bool   `json:"triggered"`
+	Spawned    int    `json:"spawned"`
+	// Active entities are still in the team they spawned in, Converted switched team, Gone left the world
+	Active    int `json:"active"`
+	Converted int `json:"converted"`
+	Gone      int `json:"gone"`
+}
+
+// ScenarioRun runs a Scenario in a world and keeps its wave metrics, which are safe to read from any goroutine
+type ScenarioRun struct {
+	groups []SpawnGroup // Sorted by activation tick
+	// OnWave, if set, is called in the world goroutine when every group of a wave spawned
+	OnWave func(stats WaveStats)
+
+	mu      sync.Mutex
+	next    int // Index in groups of the next group to spawn
+	waves   []WaveStats
+	index   map[string]int // Wave name -> index in waves
+	pending map[string]int // Wave name -> groups not spawned yet
+	members []waveMember
+}
+
+type waveMember struct {
+	id   string
+	wave int
+	team pb.TeamColor
+}
+
+// NewScenarioRun prepares the run of a validated scenario
+func NewScenarioRun(s *Scenario) *ScenarioRun {
+	groups := slices.Clone(s.Groups)
+	slices.SortStableFunc(groups, func(a, b SpawnGroup) int {
+		return cmp.Compare(a.ActivateAt, b.ActivateAt)
+	})
+	r := &ScenarioRun{groups: groups}
+	r.reset()
+	return r
+}
+
+// WithScenario runs the scenario in the world: its groups spawn at their activation tick.
+// The run restarts from the first wave every time the option is applied to a new world.
+func WithScenario(run *ScenarioRun) WorldOption {
+	return func(w *world) {
+		run.reset()
+		w.tickHooks = append(w.tickHooks, run.tick)
+	}
+}
+
+func (r *ScenarioRun) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next = 0
+	r.waves = r.waves[:0]
+	r.index = map[string]int{}
+	r.pending = map[string]int{}
+	r.members = r.members[:0]
+	for _, g := range r.groups {
+		if _, ok := r.index[g.Wave]; !ok {
+			r.index[g.Wave] = len(r.waves)
+			r.waves = append(r.waves, WaveStats{Wave: g.Wave, ActivateAt: g.ActivateAt})
+		}
+		r.pending[g.Wave]++
+	}
+}
+
+// Waves returns the metrics of the waves, in activation order
+func (r *ScenarioRun) Waves() []WaveStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.waves)
+}
+
+// tick is the TickHook spawning the groups due and updating the metrics
+func (r *ScenarioRun) tick(view *WorldView, cmds *CommandQueue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ; r.next < len(r.groups) && r.groups[r.next].ActivateAt <= view.Tick(); r.next++ {
+		r.spawnGroup(view, cmds, r.next)
+	}
+
+	for i := range r.waves {
+		r.waves[i].Active, r.waves[i].Converted, r.waves[i].Gone = 0, 0, 0
+	}
+	for _, m := range r.members {
+		stats := &r.waves[m.wave]
+		switch e, ok := view.Entity(m.id); {
+		case !ok:
+			stats.Gone++
+		case e.Color == m.team:
+			stats.Active++
+		default:
+			stats.Converted++
+		}
+	}
+}
+
+// spawnGroup queues the entities of the group 'g' around its center
+func (r *ScenarioRun) spawnGroup(view *WorldView, cmds *CommandQueue, g int) {
+	group := r.groups[g]
+	team, _ := parseTeam(group.Team)
+	wave := r.index[group.Wave]
+	spread := group.Spread
+	if spread == 0 {
+		spread = DefaultGroupSpread
+	}
+	cfg := view.Config()
+	for i := 0; i < group.Count; i++ {
+		// Uniform in the disk, clamped inside the world, with a random heading
+		rng := view.Rand(fmt.Sprintf("scenario/%d/%d", g, i))
+		angle, dist := rng.Float64()*2*math.Pi, spread*math.Sqrt(rng.Float64())
+		pos := geometry.Vector2D{
+			X: min(max(group.X+dist*math.Cos(angle), 0), cfg.WorldWidth),
+			Y: min(max(group.Y+dist*math.Sin(angle), 0), cfg.WorldHeight),
+		}
+		heading := rng.Float64() * 2 * math.Pi
+		vel := geometry.Vector2D{X: math.Cos(heading), Y: math.Sin(heading)}
+		cmds.SpawnWith(team, pos, vel, func(id string) {
+			// Commands run after the hooks returned: the lock is free again
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.members = append(r.members, waveMember{id: id, wave: wave, team: team})
+			r.waves[wave].Spawned++
+			r.waves[wave].Active++
+		})
+	}
+	// The wave is complete with its last group, notified once its entities are in the world
+	r.pending[group.Wave]--
+	if r.pending[group.Wave] == 0 {
+		cmds.cmds = append(cmds.cmds, func(*world) {
+			r.mu.Lock()
+			r.waves[wave].Triggered = true
+			stats := r.waves[wave]
+			r.mu.Unlock()
+			if r.OnWave != nil {
+				r.OnWave(stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestScenarioWaves(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 1
+	cfg.NumBlueAtStart = 1
+	cfg.Seed = 42
+	scenario := &Scenario{Groups: []SpawnGroup{
+		{Wave: "assault", Team: "RED", Count: 4, X: 100, Y: 100, ActivateAt: 3},
+		{Wave: "scouts", Team: "BLUE", Count: 2, X: 200, Y: 200},
+		{Wave: "assault", Team: "RED", Count: 3, X: 300, Y: 100, ActivateAt: 3},
+	}}
+	if err := scenario.Validate(cfg); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	run := NewScenarioRun(scenario)
+	var triggered []string
+	run.OnWave = func(stats WaveStats) { triggered = append(triggered, stats.Wave) }
+
+	snapshotCh := make(chan *pb.WorldSnapshot, 1)
+	engine, err := NewLocalEngine(ctx, snapshotCh, cfg, WithScenario(run))
+	if err != nil {
+		t.Fatalf("NewLocalEngine failed: %v", err)
+	}
+	defer engine.Stop(ctx)
+	actors := func() int {
+		if err := engine.Send(ctx, &pb.Tick{}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		return len((<-snapshotCh).GetActors())
+	}
+
+	// The scouts arrive on the first tick, the assault stays out of the world until tick 3
+	if n := actors(); n != 4 {
+		t.Errorf("Expected the 2 scouts on tick 1, got %d actors", n)
+	}
+	if n := actors(); n != 4 {
+		t.Errorf("Expected the assault to wait on tick 2, got %d actors", n)
+	}
+	waves := run.Waves()
+	if len(waves) != 2 || waves[0].Wave != "scouts" || !waves[0].Triggered || waves[1].Triggered || waves[1].Spawned != 0 {
+		t.Errorf("Unexpected waves before the assault: %+v", waves)
+	}
+	if n := actors(); n != 11 {
+		t.Errorf("Expected the 7 entities of the assault on tick 3, got %d actors", n)
+	}
+	actors() // The metrics are computed at the start of the next tick
+
+	waves = run.Waves()
+	assault := waves[1]
+	if !assault.Triggered || assault.Spawned != 7 || assault.Active+assault.Converted+assault.Gone != 7 || assault.ActivateAt != 3 {
+		t.Errorf("Unexpected assault metrics: %+v", assault)
+	}
+	if strings.Join(triggered, ",") != "scouts,assault" {
+		t.Errorf("Expected OnWave once per wave, got %v", triggered)
+	}
+}
+
+func TestScenarioValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	tests := []struct {
+		name  string
+		group SpawnGroup
+		want  string
+	}{
+		{"no wave", SpawnGroup{Team: "RED", Count: 1}, "missing wave"},
+		{"bad team", SpawnGroup{Wave: "w", Team: "GREEN", Count: 1}, "unknown team"},
+		{"empty", SpawnGroup{Wave: "w", Team: "RED"}, "count"},
+		{"outside", SpawnGroup{Wave: "w", Team: "BLUE", Count: 1, X: -10}, "outside"},
+	}
+	for _, tt := range tests {
+		s := &Scenario{Groups: []SpawnGroup{tt.group}}
+		if err := s.Validate(cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	ecs := *cfg
+	ecs.Engine = EngineECS
+	valid := &Scenario{Groups: []SpawnGroup{{Wave: "w", Team: "RED", Count: 1}}}
+	if err := valid.Validate(&ecs); err == nil {
+		t.Error("Expected the ecs engine to be rejected")
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	s, err := LoadScenario(filepath.Join("..", "..", "scenarios", "assault.json"))
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	if err := s.Validate(DefaultConfig()); err != nil {
+		t.Errorf("The example scenario is invalid: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScenario(path); err == nil {
+		t.Error("Expected an error for an invalid file")
+	}
+}
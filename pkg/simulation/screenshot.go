@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/capture"
+)
+
+// DefaultScreenshotKey saves the current frame to a PNG file when Config.ScreenshotKey is not set
+const DefaultScreenshotKey = "F12"
+
+// screenshotKeyName returns cfg.ScreenshotKey, DefaultScreenshotKey when it is not set
+func screenshotKeyName(cfg *Config) string {
+	if cfg.ScreenshotKey == "" {
+		return DefaultScreenshotKey
+	}
+	return cfg.ScreenshotKey
+}
+
+// screenshotKey returns the key named by cfg.ScreenshotKey (Ebiten key names: F12, P, Digit1...)
+func screenshotKey(cfg *Config) (ebiten.Key, error) {
+	name := screenshotKeyName(cfg)
+	var key ebiten.Key
+	if err := key.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown screenshotKey %q", name)
+	}
+	return key, nil
+}
+
+// requestScreenshot saves the next frame, it is taken in Draw once the world is drawn
+func (g *Game) requestScreenshot() {
+	g.screenshotPending = true
+}
+
+// requestScreenshotAs saves the next frame to 'path' instead of a timestamped file
+func (g *Game) requestScreenshotAs(path string) {
+	g.screenshotPending = true
+	g.screenshotPath = path
+}
+
+// scheduleThumbnail requests the screenshot of the frame of 'snap' when it is one of the stills of
+// the run (see Config.ThumbnailEvery and Config.ScreenshotOnGameOver)
+func (g *Game) scheduleThumbnail(snap *pb.WorldSnapshot) {
+	if name := g.thumbnails.due(snap); name != "" {
+		g.requestScreenshotAs(filepath.Join(runDir(g.runID), name))
+	}
+}
+
+// takePendingScreenshot redraws the frame on an offscreen image, the UI overlays only when
+// "Screenshot Includes UI" is checked, and writes it to screenshotPath or a timestamped PNG in the captures directory
+func (g *Game) takePendingScreenshot(screen *ebiten.Image) {
+	if !g.screenshotPending {
+		return
+	}
+	g.screenshotPending = false
+	bounds := screen.Bounds()
+	offscreen := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	defer offscreen.Deallocate()
+	// The screen is transparent where nothing is drawn, a still needs an opaque background
+	offscreen.Fill(color.Black)
+	g.drawWorldLayer(offscreen)
+	if g.widgetScreenshotUI.Value {
+		g.drawOverlays(offscreen)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	offscreen.ReadPixels(img.Pix)
+
+	path := g.screenshotPath
+	g.screenshotPath = ""
+	if path == "" {
+		// Milliseconds: several stills can be taken in the same second
+		name := fmt.Sprintf("swarm-%s.png", time.Now().Format("20060102-150405.000"))
+		path = filepath.Join(captureDir, name)
+	}
+	go func() {
+		if err := capture.SavePNG(path, img); err != nil {
+			g.engine.Logger().Errorf("Screenshot failed: %v", err)
+			return
+		}
+		g.engine.Logger().Infof("Screenshot saved to %s", path)
+	}()
+}
@@ -0,0 +1,22 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestScreenshotKey(t *testing.T) {
+	cfg := DefaultConfig()
+	if key, err := screenshotKey(cfg); err != nil || key != ebiten.KeyF12 {
+		t.Errorf("Expected F12 by default, got %v %v", key, err)
+	}
+	cfg.ScreenshotKey = "P"
+	if key, err := screenshotKey(cfg); err != nil || key != ebiten.KeyP {
+		t.Errorf("Expected P, got %v %v", key, err)
+	}
+	cfg.ScreenshotKey = "NoSuchKey"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown key to be rejected")
+	}
+}
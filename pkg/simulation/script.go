@@ -0,0 +1,203 @@
+package simulation
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// StrategyScriptPrefix prefixes the strategies running a Starlark script, e.g. "script:behaviors/chase.star"
+const StrategyScriptPrefix = "script"
+
+// scriptMaxSteps bounds the work of one call of a script, an endless loop stops the entity instead
+// of the world
+const scriptMaxSteps = 100_000
+
+// Names of the functions of a script moving each team
+var scriptFunctions = map[pb.TeamColor]string{
+	pb.TeamColor_TEAM_RED:  "updateRed",
+	pb.TeamColor_TEAM_BLUE: "updateBlue",
+}
+
+func init() {
+	RegisterBehaviorResolver(StrategyScriptPrefix, func(path string) (Behavior, error) {
+		program, err := loadScript(path)
+		if err != nil {
+			return nil, err
+		}
+		return &ScriptBehavior{program: program}, nil
+	})
+}
+
+// scriptProgram is a loaded script, its frozen globals are safe to call from every individual
+type scriptProgram struct {
+	path     string
+	modTime  time.Time
+	globals  starlark.StringDict
+	errOnce  sync.Once // Only the first error of the calls is logged
+	printLog func(thread *starlark.Thread, msg string)
+}
+
+var (
+	scriptsMu sync.Mutex
+	scripts   = make(map[string]*scriptProgram)
+)
+
+// loadScript compiles the script at 'path' once, again when the file changed: an edited script
+// is picked up by the next restart or strategy change, without recompiling the game
+func loadScript(path string) (*scriptProgram, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read script: %w", err)
+	}
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+	if p, ok := scripts[path]; ok && p.modTime.Equal(info.ModTime()) {
+		return p, nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read script: %w", err)
+	}
+	p := &scriptProgram{path: path, modTime: info.ModTime(), printLog: func(_ *starlark.Thread, msg string) {
+		log.Printf("INFO [%s] %s", path, msg)
+	}}
+	// The scripts only see the starlark builtins and the math module: no file, network or clock
+	thread := &starlark.Thread{Name: path, Print: p.printLog}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	p.globals, err = starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, src, starlark.StringDict{"math": starlarkmath.Module})
+	if err != nil {
+		return nil, fmt.Errorf("cannot load script %s: %w", path, err)
+	}
+	defined := false
+	for _, name := range scriptFunctions {
+		if fn, ok := p.globals[name]; ok {
+			if _, callable := fn.(starlark.Callable); !callable {
+				return nil, fmt.Errorf("script %s: %s is not a function", path, name)
+			}
+			defined = true
+		}
+	}
+	if !defined {
+		return nil, fmt.Errorf("script %s defines neither updateRed nor updateBlue", path)
+	}
+	scripts[path] = p
+	return p, nil
+}
+
+// ScriptBehavior moves an entity with the function of a Starlark script for its team:
+// updateRed(state, perception) or updateBlue(state, perception). 'state' has the fields id, team,
+// x, y, vx, vy and random() (the random stream of the entity), 'perception' the lists friends and
+// targets (id, x, y, vx, vy, and dx, dy relative to the entity) and the struct config (worldWidth,
+// worldHeight, maxSpeed, minSpeed, aggression, turnFactor), the math module is predeclared.
+// The function returns the steering force as a (x, y) pair, or None, applied like the Action of
+// an AgentPolicy.
+type ScriptBehavior struct {
+	program *scriptProgram
+	thread  *starlark.Thread
+}
+
+// Defines reports whether the script has a function for 'team'
+func (b *ScriptBehavior) Defines(team pb.TeamColor) bool {
+	_, ok := b.program.globals[scriptFunctions[team]]
+	return ok
+}
+
+func (b *ScriptBehavior) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	steer, err := b.call(me, perception, cfg)
+	if err != nil {
+		b.program.errOnce.Do(func() {
+			log.Printf("ERROR [%s] %v (the entities keep their velocity)", b.program.path, err)
+		})
+	}
+	steerEntity(me, Action{Steer: steer}, cfg)
+}
+
+// call runs the function of the team of 'me' and returns its steering force
+func (b *ScriptBehavior) call(me *Entity, perception *pb.Perception, cfg *Config) (geometry.Vector2D, error) {
+	fn, ok := b.program.globals[scriptFunctions[me.Color]]
+	if !ok {
+		return geometry.Vector2D{}, fmt.Errorf("no %s function", scriptFunctions[me.Color])
+	}
+	if b.thread == nil {
+		b.thread = &starlark.Thread{Name: b.program.path, Print: b.program.printLog}
+		b.thread.SetMaxExecutionSteps(scriptMaxSteps)
+	}
+	b.thread.Steps = 0
+
+	state := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"id":   starlark.String(me.ID),
+		"team": starlark.String(scriptTeam(me.Color)),
+		"x":    starlark.Float(me.Pos.X),
+		"y":    starlark.Float(me.Pos.Y),
+		"vx":   starlark.Float(me.Vel.X),
+		"vy":   starlark.Float(me.Vel.Y),
+		"random": starlark.NewBuiltin("random", func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+			return starlark.Float(me.Float64()), nil
+		}),
+	})
+	seen := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"friends": scriptNeighbors(me.Pos, perception.GetFriends()),
+		"targets": scriptNeighbors(me.Pos, perception.GetTargets()),
+		"config": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"worldWidth":  starlark.Float(cfg.WorldWidth),
+			"worldHeight": starlark.Float(cfg.WorldHeight),
+			"maxSpeed":    starlark.Float(cfg.MaxSpeed),
+			"minSpeed":    starlark.Float(cfg.MinSpeed),
+			"aggression":  starlark.Float(cfg.Aggression),
+			"turnFactor":  starlark.Float(cfg.TurnFactor),
+		}),
+	})
+	result, err := starlark.Call(b.thread, fn, starlark.Tuple{state, seen}, nil)
+	if err != nil {
+		return geometry.Vector2D{}, err
+	}
+	if result == starlark.None {
+		return geometry.Vector2D{}, nil
+	}
+	pair, ok := result.(starlark.Indexable)
+	if !ok || pair.Len() != 2 {
+		return geometry.Vector2D{}, fmt.Errorf("%s returned %s, expected an (x, y) pair or None", fn, result)
+	}
+	x, okX := starlark.AsFloat(pair.Index(0))
+	y, okY := starlark.AsFloat(pair.Index(1))
+	if !okX || !okY {
+		return geometry.Vector2D{}, fmt.Errorf("%s returned %s, expected numbers", fn, result)
+	}
+	return geometry.Vector2D{X: x, Y: y}, nil
+}
+
+// scriptNeighbors converts the perceived states for a script
+func scriptNeighbors(from geometry.Vector2D, states []*pb.ActorState) *starlark.List {
+	list := make([]starlark.Value, len(states))
+	for i, s := range states {
+		pos, vel := GeomVector2DFromProto(s.GetPosition()), GeomVector2DFromProto(s.GetVelocity())
+		list[i] = starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"id": starlark.String(s.GetId()),
+			"x":  starlark.Float(pos.X),
+			"y":  starlark.Float(pos.Y),
+			"vx": starlark.Float(vel.X),
+			"vy": starlark.Float(vel.Y),
+			"dx": starlark.Float(pos.X - from.X),
+			"dy": starlark.Float(pos.Y - from.Y),
+		})
+	}
+	return starlark.NewList(list)
+}
+
+// scriptTeam names a team for the scripts
+func scriptTeam(team pb.TeamColor) string {
+	if team == pb.TeamColor_TEAM_RED {
+		return "red"
+	}
+	return "blue"
+}
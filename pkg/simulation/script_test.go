@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func writeScript(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "behavior.star")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScriptBehavior(t *testing.T) {
+	path := writeScript(t, `
+def updateRed(me, seen):
+    if len(seen.targets) == 0:
+        return None
+    t = seen.targets[0]
+    return (t.dx, t.dy)
+`)
+	b, err := NewBehavior(StrategyScriptPrefix + ":" + path)
+	if err != nil {
+		t.Fatalf("NewBehavior() error = %v", err)
+	}
+	cfg := DefaultConfig()
+	me := &Entity{ID: "red-1", Color: pb.TeamColor_TEAM_RED, Pos: geometry.Vector2D{X: 100, Y: 100}}
+	b.Update(me, &pb.Perception{Targets: []*pb.ActorState{{Id: "blue-1", Position: &pb.Vector{X: 100, Y: 200}}}}, cfg)
+	if me.Vel.X != 0 || me.Vel.Y != cfg.MaxSpeed {
+		t.Errorf("Expected the red steered down at max speed, got %v", me.Vel)
+	}
+	// No target: None keeps the velocity
+	b.Update(me, &pb.Perception{}, cfg)
+	if me.Vel.Y != cfg.MaxSpeed {
+		t.Errorf("Expected the velocity kept, got %v", me.Vel)
+	}
+
+	// The config rejects the script for blue, it has no updateBlue
+	cfg.RedStrategy = StrategyScriptPrefix + ":" + path
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the script valid for red, got %v", err)
+	}
+	cfg.BlueStrategy = cfg.RedStrategy
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected the script rejected for blue")
+	}
+}
+
+func TestScriptBehavior_sandbox(t *testing.T) {
+	for name, src := range map[string]string{
+		"syntax":       "def updateRed(me, seen)\n    return None\n",
+		"no function":  "speed = 2\n",
+		"not callable": "updateRed = 1\n",
+		"no io":        "data = open('/etc/passwd')\ndef updateRed(me, seen):\n    return None\n",
+	} {
+		if _, err := NewBehavior(StrategyScriptPrefix + ":" + writeScript(t, src)); err == nil {
+			t.Errorf("%s: expected the script to be rejected", name)
+		}
+	}
+
+	// An endless loop is stopped, the entity keeps moving
+	path := writeScript(t, "def updateRed(me, seen):\n    n = 0\n    for i in range(1000000000):\n        n += i\n    return (1, 0)\n")
+	b, err := NewBehavior(StrategyScriptPrefix + ":" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := &Entity{Color: pb.TeamColor_TEAM_RED, Vel: geometry.Vector2D{Y: 1}}
+	b.Update(me, &pb.Perception{}, DefaultConfig())
+	if me.Vel.X != 0 || me.Vel.Y != 1 || me.Pos.Y != 1 {
+		t.Errorf("Expected the entity to coast, got vel %v pos %v", me.Vel, me.Pos)
+	}
+}
+
+func TestScriptBehavior_example(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RedStrategy = StrategyScriptPrefix + ":../../behaviors/chase.star"
+	cfg.BlueStrategy = cfg.RedStrategy
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected the example script to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SensitivityParameters are the Config fields (by JSON name) perturbed by default in a sensitivity analysis
+var SensitivityParameters = []string{
+	"numRedAtStart", "numBlueAtStart",
+	"detectionRadius", "defenseRadius", "contactRadius", "visualRange", "protectedRange",
+	"maxSpeed", "minSpeed", "aggression",
+	"centeringFactor", "avoidFactor", "matchingFactor", "turnFactor",
+}
+
+// SensitivityOptions configures AnalyzeSensitivity
+type SensitivityOptions struct {
+	// Parameters are the JSON names of the perturbed Config fields (SensitivityParameters when empty)
+	Parameters []string
+	// Delta is the relative perturbation applied below and above the baseline value (0.1 = ±10%)
+	Delta float64
+	// Rounds is the number of seeded rounds run for every configuration, round i uses seed Seed+i
+	// so that every configuration faces the same initial layouts
+	Rounds int
+	Seed   uint64
+	// MaxTicks ends a round without winner
+	MaxTicks uint64
+	// Engine runs the rounds, NewLocalEngine (reproducible) when nil
+	Engine EngineFactory
+	// Workers is the number of rounds run in parallel, the number of CPUs when <= 0
+	Workers int
+}
+
+// DefaultSensitivityOptions returns a ±10% analysis of 10 rounds of at most three minutes of simulation time
+func DefaultSensitivityOptions() SensitivityOptions {
+	return SensitivityOptions{
+		Delta:    0.1,
+		Rounds:   10,
+		Seed:     1,
+		MaxTicks: 3 * 60 * SimTicksPerSecond,
+	}
+}
+
+// Outcome summarizes the rounds of one configuration
+type Outcome struct {
+	// RedWinRate is the fraction of the rounds won by the red team, BlueWinRate by the blue team
+	// (the rest ended without winner after MaxTicks)
+	RedWinRate  float64 `json:"redWinRate"`
+	BlueWinRate float64 `json:"blueWinRate"`
+	// MeanTicks is the mean time-to-victory of the won rounds, in ticks (0 when no round was won)
+	MeanTicks float64 `json:"meanTicks"`
+}
+
+// ParameterSensitivity is the effect of perturbing one parameter around the baseline
+type ParameterSensitivity struct {
+	Name string  `json:"name"`
+	Low  float64 `json:"low"`
+	Base float64 `json:"base"`
+	High float64 `json:"high"`
+	// LowOutcome and HighOutcome are nil when the perturbed config is invalid (see Error)
+	LowOutcome  *Outcome `json:"lowOutcome,omitempty"`
+	HighOutcome *Outcome `json:"highOutcome,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	// WinRateEffect is the largest change of the red win rate from the baseline,
+	// TimeEffect the largest relative change of the time-to-victory
+	WinRateEffect float64 `json:"winRateEffect"`
+	TimeEffect    float64 `json:"timeEffect"`
+}
+
+// SensitivityReport ranks the parameters by their effect on the outcome of the rounds
+type SensitivityReport struct {
+	Options    SensitivityOptions     `json:"-"`
+	Baseline   Outcome                `json:"baseline"`
+	Parameters []ParameterSensitivity `json:"parameters"` // Most influential first
+}
+
+// AnalyzeSensitivity perturbs each parameter ±Delta around 'base', one at a time, runs the seeded
+// rounds of every configuration headless and ranks the parameters by their effect on the red
+// win rate, then on the time-to-victory
+func AnalyzeSensitivity(ctx context.Context, base *Config, opts SensitivityOptions) (*SensitivityReport, error) {
+	if err := base.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid baseline config: %w", err)
+	}
+	if len(opts.Parameters) == 0 {
+		opts.Parameters = SensitivityParameters
+	}
+	if opts.Rounds <= 0 {
+		return nil, fmt.Errorf("rounds (%d) must be positive", opts.Rounds)
+	}
+	if opts.Engine == nil {
+		opts.Engine = NewLocalEngine
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	// Every configuration to run: the baseline, then the low and high variant of each parameter
+	configs := []*Config{base}
+	report := &SensitivityReport{Options: opts}
+	for _, name := range opts.Parameters {
+		field, ok := configField(name)
+		if !ok || !isNumeric(field.Type.Kind()) {
+			return nil, fmt.Errorf("%q is not a numeric config parameter", name)
+		}
+		p := ParameterSensitivity{Name: name, Base: fieldValue(base, field)}
+		low, high := *base, *base
+		p.Low = setFieldValue(&low, field, p.Base*(1-opts.Delta))
+		p.High = setFieldValue(&high, field, p.Base*(1+opts.Delta))
+		for _, cfg := range []*Config{&low, &high} {
+			if err := cfg.Validate(); err != nil && p.Error == "" {
+				p.Error = err.Error()
+			}
+		}
+		if p.Error == "" {
+			configs = append(configs, &low, &high)
+		}
+		report.Parameters = append(report.Parameters, p)
+	}
+
+	outcomes, err := runOutcomes(ctx, configs, opts)
+	if err != nil {
+		return nil, err
+	}
+	report.Baseline = outcomes[0]
+	next := 1
+	for i := range report.Parameters {
+		p := &report.Parameters[i]
+		if p.Error != "" {
+			continue
+		}
+		p.LowOutcome, p.HighOutcome = &outcomes[next], &outcomes[next+1]
+		next += 2
+		for _, o := range []*Outcome{p.LowOutcome, p.HighOutcome} {
+			p.WinRateEffect = max(p.WinRateEffect, math.Abs(o.RedWinRate-report.Baseline.RedWinRate))
+			if report.Baseline.MeanTicks > 0 {
+				p.TimeEffect = max(p.TimeEffect, math.Abs(o.MeanTicks-report.Baseline.MeanTicks)/report.Baseline.MeanTicks)
+			}
+		}
+	}
+	sort.SliceStable(report.Parameters, func(i, j int) bool {
+		a, b := report.Parameters[i], report.Parameters[j]
+		if a.WinRateEffect != b.WinRateEffect {
+			return a.WinRateEffect > b.WinRateEffect
+		}
+		return a.TimeEffect > b.TimeEffect
+	})
+	return report, nil
+}
+
+// runRounds plays the rounds 0 to n-1 on 'workers' goroutines and stops at the first error.
+// 'done' is called with the number of rounds played after each of them, from the calling goroutine.
+func runRounds(ctx context.Context, n, workers int, play func(ctx context.Context, round int) error, done func(played int)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rounds := make(chan int)
+	finished := make(chan struct{})
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range rounds {
+				if err := play(ctx, r); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				finished <- struct{}{}
+			}
+		}()
+	}
+	go func() {
+		defer close(rounds)
+		for r := 0; r < n; r++ {
+			select {
+			case rounds <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	played := 0
+	for range finished {
+		played++
+		if done != nil {
+			done(played)
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// runOutcomes runs the rounds of every config on opts.Workers goroutines
+func runOutcomes(ctx context.Context, configs []*Config, opts SensitivityOptions) ([]Outcome, error) {
+	type result struct {
+		winner string
+		ticks  uint64
+	}
+	results := make([][]result, len(configs))
+	for i := range results {
+		results[i] = make([]result, opts.Rounds)
+	}
+
+	// Round r plays the config r / Rounds with the seed offset r % Rounds
+	err := runRounds(ctx, len(configs)*opts.Rounds, opts.Workers, func(ctx context.Context, r int) error {
+		c, index := r/opts.Rounds, r%opts.Rounds
+		cfg := *configs[c]
+		cfg.Seed = opts.Seed + uint64(index)
+		winner, ticks, err := playRound(ctx, &cfg, opts)
+		if err != nil {
+			return err
+		}
+		results[c][index] = result{winner: winner, ticks: ticks}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]Outcome, len(configs))
+	for c, rs := range results {
+		var red, blue int
+		var ticks uint64
+		for _, r := range rs {
+			switch r.winner {
+			case ColorRed:
+				red++
+			case ColorBlue:
+				blue++
+			default:
+				continue
+			}
+			ticks += r.ticks
+		}
+		o := &outcomes[c]
+		o.RedWinRate = float64(red) / float64(len(rs))
+		o.BlueWinRate = float64(blue) / float64(len(rs))
+		if won := red + blue; won > 0 {
+			o.MeanTicks = float64(ticks) / float64(won)
+		}
+	}
+	return outcomes, nil
+}
+
+// playRound runs one headless round and returns its winner ("" when MaxTicks was reached) and length
+func playRound(ctx context.Context, cfg *Config, opts SensitivityOptions) (string, uint64, error) {
+	runner, err := NewRunner(ctx, cfg, WithEngine(opts.Engine))
+	if err != nil {
+		return "", 0, err
+	}
+	defer runner.Stop(ctx)
+	last, err := runner.Run(ctx, opts.MaxTicks, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	return last.GetWinner(), runner.Tick(), nil
+}
+
+// WriteText writes the report as a table, most influential parameter first
+func (r *SensitivityReport) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Baseline: red wins %.0f%%, blue wins %.0f%%, victory in %.0f ticks (%d rounds, ±%.0f%%)\n\n",
+		r.Baseline.RedWinRate*100, r.Baseline.BlueWinRate*100, r.Baseline.MeanTicks, r.Options.Rounds, r.Options.Delta*100)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%-4s %-16s %10s %10s %10s %14s %14s %9s %8s\n",
+		"rank", "parameter", "low", "base", "high", "red wins -/+", "ticks -/+", "Δwin", "Δtime"); err != nil {
+		return err
+	}
+	for i, p := range r.Parameters {
+		var line string
+		if p.Error != "" {
+			line = fmt.Sprintf("%-4d %-16s %10.4g %10.4g %10.4g  skipped: %s\n", i+1, p.Name, p.Low, p.Base, p.High, p.Error)
+		} else {
+			line = fmt.Sprintf("%-4d %-16s %10.4g %10.4g %10.4g %6.0f%%/%5.0f%% %6.0f/%7.0f %8.0f%% %7.0f%%\n",
+				i+1, p.Name, p.Low, p.Base, p.High,
+				p.LowOutcome.RedWinRate*100, p.HighOutcome.RedWinRate*100,
+				p.LowOutcome.MeanTicks, p.HighOutcome.MeanTicks,
+				p.WinRateEffect*100, p.TimeEffect*100)
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func fieldValue(cfg *Config, field reflect.StructField) float64 {
+	v := reflect.ValueOf(cfg).Elem().FieldByIndex(field.Index)
+	if v.Kind() == reflect.Int {
+		return float64(v.Int())
+	}
+	return v.Float()
+}
+
+// setFieldValue sets the field to 'value' (rounded for integers) and returns the value actually set
+func setFieldValue(cfg *Config, field reflect.StructField, value float64) float64 {
+	v := reflect.ValueOf(cfg).Elem().FieldByIndex(field.Index)
+	if v.Kind() == reflect.Int {
+		v.SetInt(int64(math.Round(value)))
+		return float64(v.Int())
+	}
+	v.SetFloat(value)
+	return value
+}
@@ -0,0 +1,94 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAnalyzeSensitivity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 3
+	cfg.NumBlueAtStart = 10
+	cfg.LogLevel = "error"
+	// defenseRadius cannot grow past detectionRadius: its high variant is invalid
+	cfg.DefenseRadius = cfg.DetectionRadius
+	opts := DefaultSensitivityOptions()
+	opts.Parameters = []string{"numRedAtStart", "defenseRadius", "maxSpeed"}
+	opts.Rounds = 2
+	opts.MaxTicks = 100
+	opts.Workers = 2
+
+	report, err := AnalyzeSensitivity(context.Background(), cfg, opts)
+	if err != nil {
+		t.Fatalf("AnalyzeSensitivity() error = %v", err)
+	}
+	if len(report.Parameters) != 3 {
+		t.Fatalf("Expected 3 parameters in the report, got %d", len(report.Parameters))
+	}
+	for i, p := range report.Parameters {
+		if i > 0 && p.WinRateEffect > report.Parameters[i-1].WinRateEffect {
+			t.Errorf("Parameters not ranked by win rate effect: %+v", report.Parameters)
+		}
+		switch p.Name {
+		case "defenseRadius":
+			if p.Error == "" || p.LowOutcome != nil {
+				t.Errorf("Expected the invalid variant of defenseRadius to be skipped, got %+v", p)
+			}
+		case "numRedAtStart":
+			if p.Low != 3 || p.High != 3 {
+				// ±10% of 3 entities rounds back to 3
+				t.Errorf("Expected numRedAtStart to stay 3, got %v and %v", p.Low, p.High)
+			}
+		}
+		if p.Error == "" && (p.LowOutcome == nil || p.HighOutcome == nil) {
+			t.Errorf("Missing outcomes for %s", p.Name)
+		}
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "maxSpeed") || !strings.Contains(text.String(), "skipped") {
+		t.Errorf("Unexpected report:\n%s", text.String())
+	}
+}
+
+func TestAnalyzeSensitivity_unknownParameter(t *testing.T) {
+	opts := DefaultSensitivityOptions()
+	opts.Parameters = []string{"redStrategy"}
+	if _, err := AnalyzeSensitivity(context.Background(), DefaultConfig(), opts); err == nil {
+		t.Error("Expected an error for a parameter that is not numeric")
+	}
+}
+
+func TestRunRounds(t *testing.T) {
+	var sum atomic.Int64
+	var progress []int
+	err := runRounds(context.Background(), 10, 3, func(_ context.Context, r int) error {
+		sum.Add(int64(r))
+		return nil
+	}, func(played int) {
+		progress = append(progress, played)
+	})
+	if err != nil || sum.Load() != 45 || len(progress) != 10 || progress[9] != 10 {
+		t.Errorf("Expected the 10 rounds played once each, got %v, sum %d, progress %v", err, sum.Load(), progress)
+	}
+
+	broken := errors.New("broken round")
+	var played atomic.Int32
+	err = runRounds(context.Background(), 100, 2, func(ctx context.Context, r int) error {
+		played.Add(1)
+		if r == 3 {
+			return broken
+		}
+		return ctx.Err()
+	}, nil)
+	if !errors.Is(err, broken) || played.Load() == 100 {
+		t.Errorf("Expected the first error to stop the rounds, got %v after %d rounds", err, played.Load())
+	}
+}
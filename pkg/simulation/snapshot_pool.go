@@ -0,0 +1,84 @@
+package simulation
+
+import (
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SnapshotPool recycles the snapshots pushed on the engine channel, with their ActorState
+// and Vector messages, so that a tick does not allocate thousands of protobuf messages.
+// The consumer of the channel gives every snapshot back with Put once it is done with it.
+type SnapshotPool struct {
+	pool sync.Pool
+}
+
+// NewSnapshotPool creates an empty pool
+func NewSnapshotPool() *SnapshotPool {
+	return &SnapshotPool{}
+}
+
+// WithSnapshotPool makes the world build its snapshots from the pool. The snapshots published
+// to a SnapshotHub are never recycled: hub subscribers keep them for an unknown time.
+func WithSnapshotPool(pool *SnapshotPool) WorldOption {
+	return func(w *world) {
+		w.snapshots = pool
+	}
+}
+
+// get returns an empty snapshot whose Actors slice has room for 'n' recycled states
+func (p *SnapshotPool) get(n int) *pb.WorldSnapshot {
+	if p == nil {
+		return &pb.WorldSnapshot{Actors: make([]*pb.ActorState, 0, n)}
+	}
+	snap, _ := p.pool.Get().(*pb.WorldSnapshot)
+	if snap == nil {
+		return &pb.WorldSnapshot{Actors: make([]*pb.ActorState, 0, n)}
+	}
+	actors := snap.Actors
+	proto.Reset(snap)
+	snap.Actors = actors[:0]
+	return snap
+}
+
+// Put gives a snapshot back to the pool: neither it nor its actors may be used afterwards.
+// It accepts nil and snapshots that do not come from the pool.
+func (p *SnapshotPool) Put(snap *pb.WorldSnapshot) {
+	if p == nil || snap == nil {
+		return
+	}
+	p.pool.Put(snap)
+}
+
+// nextActor returns the next state of 'snap', recycling the message left by a previous use
+func nextActor(snap *pb.WorldSnapshot) *pb.ActorState {
+	n := len(snap.Actors)
+	if n < cap(snap.Actors) {
+		snap.Actors = snap.Actors[:n+1]
+		if state := snap.Actors[n]; state != nil {
+			return state
+		}
+	} else {
+		snap.Actors = append(snap.Actors, nil)
+	}
+	state := &pb.ActorState{}
+	snap.Actors[n] = state
+	return state
+}
+
+// fillActorState writes the state of an entity into a (possibly recycled) message
+func fillActorState(state *pb.ActorState, id string, color pb.TeamColor, x, y, vx, vy float64, generation uint32, hunger, fatigue float64) {
+	state.Id = id
+	state.Color = color
+	state.Generation = generation
+	state.Hunger, state.Fatigue = hunger, fatigue
+	if state.Position == nil {
+		state.Position = &pb.Vector{}
+	}
+	state.Position.X, state.Position.Y = x, y
+	if state.Velocity == nil {
+		state.Velocity = &pb.Vector{}
+	}
+	state.Velocity.X, state.Velocity.Y = vx, vy
+}
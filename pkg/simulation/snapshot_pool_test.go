@@ -0,0 +1,112 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSnapshotPool_recycledSnapshotsMatchFreshOnes(t *testing.T) {
+	ctx := context.Background()
+	run := func(pool *SnapshotPool) []*pb.WorldSnapshot {
+		cfg := DefaultConfig()
+		cfg.Seed = 3
+		snapshotCh := make(chan *pb.WorldSnapshot, 1)
+		engine, err := NewLocalEngine(ctx, snapshotCh, cfg, WithSnapshotPool(pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var snaps []*pb.WorldSnapshot
+		for tick := 0; tick < 5; tick++ {
+			_ = engine.Send(ctx, &pb.Tick{})
+			snap := <-snapshotCh
+			// Keep a copy, then recycle the snapshot
+			snaps = append(snaps, proto.Clone(snap).(*pb.WorldSnapshot))
+			pool.Put(snap)
+		}
+		return snaps
+	}
+
+	fresh, pooled := run(nil), run(NewSnapshotPool())
+	for i := range fresh {
+		if !proto.Equal(fresh[i], pooled[i]) {
+			t.Fatalf("Snapshot of tick %d differs when built from recycled messages", fresh[i].GetTick())
+		}
+	}
+}
+
+func TestSnapshotPool_recycledSnapshotsKeepTheNeeds(t *testing.T) {
+	w := newWorld(nil, DefaultConfig())
+	w.snapshots = NewSnapshotPool()
+	blue := &Entity{ID: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Hunger: 0.3}
+	red := &Entity{ID: "Red-000", Color: pb.TeamColor_TEAM_RED, Fatigue: 0.7}
+	w.addEntity(blue)
+	w.addEntity(red)
+	needs := func(snap *pb.WorldSnapshot) map[string][2]float64 {
+		got := map[string][2]float64{}
+		for _, a := range snap.GetActors() {
+			got[a.GetId()] = [2]float64{a.GetHunger(), a.GetFatigue()}
+		}
+		return got
+	}
+
+	first := w.buildSnapshot(nil)
+	if got := needs(first); got["Blue-000"] != [2]float64{0.3, 0} || got["Red-000"] != [2]float64{0, 0.7} {
+		t.Fatalf("Expected the hunger and the fatigue in the snapshot, got %v", got)
+	}
+	w.snapshots.Put(first)
+	blue.Hunger, red.Fatigue = 0, 0.2
+	if got := needs(w.buildSnapshot(nil)); got["Blue-000"] != [2]float64{0, 0} || got["Red-000"] != [2]float64{0, 0.2} {
+		t.Errorf("Expected the recycled messages to carry the new needs, got %v", got)
+	}
+
+	// The ECS engine has no needs: the messages recycled from the world are zeroed
+	ctx := context.Background()
+	pool := NewSnapshotPool()
+	blue.Hunger, red.Fatigue = 1, 1
+	pool.Put(w.buildSnapshot(nil))
+	snapshotCh := make(chan *pb.WorldSnapshot, 1)
+	engine, err := NewECSEngine(ctx, snapshotCh, DefaultConfig(), WithSnapshotPool(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = engine.Send(ctx, &pb.Tick{})
+	for id, got := range needs(<-snapshotCh) {
+		if got != [2]float64{} {
+			t.Fatalf("Expected no hunger nor fatigue with the %s engine, got %v for %s", EngineECS, got, id)
+		}
+	}
+}
+
+func TestSnapshotPool_disabledWithHub(t *testing.T) {
+	pool := NewSnapshotPool()
+	w := newWorld(make(chan *pb.WorldSnapshot), DefaultConfig(), WithSnapshotPool(pool), WithSnapshotHub(NewSnapshotHub()))
+	if w.snapshotPool() != nil {
+		t.Errorf("Expected the snapshots shared with the hub never to be recycled")
+	}
+}
+
+func BenchmarkWorld_buildSnapshot(b *testing.B) {
+	for _, pooled := range []bool{false, true} {
+		name := "alloc"
+		if pooled {
+			name = "pooled"
+		}
+		b.Run(name, func(b *testing.B) {
+			w := newWorld(nil, DefaultConfig())
+			if pooled {
+				w.snapshots = NewSnapshotPool()
+			}
+			for i := 0; i < 5000; i++ {
+				w.addEntity(&Entity{ID: string(rune(i)), Color: pb.TeamColor_TEAM_BLUE})
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w.snapshots.Put(w.buildSnapshot(nil))
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package simulation
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// SnapshotView is a read-only view of the latest snapshot of a world that any goroutine can read
+// without sending a message to the engine: HTTP handlers, metrics exporters, embedding applications.
+// Reading is a single atomic load, the world never waits for the readers.
+//
+// Every published snapshot gets the next generation number, which keeps growing across the
+// restarts of the world (unlike the tick): a reader compares generations to know whether
+// the state changed since its last read. The snapshots are shared: treat them as read-only.
+type SnapshotView struct {
+	current atomic.Pointer[viewState]
+}
+
+// viewState is one published snapshot, 'next' is closed once a newer one replaced it
+type viewState struct {
+	snap       *pb.WorldSnapshot
+	generation uint64
+	next       chan struct{}
+}
+
+// NewSnapshotView creates a view of generation 0, with an empty snapshot until the first publication
+func NewSnapshotView() *SnapshotView {
+	v := &SnapshotView{}
+	v.current.Store(&viewState{snap: &pb.WorldSnapshot{}, next: make(chan struct{})})
+	return v
+}
+
+// WithSnapshotView makes the world publish every snapshot to the view. Like those of a SnapshotHub,
+// they are never recycled and list every entity whatever the viewport of the Game.
+func WithSnapshotView(view *SnapshotView) WorldOption {
+	return func(w *world) {
+		w.view = view
+	}
+}
+
+// Load returns the latest snapshot and its generation
+func (v *SnapshotView) Load() (*pb.WorldSnapshot, uint64) {
+	s := v.current.Load()
+	return s.snap, s.generation
+}
+
+// Snapshot returns the latest snapshot (empty before the first publication)
+func (v *SnapshotView) Snapshot() *pb.WorldSnapshot {
+	return v.current.Load().snap
+}
+
+// Generation returns the number of snapshots published so far
+func (v *SnapshotView) Generation() uint64 {
+	return v.current.Load().generation
+}
+
+// Wait blocks until a snapshot newer than generation 'after' is published, then returns the latest one.
+// It returns at once when the view is already past 'after', and the context error when it is done first.
+func (v *SnapshotView) Wait(ctx context.Context, after uint64) (*pb.WorldSnapshot, uint64, error) {
+	for {
+		s := v.current.Load()
+		if s.generation > after {
+			return s.snap, s.generation, nil
+		}
+		select {
+		case <-s.next:
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+// publish replaces the latest snapshot. A restarted world may overlap with the last tick
+// of the previous one: the generations stay unique and the waiters are woken once.
+func (v *SnapshotView) publish(snap *pb.WorldSnapshot) {
+	for {
+		prev := v.current.Load()
+		next := &viewState{snap: snap, generation: prev.generation + 1, next: make(chan struct{})}
+		if v.current.CompareAndSwap(prev, next) {
+			close(prev.next)
+			return
+		}
+	}
+}
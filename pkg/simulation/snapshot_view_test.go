@@ -0,0 +1,84 @@
+package simulation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestSnapshotView_Wait(t *testing.T) {
+	view := NewSnapshotView()
+	if snap, gen := view.Load(); snap == nil || gen != 0 {
+		t.Fatalf("Expected an empty snapshot of generation 0, got %v %d", snap, gen)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := view.Wait(ctx, 0); err == nil {
+		t.Error("Expected Wait to time out before the first publication")
+	}
+
+	done := make(chan uint64)
+	go func() {
+		_, gen, _ := view.Wait(context.Background(), 0)
+		done <- gen
+	}()
+	snap := &pb.WorldSnapshot{Tick: 7}
+	view.publish(snap)
+	if gen := <-done; gen != 1 {
+		t.Errorf("Expected Wait to return generation 1, got %d", gen)
+	}
+	if view.Snapshot() != snap || view.Generation() != 1 {
+		t.Errorf("Expected the published snapshot, got tick %d generation %d", view.Snapshot().GetTick(), view.Generation())
+	}
+}
+
+func TestSnapshotView_concurrentReaders(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 3
+	cfg.NumBlueAtStart = 10
+	cfg.Seed = 5
+	view := NewSnapshotView()
+	runner, err := NewRunner(ctx, cfg, WithEngine(NewLocalEngine),
+		WithWorldOptions(WithSnapshotPool(NewSnapshotPool()), WithSnapshotView(view)))
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	defer runner.Stop(ctx)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			var last uint64
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				snap, gen := view.Load()
+				if gen < last {
+					t.Errorf("Generation went back from %d to %d", last, gen)
+					return
+				}
+				// Never recycled while a reader holds it
+				_ = len(snap.GetActors())
+				last = gen
+			}
+		})
+	}
+	for range 50 {
+		if _, err := runner.Step(ctx); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if view.Generation() != 50 || view.Snapshot().GetTick() != 50 {
+		t.Errorf("Expected generation and tick 50, got %d and %d", view.Generation(), view.Snapshot().GetTick())
+	}
+}
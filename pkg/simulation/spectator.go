@@ -0,0 +1,112 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+)
+
+// Spectator is an Ebiten game showing a simulation running elsewhere, e.g. streamed by the
+// SwarmObserver gRPC service of another process (see Watch). The snapshots go through an
+// Interpolator, so the view stays smooth when they arrive late or are dropped on the way.
+type Spectator struct {
+	cfg    *Config
+	interp *Interpolator
+	trails *Trails
+	frame  *pb.WorldSnapshot
+
+	mu       sync.Mutex
+	received uint64
+	err      error
+}
+
+// NewSpectator shows the snapshots 'delay' snapshots behind the newest one received
+// (DefaultInterpolationDelay when <= 0), cfg gives the size of the world and the display options
+func NewSpectator(cfg *Config, delay int) *Spectator {
+	s := &Spectator{
+		cfg:    cfg,
+		interp: NewInterpolator(delay),
+		trails: NewTrails(DefaultMaxTrailPoints),
+		frame:  &pb.WorldSnapshot{},
+	}
+	s.trails.SetViewport(0, 0, cfg.WorldWidth, cfg.WorldHeight)
+	return s
+}
+
+// Push adds a snapshot received from the remote simulation
+func (s *Spectator) Push(snap *pb.WorldSnapshot) {
+	s.interp.Push(snap, time.Now())
+	s.mu.Lock()
+	s.received++
+	s.mu.Unlock()
+}
+
+// Watch streams the snapshots of 'client' (one every 'everyNTicks' ticks) into the spectator
+// until the stream ends or ctx is canceled. The error is also shown on screen.
+func (s *Spectator) Watch(ctx context.Context, client pb.SwarmObserverClient, everyNTicks int32) error {
+	err := s.watch(ctx, client, everyNTicks)
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Spectator) watch(ctx context.Context, client pb.SwarmObserverClient, everyNTicks int32) error {
+	stream, err := client.StreamSnapshots(ctx, &pb.StreamRequest{EveryNTicks: everyNTicks})
+	if err != nil {
+		return fmt.Errorf("cannot open the snapshot stream: %w", err)
+	}
+	for {
+		snap, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("snapshot stream interrupted: %w", err)
+		}
+		s.Push(snap)
+	}
+}
+
+func (s *Spectator) Update() error {
+	if frame := s.interp.Frame(time.Now()); frame != nil {
+		s.frame = frame
+		s.trails.Update(frame)
+	}
+	return nil
+}
+
+func (s *Spectator) Draw(screen *ebiten.Image) {
+	DrawWorld(ebitenRenderer{screen}, s.frame, s.trails, WorldDrawOptions{
+		ShowDetection:   s.cfg.DisplayDetectionCircle,
+		DetectionRadius: s.cfg.DetectionRadius,
+		ShowDefense:     s.cfg.DisplayDefenseCircle,
+		DefenseRadius:   s.cfg.DefenseRadius,
+	})
+	drawPopulationBar(screen, s.frame, ui.NewLayout(s.cfg.WorldWidth, s.cfg.WorldHeight, overlayMargin))
+
+	s.mu.Lock()
+	received, err := s.received, s.err
+	s.mu.Unlock()
+	msg := fmt.Sprintf("Spectating - tick %d\nReceived: %d\nBuffered: %d", s.frame.GetTick(), received, s.interp.Len())
+	switch {
+	case err != nil:
+		msg += "\n" + err.Error()
+	case received == 0:
+		msg += "\nWaiting for the simulation..."
+	}
+	printOverlay(screen, msg, 10, 10)
+	if s.frame.GetIsGameOver() {
+		drawGameOver(screen, s.frame.GetWinner(), s.cfg.WorldWidth, s.cfg.WorldHeight)
+	}
+}
+
+func (s *Spectator) Layout(w, h int) (int, int) {
+	return int(s.cfg.WorldWidth), int(s.cfg.WorldHeight)
+}
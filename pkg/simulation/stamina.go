@@ -0,0 +1,44 @@
+package simulation
+
+import "github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+
+const (
+	// sprintSpeed is the fraction of MaxSpeed above which a red spends its stamina
+	sprintSpeed = 0.8
+	// staminaRecovery is how fast a red below sprintSpeed recovers, relative to how fast it tires
+	staminaRecovery = 0.5
+	// exhaustedSpeed is the fraction of MaxSpeed an exhausted red cannot exceed
+	exhaustedSpeed = 0.5
+	// restedFatigue is the fatigue under which an exhausted red sprints again
+	restedFatigue = 0.5
+)
+
+// coolingDown reports whether the red 'e' attacked less than AttackCooldown ticks ago
+func (w *world) coolingDown(e *Entity) bool {
+	return w.cfg.AttackCooldown > 0 && e.attackTick > 0 && w.tick < e.attackTick+uint64(w.cfg.AttackCooldown)
+}
+
+// tire spends the stamina of a red sprinting above sprintSpeed, all of it after RedStamina ticks at
+// full speed, and lets it recover below. An exhausted red cannot exceed exhaustedSpeed until its
+// fatigue drops under restedFatigue.
+func tire(me *Entity, cfg *Config) {
+	if cfg.RedStamina == 0 || me.Color != pb.TeamColor_TEAM_RED {
+		return
+	}
+	step := me.DeltaTime() / float64(cfg.RedStamina)
+	// An exhausted red is slowed down below: it rests whatever its strategy asks
+	if !me.exhausted && me.Vel.Len() > cfg.MaxSpeed*sprintSpeed {
+		me.Fatigue = min(me.Fatigue+step, 1)
+	} else {
+		me.Fatigue = max(me.Fatigue-step*staminaRecovery, 0)
+	}
+	switch {
+	case me.Fatigue >= 1:
+		me.exhausted = true
+	case me.Fatigue < restedFatigue:
+		me.exhausted = false
+	}
+	if me.exhausted {
+		me.ClampVelocity(0, cfg.MaxSpeed*exhaustedSpeed)
+	}
+}
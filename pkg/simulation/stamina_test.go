@@ -0,0 +1,54 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestTire(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RedStamina = 4
+	red := &Entity{Color: pb.TeamColor_TEAM_RED}
+	sprint := func() {
+		red.Vel = geometry.Vector2D{X: cfg.MaxSpeed}
+		tire(red, cfg)
+	}
+	for range 3 {
+		sprint()
+	}
+	if red.exhausted || red.Vel.X != cfg.MaxSpeed {
+		t.Fatalf("Expected the red to sprint for RedStamina ticks, got fatigue %v", red.Fatigue)
+	}
+	sprint()
+	if !red.exhausted || red.Vel.Len() > cfg.MaxSpeed*exhaustedSpeed+1e-9 {
+		t.Fatalf("Expected the red exhausted and slowed down, got %v at fatigue %v", red.Vel, red.Fatigue)
+	}
+	// Exhausted, it rests whatever its strategy asks, until it recovered half of its stamina
+	for range 3 {
+		sprint()
+	}
+	if !red.exhausted {
+		t.Fatalf("Expected the red still exhausted, fatigue %v", red.Fatigue)
+	}
+	sprint()
+	sprint()
+	if red.exhausted {
+		t.Errorf("Expected the red rested, fatigue %v", red.Fatigue)
+	}
+
+	blue := &Entity{Color: pb.TeamColor_TEAM_BLUE, Vel: geometry.Vector2D{X: cfg.MaxSpeed}}
+	for range 10 {
+		tire(blue, cfg)
+	}
+	if blue.Fatigue != 0 || blue.Vel.X != cfg.MaxSpeed {
+		t.Errorf("Expected the blues never to tire, got %v", blue.Fatigue)
+	}
+
+	bad := *cfg
+	bad.Engine = EngineECS
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected the stamina to be rejected by the ecs engine")
+	}
+}
@@ -0,0 +1,202 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Names of the built-in behaviors
+const (
+	StrategyClassicHunter = "classic-hunter" // Red default: chase the closest target
+	StrategyPackHunter    = "pack-hunter"    // Red: stay in pack and chase the prey closest to the pack
+	StrategyClassicBoids  = "classic-boids"  // Blue default: Reynolds' boids flocking
+)
+
+// Behavior moves an entity for one tick, based on what it currently perceives.
+// Implementations may keep per-individual state: a new instance is created for every individual.
+type Behavior interface {
+	Update(me *Entity, perception *pb.Perception, cfg *Config)
+}
+
+// BehaviorFactory creates a fresh Behavior instance
+type BehaviorFactory func() Behavior
+
+// BehaviorResolver creates a Behavior from the argument of a prefixed name,
+// e.g. the resolver registered for "scripted" receives "lua-file" for "scripted:lua-file"
+type BehaviorResolver func(arg string) (Behavior, error)
+
+type behaviorEntry struct {
+	team    pb.TeamColor // TEAM_UNSPECIFIED means usable by both teams
+	factory BehaviorFactory
+}
+
+var (
+	behaviorsMu       sync.RWMutex
+	behaviors         = make(map[string]behaviorEntry)
+	behaviorResolvers = make(map[string]BehaviorResolver)
+)
+
+func init() {
+	RegisterBehavior(StrategyClassicHunter, pb.TeamColor_TEAM_RED, func() Behavior { return &ClassicHunter{} })
+	RegisterBehavior(StrategyPackHunter, pb.TeamColor_TEAM_RED, func() Behavior { return &PackHunter{} })
+	RegisterBehavior(StrategyClassicBoids, pb.TeamColor_TEAM_BLUE, func() Behavior { return &ClassicBoids{} })
+}
+
+// RegisterBehavior adds (or replaces) a named behavior in the registry.
+// 'team' is only a hint for the UI, use TEAM_UNSPECIFIED for behaviors suited to both teams.
+func RegisterBehavior(name string, team pb.TeamColor, factory BehaviorFactory) {
+	behaviorsMu.Lock()
+	defer behaviorsMu.Unlock()
+	behaviors[name] = behaviorEntry{team: team, factory: factory}
+}
+
+// RegisterBehaviorResolver registers a resolver for names of the form "prefix:argument"
+func RegisterBehaviorResolver(prefix string, resolver BehaviorResolver) {
+	behaviorsMu.Lock()
+	defer behaviorsMu.Unlock()
+	behaviorResolvers[prefix] = resolver
+}
+
+// NewBehavior creates a Behavior by name, using the registered resolvers for "prefix:argument" names
+func NewBehavior(name string) (Behavior, error) {
+	behaviorsMu.RLock()
+	entry, ok := behaviors[name]
+	var resolver BehaviorResolver
+	prefix, arg, hasPrefix := strings.Cut(name, ":")
+	if hasPrefix {
+		resolver = behaviorResolvers[prefix]
+	}
+	behaviorsMu.RUnlock()
+
+	if ok {
+		return entry.factory(), nil
+	}
+	if resolver != nil {
+		return resolver(arg)
+	}
+	return nil, fmt.Errorf("unknown behavior %q", name)
+}
+
+// BehaviorNames returns the sorted names of the behaviors suited to 'team'
+func BehaviorNames(team pb.TeamColor) []string {
+	behaviorsMu.RLock()
+	defer behaviorsMu.RUnlock()
+	names := make([]string, 0, len(behaviors))
+	for name, entry := range behaviors {
+		if entry.team == pb.TeamColor_TEAM_UNSPECIFIED || entry.team == team {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultStrategy returns the behavior used by a team when the config does not specify one
+func DefaultStrategy(team pb.TeamColor) string {
+	if team == pb.TeamColor_TEAM_RED {
+		return StrategyClassicHunter
+	}
+	return StrategyClassicBoids
+}
+
+// ============================================================================
+// Built-in Behaviors
+// ============================================================================
+
+// ClassicHunter chases the closest visible target and wanders randomly otherwise. It steers around
+// the obstacles, and finds its way to a target hidden behind them.
+type ClassicHunter struct {
+	path huntPath
+}
+
+func (h *ClassicHunter) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	me.ApplyForce(behavior.AvoidObstacles(me.Pos, me.Vel, cfg.ObstacleList(), cfg.ObstacleReach()))
+	targets := perception.GetTargets()
+	if len(targets) > 0 {
+		chaseClosest(me, targets, me.Pos, cfg, &h.path)
+	} else {
+		wander(me)
+	}
+	me.UpdatePhysics() // Pos += Vel
+	me.BounceOffWalls(cfg.WorldWidth, cfg.WorldHeight)
+}
+
+// PackHunter keeps close to the other visible hunters (boids cohesion and alignment)
+// and chases the prey closest to the center of the pack, so the reds tend to converge on the same victim.
+type PackHunter struct {
+	path huntPath
+}
+
+func (h *PackHunter) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	friends := perception.GetFriends()
+	targets := perception.GetTargets()
+
+	// Pack center (including myself)
+	center := me.Pos
+	for _, f := range friends {
+		center = center.Add(GeomVector2DFromProto(f.Position))
+	}
+	center = center.Mul(1 / float64(len(friends)+1))
+
+	// Stay together: reuse the boids rules without the separation term being dominant
+	me.ApplyForce(ComputeBoidUpdate(me, friends, cfg))
+
+	if len(targets) > 0 {
+		chaseClosest(me, targets, center, cfg, &h.path)
+	} else {
+		wander(me)
+	}
+	me.ClampVelocity(0, cfg.MaxSpeed)
+	me.UpdatePhysics()
+	me.BounceOffWalls(cfg.WorldWidth, cfg.WorldHeight)
+}
+
+// ClassicBoids applies Reynolds' flocking rules and soft boundaries
+type ClassicBoids struct{}
+
+func (b *ClassicBoids) Update(me *Entity, perception *pb.Perception, cfg *Config) {
+	// Apply boids flocking rules
+	force := ComputeBoidUpdate(me, perception.GetFriends(), cfg)
+
+	me.ApplyForce(force)
+	me.SoftBoundaries(cfg.WorldWidth, cfg.WorldHeight, cfg.TurnFactor)
+	me.ClampVelocity(cfg.MinSpeed, cfg.MaxSpeed)
+	me.UpdatePhysics()
+}
+
+// wander adds a small random jitter to the velocity
+func wander(me *Entity) {
+	me.ApplyForce(behavior.Wander(me.Float64, 0.15))
+}
+
+// chaseClosest steers 'me' toward the target closest to 'from', along 'path' when the obstacles
+// hide it, and caps the speed
+func chaseClosest(me *Entity, targets []*pb.ActorState, from geometry.Vector2D, cfg *Config, path *huntPath) {
+	// Find nearest enemy
+	var closest *pb.ActorState
+	minDistSq := math.MaxFloat64
+
+	for _, target := range targets {
+		distSq := from.DistanceSquaredTo(GeomVector2DFromProto(target.Position))
+
+		if distSq < minDistSq {
+			minDistSq = distSq
+			closest = target
+		}
+	}
+
+	if closest == nil {
+		return
+	}
+
+	// Pulled toward it, the farther the stronger, then capped at max speed
+	me.ApplyForce(behavior.Attract(me.Pos, path.aim(me, GeomVector2DFromProto(closest.Position), cfg)))
+	me.Vel = behavior.Truncate(me.Vel, cfg.MaxSpeed)
+}
@@ -0,0 +1,78 @@
+package simulation
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestNewBehavior(t *testing.T) {
+	if _, err := NewBehavior(StrategyClassicBoids); err != nil {
+		t.Errorf("Expected built-in %s to be registered, got %v", StrategyClassicBoids, err)
+	}
+	if _, err := NewBehavior("does-not-exist"); err == nil {
+		t.Error("Expected an error for an unknown behavior")
+	}
+
+	// Prefixed names are handled by resolvers
+	var gotArg string
+	RegisterBehaviorResolver("test", func(arg string) (Behavior, error) {
+		gotArg = arg
+		return &ClassicHunter{}, nil
+	})
+	if _, err := NewBehavior("test:my-file"); err != nil {
+		t.Errorf("Expected resolver to create the behavior, got %v", err)
+	}
+	if gotArg != "my-file" {
+		t.Errorf("Expected resolver argument my-file, got %q", gotArg)
+	}
+}
+
+func TestBehaviorNames(t *testing.T) {
+	red := BehaviorNames(pb.TeamColor_TEAM_RED)
+	if !slices.Contains(red, StrategyClassicHunter) || !slices.Contains(red, StrategyPackHunter) {
+		t.Errorf("Expected red strategies to contain the hunters, got %v", red)
+	}
+	if slices.Contains(red, StrategyClassicBoids) {
+		t.Errorf("Did not expect %s in red strategies", StrategyClassicBoids)
+	}
+}
+
+func TestConfig_StrategyFor(t *testing.T) {
+	cfg := &Config{RedStrategy: StrategyPackHunter}
+	if got := cfg.StrategyFor(pb.TeamColor_TEAM_RED); got != StrategyPackHunter {
+		t.Errorf("Expected %s, got %s", StrategyPackHunter, got)
+	}
+	if got := cfg.StrategyFor(pb.TeamColor_TEAM_BLUE); got != StrategyClassicBoids {
+		t.Errorf("Expected default %s, got %s", StrategyClassicBoids, got)
+	}
+}
+
+func TestBehaviors_scaleWithDeltaTime(t *testing.T) {
+	cfg := DefaultConfig()
+	// A lone boid far from the borders drifts at constant speed: one tick of 2 nominal ticks
+	// covers the distance of two nominal ticks
+	start := func() *Entity {
+		return &Entity{Color: pb.TeamColor_TEAM_BLUE, Pos: geometry.Vector2D{X: cfg.WorldWidth / 2, Y: cfg.WorldHeight / 2}, Vel: geometry.Vector2D{X: cfg.MinSpeed}}
+	}
+	boids := &ClassicBoids{}
+
+	nominal := start()
+	for range 2 {
+		nominal.dt = tickScale(0)
+		boids.Update(nominal, &pb.Perception{}, cfg)
+	}
+	double := start()
+	double.dt = tickScale(int64(2 * TickDuration))
+	boids.Update(double, &pb.Perception{}, cfg)
+
+	if math.Abs(nominal.Pos.X-double.Pos.X) > 1e-9 || nominal.Pos.Y != double.Pos.Y {
+		t.Errorf("Expected the same position at any tick rate, got %v and %v", nominal.Pos, double.Pos)
+	}
+	if double.DeltaTime() != 2 || (&Entity{}).DeltaTime() != 1 {
+		t.Errorf("Unexpected time steps %v and %v", double.DeltaTime(), (&Entity{}).DeltaTime())
+	}
+}
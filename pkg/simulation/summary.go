@@ -0,0 +1,145 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// summaryTimelineSize is the most samples of the population kept by a RunSummary for the whole run
+const summaryTimelineSize = 512
+
+// RunStats are the statistics of a whole run, see RunSummary
+type RunStats struct {
+	Ticks uint64 `json:"ticks"`
+	// Seconds of simulation time, at SimTicksPerSecond
+	Seconds float64 `json:"seconds"`
+	// ToRed and ToBlue count the conversions by the team the converted entity joined
+	ToRed  int `json:"conversionsToRed"`
+	ToBlue int `json:"conversionsToBlue"`
+	// CapturedByRed and CapturedByBlue count the civilians each team captured (see Config.NumNeutralAtStart)
+	CapturedByRed  int    `json:"capturedByRed,omitempty"`
+	CapturedByBlue int    `json:"capturedByBlue,omitempty"`
+	PeakRed        int32  `json:"peakRed"`
+	PeakRedTick    uint64 `json:"peakRedTick"`
+	PeakBlue       int32  `json:"peakBlue"`
+	PeakBlueTick   uint64 `json:"peakBlueTick"`
+	Winner         string `json:"winner,omitempty"`
+	// Timeline is the population from the start to the end of the run, at most summaryTimelineSize
+	// samples evenly spread (ConversionRate is not set)
+	Timeline []PopulationSample `json:"timeline"`
+}
+
+// Len and At give the timeline to the population chart
+func (s RunStats) Len() int                  { return len(s.Timeline) }
+func (s RunStats) At(i int) PopulationSample { return s.Timeline[i] }
+
+// RunSummary accumulates the statistics of a whole run for the post-game summary of the Game:
+// it is the EventSink of the conversions, Observe takes the snapshots. Unlike PopulationHistory
+// its timeline covers the whole run: every time it fills up, every other sample is dropped.
+type RunSummary struct {
+	mu       sync.Mutex
+	stats    RunStats
+	started  bool
+	first    uint64 // Tick of the first snapshot
+	interval uint64 // Ticks between two samples of the timeline
+}
+
+// NewRunSummary returns an empty summary
+func NewRunSummary() *RunSummary {
+	return &RunSummary{interval: 1, stats: RunStats{Timeline: make([]PopulationSample, 0, summaryTimelineSize)}}
+}
+
+func (s *RunSummary) HandleEvent(e Event) {
+	if e.Kind != EventConversion {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	red, blue := e.Color == teamLabel(pb.TeamColor_TEAM_RED), e.Color == teamLabel(pb.TeamColor_TEAM_BLUE)
+	switch {
+	case e.From == teamLabel(NeutralColor) && red:
+		s.stats.CapturedByRed++
+	case e.From == teamLabel(NeutralColor) && blue:
+		s.stats.CapturedByBlue++
+	case red:
+		s.stats.ToRed++
+	case blue:
+		s.stats.ToBlue++
+	}
+}
+
+// Observe records the population of a snapshot, snapshots may be skipped
+func (s *RunSummary) Observe(snap *pb.WorldSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &s.stats
+	if !s.started {
+		s.started, s.first = true, snap.Tick
+	}
+	st.Ticks = snap.Tick - s.first
+	st.Seconds = float64(st.Ticks) / SimTicksPerSecond
+	if snap.RedCount > st.PeakRed {
+		st.PeakRed, st.PeakRedTick = snap.RedCount, snap.Tick
+	}
+	if snap.BlueCount > st.PeakBlue {
+		st.PeakBlue, st.PeakBlueTick = snap.BlueCount, snap.Tick
+	}
+	if snap.IsGameOver {
+		st.Winner = snap.Winner
+	}
+
+	sample := PopulationSample{Tick: snap.Tick, Red: snap.RedCount, Blue: snap.BlueCount}
+	// The final state is always kept, once
+	if n := len(st.Timeline); n > 0 {
+		last := st.Timeline[n-1].Tick
+		if snap.Tick == last || snap.Tick < last+s.interval && !snap.IsGameOver {
+			return
+		}
+	}
+	if len(st.Timeline) == summaryTimelineSize {
+		// Keep every other sample, twice as far apart
+		for i := 0; 2*i < len(st.Timeline); i++ {
+			st.Timeline[i] = st.Timeline[2*i]
+		}
+		st.Timeline = st.Timeline[:(len(st.Timeline)+1)/2]
+		s.interval *= 2
+	}
+	st.Timeline = append(st.Timeline, sample)
+}
+
+// Stats returns a copy of the statistics so far
+func (s *RunSummary) Stats() RunStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Timeline = append([]PopulationSample(nil), s.stats.Timeline...)
+	return stats
+}
+
+// Reset forgets the run, for a restart
+func (s *RunSummary) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = RunStats{Timeline: s.stats.Timeline[:0]}
+	s.started, s.first, s.interval = false, 0, 1
+}
+
+// SaveRunStats writes the statistics as indented JSON to 'path', creating its directory
+func SaveRunStats(path string, stats RunStats) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cannot create stats directory: %w", err)
+	}
+	b, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cannot write stats: %w", err)
+	}
+	return nil
+}
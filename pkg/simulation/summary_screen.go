@@ -0,0 +1,144 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui/text"
+)
+
+// Size of the post-game summary, before the overlay scale
+const (
+	summaryWidth        = 460.0
+	summaryHeight       = 390.0
+	summaryPadding      = 16.0
+	summaryChartHeight  = 130.0
+	summaryButtonWidth  = 140.0
+	summaryButtonHeight = 30.0
+	// summaryFile is the name of the exported statistics, in the directory of the run
+	summaryFile = "summary.json"
+)
+
+var (
+	summaryBackground = color.RGBA{R: 15, G: 15, B: 25, A: 235}
+	summaryBorder     = color.RGBA{R: 255, G: 220, B: 80, A: 255}
+)
+
+// summaryScreen is the post-game summary of the Game, shown at the game over: duration, conversions
+// per direction, peak populations and the population of the whole run, with Restart and Export buttons
+type summaryScreen struct {
+	restartButton *ui.Button
+	exportButton  *ui.Button // nil in the browser
+	rect          image.Rectangle
+	exported      string // File of the last export of the run
+}
+
+// newSummaryScreen creates the buttons of the summary, they restart the game and export its statistics
+func newSummaryScreen(g *Game) summaryScreen {
+	s := summaryScreen{
+		restartButton: ui.NewButton(0, 0, scaled(summaryButtonWidth), scaled(summaryButtonHeight), "Restart", func() {
+			g.restartRequested = true
+		}),
+	}
+	if canWriteFiles {
+		s.exportButton = ui.NewButton(0, 0, scaled(summaryButtonWidth), scaled(summaryButtonHeight), "Export stats", g.exportStats)
+	}
+	return s
+}
+
+// summaryVisible reports whether the summary is shown: once the game is over
+func (g *Game) summaryVisible() bool {
+	return g.lastState.GetIsGameOver()
+}
+
+// updateSummary handles the buttons of the summary, placed by the last Draw
+func (g *Game) updateSummary() {
+	s := &g.summaryScreen
+	if !g.summaryVisible() || s.rect.Empty() {
+		return
+	}
+	s.restartButton.Update()
+	if s.exportButton != nil {
+		s.exportButton.Update()
+	}
+}
+
+// exportStats writes the statistics of the run to the summary file of its directory of the captures
+func (g *Game) exportStats() {
+	path := filepath.Join(runDir(g.runID), summaryFile)
+	if err := SaveRunStats(path, g.summary.Stats()); err != nil {
+		g.engine.Logger().Errorf("Cannot export stats: %v", err)
+		return
+	}
+	g.summaryScreen.exported = path
+	g.engine.Logger().Infof("Stats exported to %s", path)
+}
+
+// drawSummary draws the post-game summary at the center of the screen
+func (g *Game) drawSummary(screen *ebiten.Image) {
+	s := &g.summaryScreen
+	s.rect = image.Rectangle{}
+	if !g.summaryVisible() {
+		return
+	}
+	stats := g.summary.Stats()
+	w, h, pad := scaled(summaryWidth), scaled(summaryHeight), scaled(summaryPadding)
+	x, y := g.layout.Place(ui.AnchorCenter, w, h)
+	s.rect = image.Rect(int(x), int(y), int(x+w), int(y+h))
+	vector.FillRect(screen, float32(x), float32(y), float32(w), float32(h), summaryBackground, true)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 2, summaryBorder, true)
+
+	title := text.Options{Size: scaled(28), Align: text.AlignCenter, Color: summaryBorder}
+	top := y + pad
+	text.Draw(screen, "GAME OVER", x+w/2, top, title)
+	top += text.LineHeight(title.Size)
+	winner := text.Options{Size: scaled(16), Align: text.AlignCenter}
+	text.Draw(screen, g.lastState.GetWinner()+" is the WINNER !", x+w/2, top, winner)
+	top += text.LineHeight(winner.Size) + pad/2
+
+	duration := time.Duration(stats.Seconds * float64(time.Second)).Round(time.Second)
+	msg := fmt.Sprintf("Duration     %s (%d ticks)\nConversions  %d → blue, %d → red\nPeak red     %d at tick %d\nPeak blue    %d at tick %d",
+		duration, stats.Ticks, stats.ToBlue, stats.ToRed, stats.PeakRed, stats.PeakRedTick, stats.PeakBlue, stats.PeakBlueTick)
+	if stats.CapturedByRed+stats.CapturedByBlue > 0 {
+		msg += fmt.Sprintf("\nCivilians    %d → blue, %d → red", stats.CapturedByBlue, stats.CapturedByRed)
+	}
+	printOverlay(screen, msg, x+pad, top)
+	_, msgH := text.Measure(msg, overlayText)
+	top += msgH + pad/2
+
+	chartH := scaled(summaryChartHeight)
+	drawPopulationChart(screen, stats, float32(x+pad), float32(top), float32(w-2*pad), float32(chartH), false)
+
+	// Buttons at the bottom, the export path above them
+	buttons := []*ui.Button{s.restartButton}
+	if s.exportButton != nil {
+		buttons = append(buttons, s.exportButton)
+	}
+	bw, bh := scaled(summaryButtonWidth), scaled(summaryButtonHeight)
+	bx := x + w/2 - (float64(len(buttons))*bw+float64(len(buttons)-1)*pad)/2
+	by := y + h - pad - bh
+	label := text.Options{Size: scaled(text.DefaultSize), Align: text.AlignCenter}
+	for _, b := range buttons {
+		b.X, b.Y = bx, by
+		b.Draw(screen)
+		label.Color = b.TextColor
+		text.Draw(screen, b.Label, b.X+b.Width/2, b.Y+(b.Height-text.LineHeight(label.Size))/2, label)
+		bx += bw + pad
+	}
+	note := ""
+	if t := g.tournament; t != nil && !t.Done() && !g.roundOver.IsZero() {
+		note = fmt.Sprintf("Round %d/%d in %.0fs", t.Played()+1, t.Rounds, (roundPause - time.Since(g.roundOver)).Seconds())
+	}
+	if s.exported != "" {
+		note = "Saved to " + s.exported
+	}
+	if note != "" {
+		printOverlay(screen, note, x+pad, by-text.LineHeight(overlayText.Size)-scaled(4))
+	}
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestRunSummary(t *testing.T) {
+	s := NewRunSummary()
+	s.HandleEvent(Event{Kind: EventConversion, Color: "RED"})
+	s.HandleEvent(Event{Kind: EventConversion, Color: "BLUE"})
+	s.HandleEvent(Event{Kind: EventConversion, Color: "BLUE"})
+	s.HandleEvent(Event{Kind: EventSpawn, Color: "RED"})
+	// Civilians captured: not conversions
+	s.HandleEvent(Event{Kind: EventConversion, Color: "BLUE", From: "NEUTRAL"})
+	s.HandleEvent(Event{Kind: EventConversion, Color: "RED", From: "NEUTRAL"})
+	s.HandleEvent(Event{Kind: EventConversion, Color: "RED", From: "NEUTRAL"})
+
+	// A run starting at tick 10, the red population peaks at tick 510
+	for tick := uint64(10); tick <= 2010; tick++ {
+		red := int32(1000 - max(int64(tick)-510, 510-int64(tick)))
+		s.Observe(&pb.WorldSnapshot{Tick: tick, RedCount: red, BlueCount: 5})
+	}
+	over := &pb.WorldSnapshot{Tick: 2011, RedCount: 0, BlueCount: 40, IsGameOver: true, Winner: "Blue"}
+	s.Observe(over)
+	s.Observe(over)
+
+	stats := s.Stats()
+	if stats.ToRed != 1 || stats.ToBlue != 2 {
+		t.Errorf("Expected 1 conversion to red and 2 to blue, got %d and %d", stats.ToRed, stats.ToBlue)
+	}
+	if stats.CapturedByRed != 2 || stats.CapturedByBlue != 1 {
+		t.Errorf("Expected 2 civilians captured by red and 1 by blue, got %d and %d", stats.CapturedByRed, stats.CapturedByBlue)
+	}
+	if stats.Ticks != 2001 || stats.Seconds != 2001.0/SimTicksPerSecond || stats.Winner != "Blue" {
+		t.Errorf("Expected 2001 ticks won by Blue, got %+v", stats)
+	}
+	if stats.PeakRed != 1000 || stats.PeakRedTick != 510 || stats.PeakBlue != 40 || stats.PeakBlueTick != 2011 {
+		t.Errorf("Unexpected peaks %+v", stats)
+	}
+	n := stats.Len()
+	if n > summaryTimelineSize || n < summaryTimelineSize/2 {
+		t.Fatalf("Expected between %d and %d samples, got %d", summaryTimelineSize/2, summaryTimelineSize, n)
+	}
+	if first, last := stats.At(0), stats.At(n-1); first.Tick != 10 || last.Tick != 2011 || last.Blue != 40 {
+		t.Errorf("Expected the timeline from the first to the final state, got %+v ... %+v", first, last)
+	}
+	for i := 1; i < n; i++ {
+		if stats.At(i).Tick <= stats.At(i-1).Tick {
+			t.Fatalf("Timeline not in order at %d: %d after %d", i, stats.At(i).Tick, stats.At(i-1).Tick)
+		}
+	}
+
+	s.Reset()
+	if stats := s.Stats(); stats.Len() != 0 || stats.ToBlue != 0 || stats.PeakRed != 0 {
+		t.Errorf("Expected an empty summary after Reset, got %+v", stats)
+	}
+}
+
+func TestRunSummary_captures(t *testing.T) {
+	summary := NewRunSummary()
+	_, s := newScriptedWorld(combatConfig(), WithEventSink(summary))
+	s.report(redAt("Red-000", 100, 100), neutralAt("Neutral-000", 105, 100),
+		redAt("Red-001", 300, 300), blueAt("Blue-000", 305, 300))
+	s.tick()
+	if stats := summary.Stats(); stats.CapturedByRed != 1 || stats.ToRed != 1 {
+		t.Errorf("Expected the civilian captured by red apart from the conversion of the blue, got %+v", stats)
+	}
+}
+
+func TestSaveRunStats(t *testing.T) {
+	if !canWriteFiles {
+		t.Skip("No file in the browser")
+	}
+	path := filepath.Join(t.TempDir(), "run", summaryFile)
+	want := RunStats{Ticks: 60, Seconds: 1, ToBlue: 3, Winner: "Red", Timeline: []PopulationSample{{Tick: 1, Red: 2, Blue: 3}}}
+	if err := SaveRunStats(path, want); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got RunStats
+	if err := json.Unmarshal(b, &got); err != nil || got.ToBlue != 3 || got.Winner != "Red" || got.Len() != 1 {
+		t.Errorf("Expected the stats back, got %+v %v", got, err)
+	}
+}
@@ -0,0 +1,260 @@
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// SweepSpec describes a parameter sweep: every combination of the values of its parameters plays
+// Seeds rounds, round i with seed Seed+i, so that every combination faces the same initial layouts
+type SweepSpec struct {
+	Parameters []SweepParameter `json:"parameters"`
+	Seeds      int              `json:"seeds"`
+	Seed       uint64           `json:"seed"`
+	// MaxTicks ends a round without winner
+	MaxTicks uint64 `json:"maxTicks"`
+}
+
+// SweepParameter is a numeric Config field, by its JSON name, and the values it takes:
+// the ones listed in Values, or From to To (included) by Step
+type SweepParameter struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values,omitempty"`
+	From   float64   `json:"from,omitempty"`
+	To     float64   `json:"to,omitempty"`
+	Step   float64   `json:"step,omitempty"`
+}
+
+// LoadSweepSpec reads a sweep spec from a JSON file, Seeds and MaxTicks default to the ones of
+// DefaultSensitivityOptions
+func LoadSweepSpec(path string) (*SweepSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read sweep spec: %w", err)
+	}
+	defaults := DefaultSensitivityOptions()
+	spec := &SweepSpec{Seeds: defaults.Rounds, Seed: defaults.Seed, MaxTicks: defaults.MaxTicks}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("cannot parse sweep spec %s: %w", path, err)
+	}
+	return spec, spec.Validate()
+}
+
+// Validate checks that the parameters are numeric config fields with at least one value
+func (s *SweepSpec) Validate() error {
+	if len(s.Parameters) == 0 {
+		return fmt.Errorf("a sweep needs at least one parameter")
+	}
+	if s.Seeds <= 0 {
+		return fmt.Errorf("seeds (%d) must be positive", s.Seeds)
+	}
+	seen := make(map[string]bool, len(s.Parameters))
+	for _, p := range s.Parameters {
+		field, ok := configField(p.Name)
+		if !ok || !isNumeric(field.Type.Kind()) {
+			return fmt.Errorf("%q is not a numeric config parameter", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("%q is swept twice", p.Name)
+		}
+		seen[p.Name] = true
+		if _, err := p.values(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// values returns the values of the parameter, Values or the range From..To
+func (p SweepParameter) values() ([]float64, error) {
+	if len(p.Values) > 0 {
+		return p.Values, nil
+	}
+	if p.Step <= 0 || p.To < p.From {
+		return nil, fmt.Errorf("%s: needs values, or a range from <= to with a positive step", p.Name)
+	}
+	// The rounding error of the last step must not drop 'To'
+	n := int(math.Floor((p.To-p.From)/p.Step+1e-9)) + 1
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = p.From + float64(i)*p.Step
+	}
+	return values, nil
+}
+
+// Combinations returns every combination of the values of the parameters, in the order of
+// the parameters, the last one varying the fastest
+func (s *SweepSpec) Combinations() [][]float64 {
+	combos := [][]float64{nil}
+	for _, p := range s.Parameters {
+		values, _ := p.values()
+		next := make([][]float64, 0, len(combos)*len(values))
+		for _, combo := range combos {
+			for _, v := range values {
+				next = append(next, append(combo[:len(combo):len(combo)], v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// SweepOptions configures RunSweep
+type SweepOptions struct {
+	// Engine runs the rounds, NewLocalEngine (reproducible) when nil
+	Engine EngineFactory
+	// Workers is the number of rounds run in parallel, the number of CPUs when <= 0
+	Workers int
+	// OnResult is called after every round, from the goroutine of RunSweep (e.g. to show the progress)
+	OnResult func(done, total int)
+}
+
+// SweepResult is the outcome of one round of a sweep
+type SweepResult struct {
+	// Values of the parameters of the spec, in their order
+	Values []float64 `json:"values"`
+	Seed   uint64    `json:"seed"`
+	// Winner is ColorRed or ColorBlue, empty when the round reached MaxTicks
+	Winner  string  `json:"winner,omitempty"`
+	Ticks   uint64  `json:"ticks"`
+	Seconds float64 `json:"seconds"`
+	// ToRed and ToBlue count the conversions by the team the converted entity joined
+	ToRed  int   `json:"conversionsToRed"`
+	ToBlue int   `json:"conversionsToBlue"`
+	Red    int32 `json:"red"` // Population at the end of the round
+	Blue   int32 `json:"blue"`
+	// Error is set when the combination is not a valid config, the round was not played
+	Error string `json:"error,omitempty"`
+}
+
+// RunSweep plays the rounds of every combination of 'spec' headless, with the other parameters of
+// 'base', and returns their results grouped by combination (see SweepSpec.Combinations), then by seed
+func RunSweep(ctx context.Context, base *Config, spec *SweepSpec, opts SweepOptions) ([]SweepResult, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Engine == nil {
+		opts.Engine = NewLocalEngine
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	combos := spec.Combinations()
+	results := make([]SweepResult, 0, len(combos)*spec.Seeds)
+	var configs []*Config
+	for _, combo := range combos {
+		cfg := *base
+		for i, p := range spec.Parameters {
+			field, _ := configField(p.Name)
+			combo[i] = setFieldValue(&cfg, field, combo[i])
+		}
+		err := cfg.Validate()
+		for s := 0; s < spec.Seeds; s++ {
+			r := SweepResult{Values: combo, Seed: spec.Seed + uint64(s)}
+			if err != nil {
+				r.Error = err.Error()
+			}
+			results = append(results, r)
+			round := cfg
+			round.Seed = r.Seed
+			configs = append(configs, &round)
+		}
+	}
+
+	// The rounds of the invalid combinations are not played
+	var playable []int
+	for i := range results {
+		if results[i].Error == "" {
+			playable = append(playable, i)
+		}
+	}
+	err := runRounds(ctx, len(playable), opts.Workers, func(ctx context.Context, n int) error {
+		i := playable[n]
+		return playSweepRound(ctx, configs[i], spec.MaxTicks, opts.Engine, &results[i])
+	}, func(played int) {
+		if opts.OnResult != nil {
+			opts.OnResult(played, len(playable))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// playSweepRound plays one round of a sweep and fills its result
+func playSweepRound(ctx context.Context, cfg *Config, maxTicks uint64, engine EngineFactory, result *SweepResult) error {
+	summary := NewRunSummary()
+	runner, err := NewRunner(ctx, cfg, WithEngine(engine), WithWorldOptions(WithEventSink(summary)))
+	if err != nil {
+		return err
+	}
+	defer runner.Stop(ctx)
+	last, err := runner.Run(ctx, maxTicks, nil)
+	if err != nil {
+		return err
+	}
+	stats := summary.Stats()
+	result.Winner = last.GetWinner()
+	result.Ticks = runner.Tick()
+	result.Seconds = float64(result.Ticks) / SimTicksPerSecond
+	result.ToRed, result.ToBlue = stats.ToRed, stats.ToBlue
+	result.Red, result.Blue = last.GetRedCount(), last.GetBlueCount()
+	return nil
+}
+
+// WriteSweepCSV writes the results as a CSV table: one column per parameter of the spec, then the
+// seed, the winner (red, blue or none), the duration, the conversions and the final populations
+func WriteSweepCSV(w io.Writer, spec *SweepSpec, results []SweepResult) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(spec.Parameters)+9)
+	for _, p := range spec.Parameters {
+		header = append(header, p.Name)
+	}
+	header = append(header, "seed", "winner", "ticks", "seconds", "conversionsToRed", "conversionsToBlue", "red", "blue", "error")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range results {
+		row := make([]string, 0, len(header))
+		for _, v := range r.Values {
+			row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		row = append(row,
+			strconv.FormatUint(r.Seed, 10),
+			sweepWinner(r),
+			strconv.FormatUint(r.Ticks, 10),
+			strconv.FormatFloat(r.Seconds, 'f', 2, 64),
+			strconv.Itoa(r.ToRed),
+			strconv.Itoa(r.ToBlue),
+			strconv.Itoa(int(r.Red)),
+			strconv.Itoa(int(r.Blue)),
+			r.Error)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// sweepWinner names the winner of a round in the CSV table
+func sweepWinner(r SweepResult) string {
+	switch {
+	case r.Error != "":
+		return ""
+	case r.Winner == ColorRed:
+		return "red"
+	case r.Winner == ColorBlue:
+		return "blue"
+	}
+	return "none"
+}
@@ -0,0 +1,94 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+)
+
+func TestSweepSpec_Combinations(t *testing.T) {
+	spec := &SweepSpec{
+		Seeds: 1,
+		Parameters: []SweepParameter{
+			{Name: "aggression", From: 0.2, To: 0.6, Step: 0.2},
+			{Name: "numRedAtStart", Values: []float64{3, 5}},
+		},
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	combos := spec.Combinations()
+	if len(combos) != 6 {
+		t.Fatalf("Expected 3 x 2 combinations, got %v", combos)
+	}
+	if last := combos[5]; last[0] < 0.59 || last[1] != 5 {
+		t.Errorf("Expected the range to include its end, got %v", combos)
+	}
+
+	for _, invalid := range []*SweepSpec{
+		{Seeds: 1},
+		{Seeds: 1, Parameters: []SweepParameter{{Name: "redStrategy", Values: []float64{1}}}},
+		{Seeds: 1, Parameters: []SweepParameter{{Name: "aggression", From: 1, To: 0, Step: 0.1}}},
+		{Seeds: 1, Parameters: []SweepParameter{{Name: "aggression", Values: []float64{1}}, {Name: "aggression", Values: []float64{2}}}},
+		{Seeds: 0, Parameters: []SweepParameter{{Name: "aggression", Values: []float64{1}}}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", invalid)
+		}
+	}
+}
+
+func TestRunSweep(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 3
+	cfg.NumBlueAtStart = 10
+	cfg.LogLevel = "error"
+	spec := &SweepSpec{
+		Seeds:    2,
+		Seed:     7,
+		MaxTicks: 100,
+		Parameters: []SweepParameter{
+			{Name: "numRedAtStart", Values: []float64{2, 4}},
+			// A defense radius above the detection radius is not a valid config
+			{Name: "defenseRadius", Values: []float64{cfg.DetectionRadius / 2, cfg.DetectionRadius * 2}},
+		},
+	}
+	calls := 0
+	results, err := RunSweep(context.Background(), cfg, spec, SweepOptions{Workers: 2, OnResult: func(done, total int) {
+		calls++
+		if total != 4 {
+			t.Errorf("Expected 4 valid rounds, got %d", total)
+		}
+	}})
+	if err != nil {
+		t.Fatalf("RunSweep() error = %v", err)
+	}
+	if len(results) != 8 || calls != 4 {
+		t.Fatalf("Expected 8 results of which 4 played, got %d and %d", len(results), calls)
+	}
+	for i, r := range results {
+		invalid := i%4 >= 2
+		if invalid != (r.Error != "") {
+			t.Errorf("Result %d: unexpected error %q", i, r.Error)
+		}
+		if r.Seed != 7+uint64(i%2) {
+			t.Errorf("Result %d: expected seed %d, got %d", i, 7+i%2, r.Seed)
+		}
+		if !invalid && (r.Ticks == 0 || r.Red+r.Blue == 0) {
+			t.Errorf("Result %d was not played: %+v", i, r)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteSweepCSV(&out, spec, results); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 9 || rows[0][0] != "numRedAtStart" || rows[1][0] != "2" {
+		t.Errorf("Unexpected CSV:\n%v", rows)
+	}
+}
@@ -0,0 +1,254 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png" // Terrain images
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// terrainCell is the size of the cells of a terrain made of regions
+const terrainCell = 10.0
+
+// TerrainKind is the ground of a cell of the terrain
+type TerrainKind uint8
+
+// Kinds of ground of Config.Terrain
+const (
+	TerrainPlain   TerrainKind = iota // No modifier
+	TerrainMud                        // The entities crawl at half speed
+	TerrainHighway                    // The entities fly 1.5 times faster
+	TerrainIce                        // The entities hardly turn back at the edges
+	numTerrains
+)
+
+var terrainNames = [numTerrains]string{"plain", "mud", "highway", "ice"}
+
+func (k TerrainKind) String() string {
+	if k < numTerrains {
+		return terrainNames[k]
+	}
+	return "unknown"
+}
+
+// terrainEffects are the factors each kind of ground applies to MaxSpeed (and MinSpeed) and TurnFactor
+var terrainEffects = [numTerrains]struct{ speed, turn float64 }{
+	TerrainPlain:   {1, 1},
+	TerrainMud:     {0.5, 1},
+	TerrainHighway: {1.5, 1},
+	TerrainIce:     {1, 0.3},
+}
+
+// terrainColors draw the terrain, and give the kind of the pixels of a terrain image
+var terrainColors = [numTerrains]color.RGBA{
+	TerrainPlain:   {},
+	TerrainMud:     {R: 110, G: 80, B: 40, A: 255},
+	TerrainHighway: {R: 120, G: 120, B: 130, A: 255},
+	TerrainIce:     {R: 190, G: 230, B: 255, A: 255},
+}
+
+// terrainColorTolerance is how far (euclidean RGB distance) a pixel of a terrain image can be from
+// the color of a kind of ground, the farther pixels are plain
+const terrainColorTolerance = 80
+
+// Terrain is a grid of grounds covering the world, sampled by every entity at every tick
+type Terrain struct {
+	Cols, Rows   int
+	CellW, CellH float64
+	cells        []TerrainKind
+}
+
+// At returns the ground under 'pos', plain outside the world
+func (t *Terrain) At(pos geometry.Vector2D) TerrainKind {
+	col, row := int(pos.X/t.CellW), int(pos.Y/t.CellH)
+	if pos.X < 0 || pos.Y < 0 || col >= t.Cols || row >= t.Rows {
+		return TerrainPlain
+	}
+	return t.cells[row*t.Cols+col]
+}
+
+// Cell returns the ground of the cell (col, row)
+func (t *Terrain) Cell(col, row int) TerrainKind {
+	return t.cells[row*t.Cols+col]
+}
+
+// ParseTerrain builds the terrain of a world of width x height: a path ending in .png is an image
+// stretched over the world, whose pixels take the kind of ground of the closest color of
+// terrainColors; anything else is a list of rectangles "kind:x,y,width,height" separated by
+// semicolons, e.g. "mud:100,100,200,150;highway:0,380,1200,40", the last one winning where they overlap
+func ParseTerrain(spec string, width, height float64) (*Terrain, error) {
+	if strings.HasSuffix(strings.ToLower(spec), ".png") {
+		return loadTerrainImage(spec, width, height)
+	}
+	t := &Terrain{Cols: max(1, int(width/terrainCell)), Rows: max(1, int(height/terrainCell))}
+	t.CellW, t.CellH = width/float64(t.Cols), height/float64(t.Rows)
+	t.cells = make([]TerrainKind, t.Cols*t.Rows)
+	for i, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, rect, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("terrain region %d: %q is not kind:x,y,width,height", i+1, item)
+		}
+		kind, err := terrainKind(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("terrain region %d: %w", i+1, err)
+		}
+		parts := strings.Split(rect, ",")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("terrain region %d: %q is not kind:x,y,width,height", i+1, item)
+		}
+		var values [4]float64
+		for j, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, fmt.Errorf("terrain region %d: %q is not kind:x,y,width,height: %w", i+1, item, err)
+			}
+			values[j] = v
+		}
+		if values[2] <= 0 || values[3] <= 0 {
+			return nil, fmt.Errorf("terrain region %d: the size (%f x %f) must be positive", i+1, values[2], values[3])
+		}
+		// The cells whose center is inside the rectangle
+		for row := 0; row < t.Rows; row++ {
+			y := (float64(row) + 0.5) * t.CellH
+			if y < values[1] || y >= values[1]+values[3] {
+				continue
+			}
+			for col := 0; col < t.Cols; col++ {
+				if x := (float64(col) + 0.5) * t.CellW; x >= values[0] && x < values[0]+values[2] {
+					t.cells[row*t.Cols+col] = kind
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// terrainKind returns the kind of ground named 'name'
+func terrainKind(name string) (TerrainKind, error) {
+	for k, n := range terrainNames {
+		if n == name {
+			return TerrainKind(k), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown terrain %q (use one of %v)", name, terrainNames)
+}
+
+// loadTerrainImage reads the terrain image 'path', one cell per pixel stretched over width x height
+func loadTerrainImage(path string, width, height float64) (*Terrain, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read terrain: %w", err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("terrain %s: %w", path, err)
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, fmt.Errorf("terrain %s: empty image", path)
+	}
+	t := &Terrain{Cols: b.Dx(), Rows: b.Dy(), cells: make([]TerrainKind, b.Dx()*b.Dy())}
+	t.CellW, t.CellH = width/float64(t.Cols), height/float64(t.Rows)
+	for row := 0; row < t.Rows; row++ {
+		for col := 0; col < t.Cols; col++ {
+			t.cells[row*t.Cols+col] = pixelTerrain(color.RGBAModel.Convert(img.At(b.Min.X+col, b.Min.Y+row)).(color.RGBA))
+		}
+	}
+	return t, nil
+}
+
+// pixelTerrain returns the kind of ground of the color closest to 'c', plain for the transparent
+// pixels and those too far from every color
+func pixelTerrain(c color.RGBA) TerrainKind {
+	best, bestSq := TerrainPlain, terrainColorTolerance*terrainColorTolerance
+	if c.A < 128 {
+		return best
+	}
+	for k := TerrainMud; k < numTerrains; k++ {
+		ref := terrainColors[k]
+		dr, dg, db := int(c.R)-int(ref.R), int(c.G)-int(ref.G), int(c.B)-int(ref.B)
+		if d := dr*dr + dg*dg + db*db; d < bestSq {
+			best, bestSq = k, d
+		}
+	}
+	return best
+}
+
+// terrainKey identifies a built terrain: the spec and the size of the world it covers
+type terrainKey struct {
+	spec          string
+	width, height float64
+}
+
+// terrains caches the built terrains, sampled every tick by every entity
+var terrains sync.Map // terrainKey -> terrainEntry
+
+type terrainEntry struct {
+	terrain *Terrain
+	err     error
+}
+
+// buildTerrain builds the terrain of Config.Terrain, once per spec and world size
+func (c *Config) buildTerrain() (*Terrain, error) {
+	key := terrainKey{spec: c.Terrain, width: c.WorldWidth, height: c.WorldHeight}
+	if cached, ok := terrains.Load(key); ok {
+		entry := cached.(terrainEntry)
+		return entry.terrain, entry.err
+	}
+	var entry terrainEntry
+	entry.terrain, entry.err = ParseTerrain(c.Terrain, c.WorldWidth, c.WorldHeight)
+	terrains.Store(key, entry)
+	return entry.terrain, entry.err
+}
+
+// TerrainMap returns the terrain of the world, nil when Terrain is empty or invalid
+func (c *Config) TerrainMap() *Terrain {
+	if c.Terrain == "" {
+		return nil
+	}
+	t, _ := c.buildTerrain()
+	return t
+}
+
+// terrainFactors returns the factors of MaxSpeed and TurnFactor of the ground under 'pos'
+func (c *Config) terrainFactors(pos geometry.Vector2D) (speed, turn float64) {
+	t := c.TerrainMap()
+	if t == nil {
+		return 1, 1
+	}
+	effect := terrainEffects[t.At(pos)]
+	return effect.speed, effect.turn
+}
+
+// terrainAlpha is the opacity of the terrain drawn beneath the entities
+const terrainAlpha = 90
+
+// drawTerrain draws every run of cells of the same ground of each row as one thick line
+func drawTerrain(r Renderer, t *Terrain) {
+	for row := 0; row < t.Rows; row++ {
+		y := float32((float64(row) + 0.5) * t.CellH)
+		for col := 0; col < t.Cols; {
+			kind, start := t.Cell(col, row), col
+			for col < t.Cols && t.Cell(col, row) == kind {
+				col++
+			}
+			if kind == TerrainPlain {
+				continue
+			}
+			clr := terrainColors[kind]
+			clr.A = terrainAlpha
+			r.StrokeLine(float32(float64(start)*t.CellW), y, float32(float64(col)*t.CellW), y, float32(t.CellH), clr)
+		}
+	}
+}
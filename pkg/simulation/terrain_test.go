@@ -0,0 +1,128 @@
+package simulation
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestParseTerrain(t *testing.T) {
+	terrain, err := ParseTerrain("mud:0,0,100,50; highway:50,0,100,100", 200, 100)
+	if err != nil {
+		t.Fatalf("ParseTerrain() error = %v", err)
+	}
+	if terrain.Cols != 20 || terrain.Rows != 10 {
+		t.Errorf("Expected 20 x 10 cells, got %d x %d", terrain.Cols, terrain.Rows)
+	}
+	for _, tt := range []struct {
+		pos  geometry.Vector2D
+		want TerrainKind
+	}{
+		{geometry.Vector2D{X: 10, Y: 10}, TerrainMud},
+		{geometry.Vector2D{X: 10, Y: 60}, TerrainPlain},
+		{geometry.Vector2D{X: 70, Y: 10}, TerrainHighway}, // The last region wins
+		{geometry.Vector2D{X: 170, Y: 90}, TerrainPlain},
+		{geometry.Vector2D{X: -5, Y: 10}, TerrainPlain},
+		{geometry.Vector2D{X: 70, Y: 150}, TerrainPlain},
+	} {
+		if got := terrain.At(tt.pos); got != tt.want {
+			t.Errorf("At(%v) = %v, expected %v", tt.pos, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"mud", "swamp:0,0,10,10", "mud:0,0,10", "mud:0,0,0,10", "mud:a,0,10,10", "missing.png"} {
+		if _, err := ParseTerrain(spec, 200, 100); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestParseTerrain_image(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, terrainColors[TerrainMud])
+	img.Set(1, 0, color.RGBA{R: 200, G: 235, B: 250, A: 255}) // Close to the ice
+	img.Set(2, 0, color.RGBA{R: 255, A: 255})                 // Far from every ground
+	img.Set(3, 1, terrainColors[TerrainHighway])
+	path := filepath.Join(t.TempDir(), "terrain.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	terrain, err := ParseTerrain(path, 400, 200)
+	if err != nil {
+		t.Fatalf("ParseTerrain() error = %v", err)
+	}
+	want := []TerrainKind{TerrainMud, TerrainIce, TerrainPlain, TerrainPlain, TerrainPlain, TerrainPlain, TerrainPlain, TerrainHighway}
+	for i, kind := range want {
+		if got := terrain.Cell(i%4, i/4); got != kind {
+			t.Errorf("Cell(%d, %d) = %v, expected %v", i%4, i/4, got, kind)
+		}
+	}
+	if got := terrain.At(geometry.Vector2D{X: 350, Y: 150}); got != TerrainHighway {
+		t.Errorf("Expected the image stretched over the world, got %v", got)
+	}
+}
+
+func TestConfigTerrain(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Terrain = "mud:0,0,100,100"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Terrain = "lava:0,0,100,100" },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestIndividual_movingConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Terrain = "mud:0,0,100,100;ice:100,0,100,100"
+	live := newLiveConfig(cfg)
+	i := newIndividual(pb.TeamColor_TEAM_BLUE, 300, 300, 0, 0, live, nil)
+	i.setID("Blue-000")
+	if got := i.movingConfig(); got != live.Load() {
+		t.Error("Expected the config of the world on the plain")
+	}
+
+	i.State.Pos = geometry.Vector2D{X: 50, Y: 50}
+	mud := i.movingConfig()
+	if mud.MaxSpeed != cfg.MaxSpeed*0.5 || mud.MinSpeed != cfg.MinSpeed*0.5 || mud.TurnFactor != cfg.TurnFactor {
+		t.Errorf("Expected the speeds halved in the mud, got %f and %f", mud.MaxSpeed, mud.MinSpeed)
+	}
+	if again := i.movingConfig(); again != mud {
+		t.Error("Expected the scaled copy to be reused")
+	}
+
+	i.State.Pos = geometry.Vector2D{X: 150, Y: 50}
+	if ice := i.movingConfig(); ice.MaxSpeed != cfg.MaxSpeed || ice.TurnFactor != cfg.TurnFactor*0.3 {
+		t.Errorf("Expected a weaker turn on the ice, got %f", ice.TurnFactor)
+	}
+
+	// The speed power-up adds up with the ground
+	live.powerUps.Store(&powerUpState{active: map[string]PowerUpSet{"Blue-000": 1 << PowerUpSpeed}})
+	i.State.Pos = geometry.Vector2D{X: 50, Y: 50}
+	if got := i.movingConfig(); got.MaxSpeed != cfg.MaxSpeed*0.5*speedBoost {
+		t.Errorf("Expected the boost in the mud, got %f", got.MaxSpeed)
+	}
+	if live.Load().MaxSpeed != cfg.MaxSpeed {
+		t.Error("Expected the config of the world unchanged")
+	}
+}
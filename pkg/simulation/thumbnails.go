@@ -0,0 +1,136 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/behavior"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/capture"
+)
+
+// runSequence tells apart the runs started by the process in the same second with the same seed
+var runSequence atomic.Uint64
+
+// NewRunID returns a name unique to the process for a run started now, e.g. "20261017-150405-seed42-1":
+// the directory of the captures of the run (see Thumbnails). The seed is left out when it is random (0).
+func NewRunID(seed uint64) string {
+	id := time.Now().Format("20060102-150405")
+	if seed != 0 {
+		id += fmt.Sprintf("-seed%d", seed)
+	}
+	return fmt.Sprintf("%s-%d", id, runSequence.Add(1))
+}
+
+// runDir returns the directory of the captures of the run 'runID'
+func runDir(runID string) string {
+	return filepath.Join(captureDir, runID)
+}
+
+// thumbnailSchedule picks the snapshots of a run saved as stills:
+// every Config.ThumbnailEvery ticks, and the final state with Config.ScreenshotOnGameOver
+type thumbnailSchedule struct {
+	every    uint64
+	gameOver bool
+	next     uint64 // Tick of the next periodic still
+	over     bool   // The final state was saved
+}
+
+func newThumbnailSchedule(cfg *Config) thumbnailSchedule {
+	return thumbnailSchedule{every: uint64(cfg.ThumbnailEvery), gameOver: cfg.ScreenshotOnGameOver, next: uint64(cfg.ThumbnailEvery)}
+}
+
+// enabled reports whether the schedule saves anything, never in the browser
+func (s *thumbnailSchedule) enabled() bool {
+	return canWriteFiles && (s.every > 0 || s.gameOver)
+}
+
+// due returns the file name of the still of 'snap', "" when it is not to be saved
+func (s *thumbnailSchedule) due(snap *pb.WorldSnapshot) string {
+	if !s.enabled() || s.over {
+		return ""
+	}
+	tick := snap.GetTick()
+	if snap.GetIsGameOver() {
+		if !s.gameOver {
+			return ""
+		}
+		s.over = true
+		return fmt.Sprintf("game-over-tick-%06d.png", tick)
+	}
+	if s.every == 0 || tick < s.next {
+		return ""
+	}
+	s.next = (tick/s.every + 1) * s.every
+	return fmt.Sprintf("tick-%06d.png", tick)
+}
+
+// Thumbnails saves PNG stills of a run without a window, drawn with an ImageRenderer at the size
+// of the world: every Config.ThumbnailEvery ticks and the final state with Config.ScreenshotOnGameOver,
+// in the directory of the captures named by the run ID. The Runner and the Lab feed it their
+// snapshots, the Game saves its own frames instead. A nil Thumbnails saves nothing.
+type Thumbnails struct {
+	dir       string
+	schedule  thumbnailSchedule
+	trails    *Trails
+	img       *image.RGBA
+	obstacles []behavior.Obstacle
+	terrain   *Terrain
+	saved     int
+}
+
+// NewThumbnails returns the Thumbnails of the run 'runID', nil when cfg asks for no still
+func NewThumbnails(cfg *Config, runID string) *Thumbnails {
+	schedule := newThumbnailSchedule(cfg)
+	if !schedule.enabled() {
+		return nil
+	}
+	return &Thumbnails{
+		dir:       runDir(runID),
+		schedule:  schedule,
+		trails:    NewTrails(DefaultMaxTrailPoints),
+		img:       image.NewRGBA(image.Rect(0, 0, int(cfg.WorldWidth), int(cfg.WorldHeight))),
+		obstacles: cfg.ObstacleList(),
+		terrain:   cfg.TerrainMap(),
+	}
+}
+
+// Dir returns the directory of the stills
+func (t *Thumbnails) Dir() string {
+	if t == nil {
+		return ""
+	}
+	return t.dir
+}
+
+// Saved returns the number of stills written so far
+func (t *Thumbnails) Saved() int {
+	if t == nil {
+		return 0
+	}
+	return t.saved
+}
+
+// Observe follows the trails of every snapshot, and writes the still of those due
+func (t *Thumbnails) Observe(snap *pb.WorldSnapshot) error {
+	if t == nil {
+		return nil
+	}
+	t.trails.Update(snap)
+	name := t.schedule.due(snap)
+	if name == "" {
+		return nil
+	}
+	draw.Draw(t.img, t.img.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	DrawWorld(NewImageRenderer(t.img), snap, t.trails, WorldDrawOptions{Obstacles: t.obstacles, Terrain: t.terrain})
+	if err := capture.SavePNG(filepath.Join(t.dir, name), t.img); err != nil {
+		return err
+	}
+	t.saved++
+	return nil
+}
@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+func TestThumbnailSchedule(t *testing.T) {
+	if !canWriteFiles {
+		t.Skip("No file in the browser")
+	}
+	cfg := DefaultConfig()
+	cfg.ThumbnailEvery = 10
+	cfg.ScreenshotOnGameOver = true
+	s := newThumbnailSchedule(cfg)
+	var names []string
+	// Ticks may be skipped: the next still is the first tick past a multiple of ThumbnailEvery
+	for _, tick := range []uint64{1, 9, 10, 11, 19, 23, 30} {
+		if name := s.due(&pb.WorldSnapshot{Tick: tick}); name != "" {
+			names = append(names, name)
+		}
+	}
+	over := &pb.WorldSnapshot{Tick: 31, IsGameOver: true}
+	names = append(names, s.due(over), s.due(over))
+	want := []string{"tick-000010.png", "tick-000023.png", "tick-000030.png", "game-over-tick-000031.png", ""}
+	if !slices.Equal(names, want) {
+		t.Errorf("Stills %v, expected %v", names, want)
+	}
+
+	if NewThumbnails(DefaultConfig(), "run") != nil {
+		t.Error("Expected no Thumbnails by default")
+	}
+}
+
+func TestThumbnails_observe(t *testing.T) {
+	if !canWriteFiles {
+		t.Skip("No file in the browser")
+	}
+	t.Chdir(t.TempDir())
+	cfg := DefaultConfig()
+	cfg.WorldWidth, cfg.WorldHeight = 200, 100
+	cfg.ScreenshotOnGameOver = true
+	thumbnails := NewThumbnails(cfg, "test-run")
+	snap := &pb.WorldSnapshot{Tick: 5, Actors: []*pb.ActorState{redAt("Red-000", 50, 50)}}
+	if err := thumbnails.Observe(snap); err != nil || thumbnails.Saved() != 0 {
+		t.Fatalf("Expected no still before the game over, got %d %v", thumbnails.Saved(), err)
+	}
+	snap = &pb.WorldSnapshot{Tick: 6, IsGameOver: true, Actors: []*pb.ActorState{redAt("Red-000", 50, 50)}}
+	if err := thumbnails.Observe(snap); err != nil || thumbnails.Saved() != 1 {
+		t.Fatalf("Expected the final state saved, got %d %v", thumbnails.Saved(), err)
+	}
+	if _, err := os.Stat(filepath.Join(captureDir, "test-run", "game-over-tick-000006.png")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestImageRenderer(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	r := NewImageRenderer(img)
+	r.DrawSprite(SpriteRedShip, 10, 10, 0, 1, noTint)
+	if got := img.RGBAAt(10, 10); got != shipColors[SpriteRedShip] {
+		t.Errorf("Expected the red ship at its center, got %v", got)
+	}
+	r.FillCircle(30, 30, 3, color.RGBA{G: 255, A: 255})
+	if got := img.RGBAAt(30, 30); got.G != 255 {
+		t.Errorf("Expected the disc at its center, got %v", got)
+	}
+	// Drawing outside of the image is clipped
+	r.StrokeLine(-10, -10, 100, 20, 2, color.RGBA{B: 255, A: 255})
+	r.StrokeCircle(45, 45, 10, 1, color.RGBA{B: 255, A: 255})
+	if got := img.RGBAAt(0, 39); got != (color.RGBA{}) {
+		t.Errorf("Expected an untouched corner, got %v", got)
+	}
+}
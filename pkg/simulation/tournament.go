@@ -0,0 +1,89 @@
+package simulation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoundResult is the outcome of a round of a Tournament
+type RoundResult struct {
+	Winner  string  `json:"winner"` // ColorRed or ColorBlue
+	Seconds float64 `json:"seconds"`
+}
+
+// Tournament scores the rounds of the tournament mode of the Game (see Config.Rounds):
+// the wins of each team and their mean time-to-win, shown as a scoreboard
+type Tournament struct {
+	Rounds  int
+	results []RoundResult
+}
+
+// NewTournament returns a tournament of 'rounds' rounds, nil when rounds <= 0 (no tournament)
+func NewTournament(rounds int) *Tournament {
+	if rounds <= 0 {
+		return nil
+	}
+	return &Tournament{Rounds: rounds}
+}
+
+// Record adds the outcome of a round, ignored once every round is played
+func (t *Tournament) Record(stats RunStats) {
+	if t.Done() {
+		return
+	}
+	t.results = append(t.results, RoundResult{Winner: stats.Winner, Seconds: stats.Seconds})
+}
+
+// Played returns the number of rounds recorded
+func (t *Tournament) Played() int {
+	return len(t.results)
+}
+
+// Done reports whether every round is played
+func (t *Tournament) Done() bool {
+	return len(t.results) >= t.Rounds
+}
+
+// Results returns the outcomes of the rounds played, in order
+func (t *Tournament) Results() []RoundResult {
+	return t.results
+}
+
+// Wins returns the number of rounds won by 'winner' (ColorRed or ColorBlue) and their mean duration
+// in seconds of simulation time, 0 when it won none
+func (t *Tournament) Wins(winner string) (wins int, meanSeconds float64) {
+	var total float64
+	for _, r := range t.results {
+		if r.Winner == winner {
+			wins++
+			total += r.Seconds
+		}
+	}
+	if wins == 0 {
+		return 0, 0
+	}
+	return wins, total / float64(wins)
+}
+
+// Reset forgets the rounds played, for a new tournament
+func (t *Tournament) Reset() {
+	t.results = t.results[:0]
+}
+
+// Scoreboard describes the tournament in a few lines: the round and the score of each team
+func (t *Tournament) Scoreboard() string {
+	var b strings.Builder
+	if t.Done() {
+		fmt.Fprintf(&b, "Tournament over: %d rounds", t.Rounds)
+	} else {
+		fmt.Fprintf(&b, "Round %d/%d", t.Played()+1, t.Rounds)
+	}
+	for _, team := range []struct{ label, color string }{{"RED", ColorRed}, {"BLUE", ColorBlue}} {
+		wins, mean := t.Wins(team.color)
+		fmt.Fprintf(&b, "\n%-5s %3d wins", team.label, wins)
+		if wins > 0 {
+			fmt.Fprintf(&b, "  avg %.1fs", mean)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package simulation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTournament(t *testing.T) {
+	if NewTournament(0) != nil {
+		t.Fatal("Expected no tournament without rounds")
+	}
+	tour := NewTournament(3)
+	tour.Record(RunStats{Winner: ColorRed, Seconds: 10})
+	tour.Record(RunStats{Winner: ColorBlue, Seconds: 40})
+	if tour.Done() || tour.Played() != 2 {
+		t.Fatalf("Expected 2 rounds of 3 played, got %d (done %v)", tour.Played(), tour.Done())
+	}
+	if board := tour.Scoreboard(); !strings.HasPrefix(board, "Round 3/3") {
+		t.Errorf("Expected the third round on the scoreboard, got %q", board)
+	}
+	tour.Record(RunStats{Winner: ColorRed, Seconds: 30})
+	tour.Record(RunStats{Winner: ColorRed, Seconds: 99}) // Past the last round, ignored
+	if !tour.Done() || tour.Played() != 3 {
+		t.Fatalf("Expected the 3 rounds played, got %d", tour.Played())
+	}
+	if wins, mean := tour.Wins(ColorRed); wins != 2 || mean != 20 {
+		t.Errorf("Expected 2 red wins in 20s on average, got %d in %f", wins, mean)
+	}
+	if wins, mean := tour.Wins(ColorBlue); wins != 1 || mean != 40 {
+		t.Errorf("Expected 1 blue win in 40s, got %d in %f", wins, mean)
+	}
+	board := tour.Scoreboard()
+	if !strings.HasPrefix(board, "Tournament over") || !strings.Contains(board, "avg 20.0s") {
+		t.Errorf("Unexpected final scoreboard %q", board)
+	}
+	tour.Reset()
+	if tour.Played() != 0 || tour.Done() {
+		t.Errorf("Expected an empty tournament after Reset")
+	}
+
+	cfg := DefaultConfig()
+	cfg.Rounds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected negative rounds to be rejected")
+	}
+}
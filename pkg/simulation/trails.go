@@ -0,0 +1,128 @@
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// trailLength is the number of positions kept per red entity when the budget allows it
+	trailLength = 20
+	// minTrailLength is the shortest trail worth drawing
+	minTrailLength = 2
+	// DefaultMaxTrailPoints caps the positions stored for all trails together (200 full trails)
+	DefaultMaxTrailPoints = 200 * trailLength
+)
+
+// Trails stores the recent positions of the red entities under a global memory cap.
+// When the whole population turns red, the trails get shorter (the oldest positions go first)
+// and, past minTrailLength points each, the trails outside the viewport are evicted first,
+// then the ones of the entities coming last in the snapshot.
+type Trails struct {
+	// paths maps an ActorID to its list of positions, oldest first
+	paths     map[string][]geometry.Vector2D
+	maxPoints int
+	points    int
+	// viewport is the visible area, an empty one means everything is visible
+	viewMin, viewMax geometry.Vector2D
+	// scratch buffers reused by Update
+	visible, hidden []*pb.ActorState
+}
+
+// NewTrails creates an empty trail store holding at most 'maxPoints' positions (DefaultMaxTrailPoints when <= 0)
+func NewTrails(maxPoints int) *Trails {
+	if maxPoints <= 0 {
+		maxPoints = DefaultMaxTrailPoints
+	}
+	return &Trails{
+		paths:     make(map[string][]geometry.Vector2D),
+		maxPoints: maxPoints,
+	}
+}
+
+// SetViewport sets the visible area used to choose which trails to evict first
+func (t *Trails) SetViewport(minX, minY, maxX, maxY float64) {
+	t.viewMin = geometry.Vector2D{X: minX, Y: minY}
+	t.viewMax = geometry.Vector2D{X: maxX, Y: maxY}
+}
+
+// Trail returns the positions of an entity, oldest first (nil when it has no trail)
+func (t *Trails) Trail(id string) []geometry.Vector2D {
+	if t == nil {
+		return nil
+	}
+	return t.paths[id]
+}
+
+// Len returns the number of stored trails
+func (t *Trails) Len() int {
+	return len(t.paths)
+}
+
+// Points returns the number of stored positions, never above the cap
+func (t *Trails) Points() int {
+	return t.points
+}
+
+// Reset forgets every trail
+func (t *Trails) Reset() {
+	clear(t.paths)
+	t.points = 0
+}
+
+// Update appends the positions of a new snapshot and enforces the memory cap
+func (t *Trails) Update(snap *pb.WorldSnapshot) {
+	// 1. Split the red entities, the visible ones keep their trail first
+	t.visible, t.hidden = t.visible[:0], t.hidden[:0]
+	for _, a := range snap.Actors {
+		if a.Color != pb.TeamColor_TEAM_RED {
+			continue
+		}
+		if t.isVisible(a.Position) {
+			t.visible = append(t.visible, a)
+		} else {
+			t.hidden = append(t.hidden, a)
+		}
+	}
+	kept := append(t.visible, t.hidden...)
+	if maxTrails := t.maxPoints / minTrailLength; len(kept) > maxTrails {
+		kept = kept[:maxTrails]
+	}
+
+	// 2. Share the budget: every trail shortens before any is evicted
+	perTrail := trailLength
+	if len(kept) > 0 {
+		perTrail = min(trailLength, max(minTrailLength, t.maxPoints/len(kept)))
+	}
+
+	// 3. Append and trim from the tail (oldest positions)
+	alive := make(map[string]bool, len(kept))
+	t.points = 0
+	for _, a := range kept {
+		alive[a.Id] = true
+		list := append(t.paths[a.Id], geometry.Vector2D{X: a.Position.X, Y: a.Position.Y})
+		if extra := len(list) - perTrail; extra > 0 {
+			// Shift in place so the backing array never grows past the trail length
+			n := copy(list, list[extra:])
+			list = list[:n]
+		}
+		t.paths[a.Id] = list
+		t.points += len(list)
+	}
+
+	// 4. Evict the trails of dead/converted and over-budget entities
+	for id := range t.paths {
+		if !alive[id] {
+			delete(t.paths, id)
+		}
+	}
+	t.visible = kept[:0]
+	t.hidden = t.hidden[:0]
+}
+
+func (t *Trails) isVisible(p *pb.Vector) bool {
+	if t.viewMax == t.viewMin {
+		return true
+	}
+	return p.X >= t.viewMin.X && p.X <= t.viewMax.X && p.Y >= t.viewMin.Y && p.Y <= t.viewMax.Y
+}
@@ -0,0 +1,71 @@
+package simulation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// redSnapshot returns a snapshot of 'n' red entities, the first 'hidden' ones outside of a 100x100 viewport
+func redSnapshot(n, hidden int, step float64) *pb.WorldSnapshot {
+	snap := &pb.WorldSnapshot{}
+	for i := 0; i < n; i++ {
+		x := 50.0
+		if i < hidden {
+			x = 500
+		}
+		snap.Actors = append(snap.Actors, &pb.ActorState{
+			Id:       fmt.Sprintf("Red-%03d", i),
+			Color:    pb.TeamColor_TEAM_RED,
+			Position: &pb.Vector{X: x, Y: step},
+		})
+	}
+	snap.Actors = append(snap.Actors, &pb.ActorState{Id: "Blue-000", Color: pb.TeamColor_TEAM_BLUE, Position: &pb.Vector{}})
+	return snap
+}
+
+func TestTrailsKeepFullLengthUnderTheCap(t *testing.T) {
+	trails := NewTrails(100)
+	for step := 0; step < 30; step++ {
+		trails.Update(redSnapshot(5, 0, float64(step)))
+	}
+	if trails.Len() != 5 || trails.Points() != 5*trailLength {
+		t.Fatalf("Expected 5 full trails, got %d trails and %d points", trails.Len(), trails.Points())
+	}
+	// The oldest positions were dropped
+	if trace := trails.Trail("Red-000"); trace[0].Y != 10 || trace[len(trace)-1].Y != 29 {
+		t.Errorf("Expected the last %d positions, got %v", trailLength, trace)
+	}
+	if trails.Trail("Blue-000") != nil {
+		t.Errorf("Expected no trail for blue entities")
+	}
+}
+
+func TestTrailsShortenThenEvict(t *testing.T) {
+	trails := NewTrails(100)
+	trails.SetViewport(0, 0, 100, 100)
+	for step := 0; step < 30; step++ {
+		trails.Update(redSnapshot(10, 0, float64(step)))
+	}
+	// 10 trails share 100 points: they shrink to 10 positions each
+	if trails.Len() != 10 || trails.Points() != 100 || len(trails.Trail("Red-009")) != 10 {
+		t.Errorf("Expected 10 trails of 10 points, got %d trails and %d points", trails.Len(), trails.Points())
+	}
+
+	// The whole population turned red: only 50 trails of 2 points fit, the last off-screen ones go first
+	for step := 0; step < 5; step++ {
+		trails.Update(redSnapshot(80, 40, float64(step)))
+	}
+	if trails.Len() != 50 || trails.Points() != 100 {
+		t.Errorf("Expected 50 trails and 100 points, got %d trails and %d points", trails.Len(), trails.Points())
+	}
+	if trails.Trail("Red-039") != nil || trails.Trail("Red-079") == nil {
+		t.Errorf("Expected the off-screen trails to be evicted first")
+	}
+
+	trails.Reset()
+	if trails.Len() != 0 || trails.Points() != 0 {
+		t.Errorf("Expected Reset to clear every trail")
+	}
+}
@@ -0,0 +1,315 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+)
+
+// Objectives of TuneParameters
+const (
+	// ObjectiveBalance : the red team wins half of the rounds
+	ObjectiveBalance = "balance"
+	// ObjectiveStalemate : the rounds last as long as possible, ideally until MaxTicks
+	ObjectiveStalemate = "stalemate"
+)
+
+// TunerOptions configures TuneParameters
+type TunerOptions struct {
+	// Parameters are the JSON names of the tuned Config fields (SensitivityParameters when empty)
+	Parameters []string
+	// Objective is ObjectiveBalance or ObjectiveStalemate
+	Objective string
+	// Spread bounds the search: a parameter ranges from base*(1-Spread) to base*(1+Spread)
+	Spread float64
+	// Population is the number of candidates of a generation, Elite the best ones kept unchanged
+	Population  int
+	Generations int
+	Elite       int
+	// CrossoverRate is the probability that a child mixes the values of two parents, else it copies
+	// the first one. MutationRate is the probability that a value mutates, by a normal step of
+	// MutationScale times the range of the parameter.
+	CrossoverRate float64
+	MutationRate  float64
+	MutationScale float64
+	// Rounds is the number of seeded rounds played by every candidate, round i uses seed Seed+i.
+	// Seed also seeds the random choices of the tuner: a tuning with the local engine is reproducible.
+	Rounds int
+	Seed   uint64
+	// MaxTicks ends a round without winner
+	MaxTicks uint64
+	// Engine runs the rounds, NewLocalEngine (reproducible) when nil
+	Engine EngineFactory
+	// Workers is the number of rounds run in parallel, the number of CPUs when <= 0
+	Workers int
+	// OnGeneration is called after every generation (e.g. to show the progress)
+	OnGeneration func(gen TunerGeneration)
+}
+
+// DefaultTunerOptions returns a balance tuning of 10 generations of 16 candidates within ±50% of the
+// baseline, every candidate playing 4 rounds of at most three minutes of simulation time
+func DefaultTunerOptions() TunerOptions {
+	return TunerOptions{
+		Objective:     ObjectiveBalance,
+		Spread:        0.5,
+		Population:    16,
+		Generations:   10,
+		Elite:         2,
+		CrossoverRate: 0.7,
+		MutationRate:  0.2,
+		MutationScale: 0.1,
+		Rounds:        4,
+		Seed:          1,
+		MaxTicks:      3 * 60 * SimTicksPerSecond,
+	}
+}
+
+// Candidate is a set of values of the tuned parameters and how well it meets the objective
+type Candidate struct {
+	Values []float64 `json:"values"` // In the order of TunerReport.Parameters
+	// Outcome is nil when the values do not make a valid config (see Error), its fitness is then -1
+	Outcome   *Outcome `json:"outcome,omitempty"`
+	Fitness   float64  `json:"fitness"` // From 0 to 1, the higher the better
+	Error     string   `json:"error,omitempty"`
+	evaluated bool
+}
+
+// TunerGeneration summarizes a generation of the tuning
+type TunerGeneration struct {
+	Index       int       `json:"index"`
+	Best        Candidate `json:"best"`
+	MeanFitness float64   `json:"meanFitness"`
+}
+
+// TunerReport is the result of TuneParameters: the best candidate found and its config
+type TunerReport struct {
+	Options     TunerOptions      `json:"-"`
+	Parameters  []string          `json:"parameters"`
+	Best        Candidate         `json:"best"`
+	Config      *Config           `json:"config"` // The baseline with the values of Best
+	Generations []TunerGeneration `json:"generations"`
+}
+
+// tunerGene is the range of a tuned parameter
+type tunerGene struct {
+	name   string
+	lo, hi float64
+	apply  func(cfg *Config, value float64) float64
+}
+
+// TuneParameters evolves sets of parameter values around 'base' toward the objective with a genetic
+// algorithm: the first generation holds the baseline and random candidates, every next one keeps the
+// Elite best candidates and breeds the others from parents picked by tournament, with crossover and
+// mutation. Every candidate plays the same seeded rounds headless.
+func TuneParameters(ctx context.Context, base *Config, opts TunerOptions) (*TunerReport, error) {
+	if err := base.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid baseline config: %w", err)
+	}
+	if len(opts.Parameters) == 0 {
+		opts.Parameters = SensitivityParameters
+	}
+	if _, err := objectiveFitness(opts.Objective, Outcome{}, 1); err != nil {
+		return nil, err
+	}
+	switch {
+	case opts.Population < 2:
+		return nil, fmt.Errorf("population (%d) must be at least 2", opts.Population)
+	case opts.Generations <= 0:
+		return nil, fmt.Errorf("generations (%d) must be positive", opts.Generations)
+	case opts.Elite < 0 || opts.Elite >= opts.Population:
+		return nil, fmt.Errorf("elite (%d) must be between 0 and the population (%d)", opts.Elite, opts.Population)
+	case opts.Rounds <= 0:
+		return nil, fmt.Errorf("rounds (%d) must be positive", opts.Rounds)
+	case opts.Spread <= 0:
+		return nil, fmt.Errorf("spread (%f) must be positive", opts.Spread)
+	}
+	if opts.Engine == nil {
+		opts.Engine = NewLocalEngine
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	genes := make([]tunerGene, len(opts.Parameters))
+	baseline := make([]float64, len(opts.Parameters))
+	for i, name := range opts.Parameters {
+		field, ok := configField(name)
+		if !ok || !isNumeric(field.Type.Kind()) {
+			return nil, fmt.Errorf("%q is not a numeric config parameter", name)
+		}
+		value := fieldValue(base, field)
+		lo, hi := value*(1-opts.Spread), value*(1+opts.Spread)
+		if value == 0 {
+			lo, hi = 0, opts.Spread
+		}
+		genes[i] = tunerGene{name: name, lo: max(0, lo), hi: hi, apply: func(cfg *Config, v float64) float64 {
+			return setFieldValue(cfg, field, v)
+		}}
+		baseline[i] = value
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, uint64(len(genes))))
+	population := make([]Candidate, opts.Population)
+	population[0].Values = baseline
+	for i := 1; i < len(population); i++ {
+		values := make([]float64, len(genes))
+		for g, gene := range genes {
+			values[g] = gene.lo + rng.Float64()*(gene.hi-gene.lo)
+		}
+		population[i].Values = values
+	}
+
+	report := &TunerReport{Options: opts, Parameters: opts.Parameters}
+	for gen := 0; gen < opts.Generations; gen++ {
+		if err := evaluateCandidates(ctx, base, genes, population, opts); err != nil {
+			return nil, err
+		}
+		sort.SliceStable(population, func(i, j int) bool { return population[i].Fitness > population[j].Fitness })
+		summary := TunerGeneration{Index: gen, Best: population[0]}
+		for _, c := range population {
+			summary.MeanFitness += c.Fitness / float64(len(population))
+		}
+		report.Generations = append(report.Generations, summary)
+		if gen == 0 || population[0].Fitness > report.Best.Fitness {
+			report.Best = population[0]
+		}
+		if opts.OnGeneration != nil {
+			opts.OnGeneration(summary)
+		}
+		if gen < opts.Generations-1 {
+			population = breed(rng, genes, population, opts)
+		}
+	}
+
+	cfg := *base
+	for i, gene := range genes {
+		gene.apply(&cfg, report.Best.Values[i])
+	}
+	report.Config = &cfg
+	return report, nil
+}
+
+// evaluateCandidates plays the rounds of the candidates not evaluated yet and sets their fitness
+func evaluateCandidates(ctx context.Context, base *Config, genes []tunerGene, population []Candidate, opts TunerOptions) error {
+	var configs []*Config
+	var pending []*Candidate
+	for i := range population {
+		c := &population[i]
+		if c.evaluated {
+			continue
+		}
+		cfg := *base
+		for g, gene := range genes {
+			c.Values[g] = gene.apply(&cfg, c.Values[g])
+		}
+		if err := cfg.Validate(); err != nil {
+			c.Error, c.Fitness, c.evaluated = err.Error(), -1, true
+			continue
+		}
+		configs = append(configs, &cfg)
+		pending = append(pending, c)
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	outcomes, err := runOutcomes(ctx, configs, SensitivityOptions{
+		Rounds:   opts.Rounds,
+		Seed:     opts.Seed,
+		MaxTicks: opts.MaxTicks,
+		Engine:   opts.Engine,
+		Workers:  opts.Workers,
+	})
+	if err != nil {
+		return err
+	}
+	for i, c := range pending {
+		c.Outcome = &outcomes[i]
+		c.Fitness, _ = objectiveFitness(opts.Objective, outcomes[i], opts.MaxTicks)
+		c.evaluated = true
+	}
+	return nil
+}
+
+// objectiveFitness scores an outcome from 0 to 1 for the objective
+func objectiveFitness(objective string, o Outcome, maxTicks uint64) (float64, error) {
+	switch objective {
+	case ObjectiveBalance:
+		return 1 - 2*math.Abs(o.RedWinRate-0.5), nil
+	case ObjectiveStalemate:
+		// The undecided rounds lasted maxTicks, the won ones MeanTicks
+		won := o.RedWinRate + o.BlueWinRate
+		if maxTicks == 0 {
+			return 1 - won, nil
+		}
+		return 1 - won + won*min(1, o.MeanTicks/float64(maxTicks)), nil
+	}
+	return 0, fmt.Errorf("unknown objective %q, expected %s or %s", objective, ObjectiveBalance, ObjectiveStalemate)
+}
+
+// breed returns the next generation of a population sorted by fitness
+func breed(rng *rand.Rand, genes []tunerGene, population []Candidate, opts TunerOptions) []Candidate {
+	next := make([]Candidate, 0, len(population))
+	next = append(next, population[:opts.Elite]...)
+	for len(next) < len(population) {
+		a, b := pickParent(rng, population), pickParent(rng, population)
+		values := append([]float64(nil), a.Values...)
+		crossover := rng.Float64() < opts.CrossoverRate
+		for g, gene := range genes {
+			if crossover && rng.IntN(2) == 0 {
+				values[g] = b.Values[g]
+			}
+			if rng.Float64() < opts.MutationRate {
+				values[g] += rng.NormFloat64() * opts.MutationScale * (gene.hi - gene.lo)
+			}
+			values[g] = max(gene.lo, min(gene.hi, values[g]))
+		}
+		next = append(next, Candidate{Values: values})
+	}
+	return next
+}
+
+// pickParent returns the fittest of three random candidates
+func pickParent(rng *rand.Rand, population []Candidate) Candidate {
+	best := population[rng.IntN(len(population))]
+	for range 2 {
+		if c := population[rng.IntN(len(population))]; c.Fitness > best.Fitness {
+			best = c
+		}
+	}
+	return best
+}
+
+// WriteText writes the progress of the generations, then the best values found
+func (r *TunerReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%-10s %12s %12s\n", "generation", "best", "mean"); err != nil {
+		return err
+	}
+	for _, g := range r.Generations {
+		if _, err := fmt.Fprintf(w, "%-10d %12.3f %12.3f\n", g.Index+1, g.Best.Fitness, g.MeanFitness); err != nil {
+			return err
+		}
+	}
+	best := r.Best
+	if _, err := fmt.Fprintf(w, "\nBest fitness %.3f (%s)", best.Fitness, r.Options.Objective); err != nil {
+		return err
+	}
+	if best.Outcome != nil {
+		if _, err := fmt.Fprintf(w, ": red wins %.0f%%, blue wins %.0f%%, victory in %.0f ticks",
+			best.Outcome.RedWinRate*100, best.Outcome.BlueWinRate*100, best.Outcome.MeanTicks); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	for i, name := range r.Parameters {
+		if _, err := fmt.Fprintf(w, "%-16s %10.4g\n", name, best.Values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
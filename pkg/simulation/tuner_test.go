@@ -0,0 +1,94 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTuneParameters(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NumRedAtStart = 3
+	cfg.NumBlueAtStart = 10
+	cfg.LogLevel = "error"
+	opts := DefaultTunerOptions()
+	opts.Parameters = []string{"aggression", "maxSpeed"}
+	opts.Population = 4
+	opts.Generations = 3
+	opts.Elite = 1
+	opts.Rounds = 1
+	opts.MaxTicks = 60
+	opts.Workers = 2
+	generations := 0
+	opts.OnGeneration = func(gen TunerGeneration) { generations++ }
+
+	report, err := TuneParameters(context.Background(), cfg, opts)
+	if err != nil {
+		t.Fatalf("TuneParameters() error = %v", err)
+	}
+	if len(report.Generations) != 3 || generations != 3 {
+		t.Fatalf("Expected 3 generations, got %d (%d callbacks)", len(report.Generations), generations)
+	}
+	for i, gen := range report.Generations {
+		// The elite keeps the best candidate: the best fitness never drops
+		if i > 0 && gen.Best.Fitness < report.Generations[i-1].Best.Fitness {
+			t.Errorf("Best fitness dropped at generation %d: %+v", i, report.Generations)
+		}
+		if gen.Best.Fitness > report.Best.Fitness {
+			t.Errorf("Generation %d is better than the best candidate", i)
+		}
+	}
+	if report.Config.Aggression != report.Best.Values[0] || report.Config.MaxSpeed != report.Best.Values[1] {
+		t.Errorf("Expected the config of the best candidate, got %+v for %v", report.Config, report.Best.Values)
+	}
+	for i, v := range report.Best.Values {
+		base := []float64{cfg.Aggression, cfg.MaxSpeed}[i]
+		if v < base*(1-opts.Spread) || v > base*(1+opts.Spread) {
+			t.Errorf("Value %f of %s out of the ±%.0f%% range", v, opts.Parameters[i], opts.Spread*100)
+		}
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "aggression") || !strings.Contains(text.String(), "Best fitness") {
+		t.Errorf("Unexpected report:\n%s", text.String())
+	}
+}
+
+func TestTuneParameters_invalidOptions(t *testing.T) {
+	for _, invalid := range []func(o *TunerOptions){
+		func(o *TunerOptions) { o.Objective = "fastest" },
+		func(o *TunerOptions) { o.Population = 1 },
+		func(o *TunerOptions) { o.Elite = o.Population },
+		func(o *TunerOptions) { o.Parameters = []string{"redStrategy"} },
+	} {
+		opts := DefaultTunerOptions()
+		invalid(&opts)
+		if _, err := TuneParameters(context.Background(), DefaultConfig(), opts); err == nil {
+			t.Errorf("Expected %+v to be rejected", opts)
+		}
+	}
+}
+
+func TestObjectiveFitness(t *testing.T) {
+	for _, tc := range []struct {
+		objective string
+		outcome   Outcome
+		want      float64
+	}{
+		{ObjectiveBalance, Outcome{RedWinRate: 0.5, BlueWinRate: 0.5}, 1},
+		{ObjectiveBalance, Outcome{RedWinRate: 1}, 0},
+		{ObjectiveBalance, Outcome{RedWinRate: 0.25, BlueWinRate: 0.5}, 0.5},
+		{ObjectiveStalemate, Outcome{}, 1},
+		{ObjectiveStalemate, Outcome{BlueWinRate: 1, MeanTicks: 50}, 0.5},
+		{ObjectiveStalemate, Outcome{RedWinRate: 0.5, MeanTicks: 50}, 0.75},
+	} {
+		got, err := objectiveFitness(tc.objective, tc.outcome, 100)
+		if err != nil || got != tc.want {
+			t.Errorf("objectiveFitness(%s, %+v) = %f, %v, want %f", tc.objective, tc.outcome, got, err, tc.want)
+		}
+	}
+}
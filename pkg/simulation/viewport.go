@@ -0,0 +1,55 @@
+package simulation
+
+import "github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+
+// viewportOf returns the visible area set by 'msg', nil when it is empty (everything is visible)
+func viewportOf(msg *pb.SetViewport) *pb.SetViewport {
+	if msg.GetMaxX() <= msg.GetMinX() || msg.GetMaxY() <= msg.GetMinY() {
+		return nil
+	}
+	return msg
+}
+
+// inViewport reports whether (x, y) is inside 'view', always true for a nil view
+func inViewport(view *pb.SetViewport, x, y float64) bool {
+	return view == nil || (x >= view.MinX && x <= view.MaxX && y >= view.MinY && y <= view.MaxY)
+}
+
+// countEntity adds one entity of 'color' to the counts of 'snap' and reports whether it is on screen,
+// the entities outside 'view' are only counted
+func countEntity(snap *pb.WorldSnapshot, view *pb.SetViewport, color pb.TeamColor, x, y float64) bool {
+	if color == NeutralColor {
+		// The civilians belong to no team: counted apart, without offscreen count
+		snap.NeutralCount++
+		return inViewport(view, x, y)
+	}
+	red := color == pb.TeamColor_TEAM_RED
+	if red {
+		snap.RedCount++
+	} else {
+		snap.BlueCount++
+	}
+	if inViewport(view, x, y) {
+		return true
+	}
+	if red {
+		snap.OffscreenRed++
+	} else {
+		snap.OffscreenBlue++
+	}
+	return false
+}
+
+// setGameOver declares the winner once a team is extinct
+func setGameOver(snap *pb.WorldSnapshot) {
+	if snap.RedCount+snap.BlueCount == 0 {
+		return
+	}
+	if snap.RedCount == 0 {
+		snap.IsGameOver = true
+		snap.Winner = ColorBlue
+	} else if snap.BlueCount == 0 {
+		snap.IsGameOver = true
+		snap.Winner = ColorRed
+	}
+}
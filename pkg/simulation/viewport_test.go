@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+)
+
+// checkViewportSnapshot checks that 'snap' lists only the entities inside 'view' and counts the others
+func checkViewportSnapshot(t *testing.T, snap *pb.WorldSnapshot, view *pb.SetViewport, total int32) {
+	t.Helper()
+	if snap.RedCount+snap.BlueCount != total {
+		t.Errorf("Expected %d entities counted, got %d red and %d blue", total, snap.RedCount, snap.BlueCount)
+	}
+	for _, a := range snap.Actors {
+		if !inViewport(view, a.Position.X, a.Position.Y) {
+			t.Errorf("%s at %v is outside the viewport", a.Id, a.Position)
+		}
+	}
+	if got := int32(len(snap.Actors)) + snap.OffscreenRed + snap.OffscreenBlue; got != total {
+		t.Errorf("Expected %d actors and offscreen entities, got %d", total, got)
+	}
+}
+
+func TestEngines_viewportSnapshots(t *testing.T) {
+	for name, factory := range map[string]EngineFactory{EngineLocal: NewLocalEngine, EngineECS: NewECSEngine} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := DefaultConfig()
+			cfg.NumRedAtStart = 20
+			cfg.NumBlueAtStart = 80
+			cfg.Seed = 7
+			snapshotCh := make(chan *pb.WorldSnapshot, 1)
+			hub := NewSnapshotHub()
+			observed, cancel := hub.Subscribe(1)
+			defer cancel()
+			engine, err := factory(ctx, snapshotCh, cfg, WithSnapshotHub(hub))
+			if err != nil {
+				t.Fatalf("factory failed: %v", err)
+			}
+			defer engine.Stop(ctx)
+
+			view := &pb.SetViewport{MinX: 0, MinY: 0, MaxX: cfg.WorldWidth / 2, MaxY: cfg.WorldHeight / 2}
+			if err := engine.Send(ctx, view); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			_ = engine.Send(ctx, &pb.Tick{})
+			snap := <-snapshotCh
+			checkViewportSnapshot(t, snap, view, 100)
+			if len(snap.Actors) == 0 || len(snap.Actors) == 100 {
+				t.Errorf("Expected a quarter of the world to show some of the entities, got %d", len(snap.Actors))
+			}
+			// Observers of the hub still get every entity
+			if full := <-observed; len(full.Actors) != 100 || full.OffscreenRed+full.OffscreenBlue != 0 {
+				t.Errorf("Expected a full snapshot on the hub, got %d actors", len(full.Actors))
+			}
+
+			// An empty viewport restores the full snapshots
+			_ = engine.Send(ctx, &pb.SetViewport{})
+			_ = engine.Send(ctx, &pb.Tick{})
+			if snap := <-snapshotCh; len(snap.Actors) != 100 {
+				t.Errorf("Expected 100 actors without viewport, got %d", len(snap.Actors))
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+const (
+	// defaultWindPeriod is the number of ticks of a full turn of the rotating wind, and between two
+	// gusts of the noisy one, when Config.WindPeriod is 0
+	defaultWindPeriod = 600
+	// windGustAngle is the largest angle (radians) between a gust and WindDirection
+	windGustAngle = math.Pi / 2
+)
+
+// Modes of Config.WindMode
+const (
+	WindConstant = "constant" // Blows toward WindDirection (default)
+	WindRotating = "rotating" // Turns clockwise, a full turn every WindPeriod ticks
+	WindNoise    = "noise"    // Gusts around WindDirection, a new one every WindPeriod ticks
+)
+
+// Wind links the world to whoever steers and shows its wind, e.g. the sliders of the Game. Give it
+// to the world with WithWind. A Wind is safe for concurrent use.
+type Wind struct {
+	mu                  sync.Mutex
+	strength, direction float64
+	set                 bool
+	force               geometry.Vector2D
+}
+
+// Set makes the wind blow with 'strength' toward 'direction' (degrees) in place of
+// Config.WindStrength and WindDirection
+func (w *Wind) Set(strength, direction float64) {
+	w.mu.Lock()
+	w.strength, w.direction, w.set = strength, direction, true
+	w.mu.Unlock()
+}
+
+// Force returns the wind blowing on every entity at the last tick, per nominal tick
+func (w *Wind) Force() geometry.Vector2D {
+	if w == nil {
+		return geometry.Vector2D{}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.force
+}
+
+// settings returns the strength and the direction given to Set, those of 'cfg' until then
+func (w *Wind) settings(cfg *Config) (strength, direction float64) {
+	if w == nil {
+		return cfg.WindStrength, cfg.WindDirection
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.set {
+		return cfg.WindStrength, cfg.WindDirection
+	}
+	return w.strength, w.direction
+}
+
+func (w *Wind) setForce(force geometry.Vector2D) {
+	w.mu.Lock()
+	w.force = force
+	w.mu.Unlock()
+}
+
+// WithWind lets 'wind' steer the wind of the world, which publishes the force blowing at every tick
+func WithWind(wind *Wind) WorldOption {
+	return func(w *world) {
+		w.wind.control = wind
+	}
+}
+
+// windGust is a deviation of the noisy wind from WindDirection and a factor of WindStrength
+type windGust struct {
+	angle, strength float64
+}
+
+// windTracker keeps the gusts of the noisy wind from a tick to the next
+type windTracker struct {
+	control *Wind
+	// period is the index of the WindPeriod ticks blending 'from' into 'to', once drawn
+	period   int64
+	drawn    bool
+	from, to windGust
+}
+
+// updateWind computes the wind of the tick and publishes it to the individuals, nothing when it is still
+func (w *world) updateWind() {
+	strength, direction := w.wind.control.settings(w.cfg)
+	var force geometry.Vector2D
+	if strength > 0 {
+		angle, factor := w.windAngle(direction*math.Pi/180), 1.0
+		if w.cfg.WindMode == WindNoise {
+			angle, factor = w.windGust(angle)
+		}
+		force = geometry.Vector2D{X: math.Cos(angle), Y: math.Sin(angle)}.Mul(strength * factor)
+	}
+	if force == (geometry.Vector2D{}) {
+		w.live.wind.Store(nil)
+	} else {
+		w.live.wind.Store(&force)
+	}
+	if w.wind.control != nil {
+		w.wind.control.setForce(force)
+	}
+}
+
+// windPeriod returns the number of ticks of Config.WindPeriod
+func (w *world) windPeriod() uint64 {
+	if w.cfg.WindPeriod > 0 {
+		return uint64(w.cfg.WindPeriod)
+	}
+	return defaultWindPeriod
+}
+
+// windAngle returns the direction (radians) the wind blows toward at this tick: 'base', turned by
+// the rotation of the rotating wind
+func (w *world) windAngle(base float64) float64 {
+	if w.cfg.WindMode != WindRotating {
+		return base
+	}
+	period := w.windPeriod()
+	return base + 2*math.Pi*float64(w.tick%period)/float64(period)
+}
+
+// windGust returns the direction and the strength factor of the noisy wind at this tick: it blends
+// smoothly from a gust to the next, each one drawn from the world seed
+func (w *world) windGust(base float64) (angle, factor float64) {
+	t := &w.wind
+	period := w.windPeriod()
+	if current := int64(w.tick / period); !t.drawn || current != t.period {
+		if t.drawn && current == t.period+1 {
+			t.from = t.to
+		} else {
+			t.from = w.drawGust(current)
+		}
+		t.period, t.to, t.drawn = current, w.drawGust(current+1), true
+	}
+	f := float64(w.tick%period) / float64(period)
+	f = f * f * (3 - 2*f) // Smoothstep: no jolt from a gust to the next
+	return base + t.from.angle + (t.to.angle-t.from.angle)*f, t.from.strength + (t.to.strength-t.from.strength)*f
+}
+
+// drawGust draws the gust starting the period 'n', the same for every run with the same seed
+func (w *world) drawGust(n int64) windGust {
+	rng := w.entityRand(fmt.Sprintf("wind/%d", n))
+	return windGust{angle: (2*rng.Float64() - 1) * windGustAngle, strength: 0.5 + rng.Float64()}
+}
+
+const (
+	// windArrowScale is the length (pixels) of the wind indicator per unit of force
+	windArrowScale = 400.0
+	// windArrowMax is the largest length (pixels) of the wind indicator
+	windArrowMax = 80.0
+)
+
+var windColor = color.RGBA{R: 200, G: 220, B: 255, A: 200}
+
+// drawWind draws an arrow pointing where the wind blows at the top center of the world, the longer
+// the stronger the wind
+func drawWind(r Renderer, force geometry.Vector2D, worldWidth float64) {
+	if force == (geometry.Vector2D{}) {
+		return
+	}
+	arrow := force.Mul(windArrowScale)
+	if l := arrow.Len(); l > windArrowMax {
+		arrow = arrow.Mul(windArrowMax / l)
+	}
+	drawArrow(r, worldWidth/2, windArrowMax/2, arrow.X, arrow.Y, windColor)
+}
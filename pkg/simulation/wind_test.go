@@ -0,0 +1,111 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+func TestConfigWind(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.WindStrength, cfg.WindMode = 0.05, WindNoise
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.WindStrength = -1 },
+		func(c *Config) { c.WindPeriod = -1 },
+		func(c *Config) { c.WindMode = "storm" },
+		func(c *Config) { c.Engine = EngineECS },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+// nearForce reports whether 'a' and 'b' are the same force, up to the rounding of the trigonometry
+func nearForce(a, b geometry.Vector2D) bool {
+	return a.DistanceTo(b) < 1e-9
+}
+
+func TestWorld_updateWind(t *testing.T) {
+	cfg := &Config{WorldWidth: 400, WorldHeight: 400, WindStrength: 0.1, WindDirection: 90, WindPeriod: 100, Seed: 3}
+	control := &Wind{}
+	w := newWorld(nil, cfg, WithWind(control))
+	cfg = w.cfg
+	w.tick = 25
+	w.updateWind()
+	if got := *w.live.wind.Load(); !nearForce(got, geometry.Vector2D{Y: 0.1}) || control.Force() != got {
+		t.Errorf("Expected the constant wind to blow down the screen, got %v", got)
+	}
+
+	cfg.WindMode = WindRotating
+	w.updateWind()
+	if got := control.Force(); !nearForce(got, geometry.Vector2D{X: -0.1}) {
+		t.Errorf("Expected the rotating wind a quarter turn further after a quarter of its period, got %v", got)
+	}
+
+	// The sliders of the Game take over the config
+	control.Set(0.2, 0)
+	cfg.WindMode = WindConstant
+	w.updateWind()
+	if got := control.Force(); !nearForce(got, geometry.Vector2D{X: 0.2}) {
+		t.Errorf("Expected the wind of Set, got %v", got)
+	}
+	control.Set(0, 0)
+	w.updateWind()
+	if w.live.wind.Load() != nil || control.Force() != (geometry.Vector2D{}) {
+		t.Error("Expected no wind at strength 0")
+	}
+}
+
+func TestWorld_windGust(t *testing.T) {
+	cfg := &Config{WorldWidth: 400, WorldHeight: 400, WindStrength: 0.1, WindMode: WindNoise, WindPeriod: 50, Seed: 3}
+	gusts := func() []geometry.Vector2D {
+		w := newWorld(nil, cfg)
+		var forces []geometry.Vector2D
+		for range 200 {
+			w.tick++
+			w.updateWind()
+			forces = append(forces, *w.live.wind.Load())
+		}
+		return forces
+	}
+	first, again := gusts(), gusts()
+	for tick, force := range first {
+		if force != again[tick] {
+			t.Fatalf("Expected the same gusts with the same seed, tick %d: %v and %v", tick+1, force, again[tick])
+		}
+		if l := force.Len(); l < 0.05-1e-9 || l > 0.15+1e-9 {
+			t.Errorf("Tick %d: the strength %f is outside [0.05, 0.15]", tick+1, l)
+		}
+		if diff := math.Abs(math.Remainder(force.Angle(), 2*math.Pi)); diff > windGustAngle+1e-9 {
+			t.Errorf("Tick %d: the gust %v is more than 90° from the direction", tick+1, force)
+		}
+		// Smooth: no jolt from a tick to the next, even from a gust to the next
+		if tick > 0 && force.DistanceTo(first[tick-1]) > 0.01 {
+			t.Errorf("Tick %d: the wind jumped from %v to %v", tick+1, first[tick-1], force)
+		}
+	}
+}
+
+func TestIndividual_wind(t *testing.T) {
+	live := newLiveConfig(DefaultConfig())
+	live.wind.Store(&geometry.Vector2D{X: 0.5})
+	for _, color := range []pb.TeamColor{pb.TeamColor_TEAM_BLUE, NeutralColor} {
+		still := newIndividual(color, 300, 300, 0, 2, newLiveConfig(DefaultConfig()), nil)
+		blown := newIndividual(color, 300, 300, 0, 2, live, nil)
+		still.setID("Blue-000")
+		blown.setID("Blue-000")
+		calm, windy := still.handleTick(&pb.Tick{}), blown.handleTick(&pb.Tick{})
+		if windy.Position.X <= calm.Position.X {
+			t.Errorf("%v: expected the wind to push the entity right, got x %f without and %f with it",
+				color, calm.Position.X, windy.Position.X)
+		}
+	}
+}
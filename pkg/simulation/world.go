@@ -2,50 +2,172 @@ package simulation
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"math/rand/v2"
 	"time"
 
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
-	"github.com/tochemey/goakt/v3/actor"
-	"github.com/tochemey/goakt/v3/goaktpb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/spatial"
+	"google.golang.org/protobuf/proto"
 )
 
+// SimTicksPerSecond is the number of ticks in one second of simulation time
+// (one tick per frame at the default 60 TPS of Ebiten)
+const SimTicksPerSecond = 60
+
+// benchmarkIntervalTicks is the telemetry period, in simulation time so that it stays
+// meaningful in fast-forward, slow motion and headless runs
+const benchmarkIntervalTicks = 5 * SimTicksPerSecond
+
+// TickDuration is the simulation time of a nominal tick: the speeds and forces of the
+// config are per nominal tick, a pb.Tick with another DeltaTime scales them
+const TickDuration = time.Second / SimTicksPerSecond
+
+// SimTime converts a number of ticks to simulation time
+func SimTime(ticks uint64) time.Duration {
+	return time.Duration(ticks) * time.Second / SimTicksPerSecond
+}
+
+// tickScale converts the DeltaTime of a pb.Tick (nanoseconds, 0 for a nominal tick) to nominal ticks
+func tickScale(deltaTime int64) float64 {
+	if deltaTime <= 0 {
+		return 1
+	}
+	return float64(deltaTime) / float64(TickDuration)
+}
+
 type gridKey struct {
 	x, y int
 }
 
-// WorldActor is the new "Brain." It manages the authoritative state and the spatial grid optimization.
-type WorldActor struct {
-	entities  map[string]*Entity
-	pids      []*actor.PID // Keep track of children
-	pidsCache map[string]*actor.PID
+// swarm delivers the messages of the world to its individuals and its commanders.
+// The actor engine spawns one GoAkt actor per individual, the local engine keeps plain structs.
+type swarm interface {
+	spawn(id string, ind *individual)
+	// spawnCommander starts the commander of a team (see commander.go), told by the world under 'id'
+	spawnCommander(id string, c *commander)
+	// tell returns false when 'id' is unknown
+	tell(id string, msg proto.Message) bool
+	logger() Logger
+}
+
+// world is the new "Brain." It manages the authoritative state and the spatial grid optimization.
+// It does not know how individuals run: the engine gives it a swarm before calling start.
+type world struct {
+	entities map[string]*Entity
+	// order lists the entities by arrival, every per-tick loop follows it so that
+	// a seeded run with the local engine is reproducible (map iteration order is random)
+	order []*Entity
+	// seed of every random stream of the world, see entityRand
+	seed      uint64
+	swarm     swarm
 	uiChannel chan<- *pb.WorldSnapshot
 	// Optimization: Spatial Hashing
 	// Map gridKey -> list of entities in that cell
 	grid map[gridKey][]*Entity
+	// index answers the neighbor queries: the grid above or a quadtree (see world_index.go)
+	index spatial.SpatialIndex[*Entity]
+	// autoIndex switches the index above with the density, nil unless Config.SpatialIndex is auto
+	autoIndex *autoIndex
+	// Reusable query visitors
+	scan    neighborScan
+	counter colorCount
+	// defenders collects the neighbors of a victim (see defense.go)
+	defenders defenderScan
+	// contacts found by the scan of the current tick, resolved once it is over (see contacts.go),
+	// converted holds the IDs already converted during the tick
+	contacts  []contact
+	converted map[string]bool
+	// captures are the contacts of the tick with civilians (see neutral.go)
+	captures []contact
+	// combat decides the fights of the contacts, fight is reused for each of them and killed holds
+	// the IDs of the entities they kill (see combat.go)
+	combat     CombatRule
+	combatRand *rand.Rand
+	fight      Fight
+	killed     []string
+	// gridDirty is set when entities are added or removed after the grid was rebuilt
+	gridDirty bool
+	// Despawned entities waiting to be recycled (see pool.go)
+	pool entityPool
 	// Communication with UI
 	snapshotCh chan<- *pb.WorldSnapshot
+	// Optional fan-out to other observers (see hub.go)
+	hub *SnapshotHub
+	// Optional latest snapshot for concurrent readers (see snapshot_view.go)
+	view *SnapshotView
+	// Optional recycling of the snapshots (see snapshot_pool.go)
+	snapshots *SnapshotPool
+	// viewport is the area visible in the UI, nil when the UI shows everything (see viewport.go)
+	viewport *pb.SetViewport
 	// Game Settings (received from UI)
 	detectionRadius float64
 	visualRange     float64 // For friends (Blue seeking Blue)
 	defenseRadius   float64
 	cfg             *Config
+	// pendingConfig is the last UpdateConfig received, applied at the next tick (see config_groups.go)
+	pendingConfig *pb.UpdateConfig
+	// live is the copy of cfg read by the individuals
+	live *liveConfig
 	// --- Benchmark Stats ---
 	msgSentCount int
 	msgRecvCount int
+	lastLogTick  uint64
 	lastLogTime  time.Time
+	// tick is the number of simulation steps executed so far
+	tick uint64
+	// conversions ordered during the current tick, reported in its snapshot
+	tickConversions uint32
+	// wall time spent by the world on the last tick when timeTicks is set (see WithTickTiming),
+	// the individuals run on their own goroutines
+	timeTicks    bool
+	tickDuration time.Duration
+	// Custom per-tick callbacks (see hooks.go)
+	tickHooks []TickHook
+	commands  CommandQueue
+	// external are the queues of WithCommandQueue
+	external []*CommandQueue
+	// leaders designates the leaders of Config.LeaderTeam (see leader.go)
+	leaders leaderTracker
+	// commanders brief the commanders of the teams of Config.CommandTeam (see commander.go)
+	commanders commanderTracker
+	// pheromones are the fields of Config.Pheromones (see pheromone.go)
+	pheromones pheromoneTracker
+	// food are the food items of Config.FoodRate (see food.go)
+	food foodTracker
+	// powerUps are the pickups of Config.PowerUpRate (see powerup.go)
+	powerUps powerUpTracker
+	// wind is the force of Config.WindStrength (see wind.go)
+	wind windTracker
+	// attractors are the attractors and repulsors of WithAttractors (see attractor.go)
+	attractors attractorTracker
+	// scare is the scare of WithScare, carried by the perceptions of the entities it reaches (see scare.go)
+	scare scareTracker
+	// birthRand places the newborns of Config.ReproduceTicks and ReproduceMeals (see reproduction.go)
+	birthRand *rand.Rand
+	// events are sent to the sinks of WithEventSink, gameOver is set once its event was sent
+	events   eventBus
+	gameOver bool
+	// memory receives the size of the entities and the grid, nil unless WithMemoryUsage
+	memory *MemoryUsage
 }
 
-// NewWorldActor creates the world logic unit
-func NewWorldActor(snapshotCh chan<- *pb.WorldSnapshot, cfg *Config) *WorldActor {
-	return &WorldActor{
+// newWorld creates the world logic unit. It runs on a copy of 'cfg': the caller (e.g. the Game)
+// keeps reading its config while the world changes its own, published to the individuals by w.live.
+func newWorld(snapshotCh chan<- *pb.WorldSnapshot, cfg *Config, opts ...WorldOption) *world {
+	own := *cfg
+	cfg = &own
+	w := &world{
 		entities:        make(map[string]*Entity),
-		pidsCache:       make(map[string]*actor.PID),
 		grid:            make(map[gridKey][]*Entity),
+		converted:       make(map[string]bool),
+		pool:            newEntityPool(),
+		seed:            resolveSeed(cfg.Seed),
 		snapshotCh:      snapshotCh,
 		cfg:             cfg,
+		live:            newLiveConfig(cfg),
 		detectionRadius: cfg.DetectionRadius,
 		defenseRadius:   cfg.DefenseRadius,
 		visualRange:     cfg.VisualRange,
@@ -53,114 +175,188 @@ func NewWorldActor(snapshotCh chan<- *pb.WorldSnapshot, cfg *Config) *WorldActor
 		msgRecvCount:    0,
 		lastLogTime:     time.Now(),
 	}
+	w.index = w.newSpatialIndex()
+	w.scan.w = w
+	w.scan.visitFn = w.scan.visit
+	w.counter.visitFn = w.counter.visit
+	w.defenders.visitFn = w.defenders.visit
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
-func (w *WorldActor) PreStart(ctx *actor.Context) error {
-	// 1. WE SPAWN THE POPULATION HERE NOW
-	// The World is responsible for creating its inhabitants
-	// Actually, Individuals need a way to talk back.
-	// In this refactor, Individuals should send to ctx.Parent() (the World).
-	ctx.ActorSystem().Logger().Info("World is spawning the swarm...")
-
-	return nil
+// start spawns the population, the swarm must be set
+func (w *world) start() {
+	w.swarm.logger().Info("World Started. Spawning Swarm...")
+	w.spawnSwarm()
 }
 
-func (w *WorldActor) Receive(ctx *actor.ReceiveContext) {
-	switch msg := ctx.Message().(type) {
-
-	case *goaktpb.PostStart:
-		ctx.Logger().Info("World Started. Spawning Swarm...")
-		w.spawnSwarm(ctx)
+// handle processes one message sent to the world, by the engine or by an individual
+func (w *world) handle(msg proto.Message) {
+	switch msg := msg.(type) {
 
 	// 1. Handle Updates from Individuals
 	// You might need to add this message to your Proto or use a wrapper
 	case *pb.ActorState:
 		w.msgRecvCount++
 		if existing, ok := w.entities[msg.Id]; ok {
-			existing.UpdateFromProto(msg)
-		} else {
+			// Ignore the late reports of a previous life of a recycled ID
+			if msg.Generation == existing.Generation {
+				existing.UpdateFromProto(msg)
+			}
+		} else if !w.isParked(msg.Id) {
 			// Only allocate if it's a new actor
-			w.entities[msg.Id] = FromProto(msg)
+			w.addEntity(FromProto(msg))
 		}
 
 	// 2. The Main Simulation Step (Driven by Game Loop)
 	case *pb.Tick:
-		// 1. Telemetry
-		w.logBenchmarks(ctx)
-
-		// 2. Physics & Logic
-		w.rebuildGrid()
-		w.broadcastSimulationStep(ctx, msg.DeltaTime)
+		// 1. Physics & Logic
+		start := time.Now()
+		w.tick++
+		w.applyPendingConfig()
+		w.drainCommands()
+		w.updateGrid()
+		w.runTickHooks()
+		w.updateReproduction()
+		w.updateLifespans()
+		// Individuals see the config as it is at the start of the tick, hooks included
+		w.live.publish(w.cfg)
+		if w.gridDirty {
+			// Hooks spawned or despawned entities
+			w.rebuildGrid()
+		}
+		w.updateLeaders()
+		w.updateFormation()
+		w.updatePheromones()
+		w.updateFood()
+		w.updatePowerUps()
+		w.updateWind()
+		w.updateAttractors()
+		w.updateScare()
+		w.updateCommanders()
+		w.broadcastSimulationStep(msg.DeltaTime)
+		if w.timeTicks {
+			w.tickDuration = time.Since(start)
+		}
 
-		// 3. UI Update
+		// 2. UI Update
 		w.pushSnapshot()
 
+		// 3. Telemetry
+		w.logBenchmarks()
+		if w.memory != nil && w.tick%memoryReportTicks == 0 {
+			w.reportMemory()
+		}
+
 		// Handle dynamic config updates from UI
 	case *pb.UpdateConfig:
-		// Update radii
-		w.detectionRadius = msg.GetDetectionRadius()
-		w.defenseRadius = msg.GetDefenseRadius()
-		w.visualRange = msg.GetVisualRange()
-
-		// Update config for other parameters (these affect new calculations)
-		w.cfg.DetectionRadius = msg.GetDetectionRadius()
-		w.cfg.DefenseRadius = msg.GetDefenseRadius()
-		w.cfg.ContactRadius = msg.GetContactRadius()
-		w.cfg.VisualRange = msg.GetVisualRange()
-		w.cfg.ProtectedRange = msg.GetProtectedRange()
-		w.cfg.MaxSpeed = msg.GetMaxSpeed()
-		w.cfg.MinSpeed = msg.GetMinSpeed()
-		w.cfg.Aggression = msg.GetAggression()
-		w.cfg.CenteringFactor = msg.GetCenteringFactor()
-		w.cfg.AvoidFactor = msg.GetAvoidFactor()
-		w.cfg.MatchingFactor = msg.GetMatchingFactor()
-		w.cfg.TurnFactor = msg.GetTurnFactor()
-		w.cfg.DisplayDetectionCircle = msg.GetDisplayDetectionCircle()
-		w.cfg.DisplayDefenseCircle = msg.GetDisplayDefenseCircle()
-
-		// Note: Population parameters (NumRedAtStart, NumBlueAtStart)
-		// are stored but require a simulation restart to take effect
-		w.cfg.NumRedAtStart = int(msg.GetNumRedAtStart())
-		w.cfg.NumBlueAtStart = int(msg.GetNumBlueAtStart())
+		// Staged until the next tick boundary, so that a tick never mixes old and new values
+		// (the last update wins when several arrive between two ticks)
+		w.pendingConfig = msg
+
+	// Hot-swap the strategy of a whole team
+	case *pb.SetStrategy:
+		w.setTeamStrategy(msg)
+
+	// Only the next snapshots change: no need to wait for the tick boundary
+	case *pb.SetViewport:
+		w.viewport = viewportOf(msg)
+	}
+}
+
+// setTeamStrategy records the new strategy of a team (used for future conversions)
+// and forwards it to every individual, only the members of that team will switch.
+func (w *world) setTeamStrategy(msg *pb.SetStrategy) {
+	if _, err := NewBehavior(msg.GetName()); err != nil {
+		w.swarm.logger().Errorf("Cannot switch %s strategy: %v", msg.GetTeam(), err)
+		return
+	}
+	if msg.GetTeam() == pb.TeamColor_TEAM_RED {
+		w.cfg.RedStrategy = msg.GetName()
+	} else {
+		w.cfg.BlueStrategy = msg.GetName()
+	}
+	w.live.publish(w.cfg)
+	w.swarm.logger().Infof("Team %s now uses strategy %s", msg.GetTeam(), msg.GetName())
+	for _, e := range w.order {
+		if w.swarm.tell(e.ID, msg) {
+			w.msgSentCount++
+		}
 	}
 }
 
-func (w *WorldActor) logBenchmarks(ctx *actor.ReceiveContext) {
-	if time.Since(w.lastLogTime) >= time.Second {
-		total := w.msgSentCount + w.msgRecvCount
-		ctx.Logger().Infof("📊 MSG RATE: %d/sec (Sent: %d, Recv: %d) | Actors: %d",
-			total, w.msgSentCount, w.msgRecvCount, len(w.entities))
-		w.msgSentCount = 0
-		w.msgRecvCount = 0
-		w.lastLogTime = time.Now()
+// logBenchmarks reports the message rates every benchmarkIntervalTicks, per second of
+// simulation time and per second of wall time (their ratio is the speed of the simulation)
+func (w *world) logBenchmarks() {
+	ticks := w.tick - w.lastLogTick
+	if ticks < benchmarkIntervalTicks {
+		return
 	}
+	simTime := SimTime(ticks)
+	wallTime := time.Since(w.lastLogTime)
+	total := float64(w.msgSentCount + w.msgRecvCount)
+	pool := w.poolStats()
+	w.swarm.logger().Infof("📊 MSG RATE: %.0f/sim-s, %.0f/wall-s (Sent: %d, Recv: %d) | Ticks %d-%d: %s sim in %s wall (x%.2f) | Actors: %d | Pool: %d parked, %d reused, %.0f%% occupied",
+		total/simTime.Seconds(), total/wallTime.Seconds(), w.msgSentCount, w.msgRecvCount,
+		w.lastLogTick+1, w.tick, simTime, wallTime.Round(time.Millisecond), simTime.Seconds()/wallTime.Seconds(),
+		len(w.entities), pool.Parked, pool.Reused, pool.Occupancy()*100)
+	w.msgSentCount = 0
+	w.msgRecvCount = 0
+	w.lastLogTick = w.tick
+	w.lastLogTime = time.Now()
 }
 
-func (w *WorldActor) pushSnapshot() {
+func (w *world) pushSnapshot() {
+	snapshot := w.buildSnapshot(w.viewport)
+	if snapshot.IsGameOver && !w.gameOver {
+		w.gameOver = true
+		w.events.publish(gameOverEvent(snapshot))
+	}
 	select {
-	case w.snapshotCh <- w.buildSnapshot():
+	case w.snapshotCh <- snapshot:
 	default:
 		// UI busy, skip frame
+		w.snapshotPool().Put(snapshot)
+	}
+	if w.hub != nil || w.view != nil {
+		if w.viewport != nil {
+			// Recordings and remote observers get every entity
+			snapshot = w.buildSnapshot(nil)
+		}
+		if w.hub != nil {
+			w.hub.Publish(snapshot)
+		}
+		if w.view != nil {
+			w.view.publish(snapshot)
+		}
+	}
+	w.tickConversions = 0
+}
+
+// snapshotPool returns the pool of the snapshots, nil when they must not be recycled
+func (w *world) snapshotPool() *SnapshotPool {
+	if w.hub != nil || w.view != nil {
+		return nil
 	}
+	return w.snapshots
 }
 
 // broadcastSimulationStep is the "Mega Loop" optimized for single-pass execution.
-// It combines Perception gathering, Combat Logic, and Tick dispatching.
-func (w *WorldActor) broadcastSimulationStep(ctx *actor.ReceiveContext, dt int64) {
+// It combines Perception gathering, contact collection, and Tick dispatching, then resolves the contacts.
+func (w *world) broadcastSimulationStep(dt int64) {
 	// Pre-calculate squared ranges to avoid Sqrt() calls in loops
-	ranges := struct {
-		perceptionSq float64
-		detectionSq  float64
-		contactSq    float64
-	}{
+	ranges := scanRanges{
 		perceptionSq: w.visualRange * w.visualRange,
 		detectionSq:  w.detectionRadius * w.detectionRadius,
 		contactSq:    w.cfg.ContactRadius * w.cfg.ContactRadius,
 	}
 
-	for id, me := range w.entities {
+	for _, me := range w.order {
+		id := me.ID
 		// 1. Scan grid for neighbors (Perception + Combat triggers)
-		enemies, friends := w.scanNeighbors(ctx, me, ranges)
+		enemies, friends := w.scanNeighbors(me, ranges)
 
 		// 2. Construct the enriched Tick
 		individualTick := &pb.Tick{
@@ -168,413 +364,202 @@ func (w *WorldActor) broadcastSimulationStep(ctx *actor.ReceiveContext, dt int64
 			Context: &pb.Perception{
 				Targets: enemies,
 				Friends: friends,
+				Force:   w.scareForce(me.Pos),
 			},
 		}
 
 		// 3. Dispatch
-		if pid, ok := w.pidsCache[id]; ok {
+		if w.swarm.tell(id, individualTick) {
 			w.msgSentCount++
-			ctx.Tell(pid, individualTick)
 		}
 	}
+	w.resolveContacts()
+	w.resolveCaptures()
 }
 
-// scanNeighbors iterates the spatial grid around 'me'.
-// It populates perception lists AND handles combat interactions inline for efficiency.
-func (w *WorldActor) scanNeighbors(ctx *actor.ReceiveContext, me *Entity, ranges struct{ perceptionSq, detectionSq, contactSq float64 }) ([]*pb.ActorState, []*pb.ActorState) {
-	var visibleEnemies []*pb.ActorState
-	var visibleFriends []*pb.ActorState
-
-	// Get grid bounds for the largest relevant radius (usually Detection or Perception)
-	gx, gy := w.getCellIndices(me.Pos.X, me.Pos.Y)
-
-	// Iterate 3x3 Grid
-	for i := gx - 1; i <= gx+1; i++ {
-		for j := gy - 1; j <= gy+1; j++ {
-			key := gridKey{x: i, y: j}
-			actorsInCell, ok := w.grid[key]
-			if !ok {
-				continue
-			}
+// scanNeighbors queries the spatial index around 'me'.
+// It populates perception lists AND collects the contacts inline for efficiency.
+func (w *world) scanNeighbors(me *Entity, ranges scanRanges) ([]*pb.ActorState, []*pb.ActorState) {
+	scan := &w.scan
+	scan.me, scan.ranges = me, ranges
+	scan.enemies, scan.friends = nil, nil
 
-			for _, other := range actorsInCell {
-				if other.ID == me.ID {
-					continue
-				}
-
-				distSq := me.DistanceSquaredTo(other)
-
-				// --- Logic Branching ---
-				if other.Color == me.Color {
-					// Friend Logic: Flocking
-					if distSq < ranges.perceptionSq {
-						visibleFriends = append(visibleFriends, other.ToProto())
-					}
-				} else {
-					// Enemy Logic: Detection
-					if distSq < ranges.detectionSq {
-						visibleEnemies = append(visibleEnemies, other.ToProto())
-					}
-				}
-
-				// Combat Logic: Red attacks Blue
-				// We check this here to avoid re-iterating neighbors later
-				if me.Color == pb.TeamColor_TEAM_RED && other.Color == pb.TeamColor_TEAM_BLUE {
-					if distSq < ranges.contactSq {
-						w.resolveCombat(ctx, me, other)
-					}
-				}
-			}
-		}
-	}
+	// Query the largest relevant radius (usually Detection or Perception)
+	radius := math.Max(w.visualRange, w.detectionRadius)
+	radius = math.Max(radius, w.cfg.ContactRadius)
+	w.index.Query(me.Pos, radius, scan.visitFn)
+
+	visibleEnemies, visibleFriends := scan.enemies, scan.friends
+	scan.me, scan.enemies, scan.friends = nil, nil, nil
 	return visibleEnemies, visibleFriends
 }
 
-// resolveCombat handles the specific rules of engagement
-func (w *WorldActor) resolveCombat(ctx *actor.ReceiveContext, attacker, victim *Entity) {
-	// Optimization: Use the allocation-free counter we built previously
-	defenders := w.countFriendsInRadius(
-		victim.Pos,
-		w.defenseRadius,
-		pb.TeamColor_TEAM_BLUE, // Target is Blue defenders
-		victim.ID,              // Exclude the victim themselves
-	)
-
-	if defenders >= 3 {
-		// Defense Success: Attacker converts to Blue
-		w.sendConvert(ctx, attacker.ID, pb.TeamColor_TEAM_BLUE)
-	} else {
-		// Defense Failed: Victim converts to Red
-		w.sendConvert(ctx, victim.ID, pb.TeamColor_TEAM_RED)
+// sendConvert orders 'targetID' to switch to 'newColor', attackerID and victimID are the
+// entities of the contact that caused it (empty for the conversions ordered by tick hooks)
+func (w *world) sendConvert(targetID string, newColor pb.TeamColor, attackerID, victimID string) {
+	if w.swarm.tell(targetID, &pb.Convert{TargetColor: newColor, Strategy: w.cfg.StrategyFor(newColor)}) {
+		w.msgSentCount++
+		w.tickConversions++
+		if w.events.enabled() {
+			e := Event{Kind: EventConversion, Tick: w.tick, ID: targetID, Color: teamLabel(newColor),
+				Attacker: attackerID, Victim: victimID}
+			if target, ok := w.entities[targetID]; ok {
+				e.X, e.Y, e.From = target.Pos.X, target.Pos.Y, teamLabel(target.Color)
+			}
+			w.events.publish(e)
+		}
+		if target, ok := w.entities[targetID]; ok && w.cfg.Pheromones {
+			w.pheromones.markAttack(target.Pos)
+		}
 	}
 }
 
-func (w *WorldActor) sendConvert(ctx *actor.ReceiveContext, targetID string, newColor pb.TeamColor) {
-	if pid := w.pidsCache[targetID]; pid != nil {
-		w.msgSentCount++
-		ctx.Tell(pid, &pb.Convert{TargetColor: newColor})
-	}
+func (w *world) spawnSwarm() {
+	spawnLayout(w.cfg, w.seed, func(color pb.TeamColor, pos, vel geometry.Vector2D) {
+		w.spawnEntity(color, pos, vel)
+	})
 }
 
-func (w *WorldActor) spawnSwarm(ctx *actor.ReceiveContext) {
+// spawnLayout places the initial population of 'cfg' and calls spawn for every entity, reds first,
+// civilians last.
+// Every engine uses it, so that a seed gives the same starting positions whatever the engine.
+func spawnLayout(cfg *Config, seed uint64, spawn func(color pb.TeamColor, pos, vel geometry.Vector2D)) {
 	var (
-		redX     = w.cfg.WorldWidth / 6
-		redY     = w.cfg.WorldHeight / 6
-		incRedX  = math.Min(w.cfg.WorldHeight/float64(w.cfg.NumRedAtStart), w.cfg.DetectionRadius)
-		incRedY  = math.Min(w.cfg.WorldHeight/float64(w.cfg.NumRedAtStart), w.cfg.DetectionRadius)
-		blueX    = (w.cfg.WorldWidth / 4) * 2
-		blueY    = (w.cfg.WorldHeight / 4) * 2
-		incBlueX = math.Min(w.cfg.WorldHeight/float64(w.cfg.NumBlueAtStart), w.cfg.DefenseRadius)
-		incBlueY = math.Min(w.cfg.WorldHeight/float64(w.cfg.NumBlueAtStart), w.cfg.DefenseRadius)
+		redX     = cfg.WorldWidth / 6
+		redY     = cfg.WorldHeight / 6
+		incRedX  = math.Min(cfg.WorldHeight/float64(cfg.NumRedAtStart), cfg.DetectionRadius)
+		incRedY  = math.Min(cfg.WorldHeight/float64(cfg.NumRedAtStart), cfg.DetectionRadius)
+		blueX    = (cfg.WorldWidth / 4) * 2
+		blueY    = (cfg.WorldHeight / 4) * 2
+		incBlueX = math.Min(cfg.WorldHeight/float64(cfg.NumBlueAtStart), cfg.DefenseRadius)
+		incBlueY = math.Min(cfg.WorldHeight/float64(cfg.NumBlueAtStart), cfg.DefenseRadius)
 	)
 	// 1. SPAWN REDS
-	for i := 0; i < w.cfg.NumRedAtStart; i++ {
-		name := fmt.Sprintf("Red-%03d", i)
-		startX := redX + float64(i)*incRedX*rand.Float64()*2
-		startY := redY + float64(i)*incRedY*rand.Float64()*2
+	for i := 0; i < cfg.NumRedAtStart; i++ {
+		// Each spawn slot draws from its own stream, see entityRand
+		rng := entityRand(seed, fmt.Sprintf("spawn/red/%d", i))
+		startX := redX + float64(i)*incRedX*rng.Float64()*2
+		startY := redY + float64(i)*incRedY*rng.Float64()*2
 		// Bounds check spawn
-		if startX > w.cfg.WorldWidth-50 {
+		if startX > cfg.WorldWidth-50 {
 			startX = 50 + float64(i)*5
 		}
-		if startY > w.cfg.WorldHeight-50 {
+		if startY > cfg.WorldHeight-50 {
 			startY = 50 + float64(i)*5
 		}
 		// Calculate Random Velocity HERE
-		vx := (rand.Float64() - 0.5) * 2
-		vy := (rand.Float64() - 0.5) * 2
-
-		pid := ctx.Spawn(name, NewIndividual(pb.TeamColor_TEAM_RED, startX, startY, vx, vy, w.cfg))
-		w.pids = append(w.pids, pid)
-		w.pidsCache[name] = pid
-
-		// We must insert the actor into the map NOW, so the very first Tick loop
-		// sees it and sends it a message.
-		w.entities[name] = &Entity{
-			ID:    name,
-			Color: pb.TeamColor_TEAM_RED,
-			Pos: geometry.Vector2D{
-				X: startX,
-				Y: startY,
-			},
-			Vel: geometry.Vector2D{
-				X: vx,
-				Y: vy,
-			},
-		}
+		vx := (rng.Float64() - 0.5) * 2
+		vy := (rng.Float64() - 0.5) * 2
+
+		spawn(pb.TeamColor_TEAM_RED, geometry.Vector2D{X: startX, Y: startY}, geometry.Vector2D{X: vx, Y: vy})
 	}
 
 	// 2. SPAWN BLUES
-	for i := 0; i < w.cfg.NumBlueAtStart; i++ {
-		name := fmt.Sprintf("Blue-%03d", i)
-
-		startX := blueX + float64(i)*incBlueX*rand.Float64()*2
-		startY := blueY + (float64(i%5)*incBlueY)*rand.Float64()*2
+	for i := 0; i < cfg.NumBlueAtStart; i++ {
+		rng := entityRand(seed, fmt.Sprintf("spawn/blue/%d", i))
+		startX := blueX + float64(i)*incBlueX*rng.Float64()*2
+		startY := blueY + (float64(i%5)*incBlueY)*rng.Float64()*2
 		// Bounds check spawn
-		if startX > w.cfg.WorldWidth-50 {
+		if startX > cfg.WorldWidth-50 {
 			startX = 50 + float64(i)*5
 		}
-		if startY > w.cfg.WorldHeight-50 {
+		if startY > cfg.WorldHeight-50 {
 			startY = 50 + float64(i)*5
 		}
-		vx := (rand.Float64() - 0.5) * 2
-		vy := (rand.Float64() - 0.5) * 2
-
-		pid := ctx.Spawn(name, NewIndividual(pb.TeamColor_TEAM_BLUE, startX, startY, vx, vy, w.cfg))
-		w.pids = append(w.pids, pid)
-		w.pidsCache[name] = pid
-
-		w.entities[name] = &Entity{
-			ID:    name,
-			Color: pb.TeamColor_TEAM_BLUE,
-			Pos: geometry.Vector2D{
-				X: startX,
-				Y: startY,
-			},
-			Vel: geometry.Vector2D{
-				X: vx,
-				Y: vy,
-			},
-		}
-	}
-}
-
-func (w *WorldActor) rebuildGrid() {
-	// 1. Reset slices to length 0, but keep capacity! it's better then clear(w.grid)
-	// This allows to reuse the underlying arrays of the slices,
-	// reducing memory allocation to almost zero during runtime.
-	for k := range w.grid {
-		w.grid[k] = w.grid[k][:0]
-	}
+		vx := (rng.Float64() - 0.5) * 2
+		vy := (rng.Float64() - 0.5) * 2
 
-	cellSize := w.getCellSize()
-	for _, a := range w.entities {
-		gx, gy := int(a.Pos.X/cellSize), int(a.Pos.Y/cellSize)
-		key := gridKey{x: gx, y: gy}
-
-		// append will reuse the existing array capacity if available
-		w.grid[key] = append(w.grid[key], a)
+		spawn(pb.TeamColor_TEAM_BLUE, geometry.Vector2D{X: startX, Y: startY}, geometry.Vector2D{X: vx, Y: vy})
 	}
-}
 
-func (w *WorldActor) getCellSize() float64 {
-	// Use the largest radius to ensure our 3x3 grid check covers everything
-	maxRadius := math.Max(w.detectionRadius, w.defenseRadius)
-	maxRadius = math.Max(maxRadius, w.visualRange)
-	// Clamp to a minimum of 10 to avoid tiny grids or div by zero
-	return math.Max(maxRadius, 10.0)
+	// 3. SPAWN CIVILIANS
+	spawnNeutrals(cfg, seed, spawn)
 }
 
-func (w *WorldActor) getCellIndices(x, y float64) (int, int) {
-	cs := w.getCellSize()
-	return int(x / cs), int(y / cs)
+// addEntity registers an entity in the authoritative store
+func (w *world) addEntity(e *Entity) {
+	w.entities[e.ID] = e
+	w.order = append(w.order, e)
+	w.gridDirty = true
 }
 
-// getNearbyActors retrieves all the entities in grids located in and around x,y  (3x3 Grid)
-func (w *WorldActor) getNearbyActors(x, y float64) []*Entity {
-	gx, gy := w.getCellIndices(x, y)
-	var neighbors []*Entity
-
-	// Loop through X-1 to X+1 and Y-1 to Y+1
-	for i := gx - 1; i <= gx+1; i++ {
-		for j := gy - 1; j <= gy+1; j++ {
-			key := gridKey{x: i, y: j}
-			if actors, ok := w.grid[key]; ok {
-				neighbors = append(neighbors, actors...)
-			}
-		}
-	}
-	return neighbors
+// entityRand returns the random stream named 'id' (e.g. the ID of an individual). Each stream
+// only depends on the world seed and its name: adding an entity does not perturb the random
+// sequences of the others, which keeps comparative experiments under control.
+func (w *world) entityRand(id string) *rand.Rand {
+	return entityRand(w.seed, id)
 }
 
-// NEW METHOD: Separate perception broadcasting
-func (w *WorldActor) sendPerceptionUpdates(ctx *actor.ReceiveContext) {
-	perceptionSq := w.visualRange * w.visualRange
-	detectionSq := w.detectionRadius * w.detectionRadius
-
-	for _, entity := range w.entities {
-		nearby := w.getNearbyActors(entity.Pos.X, entity.Pos.Y)
-
-		var visibleEnemies []*pb.ActorState
-		var visibleFriends []*pb.ActorState
-
-		for _, other := range nearby {
-			if other.ID == entity.ID {
-				continue
-			}
-
-			distSq := entity.DistanceSquaredTo(other)
-
-			if other.Color == entity.Color {
-				if distSq < perceptionSq {
-					visibleFriends = append(visibleFriends, other.ToProto())
-				}
-			} else {
-				if distSq < detectionSq {
-					visibleEnemies = append(visibleEnemies, other.ToProto())
-				}
-			}
-		}
-
-		// Send fresh perception BEFORE they move
-		if pid, ok := w.pidsCache[entity.ID]; ok {
-			w.msgSentCount++ // COUNT PERCEPTION MSG
-			ctx.Tell(pid, &pb.Perception{
-				Targets: visibleEnemies,
-				Friends: visibleFriends,
-			})
-		}
-	}
+func entityRand(seed uint64, id string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return rand.New(rand.NewPCG(seed, h.Sum64()))
 }
 
-// processInteractions  Only handle combat now
-func (w *WorldActor) processInteractions(ctx *actor.ReceiveContext) {
-	contactSq := w.cfg.ContactRadius * w.cfg.ContactRadius
-
-	// Only iterate Red entities to avoid double-processing
-	for _, attacker := range w.entities {
-		if attacker.Color != pb.TeamColor_TEAM_RED {
-			continue // Skip Blues
-		}
-
-		nearby := w.getNearbyActors(attacker.Pos.X, attacker.Pos.Y)
-
-		for _, victim := range nearby {
-			if victim.Color != pb.TeamColor_TEAM_BLUE {
-				continue // Only attack Blues
-			}
-
-			distSq := attacker.DistanceSquaredTo(victim)
-			if distSq >= contactSq {
-				continue // Too far for combat
-			}
-
-			// === COMBAT LOGIC ===
-			defenders := w.countFriendsInRadius(
-				victim.Pos,
-				w.defenseRadius,
-				pb.TeamColor_TEAM_BLUE,
-				victim.ID,
-			)
-
-			// Apply conversion
-			if defenders >= 3 {
-				// Defense success: Convert attacker
-				if pid := w.pidsCache[attacker.ID]; pid != nil {
-					w.msgSentCount++ // <--- COUNT CONVERT MSG
-					ctx.Tell(pid, &pb.Convert{TargetColor: pb.TeamColor_TEAM_BLUE})
-				}
-			} else {
-				// Defense failed: Convert victim
-				if pid := w.pidsCache[victim.ID]; pid != nil {
-					w.msgSentCount++ // <--- COUNT CONVERT MSG
-					ctx.Tell(pid, &pb.Convert{TargetColor: pb.TeamColor_TEAM_RED})
-				}
-			}
-		}
+// resolveSeed returns 'seed', or a random seed when it is 0
+func resolveSeed(seed uint64) uint64 {
+	if seed == 0 {
+		return rand.Uint64()
 	}
+	return seed
 }
 
-func (w *WorldActor) buildSnapshot() *pb.WorldSnapshot {
-	snapshot := &pb.WorldSnapshot{
-		Actors:    make([]*pb.ActorState, 0, len(w.entities)),
-		RedCount:  0,
-		BlueCount: 0,
+// updateGrid brings the spatial index up to date with the current positions: the uniform grid
+// only moves the entities that changed cell, other indexes (and any population change) are rebuilt.
+func (w *world) updateGrid() {
+	if w.autoIndex != nil && w.tick%autoIndexCheckEvery == 0 {
+		w.autoIndex.check(w)
 	}
-
-	for _, state := range w.entities {
-		snapshot.Actors = append(snapshot.Actors, state.ToProto())
-		if state.Color == pb.TeamColor_TEAM_RED {
-			snapshot.RedCount++
-		} else {
-			snapshot.BlueCount++
-		}
+	if g, ok := w.index.(*gridIndex); ok && !w.gridDirty && g.canMove(len(w.order)) {
+		g.move(w.order)
+		return
 	}
+	w.rebuildGrid()
+}
 
-	totalPopulation := snapshot.RedCount + snapshot.BlueCount
-	if totalPopulation > 0 {
-		if snapshot.RedCount == 0 {
-			snapshot.IsGameOver = true
-			snapshot.Winner = ColorBlue
-		} else if snapshot.BlueCount == 0 {
-			snapshot.IsGameOver = true
-			snapshot.Winner = ColorRed
-		}
+// rebuildGrid refills the spatial index with the current positions
+func (w *world) rebuildGrid() {
+	w.gridDirty = false
+	w.index.Clear()
+	for _, a := range w.order {
+		w.index.Insert(a.Pos, a)
 	}
-
-	return snapshot
 }
 
-func (w *WorldActor) PostStop(ctx *actor.Context) error {
-	ctx.ActorSystem().Logger().Info("World is shutdown...")
-	return nil
+func (w *world) getCellSize() float64 {
+	return gridCellSize(w.detectionRadius, w.defenseRadius, w.visualRange)
 }
 
-// countFriendsInRadius returns the count of entities of 'targetColor' within 'radius', excluding 'excludeID'.
-// It performs 0 allocations.
-func (w *WorldActor) countFriendsInRadius(center geometry.Vector2D, radius float64, targetColor pb.TeamColor, excludeID string) int {
-	radiusSq := radius * radius
-	cellSize := w.getCellSize()
-
-	// Calculate grid bounds
-	minGx := int((center.X - radius) / cellSize)
-	maxGx := int((center.X + radius) / cellSize)
-	minGy := int((center.Y - radius) / cellSize)
-	maxGy := int((center.Y + radius) / cellSize)
-
-	count := 0
-
-	for gx := minGx; gx <= maxGx; gx++ {
-		for gy := minGy; gy <= maxGy; gy++ {
-			key := gridKey{x: gx, y: gy}
-			if entities, ok := w.grid[key]; ok {
-				for _, e := range entities {
-					// 1. Check ID and Color FIRST (cheaper than math)
-					if e.Color != targetColor || e.ID == excludeID {
-						continue
-					}
-
-					// 2. Check Distance
-					if e.Pos.DistanceSquaredTo(center) < radiusSq {
-						count++
-					}
-				}
-			}
-		}
-	}
-	return count
+// gridCellSize returns the cell size of the spatial grid of a world using these radii
+func gridCellSize(detectionRadius, defenseRadius, visualRange float64) float64 {
+	// Use the largest radius to ensure our 3x3 grid check covers everything
+	maxRadius := math.Max(detectionRadius, defenseRadius)
+	maxRadius = math.Max(maxRadius, visualRange)
+	// Clamp to a minimum of 10 to avoid tiny grids or div by zero
+	return math.Max(maxRadius, 10.0)
 }
 
-// getActorsInRadius returns entities within a specific radius of (x, y)
-// More efficient than getNearbyActors when radius << cellSize
-func (w *WorldActor) getBlueActorsInRadius(x, y, radius float64) []*Entity {
-	radiusSq := radius * radius
-	cellSize := w.getCellSize()
-	center := geometry.Vector2D{
-		X: x,
-		Y: y,
-	}
+func (w *world) buildSnapshot(view *pb.SetViewport) *pb.WorldSnapshot {
+	snapshot := w.snapshotPool().get(len(w.order))
+	snapshot.Tick = w.tick
+	snapshot.Conversions = w.tickConversions
+	snapshot.TickDuration = int64(w.tickDuration)
 
-	// Calculate grid bounds that could contain actors within radius
-	minGx := int((x - radius) / cellSize)
-	maxGx := int((x + radius) / cellSize)
-	minGy := int((y - radius) / cellSize)
-	maxGy := int((y + radius) / cellSize)
-
-	var result []*Entity
-
-	// Only scan necessary cells
-	for gx := minGx; gx <= maxGx; gx++ {
-		for gy := minGy; gy <= maxGy; gy++ {
-			key := gridKey{x: gx, y: gy}
-			if entities, ok := w.grid[key]; ok {
-				for _, e := range entities {
-					if e.Pos.DistanceSquaredTo(center) < radiusSq {
-						result = append(result, e)
-					}
-				}
-			}
+	for _, state := range w.order {
+		if countEntity(snapshot, view, state.Color, state.Pos.X, state.Pos.Y) {
+			state.FillProto(nextActor(snapshot))
 		}
 	}
+	setGameOver(snapshot)
+	return snapshot
+}
 
-	return result
+// countFriendsInRadius returns the count of entities of 'targetColor' within 'radius', excluding 'excludeID'.
+// It performs 0 allocations.
+func (w *world) countFriendsInRadius(center geometry.Vector2D, radius float64, targetColor pb.TeamColor, excludeID string) int {
+	// Counting may run while a neighbor scan is running: the scan and the counter are separate visitors
+	counter := &w.counter
+	counter.color, counter.excludeID, counter.count = targetColor, excludeID, 0
+	w.index.Query(center, radius, counter.visitFn)
+	return counter.count
 }
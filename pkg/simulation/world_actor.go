@@ -0,0 +1,86 @@
+//go:build !js
+
+package simulation
+
+import (
+	"sync/atomic"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/tochemey/goakt/v3/actor"
+	"github.com/tochemey/goakt/v3/goaktpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// worldActor runs the world as a GoAkt actor, every individual is a child actor
+type worldActor struct {
+	w         *world
+	ctx       *actor.ReceiveContext // Context of the message being processed
+	pidsCache map[string]*actor.PID
+	// budget is shared with the individuals, see cpuBudget
+	budget *cpuBudget
+	// ticksInFlight counts the ticks sent by the actor engine, each one done once its snapshot is published
+	ticksInFlight atomic.Int32
+}
+
+var _ actor.Actor = (*worldActor)(nil)
+
+func newWorldActor(w *world, budget *cpuBudget) *worldActor {
+	a := &worldActor{
+		w:         w,
+		pidsCache: make(map[string]*actor.PID),
+		budget:    budget,
+	}
+	w.swarm = a
+	return a
+}
+
+func (a *worldActor) PreStart(ctx *actor.Context) error {
+	// The World is responsible for creating its inhabitants,
+	// Individuals send their state back to their parent (the World).
+	ctx.ActorSystem().Logger().Info("World is spawning the swarm...")
+	return nil
+}
+
+func (a *worldActor) Receive(ctx *actor.ReceiveContext) {
+	a.ctx = ctx
+	switch msg := ctx.Message().(type) {
+	case *goaktpb.PostStart:
+		a.w.start()
+	case *pb.Tick:
+		// The simulation step is the heaviest message of the world
+		a.budget.acquire()
+		a.w.handle(msg)
+		a.budget.release()
+		a.ticksInFlight.Add(-1)
+	case *pb.ActorState, *pb.UpdateConfig, *pb.SetStrategy, *pb.SetViewport:
+		a.w.handle(msg)
+	}
+}
+
+func (a *worldActor) PostStop(ctx *actor.Context) error {
+	ctx.ActorSystem().Logger().Info("World is shutdown...")
+	return nil
+}
+
+// swarm implementation
+
+func (a *worldActor) spawn(id string, ind *individual) {
+	a.pidsCache[id] = a.ctx.Spawn(id, &individualActor{individual: ind, budget: a.budget})
+}
+
+func (a *worldActor) spawnCommander(id string, c *commander) {
+	a.pidsCache[id] = a.ctx.Spawn(id, &commanderActor{commander: c, pids: make(map[string]*actor.PID)})
+}
+
+func (a *worldActor) tell(id string, msg proto.Message) bool {
+	pid := a.pidsCache[id]
+	if pid == nil {
+		return false
+	}
+	a.ctx.Tell(pid, msg)
+	return true
+}
+
+func (a *worldActor) logger() Logger {
+	return a.ctx.Logger()
+}
@@ -0,0 +1,186 @@
+package simulation
+
+import (
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/spatial"
+)
+
+// Names of the spatial indexes selectable with Config.SpatialIndex
+const (
+	SpatialIndexGrid     = "grid"     // Uniform grid, cell size = largest radius (default)
+	SpatialIndexQuadtree = "quadtree" // Adaptive cells, for very uneven densities
+	SpatialIndexAuto     = "auto"     // Grid or quadtree, following the density (see world_index_auto.go)
+)
+
+// spatialIndexNames lists the spatial indexes, the default first
+var spatialIndexNames = []string{SpatialIndexGrid, SpatialIndexQuadtree, SpatialIndexAuto}
+
+// newSpatialIndex returns the index named in the config, the uniform grid by default.
+// The auto index starts on the grid.
+func (w *world) newSpatialIndex() spatial.SpatialIndex[*Entity] {
+	if w.cfg.SpatialIndex == SpatialIndexAuto {
+		w.autoIndex = newAutoIndex(w)
+		return w.autoIndex.grid
+	}
+	if w.cfg.SpatialIndex == SpatialIndexQuadtree {
+		bounds := spatial.NewRect(0, 0, w.cfg.WorldWidth, w.cfg.WorldHeight)
+		return spatial.NewQuadtree[*Entity](bounds, spatial.DefaultNodeCapacity, spatial.DefaultMaxDepth)
+	}
+	return &gridIndex{w: w}
+}
+
+// gridIndex is the uniform grid of the world (w.grid) seen as a spatial.SpatialIndex.
+// The cell size follows the largest radius, so a query scans at most 3x3 cells.
+type gridIndex struct {
+	w        *world
+	cellSize float64
+	count    int
+}
+
+func (g *gridIndex) Clear() {
+	// Reset slices to length 0, but keep capacity! it's better then clear(w.grid)
+	// This allows to reuse the underlying arrays of the slices,
+	// reducing memory allocation to almost zero during runtime.
+	for k := range g.w.grid {
+		g.w.grid[k] = g.w.grid[k][:0]
+	}
+	g.cellSize = g.w.getCellSize()
+	g.count = 0
+}
+
+func (g *gridIndex) Insert(pos geometry.Vector2D, e *Entity) {
+	key := g.key(pos)
+	// append will reuse the existing array capacity if available
+	g.w.grid[key] = append(g.w.grid[key], e)
+	e.gridCell = key
+	g.count++
+}
+
+func (g *gridIndex) key(pos geometry.Vector2D) gridKey {
+	return gridKey{x: int(pos.X / g.cellSize), y: int(pos.Y / g.cellSize)}
+}
+
+// canMove reports whether the grid can follow the entities with move instead of being rebuilt:
+// it must hold exactly 'n' entities and the cell size must not have changed
+func (g *gridIndex) canMove(n int) bool {
+	return g.count == n && g.cellSize == g.w.getCellSize()
+}
+
+// move updates the membership of the entities that crossed a cell boundary since the last update.
+// Most entities stay in their cell from one tick to the next, so this is much cheaper than a rebuild.
+func (g *gridIndex) move(entities []*Entity) {
+	for _, e := range entities {
+		key := g.key(e.Pos)
+		if key == e.gridCell {
+			continue
+		}
+		// Remove from the previous cell, keeping the order of the others
+		cell := g.w.grid[e.gridCell]
+		for i, other := range cell {
+			if other == e {
+				g.w.grid[e.gridCell] = append(cell[:i], cell[i+1:]...)
+				break
+			}
+		}
+		g.w.grid[key] = append(g.w.grid[key], e)
+		e.gridCell = key
+	}
+}
+
+func (g *gridIndex) Query(center geometry.Vector2D, radius float64, fn func(pos geometry.Vector2D, e *Entity) bool) {
+	radiusSq := radius * radius
+	// Calculate grid bounds that could contain entities within radius
+	minGx := int((center.X - radius) / g.cellSize)
+	maxGx := int((center.X + radius) / g.cellSize)
+	minGy := int((center.Y - radius) / g.cellSize)
+	maxGy := int((center.Y + radius) / g.cellSize)
+
+	for gx := minGx; gx <= maxGx; gx++ {
+		for gy := minGy; gy <= maxGy; gy++ {
+			for _, e := range g.w.grid[gridKey{x: gx, y: gy}] {
+				if e.Pos.DistanceSquaredTo(center) < radiusSq && !fn(e.Pos, e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (g *gridIndex) Len() int {
+	return g.count
+}
+
+// ============================================================================
+// Query visitors: created once per world, so that the per-tick queries do not allocate closures
+// ============================================================================
+
+type scanRanges struct {
+	perceptionSq float64
+	detectionSq  float64
+	contactSq    float64
+}
+
+// neighborScan collects the perception of one entity and its contacts
+type neighborScan struct {
+	w       *world
+	me      *Entity
+	ranges  scanRanges
+	enemies []*pb.ActorState
+	friends []*pb.ActorState
+	visitFn func(pos geometry.Vector2D, other *Entity) bool
+}
+
+func (s *neighborScan) visit(_ geometry.Vector2D, other *Entity) bool {
+	me := s.me
+	if other.ID == me.ID {
+		return true
+	}
+
+	distSq := me.DistanceSquaredTo(other)
+
+	// --- Logic Branching ---
+	if other.Color == me.Color {
+		// Friend Logic: Flocking
+		if distSq < s.ranges.perceptionSq {
+			s.friends = append(s.friends, other.ToProto())
+		}
+	} else if me.Color != pb.TeamColor_TEAM_BLUE || other.Color != NeutralColor {
+		// Enemy Logic: Detection. The reds see the civilians as prey, the blues ignore them
+		if distSq < s.ranges.detectionSq {
+			s.enemies = append(s.enemies, other.ToProto())
+		}
+	}
+
+	// Capture Logic: either team converts the civilians it touches (see neutral.go)
+	if other.Color == NeutralColor && me.Color != NeutralColor {
+		if distSq < s.ranges.contactSq && !s.w.spawnProtected(me) && !s.w.spawnProtected(other) {
+			s.w.captures = append(s.w.captures, contact{attacker: me, victim: other})
+		}
+	}
+
+	// Combat Logic: Red attacks Blue
+	// We check this here to avoid re-iterating neighbors later
+	if me.Color == pb.TeamColor_TEAM_RED && other.Color == pb.TeamColor_TEAM_BLUE {
+		if distSq < s.ranges.contactSq && !s.w.spawnProtected(me) && !s.w.spawnProtected(other) {
+			s.w.contacts = append(s.w.contacts, contact{attacker: me, victim: other})
+		}
+	}
+	return true
+}
+
+// colorCount counts the entities of one color, except one
+type colorCount struct {
+	color     pb.TeamColor
+	excludeID string
+	count     int
+	visitFn   func(pos geometry.Vector2D, e *Entity) bool
+}
+
+func (c *colorCount) visit(_ geometry.Vector2D, e *Entity) bool {
+	// Check ID and Color (the index already checked the distance)
+	if e.Color == c.color && e.ID != c.excludeID {
+		c.count++
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/spatial"
+)
+
+// The auto index measures the density every autoIndexCheckEvery ticks. A query of the uniform grid
+// scans every entity of the 3x3 cells around it: the grid degenerates when the entities pile up in
+// a few cells while the rest of the world is empty, where the quadtree keeps splitting. Uniformly
+// dense worlds stay on the grid, the quadtree would not scan fewer entities there.
+const (
+	autoIndexCheckEvery = 30
+	// Quadtree when an entity shares its cell with more than autoQuadtreeCrowding entities on average
+	// and the occupancy of the cells is autoQuadtreeDispersion times more variable than random positions
+	autoQuadtreeCrowding   = 48.0
+	autoQuadtreeDispersion = 8.0
+	// Back to the grid below these values: the gap avoids switching at every check near the thresholds
+	autoGridCrowding   = 16.0
+	autoGridDispersion = 2.0
+)
+
+// densityStats describes how the entities are spread over the cells of the grid
+type densityStats struct {
+	// Crowding is the mean number of entities in the cell of an entity (itself included)
+	Crowding float64
+	// Dispersion is the variance of the entities per cell over its mean, ~1 for random positions
+	Dispersion float64
+}
+
+// autoIndex switches the world between the uniform grid and a quadtree (Config.SpatialIndex = auto)
+type autoIndex struct {
+	grid     *gridIndex
+	quadtree *spatial.Quadtree[*Entity]
+	counts   []int // Entities per cell, reused by every check
+}
+
+func newAutoIndex(w *world) *autoIndex {
+	bounds := spatial.NewRect(0, 0, w.cfg.WorldWidth, w.cfg.WorldHeight)
+	return &autoIndex{
+		grid:     &gridIndex{w: w},
+		quadtree: spatial.NewQuadtree[*Entity](bounds, spatial.DefaultNodeCapacity, spatial.DefaultMaxDepth),
+	}
+}
+
+// density counts the entities of every cell of the grid covering the world
+func (a *autoIndex) density(w *world) densityStats {
+	cellSize := w.getCellSize()
+	cols := max(int(math.Ceil(w.cfg.WorldWidth/cellSize)), 1)
+	rows := max(int(math.Ceil(w.cfg.WorldHeight/cellSize)), 1)
+	if cap(a.counts) < cols*rows {
+		a.counts = make([]int, cols*rows)
+	}
+	a.counts = a.counts[:cols*rows]
+	clear(a.counts)
+	for _, e := range w.order {
+		// The entities bouncing on the border may be slightly outside
+		x := min(max(int(e.Pos.X/cellSize), 0), cols-1)
+		y := min(max(int(e.Pos.Y/cellSize), 0), rows-1)
+		a.counts[y*cols+x]++
+	}
+
+	n := float64(len(w.order))
+	if n == 0 {
+		return densityStats{}
+	}
+	sumSq := 0.0
+	for _, c := range a.counts {
+		sumSq += float64(c * c)
+	}
+	mean := n / float64(len(a.counts))
+	variance := sumSq/float64(len(a.counts)) - mean*mean
+	return densityStats{Crowding: sumSq / n, Dispersion: variance / mean}
+}
+
+// check measures the density and switches the index of the world when the current one became
+// pathological. The new index is filled by the next rebuild.
+func (a *autoIndex) check(w *world) {
+	d := a.density(w)
+	_, onGrid := w.index.(*gridIndex)
+	switch {
+	case onGrid && d.Crowding > autoQuadtreeCrowding && d.Dispersion > autoQuadtreeDispersion:
+		w.index = a.quadtree
+	case !onGrid && (d.Crowding < autoGridCrowding || d.Dispersion < autoGridDispersion):
+		w.index = a.grid
+	default:
+		return
+	}
+	w.gridDirty = true
+	w.swarm.logger().Infof("🗺️ Tick %d: spatial index switched to %s (%d entities, crowding %.0f per cell, dispersion %.1f)",
+		w.tick, w.spatialIndexName(), len(w.order), d.Crowding, d.Dispersion)
+}
+
+// spatialIndexName returns the name of the index in use
+func (w *world) spatialIndexName() string {
+	if _, ok := w.index.(*gridIndex); ok {
+		return SpatialIndexGrid
+	}
+	return SpatialIndexQuadtree
+}
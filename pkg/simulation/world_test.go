@@ -1,14 +1,20 @@
 package simulation
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pb"
 	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestWorldActor_rebuildGrid(t *testing.T) {
 	// 1. Setup
-	// We need a WorldActor with specific dimensions and radii to determine cell size = max(detection, defense, 10)
+	// We need a world actor with specific dimensions and radii to determine cell size = max(detection, defense, 10)
 	// Let's use detection=100, defense=50 -> cell size = 100
 	cfg := &Config{
 		WorldWidth:      1000,
@@ -16,7 +22,7 @@ func TestWorldActor_rebuildGrid(t *testing.T) {
 		DetectionRadius: 100,
 		DefenseRadius:   50,
 	}
-	w := NewWorldActor(nil, cfg)
+	w := newWorld(nil, cfg)
 
 	// Create some entities
 	a1 := &Entity{ID: "a1", Pos: geometry.Vector2D{X: 50, Y: 50}}   // Grid 0,0
@@ -24,10 +30,10 @@ func TestWorldActor_rebuildGrid(t *testing.T) {
 	a3 := &Entity{ID: "a3", Pos: geometry.Vector2D{X: 50, Y: 150}}  // Grid 0,1
 	a4 := &Entity{ID: "a4", Pos: geometry.Vector2D{X: 250, Y: 250}} // Grid 2,2
 
-	w.entities["a1"] = a1
-	w.entities["a2"] = a2
-	w.entities["a3"] = a3
-	w.entities["a4"] = a4
+	w.addEntity(a1)
+	w.addEntity(a2)
+	w.addEntity(a3)
+	w.addEntity(a4)
 
 	// 2. Execute
 	w.rebuildGrid()
@@ -73,7 +79,7 @@ func TestWorldActor_rebuildGrid(t *testing.T) {
 	}
 }
 
-func TestWorldActor_getNearbyActors(t *testing.T) {
+func TestWorld_indexQuery(t *testing.T) {
 	// Setup: Cell size = 100
 	cfg := &Config{
 		WorldWidth:      1000,
@@ -81,52 +87,27 @@ func TestWorldActor_getNearbyActors(t *testing.T) {
 		DetectionRadius: 100,
 		DefenseRadius:   50,
 	}
-	w := NewWorldActor(nil, cfg)
+	w := newWorld(nil, cfg)
 
-	// Populate grid manually for precise control
-	// Center is 1,1 (x=150, y=150)
-	// Neighbors: 0,0; 1,0; 2,0; 0,1; 2,1; 0,2; 1,2; 2,2
-
-	// Inside 3x3 block centered at 1,1
 	center := &Entity{ID: "center", Pos: geometry.Vector2D{X: 150, Y: 150}}   // 1,1
-	neighbor := &Entity{ID: "neighbor", Pos: geometry.Vector2D{X: 50, Y: 50}} // 0,0
-
-	// Outside 3x3 block
-	farAway := &Entity{ID: "far", Pos: geometry.Vector2D{X: 350, Y: 350}} // 3,3
-
-	w.grid[gridKey{x: 1, y: 1}] = []*Entity{center}
-	w.grid[gridKey{x: 0, y: 0}] = []*Entity{neighbor}
-	w.grid[gridKey{x: 3, y: 3}] = []*Entity{farAway}
-
-	// Execute: Query near center (150, 150) -> Grid 1,1
-	// Should return contents of 0,0 to 2,2
-	result := w.getNearbyActors(150, 150)
-
-	// Verify
-	foundCenter := false
-	foundNeighbor := false
-	foundFar := false
-
-	for _, a := range result {
-		if a.ID == "center" {
-			foundCenter = true
-		}
-		if a.ID == "neighbor" {
-			foundNeighbor = true
-		}
-		if a.ID == "far" {
-			foundFar = true
-		}
+	neighbor := &Entity{ID: "neighbor", Pos: geometry.Vector2D{X: 90, Y: 90}} // 0,0, within the radius
+	corner := &Entity{ID: "corner", Pos: geometry.Vector2D{X: 50, Y: 50}}     // 0,0, out of the radius
+	farAway := &Entity{ID: "far", Pos: geometry.Vector2D{X: 350, Y: 350}}     // 3,3
+	for _, e := range []*Entity{center, neighbor, corner, farAway} {
+		w.addEntity(e)
 	}
+	w.rebuildGrid()
 
-	if !foundCenter {
-		t.Error("Expected to find center actor")
-	}
-	if !foundNeighbor {
-		t.Error("Expected to find neighbor actor (in 0,0)")
-	}
-	if foundFar {
-		t.Error("Should NOT find far actor (in 3,3)")
+	// Execute: every entity within the detection radius of the center, across cells
+	var found []string
+	w.index.Query(center.Pos, cfg.DetectionRadius, func(_ geometry.Vector2D, e *Entity) bool {
+		found = append(found, e.ID)
+		return true
+	})
+
+	slices.Sort(found)
+	if want := []string{"center", "neighbor"}; !slices.Equal(found, want) {
+		t.Errorf("Query found %v, expected %v", found, want)
 	}
 }
 
@@ -138,10 +119,10 @@ func BenchmarkWorldActor_rebuildGrid(b *testing.B) {
 		DetectionRadius: 100,
 		DefenseRadius:   50,
 	}
-	w := NewWorldActor(nil, cfg)
+	w := newWorld(nil, cfg)
 	for i := 0; i < 1000; i++ {
 		id := string(rune(i))
-		w.entities[id] = &Entity{ID: id, Pos: geometry.Vector2D{X: float64(i), Y: float64(i)}}
+		w.addEntity(&Entity{ID: id, Pos: geometry.Vector2D{X: float64(i), Y: float64(i)}})
 	}
 
 	b.ResetTimer()
@@ -150,7 +131,26 @@ func BenchmarkWorldActor_rebuildGrid(b *testing.B) {
 	}
 }
 
-func BenchmarkWorldActor_getNearbyActors(b *testing.B) {
+func BenchmarkWorld_updateGrid(b *testing.B) {
+	cfg := &Config{WorldWidth: 1000, WorldHeight: 1000, DetectionRadius: 100, DefenseRadius: 50}
+	w := newWorld(nil, cfg)
+	for i := 0; i < 5000; i++ {
+		w.addEntity(&Entity{ID: fmt.Sprintf("e%d", i), Pos: geometry.Vector2D{X: float64(i % 1000), Y: float64(i / 5)}})
+	}
+	w.rebuildGrid()
+	w.gridDirty = false
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Small moves, as in a tick: a few entities cross a cell boundary
+		for _, e := range w.order {
+			e.Pos.X = float64(int(e.Pos.X+1) % 1000)
+		}
+		w.updateGrid()
+	}
+}
+
+func BenchmarkWorld_indexQuery(b *testing.B) {
 	// Setup: Populated grid
 	cfg := &Config{
 		WorldWidth:      1000,
@@ -158,18 +158,202 @@ func BenchmarkWorldActor_getNearbyActors(b *testing.B) {
 		DetectionRadius: 100,
 		DefenseRadius:   50,
 	}
-	w := NewWorldActor(nil, cfg)
+	w := newWorld(nil, cfg)
 	// Fill grid with some entities
 	for i := 0; i < 1000; i++ {
 		id := string(rune(i))
 		a := &Entity{ID: id, Pos: geometry.Vector2D{X: float64(i % 1000), Y: float64(i % 1000)}}
-		w.entities[id] = a
+		w.addEntity(a)
 	}
 	w.rebuildGrid()
+	center := geometry.Vector2D{X: 500, Y: 500}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Query middle of the map
-		w.getNearbyActors(500, 500)
+		w.index.Query(center, cfg.DetectionRadius, func(geometry.Vector2D, *Entity) bool { return true })
+	}
+}
+
+// logSwarm is a swarm without individuals that records the log lines of the world
+type logSwarm struct {
+	lines []string
+}
+
+func (s *logSwarm) spawn(string, *individual)         {}
+func (s *logSwarm) spawnCommander(string, *commander) {}
+func (s *logSwarm) tell(string, proto.Message) bool   { return false }
+func (s *logSwarm) logger() Logger                    { return s }
+func (s *logSwarm) Debugf(string, ...any)             {}
+func (s *logSwarm) Info(args ...any)                  { s.lines = append(s.lines, fmt.Sprint(args...)) }
+func (s *logSwarm) Infof(format string, args ...any) {
+	s.lines = append(s.lines, fmt.Sprintf(format, args...))
+}
+func (s *logSwarm) Errorf(format string, args ...any) {
+	s.lines = append(s.lines, fmt.Sprintf(format, args...))
+}
+
+func TestWorld_logBenchmarksInSimTime(t *testing.T) {
+	swarm := &logSwarm{}
+	w := newWorld(make(chan *pb.WorldSnapshot, 1), &Config{WorldWidth: 100, WorldHeight: 100})
+	w.swarm = swarm
+
+	for i := 0; i < benchmarkIntervalTicks-1; i++ {
+		w.handle(&pb.Tick{})
+	}
+	if len(swarm.lines) != 0 {
+		t.Fatalf("Expected no telemetry before %d ticks, got %v", benchmarkIntervalTicks, swarm.lines)
+	}
+	w.handle(&pb.Tick{})
+	if len(swarm.lines) != 1 || !strings.Contains(swarm.lines[0], "Ticks 1-300: 5s sim") {
+		t.Fatalf("Expected one report of 5s of simulation time, got %v", swarm.lines)
+	}
+	if got := SimTime(90); got.Seconds() != 1.5 {
+		t.Errorf("Expected 90 ticks to last 1.5s, got %s", got)
+	}
+}
+
+func TestWorld_spatialIndexesAgree(t *testing.T) {
+	worlds := map[string]*world{}
+	for _, name := range []string{SpatialIndexGrid, SpatialIndexQuadtree} {
+		cfg := &Config{WorldWidth: 1000, WorldHeight: 1000, DetectionRadius: 60, DefenseRadius: 30, VisualRange: 40, SpatialIndex: name}
+		w := newWorld(nil, cfg)
+		w.swarm = &logSwarm{}
+		// All blues clumped in a corner, a few reds around
+		for i := 0; i < 400; i++ {
+			w.addEntity(&Entity{ID: fmt.Sprintf("b%d", i), Color: pb.TeamColor_TEAM_BLUE,
+				Pos: geometry.Vector2D{X: float64(i%20) * 2.5, Y: float64(i/20) * 2.5}})
+		}
+		for i := 0; i < 10; i++ {
+			w.addEntity(&Entity{ID: fmt.Sprintf("r%d", i), Color: pb.TeamColor_TEAM_RED,
+				Pos: geometry.Vector2D{X: float64(i) * 40, Y: float64(i) * 30}})
+		}
+		w.rebuildGrid()
+		worlds[name] = w
+	}
+
+	grid, quad := worlds[SpatialIndexGrid], worlds[SpatialIndexQuadtree]
+	if _, ok := quad.index.(*gridIndex); ok {
+		t.Fatalf("Expected the quadtree to be selected")
+	}
+	ranges := scanRanges{perceptionSq: 40 * 40, detectionSq: 60 * 60}
+	for _, e := range grid.order {
+		center := e.Pos
+		if a, b := grid.countFriendsInRadius(center, 30, pb.TeamColor_TEAM_BLUE, e.ID), quad.countFriendsInRadius(center, 30, pb.TeamColor_TEAM_BLUE, e.ID); a != b {
+			t.Fatalf("%s: grid counts %d blues, quadtree %d", e.ID, a, b)
+		}
+		ge, gf := grid.scanNeighbors(e, ranges)
+		qe, qf := quad.scanNeighbors(quad.entities[e.ID], ranges)
+		if len(ge) != len(qe) || len(gf) != len(qf) {
+			t.Fatalf("%s: grid sees %d/%d enemies/friends, quadtree %d/%d", e.ID, len(ge), len(gf), len(qe), len(qf))
+		}
+	}
+}
+
+func TestWorld_entityRandIgnoresPopulation(t *testing.T) {
+	members := func(numBlue int) map[string]*individual {
+		cfg := DefaultConfig()
+		cfg.Seed = 42
+		cfg.NumRedAtStart = 3
+		cfg.NumBlueAtStart = numBlue
+		engine, err := NewLocalEngine(context.Background(), make(chan *pb.WorldSnapshot, 1), cfg)
+		if err != nil {
+			t.Fatalf("NewLocalEngine failed: %v", err)
+		}
+		return engine.(*localEngine).members
+	}
+	small, large := members(10), members(11)
+
+	// One more blue does not change the random sequences of the others
+	for _, id := range []string{"Red-000", "Red-002", "Blue-000", "Blue-009"} {
+		a, b := small[id].State.Rand, large[id].State.Rand
+		for n := 0; n < 5; n++ {
+			if x, y := a.Float64(), b.Float64(); x != y {
+				t.Fatalf("%s: draw %d differs with one more entity (%f != %f)", id, n, x, y)
+			}
+		}
+	}
+	// But every entity has its own stream
+	if small["Blue-000"].State.Rand.Float64() == small["Blue-001"].State.Rand.Float64() {
+		t.Errorf("Expected Blue-000 and Blue-001 to draw different numbers")
+	}
+}
+
+func TestWorld_updateGridMovesOnlyCrossingEntities(t *testing.T) {
+	cfg := &Config{WorldWidth: 1000, WorldHeight: 1000, DetectionRadius: 100, DefenseRadius: 50}
+	w := newWorld(nil, cfg)
+	stay := &Entity{ID: "stay", Pos: geometry.Vector2D{X: 10, Y: 10}}
+	mover := &Entity{ID: "mover", Pos: geometry.Vector2D{X: 20, Y: 20}}
+	w.addEntity(stay)
+	w.addEntity(mover)
+	w.updateGrid()
+	if w.gridDirty {
+		t.Fatalf("Expected the rebuild to clear gridDirty")
+	}
+
+	stay.Pos = geometry.Vector2D{X: 90, Y: 90}
+	mover.Pos = geometry.Vector2D{X: 150, Y: 20}
+	w.updateGrid()
+	if len(w.grid[gridKey{0, 0}]) != 1 || w.grid[gridKey{0, 0}][0] != stay {
+		t.Errorf("Expected only 'stay' in cell (0,0), got %v", w.grid[gridKey{0, 0}])
+	}
+	if len(w.grid[gridKey{1, 0}]) != 1 || w.grid[gridKey{1, 0}][0] != mover {
+		t.Errorf("Expected 'mover' in cell (1,0), got %v", w.grid[gridKey{1, 0}])
+	}
+
+	// A new cell size forces a full rebuild
+	w.cfg.DetectionRadius = 200
+	w.syncRadii()
+	w.updateGrid()
+	if len(w.grid[gridKey{0, 0}]) != 2 {
+		t.Errorf("Expected both entities in cell (0,0) after the cell size change, got %d", len(w.grid[gridKey{0, 0}]))
+	}
+}
+
+func TestWorld_autoSpatialIndex(t *testing.T) {
+	cfg := &Config{WorldWidth: 1000, WorldHeight: 1000, DetectionRadius: 60, DefenseRadius: 30, VisualRange: 40, SpatialIndex: SpatialIndexAuto}
+	w := newWorld(nil, cfg)
+	swarm := &logSwarm{}
+	w.swarm = swarm
+	// A uniform population stays on the grid
+	for i := 0; i < 400; i++ {
+		w.addEntity(&Entity{ID: fmt.Sprintf("b%d", i), Color: pb.TeamColor_TEAM_BLUE,
+			Pos: geometry.Vector2D{X: float64(i%20)*50 + 10, Y: float64(i/20)*50 + 10}})
+	}
+	w.tick = autoIndexCheckEvery
+	w.updateGrid()
+	if w.spatialIndexName() != SpatialIndexGrid {
+		t.Fatalf("Expected a uniform population to stay on the grid")
+	}
+
+	// Everyone clumps in a corner: the next check moves to the quadtree
+	for i, e := range w.order {
+		e.Pos = geometry.Vector2D{X: float64(i%20) * 2.5, Y: float64(i/20) * 2.5}
+	}
+	w.tick++
+	w.updateGrid()
+	if w.spatialIndexName() != SpatialIndexGrid {
+		t.Fatalf("Expected the index to change only on the check ticks")
+	}
+	w.tick = 2 * autoIndexCheckEvery
+	w.updateGrid()
+	if w.spatialIndexName() != SpatialIndexQuadtree || w.index.Len() != 400 {
+		t.Fatalf("Expected the clump to switch to a filled quadtree, got %s with %d entities", w.spatialIndexName(), w.index.Len())
+	}
+	if n := w.countFriendsInRadius(geometry.Vector2D{}, 10, pb.TeamColor_TEAM_BLUE, ""); n == 0 {
+		t.Errorf("Expected the quadtree to answer the queries")
+	}
+	if len(swarm.lines) != 1 || !strings.Contains(swarm.lines[0], "switched to quadtree") {
+		t.Errorf("Expected the switch to be logged, got %v", swarm.lines)
+	}
+
+	// The flock spreads again
+	for i, e := range w.order {
+		e.Pos = geometry.Vector2D{X: float64(i%20)*50 + 10, Y: float64(i/20)*50 + 10}
+	}
+	w.tick = 3 * autoIndexCheckEvery
+	w.updateGrid()
+	if w.spatialIndexName() != SpatialIndexGrid || w.index.Len() != 400 {
+		t.Fatalf("Expected the spread flock to switch back to a filled grid, got %s with %d entities", w.spatialIndexName(), w.index.Len())
 	}
 }
@@ -0,0 +1,88 @@
+// Package sound synthesizes the built-in sound effects of the simulation as 16-bit little-endian
+// stereo PCM, the format played by ebiten/audio, so that the game has sounds without asset files.
+package sound
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// SampleRate is the sample rate of the synthesized sounds, in Hz
+const SampleRate = 44100
+
+// bytesPerFrame is the size of one stereo frame: two 16-bit samples
+const bytesPerFrame = 4
+
+// Conversion is a short rising chirp with a fast decay, played when an entity switches team
+func Conversion(sampleRate int) []byte {
+	const duration = 0.09
+	return synth(sampleRate, duration, func(t float64) float64 {
+		// The frequency sweeps from 600 to 1400 Hz: the phase is its integral
+		phase := 2 * math.Pi * (600*t + (1400-600)*t*t/(2*duration))
+		return 0.5 * math.Sin(phase) * math.Exp(-t*30)
+	})
+}
+
+// GameOver is a rising major arpeggio ending on a held chord
+func GameOver(sampleRate int) []byte {
+	notes := []float64{523.25, 659.25, 783.99} // C5, E5, G5
+	const step, duration = 0.15, 1.4
+	return synth(sampleRate, duration, func(t float64) float64 {
+		v := 0.0
+		for i, f := range notes {
+			start := float64(i) * step
+			if t < start {
+				break
+			}
+			dt := t - start
+			// Soft attack, then a slow decay
+			env := min(dt/0.01, 1) * math.Exp(-dt*2.5)
+			v += 0.25 * env * (math.Sin(2*math.Pi*f*dt) + 0.3*math.Sin(4*math.Pi*f*dt))
+		}
+		return v
+	})
+}
+
+// AmbientLoopSeconds is the length of the Ambient loop
+const AmbientLoopSeconds = 8
+
+// Ambient is a low drone slowly beating and swelling. Every partial completes a whole number of
+// periods in AmbientLoopSeconds, so the sound loops without a click.
+func Ambient(sampleRate int) []byte {
+	// Frequencies in multiples of 1/AmbientLoopSeconds Hz
+	partials := []struct{ freq, amp float64 }{
+		{55, 0.20}, {55.25, 0.15}, {82.5, 0.10}, {110.125, 0.06}, {165, 0.03},
+	}
+	return synth(sampleRate, AmbientLoopSeconds, func(t float64) float64 {
+		v := 0.0
+		for _, p := range partials {
+			v += p.amp * math.Sin(2*math.Pi*p.freq*t)
+		}
+		// One swell per loop
+		return v * (0.75 + 0.25*math.Sin(2*math.Pi*t/AmbientLoopSeconds))
+	})
+}
+
+// synth samples fn (a signal in [-1, 1] of the time in seconds) for 'duration' seconds,
+// the same value on both channels
+func synth(sampleRate int, duration float64, fn func(t float64) float64) []byte {
+	frames := int(duration * float64(sampleRate))
+	buf := make([]byte, frames*bytesPerFrame)
+	for i := range frames {
+		v := fn(float64(i) / float64(sampleRate))
+		s := uint16(int16(math.Round(min(max(v, -1), 1) * math.MaxInt16)))
+		binary.LittleEndian.PutUint16(buf[i*bytesPerFrame:], s)
+		binary.LittleEndian.PutUint16(buf[i*bytesPerFrame+2:], s)
+	}
+	return buf
+}
+
+// Sample returns the left sample of the frame 'i' of a PCM buffer, in [-1, 1]
+func Sample(pcm []byte, i int) float64 {
+	return float64(int16(binary.LittleEndian.Uint16(pcm[i*bytesPerFrame:]))) / math.MaxInt16
+}
+
+// Frames returns the number of stereo frames of a PCM buffer
+func Frames(pcm []byte) int {
+	return len(pcm) / bytesPerFrame
+}
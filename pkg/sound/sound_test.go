@@ -0,0 +1,47 @@
+package sound
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		pcm      []byte
+		duration float64
+	}{
+		{"conversion", Conversion(SampleRate), 0.09},
+		{"game over", GameOver(SampleRate), 1.4},
+		{"ambient", Ambient(SampleRate), AmbientLoopSeconds},
+	}
+	for _, tt := range tests {
+		if got := float64(Frames(tt.pcm)) / SampleRate; math.Abs(got-tt.duration) > 0.001 {
+			t.Errorf("%s: expected %.2fs, got %.2fs", tt.name, tt.duration, got)
+		}
+		peak := 0.0
+		for i := range Frames(tt.pcm) {
+			peak = max(peak, math.Abs(Sample(tt.pcm, i)))
+		}
+		if peak < 0.1 || peak > 1 {
+			t.Errorf("%s: unexpected peak %.3f", tt.name, peak)
+		}
+		// Both channels carry the same signal
+		if tt.pcm[0] != tt.pcm[2] || tt.pcm[1] != tt.pcm[3] {
+			t.Errorf("%s: expected the same left and right samples", tt.name)
+		}
+	}
+}
+
+func TestAmbientLoops(t *testing.T) {
+	pcm := Ambient(SampleRate)
+	last, first := Sample(pcm, Frames(pcm)-1), Sample(pcm, 0)
+	// The step across the loop point is not larger than between two frames inside the loop
+	maxStep := 0.0
+	for i := 1; i < Frames(pcm); i++ {
+		maxStep = max(maxStep, math.Abs(Sample(pcm, i)-Sample(pcm, i-1)))
+	}
+	if step := math.Abs(first - last); step > maxStep {
+		t.Errorf("Expected a seamless loop, the step at the loop point is %.4f (max inside %.4f)", step, maxStep)
+	}
+}
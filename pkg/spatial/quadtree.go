@@ -0,0 +1,185 @@
+package spatial
+
+import (
+	"unsafe"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// Quadtree defaults
+const (
+	DefaultNodeCapacity = 8  // Items in a leaf before it splits
+	DefaultMaxDepth     = 10 // Leaves at this depth never split (many items at the same spot)
+)
+
+type entry[T any] struct {
+	pos  geometry.Vector2D
+	item T
+}
+
+// quadNode is stored by value in Quadtree.nodes, children are the 4 consecutive nodes at 'child'
+type quadNode[T any] struct {
+	bounds Rect
+	items  []entry[T]
+	child  int32 // Index of the first child, -1 for a leaf
+	depth  int32
+}
+
+// Quadtree is a point quadtree: cells split when they hold more than a few items, so queries
+// stay fast when the density is very uneven (e.g. a whole flock clumped in one corner), where
+// a uniform grid puts hundreds of items in the same cells.
+// Points outside of the bounds are kept in a separate list scanned by every query.
+type Quadtree[T any] struct {
+	nodes    []quadNode[T]
+	outside  []entry[T]
+	capacity int
+	maxDepth int32
+	count    int
+}
+
+var _ SpatialIndex[int] = (*Quadtree[int])(nil)
+
+// NewQuadtree creates a quadtree covering 'bounds', with DefaultNodeCapacity and DefaultMaxDepth
+// when 'capacity' or 'maxDepth' are <= 0
+func NewQuadtree[T any](bounds Rect, capacity, maxDepth int) *Quadtree[T] {
+	if capacity <= 0 {
+		capacity = DefaultNodeCapacity
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	q := &Quadtree[T]{capacity: capacity, maxDepth: int32(maxDepth)}
+	q.nodes = append(q.nodes, quadNode[T]{bounds: bounds, child: -1})
+	return q
+}
+
+// Bounds returns the area covered by the tree
+func (q *Quadtree[T]) Bounds() Rect {
+	return q.nodes[0].bounds
+}
+
+func (q *Quadtree[T]) Clear() {
+	root := &q.nodes[0]
+	root.items = root.items[:0]
+	root.child = -1
+	q.nodes = q.nodes[:1]
+	q.outside = q.outside[:0]
+	q.count = 0
+}
+
+func (q *Quadtree[T]) Len() int {
+	return q.count
+}
+
+// MemoryBytes returns the approximate memory held by the tree, items included
+func (q *Quadtree[T]) MemoryBytes() int64 {
+	entrySize := int64(unsafe.Sizeof(entry[T]{}))
+	n := int64(cap(q.nodes))*int64(unsafe.Sizeof(quadNode[T]{})) + int64(cap(q.outside))*entrySize
+	for _, node := range q.nodes[:cap(q.nodes)] {
+		n += int64(cap(node.items)) * entrySize
+	}
+	return n
+}
+
+func (q *Quadtree[T]) Insert(pos geometry.Vector2D, item T) {
+	q.count++
+	if !q.nodes[0].bounds.Contains(pos) {
+		q.outside = append(q.outside, entry[T]{pos: pos, item: item})
+		return
+	}
+	q.insert(0, entry[T]{pos: pos, item: item})
+}
+
+func (q *Quadtree[T]) insert(idx int32, e entry[T]) {
+	for {
+		n := &q.nodes[idx]
+		if n.child < 0 {
+			if len(n.items) < q.capacity || n.depth >= q.maxDepth {
+				n.items = append(n.items, e)
+				return
+			}
+			q.split(idx)
+			n = &q.nodes[idx] // split may have moved the nodes
+		}
+		idx = n.child + q.quadrant(n.bounds, e.pos)
+	}
+}
+
+// split turns a full leaf into an inner node and moves its items to the 4 new children
+func (q *Quadtree[T]) split(idx int32) {
+	first := int32(len(q.nodes))
+	b := q.nodes[idx].bounds
+	mid := geometry.Vector2D{X: (b.Min.X + b.Max.X) / 2, Y: (b.Min.Y + b.Max.Y) / 2}
+	quads := [4]Rect{
+		{Min: b.Min, Max: mid},
+		{Min: geometry.Vector2D{X: mid.X, Y: b.Min.Y}, Max: geometry.Vector2D{X: b.Max.X, Y: mid.Y}},
+		{Min: geometry.Vector2D{X: b.Min.X, Y: mid.Y}, Max: geometry.Vector2D{X: mid.X, Y: b.Max.Y}},
+		{Min: mid, Max: b.Max},
+	}
+	depth := q.nodes[idx].depth + 1
+	for _, r := range quads {
+		// Reuse the nodes (and their item arrays) left over by the previous Clear
+		if len(q.nodes) < cap(q.nodes) {
+			q.nodes = q.nodes[:len(q.nodes)+1]
+			n := &q.nodes[len(q.nodes)-1]
+			n.bounds, n.items, n.child, n.depth = r, n.items[:0], -1, depth
+		} else {
+			q.nodes = append(q.nodes, quadNode[T]{bounds: r, child: -1, depth: depth})
+		}
+	}
+
+	n := &q.nodes[idx]
+	items := n.items
+	n.items = n.items[:0]
+	n.child = first
+	for _, e := range items {
+		child := &q.nodes[first+q.quadrant(b, e.pos)]
+		child.items = append(child.items, e)
+	}
+}
+
+// quadrant returns the child (0: top-left, 1: top-right, 2: bottom-left, 3: bottom-right) containing 'p'
+func (q *Quadtree[T]) quadrant(b Rect, p geometry.Vector2D) int32 {
+	var quad int32
+	if p.X >= (b.Min.X+b.Max.X)/2 {
+		quad |= 1
+	}
+	if p.Y >= (b.Min.Y+b.Max.Y)/2 {
+		quad |= 2
+	}
+	return quad
+}
+
+func (q *Quadtree[T]) Query(center geometry.Vector2D, radius float64, fn func(pos geometry.Vector2D, item T) bool) {
+	radiusSq := radius * radius
+	if !q.query(0, center, radius, radiusSq, fn) {
+		return
+	}
+	for _, e := range q.outside {
+		if e.pos.DistanceSquaredTo(center) < radiusSq && !fn(e.pos, e.item) {
+			return
+		}
+	}
+}
+
+// query visits node 'idx' and its children, it returns false when fn asked to stop
+func (q *Quadtree[T]) query(idx int32, center geometry.Vector2D, radius, radiusSq float64, fn func(pos geometry.Vector2D, item T) bool) bool {
+	n := &q.nodes[idx]
+	if !n.bounds.IntersectsCircle(center, radius) {
+		return true
+	}
+	for _, e := range n.items {
+		if e.pos.DistanceSquaredTo(center) < radiusSq && !fn(e.pos, e.item) {
+			return false
+		}
+	}
+	if n.child < 0 {
+		return true
+	}
+	for c := n.child; c < n.child+4; c++ {
+		if !q.query(c, center, radius, radiusSq, fn) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package spatial
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+)
+
+// clumpedPoints returns 'n' points, 90% of them in a 50x50 corner of a 1000x1000 world
+func clumpedPoints(n int, rng *rand.Rand) []geometry.Vector2D {
+	points := make([]geometry.Vector2D, n)
+	for i := range points {
+		size := 1000.0
+		if i%10 != 0 {
+			size = 50
+		}
+		points[i] = geometry.Vector2D{X: rng.Float64() * size, Y: rng.Float64() * size}
+	}
+	return points
+}
+
+func bruteForce(points []geometry.Vector2D, center geometry.Vector2D, radius float64) []int {
+	var ids []int
+	for i, p := range points {
+		if p.DistanceSquaredTo(center) < radius*radius {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+func TestQuadtreeMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	q := NewQuadtree[int](NewRect(0, 0, 1000, 1000), 4, 0)
+	for round := 0; round < 3; round++ {
+		points := clumpedPoints(2000, rng)
+		// Some points wander out of the world
+		points = append(points, geometry.Vector2D{X: -5, Y: 20}, geometry.Vector2D{X: 1000, Y: 1003})
+
+		q.Clear()
+		for i, p := range points {
+			q.Insert(p, i)
+		}
+		if q.Len() != len(points) {
+			t.Fatalf("Expected %d items, got %d", len(points), q.Len())
+		}
+		for _, center := range []geometry.Vector2D{{X: 25, Y: 25}, {X: 500, Y: 500}, {X: 0, Y: 20}, {X: 999, Y: 999}} {
+			for _, radius := range []float64{5, 30, 120} {
+				var got []int
+				q.Query(center, radius, func(_ geometry.Vector2D, id int) bool {
+					got = append(got, id)
+					return true
+				})
+				slices.Sort(got)
+				if want := bruteForce(points, center, radius); !slices.Equal(got, want) {
+					t.Errorf("Round %d, query %v r=%.0f: expected %d items, got %d", round, center, radius, len(want), len(got))
+				}
+			}
+		}
+	}
+}
+
+func TestQuadtreeStopsEarlyAndHandlesDuplicates(t *testing.T) {
+	q := NewQuadtree[int](NewRect(0, 0, 100, 100), 2, 3)
+	// Many items on the same spot: the depth limit keeps them in one leaf
+	for i := 0; i < 50; i++ {
+		q.Insert(geometry.Vector2D{X: 10, Y: 10}, i)
+	}
+	visited := 0
+	q.Query(geometry.Vector2D{X: 10, Y: 10}, 1, func(geometry.Vector2D, int) bool {
+		visited++
+		return visited < 7
+	})
+	if visited != 7 {
+		t.Errorf("Expected the query to stop after 7 items, visited %d", visited)
+	}
+	// 50 entries of a position and an int, at least
+	if got := q.MemoryBytes(); got < 50*24 {
+		t.Errorf("Expected MemoryBytes to count the items, got %d", got)
+	}
+}
+
+func TestRectIntersectsCircle(t *testing.T) {
+	r := NewRect(0, 0, 10, 10)
+	if !r.IntersectsCircle(geometry.Vector2D{X: 5, Y: 5}, 1) || !r.IntersectsCircle(geometry.Vector2D{X: 12, Y: 5}, 3) {
+		t.Errorf("Expected the circles to overlap the rectangle")
+	}
+	if r.IntersectsCircle(geometry.Vector2D{X: 13, Y: 13}, 3) {
+		t.Errorf("Expected a circle near the corner not to overlap")
+	}
+}
+
+func BenchmarkQuadtreeClumped(b *testing.B) {
+	points := clumpedPoints(5000, rand.New(rand.NewPCG(1, 2)))
+	q := NewQuadtree[int](NewRect(0, 0, 1000, 1000), 0, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Clear()
+		for id, p := range points {
+			q.Insert(p, id)
+		}
+		count := 0
+		for _, p := range points[:500] {
+			q.Query(p, 10, func(geometry.Vector2D, int) bool {
+				count++
+				return true
+			})
+		}
+	}
+}
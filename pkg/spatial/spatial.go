@@ -0,0 +1,43 @@
+// Package spatial provides spatial indexes answering "what is within this radius" queries
+// over a set of 2D points, rebuilt from scratch every simulation step.
+package spatial
+
+import "github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/geometry"
+
+// SpatialIndex stores items by position. It is meant to be cleared and refilled every tick:
+// implementations keep their memory across Clear calls.
+type SpatialIndex[T any] interface {
+	// Clear removes every item, keeping the allocated memory
+	Clear()
+	// Insert adds an item at 'pos'
+	Insert(pos geometry.Vector2D, item T)
+	// Query calls fn for every item strictly within 'radius' of 'center', until fn returns false.
+	// The order of the items is deterministic for a given sequence of inserts.
+	Query(center geometry.Vector2D, radius float64, fn func(pos geometry.Vector2D, item T) bool)
+	// Len returns the number of items
+	Len() int
+}
+
+// Rect is an axis-aligned rectangle, Min included and Max excluded
+type Rect struct {
+	Min, Max geometry.Vector2D
+}
+
+// NewRect returns the rectangle of origin (x, y) and size w x h
+func NewRect(x, y, w, h float64) Rect {
+	return Rect{Min: geometry.Vector2D{X: x, Y: y}, Max: geometry.Vector2D{X: x + w, Y: y + h}}
+}
+
+// Contains reports whether 'p' is inside the rectangle
+func (r Rect) Contains(p geometry.Vector2D) bool {
+	return p.X >= r.Min.X && p.X < r.Max.X && p.Y >= r.Min.Y && p.Y < r.Max.Y
+}
+
+// IntersectsCircle reports whether the circle of 'center' and 'radius' overlaps the rectangle
+func (r Rect) IntersectsCircle(center geometry.Vector2D, radius float64) bool {
+	// Closest point of the rectangle to the center
+	cx := min(max(center.X, r.Min.X), r.Max.X)
+	cy := min(max(center.Y, r.Min.Y), r.Max.Y)
+	dx, dy := center.X-cx, center.Y-cy
+	return dx*dx+dy*dy < radius*radius
+}
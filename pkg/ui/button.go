@@ -16,24 +16,26 @@ type Button struct {
 	clicked bool   // Track if already clicked this frame
 	OnClick func() // Callback function
 
-	// Styling
-	BGColor    color.RGBA
-	HoverColor color.RGBA
-	TextColor  color.RGBA
+	// Styling, from the theme by default
+	BGColor     color.RGBA
+	HoverColor  color.RGBA
+	TextColor   color.RGBA
+	BorderColor color.RGBA
 }
 
 // NewButton creates a new button instance
 func NewButton(x, y, width, height float64, label string, onClick func()) *Button {
 	return &Button{
-		Label:      label,
-		X:          x,
-		Y:          y,
-		Width:      width,
-		Height:     height,
-		OnClick:    onClick,
-		BGColor:    color.RGBA{R: 80, G: 120, B: 180, A: 255},
-		HoverColor: color.RGBA{R: 100, G: 150, B: 220, A: 255},
-		TextColor:  color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		Label:       label,
+		X:           x,
+		Y:           y,
+		Width:       width,
+		Height:      height,
+		OnClick:     onClick,
+		BGColor:     theme.Accent,
+		HoverColor:  theme.AccentHover,
+		TextColor:   theme.AccentText,
+		BorderColor: theme.ControlBorder,
 	}
 }
 
@@ -78,5 +80,5 @@ func (b *Button) Draw(screen *ebiten.Image) {
 	vector.StrokeRect(screen,
 		float32(b.X), float32(b.Y),
 		float32(b.Width), float32(b.Height),
-		2, color.RGBA{R: 200, G: 200, B: 200, A: 255}, true)
+		2, b.BorderColor, true)
 }
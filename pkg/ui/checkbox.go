@@ -14,16 +14,22 @@ type Checkbox struct {
 	X, Y    float64
 	Size    float64
 	clicked bool // Track if already clicked this frame
+
+	// Styling, from the theme by default
+	BorderColor color.RGBA
+	CheckColor  color.RGBA
 }
 
 // NewCheckbox creates a new checkbox instance
 func NewCheckbox(x, y float64, label string, value bool) *Checkbox {
 	return &Checkbox{
-		Label: label,
-		Value: value,
-		X:     x,
-		Y:     y,
-		Size:  16, // Default size
+		Label:       label,
+		Value:       value,
+		X:           x,
+		Y:           y,
+		Size:        16 * theme.FontScale, // Default size, grows with the text
+		BorderColor: theme.ControlBorder,
+		CheckColor:  theme.Check,
 	}
 }
 
@@ -53,7 +59,7 @@ func (c *Checkbox) Draw(screen *ebiten.Image) {
 		float32(c.X), float32(c.Y),
 		float32(c.Size), float32(c.Size),
 		2,
-		color.RGBA{R: 200, G: 200, B: 200, A: 255},
+		c.BorderColor,
 		true)
 
 	// Fill if checked
@@ -61,7 +67,7 @@ func (c *Checkbox) Draw(screen *ebiten.Image) {
 		vector.FillRect(screen,
 			float32(c.X+2), float32(c.Y+2),
 			float32(c.Size-4), float32(c.Size-4),
-			color.RGBA{R: 100, G: 200, B: 100, A: 255},
+			c.CheckColor,
 			true)
 	}
 }
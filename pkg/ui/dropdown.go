@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Dropdown is a select widget: a click opens the list of its options below it,
+// a click on an option selects it and closes the list, a click elsewhere only closes it
+type Dropdown struct {
+	Label    string
+	Options  []string
+	Selected int // Index in Options
+	Open     bool
+	X, Y     float64
+	W, H     float64
+	// OnChange is called when another option is selected
+	OnChange func(index int, option string)
+
+	// Styling, from the theme by default
+	BGColor     color.RGBA
+	HoverColor  color.RGBA
+	TextColor   color.RGBA
+	BorderColor color.RGBA
+}
+
+// NewDropdown creates a new dropdown instance with 'selected' chosen
+func NewDropdown(x, y, w float64, label string, options []string, selected int) *Dropdown {
+	return &Dropdown{
+		Label:       label,
+		Options:     options,
+		Selected:    selected,
+		X:           x,
+		Y:           y,
+		W:           w,
+		H:           controlHeight(), // Default height
+		BGColor:     theme.Control,
+		HoverColor:  theme.Accent,
+		TextColor:   theme.Text,
+		BorderColor: theme.ControlBorder,
+	}
+}
+
+// Value returns the selected option, "" when there is none
+func (d *Dropdown) Value() string {
+	if d.Selected < 0 || d.Selected >= len(d.Options) {
+		return ""
+	}
+	return d.Options[d.Selected]
+}
+
+// Select chooses the option equal to 'option', it returns false when there is none
+func (d *Dropdown) Select(option string) bool {
+	for i, o := range d.Options {
+		if o == option {
+			d.Selected = i
+			return true
+		}
+	}
+	return false
+}
+
+// optionAt returns the index of the option under (x, y) in the open list, -1 outside of it
+func (d *Dropdown) optionAt(x, y float64) int {
+	if x < d.X || x > d.X+d.W || y < d.Y+d.H {
+		return -1
+	}
+	i := int((y - d.Y - d.H) / d.H)
+	if i >= len(d.Options) {
+		return -1
+	}
+	return i
+}
+
+// Contains reports whether (x, y) is on the dropdown, its open list included
+func (d *Dropdown) Contains(x, y float64) bool {
+	if x < d.X || x > d.X+d.W || y < d.Y {
+		return false
+	}
+	height := d.H
+	if d.Open {
+		height += d.H * float64(len(d.Options))
+	}
+	return y <= d.Y+height
+}
+
+// Update opens and closes the list, and selects the clicked option
+func (d *Dropdown) Update() {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	x, y := float64(mx), float64(my)
+	if d.Open {
+		d.Open = false
+		if i := d.optionAt(x, y); i >= 0 && i != d.Selected {
+			d.Selected = i
+			if d.OnChange != nil {
+				d.OnChange(i, d.Options[i])
+			}
+		}
+		return
+	}
+	if x >= d.X && x <= d.X+d.W && y >= d.Y && y <= d.Y+d.H {
+		d.Open = true
+	}
+}
+
+// Draw renders the box with the selected option, the list is drawn by DrawList
+func (d *Dropdown) Draw(screen *ebiten.Image) {
+	vector.FillRect(screen,
+		float32(d.X), float32(d.Y),
+		float32(d.W), float32(d.H),
+		d.BGColor, true)
+	vector.StrokeRect(screen,
+		float32(d.X), float32(d.Y),
+		float32(d.W), float32(d.H),
+		2, d.BorderColor, true)
+	DrawText(screen, d.Value(), int(d.X)+4, int(d.Y)+2, d.TextColor)
+	// Arrow on the right side
+	arrow := "v"
+	if d.Open {
+		arrow = "^"
+	}
+	DrawText(screen, arrow, int(d.X+d.W-textWidth(arrow)-6), int(d.Y)+2, d.TextColor)
+}
+
+// DrawList renders the open list of the options below the box. It is drawn after
+// every other widget, so that the list stays above those it covers.
+func (d *Dropdown) DrawList(screen *ebiten.Image) {
+	if !d.Open {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	hovered := d.optionAt(float64(mx), float64(my))
+	for i, option := range d.Options {
+		y := d.Y + d.H*float64(i+1)
+		bg, text := d.BGColor, d.TextColor
+		if i == hovered {
+			bg, text = d.HoverColor, theme.AccentText
+		}
+		vector.FillRect(screen,
+			float32(d.X), float32(y),
+			float32(d.W), float32(d.H),
+			bg, true)
+		DrawText(screen, option, int(d.X)+4, int(y)+2, text)
+	}
+	vector.StrokeRect(screen,
+		float32(d.X), float32(d.Y+d.H),
+		float32(d.W), float32(d.H*float64(len(d.Options))),
+		1, d.BorderColor, true)
+}
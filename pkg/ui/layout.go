@@ -0,0 +1,59 @@
+package ui
+
+// Anchor is the corner, edge or center of the screen a box of a Layout sticks to
+type Anchor uint8
+
+const (
+	AnchorTopLeft Anchor = iota
+	AnchorTop            // Centered horizontally at the top
+	AnchorTopRight
+	AnchorBottomLeft
+	AnchorBottom // Centered horizontally at the bottom
+	AnchorBottomRight
+	AnchorCenter // Centered on the screen, the boxes do not stack
+	anchorCount
+)
+
+// Layout places the overlays at the edges of the screen, so that they follow the size of the window
+// instead of fixed coordinates. The boxes anchored to the same point stack in the order they are placed:
+// downward from the top anchors, upward from the bottom ones, with Margin between them and from the edges.
+// A box that is not drawn is simply not placed, the next ones take its room.
+// Reset it with the size of the screen at every frame, before placing the boxes.
+type Layout struct {
+	Width, Height float64 // Size of the screen
+	Margin        float64
+	used          [anchorCount]float64 // Height taken by the boxes placed at each anchor
+}
+
+// NewLayout returns an empty layout of a screen of width x height
+func NewLayout(width, height, margin float64) *Layout {
+	return &Layout{Width: width, Height: height, Margin: margin}
+}
+
+// Reset empties the layout for a screen of width x height
+func (l *Layout) Reset(width, height float64) {
+	l.Width, l.Height = width, height
+	l.used = [anchorCount]float64{}
+}
+
+// Place returns the top left corner of a box of w x h at the anchor 'a', after the boxes placed before
+func (l *Layout) Place(a Anchor, w, h float64) (x, y float64) {
+	switch a {
+	case AnchorTopLeft, AnchorBottomLeft:
+		x = l.Margin
+	case AnchorTopRight, AnchorBottomRight:
+		x = l.Width - l.Margin - w
+	default:
+		x = (l.Width - w) / 2
+	}
+	switch a {
+	case AnchorCenter:
+		return x, (l.Height - h) / 2
+	case AnchorTopLeft, AnchorTop, AnchorTopRight:
+		y = l.Margin + l.used[a]
+	default:
+		y = l.Height - l.Margin - l.used[a] - h
+	}
+	l.used[a] += h + l.Margin
+	return x, y
+}
@@ -3,86 +3,155 @@ package ui
 import (
 	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-// Slider is a simple UI widget
+// nudgeSteps is the number of arrow key presses crossing a slider without Step
+const nudgeSteps = 100
+
+// Slider is a simple UI widget: drag it with the mouse, or nudge it with the Left and Right
+// arrow keys while the cursor is over it
 type Slider struct {
 	Label    string
 	Value    float64
 	Min, Max float64
-	X, Y     float64
-	W, H     float64
+	// Step snaps the value to Min + n*Step, 0 leaves it continuous
+	Step float64
+	// Integer rounds the value to whole numbers (a Step below 1 counts as 1)
+	Integer bool
+	X, Y    float64
+	W, H    float64
+
+	// Styling, from the theme by default
+	TrackColor color.RGBA
+	FillColor  color.RGBA
+	ValueBG    color.RGBA
+	TextColor  color.RGBA
+	HoverColor color.RGBA
 }
 
 // NewSlider creates a new slider instance
 func NewSlider(x, y, w float64, label string, min, max, value float64) *Slider {
 	return &Slider{
-		Label: label,
-		Value: value,
-		Min:   min,
-		Max:   max,
-		X:     x,
-		Y:     y,
-		W:     w,
-		H:     20, // Default height
+		Label:      label,
+		Value:      value,
+		Min:        min,
+		Max:        max,
+		X:          x,
+		Y:          y,
+		W:          w,
+		H:          controlHeight(), // Default height
+		TrackColor: theme.Track,
+		FillColor:  theme.Fill,
+		ValueBG:    theme.ValueBG,
+		TextColor:  theme.Text,
+		HoverColor: theme.AccentHover,
 	}
 }
 
-// Update checks for mouse interaction
-func (s *Slider) Update() {
+// step returns the increment of the value: Step, at least 1 in integer mode, 0 when continuous
+func (s *Slider) step() float64 {
+	if s.Integer {
+		return max(s.Step, 1)
+	}
+	return s.Step
+}
+
+// snap clamps 'v' to the range of the slider and rounds it to the nearest step
+func (s *Slider) snap(v float64) float64 {
+	if step := s.step(); step > 0 {
+		v = s.Min + math.Round((v-s.Min)/step)*step
+	}
+	return max(s.Min, min(v, s.Max))
+}
+
+// Nudge moves the value by 'n' steps (n/100 of the range without Step), negative moves it down
+func (s *Slider) Nudge(n int) {
+	step := s.step()
+	if step == 0 {
+		step = (s.Max - s.Min) / nudgeSteps
+	}
+	s.Value = s.snap(s.Value + float64(n)*step)
+}
+
+// Hovered reports whether the cursor is over the slider
+func (s *Slider) Hovered() bool {
 	mx, my := ebiten.CursorPosition()
-	// Check if mouse is clicking inside the slider area
+	return float64(mx) >= s.X && float64(mx) <= s.X+s.W &&
+		float64(my) >= s.Y && float64(my) <= s.Y+s.H
+}
+
+// Update checks for mouse interaction, and for the arrow keys while hovered
+func (s *Slider) Update() {
+	if !s.Hovered() {
+		return
+	}
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		if float64(mx) >= s.X && float64(mx) <= s.X+s.W &&
-			float64(my) >= s.Y && float64(my) <= s.Y+s.H {
-			// Calculate value based on horizontal position
-			p := (float64(mx) - s.X) / s.W
-			s.Value = s.Min + p*(s.Max-s.Min)
+		// Calculate value based on horizontal position
+		mx, _ := ebiten.CursorPosition()
+		p := (float64(mx) - s.X) / s.W
+		s.Value = s.snap(s.Min + p*(s.Max-s.Min))
+		return
+	}
+	// The arrows belong to the text input having the focus
+	if KeyboardCaptured() {
+		return
+	}
+	if repeating(ebiten.KeyLeft) {
+		s.Nudge(-1)
+	}
+	if repeating(ebiten.KeyRight) {
+		s.Nudge(1)
+	}
+}
 
-			// Clamp value
-			if s.Value < s.Min {
-				s.Value = s.Min
-			}
-			if s.Value > s.Max {
-				s.Value = s.Max
-			}
-		}
+// Text formats the value: without decimals in integer mode, with those of Step when it is set,
+// by magnitude otherwise
+func (s *Slider) Text() string {
+	if s.Integer {
+		return fmt.Sprintf("%.0f", s.Value)
+	}
+	if s.Step > 0 {
+		decimals := max(0, int(math.Ceil(-math.Log10(s.Step)-1e-9)))
+		return fmt.Sprintf("%.*f", decimals, s.Value)
+	}
+	switch {
+	case s.Value >= 5:
+		// Values >= 5: no decimals
+		return fmt.Sprintf("%.0f", s.Value)
+	case s.Value < 0.01 && s.Value > 0:
+		// Very small values - use more decimals
+		return fmt.Sprintf("%.4f", s.Value)
+	case s.Value < 1:
+		// Small values - 2 decimals
+		return fmt.Sprintf("%.2f", s.Value)
 	}
+	// Medium values (1-5) - 1 decimal
+	return fmt.Sprintf("%.1f", s.Value)
 }
 
 // Draw renders the slider
 func (s *Slider) Draw(screen *ebiten.Image) {
-	// Draw Background (Dark Gray)
-	vector.FillRect(screen, float32(s.X), float32(s.Y), float32(s.W), float32(s.H), color.RGBA{R: 80, G: 80, B: 80, A: 255}, true)
-
-	// Draw Value Bar (Light Gray/White)
+	// Draw the track, then the value bar
+	vector.FillRect(screen, float32(s.X), float32(s.Y), float32(s.W), float32(s.H), s.TrackColor, true)
 	ratio := (s.Value - s.Min) / (s.Max - s.Min)
 	barWidth := s.W * ratio
-	vector.FillRect(screen, float32(s.X), float32(s.Y), float32(barWidth), float32(s.H), color.RGBA{R: 200, G: 200, B: 200, A: 255}, true)
+	vector.FillRect(screen, float32(s.X), float32(s.Y), float32(barWidth), float32(s.H), s.FillColor, true)
 
-	// Draw current value at the right end of the bar
-	// Format value based on magnitude
-	var valueText string
-	if s.Value >= 5 {
-		// Values >= 5: no decimals
-		valueText = fmt.Sprintf("%.0f", s.Value)
-	} else if s.Value < 0.01 && s.Value > 0 {
-		// Very small values - use more decimals
-		valueText = fmt.Sprintf("%.4f", s.Value)
-	} else if s.Value < 1 {
-		// Small values - 2 decimals
-		valueText = fmt.Sprintf("%.2f", s.Value)
-	} else {
-		// Medium values (1-5) - 1 decimal
-		valueText = fmt.Sprintf("%.1f", s.Value)
-	}
+	// Draw current value at the right end of the bar, kept inside the slider
+	// on a dark background so that it stays readable over the bar
+	valueText := s.Text()
+	textW := textWidth(valueText)
+	textX := min(s.X+barWidth+5, s.X+s.W-textW-4)
+	textY := s.Y + 2
+	vector.FillRect(screen, float32(textX-2), float32(textY), float32(textW+4), float32(s.H-4), s.ValueBG, true)
+	DrawText(screen, valueText, int(textX), int(textY), s.TextColor)
 
-	// Position text at the right end of the value bar
-	textX := int(s.X + barWidth + 5)
-	textY := int(s.Y + 5)
-	ebitenutil.DebugPrintAt(screen, valueText, textX, textY)
+	// Outline while the arrow keys nudge it
+	if s.Hovered() {
+		vector.StrokeRect(screen, float32(s.X), float32(s.Y), float32(s.W), float32(s.H), 1, s.HoverColor, true)
+	}
 }
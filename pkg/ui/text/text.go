@@ -0,0 +1,105 @@
+// Package text draws the labels, stats and messages of the UI with the embedded Go fonts (TrueType,
+// regular and mono) through Ebiten's text/v2: any size, horizontal alignment, color, and the accented,
+// Greek and Cyrillic letters the 6x16 debug font cannot print.
+package text
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	ebitentext "github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// DefaultSize is the size in pixels of the text drawn with a zero Options.Size, as tall as the debug font
+const DefaultSize = 13
+
+// Align is the horizontal alignment of the text on the x given to Draw
+type Align uint8
+
+const (
+	AlignStart  Align = iota // x is the left edge of the text (default)
+	AlignCenter              // x is the center of the text
+	AlignEnd                 // x is the right edge of the text
+)
+
+// Options are the style of a text, the zero value draws white regular text at DefaultSize
+type Options struct {
+	Size  float64     // Size in pixels, DefaultSize when 0
+	Color color.Color // White when nil
+	Align Align
+	// Mono selects Go Mono: every character has the same width, for the columns of the stats and tables
+	Mono bool
+}
+
+var (
+	loadOnce sync.Once
+	regular  *ebitentext.GoTextFaceSource
+	mono     *ebitentext.GoTextFaceSource
+)
+
+// sources parses the embedded fonts on first use
+func sources() (*ebitentext.GoTextFaceSource, *ebitentext.GoTextFaceSource) {
+	loadOnce.Do(func() {
+		var err error
+		if regular, err = ebitentext.NewGoTextFaceSource(bytes.NewReader(goregular.TTF)); err != nil {
+			panic("text: invalid embedded font: " + err.Error())
+		}
+		if mono, err = ebitentext.NewGoTextFaceSource(bytes.NewReader(gomono.TTF)); err != nil {
+			panic("text: invalid embedded font: " + err.Error())
+		}
+	})
+	return regular, mono
+}
+
+// size returns the size of the options, DefaultSize when it is not set
+func (o Options) size() float64 {
+	if o.Size <= 0 {
+		return DefaultSize
+	}
+	return o.Size
+}
+
+// Face returns the font face of the options, for the functions of text/v2
+func (o Options) Face() ebitentext.Face {
+	source, monoSource := sources()
+	if o.Mono {
+		source = monoSource
+	}
+	return &ebitentext.GoTextFace{Source: source, Size: o.size()}
+}
+
+// LineHeight returns the distance between two lines of text of 'size' pixels (16 for DefaultSize)
+func LineHeight(size float64) float64 {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return math.Round(size * 1.25)
+}
+
+// Draw prints 's' with its top at 'y', each line of a multi-line text aligned on 'x'
+func Draw(dst *ebiten.Image, s string, x, y float64, opts Options) {
+	op := &ebitentext.DrawOptions{}
+	op.GeoM.Translate(x, y)
+	op.LineSpacing = LineHeight(opts.size())
+	op.PrimaryAlign = ebitentext.Align(opts.Align)
+	if opts.Color != nil {
+		op.ColorScale.ScaleWithColor(opts.Color)
+	}
+	ebitentext.Draw(dst, s, opts.Face(), op)
+}
+
+// Measure returns the width of the longest line of 's' and the height of its lines
+func Measure(s string, opts Options) (w, h float64) {
+	return ebitentext.Measure(s, opts.Face(), LineHeight(opts.size()))
+}
+
+// Width returns the width of the longest line of 's'
+func Width(s string, opts Options) float64 {
+	w, _ := Measure(s, opts)
+	return w
+}
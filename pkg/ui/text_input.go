@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"image/color"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// focused is the TextInput receiving the keyboard, nil when none has the focus
+var focused *TextInput
+
+// KeyboardCaptured reports whether a TextInput has the focus: the keyboard shortcuts of the
+// application must then be ignored, the keys go to the text
+func KeyboardCaptured() bool {
+	return focused != nil
+}
+
+// AcceptDigits accepts the characters of a positive integer
+func AcceptDigits(r rune) bool {
+	return unicode.IsDigit(r)
+}
+
+// AcceptNumber accepts the characters of a decimal number, with a sign and an exponent
+func AcceptNumber(r rune) bool {
+	return unicode.IsDigit(r) || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E'
+}
+
+// TextInput is a single-line text field: a click gives it the focus, then it captures the keyboard
+// until Enter (OnSubmit), Escape (the text typed is discarded) or a click elsewhere
+type TextInput struct {
+	Label string
+	Text  string
+	X, Y  float64
+	W, H  float64
+	// MaxLen caps the number of characters, 0 means no limit
+	MaxLen int
+	// Accept filters the typed characters (e.g. AcceptDigits), nil accepts every printable one
+	Accept func(r rune) bool
+	// OnSubmit is called with the text when Enter is pressed
+	OnSubmit func(text string)
+
+	runes    []rune
+	caret    int    // Index in runes of the character after the caret
+	original string // Text when the focus was taken, restored by Escape
+	frames   int    // Frames since the focus was taken, blinks the caret
+	chars    []rune // Reusable buffer of the characters typed this frame
+
+	// Styling, from the theme by default
+	BGColor     color.RGBA
+	BorderColor color.RGBA
+	FocusColor  color.RGBA
+	TextColor   color.RGBA
+}
+
+// NewTextInput creates a new text input instance
+func NewTextInput(x, y, w float64, label, text string) *TextInput {
+	return &TextInput{
+		Label:       label,
+		Text:        text,
+		X:           x,
+		Y:           y,
+		W:           w,
+		H:           controlHeight(), // Default height
+		BGColor:     theme.Control,
+		BorderColor: theme.ControlBorder,
+		FocusColor:  theme.AccentHover,
+		TextColor:   theme.Text,
+	}
+}
+
+// Focused reports whether the input receives the keyboard
+func (t *TextInput) Focused() bool {
+	return focused == t
+}
+
+// Focus gives the keyboard to the input, with the caret at the end of the text
+func (t *TextInput) Focus() {
+	if focused == t {
+		return
+	}
+	if focused != nil {
+		focused.Blur()
+	}
+	focused = t
+	t.runes = []rune(t.Text)
+	t.caret = len(t.runes)
+	t.original = t.Text
+	t.frames = 0
+}
+
+// Blur releases the keyboard, keeping the text typed
+func (t *TextInput) Blur() {
+	if focused == t {
+		focused = nil
+	}
+}
+
+// Update takes the focus on a click inside the input, drops it on a click outside,
+// and edits the text while focused
+func (t *TextInput) Update() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		mx, my := ebiten.CursorPosition()
+		if float64(mx) >= t.X && float64(mx) <= t.X+t.W &&
+			float64(my) >= t.Y && float64(my) <= t.Y+t.H {
+			t.Focus()
+			// Caret on the clicked character
+			t.caret = min(t.scroll()+max(0, int((float64(mx)-t.X-4)/monoWidth(1)+0.5)), len(t.runes))
+		} else {
+			t.Blur()
+		}
+	}
+	if !t.Focused() {
+		return
+	}
+	t.frames++
+
+	t.chars = ebiten.AppendInputChars(t.chars[:0])
+	for _, r := range t.chars {
+		t.insert(r)
+	}
+	switch {
+	case repeating(ebiten.KeyBackspace):
+		if t.caret > 0 {
+			t.runes = append(t.runes[:t.caret-1], t.runes[t.caret:]...)
+			t.caret--
+		}
+	case repeating(ebiten.KeyDelete):
+		if t.caret < len(t.runes) {
+			t.runes = append(t.runes[:t.caret], t.runes[t.caret+1:]...)
+		}
+	case repeating(ebiten.KeyLeft):
+		t.caret = max(0, t.caret-1)
+	case repeating(ebiten.KeyRight):
+		t.caret = min(len(t.runes), t.caret+1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		t.caret = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		t.caret = len(t.runes)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		t.runes = []rune(t.original)
+		t.Text = t.original
+		t.Blur()
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter):
+		t.Text = string(t.runes)
+		t.Blur()
+		if t.OnSubmit != nil {
+			t.OnSubmit(t.Text)
+		}
+		return
+	}
+	t.Text = string(t.runes)
+}
+
+// insert types 'r' at the caret, unless it is filtered out or the text is full
+func (t *TextInput) insert(r rune) {
+	if !unicode.IsPrint(r) || (t.Accept != nil && !t.Accept(r)) || (t.MaxLen > 0 && len(t.runes) >= t.MaxLen) {
+		return
+	}
+	t.runes = append(t.runes, 0)
+	copy(t.runes[t.caret+1:], t.runes[t.caret:])
+	t.runes[t.caret] = r
+	t.caret++
+}
+
+// visibleChars returns the number of characters fitting in the input
+func (t *TextInput) visibleChars() int {
+	return max(1, int((t.W-8)/monoWidth(1)))
+}
+
+// scroll returns the index of the first visible character: the caret always stays visible
+func (t *TextInput) scroll() int {
+	if !t.Focused() {
+		return 0
+	}
+	return max(0, t.caret-t.visibleChars())
+}
+
+// Draw renders the input box, the visible part of the text and the caret while focused
+func (t *TextInput) Draw(screen *ebiten.Image) {
+	vector.FillRect(screen,
+		float32(t.X), float32(t.Y),
+		float32(t.W), float32(t.H),
+		t.BGColor, true)
+	border := t.BorderColor
+	if t.Focused() {
+		border = t.FocusColor
+	}
+	vector.StrokeRect(screen,
+		float32(t.X), float32(t.Y),
+		float32(t.W), float32(t.H),
+		2, border, true)
+
+	text := []rune(t.Text)
+	start := t.scroll()
+	end := min(len(text), start+t.visibleChars())
+	if start < end {
+		drawMonoText(screen, string(text[start:end]), int(t.X)+4, int(t.Y)+2, t.TextColor)
+	}
+	// Blinks twice per second at 60 TPS
+	if t.Focused() && t.frames%30 < 20 {
+		x := float32(t.X + 4 + monoWidth(t.caret-start))
+		vector.StrokeLine(screen, x, float32(t.Y)+4, x, float32(t.Y+t.H)-4, 1, t.TextColor, true)
+	}
+}
+
+// repeating reports whether 'key' was just pressed, or is held long enough to repeat
+func repeating(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d >= 30 && (d-30)%3 == 0)
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lao-tseu-is-alive/go-swarm-simulation/pkg/ui/text"
+)
+
+// Theme is the look of the widgets: their colors, the margin of the panel and the size of the text.
+// The widgets take it when they are created (see SetTheme), their color fields may still be changed one by one.
+type Theme struct {
+	Name string
+
+	PanelBG     color.RGBA // Background of the panel
+	PanelBorder color.RGBA
+	SectionBG   color.RGBA // Section headers and title bars of the windows
+	WindowBG    color.RGBA
+	Text        color.RGBA // Labels, values and titles
+
+	Control       color.RGBA // Background of the inputs and dropdowns
+	ControlBorder color.RGBA
+	Track         color.RGBA // Background of the slider tracks
+	Fill          color.RGBA // Value bar of the sliders
+	ValueBG       color.RGBA // Readout of the slider values, over the bar
+	Check         color.RGBA // Box of a checked checkbox
+	Accent        color.RGBA // Buttons, focus and hover outlines, selected option
+	AccentHover   color.RGBA
+	AccentText    color.RGBA // Text over Accent
+	TooltipBG     color.RGBA
+
+	// Padding is the margin between the border of the panel and its widgets
+	Padding float64
+	// FontScale multiplies the size of the text (text.DefaultSize), the widgets grow with it
+	FontScale float64
+}
+
+// DarkTheme is the default theme
+var DarkTheme = Theme{
+	Name:          "dark",
+	PanelBG:       color.RGBA{R: 40, G: 40, B: 45, A: 230},
+	PanelBorder:   color.RGBA{R: 100, G: 100, B: 110, A: 255},
+	SectionBG:     color.RGBA{R: 60, G: 60, B: 70, A: 255},
+	WindowBG:      color.RGBA{R: 20, G: 20, B: 30, A: 220},
+	Text:          color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Control:       color.RGBA{R: 25, G: 25, B: 30, A: 255},
+	ControlBorder: color.RGBA{R: 200, G: 200, B: 200, A: 255},
+	Track:         color.RGBA{R: 80, G: 80, B: 80, A: 255},
+	Fill:          color.RGBA{R: 200, G: 200, B: 200, A: 255},
+	ValueBG:       color.RGBA{R: 40, G: 40, B: 40, A: 200},
+	Check:         color.RGBA{R: 100, G: 200, B: 100, A: 255},
+	Accent:        color.RGBA{R: 80, G: 120, B: 180, A: 255},
+	AccentHover:   color.RGBA{R: 100, G: 150, B: 220, A: 255},
+	AccentText:    color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	TooltipBG:     color.RGBA{R: 20, G: 20, B: 25, A: 240},
+	Padding:       10,
+	FontScale:     1,
+}
+
+// LightTheme has dark text on light grays, for bright rooms and projectors
+var LightTheme = Theme{
+	Name:          "light",
+	PanelBG:       color.RGBA{R: 235, G: 235, B: 238, A: 235},
+	PanelBorder:   color.RGBA{R: 150, G: 150, B: 160, A: 255},
+	SectionBG:     color.RGBA{R: 205, G: 208, B: 215, A: 255},
+	WindowBG:      color.RGBA{R: 245, G: 245, B: 248, A: 230},
+	Text:          color.RGBA{R: 30, G: 30, B: 35, A: 255},
+	Control:       color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	ControlBorder: color.RGBA{R: 120, G: 120, B: 130, A: 255},
+	Track:         color.RGBA{R: 200, G: 200, B: 205, A: 255},
+	Fill:          color.RGBA{R: 140, G: 170, B: 210, A: 255},
+	ValueBG:       color.RGBA{R: 255, G: 255, B: 255, A: 210},
+	Check:         color.RGBA{R: 60, G: 160, B: 60, A: 255},
+	Accent:        color.RGBA{R: 60, G: 110, B: 190, A: 255},
+	AccentHover:   color.RGBA{R: 80, G: 135, B: 220, A: 255},
+	AccentText:    color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	TooltipBG:     color.RGBA{R: 255, G: 255, B: 230, A: 245},
+	Padding:       10,
+	FontScale:     1,
+}
+
+// ThemeNames lists the names of the preset themes
+var ThemeNames = []string{DarkTheme.Name, LightTheme.Name}
+
+// ThemeByName returns the preset theme named 'name' (case-insensitive), DarkTheme for ""
+func ThemeByName(name string) (Theme, error) {
+	switch strings.ToLower(name) {
+	case "", DarkTheme.Name:
+		return DarkTheme, nil
+	case LightTheme.Name:
+		return LightTheme, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames, ", "))
+}
+
+// theme is the theme of the widgets created from now on, and of the text they draw
+var theme = DarkTheme
+
+// SetTheme selects the theme of the widgets created from now on: call it before building the panel.
+// A zero Padding or FontScale keeps the one of DarkTheme.
+func SetTheme(t Theme) {
+	if t.Padding <= 0 {
+		t.Padding = DarkTheme.Padding
+	}
+	if t.FontScale <= 0 {
+		t.FontScale = DarkTheme.FontScale
+	}
+	theme = t
+}
+
+// CurrentTheme returns the theme selected by SetTheme, DarkTheme by default
+func CurrentTheme() Theme {
+	return theme
+}
+
+// textOptions returns the style of the text of the widgets, at the scale of the theme
+func textOptions(clr color.RGBA) text.Options {
+	return text.Options{Size: text.DefaultSize * theme.FontScale, Color: clr}
+}
+
+// textWidth returns the width of 's' in the text of the widgets
+func textWidth(s string) float64 {
+	return text.Width(s, textOptions(theme.Text))
+}
+
+// monoWidth returns the width of 'n' characters of the mono text of the inputs
+func monoWidth(n int) float64 {
+	opts := textOptions(theme.Text)
+	opts.Mono = true
+	return text.Width("0", opts) * float64(n)
+}
+
+// textHeight returns the height of a line of the text of the widgets
+func textHeight() float64 {
+	return text.LineHeight(text.DefaultSize * theme.FontScale)
+}
+
+// controlHeight is the height of the sliders, inputs and dropdowns: 20, more when the text is larger
+func controlHeight() float64 {
+	return max(20, textHeight()+4)
+}
+
+// DrawText prints 'text' at (x, y) in 'clr', at the size of the theme
+func DrawText(screen *ebiten.Image, s string, x, y int, clr color.RGBA) {
+	text.Draw(screen, s, float64(x), float64(y), textOptions(clr))
+}
+
+// drawMonoText is DrawText with the mono font, whose characters all have the width of monoWidth(1)
+func drawMonoText(screen *ebiten.Image, s string, x, y int, clr color.RGBA) {
+	opts := textOptions(clr)
+	opts.Mono = true
+	text.Draw(screen, s, float64(x), float64(y), opts)
+}
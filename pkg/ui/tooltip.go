@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"image"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// tooltipDelay is the number of frames the cursor rests on a widget before its tooltip shows (0.5s at 60 TPS)
+	tooltipDelay = 30
+	// tooltipWidth is the maximum number of characters of a tooltip line
+	tooltipWidth = 40
+)
+
+// tooltipState follows the widget under the cursor, to show its tooltip once the cursor rested on it
+type tooltipState struct {
+	index  int // Widget under the cursor, -1 when none
+	frames int // Frames since the cursor entered it
+}
+
+// track counts the frames spent on widget 'index', -1 for none
+func (t *tooltipState) track(index int) {
+	if index != t.index {
+		t.index, t.frames = index, 0
+		return
+	}
+	t.frames++
+}
+
+// due reports whether the cursor rested long enough on a widget to show its tooltip
+func (t *tooltipState) due() bool {
+	return t.index >= 0 && t.frames >= tooltipDelay
+}
+
+// wrapText splits 'text' in lines of at most 'width' characters, breaking between words
+// (a word longer than the width gets a line of its own)
+func wrapText(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && line.Len()+1+len(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// drawTooltip renders 'text' wrapped in a box below and right of the cursor, kept inside the screen
+func drawTooltip(screen *ebiten.Image, text string) {
+	lines := wrapText(text, tooltipWidth)
+	if len(lines) == 0 {
+		return
+	}
+	longest := 0.0
+	for _, line := range lines {
+		longest = max(longest, textWidth(line))
+	}
+	w, h := int(longest)+12, int(textHeight())*len(lines)+8
+	mx, my := ebiten.CursorPosition()
+	box := image.Rect(mx+12, my+16, mx+12+w, my+16+h)
+	bounds := screen.Bounds()
+	if box.Max.X > bounds.Max.X {
+		box = box.Sub(image.Pt(box.Max.X-bounds.Max.X, 0))
+	}
+	if box.Max.Y > bounds.Max.Y {
+		// Above the cursor rather than over it
+		box = box.Sub(image.Pt(0, h+24))
+	}
+	vector.FillRect(screen, float32(box.Min.X), float32(box.Min.Y), float32(w), float32(h),
+		theme.TooltipBG, true)
+	vector.StrokeRect(screen, float32(box.Min.X), float32(box.Min.Y), float32(w), float32(h),
+		1, theme.AccentHover, true)
+	for i, line := range lines {
+		DrawText(screen, line, box.Min.X+6, box.Min.Y+4+i*int(textHeight()), theme.Text)
+	}
+}
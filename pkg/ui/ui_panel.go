@@ -1,10 +1,11 @@
 package ui
 
 import (
+	"image"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -21,7 +22,7 @@ type SliderWrapper struct {
 }
 
 func (s *SliderWrapper) GetHeight() float64 {
-	return s.H + 25 // Slider height + label space
+	return s.H + labelSpace() // Slider height + label space
 }
 
 // CheckboxWrapper wraps Checkbox to implement UIWidget
@@ -30,7 +31,7 @@ type CheckboxWrapper struct {
 }
 
 func (c *CheckboxWrapper) GetHeight() float64 {
-	return c.Size + 25 // Checkbox size + label space + margin
+	return c.Size + labelSpace() // Checkbox size + label space + margin
 }
 
 // ButtonWrapper wraps Button to implement UIWidget
@@ -42,18 +43,58 @@ func (b *ButtonWrapper) GetHeight() float64 {
 	return b.Height + 10 // Button height + margin
 }
 
+// TextInputWrapper wraps TextInput to implement UIWidget
+type TextInputWrapper struct {
+	*TextInput
+}
+
+func (t *TextInputWrapper) GetHeight() float64 {
+	return t.H + labelSpace() // Input height + label space
+}
+
+// DropdownWrapper wraps Dropdown to implement UIWidget
+type DropdownWrapper struct {
+	*Dropdown
+}
+
+func (d *DropdownWrapper) GetHeight() float64 {
+	return d.H + labelSpace() // Box height + label space (the open list covers the next widgets)
+}
+
+// labelSpace is the room of the label above a widget, with the margin below it: 25 for the debug font
+func labelSpace() float64 {
+	return textHeight() + 9
+}
+
+// headerHeight is the height of a section header, and headerSpace the room it takes with its margin
+func headerHeight() float64 {
+	return controlHeight()
+}
+
+func headerSpace() float64 {
+	return headerHeight() + 5
+}
+
+// titleSpace is the room of the title of the panel, above the first section
+func titleSpace() float64 {
+	return textHeight() + 14
+}
+
 // UIPanel manages a collection of UI widgets in a scrollable panel
 type UIPanel struct {
 	X, Y          float64 // Panel position
 	Width, Height float64 // Panel dimensions
 	Widgets       []UIWidget
 	Labels        []string // Labels for widgets
+	Tooltips      []string // Descriptions shown when the cursor rests on a widget, "" for none
 	ScrollOffset  float64  // Current scroll position
 
-	// Styling
-	BGColor     color.RGBA
-	BorderColor color.RGBA
-	TextColor   color.RGBA
+	// Styling, from the theme by default
+	BGColor      color.RGBA
+	BorderColor  color.RGBA
+	TextColor    color.RGBA
+	SectionColor color.RGBA
+	Padding      float64 // Margin between the border and the widgets
 
 	// Section headers
 	sections []PanelSection
@@ -65,9 +106,15 @@ type UIPanel struct {
 
 	// Hide button
 	hideButton *Button
+
+	// rows are the areas of the widgets (label included) at the last Draw, empty when hidden,
+	// headers those of the section headers, clicked to collapse or expand their section
+	rows    []image.Rectangle
+	headers []image.Rectangle
+	tooltip tooltipState
 }
 
-// PanelSection represents a collapsible section in the panel
+// PanelSection represents a collapsible section in the panel: a click on its header hides its widgets
 type PanelSection struct {
 	Title      string
 	StartIndex int // Widget index where this section starts
@@ -84,14 +131,18 @@ func NewUIPanel(x, y, width, height float64) *UIPanel {
 		Height:       height,
 		Widgets:      make([]UIWidget, 0),
 		Labels:       make([]string, 0),
+		Tooltips:     make([]string, 0),
 		ScrollOffset: 0,
-		BGColor:      color.RGBA{R: 40, G: 40, B: 45, A: 230},
-		BorderColor:  color.RGBA{R: 100, G: 100, B: 110, A: 255},
-		TextColor:    color.RGBA{R: 220, G: 220, B: 220, A: 255},
+		BGColor:      theme.PanelBG,
+		BorderColor:  theme.PanelBorder,
+		TextColor:    theme.Text,
+		SectionColor: theme.SectionBG,
+		Padding:      theme.Padding,
 		sections:     make([]PanelSection, 0),
 		TargetX:      x,
 		slideSpeed:   20.0,
 		IsCollapsed:  false,
+		tooltip:      tooltipState{index: -1},
 	}
 
 	// Create hide button (top-right corner of panel)
@@ -124,31 +175,32 @@ func (p *UIPanel) EndSection() {
 	}
 }
 
-// AddSlider adds a slider widget to the panel
-func (p *UIPanel) AddSlider(label string, min, max, value float64) *Slider {
+// AddSlider adds a slider widget to the panel, 'tooltip' describes it ("" for none)
+func (p *UIPanel) AddSlider(label, tooltip string, min, max, value float64) *Slider {
 	// Calculate position within panel
 	yOffset := p.calculateNextYOffset()
 
 	slider := NewSlider(
-		p.X+10,         // X position with margin
-		p.Y+yOffset+20, // Y position
-		p.Width-20,     // Width with margins
+		p.X+p.Padding,       // X position with margin
+		p.Y+yOffset+20,      // Y position
+		p.Width-2*p.Padding, // Width with margins
 		label,
 		min, max, value,
 	)
 
 	p.Widgets = append(p.Widgets, &SliderWrapper{slider})
 	p.Labels = append(p.Labels, label)
+	p.Tooltips = append(p.Tooltips, tooltip)
 
 	return slider
 }
 
-// AddCheckbox adds a checkbox widget to the panel
-func (p *UIPanel) AddCheckbox(label string, value bool) *Checkbox {
+// AddCheckbox adds a checkbox widget to the panel, 'tooltip' describes it ("" for none)
+func (p *UIPanel) AddCheckbox(label, tooltip string, value bool) *Checkbox {
 	yOffset := p.calculateNextYOffset()
 
 	checkbox := NewCheckbox(
-		p.X+10,
+		p.X+p.Padding,
 		p.Y+yOffset+20,
 		label,
 		value,
@@ -156,6 +208,7 @@ func (p *UIPanel) AddCheckbox(label string, value bool) *Checkbox {
 
 	p.Widgets = append(p.Widgets, &CheckboxWrapper{checkbox})
 	p.Labels = append(p.Labels, label)
+	p.Tooltips = append(p.Tooltips, tooltip)
 
 	return checkbox
 }
@@ -165,28 +218,76 @@ func (p *UIPanel) AddButton(label string, onClick func()) *Button {
 	yOffset := p.calculateNextYOffset()
 
 	button := NewButton(
-		p.X+10,
+		p.X+p.Padding,
 		p.Y+yOffset+20,
-		p.Width-20,
-		30,
+		p.Width-2*p.Padding,
+		max(30, textHeight()+14),
 		label,
 		onClick,
 	)
 
 	p.Widgets = append(p.Widgets, &ButtonWrapper{button})
 	p.Labels = append(p.Labels, label)
+	p.Tooltips = append(p.Tooltips, "")
 
 	return button
 }
 
+// AddTextInput adds a text input widget to the panel
+func (p *UIPanel) AddTextInput(label, text string) *TextInput {
+	yOffset := p.calculateNextYOffset()
+
+	input := NewTextInput(
+		p.X+p.Padding,
+		p.Y+yOffset+20,
+		p.Width-2*p.Padding,
+		label,
+		text,
+	)
+
+	p.Widgets = append(p.Widgets, &TextInputWrapper{input})
+	p.Labels = append(p.Labels, label)
+	p.Tooltips = append(p.Tooltips, "")
+
+	return input
+}
+
+// AddDropdown adds a dropdown widget to the panel, with options[selected] chosen
+func (p *UIPanel) AddDropdown(label string, options []string, selected int) *Dropdown {
+	yOffset := p.calculateNextYOffset()
+
+	dropdown := NewDropdown(
+		p.X+p.Padding,
+		p.Y+yOffset+20,
+		p.Width-2*p.Padding,
+		label,
+		options,
+		selected,
+	)
+
+	p.Widgets = append(p.Widgets, &DropdownWrapper{dropdown})
+	p.Labels = append(p.Labels, label)
+	p.Tooltips = append(p.Tooltips, "")
+
+	return dropdown
+}
+
+// openDropdown returns the dropdown whose list is open, nil when none is
+func (p *UIPanel) openDropdown() *Dropdown {
+	for _, widget := range p.Widgets {
+		if d, ok := widget.(*DropdownWrapper); ok && d.Open {
+			return d.Dropdown
+		}
+	}
+	return nil
+}
+
 // calculateNextYOffset calculates the Y offset for the next widget
 func (p *UIPanel) calculateNextYOffset() float64 {
 	offset := 0.0
 
-	// Add section header heights (20px each)
-	for range p.sections {
-		offset += 25
-	}
+	// Add section header heights
+	offset += float64(len(p.sections)) * headerSpace()
 
 	// Add all widget heights
 	for _, widget := range p.Widgets {
@@ -223,23 +324,35 @@ func (p *UIPanel) Update() {
 	_, dy := ebiten.Wheel()
 	if dy != 0 {
 		p.ScrollOffset -= dy * 20
+		p.clampScroll()
+	}
 
-		// Clamp scroll
-		maxScroll := p.calculateTotalHeight() - p.Height + 40
-		if maxScroll < 0 {
-			maxScroll = 0
-		}
-		if p.ScrollOffset < 0 {
-			p.ScrollOffset = 0
-		}
-		if p.ScrollOffset > maxScroll {
-			p.ScrollOffset = maxScroll
+	// An open list covers the widgets below it: it gets the click alone
+	if open := p.openDropdown(); open != nil {
+		p.tooltip.track(-1)
+		open.Update()
+		return
+	}
+	// No tooltip over a slider being dragged
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		p.tooltip.track(-1)
+	} else {
+		p.tooltip.track(p.hoveredRow())
+	}
+
+	// A click on a section header only collapses or expands it
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if i := p.hoveredHeader(); i >= 0 {
+			p.ToggleSection(i)
+			return
 		}
 	}
 
-	// Update all widgets
-	for _, widget := range p.Widgets {
-		widget.Update()
+	// Update all widgets, but those of the collapsed sections
+	for i, widget := range p.Widgets {
+		if !p.hiddenWidget(i) {
+			widget.Update()
+		}
 	}
 
 	// Update hide button (only when panel is fully visible and not animating)
@@ -263,37 +376,48 @@ func (p *UIPanel) Draw(screen *ebiten.Image) {
 		2, p.BorderColor, true)
 
 	// Draw title
-	ebitenutil.DebugPrintAt(screen, "Configuration", int(p.X+10), int(p.Y+5))
+	DrawText(screen, "Configuration", int(p.X+p.Padding), int(p.Y+5), p.TextColor)
 
 	// Draw hide button
 	p.hideButton.Draw(screen)
-	ebitenutil.DebugPrintAt(screen, p.hideButton.Label,
-		int(p.hideButton.X+5), int(p.hideButton.Y+3))
+	DrawText(screen, p.hideButton.Label,
+		int(p.hideButton.X+5), int(p.hideButton.Y+3), p.hideButton.TextColor)
 
 	// Draw widgets with clipping and scrolling
-	currentY := p.Y + 30 - p.ScrollOffset
+	currentY := p.Y + titleSpace() - p.ScrollOffset
 	widgetIdx := 0
+	if cap(p.rows) < len(p.Widgets) {
+		p.rows = make([]image.Rectangle, len(p.Widgets))
+	}
+	p.rows = p.rows[:len(p.Widgets)]
+	clear(p.rows)
+	p.headers = p.headers[:0]
+	panelArea := image.Rect(int(p.X), int(p.Y+titleSpace()), int(p.X+p.Width), int(p.Y+p.Height))
 
 	for sectionIdx, section := range p.sections {
 		// Draw section header
-		if currentY >= p.Y-25 && currentY <= p.Y+p.Height {
-			sectionBG := color.RGBA{R: 60, G: 60, B: 70, A: 255}
+		if currentY >= p.Y-headerSpace() && currentY <= p.Y+p.Height {
 			vector.FillRect(screen,
 				float32(p.X+5), float32(currentY),
-				float32(p.Width-10), 20,
-				sectionBG, true)
-			ebitenutil.DebugPrintAt(screen, section.Title,
-				int(p.X+10), int(currentY+5))
+				float32(p.Width-10), float32(headerHeight()),
+				p.SectionColor, true)
+			marker := "- "
+			if section.Collapsed {
+				marker = "+ "
+			}
+			DrawText(screen, marker+section.Title,
+				int(p.X+p.Padding), int(currentY+(headerHeight()-textHeight())/2), p.TextColor)
 		}
-		currentY += 25
+		p.headers = append(p.headers, image.Rect(int(p.X+5), int(currentY), int(p.X+p.Width-5), int(currentY+headerHeight())).Intersect(panelArea))
+		currentY += headerSpace()
 
 		// Draw widgets in this section
-		for widgetIdx < section.EndIndex && widgetIdx < len(p.Widgets) {
+		for widgetIdx < section.EndIndex && widgetIdx < len(p.Widgets) && !section.Collapsed {
 			widget := p.Widgets[widgetIdx]
 			label := p.Labels[widgetIdx]
 
 			// Only draw if visible
-			if currentY >= p.Y-30 && currentY <= p.Y+p.Height {
+			if currentY >= p.Y-titleSpace() && currentY <= p.Y+p.Height {
 				// Handle different widget types
 				switch w := widget.(type) {
 				case *CheckboxWrapper:
@@ -301,27 +425,27 @@ func (p *UIPanel) Draw(screen *ebiten.Image) {
 					p.adjustWidgetPosition(widget, currentY+2)
 					widget.Draw(screen)
 					// Label to the right of checkbox
-					ebitenutil.DebugPrintAt(screen, label,
-						int(p.X+10+w.Size+8), int(currentY))
+					DrawText(screen, label,
+						int(p.X+p.Padding+w.Size+8), int(currentY), p.TextColor)
 
 				case *ButtonWrapper:
-					// For button: draw button with label centered inside
+					// For button: draw button with its current label centered inside
 					p.adjustWidgetPosition(widget, currentY)
 					widget.Draw(screen)
-					// Label centered in button
-					textOffset := (len(label) * 8) / 2
-					ebitenutil.DebugPrintAt(screen, label,
-						int(p.X+p.Width/2-float64(textOffset)), int(currentY+8))
+					label = w.Label
+					DrawText(screen, label,
+						int(p.X+p.Width/2-textWidth(label)/2), int(currentY+(w.Height-textHeight())/2), w.TextColor)
 
 				default:
 					// For sliders: draw label above
-					ebitenutil.DebugPrintAt(screen, label,
-						int(p.X+10), int(currentY))
-					p.adjustWidgetPosition(widget, currentY+15)
+					DrawText(screen, label,
+						int(p.X+p.Padding), int(currentY), p.TextColor)
+					p.adjustWidgetPosition(widget, currentY+textHeight()-1)
 					widget.Draw(screen)
 				}
 			}
 
+			p.rows[widgetIdx] = image.Rect(int(p.X), int(currentY), int(p.X+p.Width), int(currentY+widget.GetHeight())).Intersect(panelArea)
 			currentY += widget.GetHeight()
 			widgetIdx++
 		}
@@ -331,6 +455,92 @@ func (p *UIPanel) Draw(screen *ebiten.Image) {
 			widgetIdx = p.sections[sectionIdx+1].StartIndex
 		}
 	}
+
+	// Open list above the widgets it covers
+	if open := p.openDropdown(); open != nil {
+		open.DrawList(screen)
+	}
+
+	// Tooltip above everything
+	if p.tooltip.due() && p.tooltip.index < len(p.Tooltips) && p.Tooltips[p.tooltip.index] != "" {
+		drawTooltip(screen, p.Tooltips[p.tooltip.index])
+	}
+}
+
+// hoveredHeader returns the index of the section header under the cursor at the last Draw, -1 when none is
+func (p *UIPanel) hoveredHeader() int {
+	if p.IsCollapsed || p.X != p.TargetX {
+		return -1
+	}
+	mx, my := ebiten.CursorPosition()
+	cursor := image.Pt(mx, my)
+	for i, header := range p.headers {
+		if cursor.In(header) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ToggleSection collapses or expands section 'i'. The widgets of a collapsed section
+// are neither drawn nor updated: its inputs lose the focus and its lists close.
+func (p *UIPanel) ToggleSection(i int) {
+	if i < 0 || i >= len(p.sections) {
+		return
+	}
+	section := &p.sections[i]
+	section.Collapsed = !section.Collapsed
+	if section.Collapsed {
+		for _, widget := range p.Widgets[section.StartIndex:min(section.EndIndex, len(p.Widgets))] {
+			switch w := widget.(type) {
+			case *TextInputWrapper:
+				w.Blur()
+			case *DropdownWrapper:
+				w.Open = false
+			}
+		}
+	}
+	p.clampScroll()
+}
+
+// hiddenWidget reports whether widget 'i' belongs to a collapsed section
+func (p *UIPanel) hiddenWidget(i int) bool {
+	for _, section := range p.sections {
+		if section.Collapsed && i >= section.StartIndex && i < section.EndIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// clampScroll keeps the scroll offset within the content, which shrinks when a section collapses
+func (p *UIPanel) clampScroll() {
+	maxScroll := p.calculateTotalHeight() - p.Height + 40
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if p.ScrollOffset < 0 {
+		p.ScrollOffset = 0
+	}
+	if p.ScrollOffset > maxScroll {
+		p.ScrollOffset = maxScroll
+	}
+}
+
+// hoveredRow returns the index of the widget under the cursor at the last Draw, -1 when none is
+// or when the panel is hidden or sliding
+func (p *UIPanel) hoveredRow() int {
+	if p.IsCollapsed || p.X != p.TargetX {
+		return -1
+	}
+	mx, my := ebiten.CursorPosition()
+	cursor := image.Pt(mx, my)
+	for i, row := range p.rows {
+		if cursor.In(row) {
+			return i
+		}
+	}
+	return -1
 }
 
 // adjustWidgetPosition temporarily adjusts widget position for rendering
@@ -342,6 +552,10 @@ func (p *UIPanel) adjustWidgetPosition(widget UIWidget, newY float64) {
 		w.Y = newY
 	case *ButtonWrapper:
 		w.Y = newY
+	case *TextInputWrapper:
+		w.Y = newY
+	case *DropdownWrapper:
+		w.Y = newY
 	}
 }
 
@@ -350,12 +564,18 @@ func (p *UIPanel) updateWidgetPositions() {
 	for _, widget := range p.Widgets {
 		switch w := widget.(type) {
 		case *SliderWrapper:
-			w.X = p.X + 10
+			w.X = p.X + p.Padding
 		case *CheckboxWrapper:
-			w.X = p.X + 10
+			w.X = p.X + p.Padding
 		case *ButtonWrapper:
-			w.X = p.X + 10
-			w.Width = p.Width - 20
+			w.X = p.X + p.Padding
+			w.Width = p.Width - 2*p.Padding
+		case *TextInputWrapper:
+			w.X = p.X + p.Padding
+			w.W = p.Width - 2*p.Padding
+		case *DropdownWrapper:
+			w.X = p.X + p.Padding
+			w.W = p.Width - 2*p.Padding
 		}
 	}
 
@@ -373,19 +593,30 @@ func (p *UIPanel) Toggle() {
 		// Hide panel
 		p.TargetX = -p.Width - 10
 		p.IsCollapsed = true
+		// A hidden input no longer captures the keyboard, nor a hidden list the clicks
+		for _, widget := range p.Widgets {
+			switch w := widget.(type) {
+			case *TextInputWrapper:
+				w.Blur()
+			case *DropdownWrapper:
+				w.Open = false
+			}
+		}
 	}
 }
 
 // calculateTotalHeight calculates the total content height
 func (p *UIPanel) calculateTotalHeight() float64 {
-	height := 30.0 // Title space
+	height := titleSpace()
 
 	// Add section headers
-	height += float64(len(p.sections)) * 25
+	height += float64(len(p.sections)) * headerSpace()
 
-	// Add all widgets
-	for _, widget := range p.Widgets {
-		height += widget.GetHeight()
+	// Add all widgets, but those of the collapsed sections
+	for i, widget := range p.Widgets {
+		if !p.hiddenWidget(i) {
+			height += widget.GetHeight()
+		}
 	}
 
 	return height
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// windowTitleHeight returns the height of the draggable title bar
+func windowTitleHeight() float64 {
+	return controlHeight()
+}
+
+// Window is a floating, draggable panel rendered to its own texture.
+// Ebiten only supports one OS window, so overlays (graphs, inspector...) are moved
+// out of the way of the simulation by detaching them into Windows the user can drag around.
+type Window struct {
+	Title   string
+	X, Y    float64
+	W, H    float64 // Content size (title bar excluded)
+	Visible bool
+
+	// Content draws the window body into a canvas of size W x H (origin at top-left)
+	Content func(canvas *ebiten.Image)
+
+	canvas   *ebiten.Image
+	dragging bool
+	dragDX   float64
+	dragDY   float64
+
+	// Styling, from the theme by default
+	BGColor     color.RGBA
+	TitleColor  color.RGBA
+	TextColor   color.RGBA
+	BorderColor color.RGBA
+}
+
+// NewWindow creates a visible floating window
+func NewWindow(title string, x, y, w, h float64, content func(canvas *ebiten.Image)) *Window {
+	return &Window{
+		Title:       title,
+		X:           x,
+		Y:           y,
+		W:           w,
+		H:           h,
+		Visible:     true,
+		Content:     content,
+		BGColor:     theme.WindowBG,
+		TitleColor:  theme.SectionBG,
+		TextColor:   theme.Text,
+		BorderColor: theme.PanelBorder,
+	}
+}
+
+// Contains reports whether (x, y) is inside the window (title bar included)
+func (w *Window) Contains(x, y float64) bool {
+	return w.Visible && x >= w.X && x <= w.X+w.W && y >= w.Y && y <= w.Y+w.H+windowTitleHeight()
+}
+
+// Update handles dragging by the title bar and the close box.
+// It returns true when the window captured the mouse this frame.
+func (w *Window) Update() bool {
+	if !w.Visible {
+		w.dragging = false
+		return false
+	}
+	mx, my := ebiten.CursorPosition()
+	x, y := float64(mx), float64(my)
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && w.Contains(x, y) {
+		if y <= w.Y+windowTitleHeight() {
+			if x >= w.X+w.W-windowTitleHeight() {
+				// Close box
+				w.Visible = false
+				return true
+			}
+			w.dragging = true
+			w.dragDX = x - w.X
+			w.dragDY = y - w.Y
+		}
+		return true
+	}
+
+	if w.dragging {
+		if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			w.dragging = false
+		} else {
+			w.X = x - w.dragDX
+			w.Y = y - w.dragDY
+		}
+		return true
+	}
+	return false
+}
+
+// Draw renders the title bar and the content texture
+func (w *Window) Draw(screen *ebiten.Image) {
+	if !w.Visible {
+		return
+	}
+	cw, ch := int(w.W), int(w.H)
+	if cw <= 0 || ch <= 0 {
+		return
+	}
+	if w.canvas == nil || w.canvas.Bounds().Dx() != cw || w.canvas.Bounds().Dy() != ch {
+		if w.canvas != nil {
+			w.canvas.Deallocate()
+		}
+		w.canvas = ebiten.NewImage(cw, ch)
+	}
+
+	// Render content offscreen
+	w.canvas.Fill(w.BGColor)
+	if w.Content != nil {
+		w.Content(w.canvas)
+	}
+
+	// Title bar with close box
+	title := windowTitleHeight()
+	vector.FillRect(screen, float32(w.X), float32(w.Y), float32(w.W), float32(title), w.TitleColor, true)
+	DrawText(screen, w.Title, int(w.X+5), int(w.Y+3), w.TextColor)
+	DrawText(screen, "x", int(w.X+w.W-title+7), int(w.Y+3), w.TextColor)
+
+	// Body
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(w.X, w.Y+title)
+	screen.DrawImage(w.canvas, op)
+
+	vector.StrokeRect(screen, float32(w.X), float32(w.Y), float32(w.W), float32(w.H+title), 1,
+		w.BorderColor, true)
+}
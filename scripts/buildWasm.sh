@@ -0,0 +1,15 @@
+#!/bin/bash
+# Build the browser version of the simulation in dist/wasm, serve it with any static web server:
+#   python3 -m http.server -d dist/wasm 8000
+set -e
+OUT_DIR=dist/wasm
+mkdir -p "$OUT_DIR"
+GOOS=js GOARCH=wasm go build -o "$OUT_DIR/simulation.wasm" ./cmd/simulation
+WASM_EXEC="$(go env GOROOT)/lib/wasm/wasm_exec.js"
+if [ ! -f "$WASM_EXEC" ]; then
+  # Go < 1.24
+  WASM_EXEC="$(go env GOROOT)/misc/wasm/wasm_exec.js"
+fi
+cp "$WASM_EXEC" "$OUT_DIR/"
+cp cmd/simulation/web/index.html "$OUT_DIR/"
+echo "## WASM build ready in $OUT_DIR"
@@ -1,5 +1,7 @@
 #!/bin/bash
 # Generate Go code
-protoc --go_out=./pkg/simulation/ --go_opt=paths=source_relative \
+protoc --go_out=. --go_opt=paths=source_relative \
     --go-grpc_out=. --go-grpc_opt=paths=source_relative \
-    pb/simulation.proto
\ No newline at end of file
+    pb/simulation.proto
+# Generate the TypeScript and Python client types and the schema reference (clients/)
+go run ./cmd/pbgen